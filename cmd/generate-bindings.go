@@ -17,20 +17,27 @@ var (
 	bindingsContractID string
 	bindingsNetwork    string
 	bindingsPackage    string
+	bindingsLang       string
 )
 
 var generateBindingsCmd = &cobra.Command{
 	Use:   "generate-bindings <wasm-file>",
-	Short: "Generate TypeScript bindings for a Soroban smart contract",
-	Long: `Generate strongly-typed TypeScript client bindings from a Soroban smart contract.
+	Short: "Generate TypeScript, Go or Rust bindings for a Soroban smart contract",
+	Long: `Generate strongly-typed client bindings from a Soroban smart contract.
 
 This command extracts the contract specification from the WASM file and generates
-a TypeScript client that provides type-safe method calls with erst integration
-for simulation and debugging.
+a client with a typed method for every contract function, plus types for every
+user-defined struct, enum and union with ScVal marshalling.
+
+  ts    TypeScript client with erst integration for simulation and debugging
+  go    Go package; invocations build a transaction envelope and can be replayed
+        through the erst simulator (simulator.RunnerInterface)
+  rust  Rust crate on top of stellar-xdr; invocations build a transaction envelope
 
 Example:
   erst generate-bindings contract.wasm
   erst generate-bindings --output ./src/bindings --package my-contract contract.wasm
+  erst generate-bindings --lang go --output ./token --package token contract.wasm
   erst generate-bindings --contract-id CDLZFC... --network testnet contract.wasm`,
 	Args: cobra.ExactArgs(1),
 	RunE: runGenerateBindings,
@@ -39,6 +46,11 @@ Example:
 func runGenerateBindings(cmd *cobra.Command, args []string) error {
 	wasmPath := args[0]
 
+	lang, err := bindings.ParseLanguage(bindingsLang)
+	if err != nil {
+		return err
+	}
+
 	// Read WASM file
 	wasmBytes, err := os.ReadFile(wasmPath)
 	if err != nil {
@@ -63,6 +75,7 @@ func runGenerateBindings(cmd *cobra.Command, args []string) error {
 		PackageName: bindingsPackage,
 		ContractID:  bindingsContractID,
 		Network:     bindingsNetwork,
+		Language:    lang,
 	}
 
 	// Generate bindings
@@ -90,17 +103,29 @@ func runGenerateBindings(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Generated: %s\n", fullPath)
 	}
 
-	fmt.Printf("\n[OK] TypeScript bindings generated successfully\n")
+	fmt.Printf("\n[OK] %s bindings generated successfully\n", languageName(lang))
 	fmt.Printf("Package: %s\n", bindingsPackage)
 	fmt.Printf("Output: %s\n", bindingsOutput)
 
 	return nil
 }
 
+func languageName(lang bindings.Language) string {
+	switch lang {
+	case bindings.LanguageGo:
+		return "Go"
+	case bindings.LanguageRust:
+		return "Rust"
+	default:
+		return "TypeScript"
+	}
+}
+
 func init() {
 	generateBindingsCmd.Flags().StringVarP(&bindingsOutput, "output", "o", "", "Output directory (defaults to current directory)")
 	generateBindingsCmd.Flags().StringVarP(&bindingsPackage, "package", "p", "", "Package name (defaults to WASM filename)")
 	generateBindingsCmd.Flags().StringVar(&bindingsContractID, "contract-id", "", "Contract ID for network calls")
+	generateBindingsCmd.Flags().StringVarP(&bindingsLang, "lang", "l", "ts", "Target language (ts, go, rust)")
 	generateBindingsCmd.Flags().StringVarP(&bindingsNetwork, "network", "n", "testnet", "Stellar network (testnet, mainnet, futurenet)")
 
 	rootCmd.AddCommand(generateBindingsCmd)
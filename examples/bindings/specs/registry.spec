@@ -0,0 +1,20 @@
+# Contract spec of a record registry exercising enums, tuple structs, unions,
+# options, vectors, maps and fixed-size bytes. One base64 ScSpecEntry per line.
+# enum Status { Pending = 0, Active = 1, Revoked = 2 }
+AAAAAwAAAAAAAAAAAAAABlN0YXR1cwAAAAAAAwAAAAAAAAAHUGVuZGluZwAAAAAAAAAAAAAAAAZBY3RpdmUAAAAAAAEAAAAAAAAAB1Jldm9rZWQAAAAAAg==
+# struct Range(u64, u64)
+AAAAAQAAAAAAAAAAAAAABVJhbmdlAAAAAAAAAgAAAAAAAAABMAAAAAAAAAYAAAAAAAAAATEAAAAAAAAG
+# struct Record { owner: Address, status: Status, hash: BytesN<32>, tags: Vec<Symbol>, expires: Option<u64>, attrs: Map<Symbol, String>, valid: Range }
+AAAAAQAAAAAAAAAAAAAABlJlY29yZAAAAAAABwAAAAAAAAAFb3duZXIAAAAAAAATAAAAAAAAAAZzdGF0dXMAAAAAB9AAAAAGU3RhdHVzAAAAAAAAAAAABGhhc2gAAAPuAAAAIAAAAAAAAAAEdGFncwAAA+oAAAARAAAAAAAAAAdleHBpcmVzAAAAA+gAAAAGAAAAAAAAAAVhdHRycwAAAAAAA+wAAAARAAAAEAAAAAAAAAAFdmFsaWQAAAAAAAfQAAAABVJhbmdlAAAA
+# union Key { Admin, Record(BytesN<32>), Owner(Address, u32) }
+AAAAAgAAAAAAAAAAAAAAA0tleQAAAAADAAAAAAAAAAAAAAAFQWRtaW4AAAAAAAABAAAAAAAAAAZSZWNvcmQAAAAAAAEAAAPuAAAAIAAAAAEAAAAAAAAABU93bmVyAAAAAAAAAgAAABMAAAAE
+# fn register(record: Record) -> BytesN<32>
+AAAAAAAAAAAAAAAIcmVnaXN0ZXIAAAABAAAAAAAAAAZyZWNvcmQAAAAAB9AAAAAGUmVjb3JkAAAAAAABAAAD7gAAACA=
+# fn get(key: Key) -> Option<Record>
+AAAAAAAAAAAAAAADZ2V0AAAAAAEAAAAAAAAAA2tleQAAAAfQAAAAA0tleQAAAAABAAAD6AAAB9AAAAAGUmVjb3JkAAA=
+# fn set_status(hash: BytesN<32>, status: Status, type: Symbol)
+AAAAAAAAAAAAAAAKc2V0X3N0YXR1cwAAAAAAAwAAAAAAAAAEaGFzaAAAA+4AAAAgAAAAAAAAAAZzdGF0dXMAAAAAB9AAAAAGU3RhdHVzAAAAAAAAAAAABHR5cGUAAAARAAAAAA==
+# fn list(owner: Address, range: Range) -> Vec<Record>
+AAAAAAAAAAAAAAAEbGlzdAAAAAIAAAAAAAAABW93bmVyAAAAAAAAEwAAAAAAAAAFcmFuZ2UAAAAAAAfQAAAABVJhbmdlAAAAAAAAAQAAA+oAAAfQAAAABlJlY29yZAAA
+# fn total_supply() -> u256
+AAAAAAAAAAAAAAAMdG90YWxfc3VwcGx5AAAAAAAAAAEAAAAM
@@ -0,0 +1,13 @@
+# Contract spec of a SEP-41 style token, one base64 ScSpecEntry per line.
+# struct TokenMetadata { decimal: u32, name: String, symbol: String }
+AAAAAQAAACJNZXRhZGF0YSBzdG9yZWQgYXQgaW5pdGlhbGl6YXRpb24uAAAAAAAAAAAADVRva2VuTWV0YWRhdGEAAAAAAAADAAAAAAAAAARuYW1lAAAAEAAAAAAAAAAGc3ltYm9sAAAAAAAQAAAAAAAAAAdkZWNpbWFsAAAAAAQ=
+# error TokenError { InsufficientBalance = 1, InsufficientAllowance = 2, NotInitialized = 3 }
+AAAABAAAAAAAAAAAAAAAClRva2VuRXJyb3IAAAAAAAMAAAAAAAAAE0luc3VmZmljaWVudEJhbGFuY2UAAAAAAQAAAAAAAAAVSW5zdWZmaWNpZW50QWxsb3dhbmNlAAAAAAAAAgAAAB9pbml0aWFsaXplIGhhcyBub3QgYmVlbiBjYWxsZWQuAAAAAA5Ob3RJbml0aWFsaXplZAAAAAAAAw==
+# fn initialize(admin: Address, metadata: TokenMetadata)
+AAAAAAAAAAAAAAAKaW5pdGlhbGl6ZQAAAAAAAgAAAAAAAAAFYWRtaW4AAAAAAAATAAAAAAAAAAhtZXRhZGF0YQAAB9AAAAANVG9rZW5NZXRhZGF0YQAAAAAAAAA=
+# fn balance(id: Address) -> i128
+AAAAAAAAABpSZXR1cm5zIHRoZSBiYWxhbmNlIG9mIGlkLgAAAAAAB2JhbGFuY2UAAAAAAQAAAAAAAAACaWQAAAAAABMAAAABAAAACw==
+# fn transfer(from: Address, to: Address, amount: i128) -> Result<(), TokenError>
+AAAAAAAAAB1Nb3ZlcyBhbW91bnQgZnJvbSBmcm9tIHRvIHRvLgAAAAAAAAh0cmFuc2ZlcgAAAAMAAAAAAAAABGZyb20AAAATAAAAAAAAAAJ0bwAAAAAAEwAAAAAAAAAGYW1vdW50AAAAAAALAAAAAQAAA+kAAAACAAAH0AAAAApUb2tlbkVycm9yAAA=
+# fn metadata() -> Result<TokenMetadata, TokenError>
+AAAAAAAAAAAAAAAIbWV0YWRhdGEAAAAAAAAAAQAAA+kAAAfQAAAADVRva2VuTWV0YWRhdGEAAAAAAAfQAAAAClRva2VuRXJyb3IAAA==
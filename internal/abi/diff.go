@@ -0,0 +1,458 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package abi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// Severity classifies how a spec change affects existing callers and stored
+// data.
+type Severity string
+
+const (
+	SeverityBreaking    Severity = "breaking"
+	SeverityNonBreaking Severity = "non-breaking"
+)
+
+// Change kinds reported by Diff.
+const (
+	ChangeFunctionRemoved   = "function_removed"
+	ChangeFunctionRenamed   = "function_renamed"
+	ChangeFunctionAdded     = "function_added"
+	ChangeParamCount        = "param_count_changed"
+	ChangeParamType         = "param_type_changed"
+	ChangeParamRenamed      = "param_renamed"
+	ChangeReturnType        = "return_type_changed"
+	ChangeTypeRemoved       = "type_removed"
+	ChangeTypeAdded         = "type_added"
+	ChangeFieldRemoved      = "field_removed"
+	ChangeFieldAdded        = "field_added"
+	ChangeFieldType         = "field_type_changed"
+	ChangeVariantRemoved    = "variant_removed"
+	ChangeVariantAdded      = "variant_added"
+	ChangeVariantRenumbered = "variant_renumbered"
+	ChangeVariantType       = "variant_type_changed"
+	ChangeErrorRemoved      = "error_removed"
+	ChangeErrorAdded        = "error_added"
+	ChangeErrorCode         = "error_code_changed"
+)
+
+// Change is a single difference between two contract specs.
+type Change struct {
+	Severity Severity `json:"severity"`
+	Kind     string   `json:"kind"`
+	// Subject names the affected item, e.g. "fn transfer" or "struct Config".
+	Subject string `json:"subject"`
+	Detail  string `json:"detail"`
+}
+
+// SpecDiff is the list of changes from an old spec to a new one.
+type SpecDiff struct {
+	Changes []Change `json:"changes"`
+}
+
+// HasBreaking reports whether any change is breaking.
+func (d *SpecDiff) HasBreaking() bool {
+	for _, c := range d.Changes {
+		if c.Severity == SeverityBreaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of changes with the given severity.
+func (d *SpecDiff) Count(s Severity) int {
+	n := 0
+	for _, c := range d.Changes {
+		if c.Severity == s {
+			n++
+		}
+	}
+	return n
+}
+
+func (d *SpecDiff) add(sev Severity, kind, subject, format string, args ...interface{}) {
+	d.Changes = append(d.Changes, Change{
+		Severity: sev,
+		Kind:     kind,
+		Subject:  subject,
+		Detail:   fmt.Sprintf(format, args...),
+	})
+}
+
+// Diff compares two contract specs and classifies every interface change.
+// Functions and types are matched by name; a removed function whose exact
+// signature reappears under a new name is reported as a rename.
+func Diff(oldSpec, newSpec *ContractSpec) *SpecDiff {
+	d := &SpecDiff{}
+	d.diffFunctions(oldSpec.Functions, newSpec.Functions)
+	d.diffStructs(oldSpec.Structs, newSpec.Structs)
+	d.diffEnums(oldSpec.Enums, newSpec.Enums)
+	d.diffUnions(oldSpec.Unions, newSpec.Unions)
+	d.diffErrorEnums(oldSpec.ErrorEnums, newSpec.ErrorEnums)
+	return d
+}
+
+// ─── functions ───────────────────────────────────────────────────────────────
+
+func (d *SpecDiff) diffFunctions(oldFns, newFns []xdr.ScSpecFunctionV0) {
+	oldByName := make(map[string]xdr.ScSpecFunctionV0, len(oldFns))
+	for _, fn := range oldFns {
+		oldByName[string(fn.Name)] = fn
+	}
+	newByName := make(map[string]xdr.ScSpecFunctionV0, len(newFns))
+	for _, fn := range newFns {
+		newByName[string(fn.Name)] = fn
+	}
+
+	var added []xdr.ScSpecFunctionV0
+	for _, fn := range newFns {
+		if _, ok := oldByName[string(fn.Name)]; !ok {
+			added = append(added, fn)
+		}
+	}
+
+	for _, oldFn := range oldFns {
+		name := string(oldFn.Name)
+		newFn, ok := newByName[name]
+		if ok {
+			d.diffSignature("fn "+name, oldFn, newFn)
+			continue
+		}
+		if i := indexOfSignature(added, functionSignature(oldFn)); i >= 0 {
+			d.add(SeverityBreaking, ChangeFunctionRenamed, "fn "+name,
+				"renamed to %s; callers using the old name will fail", added[i].Name)
+			added = append(added[:i], added[i+1:]...)
+			continue
+		}
+		d.add(SeverityBreaking, ChangeFunctionRemoved, "fn "+name, "function removed: %s", formatFunction(oldFn))
+	}
+
+	for _, fn := range added {
+		d.add(SeverityNonBreaking, ChangeFunctionAdded, "fn "+string(fn.Name), "function added: %s", formatFunction(fn))
+	}
+}
+
+func (d *SpecDiff) diffSignature(subject string, oldFn, newFn xdr.ScSpecFunctionV0) {
+	if len(oldFn.Inputs) != len(newFn.Inputs) {
+		d.add(SeverityBreaking, ChangeParamCount, subject,
+			"parameter count changed from %d to %d", len(oldFn.Inputs), len(newFn.Inputs))
+	} else {
+		for i := range oldFn.Inputs {
+			o, n := oldFn.Inputs[i], newFn.Inputs[i]
+			ot, nt := FormatTypeDef(o.Type), FormatTypeDef(n.Type)
+			if ot != nt {
+				d.add(SeverityBreaking, ChangeParamType, subject,
+					"parameter %d (%s) type changed from %s to %s", i, n.Name, ot, nt)
+			} else if o.Name != n.Name {
+				// Arguments are positional on chain; only generated bindings see names.
+				d.add(SeverityNonBreaking, ChangeParamRenamed, subject,
+					"parameter %d renamed from %s to %s", i, o.Name, n.Name)
+			}
+		}
+	}
+
+	if ot, nt := formatOutputs(oldFn.Outputs), formatOutputs(newFn.Outputs); ot != nt {
+		d.add(SeverityBreaking, ChangeReturnType, subject, "return type changed from %s to %s", ot, nt)
+	}
+}
+
+func functionSignature(fn xdr.ScSpecFunctionV0) string {
+	types := make([]string, len(fn.Inputs))
+	for i, in := range fn.Inputs {
+		types[i] = FormatTypeDef(in.Type)
+	}
+	return "(" + strings.Join(types, ", ") + ") -> " + formatOutputs(fn.Outputs)
+}
+
+func indexOfSignature(fns []xdr.ScSpecFunctionV0, sig string) int {
+	for i, fn := range fns {
+		if functionSignature(fn) == sig {
+			return i
+		}
+	}
+	return -1
+}
+
+func formatOutputs(outputs []xdr.ScSpecTypeDef) string {
+	if len(outputs) == 0 {
+		return "()"
+	}
+	types := make([]string, len(outputs))
+	for i, o := range outputs {
+		types[i] = FormatTypeDef(o)
+	}
+	return strings.Join(types, ", ")
+}
+
+// ─── user-defined types ──────────────────────────────────────────────────────
+
+func (d *SpecDiff) diffStructs(oldStructs, newStructs []xdr.ScSpecUdtStructV0) {
+	newByName := make(map[string]xdr.ScSpecUdtStructV0, len(newStructs))
+	for _, s := range newStructs {
+		newByName[s.Name] = s
+	}
+	seen := make(map[string]bool, len(oldStructs))
+
+	for _, o := range oldStructs {
+		seen[o.Name] = true
+		subject := "struct " + o.Name
+		n, ok := newByName[o.Name]
+		if !ok {
+			d.add(SeverityBreaking, ChangeTypeRemoved, subject, "struct removed")
+			continue
+		}
+
+		oldFields := make(map[string]string, len(o.Fields))
+		for _, f := range o.Fields {
+			oldFields[f.Name] = FormatTypeDef(f.Type)
+		}
+		newFields := make(map[string]string, len(n.Fields))
+		for _, f := range n.Fields {
+			newFields[f.Name] = FormatTypeDef(f.Type)
+		}
+
+		// Structs are encoded as maps keyed by every field name, so values
+		// written with the old layout no longer decode after any field change.
+		for _, f := range o.Fields {
+			nt, ok := newFields[f.Name]
+			switch {
+			case !ok:
+				d.add(SeverityBreaking, ChangeFieldRemoved, subject, "field %s removed", f.Name)
+			case nt != oldFields[f.Name]:
+				d.add(SeverityBreaking, ChangeFieldType, subject,
+					"field %s type changed from %s to %s", f.Name, oldFields[f.Name], nt)
+			}
+		}
+		for _, f := range n.Fields {
+			if _, ok := oldFields[f.Name]; !ok {
+				d.add(SeverityBreaking, ChangeFieldAdded, subject,
+					"field %s: %s added; existing values lack it", f.Name, newFields[f.Name])
+			}
+		}
+	}
+
+	for _, n := range newStructs {
+		if !seen[n.Name] {
+			d.add(SeverityNonBreaking, ChangeTypeAdded, "struct "+n.Name, "struct added")
+		}
+	}
+}
+
+func (d *SpecDiff) diffEnums(oldEnums, newEnums []xdr.ScSpecUdtEnumV0) {
+	newByName := make(map[string]xdr.ScSpecUdtEnumV0, len(newEnums))
+	for _, e := range newEnums {
+		newByName[e.Name] = e
+	}
+	seen := make(map[string]bool, len(oldEnums))
+
+	for _, o := range oldEnums {
+		seen[o.Name] = true
+		subject := "enum " + o.Name
+		n, ok := newByName[o.Name]
+		if !ok {
+			d.add(SeverityBreaking, ChangeTypeRemoved, subject, "enum removed")
+			continue
+		}
+
+		newValues := make(map[string]uint32, len(n.Cases))
+		for _, c := range n.Cases {
+			newValues[c.Name] = uint32(c.Value)
+		}
+		oldNames := make(map[string]bool, len(o.Cases))
+		for _, c := range o.Cases {
+			oldNames[c.Name] = true
+			nv, ok := newValues[c.Name]
+			switch {
+			case !ok:
+				d.add(SeverityBreaking, ChangeVariantRemoved, subject, "variant %s = %d removed", c.Name, c.Value)
+			case nv != uint32(c.Value):
+				d.add(SeverityBreaking, ChangeVariantRenumbered, subject,
+					"variant %s renumbered from %d to %d", c.Name, c.Value, nv)
+			}
+		}
+		for _, c := range n.Cases {
+			if !oldNames[c.Name] {
+				d.add(SeverityNonBreaking, ChangeVariantAdded, subject, "variant %s = %d added", c.Name, c.Value)
+			}
+		}
+	}
+
+	for _, n := range newEnums {
+		if !seen[n.Name] {
+			d.add(SeverityNonBreaking, ChangeTypeAdded, "enum "+n.Name, "enum added")
+		}
+	}
+}
+
+func (d *SpecDiff) diffUnions(oldUnions, newUnions []xdr.ScSpecUdtUnionV0) {
+	newByName := make(map[string]xdr.ScSpecUdtUnionV0, len(newUnions))
+	for _, u := range newUnions {
+		newByName[u.Name] = u
+	}
+	seen := make(map[string]bool, len(oldUnions))
+
+	for _, o := range oldUnions {
+		seen[o.Name] = true
+		subject := "union " + o.Name
+		n, ok := newByName[o.Name]
+		if !ok {
+			d.add(SeverityBreaking, ChangeTypeRemoved, subject, "union removed")
+			continue
+		}
+
+		oldCases := unionCases(o)
+		newCases := unionCases(n)
+		for _, name := range sortedKeys(oldCases) {
+			nt, ok := newCases[name]
+			switch {
+			case !ok:
+				d.add(SeverityBreaking, ChangeVariantRemoved, subject, "variant %s removed", name)
+			case nt != oldCases[name]:
+				d.add(SeverityBreaking, ChangeVariantType, subject,
+					"variant %s changed from %s to %s", name, oldCases[name], nt)
+			}
+		}
+		for _, name := range sortedKeys(newCases) {
+			if _, ok := oldCases[name]; !ok {
+				d.add(SeverityNonBreaking, ChangeVariantAdded, subject, "variant %s added", name+newCases[name])
+			}
+		}
+	}
+
+	for _, n := range newUnions {
+		if !seen[n.Name] {
+			d.add(SeverityNonBreaking, ChangeTypeAdded, "union "+n.Name, "union added")
+		}
+	}
+}
+
+// unionCases maps each case name to its payload description ("" for void).
+func unionCases(u xdr.ScSpecUdtUnionV0) map[string]string {
+	out := make(map[string]string, len(u.Cases))
+	for _, c := range u.Cases {
+		switch c.Kind {
+		case xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseVoidV0:
+			out[c.VoidCase.Name] = ""
+		case xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0:
+			types := make([]string, len(c.TupleCase.Type))
+			for i, t := range c.TupleCase.Type {
+				types[i] = FormatTypeDef(t)
+			}
+			out[c.TupleCase.Name] = "(" + strings.Join(types, ", ") + ")"
+		}
+	}
+	return out
+}
+
+func (d *SpecDiff) diffErrorEnums(oldEnums, newEnums []xdr.ScSpecUdtErrorEnumV0) {
+	newByName := make(map[string]xdr.ScSpecUdtErrorEnumV0, len(newEnums))
+	for _, e := range newEnums {
+		newByName[e.Name] = e
+	}
+	seen := make(map[string]bool, len(oldEnums))
+
+	for _, o := range oldEnums {
+		seen[o.Name] = true
+		subject := "error " + o.Name
+		n, ok := newByName[o.Name]
+		if !ok {
+			d.add(SeverityBreaking, ChangeTypeRemoved, subject, "error enum removed")
+			continue
+		}
+
+		newCodes := make(map[string]uint32, len(n.Cases))
+		for _, c := range n.Cases {
+			newCodes[c.Name] = uint32(c.Value)
+		}
+		oldNames := make(map[string]bool, len(o.Cases))
+		for _, c := range o.Cases {
+			oldNames[c.Name] = true
+			code, ok := newCodes[c.Name]
+			switch {
+			case !ok:
+				d.add(SeverityBreaking, ChangeErrorRemoved, subject, "error %s (#%d) removed", c.Name, c.Value)
+			case code != uint32(c.Value):
+				d.add(SeverityBreaking, ChangeErrorCode, subject,
+					"error %s code changed from #%d to #%d", c.Name, c.Value, code)
+			}
+		}
+		for _, c := range n.Cases {
+			if !oldNames[c.Name] {
+				d.add(SeverityNonBreaking, ChangeErrorAdded, subject, "error %s (#%d) added", c.Name, c.Value)
+			}
+		}
+	}
+
+	for _, n := range newEnums {
+		if !seen[n.Name] {
+			d.add(SeverityNonBreaking, ChangeTypeAdded, "error "+n.Name, "error enum added")
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ─── output ──────────────────────────────────────────────────────────────────
+
+// FormatDiffText renders a SpecDiff with breaking changes listed first.
+func FormatDiffText(d *SpecDiff) string {
+	if len(d.Changes) == 0 {
+		return "No interface changes.\n"
+	}
+
+	var b strings.Builder
+	labels := map[Severity]string{SeverityBreaking: "Breaking", SeverityNonBreaking: "Non-breaking"}
+	for _, sev := range []Severity{SeverityBreaking, SeverityNonBreaking} {
+		n := d.Count(sev)
+		if n == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s changes (%d):\n", labels[sev], n)
+		for _, c := range d.Changes {
+			if c.Severity == sev {
+				fmt.Fprintf(&b, "  %-24s %s\n", c.Subject, c.Detail)
+			}
+		}
+	}
+	return b.String()
+}
+
+// FormatDiffJSON renders a SpecDiff as indented JSON.
+func FormatDiffJSON(d *SpecDiff) (string, error) {
+	out := struct {
+		Breaking    int      `json:"breaking"`
+		NonBreaking int      `json:"non_breaking"`
+		Changes     []Change `json:"changes"`
+	}{
+		Breaking:    d.Count(SeverityBreaking),
+		NonBreaking: d.Count(SeverityNonBreaking),
+		Changes:     d.Changes,
+	}
+	if out.Changes == nil {
+		out.Changes = []Change{}
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
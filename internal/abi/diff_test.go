@@ -0,0 +1,147 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package abi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func typeDef(t xdr.ScSpecType) xdr.ScSpecTypeDef { return xdr.ScSpecTypeDef{Type: t} }
+
+func fn(name string, out xdr.ScSpecType, inputs ...xdr.ScSpecFunctionInputV0) xdr.ScSpecFunctionV0 {
+	return xdr.ScSpecFunctionV0{Name: xdr.ScSymbol(name), Inputs: inputs, Outputs: []xdr.ScSpecTypeDef{typeDef(out)}}
+}
+
+func input(name string, t xdr.ScSpecType) xdr.ScSpecFunctionInputV0 {
+	return xdr.ScSpecFunctionInputV0{Name: name, Type: typeDef(t)}
+}
+
+func kinds(d *SpecDiff, sev Severity) []string {
+	var out []string
+	for _, c := range d.Changes {
+		if c.Severity == sev {
+			out = append(out, c.Kind)
+		}
+	}
+	return out
+}
+
+func TestDiff_Identical(t *testing.T) {
+	spec := &ContractSpec{Functions: []xdr.ScSpecFunctionV0{fn("hello", xdr.ScSpecTypeScSpecTypeVoid)}}
+	d := Diff(spec, spec)
+	assert.Empty(t, d.Changes)
+	assert.False(t, d.HasBreaking())
+	assert.Equal(t, "No interface changes.\n", FormatDiffText(d))
+}
+
+func TestDiff_Functions(t *testing.T) {
+	oldSpec := &ContractSpec{Functions: []xdr.ScSpecFunctionV0{
+		fn("transfer", xdr.ScSpecTypeScSpecTypeVoid, input("to", xdr.ScSpecTypeScSpecTypeAddress), input("amount", xdr.ScSpecTypeScSpecTypeI128)),
+		fn("balance", xdr.ScSpecTypeScSpecTypeI128, input("id", xdr.ScSpecTypeScSpecTypeAddress)),
+		fn("burn", xdr.ScSpecTypeScSpecTypeVoid, input("amount", xdr.ScSpecTypeScSpecTypeI128)),
+		fn("legacy", xdr.ScSpecTypeScSpecTypeBool, input("x", xdr.ScSpecTypeScSpecTypeU32), input("y", xdr.ScSpecTypeScSpecTypeString)),
+	}}
+	newSpec := &ContractSpec{Functions: []xdr.ScSpecFunctionV0{
+		fn("transfer", xdr.ScSpecTypeScSpecTypeVoid, input("dest", xdr.ScSpecTypeScSpecTypeAddress), input("amount", xdr.ScSpecTypeScSpecTypeI64)),
+		fn("balance", xdr.ScSpecTypeScSpecTypeU64, input("id", xdr.ScSpecTypeScSpecTypeAddress)),
+		fn("burn_from", xdr.ScSpecTypeScSpecTypeVoid, input("amount", xdr.ScSpecTypeScSpecTypeI128)),
+		fn("version", xdr.ScSpecTypeScSpecTypeU32),
+	}}
+
+	d := Diff(oldSpec, newSpec)
+	assert.ElementsMatch(t, []string{
+		ChangeParamType, ChangeReturnType, ChangeFunctionRenamed, ChangeFunctionRemoved,
+	}, kinds(d, SeverityBreaking))
+	assert.ElementsMatch(t, []string{ChangeParamRenamed, ChangeFunctionAdded}, kinds(d, SeverityNonBreaking))
+	assert.True(t, d.HasBreaking())
+
+	for _, c := range d.Changes {
+		if c.Kind == ChangeFunctionRenamed {
+			assert.Equal(t, "fn burn", c.Subject)
+			assert.Contains(t, c.Detail, "burn_from")
+		}
+	}
+}
+
+func TestDiff_UserDefinedTypes(t *testing.T) {
+	oldSpec := &ContractSpec{
+		Structs: []xdr.ScSpecUdtStructV0{{Name: "Config", Fields: []xdr.ScSpecUdtStructFieldV0{
+			{Name: "admin", Type: typeDef(xdr.ScSpecTypeScSpecTypeAddress)},
+			{Name: "fee", Type: typeDef(xdr.ScSpecTypeScSpecTypeU32)},
+		}}},
+		Enums: []xdr.ScSpecUdtEnumV0{{Name: "Kind", Cases: []xdr.ScSpecUdtEnumCaseV0{
+			{Name: "A", Value: 0}, {Name: "B", Value: 1},
+		}}},
+		Unions: []xdr.ScSpecUdtUnionV0{{Name: "Key", Cases: []xdr.ScSpecUdtUnionCaseV0{
+			{Kind: xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseVoidV0, VoidCase: &xdr.ScSpecUdtUnionCaseVoidV0{Name: "Admin"}},
+		}}},
+		ErrorEnums: []xdr.ScSpecUdtErrorEnumV0{{Name: "Error", Cases: []xdr.ScSpecUdtErrorEnumCaseV0{
+			{Name: "NotAuthorized", Value: 1}, {Name: "Overflow", Value: 2},
+		}}},
+	}
+	newSpec := &ContractSpec{
+		Structs: []xdr.ScSpecUdtStructV0{{Name: "Config", Fields: []xdr.ScSpecUdtStructFieldV0{
+			{Name: "admin", Type: typeDef(xdr.ScSpecTypeScSpecTypeAddress)},
+			{Name: "fee", Type: typeDef(xdr.ScSpecTypeScSpecTypeU64)},
+			{Name: "paused", Type: typeDef(xdr.ScSpecTypeScSpecTypeBool)},
+		}}},
+		Enums: []xdr.ScSpecUdtEnumV0{{Name: "Kind", Cases: []xdr.ScSpecUdtEnumCaseV0{
+			{Name: "A", Value: 0}, {Name: "B", Value: 2}, {Name: "C", Value: 3},
+		}}},
+		Unions: []xdr.ScSpecUdtUnionV0{{Name: "Key", Cases: []xdr.ScSpecUdtUnionCaseV0{
+			{Kind: xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseVoidV0, VoidCase: &xdr.ScSpecUdtUnionCaseVoidV0{Name: "Admin"}},
+			{Kind: xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0, TupleCase: &xdr.ScSpecUdtUnionCaseTupleV0{
+				Name: "Balance", Type: []xdr.ScSpecTypeDef{typeDef(xdr.ScSpecTypeScSpecTypeAddress)},
+			}},
+		}}},
+		ErrorEnums: []xdr.ScSpecUdtErrorEnumV0{{Name: "Error", Cases: []xdr.ScSpecUdtErrorEnumCaseV0{
+			{Name: "NotAuthorized", Value: 1}, {Name: "Overflow", Value: 3}, {Name: "Paused", Value: 4},
+		}}},
+	}
+
+	d := Diff(oldSpec, newSpec)
+	assert.ElementsMatch(t, []string{
+		ChangeFieldType, ChangeFieldAdded, ChangeVariantRenumbered, ChangeErrorCode,
+	}, kinds(d, SeverityBreaking))
+	assert.ElementsMatch(t, []string{ChangeVariantAdded, ChangeVariantAdded, ChangeErrorAdded}, kinds(d, SeverityNonBreaking))
+}
+
+func TestDiff_RemovedAndAddedTypes(t *testing.T) {
+	oldSpec := &ContractSpec{Structs: []xdr.ScSpecUdtStructV0{{Name: "Old"}}}
+	newSpec := &ContractSpec{Enums: []xdr.ScSpecUdtEnumV0{{Name: "New"}}}
+
+	d := Diff(oldSpec, newSpec)
+	require.Len(t, d.Changes, 2)
+	assert.Equal(t, Change{Severity: SeverityBreaking, Kind: ChangeTypeRemoved, Subject: "struct Old", Detail: "struct removed"}, d.Changes[0])
+	assert.Equal(t, SeverityNonBreaking, d.Changes[1].Severity)
+}
+
+func TestFormatDiff(t *testing.T) {
+	d := Diff(
+		&ContractSpec{Functions: []xdr.ScSpecFunctionV0{fn("a", xdr.ScSpecTypeScSpecTypeVoid)}},
+		&ContractSpec{Functions: []xdr.ScSpecFunctionV0{fn("b", xdr.ScSpecTypeScSpecTypeU32)}},
+	)
+
+	text := FormatDiffText(d)
+	assert.True(t, strings.HasPrefix(text, "Breaking changes (1):"))
+	assert.Contains(t, text, "Non-breaking changes (1):")
+
+	out, err := FormatDiffJSON(d)
+	require.NoError(t, err)
+	var decoded struct {
+		Breaking    int      `json:"breaking"`
+		NonBreaking int      `json:"non_breaking"`
+		Changes     []Change `json:"changes"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+	assert.Equal(t, 1, decoded.Breaking)
+	assert.Equal(t, 1, decoded.NonBreaking)
+	assert.Len(t, decoded.Changes, 2)
+}
@@ -0,0 +1,231 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package abi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// Custom section names holding contract metadata.
+const (
+	EnvMetaSection = "contractenvmetav0"
+	MetaSection    = "contractmetav0"
+)
+
+// Well-known contractmetav0 keys written by the Rust SDK.
+const (
+	MetaKeyRustcVersion = "rsver"
+	MetaKeySDKVersion   = "rssdkver"
+)
+
+// MetaEntry is a key-value pair from the contractmetav0 section.
+type MetaEntry struct {
+	Key string `json:"key"`
+	Val string `json:"value"`
+}
+
+// ContractMeta is the metadata a contract was built with.
+type ContractMeta struct {
+	// HasInterfaceVersion is false when the contractenvmetav0 section is
+	// missing or empty.
+	HasInterfaceVersion bool `json:"has_interface_version"`
+	// Protocol is the ledger protocol version the contract targets.
+	Protocol uint32 `json:"protocol"`
+	// PreRelease is non-zero for contracts built against a pre-release SDK.
+	PreRelease   uint32 `json:"pre_release"`
+	RustcVersion string `json:"rustc_version,omitempty"`
+	SDKVersion   string `json:"sdk_version,omitempty"`
+	// Custom holds every other contractmetav0 entry, in section order.
+	Custom []MetaEntry `json:"custom,omitempty"`
+}
+
+// DecodeContractMeta decodes the payloads of the contractenvmetav0 and
+// contractmetav0 sections. Either may be nil.
+func DecodeContractMeta(envMeta, meta []byte) (*ContractMeta, error) {
+	out := &ContractMeta{}
+
+	reader := bytes.NewReader(envMeta)
+	for reader.Len() > 0 {
+		var entry xdr.ScEnvMetaEntry
+		if _, err := xdr.Unmarshal(reader, &entry); err != nil {
+			return nil, fmt.Errorf("decoding env meta entry: %w", err)
+		}
+		if entry.Kind == xdr.ScEnvMetaKindScEnvMetaKindInterfaceVersion && entry.InterfaceVersion != nil {
+			out.HasInterfaceVersion = true
+			out.Protocol = uint32(entry.InterfaceVersion.Protocol)
+			out.PreRelease = uint32(entry.InterfaceVersion.PreRelease)
+		}
+	}
+
+	reader = bytes.NewReader(meta)
+	for reader.Len() > 0 {
+		var entry xdr.ScMetaEntry
+		if _, err := xdr.Unmarshal(reader, &entry); err != nil {
+			return nil, fmt.Errorf("decoding meta entry: %w", err)
+		}
+		if entry.Kind != xdr.ScMetaKindScMetaV0 || entry.V0 == nil {
+			continue
+		}
+		switch entry.V0.Key {
+		case MetaKeyRustcVersion:
+			out.RustcVersion = entry.V0.Val
+		case MetaKeySDKVersion:
+			out.SDKVersion = entry.V0.Val
+		default:
+			out.Custom = append(out.Custom, MetaEntry{Key: entry.V0.Key, Val: entry.V0.Val})
+		}
+	}
+
+	return out, nil
+}
+
+// ContractMetaFromWasm reads both metadata sections from a WASM binary.
+func ContractMetaFromWasm(wasmBytes []byte) (*ContractMeta, error) {
+	envMeta, err := ExtractCustomSection(wasmBytes, EnvMetaSection)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := ExtractCustomSection(wasmBytes, MetaSection)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeContractMeta(envMeta, meta)
+}
+
+// CheckCompatibility returns warnings when a contract built with m cannot be
+// expected to run on a ledger at the given protocol version. supported lists
+// the protocol versions the simulator can replay; it may be nil.
+func CheckCompatibility(m *ContractMeta, protocol uint32, supported []uint32) []string {
+	if !m.HasInterfaceVersion {
+		return []string{fmt.Sprintf("contract has no %s section; its interface version is unknown", EnvMetaSection)}
+	}
+
+	var warnings []string
+	if m.Protocol > protocol {
+		warnings = append(warnings, fmt.Sprintf(
+			"contract targets protocol %d but the ledger runs protocol %d; the host will refuse to instantiate it",
+			m.Protocol, protocol))
+	}
+	if m.PreRelease != 0 {
+		if m.Protocol != protocol {
+			warnings = append(warnings, fmt.Sprintf(
+				"contract was built against pre-release %d of protocol %d and only runs on exactly that protocol, not %d",
+				m.PreRelease, m.Protocol, protocol))
+		} else {
+			warnings = append(warnings, fmt.Sprintf(
+				"contract was built against pre-release %d of protocol %d; pre-release contracts are rejected on public networks",
+				m.PreRelease, m.Protocol))
+		}
+	}
+	if len(supported) > 0 && !containsVersion(supported, m.Protocol) {
+		warnings = append(warnings, fmt.Sprintf(
+			"protocol %d is not supported by the simulator (supported: %s)", m.Protocol, joinVersions(supported)))
+	}
+	return warnings
+}
+
+// hostIncompatibilityMarkers are fragments of host errors raised when a
+// contract's imports or interface version do not match the host.
+var hostIncompatibilityMarkers = []string{
+	"interface version",
+	"protocol version",
+	"unknown import",
+	"missing import",
+	"import not found",
+	"incompatible",
+	"newer than host",
+	"pre-release",
+}
+
+// LooksLikeHostIncompatibility reports whether a simulation error message
+// suggests the contract was built for a different host than it ran on.
+func LooksLikeHostIncompatibility(msg string) bool {
+	lower := strings.ToLower(msg)
+	for _, marker := range hostIncompatibilityMarkers {
+		if strings.Contains(lower, strings.ToLower(marker)) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatMetaText renders contract metadata and any compatibility warnings
+// for terminal display.
+func FormatMetaText(m *ContractMeta, warnings []string) string {
+	var b strings.Builder
+
+	b.WriteString("Environment:\n")
+	if m.HasInterfaceVersion {
+		fmt.Fprintf(&b, "  Protocol:    %d\n", m.Protocol)
+		if m.PreRelease != 0 {
+			fmt.Fprintf(&b, "  Pre-release: %d\n", m.PreRelease)
+		}
+	} else {
+		b.WriteString("  Protocol:    unknown\n")
+	}
+
+	b.WriteString("\nBuild:\n")
+	fmt.Fprintf(&b, "  SDK:   %s\n", orUnknown(m.SDKVersion))
+	fmt.Fprintf(&b, "  rustc: %s\n", orUnknown(m.RustcVersion))
+
+	if len(m.Custom) > 0 {
+		b.WriteString("\nCustom metadata:\n")
+		for _, e := range m.Custom {
+			fmt.Fprintf(&b, "  %s = %s\n", e.Key, e.Val)
+		}
+	}
+
+	if len(warnings) > 0 {
+		b.WriteString("\nWarnings:\n")
+		for _, w := range warnings {
+			fmt.Fprintf(&b, "  - %s\n", w)
+		}
+	}
+	return b.String()
+}
+
+// FormatMetaJSON renders contract metadata and warnings as indented JSON.
+func FormatMetaJSON(m *ContractMeta, warnings []string) (string, error) {
+	out := struct {
+		*ContractMeta
+		Warnings []string `json:"warnings"`
+	}{m, warnings}
+	if out.Warnings == nil {
+		out.Warnings = []string{}
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+func containsVersion(versions []uint32, v uint32) bool {
+	for _, x := range versions {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func joinVersions(versions []uint32) string {
+	parts := make([]string, len(versions))
+	for i, v := range versions {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
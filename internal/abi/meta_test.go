@@ -0,0 +1,98 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package abi
+
+import (
+	"testing"
+
+	"github.com/dotandev/hintents/internal/wasm"
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envMeta(t *testing.T, protocol, preRelease uint32) []byte {
+	t.Helper()
+	entry := xdr.ScEnvMetaEntry{
+		Kind: xdr.ScEnvMetaKindScEnvMetaKindInterfaceVersion,
+		InterfaceVersion: &xdr.ScEnvMetaEntryInterfaceVersion{
+			Protocol:   xdr.Uint32(protocol),
+			PreRelease: xdr.Uint32(preRelease),
+		},
+	}
+	b, err := entry.MarshalBinary()
+	require.NoError(t, err)
+	return b
+}
+
+func metaEntries(t *testing.T, kv ...string) []byte {
+	t.Helper()
+	var out []byte
+	for i := 0; i+1 < len(kv); i += 2 {
+		entry := xdr.ScMetaEntry{Kind: xdr.ScMetaKindScMetaV0, V0: &xdr.ScMetaV0{Key: kv[i], Val: kv[i+1]}}
+		b, err := entry.MarshalBinary()
+		require.NoError(t, err)
+		out = append(out, b...)
+	}
+	return out
+}
+
+func TestContractMetaFromWasm(t *testing.T) {
+	mod := &wasm.Module{Customs: []wasm.Custom{
+		{Name: EnvMetaSection, Data: envMeta(t, 22, 0)},
+		{Name: MetaSection, Data: metaEntries(t,
+			MetaKeyRustcVersion, "1.81.0",
+			MetaKeySDKVersion, "22.0.7#211569aa",
+			"source_repo", "github:example/token",
+		)},
+	}}
+	bin, err := mod.Encode()
+	require.NoError(t, err)
+
+	meta, err := ContractMetaFromWasm(bin)
+	require.NoError(t, err)
+	assert.True(t, meta.HasInterfaceVersion)
+	assert.Equal(t, uint32(22), meta.Protocol)
+	assert.Equal(t, "1.81.0", meta.RustcVersion)
+	assert.Equal(t, "22.0.7#211569aa", meta.SDKVersion)
+	assert.Equal(t, []MetaEntry{{Key: "source_repo", Val: "github:example/token"}}, meta.Custom)
+
+	text := FormatMetaText(meta, nil)
+	assert.Contains(t, text, "Protocol:    22")
+	assert.Contains(t, text, "source_repo = github:example/token")
+}
+
+func TestContractMetaFromWasm_NoSections(t *testing.T) {
+	bin, err := (&wasm.Module{}).Encode()
+	require.NoError(t, err)
+
+	meta, err := ContractMetaFromWasm(bin)
+	require.NoError(t, err)
+	assert.False(t, meta.HasInterfaceVersion)
+	assert.Len(t, CheckCompatibility(meta, 22, nil), 1)
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	supported := []uint32{20, 21, 22, 23}
+
+	assert.Empty(t, CheckCompatibility(&ContractMeta{HasInterfaceVersion: true, Protocol: 21}, 22, supported))
+
+	newer := CheckCompatibility(&ContractMeta{HasInterfaceVersion: true, Protocol: 23}, 22, supported)
+	require.Len(t, newer, 1)
+	assert.Contains(t, newer[0], "targets protocol 23")
+
+	pre := CheckCompatibility(&ContractMeta{HasInterfaceVersion: true, Protocol: 21, PreRelease: 3}, 22, supported)
+	require.Len(t, pre, 1)
+	assert.Contains(t, pre[0], "pre-release 3")
+
+	unsupported := CheckCompatibility(&ContractMeta{HasInterfaceVersion: true, Protocol: 19}, 22, supported)
+	require.Len(t, unsupported, 1)
+	assert.Contains(t, unsupported[0], "not supported by the simulator")
+}
+
+func TestLooksLikeHostIncompatibility(t *testing.T) {
+	assert.True(t, LooksLikeHostIncompatibility("HostError: contract protocol number is newer than host"))
+	assert.True(t, LooksLikeHostIncompatibility("Wasm linking failed: unknown import x._"))
+	assert.False(t, LooksLikeHostIncompatibility("HostError: Error(Contract, #3)"))
+}
@@ -0,0 +1,281 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package abi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// Storage durabilities reported in StorageEntry.Durability.
+const (
+	StorageInstance   = "instance"
+	StoragePersistent = "persistent"
+	StorageTemporary  = "temporary"
+)
+
+// Storage finding kinds.
+const (
+	FindingUndecodableValue = "undecodable_value"
+	FindingUnreadKey        = "unread_key"
+)
+
+// StorageEntry is one key/value pair from a contract's storage.
+type StorageEntry struct {
+	Durability string
+	Key        xdr.ScVal
+	Val        xdr.ScVal
+}
+
+// StorageFinding describes a stored entry the new code cannot handle.
+type StorageFinding struct {
+	Kind       string `json:"kind"`
+	Durability string `json:"durability"`
+	Key        string `json:"key"`
+	// Type is the user-defined type the entry was identified as under the
+	// old spec.
+	Type   string `json:"type,omitempty"`
+	Detail string `json:"detail"`
+}
+
+// StorageReport summarises a storage layout compatibility check.
+type StorageReport struct {
+	Checked int `json:"checked"`
+	// Typed counts entries whose key or value was matched to a spec type;
+	// the rest use shapes the spec does not describe and cannot be checked.
+	Typed    int              `json:"typed"`
+	Findings []StorageFinding `json:"findings"`
+	// OldSpecError is set when the deployed contract's spec could not be
+	// loaded; stored entries then cannot be matched to types and the
+	// comparison is skipped.
+	OldSpecError string `json:"old_spec_error,omitempty"`
+}
+
+// Compatible reports whether no stored value would fail to decode.
+func (r *StorageReport) Compatible() bool {
+	for _, f := range r.Findings {
+		if f.Kind == FindingUndecodableValue {
+			return false
+		}
+	}
+	return true
+}
+
+// StorageEntriesFromLedger collects the storage of one contract from a map of
+// base64 LedgerKey to base64 LedgerEntry, as returned by getLedgerEntries or
+// stored in a snapshot. Instance storage is expanded from the instance entry.
+func StorageEntriesFromLedger(entries map[string]string, contract xdr.ContractId) ([]StorageEntry, error) {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out []StorageEntry
+	for _, k := range keys {
+		raw, err := base64.StdEncoding.DecodeString(entries[k])
+		if err != nil {
+			return nil, fmt.Errorf("decode ledger entry: %w", err)
+		}
+		var entry xdr.LedgerEntry
+		if err := entry.UnmarshalBinary(raw); err != nil {
+			return nil, fmt.Errorf("unmarshal ledger entry: %w", err)
+		}
+		cd := entry.Data.ContractData
+		if entry.Data.Type != xdr.LedgerEntryTypeContractData || cd == nil {
+			continue
+		}
+		if cd.Contract.ContractId == nil || *cd.Contract.ContractId != contract {
+			continue
+		}
+
+		if cd.Key.Type == xdr.ScValTypeScvLedgerKeyContractInstance {
+			if cd.Val.Instance != nil && cd.Val.Instance.Storage != nil {
+				for _, e := range *cd.Val.Instance.Storage {
+					out = append(out, StorageEntry{Durability: StorageInstance, Key: e.Key, Val: e.Val})
+				}
+			}
+			continue
+		}
+
+		durability := StoragePersistent
+		if cd.Durability == xdr.ContractDataDurabilityTemporary {
+			durability = StorageTemporary
+		}
+		out = append(out, StorageEntry{Durability: durability, Key: cd.Key, Val: cd.Val})
+	}
+	return out, nil
+}
+
+// CheckStorage decodes every stored entry against newSpec. oldSpec, the spec
+// of the code that wrote the data, identifies which user-defined type each
+// key and value was written as; it may be nil when unavailable, in which
+// case only keys are checked against the new spec.
+//
+// Values are identified by shape: maps are matched against named structs and
+// symbol-tagged vectors against union cases. Plain scalars cannot be told
+// apart from integer enums and are not checked.
+func CheckStorage(oldSpec, newSpec *ContractSpec, entries []StorageEntry) *StorageReport {
+	if oldSpec == nil {
+		oldSpec = &ContractSpec{}
+	}
+	r := &StorageReport{Findings: []StorageFinding{}}
+
+	for _, e := range entries {
+		r.Checked++
+		key := FormatScVal(e.Key)
+		typed := false
+
+		oldKeyType := matchKeyType(oldSpec, e.Key)
+		newKeyType := matchKeyType(newSpec, e.Key)
+		if oldKeyType != "" || newKeyType != "" {
+			typed = true
+		}
+		if oldKeyType != "" && newKeyType == "" {
+			detail := fmt.Sprintf("key was written as %s but no type in the new spec matches it", oldKeyType)
+			if err := checkNamedUDT(newSpec, e.Key, oldKeyType); err != nil && hasUDT(newSpec, oldKeyType) {
+				detail = fmt.Sprintf("key no longer matches %s: %v", oldKeyType, err)
+			}
+			r.Findings = append(r.Findings, StorageFinding{
+				Kind: FindingUnreadKey, Durability: e.Durability, Key: key, Type: oldKeyType, Detail: detail,
+			})
+		}
+
+		if valType := matchValueType(oldSpec, e.Val); valType != "" {
+			typed = true
+			if err := checkNamedUDT(newSpec, e.Val, valType); err != nil {
+				r.Findings = append(r.Findings, StorageFinding{
+					Kind: FindingUndecodableValue, Durability: e.Durability, Key: key, Type: valType, Detail: err.Error(),
+				})
+			}
+		}
+
+		if typed {
+			r.Typed++
+		}
+	}
+	return r
+}
+
+// matchKeyType returns the union whose case encoding the key uses, or "".
+func matchKeyType(spec *ContractSpec, key xdr.ScVal) string {
+	items := vecOf(key)
+	if key.Type != xdr.ScValTypeScvVec || len(items) == 0 || items[0].Type != xdr.ScValTypeScvSymbol {
+		return ""
+	}
+	for _, u := range spec.Unions {
+		if checkUnion(spec, key, u, 0) == nil {
+			return u.Name
+		}
+	}
+	return ""
+}
+
+// matchValueType returns the struct or union the value was encoded as, or "".
+func matchValueType(spec *ContractSpec, v xdr.ScVal) string {
+	switch v.Type {
+	case xdr.ScValTypeScvMap:
+		for _, s := range spec.Structs {
+			if !isTupleStruct(s) && checkStruct(spec, v, s, 0) == nil {
+				return s.Name
+			}
+		}
+	case xdr.ScValTypeScvVec:
+		return matchKeyType(spec, v)
+	}
+	return ""
+}
+
+func hasUDT(spec *ContractSpec, name string) bool {
+	for _, s := range spec.Structs {
+		if s.Name == name {
+			return true
+		}
+	}
+	for _, u := range spec.Unions {
+		if u.Name == name {
+			return true
+		}
+	}
+	for _, e := range spec.Enums {
+		if e.Name == name {
+			return true
+		}
+	}
+	for _, e := range spec.ErrorEnums {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func checkNamedUDT(spec *ContractSpec, v xdr.ScVal, name string) error {
+	if !hasUDT(spec, name) {
+		return fmt.Errorf("type %s was removed", name)
+	}
+	return checkUDT(spec, v, name, 0)
+}
+
+// FormatScVal renders a storage key or value compactly for reports.
+func FormatScVal(v xdr.ScVal) string {
+	switch v.Type {
+	case xdr.ScValTypeScvSymbol:
+		if v.Sym != nil {
+			return string(*v.Sym)
+		}
+	case xdr.ScValTypeScvVec:
+		items := vecOf(v)
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = FormatScVal(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	}
+	return v.String()
+}
+
+// FormatStorageText renders a StorageReport for the terminal.
+func FormatStorageText(r *StorageReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Entries checked: %d (%d matched to spec types)\n", r.Checked, r.Typed)
+	if r.OldSpecError != "" {
+		fmt.Fprintf(&b, "Comparison skipped: the deployed contract's spec could not be loaded (%s).\n", r.OldSpecError)
+	} else if len(r.Findings) == 0 {
+		b.WriteString("All typed entries decode under the new spec.\n")
+		return b.String()
+	}
+
+	for _, kind := range []string{FindingUndecodableValue, FindingUnreadKey} {
+		var lines []string
+		for _, f := range r.Findings {
+			if f.Kind == kind {
+				lines = append(lines, fmt.Sprintf("  [%s] %s (%s): %s", f.Durability, f.Key, f.Type, f.Detail))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		title := "Values that no longer decode"
+		if kind == FindingUnreadKey {
+			title = "Keys the new code never reads"
+		}
+		fmt.Fprintf(&b, "\n%s (%d):\n%s\n", title, len(lines), strings.Join(lines, "\n"))
+	}
+	return b.String()
+}
+
+// FormatStorageJSON renders a StorageReport as indented JSON.
+func FormatStorageJSON(r *StorageReport) (string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
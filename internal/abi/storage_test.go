@@ -0,0 +1,197 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package abi
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sym(s string) xdr.ScVal {
+	v := xdr.ScSymbol(s)
+	return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &v}
+}
+
+func u32(n uint32) xdr.ScVal {
+	v := xdr.Uint32(n)
+	return xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &v}
+}
+
+func vec(items ...xdr.ScVal) xdr.ScVal {
+	v := xdr.ScVec(items)
+	p := &v
+	return xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: &p}
+}
+
+func scMap(kv ...xdr.ScVal) xdr.ScVal {
+	var m xdr.ScMap
+	for i := 0; i+1 < len(kv); i += 2 {
+		m = append(m, xdr.ScMapEntry{Key: kv[i], Val: kv[i+1]})
+	}
+	p := &m
+	return xdr.ScVal{Type: xdr.ScValTypeScvMap, Map: &p}
+}
+
+func udt(name string) xdr.ScSpecTypeDef {
+	return xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeUdt, Udt: &xdr.ScSpecTypeUdt{Name: name}}
+}
+
+func dataKeyUnion(cases ...xdr.ScSpecUdtUnionCaseV0) xdr.ScSpecUdtUnionV0 {
+	return xdr.ScSpecUdtUnionV0{Name: "DataKey", Cases: cases}
+}
+
+func voidCase(name string) xdr.ScSpecUdtUnionCaseV0 {
+	return xdr.ScSpecUdtUnionCaseV0{Kind: xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseVoidV0, VoidCase: &xdr.ScSpecUdtUnionCaseVoidV0{Name: name}}
+}
+
+func tupleCase(name string, types ...xdr.ScSpecTypeDef) xdr.ScSpecUdtUnionCaseV0 {
+	return xdr.ScSpecUdtUnionCaseV0{Kind: xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0, TupleCase: &xdr.ScSpecUdtUnionCaseTupleV0{Name: name, Type: types}}
+}
+
+func configStruct(feeType xdr.ScSpecType) xdr.ScSpecUdtStructV0 {
+	return xdr.ScSpecUdtStructV0{Name: "Config", Fields: []xdr.ScSpecUdtStructFieldV0{
+		{Name: "fee", Type: typeDef(feeType)},
+		{Name: "paused", Type: typeDef(xdr.ScSpecTypeScSpecTypeBool)},
+	}}
+}
+
+func TestCheckValue(t *testing.T) {
+	spec := &ContractSpec{
+		Structs: []xdr.ScSpecUdtStructV0{configStruct(xdr.ScSpecTypeScSpecTypeU32)},
+		Enums:   []xdr.ScSpecUdtEnumV0{{Name: "Level", Cases: []xdr.ScSpecUdtEnumCaseV0{{Name: "Low", Value: 1}}}},
+		Unions:  []xdr.ScSpecUdtUnionV0{dataKeyUnion(voidCase("Admin"), tupleCase("Balance", typeDef(xdr.ScSpecTypeScSpecTypeU32)))},
+	}
+	b := true
+	cfg := scMap(sym("fee"), u32(5), sym("paused"), xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &b})
+
+	assert.NoError(t, CheckUDT(spec, cfg, "Config"))
+	assert.Error(t, CheckUDT(spec, scMap(sym("fee"), u32(5)), "Config"), "missing field")
+	assert.NoError(t, CheckUDT(spec, u32(1), "Level"))
+	assert.Error(t, CheckUDT(spec, u32(2), "Level"))
+	assert.NoError(t, CheckUDT(spec, vec(sym("Admin")), "DataKey"))
+	assert.NoError(t, CheckUDT(spec, vec(sym("Balance"), u32(1)), "DataKey"))
+	assert.Error(t, CheckUDT(spec, vec(sym("Balance"), sym("x")), "DataKey"))
+	assert.Error(t, CheckUDT(spec, vec(sym("Nonce")), "DataKey"))
+
+	vecType := xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeVec, Vec: &xdr.ScSpecTypeVec{ElementType: udt("Level")}}
+	assert.NoError(t, CheckValue(spec, vec(u32(1), u32(1)), vecType))
+	assert.Error(t, CheckValue(spec, vec(u32(1), u32(9)), vecType))
+
+	optType := xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeOption, Option: &xdr.ScSpecTypeOption{ValueType: udt("Config")}}
+	assert.NoError(t, CheckValue(spec, xdr.ScVal{Type: xdr.ScValTypeScvVoid}, optType))
+}
+
+func TestCheckStorage(t *testing.T) {
+	b := false
+	cfg := scMap(sym("fee"), u32(5), sym("paused"), xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &b})
+
+	oldSpec := &ContractSpec{
+		Structs: []xdr.ScSpecUdtStructV0{configStruct(xdr.ScSpecTypeScSpecTypeU32)},
+		Unions: []xdr.ScSpecUdtUnionV0{dataKeyUnion(
+			voidCase("Config"), voidCase("Legacy"), tupleCase("Balance", typeDef(xdr.ScSpecTypeScSpecTypeU32)),
+		)},
+	}
+	newSpec := &ContractSpec{
+		Structs: []xdr.ScSpecUdtStructV0{configStruct(xdr.ScSpecTypeScSpecTypeU64)},
+		Unions: []xdr.ScSpecUdtUnionV0{dataKeyUnion(
+			voidCase("Config"), tupleCase("Balance", typeDef(xdr.ScSpecTypeScSpecTypeU32)),
+		)},
+	}
+	entries := []StorageEntry{
+		{Durability: StorageInstance, Key: vec(sym("Config")), Val: cfg},
+		{Durability: StoragePersistent, Key: vec(sym("Balance"), u32(7)), Val: u32(100)},
+		{Durability: StoragePersistent, Key: vec(sym("Legacy")), Val: u32(1)},
+		{Durability: StorageInstance, Key: sym("COUNTER"), Val: u32(3)},
+	}
+
+	r := CheckStorage(oldSpec, newSpec, entries)
+	assert.Equal(t, 4, r.Checked)
+	assert.Equal(t, 3, r.Typed)
+	assert.False(t, r.Compatible())
+	require.Len(t, r.Findings, 2)
+
+	assert.Equal(t, FindingUndecodableValue, r.Findings[0].Kind)
+	assert.Equal(t, "[Config]", r.Findings[0].Key)
+	assert.Equal(t, "Config", r.Findings[0].Type)
+	assert.Contains(t, r.Findings[0].Detail, "Config.fee")
+
+	assert.Equal(t, FindingUnreadKey, r.Findings[1].Kind)
+	assert.Equal(t, "[Legacy]", r.Findings[1].Key)
+	assert.Contains(t, r.Findings[1].Detail, "no case Legacy")
+
+	assert.Contains(t, FormatStorageText(r), "Keys the new code never reads (1)")
+}
+
+func TestCheckStorage_RemovedType(t *testing.T) {
+	b := false
+	cfg := scMap(sym("fee"), u32(5), sym("paused"), xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &b})
+	oldSpec := &ContractSpec{Structs: []xdr.ScSpecUdtStructV0{configStruct(xdr.ScSpecTypeScSpecTypeU32)}}
+
+	r := CheckStorage(oldSpec, &ContractSpec{}, []StorageEntry{{Durability: StoragePersistent, Key: sym("CFG"), Val: cfg}})
+	require.Len(t, r.Findings, 1)
+	assert.Equal(t, "type Config was removed", r.Findings[0].Detail)
+}
+
+func TestFormatStorageText_OldSpecUnavailable(t *testing.T) {
+	r := &StorageReport{Checked: 3, OldSpecError: "no contractspecv0 section"}
+	text := FormatStorageText(r)
+	assert.Contains(t, text, "Comparison skipped")
+	assert.NotContains(t, text, "All typed entries decode")
+}
+
+func TestStorageEntriesFromLedger(t *testing.T) {
+	id := xdr.ContractId{7}
+	other := xdr.ContractId{8}
+	storage := xdr.ScMap{{Key: sym("COUNTER"), Val: u32(1)}}
+	instanceVal := xdr.ScVal{Type: xdr.ScValTypeScvContractInstance, Instance: &xdr.ScContractInstance{
+		Executable: xdr.ContractExecutable{Type: xdr.ContractExecutableTypeContractExecutableStellarAsset},
+		Storage:    &storage,
+	}}
+
+	mk := func(cid xdr.ContractId, key, val xdr.ScVal, dur xdr.ContractDataDurability) (string, string) {
+		entry := xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeContractData,
+			ContractData: &xdr.ContractDataEntry{
+				Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &cid},
+				Key:        key,
+				Durability: dur,
+				Val:        val,
+			},
+		}}
+		lk, err := entry.LedgerKey()
+		require.NoError(t, err)
+		kb, err := lk.MarshalBinary()
+		require.NoError(t, err)
+		eb, err := entry.MarshalBinary()
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(kb), base64.StdEncoding.EncodeToString(eb)
+	}
+
+	ledger := map[string]string{}
+	for _, e := range []struct {
+		cid      xdr.ContractId
+		key, val xdr.ScVal
+		dur      xdr.ContractDataDurability
+	}{
+		{id, xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance}, instanceVal, xdr.ContractDataDurabilityPersistent},
+		{id, vec(sym("Balance"), u32(1)), u32(5), xdr.ContractDataDurabilityPersistent},
+		{id, sym("LOCK"), u32(1), xdr.ContractDataDurabilityTemporary},
+		{other, sym("X"), u32(1), xdr.ContractDataDurabilityPersistent},
+	} {
+		k, v := mk(e.cid, e.key, e.val, e.dur)
+		ledger[k] = v
+	}
+
+	got, err := StorageEntriesFromLedger(ledger, id)
+	require.NoError(t, err)
+	durabilities := map[string]int{}
+	for _, e := range got {
+		durabilities[e.Durability]++
+	}
+	assert.Equal(t, map[string]int{StorageInstance: 1, StoragePersistent: 1, StorageTemporary: 1}, durabilities)
+}
@@ -0,0 +1,288 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package abi
+
+import (
+	"fmt"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// maxTypeDepth bounds recursion through self-referential UDTs.
+const maxTypeDepth = 32
+
+// CheckValue reports whether v would deserialize as td under the given spec,
+// following the soroban-sdk encoding: named structs are maps keyed by field
+// symbol, tuple structs and union cases are vectors, and integer enums are
+// u32 values. A nil error means the value decodes.
+func CheckValue(spec *ContractSpec, v xdr.ScVal, td xdr.ScSpecTypeDef) error {
+	return checkValue(spec, v, td, 0)
+}
+
+func checkValue(spec *ContractSpec, v xdr.ScVal, td xdr.ScSpecTypeDef, depth int) error {
+	if depth > maxTypeDepth {
+		return fmt.Errorf("type nesting exceeds %d levels", maxTypeDepth)
+	}
+
+	want := func(t xdr.ScValType) error {
+		if v.Type != t {
+			return fmt.Errorf("expected %s, found %s", FormatTypeDef(td), v.Type)
+		}
+		return nil
+	}
+
+	switch td.Type {
+	case xdr.ScSpecTypeScSpecTypeVal:
+		return nil
+	case xdr.ScSpecTypeScSpecTypeBool:
+		return want(xdr.ScValTypeScvBool)
+	case xdr.ScSpecTypeScSpecTypeVoid:
+		return want(xdr.ScValTypeScvVoid)
+	case xdr.ScSpecTypeScSpecTypeError:
+		return want(xdr.ScValTypeScvError)
+	case xdr.ScSpecTypeScSpecTypeU32:
+		return want(xdr.ScValTypeScvU32)
+	case xdr.ScSpecTypeScSpecTypeI32:
+		return want(xdr.ScValTypeScvI32)
+	case xdr.ScSpecTypeScSpecTypeU64:
+		return want(xdr.ScValTypeScvU64)
+	case xdr.ScSpecTypeScSpecTypeI64:
+		return want(xdr.ScValTypeScvI64)
+	case xdr.ScSpecTypeScSpecTypeTimepoint:
+		return want(xdr.ScValTypeScvTimepoint)
+	case xdr.ScSpecTypeScSpecTypeDuration:
+		return want(xdr.ScValTypeScvDuration)
+	case xdr.ScSpecTypeScSpecTypeU128:
+		return want(xdr.ScValTypeScvU128)
+	case xdr.ScSpecTypeScSpecTypeI128:
+		return want(xdr.ScValTypeScvI128)
+	case xdr.ScSpecTypeScSpecTypeU256:
+		return want(xdr.ScValTypeScvU256)
+	case xdr.ScSpecTypeScSpecTypeI256:
+		return want(xdr.ScValTypeScvI256)
+	case xdr.ScSpecTypeScSpecTypeBytes:
+		return want(xdr.ScValTypeScvBytes)
+	case xdr.ScSpecTypeScSpecTypeString:
+		return want(xdr.ScValTypeScvString)
+	case xdr.ScSpecTypeScSpecTypeSymbol:
+		return want(xdr.ScValTypeScvSymbol)
+	case xdr.ScSpecTypeScSpecTypeAddress, xdr.ScSpecTypeScSpecTypeMuxedAddress:
+		return want(xdr.ScValTypeScvAddress)
+
+	case xdr.ScSpecTypeScSpecTypeOption:
+		if v.Type == xdr.ScValTypeScvVoid || td.Option == nil {
+			return nil
+		}
+		return checkValue(spec, v, td.Option.ValueType, depth+1)
+
+	case xdr.ScSpecTypeScSpecTypeResult:
+		// Results are only returned, never stored.
+		return nil
+
+	case xdr.ScSpecTypeScSpecTypeVec:
+		if err := want(xdr.ScValTypeScvVec); err != nil {
+			return err
+		}
+		if td.Vec == nil {
+			return nil
+		}
+		for i, elem := range vecOf(v) {
+			if err := checkValue(spec, elem, td.Vec.ElementType, depth+1); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		return nil
+
+	case xdr.ScSpecTypeScSpecTypeMap:
+		if err := want(xdr.ScValTypeScvMap); err != nil {
+			return err
+		}
+		if td.Map == nil {
+			return nil
+		}
+		for _, e := range mapOf(v) {
+			if err := checkValue(spec, e.Key, td.Map.KeyType, depth+1); err != nil {
+				return fmt.Errorf("map key: %w", err)
+			}
+			if err := checkValue(spec, e.Val, td.Map.ValueType, depth+1); err != nil {
+				return fmt.Errorf("map value: %w", err)
+			}
+		}
+		return nil
+
+	case xdr.ScSpecTypeScSpecTypeTuple:
+		if err := want(xdr.ScValTypeScvVec); err != nil {
+			return err
+		}
+		if td.Tuple == nil {
+			return nil
+		}
+		return checkSequence(spec, vecOf(v), td.Tuple.ValueTypes, depth)
+
+	case xdr.ScSpecTypeScSpecTypeBytesN:
+		if err := want(xdr.ScValTypeScvBytes); err != nil {
+			return err
+		}
+		if td.BytesN != nil && v.Bytes != nil && uint32(len(*v.Bytes)) != uint32(td.BytesN.N) {
+			return fmt.Errorf("expected %d bytes, found %d", td.BytesN.N, len(*v.Bytes))
+		}
+		return nil
+
+	case xdr.ScSpecTypeScSpecTypeUdt:
+		if td.Udt == nil {
+			return nil
+		}
+		return checkUDT(spec, v, td.Udt.Name, depth+1)
+	}
+
+	return fmt.Errorf("unsupported spec type %d", td.Type)
+}
+
+// CheckUDT reports whether v decodes as the named user-defined type.
+func CheckUDT(spec *ContractSpec, v xdr.ScVal, name string) error {
+	return checkUDT(spec, v, name, 0)
+}
+
+func checkUDT(spec *ContractSpec, v xdr.ScVal, name string, depth int) error {
+	for _, s := range spec.Structs {
+		if s.Name == name {
+			return checkStruct(spec, v, s, depth)
+		}
+	}
+	for _, u := range spec.Unions {
+		if u.Name == name {
+			return checkUnion(spec, v, u, depth)
+		}
+	}
+	for _, e := range spec.Enums {
+		if e.Name == name {
+			if v.Type != xdr.ScValTypeScvU32 || v.U32 == nil {
+				return fmt.Errorf("enum %s: expected U32, found %s", name, v.Type)
+			}
+			for _, c := range e.Cases {
+				if uint32(c.Value) == uint32(*v.U32) {
+					return nil
+				}
+			}
+			return fmt.Errorf("enum %s has no variant with value %d", name, *v.U32)
+		}
+	}
+	for _, e := range spec.ErrorEnums {
+		if e.Name == name {
+			if v.Type != xdr.ScValTypeScvError {
+				return fmt.Errorf("error %s: expected Error, found %s", name, v.Type)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("type %s is not defined", name)
+}
+
+func checkStruct(spec *ContractSpec, v xdr.ScVal, s xdr.ScSpecUdtStructV0, depth int) error {
+	if isTupleStruct(s) {
+		if v.Type != xdr.ScValTypeScvVec {
+			return fmt.Errorf("struct %s: expected Vec, found %s", s.Name, v.Type)
+		}
+		types := make([]xdr.ScSpecTypeDef, len(s.Fields))
+		for i, f := range s.Fields {
+			types[i] = f.Type
+		}
+		if err := checkSequence(spec, vecOf(v), types, depth); err != nil {
+			return fmt.Errorf("struct %s: %w", s.Name, err)
+		}
+		return nil
+	}
+
+	if v.Type != xdr.ScValTypeScvMap {
+		return fmt.Errorf("struct %s: expected Map, found %s", s.Name, v.Type)
+	}
+	entries := mapOf(v)
+	byName := make(map[string]xdr.ScVal, len(entries))
+	for _, e := range entries {
+		if e.Key.Type != xdr.ScValTypeScvSymbol || e.Key.Sym == nil {
+			return fmt.Errorf("struct %s: field key is %s, not a symbol", s.Name, e.Key.Type)
+		}
+		byName[string(*e.Key.Sym)] = e.Val
+	}
+	if len(byName) != len(s.Fields) {
+		return fmt.Errorf("struct %s: expected %d fields, found %d", s.Name, len(s.Fields), len(byName))
+	}
+	for _, f := range s.Fields {
+		val, ok := byName[f.Name]
+		if !ok {
+			return fmt.Errorf("struct %s: missing field %s", s.Name, f.Name)
+		}
+		if err := checkValue(spec, val, f.Type, depth+1); err != nil {
+			return fmt.Errorf("struct %s.%s: %w", s.Name, f.Name, err)
+		}
+	}
+	return nil
+}
+
+func checkUnion(spec *ContractSpec, v xdr.ScVal, u xdr.ScSpecUdtUnionV0, depth int) error {
+	items := vecOf(v)
+	if v.Type != xdr.ScValTypeScvVec || len(items) == 0 || items[0].Type != xdr.ScValTypeScvSymbol || items[0].Sym == nil {
+		return fmt.Errorf("union %s: expected Vec starting with a case symbol", u.Name)
+	}
+	caseName := string(*items[0].Sym)
+	for _, c := range u.Cases {
+		switch c.Kind {
+		case xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseVoidV0:
+			if c.VoidCase.Name == caseName {
+				if len(items) != 1 {
+					return fmt.Errorf("union %s::%s: unit case carries %d values", u.Name, caseName, len(items)-1)
+				}
+				return nil
+			}
+		case xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0:
+			if c.TupleCase.Name == caseName {
+				if err := checkSequence(spec, items[1:], c.TupleCase.Type, depth); err != nil {
+					return fmt.Errorf("union %s::%s: %w", u.Name, caseName, err)
+				}
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("union %s has no case %s", u.Name, caseName)
+}
+
+func checkSequence(spec *ContractSpec, vals []xdr.ScVal, types []xdr.ScSpecTypeDef, depth int) error {
+	if len(vals) != len(types) {
+		return fmt.Errorf("expected %d values, found %d", len(types), len(vals))
+	}
+	for i := range types {
+		if err := checkValue(spec, vals[i], types[i], depth+1); err != nil {
+			return fmt.Errorf("[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// isTupleStruct reports whether a struct was declared with unnamed fields,
+// which the spec records as "0", "1", ...
+func isTupleStruct(s xdr.ScSpecUdtStructV0) bool {
+	if len(s.Fields) == 0 {
+		return false
+	}
+	for i, f := range s.Fields {
+		if f.Name != fmt.Sprint(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func vecOf(v xdr.ScVal) []xdr.ScVal {
+	if vec, ok := v.GetVec(); ok && vec != nil {
+		return *vec
+	}
+	return nil
+}
+
+func mapOf(v xdr.ScVal) xdr.ScMap {
+	if m, ok := v.GetMap(); ok && m != nil {
+		return *m
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package abi
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// MatchType returns the user-defined type a stored key or value was encoded
+// as: a struct for maps and a union for symbol-tagged vectors. It returns ""
+// when no type in the spec matches or spec is nil.
+func MatchType(spec *ContractSpec, v xdr.ScVal) string {
+	if spec == nil {
+		return ""
+	}
+	return matchValueType(spec, v)
+}
+
+// FormatValue renders a key or value using the names of the spec: union
+// cases as DataKey::Balance(G...) and structs as Config { fee: 5 }. Values
+// no type matches, or all values when spec is nil, are rendered structurally.
+func FormatValue(spec *ContractSpec, v xdr.ScVal) string {
+	switch v.Type {
+	case xdr.ScValTypeScvVec:
+		items := vecOf(v)
+		if name := MatchType(spec, v); name != "" {
+			return name + "::" + formatUnionCase(spec, items)
+		}
+		return "[" + formatList(spec, items) + "]"
+	case xdr.ScValTypeScvMap:
+		entries := mapOf(v)
+		parts := make([]string, len(entries))
+		for i, e := range entries {
+			parts[i] = FormatValue(spec, e.Key) + ": " + FormatValue(spec, e.Val)
+		}
+		if name := MatchType(spec, v); name != "" {
+			return name + " { " + strings.Join(parts, ", ") + " }"
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case xdr.ScValTypeScvString:
+		if v.Str != nil {
+			return strconv.Quote(string(*v.Str))
+		}
+	case xdr.ScValTypeScvSymbol:
+		if v.Sym != nil {
+			return string(*v.Sym)
+		}
+	}
+	return v.String()
+}
+
+// formatUnionCase renders the case symbol and arguments of a union value.
+func formatUnionCase(spec *ContractSpec, items []xdr.ScVal) string {
+	name := FormatValue(spec, items[0])
+	if len(items) == 1 {
+		return name
+	}
+	return name + "(" + formatList(spec, items[1:]) + ")"
+}
+
+func formatList(spec *ContractSpec, items []xdr.ScVal) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = FormatValue(spec, item)
+	}
+	return strings.Join(parts, ", ")
+}
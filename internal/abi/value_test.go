@@ -0,0 +1,33 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package abi
+
+import (
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatValue(t *testing.T) {
+	spec := &ContractSpec{
+		Structs: []xdr.ScSpecUdtStructV0{configStruct(xdr.ScSpecTypeScSpecTypeU32)},
+		Unions:  []xdr.ScSpecUdtUnionV0{dataKeyUnion(voidCase("Admin"), tupleCase("Balance", typeDef(xdr.ScSpecTypeScSpecTypeU32)))},
+	}
+	b := false
+	cfg := scMap(sym("fee"), u32(5), sym("paused"), xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &b})
+	s := xdr.ScString("hi")
+	str := xdr.ScVal{Type: xdr.ScValTypeScvString, Str: &s}
+
+	assert.Equal(t, "DataKey::Admin", FormatValue(spec, vec(sym("Admin"))))
+	assert.Equal(t, "DataKey::Balance(7)", FormatValue(spec, vec(sym("Balance"), u32(7))))
+	assert.Equal(t, "Config { fee: 5, paused: false }", FormatValue(spec, cfg))
+	assert.Equal(t, "[Nonce, 1]", FormatValue(spec, vec(sym("Nonce"), u32(1))), "no matching case")
+	assert.Equal(t, "{fee: 5}", FormatValue(spec, scMap(sym("fee"), u32(5))), "missing field")
+	assert.Equal(t, `["hi"]`, FormatValue(spec, vec(str)))
+
+	assert.Equal(t, "[Balance, 7]", FormatValue(nil, vec(sym("Balance"), u32(7))))
+	assert.Equal(t, "Config", MatchType(spec, cfg))
+	assert.Equal(t, "", MatchType(nil, cfg))
+}
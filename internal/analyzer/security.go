@@ -4,9 +4,9 @@
 package analyzer
 
 import (
-	"fmt"
 	"strings"
 
+	"github.com/dotandev/hintents/internal/security"
 	"github.com/dotandev/hintents/internal/simulator"
 )
 
@@ -17,6 +17,8 @@ type SecurityViolation struct {
 	Location    string
 }
 
+// SecurityAnalyzer reports unauthorized state modifications in categorized
+// simulator events. It runs rule SEC008 of the security rule engine.
 type SecurityAnalyzer struct {
 	violations []SecurityViolation
 }
@@ -30,88 +32,19 @@ func NewSecurityAnalyzer() *SecurityAnalyzer {
 func (sa *SecurityAnalyzer) Analyze(resp *simulator.SimulationResponse) []SecurityViolation {
 	sa.violations = make([]SecurityViolation, 0)
 
-	if resp.Status != "success" {
+	if resp.Status != "success" || len(resp.CategorizedEvents) == 0 {
 		return sa.violations
 	}
 
-	sa.checkUnauthorizedStateModifications(resp.CategorizedEvents)
-
-	return sa.violations
-}
-
-func (sa *SecurityAnalyzer) checkUnauthorizedStateModifications(events []simulator.CategorizedEvent) {
-	type StateModification struct {
-		index      int
-		contractID string
-	}
-
-	authChecks := make(map[string][]int)
-	stateWrites := make([]StateModification, 0)
-
-	for i, event := range events {
-		contractID := ""
-		if event.ContractID != nil {
-			contractID = *event.ContractID
-		}
-
-		switch event.EventType {
-		case "require_auth":
-			authChecks[contractID] = append(authChecks[contractID], i)
-		case "storage_write":
-			stateWrites = append(stateWrites, StateModification{
-				index:      i,
-				contractID: contractID,
-			})
-		}
-	}
-
-	for _, write := range stateWrites {
-		if sa.isSACPattern(events, write.index) {
-			continue
-		}
-
-		if !sa.hasAuthBeforeWrite(authChecks[write.contractID], write.index) {
-			sa.violations = append(sa.violations, SecurityViolation{
-				Type:        "UnauthorizedStateModification",
-				Description: fmt.Sprintf("State modification without require_auth in contract %s", write.contractID),
-				Severity:    "high",
-				Location:    fmt.Sprintf("event_index:%d", write.index),
-			})
-		}
+	rule := security.RuleByID(security.RuleUnauthorizedStateWrite)
+	for _, f := range rule.Check(&security.Input{CategorizedEvents: resp.CategorizedEvents}) {
+		sa.violations = append(sa.violations, SecurityViolation{
+			Type:        "UnauthorizedStateModification",
+			Description: f.Description,
+			Severity:    strings.ToLower(string(f.Severity)),
+			Location:    f.Location,
+		})
 	}
-}
 
-func (sa *SecurityAnalyzer) hasAuthBeforeWrite(authIndices []int, writeIndex int) bool {
-	for _, authIdx := range authIndices {
-		if authIdx < writeIndex {
-			return true
-		}
-	}
-	return false
-}
-
-func (sa *SecurityAnalyzer) isSACPattern(events []simulator.CategorizedEvent, writeIndex int) bool {
-	if writeIndex >= len(events) {
-		return false
-	}
-
-	event := events[writeIndex]
-
-	for _, topic := range event.Topics {
-		topicLower := strings.ToLower(topic)
-		if strings.Contains(topicLower, "balance") ||
-			strings.Contains(topicLower, "allowance") ||
-			strings.Contains(topicLower, "admin") ||
-			strings.Contains(topicLower, "metadata") {
-			return true
-		}
-	}
-
-	dataLower := strings.ToLower(event.Data)
-	if strings.Contains(dataLower, "stellar_asset") ||
-		strings.Contains(dataLower, "sac_") {
-		return true
-	}
-
-	return false
+	return sa.violations
 }
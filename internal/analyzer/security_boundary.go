@@ -4,8 +4,9 @@
 package analyzer
 
 import (
-	"encoding/json"
 	"strings"
+
+	"github.com/dotandev/hintents/internal/security"
 )
 
 type Event struct {
@@ -23,13 +24,10 @@ type Violation struct {
 	Details     map[string]interface{} `json:"details,omitempty"`
 }
 
+// SecurityBoundaryChecker reports storage writes without a prior auth event
+// in JSON-encoded events. It runs rule SEC008 of the security rule engine.
 type SecurityBoundaryChecker struct{}
 
-type contractInvocationState struct {
-	HasAuth     bool
-	AuthChecked map[string]bool
-}
-
 func NewSecurityBoundaryChecker() *SecurityBoundaryChecker {
 	return &SecurityBoundaryChecker{}
 }
@@ -37,70 +35,18 @@ func NewSecurityBoundaryChecker() *SecurityBoundaryChecker {
 func (c *SecurityBoundaryChecker) Analyze(events []string) ([]Violation, error) {
 	var violations []Violation
 
-	contractStates := make(map[string]*contractInvocationState)
-
-	for _, eventStr := range events {
-		var event Event
-		if err := json.Unmarshal([]byte(eventStr), &event); err != nil {
-			continue
-		}
-
-		if event.Contract == "" || event.Contract == "unknown" {
-			continue
-		}
-
-		if _, exists := contractStates[event.Contract]; !exists {
-			contractStates[event.Contract] = &contractInvocationState{
-				AuthChecked: make(map[string]bool),
-			}
-		}
-
-		state := contractStates[event.Contract]
-
-		switch event.Type {
-		case "auth":
-			state.AuthChecked[event.Address] = true
-			state.HasAuth = true
-
-		case "storage_write":
-			if !state.HasAuth {
-				if !isSACPattern(event) {
-					violations = append(violations, Violation{
-						Type:        "unauthorized_state_modification",
-						Severity:    "high",
-						Description: "Storage write operation without prior require_auth check",
-						Contract:    event.Contract,
-						Details: map[string]interface{}{
-							"operation": "storage_write",
-						},
-					})
-				}
-			}
-		}
+	rule := security.RuleByID(security.RuleUnauthorizedStateWrite)
+	for _, f := range rule.Check(&security.Input{Events: events}) {
+		violations = append(violations, Violation{
+			Type:        "unauthorized_state_modification",
+			Severity:    strings.ToLower(string(f.Severity)),
+			Description: f.Description,
+			Contract:    f.Contract,
+			Details: map[string]interface{}{
+				"operation": "storage_write",
+			},
+		})
 	}
 
 	return violations, nil
 }
-
-func isSACPattern(event Event) bool {
-	contract := event.Contract
-
-	if contract == "" || contract == "unknown" {
-		return false
-	}
-
-	sacPatterns := []string{
-		"stellar_asset",
-		"SAC",
-		"token",
-	}
-
-	contractLower := strings.ToLower(contract)
-	for _, pattern := range sacPatterns {
-		if strings.Contains(contractLower, strings.ToLower(pattern)) {
-			return true
-		}
-	}
-
-	return false
-}
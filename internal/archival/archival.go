@@ -0,0 +1,285 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+// Package archival checks the lifetime of the ledger entries a Soroban
+// transaction declares in its footprint. Each contract data and code entry
+// is looked up with its live-until ledger to find entries that are about to
+// expire or already archived, estimate the rent of extending them, and build
+// the RestoreFootprint transaction archived entries need before the
+// invocation can succeed.
+package archival
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/abi"
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// Defaults for Options.
+const (
+	// DefaultWarnLedgers flags entries that expire within about a day.
+	DefaultWarnLedgers uint32 = 17_280
+	// DefaultExtendLedgers prices extending entries to about 30 days.
+	DefaultExtendLedgers uint32 = 518_400
+)
+
+// Status is the lifetime state of a footprint entry.
+type Status string
+
+const (
+	StatusLive     Status = "live"
+	StatusExpiring Status = "expiring"
+	// StatusArchived is a persistent entry past its TTL; it must be restored
+	// with RestoreFootprint before it can be used.
+	StatusArchived Status = "archived"
+	// StatusExpired is a temporary entry past its TTL; it is gone for good.
+	StatusExpired Status = "expired"
+	// StatusMissing is an entry the network does not have, e.g. one the
+	// transaction creates.
+	StatusMissing Status = "missing"
+)
+
+// TTLReader fetches ledger entries with their live-until ledgers.
+// *rpc.Client implements it.
+type TTLReader interface {
+	GetLedgerEntryTTLs(ctx context.Context, keys []string) (map[string]rpc.LedgerEntryTTL, uint32, error)
+}
+
+// Options tune the analysis.
+type Options struct {
+	// WarnLedgers flags entries that expire within this many ledgers.
+	WarnLedgers uint32
+	// ExtendLedgers is the remaining lifetime extension rent is priced for.
+	ExtendLedgers uint32
+}
+
+// Entry is the lifetime of one footprint entry.
+type Entry struct {
+	KeyXdr     string `json:"key_xdr"`
+	Key        string `json:"key"`
+	Durability string `json:"durability"`
+	ReadWrite  bool   `json:"read_write"`
+	Status     Status `json:"status"`
+	LiveUntil  uint32 `json:"live_until,omitempty"`
+	// Remaining is LiveUntil minus Report.LatestLedger; negative once the
+	// entry has expired.
+	Remaining int64 `json:"remaining"`
+	SizeBytes int   `json:"size_bytes,omitempty"`
+	// RentStroops estimates the rent of extending the entry to
+	// Options.ExtendLedgers or, for archived entries, of restoring it.
+	RentStroops int64 `json:"rent_stroops,omitempty"`
+	// EntryXdr is the entry as last stored, also for archived entries.
+	EntryXdr string `json:"-"`
+}
+
+// Report is the lifetime analysis of a transaction's footprint.
+type Report struct {
+	// LatestLedger is the ledger lifetimes are measured at. RPC only serves
+	// the current TTL of an entry, so lifetimes cannot be measured at the
+	// ledger a historic transaction ran in.
+	LatestLedger  uint32  `json:"latest_ledger"`
+	ExtendLedgers uint32  `json:"extend_ledgers"`
+	Entries       []Entry `json:"entries"`
+}
+
+// Archived returns the entries that need RestoreFootprint.
+func (r *Report) Archived() []Entry {
+	var out []Entry
+	for _, e := range r.Entries {
+		if e.Status == StatusArchived {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// RestoreEntries maps the keys of archived entries to their stored values,
+// as a replay needs them once restored.
+func (r *Report) RestoreEntries() map[string]string {
+	out := map[string]string{}
+	for _, e := range r.Archived() {
+		if e.EntryXdr != "" {
+			out[e.KeyXdr] = e.EntryXdr
+		}
+	}
+	return out
+}
+
+// ArchivedState maps the keys of archived entries and of their TTL entries
+// to their stored values, as the ledger holds them before a restore.
+func (r *Report) ArchivedState() (map[string]string, error) {
+	out := r.RestoreEntries()
+	for _, e := range r.Archived() {
+		if e.EntryXdr == "" {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(e.KeyXdr)
+		if err != nil {
+			return nil, fmt.Errorf("decode ledger key: %w", err)
+		}
+		hash := xdr.Hash(sha256.Sum256(raw))
+		ttlKey, err := xdr.MarshalBase64(xdr.LedgerKey{Type: xdr.LedgerEntryTypeTtl, Ttl: &xdr.LedgerKeyTtl{KeyHash: hash}})
+		if err != nil {
+			return nil, fmt.Errorf("encode ledger key: %w", err)
+		}
+		ttlEntry, err := xdr.MarshalBase64(xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeTtl,
+			Ttl:  &xdr.TtlEntry{KeyHash: hash, LiveUntilLedgerSeq: xdr.Uint32(e.LiveUntil)},
+		}})
+		if err != nil {
+			return nil, fmt.Errorf("encode ledger entry: %w", err)
+		}
+		out[ttlKey] = ttlEntry
+	}
+	return out, nil
+}
+
+// Footprint returns the contract data and code keys a transaction declares,
+// read-only keys first, and which of them are read-write. Classic entries
+// have no TTL and are left out.
+func Footprint(envelopeXdr string) ([]xdr.LedgerKey, map[string]bool, error) {
+	var env xdr.TransactionEnvelope
+	if err := xdr.SafeUnmarshalBase64(envelopeXdr, &env); err != nil {
+		return nil, nil, fmt.Errorf("decode envelope: %w", err)
+	}
+	data := simulator.SorobanData(env)
+	if data == nil {
+		return nil, nil, nil
+	}
+
+	var keys []xdr.LedgerKey
+	readWrite := map[string]bool{}
+	add := func(k xdr.LedgerKey, rw bool) error {
+		if k.Type != xdr.LedgerEntryTypeContractData && k.Type != xdr.LedgerEntryTypeContractCode {
+			return nil
+		}
+		keys = append(keys, k)
+		if rw {
+			keyB64, err := xdr.MarshalBase64(k)
+			if err != nil {
+				return fmt.Errorf("encode ledger key: %w", err)
+			}
+			readWrite[keyB64] = true
+		}
+		return nil
+	}
+	for _, k := range data.Resources.Footprint.ReadOnly {
+		if err := add(k, false); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, k := range data.Resources.Footprint.ReadWrite {
+		if err := add(k, true); err != nil {
+			return nil, nil, err
+		}
+	}
+	return keys, readWrite, nil
+}
+
+// Analyze looks up every footprint entry of the transaction and reports its
+// lifetime at the latest ledger.
+func Analyze(ctx context.Context, src TTLReader, envelopeXdr string, opts Options) (*Report, error) {
+	if opts.WarnLedgers == 0 {
+		opts.WarnLedgers = DefaultWarnLedgers
+	}
+	if opts.ExtendLedgers == 0 {
+		opts.ExtendLedgers = DefaultExtendLedgers
+	}
+
+	keys, readWrite, err := Footprint(envelopeXdr)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{ExtendLedgers: opts.ExtendLedgers}
+	if len(keys) == 0 {
+		return report, nil
+	}
+
+	encoded := make([]string, len(keys))
+	for i, k := range keys {
+		if encoded[i], err = xdr.MarshalBase64(k); err != nil {
+			return nil, fmt.Errorf("encode ledger key: %w", err)
+		}
+	}
+	found, latest, err := src.GetLedgerEntryTTLs(ctx, encoded)
+	if err != nil {
+		return nil, err
+	}
+	report.LatestLedger = latest
+
+	for i, k := range keys {
+		e := Entry{
+			KeyXdr:     encoded[i],
+			Key:        describeKey(k),
+			Durability: durability(k),
+			ReadWrite:  readWrite[encoded[i]],
+			Status:     StatusMissing,
+		}
+		if ttl, ok := found[encoded[i]]; ok {
+			e.EntryXdr = ttl.EntryXdr
+			e.LiveUntil = ttl.LiveUntil
+			e.Remaining = int64(ttl.LiveUntil) - int64(report.LatestLedger)
+			if raw, err := base64.StdEncoding.DecodeString(ttl.EntryXdr); err == nil {
+				e.SizeBytes = len(raw)
+			}
+			e.classify(opts)
+		}
+		report.Entries = append(report.Entries, e)
+	}
+	return report, nil
+}
+
+// classify sets the status and rent of an entry that exists.
+func (e *Entry) classify(opts Options) {
+	persistent := e.Durability != abi.StorageTemporary
+	switch {
+	case e.Remaining < 0 && persistent:
+		e.Status = StatusArchived
+		e.RentStroops = simulator.EstimateRentFee(e.SizeBytes, simulator.MinPersistentTTLLedgers, true)
+		return
+	case e.Remaining < 0:
+		e.Status = StatusExpired
+		return
+	case e.Remaining <= int64(opts.WarnLedgers):
+		e.Status = StatusExpiring
+	default:
+		e.Status = StatusLive
+	}
+	if e.Remaining < int64(opts.ExtendLedgers) {
+		e.RentStroops = simulator.EstimateRentFee(e.SizeBytes, uint32(int64(opts.ExtendLedgers)-e.Remaining), persistent)
+	}
+}
+
+func durability(k xdr.LedgerKey) string {
+	if k.Type == xdr.LedgerEntryTypeContractCode {
+		return "code"
+	}
+	switch {
+	case k.ContractData.Key.Type == xdr.ScValTypeScvLedgerKeyContractInstance:
+		return abi.StorageInstance
+	case k.ContractData.Durability == xdr.ContractDataDurabilityTemporary:
+		return abi.StorageTemporary
+	}
+	return abi.StoragePersistent
+}
+
+func describeKey(k xdr.LedgerKey) string {
+	if k.Type == xdr.LedgerEntryTypeContractCode {
+		return "wasm " + hex.EncodeToString(k.ContractCode.Hash[:])
+	}
+	contract, err := k.ContractData.Contract.String()
+	if err != nil {
+		contract = "?"
+	}
+	if k.ContractData.Key.Type == xdr.ScValTypeScvLedgerKeyContractInstance {
+		return contract + " instance"
+	}
+	return contract + " " + abi.FormatValue(nil, k.ContractData.Key)
+}
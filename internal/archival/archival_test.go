@@ -0,0 +1,188 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package archival
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const source = "GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7"
+
+type fakeReader struct {
+	entries map[string]rpc.LedgerEntryTTL
+	latest  uint32
+}
+
+func (f fakeReader) GetLedgerEntryTTLs(_ context.Context, keys []string) (map[string]rpc.LedgerEntryTTL, uint32, error) {
+	out := map[string]rpc.LedgerEntryTTL{}
+	for _, k := range keys {
+		if e, ok := f.entries[k]; ok {
+			out[k] = e
+		}
+	}
+	return out, f.latest, nil
+}
+
+func dataKey(name string, durability xdr.ContractDataDurability) xdr.LedgerKey {
+	var id xdr.ContractId
+	id[0] = 0xab
+	sym := xdr.ScSymbol(name)
+	return xdr.LedgerKey{Type: xdr.LedgerEntryTypeContractData, ContractData: &xdr.LedgerKeyContractData{
+		Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &id},
+		Key:        xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym},
+		Durability: durability,
+	}}
+}
+
+func mustKey(t *testing.T, k xdr.LedgerKey) string {
+	t.Helper()
+	s, err := xdr.MarshalBase64(k)
+	require.NoError(t, err)
+	return s
+}
+
+func envelope(t *testing.T, readOnly, readWrite []xdr.LedgerKey) string {
+	t.Helper()
+	account := xdr.MustAddress(source)
+	env := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{Tx: xdr.Transaction{
+			SourceAccount: xdr.MustMuxedAddress(source),
+			Fee:           1000,
+			SeqNum:        42,
+			Operations: []xdr.Operation{{Body: xdr.OperationBody{
+				Type: xdr.OperationTypeInvokeHostFunction,
+				InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{HostFunction: xdr.HostFunction{
+					Type: xdr.HostFunctionTypeHostFunctionTypeUploadContractWasm, Wasm: &[]byte{},
+				}},
+			}}},
+			Ext: xdr.TransactionExt{V: 1, SorobanData: &xdr.SorobanTransactionData{
+				Resources: xdr.SorobanResources{Footprint: xdr.LedgerFootprint{
+					ReadOnly:  append(readOnly, xdr.LedgerKey{Type: xdr.LedgerEntryTypeAccount, Account: &xdr.LedgerKeyAccount{AccountId: account}}),
+					ReadWrite: readWrite,
+				}},
+			}},
+		}},
+	}
+	s, err := xdr.MarshalBase64(env)
+	require.NoError(t, err)
+	return s
+}
+
+func TestAnalyze(t *testing.T) {
+	live := dataKey("live", xdr.ContractDataDurabilityPersistent)
+	expiring := dataKey("expiring", xdr.ContractDataDurabilityPersistent)
+	archived := dataKey("archived", xdr.ContractDataDurabilityPersistent)
+	expired := dataKey("nonce", xdr.ContractDataDurabilityTemporary)
+	missing := dataKey("new", xdr.ContractDataDurabilityPersistent)
+
+	entry := strings.Repeat("A", 1368) // 1026 bytes once decoded
+	reader := fakeReader{latest: 1_000_000, entries: map[string]rpc.LedgerEntryTTL{
+		mustKey(t, live):     {EntryXdr: entry, LiveUntil: 3_000_000},
+		mustKey(t, expiring): {EntryXdr: entry, LiveUntil: 1_000_100},
+		mustKey(t, archived): {EntryXdr: entry, LiveUntil: 999_000},
+		mustKey(t, expired):  {EntryXdr: entry, LiveUntil: 999_999},
+	}}
+	env := envelope(t, []xdr.LedgerKey{live, expiring}, []xdr.LedgerKey{archived, expired, missing})
+
+	report, err := Analyze(context.Background(), reader, env, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1_000_000), report.LatestLedger)
+	require.Len(t, report.Entries, 5, "the account key has no TTL")
+
+	byStatus := map[Status]Entry{}
+	for _, e := range report.Entries {
+		byStatus[e.Status] = e
+	}
+	require.Len(t, byStatus, 5)
+
+	assert.Equal(t, int64(2_000_000), byStatus[StatusLive].Remaining)
+	assert.Zero(t, byStatus[StatusLive].RentStroops, "already lives past the extension target")
+	assert.False(t, byStatus[StatusLive].ReadWrite)
+
+	exp := byStatus[StatusExpiring]
+	assert.Equal(t, int64(100), exp.Remaining)
+	assert.Equal(t, simulator.EstimateRentFee(1026, DefaultExtendLedgers-100, true), exp.RentStroops)
+
+	arch := byStatus[StatusArchived]
+	assert.True(t, arch.ReadWrite)
+	assert.Equal(t, int64(-1000), arch.Remaining)
+	assert.Equal(t, simulator.EstimateRentFee(1026, simulator.MinPersistentTTLLedgers, true), arch.RentStroops)
+	assert.Equal(t, "temporary", byStatus[StatusExpired].Durability)
+	assert.Empty(t, byStatus[StatusMissing].EntryXdr)
+
+	assert.Equal(t, map[string]string{mustKey(t, archived): entry}, report.RestoreEntries())
+
+	lines := strings.Join(report.Lines(), "\n")
+	assert.Contains(t, lines, "archived  ")
+	assert.Contains(t, lines, "needs RestoreFootprint")
+	assert.Contains(t, lines, "temporary entries cannot be restored")
+	assert.Contains(t, lines, "extending to ~30d costs")
+
+	restoreXdr, err := report.RestoreEnvelope(env)
+	require.NoError(t, err)
+	var restore xdr.TransactionEnvelope
+	require.NoError(t, xdr.SafeUnmarshalBase64(restoreXdr, &restore))
+	assert.Equal(t, xdr.OperationTypeRestoreFootprint, restore.Operations()[0].Body.Type)
+	assert.Equal(t, []xdr.LedgerKey{archived}, restore.V1.Tx.Ext.SorobanData.Resources.Footprint.ReadWrite)
+	assert.Equal(t, int64(42), restore.SeqNum())
+}
+
+func TestAnalyze_ArchivedState(t *testing.T) {
+	archived := dataKey("archived", xdr.ContractDataDurabilityPersistent)
+	entry := strings.Repeat("A", 1368)
+	reader := fakeReader{latest: 1_000_000, entries: map[string]rpc.LedgerEntryTTL{
+		mustKey(t, archived): {EntryXdr: entry, LiveUntil: 999_000},
+	}}
+	env := envelope(t, nil, []xdr.LedgerKey{archived})
+
+	report, err := Analyze(context.Background(), reader, env, Options{})
+	require.NoError(t, err)
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, StatusArchived, report.Entries[0].Status)
+	assert.Equal(t, int64(-1000), report.Entries[0].Remaining)
+
+	state, err := report.ArchivedState()
+	require.NoError(t, err)
+	require.Len(t, state, 2)
+	assert.Equal(t, entry, state[mustKey(t, archived)])
+	for k, v := range state {
+		if k == mustKey(t, archived) {
+			continue
+		}
+		var ttl xdr.LedgerEntry
+		require.NoError(t, xdr.SafeUnmarshalBase64(v, &ttl))
+		require.NotNil(t, ttl.Data.Ttl)
+		assert.Equal(t, xdr.Uint32(999_000), ttl.Data.Ttl.LiveUntilLedgerSeq)
+	}
+}
+
+func TestAnalyze_NotSoroban(t *testing.T) {
+	env := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{Tx: xdr.Transaction{
+			SourceAccount: xdr.MustMuxedAddress(source),
+			Operations: []xdr.Operation{{Body: xdr.OperationBody{
+				Type:           xdr.OperationTypeBumpSequence,
+				BumpSequenceOp: &xdr.BumpSequenceOp{BumpTo: 1},
+			}}},
+		}},
+	}
+	s, err := xdr.MarshalBase64(env)
+	require.NoError(t, err)
+
+	report, err := Analyze(context.Background(), fakeReader{}, s, Options{})
+	require.NoError(t, err)
+	assert.Empty(t, report.Entries)
+	_, err = report.RestoreEnvelope(s)
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package archival
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// Lines renders one line per footprint entry.
+func (r *Report) Lines() []string {
+	lines := make([]string, len(r.Entries))
+	for i, e := range r.Entries {
+		access := "read-only"
+		if e.ReadWrite {
+			access = "read-write"
+		}
+		lines[i] = fmt.Sprintf("%-9s %s (%s, %s): %s", e.Status, e.Key, e.Durability, access, r.detail(e))
+	}
+	return lines
+}
+
+func (r *Report) detail(e Entry) string {
+	switch e.Status {
+	case StatusMissing:
+		return "not found on the network; created by the transaction or no longer stored"
+	case StatusArchived:
+		return fmt.Sprintf("archived %d ledgers ago (%s), needs RestoreFootprint; restore rent ~%d stroops",
+			-e.Remaining, approxDuration(-e.Remaining), e.RentStroops)
+	case StatusExpired:
+		return fmt.Sprintf("expired %d ledgers ago (%s); temporary entries cannot be restored",
+			-e.Remaining, approxDuration(-e.Remaining))
+	}
+	s := fmt.Sprintf("live until ledger %d, %d ledgers left (%s)", e.LiveUntil, e.Remaining, approxDuration(e.Remaining))
+	if e.RentStroops > 0 {
+		s += fmt.Sprintf("; extending to %s costs ~%d stroops", approxDuration(int64(r.ExtendLedgers)), e.RentStroops)
+	}
+	return s
+}
+
+// approxDuration converts a number of ledgers to wall-clock time at the
+// target close time.
+func approxDuration(ledgers int64) string {
+	d := time.Duration(ledgers*simulator.LedgerCloseSeconds) * time.Second
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("~%dd", int64(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("~%dh", int64(d/time.Hour))
+	}
+	return fmt.Sprintf("~%dm", int64(d/time.Minute))
+}
+
+// RestoreEnvelope builds an unsigned RestoreFootprint transaction for the
+// archived entries of the report, from the same source account and with the
+// same sequence number and fee as the transaction it precedes.
+func (r *Report) RestoreEnvelope(envelopeXdr string) (string, error) {
+	var env xdr.TransactionEnvelope
+	if err := xdr.SafeUnmarshalBase64(envelopeXdr, &env); err != nil {
+		return "", fmt.Errorf("decode envelope: %w", err)
+	}
+
+	var keys []xdr.LedgerKey
+	for _, e := range r.Archived() {
+		var k xdr.LedgerKey
+		if err := xdr.SafeUnmarshalBase64(e.KeyXdr, &k); err != nil {
+			return "", fmt.Errorf("decode ledger key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return "", fmt.Errorf("no archived entries to restore")
+	}
+
+	restore := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{Tx: xdr.Transaction{
+			SourceAccount: env.SourceAccount(),
+			Fee:           xdr.Uint32(env.Fee()),
+			SeqNum:        xdr.SequenceNumber(env.SeqNum()),
+			Cond:          xdr.Preconditions{Type: xdr.PreconditionTypePrecondNone},
+			Operations: []xdr.Operation{{Body: xdr.OperationBody{
+				Type:               xdr.OperationTypeRestoreFootprint,
+				RestoreFootprintOp: &xdr.RestoreFootprintOp{},
+			}}},
+			Ext: xdr.TransactionExt{V: 1, SorobanData: &xdr.SorobanTransactionData{
+				Resources: xdr.SorobanResources{Footprint: xdr.LedgerFootprint{ReadWrite: keys}},
+			}},
+		}},
+	}
+	return xdr.MarshalBase64(restore)
+}
@@ -11,22 +11,49 @@ import (
 	"github.com/stellar/go-stellar-sdk/xdr"
 )
 
-// GeneratorConfig holds configuration for TypeScript bindings generation
+// Language selects the target language of generated bindings.
+type Language string
+
+const (
+	LanguageTypeScript Language = "ts"
+	LanguageGo         Language = "go"
+	LanguageRust       Language = "rust"
+)
+
+// Languages lists the supported binding languages.
+var Languages = []Language{LanguageTypeScript, LanguageGo, LanguageRust}
+
+// ParseLanguage maps a --lang value to a Language.
+func ParseLanguage(s string) (Language, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "ts", "typescript":
+		return LanguageTypeScript, nil
+	case "go", "golang":
+		return LanguageGo, nil
+	case "rust", "rs":
+		return LanguageRust, nil
+	}
+	return "", fmt.Errorf("unsupported language %q (use: ts, go, rust)", s)
+}
+
+// GeneratorConfig holds configuration for bindings generation
 type GeneratorConfig struct {
 	WasmBytes   []byte
 	OutputDir   string
 	PackageName string
 	ContractID  string
 	Network     string
+	// Language defaults to TypeScript.
+	Language Language
 }
 
-// GeneratedFile represents a generated TypeScript file
+// GeneratedFile represents a generated source file
 type GeneratedFile struct {
 	Path    string
 	Content string
 }
 
-// Generator generates TypeScript bindings from Soroban contract specs
+// Generator generates client bindings from Soroban contract specs
 type Generator struct {
 	config GeneratorConfig
 	spec   *abi.ContractSpec
@@ -39,7 +66,8 @@ func NewGenerator(config GeneratorConfig) *Generator {
 	}
 }
 
-// Generate extracts the contract spec and generates TypeScript bindings
+// Generate extracts the contract spec and generates bindings in the
+// configured language
 func (g *Generator) Generate() ([]GeneratedFile, error) {
 	// Extract contract spec from WASM
 	specBytes, err := abi.ExtractCustomSection(g.config.WasmBytes, "contractspecv0")
@@ -56,8 +84,21 @@ func (g *Generator) Generate() ([]GeneratedFile, error) {
 		return nil, fmt.Errorf("failed to decode contract spec: %w", err)
 	}
 
-	// Generate files
-	files := []GeneratedFile{
+	switch g.config.Language {
+	case LanguageTypeScript, "":
+		return g.generateTypeScript(), nil
+	case LanguageGo:
+		return g.generateGo()
+	case LanguageRust:
+		return g.generateRust(), nil
+	default:
+		return nil, fmt.Errorf("unsupported language %q", g.config.Language)
+	}
+}
+
+// generateTypeScript generates the TypeScript package
+func (g *Generator) generateTypeScript() []GeneratedFile {
+	return []GeneratedFile{
 		{
 			Path:    "types.ts",
 			Content: g.generateTypes(),
@@ -83,8 +124,6 @@ func (g *Generator) Generate() ([]GeneratedFile, error) {
 			Content: g.generateReadme(),
 		},
 	}
-
-	return files, nil
 }
 
 // generateTypes generates TypeScript type definitions
@@ -0,0 +1,1390 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package bindings
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+const goHeader = "// Code generated by erst generate-bindings. DO NOT EDIT.\n\n"
+
+// goReserved holds Go keywords and the local names used inside generated
+// methods; parameters with these names get an "Arg" suffix.
+var goReserved = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+	"c": true, "ctx": true, "opts": true, "args": true, "err": true,
+	"inv": true, "resp": true, "ret": true, "out": true,
+}
+
+// generateGo generates a Go package: UDT types with ScVal codecs, a client
+// that builds invocation envelopes and replays them through the simulator,
+// and the shared codec runtime.
+func (g *Generator) generateGo() ([]GeneratedFile, error) {
+	pkg := goPackageName(g.config.PackageName)
+	sources := []struct {
+		path string
+		body string
+	}{
+		{"types.go", g.generateGoTypes(pkg)},
+		{"client.go", g.generateGoClient(pkg)},
+		{"scval.go", goHeader + "package " + pkg + "\n" + goRuntime},
+	}
+
+	files := make([]GeneratedFile, 0, len(sources))
+	for _, src := range sources {
+		formatted, err := format.Source([]byte(src.body))
+		if err != nil {
+			return nil, fmt.Errorf("formatting %s: %w", src.path, err)
+		}
+		files = append(files, GeneratedFile{Path: src.path, Content: string(formatted)})
+	}
+	return files, nil
+}
+
+func (g *Generator) generateGoTypes(pkg string) string {
+	var b strings.Builder
+
+	for _, s := range g.spec.Structs {
+		g.generateGoStruct(&b, s)
+	}
+	for _, e := range g.spec.Enums {
+		cases := make([]enumCase, len(e.Cases))
+		for i, c := range e.Cases {
+			cases[i] = enumCase{c.Name, uint32(c.Value), c.Doc}
+		}
+		g.generateGoEnum(&b, string(e.Name), e.Doc, cases, false)
+	}
+	for _, e := range g.spec.ErrorEnums {
+		cases := make([]enumCase, len(e.Cases))
+		for i, c := range e.Cases {
+			cases[i] = enumCase{c.Name, uint32(c.Value), c.Doc}
+		}
+		g.generateGoEnum(&b, string(e.Name), e.Doc, cases, true)
+	}
+	for _, u := range g.spec.Unions {
+		g.generateGoUnion(&b, u)
+	}
+
+	return goHeader + "package " + pkg + "\n\n" + goImports(b.String(), "fmt") + b.String()
+}
+
+// enumCase is a case of an integer or error enum.
+type enumCase struct {
+	Name  string
+	Value uint32
+	Doc   string
+}
+
+func (g *Generator) generateGoStruct(b *strings.Builder, s xdr.ScSpecUdtStructV0) {
+	name := goExported(string(s.Name))
+	fields := sortedFields(s)
+	tuple := isTupleStruct(s)
+
+	writeGoDoc(b, "", s.Doc, name+" is the contract's "+string(s.Name)+" struct.")
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, f := range fields {
+		writeGoDoc(b, "\t", f.Doc, "")
+		fmt.Fprintf(b, "\t%s %s\n", goExported(f.Name), g.goType(f.Type))
+	}
+	b.WriteString("}\n\n")
+
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = fmt.Sprintf("%q", f.Name)
+	}
+
+	fmt.Fprintf(b, "func encode%s(v %s) (xdr.ScVal, error) {\n", name, name)
+	fmt.Fprintf(b, "\tvals := make([]xdr.ScVal, %d)\n", len(fields))
+	if len(fields) > 0 {
+		b.WriteString("\tvar err error\n")
+	}
+	for i, f := range fields {
+		fmt.Fprintf(b, "\tif vals[%d], err = %s(v.%s); err != nil {\n", i, g.goEncoder(f.Type), goExported(f.Name))
+		fmt.Fprintf(b, "\t\treturn xdr.ScVal{}, fmt.Errorf(\"%s: %%w\", err)\n\t}\n", f.Name)
+	}
+	if tuple {
+		b.WriteString("\treturn newVec(vals), nil\n}\n\n")
+	} else {
+		fmt.Fprintf(b, "\treturn encodeFields([]string{%s}, vals), nil\n}\n\n", strings.Join(names, ", "))
+	}
+
+	fmt.Fprintf(b, "func decode%s(sv xdr.ScVal) (v %s, err error) {\n", name, name)
+	if tuple {
+		fmt.Fprintf(b, "\tvals, err := decodeTupleN(sv, %d)\n", len(fields))
+	} else {
+		fmt.Fprintf(b, "\tvals, err := decodeFields(sv, []string{%s})\n", strings.Join(names, ", "))
+	}
+	b.WriteString("\tif err != nil {\n\t\treturn v, err\n\t}\n")
+	if len(fields) == 0 {
+		b.WriteString("\t_ = vals\n")
+	}
+	for i, f := range fields {
+		fmt.Fprintf(b, "\tif v.%s, err = %s(vals[%d]); err != nil {\n", goExported(f.Name), g.goDecoder(f.Type), i)
+		fmt.Fprintf(b, "\t\treturn v, fmt.Errorf(\"%s: %%w\", err)\n\t}\n", f.Name)
+	}
+	b.WriteString("\treturn v, nil\n}\n\n")
+
+	writeGoMarshalMethods(b, name)
+}
+
+func (g *Generator) generateGoEnum(b *strings.Builder, specName, doc string, cases []enumCase, isError bool) {
+	name := goExported(specName)
+	kind := "enum"
+	if isError {
+		kind = "error enum"
+	}
+
+	writeGoDoc(b, "", doc, fmt.Sprintf("%s is the contract's %s %s.", name, specName, kind))
+	fmt.Fprintf(b, "type %s uint32\n\nconst (\n", name)
+	for _, c := range cases {
+		writeGoDoc(b, "\t", c.Doc, "")
+		fmt.Fprintf(b, "\t%s%s %s = %d\n", name, goExported(c.Name), name, c.Value)
+	}
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(b, "func (v %s) String() string {\n\tswitch v {\n", name)
+	for _, c := range cases {
+		fmt.Fprintf(b, "\tcase %s%s:\n\t\treturn %q\n", name, goExported(c.Name), c.Name)
+	}
+	fmt.Fprintf(b, "\t}\n\treturn fmt.Sprintf(\"%s(%%d)\", uint32(v))\n}\n\n", specName)
+
+	if isError {
+		fmt.Fprintf(b, "func (v %s) Error() string {\n\treturn \"contract error: \" + v.String()\n}\n\n", name)
+	}
+
+	fmt.Fprintf(b, "func (v %s) valid() bool {\n\tswitch v {\n\tcase ", name)
+	for i, c := range cases {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(b, "%s%s", name, goExported(c.Name))
+	}
+	if len(cases) == 0 {
+		b.WriteString("^" + name + "(0)")
+	}
+	b.WriteString(":\n\t\treturn true\n\t}\n\treturn false\n}\n\n")
+
+	encode, decode := "encodeU32", "decodeU32"
+	if isError {
+		encode, decode = "encodeContractError", "decodeContractError"
+	}
+	fmt.Fprintf(b, "func encode%s(v %s) (xdr.ScVal, error) {\n\treturn %s(uint32(v))\n}\n\n", name, name, encode)
+	fmt.Fprintf(b, "func decode%s(sv xdr.ScVal) (%s, error) {\n", name, name)
+	fmt.Fprintf(b, "\tcode, err := %s(sv)\n\tif err != nil {\n\t\treturn 0, err\n\t}\n", decode)
+	fmt.Fprintf(b, "\tif v := %s(code); v.valid() {\n\t\treturn v, nil\n\t}\n", name)
+	fmt.Fprintf(b, "\treturn 0, fmt.Errorf(\"unknown %s case %%d\", code)\n}\n\n", specName)
+
+	writeGoMarshalMethods(b, name)
+}
+
+func (g *Generator) generateGoUnion(b *strings.Builder, u xdr.ScSpecUdtUnionV0) {
+	name := goExported(string(u.Name))
+	kindType := name + "Kind"
+
+	fmt.Fprintf(b, "// %s identifies the case held by a %s.\n", kindType, name)
+	fmt.Fprintf(b, "type %s string\n\nconst (\n", kindType)
+	for _, c := range u.Cases {
+		caseName := unionCaseName(c)
+		fmt.Fprintf(b, "\t%s%s %s = %q\n", kindType, goExported(caseName), kindType, caseName)
+	}
+	b.WriteString(")\n\n")
+
+	writeGoDoc(b, "", u.Doc, fmt.Sprintf("%s is the contract's %s union. Kind selects the case; tuple cases carry their values in the field of the same name.", name, u.Name))
+	fmt.Fprintf(b, "type %s struct {\n\tKind %s\n", name, kindType)
+	for _, c := range u.Cases {
+		if c.Kind == xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0 {
+			caseName := goExported(string(c.TupleCase.Name))
+			fmt.Fprintf(b, "\t%s *%s%s\n", caseName, name, caseName)
+		}
+	}
+	b.WriteString("}\n\n")
+
+	for _, c := range u.Cases {
+		if c.Kind != xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0 {
+			continue
+		}
+		caseName := goExported(string(c.TupleCase.Name))
+		fmt.Fprintf(b, "// %s%s holds the values of the %s case.\n", name, caseName, c.TupleCase.Name)
+		fmt.Fprintf(b, "type %s%s struct {\n", name, caseName)
+		for i, t := range c.TupleCase.Type {
+			fmt.Fprintf(b, "\tV%d %s\n", i, g.goType(t))
+		}
+		b.WriteString("}\n\n")
+	}
+
+	fmt.Fprintf(b, "func encode%s(v %s) (xdr.ScVal, error) {\n\tswitch v.Kind {\n", name, name)
+	for _, c := range u.Cases {
+		caseName := unionCaseName(c)
+		field := goExported(caseName)
+		fmt.Fprintf(b, "\tcase %s%s:\n", kindType, field)
+		if c.Kind != xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0 {
+			fmt.Fprintf(b, "\t\treturn encodeCase(%q), nil\n", caseName)
+			continue
+		}
+		types := c.TupleCase.Type
+		fmt.Fprintf(b, "\t\tif v.%s == nil {\n\t\t\treturn xdr.ScVal{}, fmt.Errorf(\"%s: missing values\")\n\t\t}\n", field, caseName)
+		fmt.Fprintf(b, "\t\tvals := make([]xdr.ScVal, %d)\n", len(types))
+		if len(types) > 0 {
+			b.WriteString("\t\tvar err error\n")
+		}
+		for i, t := range types {
+			fmt.Fprintf(b, "\t\tif vals[%d], err = %s(v.%s.V%d); err != nil {\n", i, g.goEncoder(t), field, i)
+			fmt.Fprintf(b, "\t\t\treturn xdr.ScVal{}, fmt.Errorf(\"%s.%d: %%w\", err)\n\t\t}\n", caseName, i)
+		}
+		fmt.Fprintf(b, "\t\treturn encodeCase(%q, vals...), nil\n", caseName)
+	}
+	fmt.Fprintf(b, "\t}\n\treturn xdr.ScVal{}, fmt.Errorf(\"unknown %s case %%q\", v.Kind)\n}\n\n", u.Name)
+
+	fmt.Fprintf(b, "func decode%s(sv xdr.ScVal) (v %s, err error) {\n", name, name)
+	b.WriteString("\tkind, vals, err := decodeCase(sv)\n\tif err != nil {\n\t\treturn v, err\n\t}\n")
+	fmt.Fprintf(b, "\tv.Kind = %s(kind)\n\tswitch v.Kind {\n", kindType)
+	for _, c := range u.Cases {
+		caseName := unionCaseName(c)
+		field := goExported(caseName)
+		fmt.Fprintf(b, "\tcase %s%s:\n", kindType, field)
+		n := 0
+		if c.Kind == xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0 {
+			n = len(c.TupleCase.Type)
+		}
+		fmt.Fprintf(b, "\t\tif len(vals) != %d {\n\t\t\treturn v, fmt.Errorf(\"%s: expected %d values, got %%d\", len(vals))\n\t\t}\n", n, caseName, n)
+		if c.Kind != xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0 {
+			continue
+		}
+		fmt.Fprintf(b, "\t\tv.%s = &%s%s{}\n", field, name, field)
+		for i, t := range c.TupleCase.Type {
+			fmt.Fprintf(b, "\t\tif v.%s.V%d, err = %s(vals[%d]); err != nil {\n", field, i, g.goDecoder(t), i)
+			fmt.Fprintf(b, "\t\t\treturn v, fmt.Errorf(\"%s.%d: %%w\", err)\n\t\t}\n", caseName, i)
+		}
+	}
+	fmt.Fprintf(b, "\tdefault:\n\t\treturn v, fmt.Errorf(\"unknown %s case %%q\", kind)\n\t}\n\treturn v, nil\n}\n\n", u.Name)
+
+	writeGoMarshalMethods(b, name)
+}
+
+func writeGoMarshalMethods(b *strings.Builder, name string) {
+	fmt.Fprintf(b, "// MarshalScVal encodes v as the contract expects it.\n")
+	fmt.Fprintf(b, "func (v %s) MarshalScVal() (xdr.ScVal, error) {\n\treturn encode%s(v)\n}\n\n", name, name)
+	fmt.Fprintf(b, "// UnmarshalScVal decodes sv into v.\n")
+	fmt.Fprintf(b, "func (v *%s) UnmarshalScVal(sv xdr.ScVal) error {\n", name)
+	fmt.Fprintf(b, "\tout, err := decode%s(sv)\n\tif err != nil {\n\t\treturn err\n\t}\n\t*v = out\n\treturn nil\n}\n\n", name)
+}
+
+func (g *Generator) generateGoClient(pkg string) string {
+	var b strings.Builder
+
+	b.WriteString(goClientRuntime)
+
+	for _, fn := range g.spec.Functions {
+		g.generateGoFunction(&b, fn)
+	}
+
+	body := b.String()
+	return goHeader + "package " + pkg + "\n\n" +
+		goImports(body, "bytes", "context", "encoding/json", "fmt", "os/exec", "regexp", "strconv",
+			"github.com/stellar/go-stellar-sdk/strkey") +
+		body
+}
+
+func (g *Generator) generateGoFunction(b *strings.Builder, fn xdr.ScSpecFunctionV0) {
+	method := goExported(string(fn.Name))
+
+	params := make([]string, 0, len(fn.Inputs))
+	names := make([]string, 0, len(fn.Inputs))
+	for _, in := range fn.Inputs {
+		p := goParam(in.Name)
+		names = append(names, p)
+		params = append(params, fmt.Sprintf("%s %s", p, g.goType(in.Type)))
+	}
+	paramList := strings.Join(append([]string{"opts InvokeOptions"}, params...), ", ")
+	callArgs := strings.Join(append([]string{"opts"}, names...), ", ")
+
+	fmt.Fprintf(b, "// %s builds an invocation of %s.\n", method, fn.Name)
+	if fn.Doc != "" {
+		b.WriteString("//\n")
+		writeGoDoc(b, "", fn.Doc, "")
+	}
+	fmt.Fprintf(b, "func (c *Client) %s(%s) (*Invocation, error) {\n", method, paramList)
+	fmt.Fprintf(b, "\targs := make([]xdr.ScVal, %d)\n", len(fn.Inputs))
+	if len(fn.Inputs) > 0 {
+		b.WriteString("\tvar err error\n")
+	}
+	for i, in := range fn.Inputs {
+		fmt.Fprintf(b, "\tif args[%d], err = %s(%s); err != nil {\n", i, g.goEncoder(in.Type), names[i])
+		fmt.Fprintf(b, "\t\treturn nil, fmt.Errorf(\"%s: %%w\", err)\n\t}\n", in.Name)
+	}
+	fmt.Fprintf(b, "\treturn c.invoke(%q, args, opts)\n}\n\n", fn.Name)
+
+	out, errEnum := g.goResult(fn)
+	fmt.Fprintf(b, "// Simulate%s replays %s through c.Runner", method, fn.Name)
+	if out != nil {
+		b.WriteString(" and decodes its result")
+	}
+	b.WriteString(".\n")
+	ctxParams := "ctx context.Context, " + paramList
+	if out == nil {
+		fmt.Fprintf(b, "func (c *Client) Simulate%s(%s) (*SimulationResponse, error) {\n", method, ctxParams)
+		fmt.Fprintf(b, "\tinv, err := c.%s(%s)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n", method, callArgs)
+		b.WriteString("\tresp, err := c.simulate(ctx, inv)\n")
+		if errEnum != "" {
+			b.WriteString("\tif err != nil {\n")
+			fmt.Fprintf(b, "\t\tif code, ok := contractErrorCode(resp); ok {\n\t\t\treturn resp, %s(code)\n\t\t}\n", errEnum)
+			b.WriteString("\t}\n")
+		}
+		b.WriteString("\treturn resp, err\n}\n\n")
+		return
+	}
+
+	fmt.Fprintf(b, "func (c *Client) Simulate%s(%s) (out %s, resp *SimulationResponse, err error) {\n", method, ctxParams, g.goType(*out))
+	fmt.Fprintf(b, "\tinv, err := c.%s(%s)\n\tif err != nil {\n\t\treturn out, nil, err\n\t}\n", method, callArgs)
+	b.WriteString("\tif resp, err = c.simulate(ctx, inv); err != nil {\n")
+	if errEnum != "" {
+		fmt.Fprintf(b, "\t\tif code, ok := contractErrorCode(resp); ok {\n\t\t\treturn out, resp, %s(code)\n\t\t}\n", errEnum)
+	}
+	b.WriteString("\t\treturn out, resp, err\n\t}\n")
+	b.WriteString("\tret, err := returnValue(resp)\n\tif err != nil {\n\t\treturn out, resp, err\n\t}\n")
+	fmt.Fprintf(b, "\tout, err = %s(ret)\n\treturn out, resp, err\n}\n\n", g.goDecoder(*out))
+}
+
+// goResult returns the decoded return type of fn, unwrapping Result, and the
+// Go name of its error enum when the error type is one. out is nil for
+// functions returning nothing.
+func (g *Generator) goResult(fn xdr.ScSpecFunctionV0) (out *xdr.ScSpecTypeDef, errEnum string) {
+	if len(fn.Outputs) == 0 {
+		return nil, ""
+	}
+	td := fn.Outputs[0]
+	if td.Type == xdr.ScSpecTypeScSpecTypeResult && td.Result != nil {
+		if et := td.Result.ErrorType; et.Type == xdr.ScSpecTypeScSpecTypeUdt && et.Udt != nil && g.isErrorEnum(et.Udt.Name) {
+			errEnum = goExported(et.Udt.Name)
+		}
+		td = td.Result.OkType
+	}
+	if td.Type == xdr.ScSpecTypeScSpecTypeVoid {
+		return nil, errEnum
+	}
+	return &td, errEnum
+}
+
+func (g *Generator) isErrorEnum(name string) bool {
+	for _, e := range g.spec.ErrorEnums {
+		if string(e.Name) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// goType maps a spec type to its Go representation.
+func (g *Generator) goType(td xdr.ScSpecTypeDef) string {
+	switch td.Type {
+	case xdr.ScSpecTypeScSpecTypeBool:
+		return "bool"
+	case xdr.ScSpecTypeScSpecTypeVoid:
+		return "struct{}"
+	case xdr.ScSpecTypeScSpecTypeError:
+		return "xdr.ScError"
+	case xdr.ScSpecTypeScSpecTypeU32:
+		return "uint32"
+	case xdr.ScSpecTypeScSpecTypeI32:
+		return "int32"
+	case xdr.ScSpecTypeScSpecTypeU64, xdr.ScSpecTypeScSpecTypeTimepoint, xdr.ScSpecTypeScSpecTypeDuration:
+		return "uint64"
+	case xdr.ScSpecTypeScSpecTypeI64:
+		return "int64"
+	case xdr.ScSpecTypeScSpecTypeU128, xdr.ScSpecTypeScSpecTypeI128,
+		xdr.ScSpecTypeScSpecTypeU256, xdr.ScSpecTypeScSpecTypeI256:
+		return "*big.Int"
+	case xdr.ScSpecTypeScSpecTypeBytes:
+		return "[]byte"
+	case xdr.ScSpecTypeScSpecTypeBytesN:
+		if td.BytesN != nil {
+			return fmt.Sprintf("[%d]byte", td.BytesN.N)
+		}
+		return "[]byte"
+	case xdr.ScSpecTypeScSpecTypeString, xdr.ScSpecTypeScSpecTypeSymbol,
+		xdr.ScSpecTypeScSpecTypeAddress, xdr.ScSpecTypeScSpecTypeMuxedAddress:
+		return "string"
+	case xdr.ScSpecTypeScSpecTypeOption:
+		if td.Option != nil {
+			return "*" + g.goType(td.Option.ValueType)
+		}
+	case xdr.ScSpecTypeScSpecTypeResult:
+		if td.Result != nil {
+			return g.goType(td.Result.OkType)
+		}
+	case xdr.ScSpecTypeScSpecTypeVec:
+		if td.Vec != nil {
+			return "[]" + g.goType(td.Vec.ElementType)
+		}
+	case xdr.ScSpecTypeScSpecTypeMap:
+		if td.Map != nil {
+			return fmt.Sprintf("[]MapEntry[%s, %s]", g.goType(td.Map.KeyType), g.goType(td.Map.ValueType))
+		}
+	case xdr.ScSpecTypeScSpecTypeTuple:
+		return "[]xdr.ScVal"
+	case xdr.ScSpecTypeScSpecTypeUdt:
+		if td.Udt != nil {
+			return goExported(td.Udt.Name)
+		}
+	}
+	return "xdr.ScVal"
+}
+
+// goEncoder returns an expression of type func(T) (xdr.ScVal, error) for
+// the Go type of td.
+func (g *Generator) goEncoder(td xdr.ScSpecTypeDef) string {
+	switch td.Type {
+	case xdr.ScSpecTypeScSpecTypeBytesN:
+		if td.BytesN != nil {
+			return fmt.Sprintf("func(v [%d]byte) (xdr.ScVal, error) { return encodeBytes(v[:]) }", td.BytesN.N)
+		}
+	case xdr.ScSpecTypeScSpecTypeOption:
+		if td.Option != nil {
+			return fmt.Sprintf("encodeOption(%s)", g.goEncoder(td.Option.ValueType))
+		}
+	case xdr.ScSpecTypeScSpecTypeResult:
+		if td.Result != nil {
+			return g.goEncoder(td.Result.OkType)
+		}
+	case xdr.ScSpecTypeScSpecTypeVec:
+		if td.Vec != nil {
+			return fmt.Sprintf("encodeVec(%s)", g.goEncoder(td.Vec.ElementType))
+		}
+	case xdr.ScSpecTypeScSpecTypeMap:
+		if td.Map != nil {
+			return fmt.Sprintf("encodeMap(%s, %s)", g.goEncoder(td.Map.KeyType), g.goEncoder(td.Map.ValueType))
+		}
+	case xdr.ScSpecTypeScSpecTypeUdt:
+		if td.Udt != nil {
+			return "encode" + goExported(td.Udt.Name)
+		}
+	}
+	return "encode" + goCodecSuffix(td)
+}
+
+// goDecoder returns an expression of type func(xdr.ScVal) (T, error) for
+// the Go type of td.
+func (g *Generator) goDecoder(td xdr.ScSpecTypeDef) string {
+	switch td.Type {
+	case xdr.ScSpecTypeScSpecTypeBytesN:
+		if td.BytesN != nil {
+			return fmt.Sprintf("func(v xdr.ScVal) (out [%d]byte, err error) { b, err := decodeBytesN(v, %d); copy(out[:], b); return out, err }",
+				td.BytesN.N, td.BytesN.N)
+		}
+	case xdr.ScSpecTypeScSpecTypeOption:
+		if td.Option != nil {
+			return fmt.Sprintf("decodeOption(%s)", g.goDecoder(td.Option.ValueType))
+		}
+	case xdr.ScSpecTypeScSpecTypeResult:
+		if td.Result != nil {
+			return g.goDecoder(td.Result.OkType)
+		}
+	case xdr.ScSpecTypeScSpecTypeVec:
+		if td.Vec != nil {
+			return fmt.Sprintf("decodeVec(%s)", g.goDecoder(td.Vec.ElementType))
+		}
+	case xdr.ScSpecTypeScSpecTypeMap:
+		if td.Map != nil {
+			return fmt.Sprintf("decodeMap(%s, %s)", g.goDecoder(td.Map.KeyType), g.goDecoder(td.Map.ValueType))
+		}
+	case xdr.ScSpecTypeScSpecTypeUdt:
+		if td.Udt != nil {
+			return "decode" + goExported(td.Udt.Name)
+		}
+	}
+	return "decode" + goCodecSuffix(td)
+}
+
+// goCodecSuffix names the runtime codec pair for scalar spec types.
+func goCodecSuffix(td xdr.ScSpecTypeDef) string {
+	switch td.Type {
+	case xdr.ScSpecTypeScSpecTypeBool:
+		return "Bool"
+	case xdr.ScSpecTypeScSpecTypeVoid:
+		return "Void"
+	case xdr.ScSpecTypeScSpecTypeError:
+		return "Error"
+	case xdr.ScSpecTypeScSpecTypeU32:
+		return "U32"
+	case xdr.ScSpecTypeScSpecTypeI32:
+		return "I32"
+	case xdr.ScSpecTypeScSpecTypeU64:
+		return "U64"
+	case xdr.ScSpecTypeScSpecTypeI64:
+		return "I64"
+	case xdr.ScSpecTypeScSpecTypeTimepoint:
+		return "Timepoint"
+	case xdr.ScSpecTypeScSpecTypeDuration:
+		return "Duration"
+	case xdr.ScSpecTypeScSpecTypeU128:
+		return "U128"
+	case xdr.ScSpecTypeScSpecTypeI128:
+		return "I128"
+	case xdr.ScSpecTypeScSpecTypeU256:
+		return "U256"
+	case xdr.ScSpecTypeScSpecTypeI256:
+		return "I256"
+	case xdr.ScSpecTypeScSpecTypeBytes, xdr.ScSpecTypeScSpecTypeBytesN:
+		return "Bytes"
+	case xdr.ScSpecTypeScSpecTypeString:
+		return "String"
+	case xdr.ScSpecTypeScSpecTypeSymbol:
+		return "Symbol"
+	case xdr.ScSpecTypeScSpecTypeAddress, xdr.ScSpecTypeScSpecTypeMuxedAddress:
+		return "Address"
+	case xdr.ScSpecTypeScSpecTypeTuple:
+		return "Tuple"
+	}
+	return "Val"
+}
+
+// goImports returns an import block for the packages in extra, plus xdr and
+// math/big, that body references.
+func goImports(body string, extra ...string) string {
+	var imports []string
+	for _, pkg := range append([]string{"github.com/stellar/go-stellar-sdk/xdr", "math/big"}, extra...) {
+		base := pkg[strings.LastIndex(pkg, "/")+1:]
+		if strings.Contains(body, base+".") {
+			imports = append(imports, pkg)
+		}
+	}
+	if len(imports) == 0 {
+		return ""
+	}
+	sort.Strings(imports)
+
+	var b strings.Builder
+	b.WriteString("import (\n")
+	for _, pkg := range imports {
+		fmt.Fprintf(&b, "\t%q\n", pkg)
+	}
+	b.WriteString(")\n\n")
+	return b.String()
+}
+
+func writeGoDoc(b *strings.Builder, indent, doc, fallback string) {
+	if doc == "" {
+		doc = fallback
+	}
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(doc), "\n") {
+		fmt.Fprintf(b, "%s// %s\n", indent, strings.TrimSpace(line))
+	}
+}
+
+// goExported converts a spec identifier such as "max_supply" to an exported
+// Go identifier ("MaxSupply").
+func goExported(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	out := b.String()
+	if out == "" || !unicode.IsLetter(rune(out[0])) {
+		out = "V" + out
+	}
+	return out
+}
+
+// goParam converts a spec identifier to a lowerCamel Go parameter name.
+func goParam(name string) string {
+	exported := goExported(name)
+	p := strings.ToLower(exported[:1]) + exported[1:]
+	if goReserved[p] {
+		p += "Arg"
+	}
+	return p
+}
+
+// goPackageName derives a Go package name from the bindings package name.
+func goPackageName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	pkg := b.String()
+	if pkg == "" || pkg[0] >= '0' && pkg[0] <= '9' {
+		pkg = "contract" + pkg
+	}
+	return pkg
+}
+
+// sortedFields returns a struct's fields in the order Soroban stores them:
+// by name for map-encoded structs, by position for tuple structs.
+func sortedFields(s xdr.ScSpecUdtStructV0) []xdr.ScSpecUdtStructFieldV0 {
+	fields := append([]xdr.ScSpecUdtStructFieldV0(nil), s.Fields...)
+	if !isTupleStruct(s) {
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	}
+	return fields
+}
+
+// isTupleStruct reports whether a struct has positional fields ("0", "1",
+// ...) and so is encoded as a vector rather than a map.
+func isTupleStruct(s xdr.ScSpecUdtStructV0) bool {
+	if len(s.Fields) == 0 {
+		return false
+	}
+	for i, f := range s.Fields {
+		if f.Name != fmt.Sprint(i) {
+			return false
+		}
+	}
+	return true
+}
+
+func unionCaseName(c xdr.ScSpecUdtUnionCaseV0) string {
+	if c.Kind == xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0 {
+		return c.TupleCase.Name
+	}
+	return c.VoidCase.Name
+}
+
+// goClientRuntime is the fixed part of client.go.
+const goClientRuntime = `// SimulationRequest is the part of an erst simulator request the client
+// sends.
+type SimulationRequest struct {
+	EnvelopeXdr   string            ` + "`json:\"envelope_xdr\"`" + `
+	ResultMetaXdr string            ` + "`json:\"result_meta_xdr\"`" + `
+	LedgerEntries map[string]string ` + "`json:\"ledger_entries,omitempty\"`" + `
+}
+
+// SimulationResponse is the part of an erst simulator response the client
+// reads.
+type SimulationResponse struct {
+	// Status is "success" or "error".
+	Status string ` + "`json:\"status\"`" + `
+	Error  string ` + "`json:\"error,omitempty\"`" + `
+	// ReturnValue is the base64 ScVal the invocation returned.
+	ReturnValue string   ` + "`json:\"return_value,omitempty\"`" + `
+	Events      []string ` + "`json:\"events,omitempty\"`" + `
+}
+
+// Runner replays simulation requests. It has the shape of the erst
+// simulator runner; ExecRunner implements it with the erst-sim binary.
+type Runner interface {
+	Run(ctx context.Context, req *SimulationRequest) (*SimulationResponse, error)
+	Close() error
+}
+
+// ExecRunner runs the erst-sim binary once per request, exchanging JSON on
+// stdin and stdout.
+type ExecRunner struct {
+	// BinaryPath is the simulator binary; empty looks up erst-sim in PATH.
+	BinaryPath string
+}
+
+// Run replays req through the simulator binary.
+func (r *ExecRunner) Run(ctx context.Context, req *SimulationRequest) (*SimulationResponse, error) {
+	path := r.BinaryPath
+	if path == "" {
+		path = "erst-sim"
+	}
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("running %s: %w: %s", path, err, stderr.String())
+	}
+	var resp SimulationResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("decoding simulator response: %w", err)
+	}
+	return &resp, nil
+}
+
+// Close implements Runner; ExecRunner holds no resources.
+func (r *ExecRunner) Close() error { return nil }
+
+// Client builds invocations of the contract and replays them through the
+// erst simulator.
+type Client struct {
+	// ContractID is the strkey (C...) of the deployed contract.
+	ContractID string
+	// Runner replays invocations for the Simulate methods.
+	Runner Runner
+	// LedgerEntries is the ledger state to simulate against, keyed by base64
+	// LedgerKey, e.g. from an erst snapshot.
+	LedgerEntries map[string]string
+}
+
+// NewClient returns a client for the contract with the given ID.
+func NewClient(contractID string, runner Runner) *Client {
+	return &Client{ContractID: contractID, Runner: runner}
+}
+
+// InvokeOptions describes the transaction wrapping an invocation.
+type InvokeOptions struct {
+	// Source is the account (G...) submitting the transaction.
+	Source string
+	// Sequence is the transaction's sequence number.
+	Sequence int64
+	// Fee is the inclusion fee in stroops; defaults to 100.
+	Fee uint32
+}
+
+// Invocation is a built contract call.
+type Invocation struct {
+	Function string
+	Args     []xdr.ScVal
+	Envelope xdr.TransactionEnvelope
+}
+
+// EnvelopeXDR returns the base64 transaction envelope.
+func (i *Invocation) EnvelopeXDR() (string, error) {
+	return xdr.MarshalBase64(i.Envelope)
+}
+
+// SimulationError is returned when a replayed invocation fails.
+type SimulationError struct {
+	Response *SimulationResponse
+}
+
+func (e *SimulationError) Error() string {
+	return "simulation failed: " + e.Response.Error
+}
+
+func (c *Client) invoke(function string, args []xdr.ScVal, opts InvokeOptions) (*Invocation, error) {
+	raw, err := strkey.Decode(strkey.VersionByteContract, c.ContractID)
+	if err != nil {
+		return nil, fmt.Errorf("contract id: %w", err)
+	}
+	var id xdr.ContractId
+	copy(id[:], raw)
+
+	source, err := xdr.AddressToMuxedAccount(opts.Source)
+	if err != nil {
+		return nil, fmt.Errorf("source: %w", err)
+	}
+	fee := opts.Fee
+	if fee == 0 {
+		fee = 100
+	}
+
+	op := xdr.InvokeHostFunctionOp{
+		HostFunction: xdr.HostFunction{
+			Type: xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+			InvokeContract: &xdr.InvokeContractArgs{
+				ContractAddress: xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &id},
+				FunctionName:    xdr.ScSymbol(function),
+				Args:            args,
+			},
+		},
+	}
+	tx := xdr.Transaction{
+		SourceAccount: source,
+		Fee:           xdr.Uint32(fee),
+		SeqNum:        xdr.SequenceNumber(opts.Sequence),
+		Cond:          xdr.Preconditions{Type: xdr.PreconditionTypePrecondNone},
+		Memo:          xdr.Memo{Type: xdr.MemoTypeMemoNone},
+		Operations: []xdr.Operation{{
+			Body: xdr.OperationBody{Type: xdr.OperationTypeInvokeHostFunction, InvokeHostFunctionOp: &op},
+		}},
+	}
+	return &Invocation{
+		Function: function,
+		Args:     args,
+		Envelope: xdr.TransactionEnvelope{Type: xdr.EnvelopeTypeEnvelopeTypeTx, V1: &xdr.TransactionV1Envelope{Tx: tx}},
+	}, nil
+}
+
+// simulate replays inv through c.Runner. A failed replay returns the
+// response together with a *SimulationError.
+func (c *Client) simulate(ctx context.Context, inv *Invocation) (*SimulationResponse, error) {
+	if c.Runner == nil {
+		return nil, fmt.Errorf("no simulator runner configured")
+	}
+	envelope, err := inv.EnvelopeXDR()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Runner.Run(ctx, &SimulationRequest{
+		EnvelopeXdr:   envelope,
+		LedgerEntries: c.LedgerEntries,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != "success" {
+		return resp, &SimulationError{Response: resp}
+	}
+	return resp, nil
+}
+
+// returnValue decodes the return value reported by a successful replay.
+func returnValue(resp *SimulationResponse) (xdr.ScVal, error) {
+	var ret xdr.ScVal
+	if resp.ReturnValue == "" {
+		return ret, fmt.Errorf("simulator did not report a return value")
+	}
+	if err := xdr.SafeUnmarshalBase64(resp.ReturnValue, &ret); err != nil {
+		return ret, fmt.Errorf("decoding return value: %w", err)
+	}
+	return ret, nil
+}
+
+// contractErrorCode extracts the code of a contract error from a failed
+// replay, e.g. "Error(Contract, #3)".
+func contractErrorCode(resp *SimulationResponse) (uint32, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	m := contractErrorPattern.FindStringSubmatch(resp.Error)
+	if m == nil {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(m[1], 10, 32)
+	return uint32(code), err == nil
+}
+
+var contractErrorPattern = regexp.MustCompile("Error\\(Contract, #(\\d+)\\)")
+
+`
+
+// goRuntime is scval.go, the ScVal codecs shared by the generated types and
+// client.
+const goRuntime = `
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/stellar/go-stellar-sdk/strkey"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// MapEntry is one key-value pair of a contract map. Entries must be in
+// ascending key order; the host rejects unsorted maps.
+type MapEntry[K, V any] struct {
+	Key   K
+	Value V
+}
+
+func typeError(want string, v xdr.ScVal) error {
+	return fmt.Errorf("expected %s, got %s", want, v.Type)
+}
+
+func encodeVal(v xdr.ScVal) (xdr.ScVal, error) { return v, nil }
+
+func decodeVal(v xdr.ScVal) (xdr.ScVal, error) { return v, nil }
+
+func encodeBool(v bool) (xdr.ScVal, error) {
+	return xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &v}, nil
+}
+
+func decodeBool(v xdr.ScVal) (bool, error) {
+	b, ok := v.GetB()
+	if !ok {
+		return false, typeError("bool", v)
+	}
+	return b, nil
+}
+
+func encodeVoid(struct{}) (xdr.ScVal, error) {
+	return xdr.ScVal{Type: xdr.ScValTypeScvVoid}, nil
+}
+
+func decodeVoid(v xdr.ScVal) (struct{}, error) {
+	if v.Type != xdr.ScValTypeScvVoid {
+		return struct{}{}, typeError("void", v)
+	}
+	return struct{}{}, nil
+}
+
+func encodeError(v xdr.ScError) (xdr.ScVal, error) {
+	return xdr.ScVal{Type: xdr.ScValTypeScvError, Error: &v}, nil
+}
+
+func decodeError(v xdr.ScVal) (xdr.ScError, error) {
+	e, ok := v.GetError()
+	if !ok {
+		return e, typeError("error", v)
+	}
+	return e, nil
+}
+
+func encodeContractError(code uint32) (xdr.ScVal, error) {
+	c := xdr.Uint32(code)
+	return encodeError(xdr.ScError{Type: xdr.ScErrorTypeSceContract, ContractCode: &c})
+}
+
+func decodeContractError(v xdr.ScVal) (uint32, error) {
+	e, err := decodeError(v)
+	if err != nil {
+		return 0, err
+	}
+	if e.Type != xdr.ScErrorTypeSceContract || e.ContractCode == nil {
+		return 0, fmt.Errorf("expected contract error, got %s", e.Type)
+	}
+	return uint32(*e.ContractCode), nil
+}
+
+func encodeU32(v uint32) (xdr.ScVal, error) {
+	u := xdr.Uint32(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &u}, nil
+}
+
+func decodeU32(v xdr.ScVal) (uint32, error) {
+	u, ok := v.GetU32()
+	if !ok {
+		return 0, typeError("u32", v)
+	}
+	return uint32(u), nil
+}
+
+func encodeI32(v int32) (xdr.ScVal, error) {
+	i := xdr.Int32(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvI32, I32: &i}, nil
+}
+
+func decodeI32(v xdr.ScVal) (int32, error) {
+	i, ok := v.GetI32()
+	if !ok {
+		return 0, typeError("i32", v)
+	}
+	return int32(i), nil
+}
+
+func encodeU64(v uint64) (xdr.ScVal, error) {
+	u := xdr.Uint64(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvU64, U64: &u}, nil
+}
+
+func decodeU64(v xdr.ScVal) (uint64, error) {
+	u, ok := v.GetU64()
+	if !ok {
+		return 0, typeError("u64", v)
+	}
+	return uint64(u), nil
+}
+
+func encodeI64(v int64) (xdr.ScVal, error) {
+	i := xdr.Int64(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvI64, I64: &i}, nil
+}
+
+func decodeI64(v xdr.ScVal) (int64, error) {
+	i, ok := v.GetI64()
+	if !ok {
+		return 0, typeError("i64", v)
+	}
+	return int64(i), nil
+}
+
+func encodeTimepoint(v uint64) (xdr.ScVal, error) {
+	t := xdr.TimePoint(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvTimepoint, Timepoint: &t}, nil
+}
+
+func decodeTimepoint(v xdr.ScVal) (uint64, error) {
+	t, ok := v.GetTimepoint()
+	if !ok {
+		return 0, typeError("timepoint", v)
+	}
+	return uint64(t), nil
+}
+
+func encodeDuration(v uint64) (xdr.ScVal, error) {
+	d := xdr.Duration(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvDuration, Duration: &d}, nil
+}
+
+func decodeDuration(v xdr.ScVal) (uint64, error) {
+	d, ok := v.GetDuration()
+	if !ok {
+		return 0, typeError("duration", v)
+	}
+	return uint64(d), nil
+}
+
+var mask64 = new(big.Int).SetUint64(^uint64(0))
+
+// toWords splits v into n big-endian 64-bit words of its two's complement
+// representation, checking that it fits.
+func toWords(v *big.Int, n int, signed bool) ([]uint64, error) {
+	if v == nil {
+		return nil, fmt.Errorf("nil integer")
+	}
+	bits := uint(64 * n)
+	limit := new(big.Int).Lsh(big.NewInt(1), bits)
+	x := new(big.Int).Set(v)
+	if signed {
+		half := new(big.Int).Rsh(limit, 1)
+		if x.Cmp(half) >= 0 || x.Cmp(new(big.Int).Neg(half)) < 0 {
+			return nil, fmt.Errorf("%s overflows i%d", v, bits)
+		}
+		if x.Sign() < 0 {
+			x.Add(x, limit)
+		}
+	} else if x.Sign() < 0 || x.Cmp(limit) >= 0 {
+		return nil, fmt.Errorf("%s overflows u%d", v, bits)
+	}
+	words := make([]uint64, n)
+	for i := n - 1; i >= 0; i-- {
+		words[i] = new(big.Int).And(x, mask64).Uint64()
+		x.Rsh(x, 64)
+	}
+	return words, nil
+}
+
+// fromWords is the inverse of toWords.
+func fromWords(words []uint64, signed bool) *big.Int {
+	x := new(big.Int)
+	for _, w := range words {
+		x.Lsh(x, 64)
+		x.Or(x, new(big.Int).SetUint64(w))
+	}
+	if signed && words[0]>>63 == 1 {
+		x.Sub(x, new(big.Int).Lsh(big.NewInt(1), uint(64*len(words))))
+	}
+	return x
+}
+
+func encodeU128(v *big.Int) (xdr.ScVal, error) {
+	w, err := toWords(v, 2, false)
+	if err != nil {
+		return xdr.ScVal{}, err
+	}
+	parts := xdr.UInt128Parts{Hi: xdr.Uint64(w[0]), Lo: xdr.Uint64(w[1])}
+	return xdr.ScVal{Type: xdr.ScValTypeScvU128, U128: &parts}, nil
+}
+
+func decodeU128(v xdr.ScVal) (*big.Int, error) {
+	p, ok := v.GetU128()
+	if !ok {
+		return nil, typeError("u128", v)
+	}
+	return fromWords([]uint64{uint64(p.Hi), uint64(p.Lo)}, false), nil
+}
+
+func encodeI128(v *big.Int) (xdr.ScVal, error) {
+	w, err := toWords(v, 2, true)
+	if err != nil {
+		return xdr.ScVal{}, err
+	}
+	parts := xdr.Int128Parts{Hi: xdr.Int64(w[0]), Lo: xdr.Uint64(w[1])}
+	return xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &parts}, nil
+}
+
+func decodeI128(v xdr.ScVal) (*big.Int, error) {
+	p, ok := v.GetI128()
+	if !ok {
+		return nil, typeError("i128", v)
+	}
+	return fromWords([]uint64{uint64(p.Hi), uint64(p.Lo)}, true), nil
+}
+
+func encodeU256(v *big.Int) (xdr.ScVal, error) {
+	w, err := toWords(v, 4, false)
+	if err != nil {
+		return xdr.ScVal{}, err
+	}
+	parts := xdr.UInt256Parts{HiHi: xdr.Uint64(w[0]), HiLo: xdr.Uint64(w[1]), LoHi: xdr.Uint64(w[2]), LoLo: xdr.Uint64(w[3])}
+	return xdr.ScVal{Type: xdr.ScValTypeScvU256, U256: &parts}, nil
+}
+
+func decodeU256(v xdr.ScVal) (*big.Int, error) {
+	p, ok := v.GetU256()
+	if !ok {
+		return nil, typeError("u256", v)
+	}
+	return fromWords([]uint64{uint64(p.HiHi), uint64(p.HiLo), uint64(p.LoHi), uint64(p.LoLo)}, false), nil
+}
+
+func encodeI256(v *big.Int) (xdr.ScVal, error) {
+	w, err := toWords(v, 4, true)
+	if err != nil {
+		return xdr.ScVal{}, err
+	}
+	parts := xdr.Int256Parts{HiHi: xdr.Int64(w[0]), HiLo: xdr.Uint64(w[1]), LoHi: xdr.Uint64(w[2]), LoLo: xdr.Uint64(w[3])}
+	return xdr.ScVal{Type: xdr.ScValTypeScvI256, I256: &parts}, nil
+}
+
+func decodeI256(v xdr.ScVal) (*big.Int, error) {
+	p, ok := v.GetI256()
+	if !ok {
+		return nil, typeError("i256", v)
+	}
+	return fromWords([]uint64{uint64(p.HiHi), uint64(p.HiLo), uint64(p.LoHi), uint64(p.LoLo)}, true), nil
+}
+
+func encodeBytes(v []byte) (xdr.ScVal, error) {
+	b := xdr.ScBytes(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvBytes, Bytes: &b}, nil
+}
+
+func decodeBytes(v xdr.ScVal) ([]byte, error) {
+	b, ok := v.GetBytes()
+	if !ok {
+		return nil, typeError("bytes", v)
+	}
+	return []byte(b), nil
+}
+
+func decodeBytesN(v xdr.ScVal, n int) ([]byte, error) {
+	b, err := decodeBytes(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != n {
+		return nil, fmt.Errorf("expected %d bytes, got %d", n, len(b))
+	}
+	return b, nil
+}
+
+func encodeString(v string) (xdr.ScVal, error) {
+	s := xdr.ScString(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvString, Str: &s}, nil
+}
+
+func decodeString(v xdr.ScVal) (string, error) {
+	s, ok := v.GetStr()
+	if !ok {
+		return "", typeError("string", v)
+	}
+	return string(s), nil
+}
+
+func encodeSymbol(v string) (xdr.ScVal, error) {
+	s := xdr.ScSymbol(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &s}, nil
+}
+
+func decodeSymbol(v xdr.ScVal) (string, error) {
+	s, ok := v.GetSym()
+	if !ok {
+		return "", typeError("symbol", v)
+	}
+	return string(s), nil
+}
+
+// encodeAddress encodes an account (G...) or contract (C...) strkey.
+func encodeAddress(v string) (xdr.ScVal, error) {
+	var addr xdr.ScAddress
+	if strings.HasPrefix(v, "C") {
+		raw, err := strkey.Decode(strkey.VersionByteContract, v)
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		var id xdr.ContractId
+		copy(id[:], raw)
+		addr = xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &id}
+	} else {
+		account, err := xdr.AddressToAccountId(v)
+		if err != nil {
+			return xdr.ScVal{}, err
+		}
+		addr = xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &account}
+	}
+	return xdr.ScVal{Type: xdr.ScValTypeScvAddress, Address: &addr}, nil
+}
+
+func decodeAddress(v xdr.ScVal) (string, error) {
+	addr, ok := v.GetAddress()
+	if !ok {
+		return "", typeError("address", v)
+	}
+	return addr.String()
+}
+
+func newVec(vals []xdr.ScVal) xdr.ScVal {
+	vec := xdr.ScVec(vals)
+	p := &vec
+	return xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: &p}
+}
+
+func vecItems(v xdr.ScVal) ([]xdr.ScVal, error) {
+	p, ok := v.GetVec()
+	if !ok || p == nil {
+		return nil, typeError("vec", v)
+	}
+	return *p, nil
+}
+
+func newMap(entries []xdr.ScMapEntry) xdr.ScVal {
+	m := xdr.ScMap(entries)
+	p := &m
+	return xdr.ScVal{Type: xdr.ScValTypeScvMap, Map: &p}
+}
+
+func mapEntries(v xdr.ScVal) ([]xdr.ScMapEntry, error) {
+	p, ok := v.GetMap()
+	if !ok || p == nil {
+		return nil, typeError("map", v)
+	}
+	return *p, nil
+}
+
+func encodeVec[T any](enc func(T) (xdr.ScVal, error)) func([]T) (xdr.ScVal, error) {
+	return func(vs []T) (xdr.ScVal, error) {
+		vals := make([]xdr.ScVal, len(vs))
+		for i, v := range vs {
+			var err error
+			if vals[i], err = enc(v); err != nil {
+				return xdr.ScVal{}, fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		return newVec(vals), nil
+	}
+}
+
+func decodeVec[T any](dec func(xdr.ScVal) (T, error)) func(xdr.ScVal) ([]T, error) {
+	return func(v xdr.ScVal) ([]T, error) {
+		items, err := vecItems(v)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]T, len(items))
+		for i, item := range items {
+			if out[i], err = dec(item); err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+		return out, nil
+	}
+}
+
+func encodeOption[T any](enc func(T) (xdr.ScVal, error)) func(*T) (xdr.ScVal, error) {
+	return func(v *T) (xdr.ScVal, error) {
+		if v == nil {
+			return encodeVoid(struct{}{})
+		}
+		return enc(*v)
+	}
+}
+
+func decodeOption[T any](dec func(xdr.ScVal) (T, error)) func(xdr.ScVal) (*T, error) {
+	return func(v xdr.ScVal) (*T, error) {
+		if v.Type == xdr.ScValTypeScvVoid {
+			return nil, nil
+		}
+		x, err := dec(v)
+		if err != nil {
+			return nil, err
+		}
+		return &x, nil
+	}
+}
+
+func encodeMap[K, V any](ek func(K) (xdr.ScVal, error), ev func(V) (xdr.ScVal, error)) func([]MapEntry[K, V]) (xdr.ScVal, error) {
+	return func(es []MapEntry[K, V]) (xdr.ScVal, error) {
+		entries := make([]xdr.ScMapEntry, len(es))
+		for i, e := range es {
+			var err error
+			if entries[i].Key, err = ek(e.Key); err != nil {
+				return xdr.ScVal{}, fmt.Errorf("key %d: %w", i, err)
+			}
+			if entries[i].Val, err = ev(e.Value); err != nil {
+				return xdr.ScVal{}, fmt.Errorf("value %d: %w", i, err)
+			}
+		}
+		return newMap(entries), nil
+	}
+}
+
+func decodeMap[K, V any](dk func(xdr.ScVal) (K, error), dv func(xdr.ScVal) (V, error)) func(xdr.ScVal) ([]MapEntry[K, V], error) {
+	return func(v xdr.ScVal) ([]MapEntry[K, V], error) {
+		entries, err := mapEntries(v)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]MapEntry[K, V], len(entries))
+		for i, e := range entries {
+			if out[i].Key, err = dk(e.Key); err != nil {
+				return nil, fmt.Errorf("key %d: %w", i, err)
+			}
+			if out[i].Value, err = dv(e.Val); err != nil {
+				return nil, fmt.Errorf("value %d: %w", i, err)
+			}
+		}
+		return out, nil
+	}
+}
+
+func encodeTuple(vs []xdr.ScVal) (xdr.ScVal, error) { return newVec(vs), nil }
+
+func decodeTuple(v xdr.ScVal) ([]xdr.ScVal, error) { return vecItems(v) }
+
+func decodeTupleN(v xdr.ScVal, n int) ([]xdr.ScVal, error) {
+	items, err := vecItems(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) != n {
+		return nil, fmt.Errorf("expected %d values, got %d", n, len(items))
+	}
+	return items, nil
+}
+
+// encodeFields builds the map of a struct; names are in ascending order.
+func encodeFields(names []string, vals []xdr.ScVal) xdr.ScVal {
+	entries := make([]xdr.ScMapEntry, len(names))
+	for i, name := range names {
+		key, _ := encodeSymbol(name)
+		entries[i] = xdr.ScMapEntry{Key: key, Val: vals[i]}
+	}
+	return newMap(entries)
+}
+
+// decodeFields returns the field values of a struct in the order of names.
+func decodeFields(v xdr.ScVal, names []string) ([]xdr.ScVal, error) {
+	entries, err := mapEntries(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) != len(names) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(names), len(entries))
+	}
+	vals := make([]xdr.ScVal, len(names))
+	for i, name := range names {
+		key, err := decodeSymbol(entries[i].Key)
+		if err != nil {
+			return nil, err
+		}
+		if key != name {
+			return nil, fmt.Errorf("expected field %q, got %q", name, key)
+		}
+		vals[i] = entries[i].Val
+	}
+	return vals, nil
+}
+
+// encodeCase builds a union value: the case name followed by its values.
+func encodeCase(name string, vals ...xdr.ScVal) xdr.ScVal {
+	sym, _ := encodeSymbol(name)
+	return newVec(append([]xdr.ScVal{sym}, vals...))
+}
+
+func decodeCase(v xdr.ScVal) (string, []xdr.ScVal, error) {
+	items, err := vecItems(v)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(items) == 0 {
+		return "", nil, fmt.Errorf("empty union value")
+	}
+	name, err := decodeSymbol(items[0])
+	return name, items[1:], err
+}
+`
@@ -0,0 +1,156 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package bindings
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/wasm"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files")
+
+const specDir = "../../examples/bindings/specs"
+
+// loadSpecWasm builds a WASM module whose contractspecv0 section holds the
+// entries of a .spec file: one base64 ScSpecEntry per line, # for comments.
+func loadSpecWasm(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var spec []byte
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		spec = append(spec, raw...)
+	}
+
+	mod := &wasm.Module{Customs: []wasm.Custom{{Name: "contractspecv0", Data: spec}}}
+	bin, err := mod.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bin
+}
+
+func TestGenerateGolden(t *testing.T) {
+	specs, err := filepath.Glob(filepath.Join(specDir, "*.spec"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) == 0 {
+		t.Fatalf("no specs in %s", specDir)
+	}
+
+	for _, specPath := range specs {
+		name := strings.TrimSuffix(filepath.Base(specPath), ".spec")
+		wasmBytes := loadSpecWasm(t, specPath)
+
+		for _, lang := range Languages {
+			t.Run(name+"/"+string(lang), func(t *testing.T) {
+				gen := NewGenerator(GeneratorConfig{
+					WasmBytes:   wasmBytes,
+					PackageName: name,
+					ContractID:  "CDLZFC3SYJYDZT7K67VZ75HPJVIEUVNIXF47ZG2FB2RMQQVU2HHGCYSC",
+					Network:     "testnet",
+					Language:    lang,
+				})
+				files, err := gen.Generate()
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+
+				dir := filepath.Join("testdata", "golden", name, string(lang))
+				for _, f := range files {
+					golden := filepath.Join(dir, filepath.FromSlash(f.Path)+".golden")
+					if *update {
+						if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+							t.Fatal(err)
+						}
+						if err := os.WriteFile(golden, []byte(f.Content), 0o644); err != nil {
+							t.Fatal(err)
+						}
+						continue
+					}
+					want, err := os.ReadFile(golden)
+					if err != nil {
+						t.Fatalf("%v (run go test -update to create it)", err)
+					}
+					if string(want) != f.Content {
+						t.Errorf("%s differs from %s; run go test -update and review the diff", f.Path, golden)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestGeneratedGoBuilds compiles the generated Go bindings, which must build
+// on their own with only the Stellar SDK as a dependency.
+func TestGeneratedGoBuilds(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not in PATH")
+	}
+	specs, err := filepath.Glob(filepath.Join(specDir, "*.spec"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, specPath := range specs {
+		name := strings.TrimSuffix(filepath.Base(specPath), ".spec")
+		t.Run(name, func(t *testing.T) {
+			files, err := NewGenerator(GeneratorConfig{
+				WasmBytes:   loadSpecWasm(t, specPath),
+				PackageName: name,
+				ContractID:  "CDLZFC3SYJYDZT7K67VZ75HPJVIEUVNIXF47ZG2FB2RMQQVU2HHGCYSC",
+				Language:    LanguageGo,
+			}).Generate()
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			// Inside the module so the SDK resolves from go.mod; testdata
+			// keeps it out of ./... patterns.
+			dir, err := os.MkdirTemp("testdata", "build-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = os.RemoveAll(dir) })
+			for _, f := range files {
+				if err := os.WriteFile(filepath.Join(dir, f.Path), []byte(f.Content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cmd := exec.Command(goBin, "vet", "./"+filepath.ToSlash(dir))
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("generated bindings do not build: %v\n%s", err, out)
+			}
+			for _, f := range files {
+				if strings.Contains(f.Content, "github.com/dotandev/hintents/") {
+					t.Errorf("%s imports a package internal to erst", f.Path)
+				}
+			}
+		})
+	}
+}
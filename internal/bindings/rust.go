@@ -0,0 +1,873 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package bindings
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+const rustHeader = "// Code generated by erst generate-bindings. DO NOT EDIT.\n\n"
+
+// rustKeywords are escaped as raw identifiers.
+var rustKeywords = map[string]bool{
+	"as": true, "async": true, "await": true, "break": true, "const": true,
+	"continue": true, "dyn": true, "else": true, "enum": true, "extern": true,
+	"false": true, "fn": true, "for": true, "if": true, "impl": true, "in": true,
+	"let": true, "loop": true, "match": true, "mod": true, "move": true, "mut": true,
+	"pub": true, "ref": true, "return": true, "static": true, "struct": true,
+	"trait": true, "true": true, "type": true, "unsafe": true, "use": true,
+	"where": true, "while": true,
+}
+
+// rustClientMethods are Client methods that contract functions must not
+// shadow.
+var rustClientMethods = map[string]bool{"new": true, "build": true, "envelope_xdr": true}
+
+// generateRust generates a Rust crate: UDT types implementing ToScVal and
+// FromScVal, and a client that builds invocation envelopes.
+func (g *Generator) generateRust() []GeneratedFile {
+	return []GeneratedFile{
+		{Path: "Cargo.toml", Content: g.generateCargoToml()},
+		{Path: "src/lib.rs", Content: g.generateRustLib()},
+		{Path: "src/types.rs", Content: g.generateRustTypes()},
+		{Path: "src/client.rs", Content: g.generateRustClient()},
+		{Path: "src/scval.rs", Content: rustHeader + rustRuntime},
+	}
+}
+
+func (g *Generator) generateCargoToml() string {
+	return fmt.Sprintf(`[package]
+name = "%s"
+version = "0.1.0"
+edition = "2021"
+description = "Rust bindings for a Soroban smart contract, generated by erst generate-bindings"
+license = "Apache-2.0"
+
+[dependencies]
+stellar-xdr = { version = "22", default-features = false, features = ["curr", "std", "base64"] }
+`, rustCrateName(g.config.PackageName))
+}
+
+func (g *Generator) generateRustLib() string {
+	var b strings.Builder
+	b.WriteString(rustHeader)
+	fmt.Fprintf(&b, "//! Rust bindings for the %s Soroban contract.\n\n", g.config.PackageName)
+	b.WriteString("#![allow(clippy::all, unused_imports)]\n\n")
+	b.WriteString("pub mod client;\npub mod scval;\npub mod types;\n\n")
+	b.WriteString("pub use client::*;\npub use scval::*;\npub use types::*;\n")
+	return b.String()
+}
+
+func (g *Generator) generateRustTypes() string {
+	var b strings.Builder
+	b.WriteString(rustHeader)
+	b.WriteString("use crate::scval::*;\n\n")
+
+	for _, s := range g.spec.Structs {
+		g.generateRustStruct(&b, s)
+	}
+	for _, e := range g.spec.Enums {
+		cases := make([]enumCase, len(e.Cases))
+		for i, c := range e.Cases {
+			cases[i] = enumCase{c.Name, uint32(c.Value), c.Doc}
+		}
+		generateRustEnum(&b, string(e.Name), e.Doc, cases, false)
+	}
+	for _, e := range g.spec.ErrorEnums {
+		cases := make([]enumCase, len(e.Cases))
+		for i, c := range e.Cases {
+			cases[i] = enumCase{c.Name, uint32(c.Value), c.Doc}
+		}
+		generateRustEnum(&b, string(e.Name), e.Doc, cases, true)
+	}
+	for _, u := range g.spec.Unions {
+		g.generateRustUnion(&b, u)
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func (g *Generator) generateRustStruct(b *strings.Builder, s xdr.ScSpecUdtStructV0) {
+	name := string(s.Name)
+	fields := sortedFields(s)
+
+	writeRustDoc(b, "", s.Doc)
+	b.WriteString("#[derive(Clone, Debug, PartialEq)]\n")
+
+	if isTupleStruct(s) {
+		types := make([]string, len(fields))
+		vals := make([]string, len(fields))
+		reads := make([]string, len(fields))
+		for i, f := range fields {
+			types[i] = "pub " + g.rustType(f.Type)
+			vals[i] = fmt.Sprintf("self.%d.to_sc_val()?", i)
+			reads[i] = fmt.Sprintf("FromScVal::from_sc_val(&items[%d])?", i)
+		}
+		fmt.Fprintf(b, "pub struct %s(%s);\n\n", name, strings.Join(types, ", "))
+		fmt.Fprintf(b, "impl ToScVal for %s {\n    fn to_sc_val(&self) -> Result<ScVal> {\n", name)
+		fmt.Fprintf(b, "        vec_val(vec![%s])\n    }\n}\n\n", strings.Join(vals, ", "))
+		fmt.Fprintf(b, "impl FromScVal for %s {\n    fn from_sc_val(v: &ScVal) -> Result<Self> {\n", name)
+		fmt.Fprintf(b, "        let items = vec_items(v, Some(%d))?;\n", len(fields))
+		fmt.Fprintf(b, "        Ok(Self(%s))\n    }\n}\n\n", strings.Join(reads, ", "))
+		return
+	}
+
+	fmt.Fprintf(b, "pub struct %s {\n", name)
+	for _, f := range fields {
+		writeRustDoc(b, "    ", f.Doc)
+		fmt.Fprintf(b, "    pub %s: %s,\n", rustIdent(f.Name), g.rustType(f.Type))
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(b, "impl ToScVal for %s {\n    fn to_sc_val(&self) -> Result<ScVal> {\n        struct_val(vec![\n", name)
+	for _, f := range fields {
+		fmt.Fprintf(b, "            (%q, self.%s.to_sc_val()?),\n", f.Name, rustIdent(f.Name))
+	}
+	b.WriteString("        ])\n    }\n}\n\n")
+
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = fmt.Sprintf("%q", f.Name)
+	}
+	fmt.Fprintf(b, "impl FromScVal for %s {\n    fn from_sc_val(v: &ScVal) -> Result<Self> {\n", name)
+	fmt.Fprintf(b, "        let fields = struct_fields(v, &[%s])?;\n        Ok(Self {\n", strings.Join(names, ", "))
+	for i, f := range fields {
+		fmt.Fprintf(b, "            %s: FromScVal::from_sc_val(&fields[%d])?,\n", rustIdent(f.Name), i)
+	}
+	b.WriteString("        })\n    }\n}\n\n")
+}
+
+func generateRustEnum(b *strings.Builder, name, doc string, cases []enumCase, isError bool) {
+	writeRustDoc(b, "", doc)
+	b.WriteString("#[derive(Clone, Copy, Debug, PartialEq, Eq)]\n#[repr(u32)]\n")
+	fmt.Fprintf(b, "pub enum %s {\n", name)
+	for _, c := range cases {
+		writeRustDoc(b, "    ", c.Doc)
+		fmt.Fprintf(b, "    %s = %d,\n", c.Name, c.Value)
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(b, "impl %s {\n", name)
+	b.WriteString("    /// Returns the case with the given value, if any.\n")
+	b.WriteString("    pub fn from_code(code: u32) -> Option<Self> {\n        match code {\n")
+	for _, c := range cases {
+		fmt.Fprintf(b, "            %d => Some(Self::%s),\n", c.Value, c.Name)
+	}
+	b.WriteString("            _ => None,\n        }\n    }\n}\n\n")
+
+	fmt.Fprintf(b, "impl ToScVal for %s {\n    fn to_sc_val(&self) -> Result<ScVal> {\n", name)
+	if isError {
+		b.WriteString("        Ok(ScVal::Error(ScError::Contract(*self as u32)))\n")
+	} else {
+		b.WriteString("        (*self as u32).to_sc_val()\n")
+	}
+	b.WriteString("    }\n}\n\n")
+
+	read := "u32::from_sc_val(v)?"
+	if isError {
+		read = "contract_error(v)?"
+	}
+	fmt.Fprintf(b, "impl FromScVal for %s {\n    fn from_sc_val(v: &ScVal) -> Result<Self> {\n", name)
+	fmt.Fprintf(b, "        let code = %s;\n", read)
+	fmt.Fprintf(b, "        Self::from_code(code).ok_or_else(|| Error::Decode(format!(\"unknown %s case {code}\")))\n    }\n}\n\n", name)
+
+	if isError {
+		fmt.Fprintf(b, "impl std::fmt::Display for %s {\n", name)
+		b.WriteString("    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {\n")
+		b.WriteString("        write!(f, \"contract error: {:?}\", self)\n    }\n}\n\n")
+		fmt.Fprintf(b, "impl std::error::Error for %s {}\n\n", name)
+	}
+}
+
+func (g *Generator) generateRustUnion(b *strings.Builder, u xdr.ScSpecUdtUnionV0) {
+	name := string(u.Name)
+
+	writeRustDoc(b, "", u.Doc)
+	b.WriteString("#[derive(Clone, Debug, PartialEq)]\n")
+	fmt.Fprintf(b, "pub enum %s {\n", name)
+	for _, c := range u.Cases {
+		if c.Kind != xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0 {
+			fmt.Fprintf(b, "    %s,\n", c.VoidCase.Name)
+			continue
+		}
+		types := make([]string, len(c.TupleCase.Type))
+		for i, t := range c.TupleCase.Type {
+			types[i] = g.rustType(t)
+		}
+		fmt.Fprintf(b, "    %s(%s),\n", c.TupleCase.Name, strings.Join(types, ", "))
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(b, "impl ToScVal for %s {\n    fn to_sc_val(&self) -> Result<ScVal> {\n        match self {\n", name)
+	for _, c := range u.Cases {
+		caseName := unionCaseName(c)
+		if c.Kind != xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0 {
+			fmt.Fprintf(b, "            Self::%s => case_val(%q, vec![]),\n", caseName, caseName)
+			continue
+		}
+		binds := make([]string, len(c.TupleCase.Type))
+		vals := make([]string, len(c.TupleCase.Type))
+		for i := range c.TupleCase.Type {
+			binds[i] = fmt.Sprintf("v%d", i)
+			vals[i] = fmt.Sprintf("v%d.to_sc_val()?", i)
+		}
+		fmt.Fprintf(b, "            Self::%s(%s) => case_val(%q, vec![%s]),\n",
+			caseName, strings.Join(binds, ", "), caseName, strings.Join(vals, ", "))
+	}
+	b.WriteString("        }\n    }\n}\n\n")
+
+	fmt.Fprintf(b, "impl FromScVal for %s {\n    fn from_sc_val(v: &ScVal) -> Result<Self> {\n", name)
+	b.WriteString("        let (case, values) = case_items(v)?;\n        match (case.as_str(), values.len()) {\n")
+	for _, c := range u.Cases {
+		caseName := unionCaseName(c)
+		if c.Kind != xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0 {
+			fmt.Fprintf(b, "            (%q, 0) => Ok(Self::%s),\n", caseName, caseName)
+			continue
+		}
+		reads := make([]string, len(c.TupleCase.Type))
+		for i := range c.TupleCase.Type {
+			reads[i] = fmt.Sprintf("FromScVal::from_sc_val(&values[%d])?", i)
+		}
+		fmt.Fprintf(b, "            (%q, %d) => Ok(Self::%s(%s)),\n", caseName, len(reads), caseName, strings.Join(reads, ", "))
+	}
+	fmt.Fprintf(b, "            _ => Err(Error::Decode(format!(\"unknown %s case {case} with {} values\", values.len()))),\n", name)
+	b.WriteString("        }\n    }\n}\n\n")
+}
+
+func (g *Generator) generateRustClient() string {
+	var b strings.Builder
+	b.WriteString(rustHeader)
+	b.WriteString(rustClientRuntime)
+
+	for _, fn := range g.spec.Functions {
+		method := rustMethod(string(fn.Name))
+		params := []string{"&self", "opts: &InvokeOptions"}
+		args := make([]string, len(fn.Inputs))
+		for i, in := range fn.Inputs {
+			p := rustIdent(in.Name)
+			params = append(params, fmt.Sprintf("%s: %s", p, g.rustType(in.Type)))
+			args[i] = p + ".to_sc_val()?"
+		}
+
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "    /// Builds an invocation of `%s`.\n", fn.Name)
+		if fn.Doc != "" {
+			b.WriteString("    ///\n")
+			writeRustDoc(&b, "    ", fn.Doc)
+		}
+		fmt.Fprintf(&b, "    pub fn %s(%s) -> Result<xdr::TransactionEnvelope> {\n", method, strings.Join(params, ", "))
+		fmt.Fprintf(&b, "        self.build(%q, vec![%s], opts)\n    }\n", fn.Name, strings.Join(args, ", "))
+
+		if out := g.rustResult(fn); out != "" {
+			fmt.Fprintf(&b, "\n    /// Decodes the return value of `%s`.\n", fn.Name)
+			fmt.Fprintf(&b, "    pub fn parse_%s(ret: &ScVal) -> Result<%s> {\n", strings.TrimPrefix(method, "r#"), out)
+			b.WriteString("        FromScVal::from_sc_val(ret)\n    }\n")
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// rustResult returns the Rust type of fn's return value with Result
+// unwrapped, or "" when it returns nothing.
+func (g *Generator) rustResult(fn xdr.ScSpecFunctionV0) string {
+	if len(fn.Outputs) == 0 {
+		return ""
+	}
+	td := fn.Outputs[0]
+	if td.Type == xdr.ScSpecTypeScSpecTypeResult && td.Result != nil {
+		td = td.Result.OkType
+	}
+	if td.Type == xdr.ScSpecTypeScSpecTypeVoid {
+		return ""
+	}
+	return g.rustType(td)
+}
+
+// rustType maps a spec type to its Rust representation.
+func (g *Generator) rustType(td xdr.ScSpecTypeDef) string {
+	switch td.Type {
+	case xdr.ScSpecTypeScSpecTypeBool:
+		return "bool"
+	case xdr.ScSpecTypeScSpecTypeVoid:
+		return "()"
+	case xdr.ScSpecTypeScSpecTypeError:
+		return "ScError"
+	case xdr.ScSpecTypeScSpecTypeU32:
+		return "u32"
+	case xdr.ScSpecTypeScSpecTypeI32:
+		return "i32"
+	case xdr.ScSpecTypeScSpecTypeU64:
+		return "u64"
+	case xdr.ScSpecTypeScSpecTypeI64:
+		return "i64"
+	case xdr.ScSpecTypeScSpecTypeTimepoint:
+		return "Timepoint"
+	case xdr.ScSpecTypeScSpecTypeDuration:
+		return "Duration"
+	case xdr.ScSpecTypeScSpecTypeU128:
+		return "u128"
+	case xdr.ScSpecTypeScSpecTypeI128:
+		return "i128"
+	case xdr.ScSpecTypeScSpecTypeU256:
+		return "UInt256Parts"
+	case xdr.ScSpecTypeScSpecTypeI256:
+		return "Int256Parts"
+	case xdr.ScSpecTypeScSpecTypeBytes:
+		return "Bytes"
+	case xdr.ScSpecTypeScSpecTypeBytesN:
+		if td.BytesN != nil {
+			return fmt.Sprintf("[u8; %d]", td.BytesN.N)
+		}
+		return "Bytes"
+	case xdr.ScSpecTypeScSpecTypeString:
+		return "String"
+	case xdr.ScSpecTypeScSpecTypeSymbol:
+		return "Symbol"
+	case xdr.ScSpecTypeScSpecTypeAddress, xdr.ScSpecTypeScSpecTypeMuxedAddress:
+		return "ScAddress"
+	case xdr.ScSpecTypeScSpecTypeOption:
+		if td.Option != nil {
+			return fmt.Sprintf("Option<%s>", g.rustType(td.Option.ValueType))
+		}
+	case xdr.ScSpecTypeScSpecTypeResult:
+		if td.Result != nil {
+			return g.rustType(td.Result.OkType)
+		}
+	case xdr.ScSpecTypeScSpecTypeVec:
+		if td.Vec != nil {
+			return fmt.Sprintf("Vec<%s>", g.rustType(td.Vec.ElementType))
+		}
+	case xdr.ScSpecTypeScSpecTypeMap:
+		if td.Map != nil {
+			return fmt.Sprintf("Map<%s, %s>", g.rustType(td.Map.KeyType), g.rustType(td.Map.ValueType))
+		}
+	case xdr.ScSpecTypeScSpecTypeTuple:
+		// ToScVal and FromScVal are implemented for tuples of up to six.
+		if td.Tuple != nil && len(td.Tuple.ValueTypes) > 0 && len(td.Tuple.ValueTypes) <= 6 {
+			types := make([]string, len(td.Tuple.ValueTypes))
+			for i, t := range td.Tuple.ValueTypes {
+				types[i] = g.rustType(t)
+			}
+			return "(" + strings.Join(types, ", ") + ",)"
+		}
+	case xdr.ScSpecTypeScSpecTypeUdt:
+		if td.Udt != nil {
+			return td.Udt.Name
+		}
+	}
+	return "ScVal"
+}
+
+func writeRustDoc(b *strings.Builder, indent, doc string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(doc), "\n") {
+		fmt.Fprintf(b, "%s/// %s\n", indent, strings.TrimSpace(line))
+	}
+}
+
+// rustIdent escapes Rust keywords in a spec identifier.
+func rustIdent(name string) string {
+	if rustKeywords[name] {
+		return "r#" + name
+	}
+	return name
+}
+
+// rustMethod names the Client method for a contract function.
+func rustMethod(name string) string {
+	if rustClientMethods[name] {
+		return name + "_fn"
+	}
+	return rustIdent(name)
+}
+
+// rustCrateName derives a Cargo package name from the bindings package name.
+func rustCrateName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	crate := strings.Trim(b.String(), "-")
+	if crate == "" {
+		crate = "contract"
+	}
+	return crate
+}
+
+// rustClientRuntime is the fixed part of client.rs; the generated methods
+// are appended inside the impl block.
+const rustClientRuntime = `use crate::scval::*;
+use crate::types::*;
+use stellar_xdr::curr::{self as xdr, Limits, WriteXdr};
+
+/// Describes the transaction wrapping an invocation.
+#[derive(Clone, Debug)]
+pub struct InvokeOptions {
+    /// Ed25519 public key of the source account.
+    pub source: [u8; 32],
+    /// Sequence number of the transaction.
+    pub sequence: i64,
+    /// Inclusion fee in stroops.
+    pub fee: u32,
+}
+
+/// Builds invocations of the contract. Submit the envelopes, or replay them
+/// locally with ` + "`erst simulate`" + `.
+#[derive(Clone, Debug)]
+pub struct Client {
+    pub contract: ScAddress,
+}
+
+impl Client {
+    pub fn new(contract: ScAddress) -> Self {
+        Self { contract }
+    }
+
+    /// Returns the base64 XDR of an envelope.
+    pub fn envelope_xdr(env: &xdr::TransactionEnvelope) -> Result<String> {
+        Ok(env.to_xdr_base64(Limits::none())?)
+    }
+
+    fn build(&self, function: &str, args: Vec<ScVal>, opts: &InvokeOptions) -> Result<xdr::TransactionEnvelope> {
+        let op = xdr::Operation {
+            source_account: None,
+            body: xdr::OperationBody::InvokeHostFunction(xdr::InvokeHostFunctionOp {
+                host_function: xdr::HostFunction::InvokeContract(xdr::InvokeContractArgs {
+                    contract_address: self.contract.clone(),
+                    function_name: xdr::ScSymbol(function.try_into()?),
+                    args: args.try_into()?,
+                }),
+                auth: xdr::VecM::default(),
+            }),
+        };
+        let tx = xdr::Transaction {
+            source_account: xdr::MuxedAccount::Ed25519(xdr::Uint256(opts.source)),
+            fee: opts.fee,
+            seq_num: xdr::SequenceNumber(opts.sequence),
+            cond: xdr::Preconditions::None,
+            memo: xdr::Memo::None,
+            operations: vec![op].try_into()?,
+            ext: xdr::TransactionExt::V0,
+        };
+        Ok(xdr::TransactionEnvelope::Tx(xdr::TransactionV1Envelope {
+            tx,
+            signatures: xdr::VecM::default(),
+        }))
+    }
+`
+
+// rustRuntime is scval.rs, the ScVal conversions shared by the generated
+// types and client.
+const rustRuntime = `use stellar_xdr::curr as xdr;
+
+pub use stellar_xdr::curr::{Int256Parts, ScAddress, ScError, ScVal, UInt256Parts};
+
+/// Errors converting between Rust values and ScVal.
+#[derive(Debug)]
+pub enum Error {
+    Xdr(xdr::Error),
+    Decode(String),
+}
+
+impl std::fmt::Display for Error {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self {
+            Error::Xdr(e) => write!(f, "xdr: {e}"),
+            Error::Decode(msg) => f.write_str(msg),
+        }
+    }
+}
+
+impl std::error::Error for Error {}
+
+impl From<xdr::Error> for Error {
+    fn from(e: xdr::Error) -> Self {
+        Error::Xdr(e)
+    }
+}
+
+pub type Result<T> = std::result::Result<T, Error>;
+
+/// Converts a value to the ScVal the contract expects.
+pub trait ToScVal {
+    fn to_sc_val(&self) -> Result<ScVal>;
+}
+
+/// Converts an ScVal returned by the contract to a value.
+pub trait FromScVal: Sized {
+    fn from_sc_val(v: &ScVal) -> Result<Self>;
+}
+
+/// Variable-length bytes.
+#[derive(Clone, Debug, Default, PartialEq, Eq)]
+pub struct Bytes(pub Vec<u8>);
+
+/// A symbol (up to 32 characters of [a-zA-Z0-9_]).
+#[derive(Clone, Debug, Default, PartialEq, Eq)]
+pub struct Symbol(pub String);
+
+/// A contract map. Entries must be in ascending key order; the host rejects
+/// unsorted maps.
+#[derive(Clone, Debug, Default, PartialEq)]
+pub struct Map<K, V>(pub Vec<(K, V)>);
+
+/// Seconds since the Unix epoch.
+#[derive(Clone, Copy, Debug, Default, PartialEq, Eq)]
+pub struct Timepoint(pub u64);
+
+/// A duration in seconds.
+#[derive(Clone, Copy, Debug, Default, PartialEq, Eq)]
+pub struct Duration(pub u64);
+
+fn type_error(want: &str, v: &ScVal) -> Error {
+    Error::Decode(format!("expected {want}, got {}", v.name()))
+}
+
+#[doc(hidden)]
+pub fn vec_val(items: Vec<ScVal>) -> Result<ScVal> {
+    Ok(ScVal::Vec(Some(xdr::ScVec(items.try_into()?))))
+}
+
+#[doc(hidden)]
+pub fn vec_items(v: &ScVal, len: Option<usize>) -> Result<Vec<ScVal>> {
+    match v {
+        ScVal::Vec(Some(items)) => {
+            let items = items.0.to_vec();
+            match len {
+                Some(n) if items.len() != n => Err(Error::Decode(format!("expected {n} values, got {}", items.len()))),
+                _ => Ok(items),
+            }
+        }
+        _ => Err(type_error("vec", v)),
+    }
+}
+
+#[doc(hidden)]
+pub fn symbol_val(name: &str) -> Result<ScVal> {
+    Ok(ScVal::Symbol(xdr::ScSymbol(name.try_into()?)))
+}
+
+/// Builds the map of a struct; fields are in ascending name order.
+#[doc(hidden)]
+pub fn struct_val(fields: Vec<(&str, ScVal)>) -> Result<ScVal> {
+    let entries = fields
+        .into_iter()
+        .map(|(key, val)| Ok(xdr::ScMapEntry { key: symbol_val(key)?, val }))
+        .collect::<Result<Vec<_>>>()?;
+    Ok(ScVal::Map(Some(xdr::ScMap(entries.try_into()?))))
+}
+
+/// Returns the field values of a struct in the order of names.
+#[doc(hidden)]
+pub fn struct_fields(v: &ScVal, names: &[&str]) -> Result<Vec<ScVal>> {
+    let ScVal::Map(Some(map)) = v else {
+        return Err(type_error("map", v));
+    };
+    if map.0.len() != names.len() {
+        return Err(Error::Decode(format!("expected {} fields, got {}", names.len(), map.0.len())));
+    }
+    map.0
+        .iter()
+        .zip(names)
+        .map(|(entry, name)| {
+            let key = Symbol::from_sc_val(&entry.key)?;
+            if key.0 != *name {
+                return Err(Error::Decode(format!("expected field {name}, got {}", key.0)));
+            }
+            Ok(entry.val.clone())
+        })
+        .collect()
+}
+
+/// Builds a union value: the case name followed by its values.
+#[doc(hidden)]
+pub fn case_val(name: &str, values: Vec<ScVal>) -> Result<ScVal> {
+    let mut items = vec![symbol_val(name)?];
+    items.extend(values);
+    vec_val(items)
+}
+
+#[doc(hidden)]
+pub fn case_items(v: &ScVal) -> Result<(String, Vec<ScVal>)> {
+    let items = vec_items(v, None)?;
+    let first = items.first().ok_or_else(|| Error::Decode("empty union value".into()))?;
+    Ok((Symbol::from_sc_val(first)?.0, items[1..].to_vec()))
+}
+
+#[doc(hidden)]
+pub fn contract_error(v: &ScVal) -> Result<u32> {
+    match v {
+        ScVal::Error(ScError::Contract(code)) => Ok(*code),
+        _ => Err(type_error("contract error", v)),
+    }
+}
+
+macro_rules! impl_scalar {
+    ($t:ty, $variant:ident, $want:expr) => {
+        impl ToScVal for $t {
+            fn to_sc_val(&self) -> Result<ScVal> {
+                Ok(ScVal::$variant(self.clone()))
+            }
+        }
+
+        impl FromScVal for $t {
+            fn from_sc_val(v: &ScVal) -> Result<Self> {
+                match v {
+                    ScVal::$variant(x) => Ok(x.clone()),
+                    _ => Err(type_error($want, v)),
+                }
+            }
+        }
+    };
+}
+
+impl_scalar!(bool, Bool, "bool");
+impl_scalar!(u32, U32, "u32");
+impl_scalar!(i32, I32, "i32");
+impl_scalar!(u64, U64, "u64");
+impl_scalar!(i64, I64, "i64");
+impl_scalar!(UInt256Parts, U256, "u256");
+impl_scalar!(Int256Parts, I256, "i256");
+impl_scalar!(ScError, Error, "error");
+impl_scalar!(ScAddress, Address, "address");
+
+impl ToScVal for ScVal {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        Ok(self.clone())
+    }
+}
+
+impl FromScVal for ScVal {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        Ok(v.clone())
+    }
+}
+
+impl ToScVal for () {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        Ok(ScVal::Void)
+    }
+}
+
+impl FromScVal for () {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        match v {
+            ScVal::Void => Ok(()),
+            _ => Err(type_error("void", v)),
+        }
+    }
+}
+
+impl ToScVal for u128 {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        Ok(ScVal::U128(xdr::UInt128Parts { hi: (*self >> 64) as u64, lo: *self as u64 }))
+    }
+}
+
+impl FromScVal for u128 {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        match v {
+            ScVal::U128(p) => Ok(((p.hi as u128) << 64) | p.lo as u128),
+            _ => Err(type_error("u128", v)),
+        }
+    }
+}
+
+impl ToScVal for i128 {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        Ok(ScVal::I128(xdr::Int128Parts { hi: (*self >> 64) as i64, lo: *self as u64 }))
+    }
+}
+
+impl FromScVal for i128 {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        match v {
+            ScVal::I128(p) => Ok(((p.hi as i128) << 64) | p.lo as i128),
+            _ => Err(type_error("i128", v)),
+        }
+    }
+}
+
+impl ToScVal for Timepoint {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        Ok(ScVal::Timepoint(xdr::TimePoint(self.0)))
+    }
+}
+
+impl FromScVal for Timepoint {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        match v {
+            ScVal::Timepoint(t) => Ok(Timepoint(t.0)),
+            _ => Err(type_error("timepoint", v)),
+        }
+    }
+}
+
+impl ToScVal for Duration {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        Ok(ScVal::Duration(xdr::Duration(self.0)))
+    }
+}
+
+impl FromScVal for Duration {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        match v {
+            ScVal::Duration(d) => Ok(Duration(d.0)),
+            _ => Err(type_error("duration", v)),
+        }
+    }
+}
+
+impl ToScVal for Bytes {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        Ok(ScVal::Bytes(xdr::ScBytes(self.0.clone().try_into()?)))
+    }
+}
+
+impl FromScVal for Bytes {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        match v {
+            ScVal::Bytes(b) => Ok(Bytes(b.0.to_vec())),
+            _ => Err(type_error("bytes", v)),
+        }
+    }
+}
+
+impl<const N: usize> ToScVal for [u8; N] {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        Bytes(self.to_vec()).to_sc_val()
+    }
+}
+
+impl<const N: usize> FromScVal for [u8; N] {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        let bytes = Bytes::from_sc_val(v)?.0;
+        let len = bytes.len();
+        bytes
+            .try_into()
+            .map_err(|_| Error::Decode(format!("expected {N} bytes, got {len}")))
+    }
+}
+
+impl ToScVal for String {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        Ok(ScVal::String(xdr::ScString(self.as_str().try_into()?)))
+    }
+}
+
+impl FromScVal for String {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        match v {
+            ScVal::String(s) => String::from_utf8(s.0.to_vec()).map_err(|e| Error::Decode(e.to_string())),
+            _ => Err(type_error("string", v)),
+        }
+    }
+}
+
+impl ToScVal for Symbol {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        symbol_val(&self.0)
+    }
+}
+
+impl FromScVal for Symbol {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        match v {
+            ScVal::Symbol(s) => String::from_utf8(s.0.to_vec())
+                .map(Symbol)
+                .map_err(|e| Error::Decode(e.to_string())),
+            _ => Err(type_error("symbol", v)),
+        }
+    }
+}
+
+impl<T: ToScVal> ToScVal for Option<T> {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        match self {
+            Some(v) => v.to_sc_val(),
+            None => Ok(ScVal::Void),
+        }
+    }
+}
+
+impl<T: FromScVal> FromScVal for Option<T> {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        match v {
+            ScVal::Void => Ok(None),
+            _ => T::from_sc_val(v).map(Some),
+        }
+    }
+}
+
+impl<T: ToScVal> ToScVal for Vec<T> {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        vec_val(self.iter().map(ToScVal::to_sc_val).collect::<Result<Vec<_>>>()?)
+    }
+}
+
+impl<T: FromScVal> FromScVal for Vec<T> {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        vec_items(v, None)?.iter().map(T::from_sc_val).collect()
+    }
+}
+
+impl<K: ToScVal, V: ToScVal> ToScVal for Map<K, V> {
+    fn to_sc_val(&self) -> Result<ScVal> {
+        let entries = self
+            .0
+            .iter()
+            .map(|(k, v)| Ok(xdr::ScMapEntry { key: k.to_sc_val()?, val: v.to_sc_val()? }))
+            .collect::<Result<Vec<_>>>()?;
+        Ok(ScVal::Map(Some(xdr::ScMap(entries.try_into()?))))
+    }
+}
+
+impl<K: FromScVal, V: FromScVal> FromScVal for Map<K, V> {
+    fn from_sc_val(v: &ScVal) -> Result<Self> {
+        match v {
+            ScVal::Map(Some(map)) => map
+                .0
+                .iter()
+                .map(|e| Ok((K::from_sc_val(&e.key)?, V::from_sc_val(&e.val)?)))
+                .collect::<Result<Vec<_>>>()
+                .map(Map),
+            _ => Err(type_error("map", v)),
+        }
+    }
+}
+
+macro_rules! impl_tuple {
+    ($n:expr; $($t:ident $i:tt),+) => {
+        impl<$($t: ToScVal),+> ToScVal for ($($t,)+) {
+            fn to_sc_val(&self) -> Result<ScVal> {
+                vec_val(vec![$(self.$i.to_sc_val()?),+])
+            }
+        }
+
+        impl<$($t: FromScVal),+> FromScVal for ($($t,)+) {
+            fn from_sc_val(v: &ScVal) -> Result<Self> {
+                let items = vec_items(v, Some($n))?;
+                Ok(($($t::from_sc_val(&items[$i])?,)+))
+            }
+        }
+    };
+}
+
+impl_tuple!(1; A 0);
+impl_tuple!(2; A 0, B 1);
+impl_tuple!(3; A 0, B 1, C 2);
+impl_tuple!(4; A 0, B 1, C 2, D 3);
+impl_tuple!(5; A 0, B 1, C 2, D 3, E 4);
+impl_tuple!(6; A 0, B 1, C 2, D 3, E 4, F 5);
+`
@@ -0,0 +1,132 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package callgraph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/decoder"
+)
+
+// DefaultMaxDepth is the call chain length above which chains are reported
+// as unusually deep.
+const DefaultMaxDepth = 4
+
+// Kind identifies what a Finding is about.
+type Kind string
+
+const (
+	// KindReentry is a call into a contract that is already on the call
+	// stack. The Soroban host rejects re-entry, so the call fails.
+	KindReentry Kind = "reentry"
+	// KindWriteAfterCall is a state write made after the contract called
+	// out to another contract, which breaks checks-effects-interactions.
+	KindWriteAfterCall Kind = "write-after-call"
+	// KindDeepChain is a call chain longer than Options.MaxDepth.
+	KindDeepChain Kind = "deep-call-chain"
+)
+
+// Finding is one issue in the call graph.
+type Finding struct {
+	Kind     Kind   `json:"kind"`
+	Contract string `json:"contract"`
+	Function string `json:"function"`
+	// Path is the call chain leading to the finding, as contract.function
+	// steps from the transaction down.
+	Path   []string `json:"path"`
+	Detail string   `json:"detail"`
+}
+
+// Options tunes Analyze.
+type Options struct {
+	// MaxDepth is the longest call chain considered normal; zero uses
+	// DefaultMaxDepth.
+	MaxDepth int
+}
+
+// Analyze walks the call tree in execution order and reports re-entry,
+// writes after external calls and deep call chains.
+func (g *Graph) Analyze(opts Options) []Finding {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultMaxDepth
+	}
+	findings := []Finding{}
+
+	var walk func(node *decoder.CallNode, stack []*decoder.CallNode)
+	walk = func(node *decoder.CallNode, stack []*decoder.CallNode) {
+		if len(stack) > 0 {
+			findings = append(findings, checkNode(node, stack)...)
+		}
+		if len(stack) == opts.MaxDepth+1 {
+			findings = append(findings, Finding{
+				Kind:     KindDeepChain,
+				Contract: node.ContractID,
+				Function: node.Function,
+				Path:     path(stack),
+				Detail:   fmt.Sprintf("call chain reaches depth %d (more than %d)", len(stack), opts.MaxDepth),
+			})
+		}
+		for _, call := range node.SubCalls {
+			walk(call, append(stack, call))
+		}
+	}
+	walk(g.Root, nil)
+	return findings
+}
+
+// checkNode looks for re-entry into node and for writes node makes after
+// its first external call. stack ends with node.
+func checkNode(node *decoder.CallNode, stack []*decoder.CallNode) []Finding {
+	var findings []Finding
+	for i, caller := range stack[:len(stack)-1] {
+		if caller.ContractID == node.ContractID {
+			findings = append(findings, Finding{
+				Kind:     KindReentry,
+				Contract: node.ContractID,
+				Function: node.Function,
+				Path:     path(stack[i:]),
+				Detail: fmt.Sprintf("%s is re-entered through %s while %s is still running",
+					Label(node.ContractID), node.Function, caller.Function),
+			})
+			break
+		}
+	}
+
+	if len(node.SubCalls) == 0 {
+		return findings
+	}
+	first := node.SubCalls[0]
+	if len(first.Events) == 0 {
+		return findings
+	}
+	callIndex := first.Events[0].Index
+	for _, e := range node.Events {
+		if e.Index > callIndex && isStateWrite(e) {
+			findings = append(findings, Finding{
+				Kind:     KindWriteAfterCall,
+				Contract: node.ContractID,
+				Function: node.Function,
+				Path:     path(stack),
+				Detail: fmt.Sprintf("%s.%s records %q after calling %s.%s; update state before external calls",
+					Label(node.ContractID), node.Function, e.Topics[0], Label(first.ContractID), first.Function),
+			})
+			break
+		}
+	}
+	return findings
+}
+
+func path(stack []*decoder.CallNode) []string {
+	out := make([]string, len(stack))
+	for i, n := range stack {
+		out[i] = Label(n.ContractID) + "." + n.Function
+	}
+	return out
+}
+
+// FormatPath joins a finding's path for display.
+func FormatPath(p []string) string {
+	return strings.Join(p, " -> ")
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+// Package callgraph models the cross-contract calls of a transaction from
+// its fn_call and fn_return diagnostic events, as decoded into a call tree
+// by decoder.DecodeEvents. It detects re-entry (A -> B -> A), state writes
+// after external calls (checks-effects-interactions violations) and
+// unusually deep call chains, and renders the graph as Mermaid or Graphviz.
+package callgraph
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/decoder"
+	"github.com/stellar/go-stellar-sdk/strkey"
+)
+
+// rootID is the contract ID decoder.DecodeEvents gives the transaction
+// itself.
+const rootID = "ROOT"
+
+// StateWriteTopics are the first event topics taken as a state write by the
+// contract that emits them: storage markers and the SEP-41 events that
+// follow a balance or allowance change.
+var StateWriteTopics = map[string]bool{
+	"storage_write":     true,
+	"put_contract_data": true,
+	"del_contract_data": true,
+	"transfer":          true,
+	"mint":              true,
+	"burn":              true,
+	"clawback":          true,
+	"approve":           true,
+	"set_admin":         true,
+	"set_authorized":    true,
+}
+
+// Edge is every call from one contract to a function of another.
+type Edge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Function string `json:"function"`
+	Count    int    `json:"count"`
+	// Reentrant marks calls into a contract that is already on the call
+	// stack.
+	Reentrant bool `json:"reentrant,omitempty"`
+}
+
+// Graph is the call graph of one transaction.
+type Graph struct {
+	// Root is the call tree the graph was built from.
+	Root *decoder.CallNode `json:"-"`
+	// Contracts are the contracts taking part, in order of first call.
+	Contracts []string `json:"contracts"`
+	Edges     []Edge   `json:"edges"`
+	// Depth is the length of the longest call chain.
+	Depth int `json:"depth"`
+}
+
+// FromEvents decodes base64 diagnostic events and builds their call graph.
+func FromEvents(eventsXdr []string) (*Graph, error) {
+	root, err := decoder.DecodeEvents(eventsXdr)
+	if err != nil {
+		return nil, err
+	}
+	return Build(root), nil
+}
+
+// Build aggregates a call tree into a graph of contracts.
+func Build(root *decoder.CallNode) *Graph {
+	g := &Graph{Root: root, Contracts: []string{}, Edges: []Edge{}}
+	seen := make(map[string]bool)
+	edges := make(map[Edge]int)
+
+	var walk func(node *decoder.CallNode, stack []string)
+	walk = func(node *decoder.CallNode, stack []string) {
+		if len(stack) > g.Depth {
+			g.Depth = len(stack)
+		}
+		for _, call := range node.SubCalls {
+			if !seen[call.ContractID] {
+				seen[call.ContractID] = true
+				g.Contracts = append(g.Contracts, call.ContractID)
+			}
+			key := Edge{From: node.ContractID, To: call.ContractID, Function: call.Function, Reentrant: onStack(stack, call.ContractID)}
+			if _, ok := edges[key]; !ok {
+				g.Edges = append(g.Edges, key)
+			}
+			edges[key]++
+			walk(call, append(stack, call.ContractID))
+		}
+	}
+	walk(root, nil)
+
+	for i := range g.Edges {
+		key := g.Edges[i]
+		g.Edges[i].Count = edges[key]
+	}
+	return g
+}
+
+func onStack(stack []string, contract string) bool {
+	for _, c := range stack {
+		if c == contract {
+			return true
+		}
+	}
+	return false
+}
+
+// Label shortens a contract ID for display: hex IDs are shown as their
+// C... strkey, and long IDs keep their first and last characters.
+func Label(contract string) string {
+	if contract == rootID {
+		return "transaction"
+	}
+	contract = FullID(contract)
+	if len(contract) > 12 {
+		return contract[:4] + "…" + contract[len(contract)-4:]
+	}
+	return contract
+}
+
+// FullID returns the C... strkey of a hex contract ID, or the ID unchanged.
+func FullID(contract string) string {
+	if raw, err := hex.DecodeString(contract); err == nil && len(raw) == 32 {
+		if id, err := strkey.Encode(strkey.VersionByteContract, raw); err == nil {
+			return id
+		}
+	}
+	return contract
+}
+
+// isStateWrite reports whether an event records a state write by the
+// contract that emitted it.
+func isStateWrite(e decoder.DecodedEvent) bool {
+	return len(e.Topics) > 0 && StateWriteTopics[strings.ToLower(e.Topics[0])]
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package callgraph
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	contractA = contractID(0xaa)
+	contractB = contractID(0xbb)
+	contractC = contractID(0xcc)
+)
+
+func contractID(b byte) xdr.ContractId {
+	var id xdr.ContractId
+	copy(id[:], bytes.Repeat([]byte{b}, 32))
+	return id
+}
+
+func sym(s string) xdr.ScVal {
+	v := xdr.ScSymbol(s)
+	return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &v}
+}
+
+func encode(t *testing.T, contract *xdr.ContractId, topics ...xdr.ScVal) string {
+	t.Helper()
+	diag := xdr.DiagnosticEvent{
+		InSuccessfulContractCall: true,
+		Event: xdr.ContractEvent{
+			ContractId: contract,
+			Type:       xdr.ContractEventTypeDiagnostic,
+			Body: xdr.ContractEventBody{V0: &xdr.ContractEventV0{
+				Topics: topics,
+				Data:   xdr.ScVal{Type: xdr.ScValTypeScvVoid},
+			}},
+		},
+	}
+	raw, err := diag.MarshalBinary()
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// call and ret encode fn_call and fn_return the way the host emits them.
+func call(t *testing.T, callee xdr.ContractId, fn string) string {
+	b := xdr.ScBytes(callee[:])
+	return encode(t, nil, sym("fn_call"), xdr.ScVal{Type: xdr.ScValTypeScvBytes, Bytes: &b}, sym(fn))
+}
+
+func ret(t *testing.T, callee xdr.ContractId, fn string) string {
+	return encode(t, &callee, sym("fn_return"), sym(fn))
+}
+
+func event(t *testing.T, contract xdr.ContractId, topic string) string {
+	return encode(t, &contract, sym(topic))
+}
+
+// swapEvents: the transaction calls A.swap, which calls B.transfer, writes
+// state afterwards, then calls C.notify, which re-enters A.
+func swapEvents(t *testing.T) []string {
+	return []string{
+		call(t, contractA, "swap"),
+		call(t, contractB, "transfer"),
+		event(t, contractB, "transfer"),
+		ret(t, contractB, "transfer"),
+		event(t, contractA, "storage_write"),
+		call(t, contractC, "notify"),
+		call(t, contractA, "on_notify"),
+		ret(t, contractA, "on_notify"),
+		ret(t, contractC, "notify"),
+		ret(t, contractA, "swap"),
+	}
+}
+
+func TestBuild(t *testing.T) {
+	g, err := FromEvents(swapEvents(t))
+	require.NoError(t, err)
+
+	a, b, c := hex.EncodeToString(contractA[:]), hex.EncodeToString(contractB[:]), hex.EncodeToString(contractC[:])
+	assert.Equal(t, []string{a, b, c}, g.Contracts)
+	assert.Equal(t, 3, g.Depth)
+	assert.Equal(t, []Edge{
+		{From: rootID, To: a, Function: "swap", Count: 1},
+		{From: a, To: b, Function: "transfer", Count: 1},
+		{From: a, To: c, Function: "notify", Count: 1},
+		{From: c, To: a, Function: "on_notify", Count: 1, Reentrant: true},
+	}, g.Edges)
+}
+
+func TestAnalyze(t *testing.T) {
+	g, err := FromEvents(swapEvents(t))
+	require.NoError(t, err)
+
+	findings := g.Analyze(Options{})
+	require.Len(t, findings, 2)
+
+	write, reentry := findings[0], findings[1]
+	assert.Equal(t, KindWriteAfterCall, write.Kind)
+	assert.Equal(t, "swap", write.Function)
+	assert.Contains(t, write.Detail, `"storage_write" after calling`)
+
+	assert.Equal(t, KindReentry, reentry.Kind)
+	assert.Equal(t, "on_notify", reentry.Function)
+	require.Len(t, reentry.Path, 3)
+	assert.True(t, strings.HasSuffix(reentry.Path[0], ".swap"))
+	assert.True(t, strings.HasSuffix(reentry.Path[2], ".on_notify"))
+}
+
+func TestAnalyze_DeepChain(t *testing.T) {
+	g, err := FromEvents(swapEvents(t))
+	require.NoError(t, err)
+
+	var deep []Finding
+	for _, f := range g.Analyze(Options{MaxDepth: 2}) {
+		if f.Kind == KindDeepChain {
+			deep = append(deep, f)
+		}
+	}
+	require.Len(t, deep, 1)
+	assert.Equal(t, "on_notify", deep[0].Function)
+	assert.Len(t, deep[0].Path, 3)
+}
+
+func TestAnalyze_WriteBeforeCall(t *testing.T) {
+	g, err := FromEvents([]string{
+		call(t, contractA, "deposit"),
+		event(t, contractA, "storage_write"),
+		call(t, contractB, "transfer"),
+		ret(t, contractB, "transfer"),
+		ret(t, contractA, "deposit"),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, g.Analyze(Options{}))
+}
+
+func TestRender(t *testing.T) {
+	g, err := FromEvents(swapEvents(t))
+	require.NoError(t, err)
+
+	mermaid := g.Mermaid()
+	assert.True(t, strings.HasPrefix(mermaid, "flowchart LR\n"))
+	assert.Contains(t, mermaid, `n0["transaction"]`)
+	assert.Contains(t, mermaid, `n3 -.->|"on_notify (re-entry)"| n1`)
+	assert.Contains(t, mermaid, "linkStyle 3 stroke:#d33")
+
+	dot := g.DOT()
+	assert.True(t, strings.HasPrefix(dot, "digraph callgraph {"))
+	assert.Contains(t, dot, `n0 -> n1 [label="swap"];`)
+	assert.Contains(t, dot, `n3 -> n1 [label="on_notify (re-entry)", style=dashed, color=red, fontcolor=red];`)
+
+	assert.Equal(t, 4, strings.Count(g.Tree(), "\n"))
+}
+
+func TestLabel(t *testing.T) {
+	assert.Equal(t, "transaction", Label(rootID))
+	full := FullID(hex.EncodeToString(contractA[:]))
+	assert.True(t, strings.HasPrefix(full, "C"))
+	assert.Len(t, full, 56)
+	assert.Equal(t, full[:4]+"…"+full[52:], Label(hex.EncodeToString(contractA[:])))
+	assert.Equal(t, "short", Label("short"))
+}
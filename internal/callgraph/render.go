@@ -0,0 +1,103 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package callgraph
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/decoder"
+)
+
+// nodeIDs numbers the transaction and the contracts for diagram output.
+func (g *Graph) nodeIDs() ([]string, map[string]string) {
+	contracts := append([]string{rootID}, g.Contracts...)
+	ids := make(map[string]string, len(contracts))
+	for i, c := range contracts {
+		ids[c] = fmt.Sprintf("n%d", i)
+	}
+	return contracts, ids
+}
+
+func edgeLabel(e Edge) string {
+	label := e.Function
+	if e.Count > 1 {
+		label += fmt.Sprintf(" ×%d", e.Count)
+	}
+	if e.Reentrant {
+		label += " (re-entry)"
+	}
+	return label
+}
+
+// Mermaid renders the graph as a Mermaid flowchart. Re-entrant calls are
+// drawn dashed and red.
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	contracts, ids := g.nodeIDs()
+	for _, c := range contracts {
+		b.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", ids[c], escapeMermaidLabel(Label(c))))
+	}
+	var reentrant []string
+	for i, e := range g.Edges {
+		arrow := "-->"
+		if e.Reentrant {
+			arrow = "-.->"
+			reentrant = append(reentrant, fmt.Sprint(i))
+		}
+		b.WriteString(fmt.Sprintf("  %s %s|\"%s\"| %s\n", ids[e.From], arrow, escapeMermaidLabel(edgeLabel(e)), ids[e.To]))
+	}
+	if len(reentrant) > 0 {
+		b.WriteString(fmt.Sprintf("  linkStyle %s stroke:#d33,color:#d33\n", strings.Join(reentrant, ",")))
+	}
+	return b.String()
+}
+
+// DOT renders the graph in Graphviz DOT format. Re-entrant calls are drawn
+// dashed and red.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph callgraph {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+
+	contracts, ids := g.nodeIDs()
+	for _, c := range contracts {
+		b.WriteString(fmt.Sprintf("  %s [label=%q, tooltip=%q];\n", ids[c], Label(c), FullID(c)))
+	}
+	for _, e := range g.Edges {
+		attrs := fmt.Sprintf("label=%q", edgeLabel(e))
+		if e.Reentrant {
+			attrs += ", style=dashed, color=red, fontcolor=red"
+		}
+		b.WriteString(fmt.Sprintf("  %s -> %s [%s];\n", ids[e.From], ids[e.To], attrs))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Tree renders the call tree as indented text, one call per line.
+func (g *Graph) Tree() string {
+	var b strings.Builder
+	var walk func(node *decoder.CallNode, depth int)
+	walk = func(node *decoder.CallNode, depth int) {
+		for _, call := range node.SubCalls {
+			b.WriteString(fmt.Sprintf("%s%s.%s\n", strings.Repeat("  ", depth), Label(call.ContractID), call.Function))
+			walk(call, depth+1)
+		}
+	}
+	walk(g.Root, 0)
+	return b.String()
+}
+
+var mermaidUnsafe = regexp.MustCompile(`[]"]`)
+
+func escapeMermaidLabel(s string) string {
+	return mermaidUnsafe.ReplaceAllStringFunc(s, func(m string) string {
+		return "\\" + m
+	})
+}
@@ -31,42 +31,99 @@ Examples:
 	RunE: abiExec,
 }
 
+var abiDiffCmd = &cobra.Command{
+	Use:   "diff <old.wasm> <new.wasm>",
+	Short: "Check interface compatibility between two contract versions",
+	Long: `Compare the contract specs of two WASM builds and list every interface
+change: removed or renamed functions, parameter and return type changes, struct
+field changes, enum variant renumbering, and changed error codes.
+
+Each change is classified as breaking or non-breaking. The command exits with
+a non-zero status when any breaking change is found, so it can gate contract
+releases in CI.
+
+Examples:
+  erst abi diff ./v1/contract.wasm ./v2/contract.wasm
+  erst abi diff --format json old.wasm new.wasm`,
+	Args: cobra.ExactArgs(2),
+	RunE: abiDiffExec,
+}
+
 func abiExec(cmd *cobra.Command, args []string) error {
-	wasmBytes, err := os.ReadFile(args[0])
+	spec, err := loadContractSpec(args[0])
 	if err != nil {
-		return fmt.Errorf("reading WASM file: %w", err)
+		return err
 	}
 
-	specBytes, err := abi.ExtractCustomSection(wasmBytes, "contractspecv0")
+	switch abiFormat {
+	case "json":
+		output, err := abi.FormatJSON(spec)
+		if err != nil {
+			return err
+		}
+		fmt.Println(output)
+	case "text":
+		fmt.Print(abi.FormatText(spec))
+	default:
+		return errors.WrapValidationError(fmt.Sprintf("unsupported format: %s (use: text, json)", abiFormat))
+	}
+
+	return nil
+}
+
+func abiDiffExec(cmd *cobra.Command, args []string) error {
+	oldSpec, err := loadContractSpec(args[0])
 	if err != nil {
 		return err
 	}
-	if specBytes == nil {
-		return errors.WrapSpecNotFound()
-	}
-
-	spec, err := abi.DecodeContractSpec(specBytes)
+	newSpec, err := loadContractSpec(args[1])
 	if err != nil {
 		return err
 	}
 
+	diff := abi.Diff(oldSpec, newSpec)
+
 	switch abiFormat {
 	case "json":
-		output, err := abi.FormatJSON(spec)
+		output, err := abi.FormatDiffJSON(diff)
 		if err != nil {
 			return err
 		}
 		fmt.Println(output)
 	case "text":
-		fmt.Print(abi.FormatText(spec))
+		fmt.Print(abi.FormatDiffText(diff))
 	default:
 		return errors.WrapValidationError(fmt.Sprintf("unsupported format: %s (use: text, json)", abiFormat))
 	}
 
+	if diff.HasBreaking() {
+		return errors.WrapValidationError(fmt.Sprintf("%d breaking interface change(s) found", diff.Count(abi.SeverityBreaking)))
+	}
 	return nil
 }
 
+func loadContractSpec(path string) (*abi.ContractSpec, error) {
+	wasmBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading WASM file: %w", err)
+	}
+	return contractSpecFromWasm(wasmBytes)
+}
+
+func contractSpecFromWasm(wasmBytes []byte) (*abi.ContractSpec, error) {
+	specBytes, err := abi.ExtractCustomSection(wasmBytes, "contractspecv0")
+	if err != nil {
+		return nil, err
+	}
+	if specBytes == nil {
+		return nil, errors.WrapSpecNotFound()
+	}
+
+	return abi.DecodeContractSpec(specBytes)
+}
+
 func init() {
-	abiCmd.Flags().StringVar(&abiFormat, "format", "text", "Output format: text or json")
+	abiCmd.PersistentFlags().StringVar(&abiFormat, "format", "text", "Output format: text or json")
+	abiCmd.AddCommand(abiDiffCmd)
 	rootCmd.AddCommand(abiCmd)
 }
@@ -0,0 +1,156 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/dotandev/hintents/internal/abi"
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/dotandev/hintents/internal/visualizer"
+	"github.com/spf13/cobra"
+)
+
+var metaProtocolFlag uint32
+
+var abiMetaCmd = &cobra.Command{
+	Use:   "meta <wasm-file|contract-id>",
+	Short: "Show the protocol, SDK and compiler versions a contract was built with",
+	Long: `Read the "contractenvmetav0" and "contractmetav0" WASM custom sections and
+print the ledger protocol the contract targets, the Soroban SDK and rustc
+versions used to build it, and any custom metadata key-values.
+
+The argument is either a local WASM file or a deployed contract ID, in which
+case the code is fetched over RPC.
+
+A warning is printed when the contract's interface version cannot run on the
+given protocol (--protocol, default: the simulator's current protocol), when
+it was built against a pre-release SDK, or when the simulator cannot replay
+its protocol.
+
+Examples:
+  erst abi meta ./contract.wasm
+  erst abi meta CDLZFC3SYJYDZT7K67VZ75HPJVIEUVNIXF47ZG2FB2RMQQVU2HHGCYSC --network mainnet
+  erst abi meta ./contract.wasm --protocol 21 --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: abiMetaExec,
+}
+
+func abiMetaExec(cmd *cobra.Command, args []string) error {
+	if abiFormat != "text" && abiFormat != "json" {
+		return errors.WrapValidationError(fmt.Sprintf("unsupported format: %s (use: text, json)", abiFormat))
+	}
+	protocol := metaProtocolFlag
+	if protocol == 0 {
+		protocol = simulator.LatestVersion()
+	}
+
+	wasmBytes, err := loadWasmOrContract(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	meta, err := abi.ContractMetaFromWasm(wasmBytes)
+	if err != nil {
+		return errors.WrapValidationError(fmt.Sprintf("decoding contract metadata: %v", err))
+	}
+	warnings := abi.CheckCompatibility(meta, protocol, simulator.Supported())
+
+	if abiFormat == "json" {
+		output, err := abi.FormatMetaJSON(meta, warnings)
+		if err != nil {
+			return errors.WrapMarshalFailed(err)
+		}
+		fmt.Println(output)
+		return nil
+	}
+	fmt.Print(abi.FormatMetaText(meta, warnings))
+	return nil
+}
+
+// loadWasmOrContract reads a local WASM file or, when arg is not a file but a
+// valid contract ID, fetches the deployed code.
+func loadWasmOrContract(ctx context.Context, arg string) ([]byte, error) {
+	if _, statErr := os.Stat(arg); statErr == nil {
+		wasmBytes, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("reading WASM file: %w", err)
+		}
+		return wasmBytes, nil
+	}
+	if _, err := rpc.ParseContractID(arg); err != nil {
+		return nil, errors.WrapValidationError(fmt.Sprintf("%s is neither a WASM file nor a contract id", arg))
+	}
+
+	opts := []rpc.ClientOption{rpc.WithNetwork(rpc.Network(networkFlag))}
+	if rpcURLFlag != "" {
+		opts = append(opts, rpc.WithHorizonURL(rpcURLFlag))
+	}
+	client, err := rpc.NewClient(opts...)
+	if err != nil {
+		return nil, errors.WrapValidationError(fmt.Sprintf("failed to create client: %v", err))
+	}
+	registerCacheFlushHook()
+
+	entries, err := rpc.FetchContractBytecode(ctx, client, arg)
+	if err != nil {
+		return nil, errors.WrapRPCConnectionFailed(err)
+	}
+	for _, entry := range entries {
+		if code, err := rpc.WasmBytesFromContractCodeEntry(entry); err == nil {
+			return code, nil
+		}
+	}
+	return nil, errors.WrapValidationError(fmt.Sprintf("no contract code found for %s", arg))
+}
+
+// isHostIncompatibilityTrap reports whether a failed simulation's error or
+// events point at a host/contract interface mismatch.
+func isHostIncompatibilityTrap(resp *simulator.SimulationResponse) bool {
+	if resp == nil || resp.Status != "error" {
+		return false
+	}
+	if abi.LooksLikeHostIncompatibility(resp.Error) {
+		return true
+	}
+	for _, event := range resp.Events {
+		if abi.LooksLikeHostIncompatibility(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// printContractMetaWarnings checks a trapped contract's interface version
+// against the protocol it ran on and prints any incompatibility.
+func printContractMetaWarnings(label string, wasmBytes []byte, protocol uint32) {
+	meta, err := abi.ContractMetaFromWasm(wasmBytes)
+	if err != nil {
+		return
+	}
+	warnings := abi.CheckCompatibility(meta, protocol, simulator.Supported())
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Printf("\n%s Contract metadata (%s):\n", visualizer.Warning(), label)
+	if meta.SDKVersion != "" {
+		fmt.Printf("  Built with SDK %s\n", meta.SDKVersion)
+	}
+	for _, w := range warnings {
+		fmt.Printf("  - %s\n", w)
+	}
+}
+
+func init() {
+	abiMetaCmd.Flags().Uint32Var(&metaProtocolFlag, "protocol", 0, "Ledger protocol version to check against (default: simulator's current protocol)")
+	abiMetaCmd.Flags().StringVarP(&networkFlag, "network", "n", string(rpc.Mainnet), "Stellar network to use")
+	abiMetaCmd.Flags().StringVar(&rpcURLFlag, "rpc-url", "", "Custom Horizon RPC URL")
+
+	_ = abiMetaCmd.RegisterFlagCompletionFunc("network", completeNetworkFlag)
+
+	abiCmd.AddCommand(abiMetaCmd)
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+
+	"github.com/dotandev/hintents/internal/abi"
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/events"
+	"github.com/dotandev/hintents/internal/logger"
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/dotandev/hintents/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var (
+	storageContractFlag   string
+	storageSnapshotFlag   string
+	storageSampleFlag     int
+	storageSorobanURLFlag string
+)
+
+var abiStorageCmd = &cobra.Command{
+	Use:   "storage-compat <new.wasm>",
+	Short: "Check that a contract's stored data decodes under an upgraded WASM",
+	Long: `Load a deployed contract's storage and decode every entry against the
+user-defined types in the new WASM's contract spec, before running an upgrade.
+
+The spec of the currently deployed code identifies which type each key and
+value was written as. The report lists values that would no longer deserialize
+under the new types and keys whose shape the new code never reads.
+
+RPC cannot enumerate a contract's storage, so entries are collected from:
+  - the contract instance (instance storage), always
+  - a snapshot file (--snapshot), e.g. one written by "erst debug"
+  - entries touched by recent transactions (--sample), refreshed to their
+    current values
+
+The command exits with a non-zero status when any value would fail to decode.
+
+Examples:
+  erst abi storage-compat ./v2/contract.wasm --contract CDLZ...
+  erst abi storage-compat new.wasm --contract CDLZ... --snapshot state.json
+  erst abi storage-compat new.wasm --contract CDLZ... --sample 50 --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: abiStorageExec,
+}
+
+func abiStorageExec(cmd *cobra.Command, args []string) error {
+	if storageContractFlag == "" {
+		return errors.WrapCliArgumentRequired("contract")
+	}
+	if abiFormat != "text" && abiFormat != "json" {
+		return errors.WrapValidationError(fmt.Sprintf("unsupported format: %s (use: text, json)", abiFormat))
+	}
+	contractID, err := rpc.ParseContractID(storageContractFlag)
+	if err != nil {
+		return errors.WrapValidationError(fmt.Sprintf("invalid contract id: %v", err))
+	}
+
+	newSpec, err := loadContractSpec(args[0])
+	if err != nil {
+		return err
+	}
+
+	opts := []rpc.ClientOption{rpc.WithNetwork(rpc.Network(networkFlag))}
+	if rpcURLFlag != "" {
+		opts = append(opts, rpc.WithHorizonURL(rpcURLFlag))
+	}
+	if storageSorobanURLFlag != "" {
+		opts = append(opts, rpc.WithSorobanURL(storageSorobanURLFlag))
+	}
+	client, err := rpc.NewClient(opts...)
+	if err != nil {
+		return errors.WrapValidationError(fmt.Sprintf("failed to create client: %v", err))
+	}
+	registerCacheFlushHook()
+
+	ctx := cmd.Context()
+	deployed, err := rpc.FetchContractBytecode(ctx, client, storageContractFlag)
+	if err != nil {
+		return errors.WrapRPCConnectionFailed(err)
+	}
+
+	var oldSpec *abi.ContractSpec
+	oldSpecErr := "no contract code found for the deployed contract"
+	for _, entry := range deployed {
+		code, err := rpc.WasmBytesFromContractCodeEntry(entry)
+		if err != nil {
+			continue
+		}
+		if oldSpec, err = contractSpecFromWasm(code); err != nil {
+			logger.Logger.Warn("Deployed contract spec unavailable", "error", err)
+			oldSpecErr = err.Error()
+			oldSpec = nil
+		}
+		break
+	}
+
+	ledger := maps.Clone(deployed)
+	if storageSnapshotFlag != "" {
+		snap, err := snapshot.Load(storageSnapshotFlag)
+		if err != nil {
+			return errors.WrapValidationError(err.Error())
+		}
+		maps.Copy(ledger, snap.ToMap())
+	}
+
+	if storageSampleFlag > 0 {
+		hashes, err := events.SampleTransactions(ctx, client, events.SampleOptions{
+			ContractID: storageContractFlag,
+			Count:      storageSampleFlag,
+		})
+		if err != nil {
+			return errors.WrapRPCConnectionFailed(err)
+		}
+		for _, hash := range hashes {
+			resp, err := client.GetTransaction(ctx, hash)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "skipping %s: %v\n", hash, err)
+				continue
+			}
+			keys, err := extractLedgerKeys(resp.ResultMetaXdr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "skipping %s: %v\n", hash, err)
+				continue
+			}
+			// Fetch current values: the meta only shows what the entries held
+			// at the time, and some may since have been removed.
+			current, err := client.GetLedgerEntries(ctx, keys)
+			if err != nil {
+				return errors.WrapRPCConnectionFailed(err)
+			}
+			maps.Copy(ledger, current)
+		}
+	}
+
+	entries, err := abi.StorageEntriesFromLedger(ledger, contractID)
+	if err != nil {
+		return errors.WrapValidationError(err.Error())
+	}
+	report := abi.CheckStorage(oldSpec, newSpec, entries)
+	if oldSpec == nil {
+		report.OldSpecError = oldSpecErr
+	}
+
+	if abiFormat == "json" {
+		output, err := abi.FormatStorageJSON(report)
+		if err != nil {
+			return errors.WrapMarshalFailed(err)
+		}
+		fmt.Println(output)
+	} else {
+		fmt.Print(abi.FormatStorageText(report))
+	}
+
+	if report.OldSpecError != "" {
+		return errors.WrapValidationError("storage comparison skipped: the deployed contract spec could not be loaded")
+	}
+	if !report.Compatible() {
+		return errors.WrapValidationError("stored data would not decode under the new contract spec")
+	}
+	return nil
+}
+
+func init() {
+	abiStorageCmd.Flags().StringVar(&storageContractFlag, "contract", "", "Deployed contract ID (C... or hex)")
+	abiStorageCmd.Flags().StringVar(&storageSnapshotFlag, "snapshot", "", "Snapshot file with additional storage entries")
+	abiStorageCmd.Flags().IntVar(&storageSampleFlag, "sample", 0, "Also check entries touched by this many recent transactions")
+	abiStorageCmd.Flags().StringVarP(&networkFlag, "network", "n", string(rpc.Mainnet), "Stellar network to use")
+	abiStorageCmd.Flags().StringVar(&rpcURLFlag, "rpc-url", "", "Custom Horizon RPC URL")
+	abiStorageCmd.Flags().StringVar(&storageSorobanURLFlag, "soroban-url", "", "Custom Soroban RPC URL")
+
+	_ = abiStorageCmd.RegisterFlagCompletionFunc("network", completeNetworkFlag)
+
+	abiCmd.AddCommand(abiStorageCmd)
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/callgraph"
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/visualizer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	callgraphSessionFlag  string
+	callgraphFormatFlag   string
+	callgraphOutputFlag   string
+	callgraphMaxDepthFlag int
+)
+
+var callgraphCmd = &cobra.Command{
+	Use:     "callgraph",
+	GroupID: "utility",
+	Short:   "Show the cross-contract call graph of a transaction and check it for re-entry",
+	Long: `Build the cross-contract call graph of the current (or a saved) session from
+its fn_call and fn_return diagnostic events, and report:
+
+  - re-entry: a contract called again while it is still on the call stack
+    (A -> B -> A), which the Soroban host rejects
+  - state writes after external calls (checks-effects-interactions
+    violations)
+  - call chains deeper than --max-depth
+
+The graph can be printed as a Mermaid flowchart or Graphviz DOT diagram, with
+re-entrant calls drawn dashed and red.
+
+Examples:
+  erst callgraph
+  erst callgraph --session <id> --format mermaid
+  erst callgraph --format dot -o calls.dot && dot -Tsvg calls.dot -o calls.svg`,
+	Args: cobra.NoArgs,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		switch callgraphFormatFlag {
+		case "text", "mermaid", "dot", "json":
+			return nil
+		}
+		return errors.WrapValidationError(fmt.Sprintf("unsupported format: %s (use: text, mermaid, dot, json)", callgraphFormatFlag))
+	},
+	RunE: runCallgraph,
+}
+
+func runCallgraph(cmd *cobra.Command, args []string) error {
+	simResp, err := loadSimulationResponse(cmd, callgraphSessionFlag)
+	if err != nil {
+		return err
+	}
+	if len(simResp.DiagnosticEventsXdr) == 0 {
+		return errors.WrapValidationError("the session has no diagnostic event XDR; replay the transaction to record it")
+	}
+	graph, err := callgraph.FromEvents(simResp.DiagnosticEventsXdr)
+	if err != nil {
+		return fmt.Errorf("failed to decode diagnostic events: %w", err)
+	}
+	findings := graph.Analyze(callgraph.Options{MaxDepth: callgraphMaxDepthFlag})
+
+	var out string
+	switch callgraphFormatFlag {
+	case "mermaid":
+		out = graph.Mermaid()
+	case "dot":
+		out = graph.DOT()
+	case "json":
+		data, err := json.MarshalIndent(struct {
+			*callgraph.Graph
+			Findings []callgraph.Finding `json:"findings"`
+		}{graph, findings}, "", "  ")
+		if err != nil {
+			return errors.WrapMarshalFailed(err)
+		}
+		out = string(data) + "\n"
+	default:
+		out = formatCallgraph(graph, findings)
+	}
+
+	if callgraphOutputFlag == "" {
+		fmt.Print(out)
+		return nil
+	}
+	if err := os.WriteFile(callgraphOutputFlag, []byte(out), 0644); err != nil {
+		return fmt.Errorf("failed to write call graph: %w", err)
+	}
+	fmt.Printf("%s Call graph saved: %s\n", visualizer.Success(), callgraphOutputFlag)
+	return nil
+}
+
+func formatCallgraph(graph *callgraph.Graph, findings []callgraph.Finding) string {
+	if len(graph.Edges) == 0 {
+		return "No contract calls found in the diagnostic events.\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Call tree (%d contract(s), depth %d):\n", len(graph.Contracts), graph.Depth)
+	for _, line := range strings.Split(strings.TrimRight(graph.Tree(), "\n"), "\n") {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+
+	if len(findings) == 0 {
+		fmt.Fprintf(&b, "\n%s No re-entry, late state writes or deep call chains detected\n", visualizer.Success())
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\nFindings: %d\n", len(findings))
+	for i, f := range findings {
+		fmt.Fprintf(&b, "%d. %s %s [%s]\n", i+1, visualizer.Warning(), f.Detail, f.Kind)
+		fmt.Fprintf(&b, "   Path: %s\n", callgraph.FormatPath(f.Path))
+	}
+	return b.String()
+}
+
+func init() {
+	callgraphCmd.Flags().StringVar(&callgraphSessionFlag, "session", "", "Load a saved session by ID")
+	callgraphCmd.Flags().StringVar(&callgraphFormatFlag, "format", "text", "Output format (text, mermaid, dot, json)")
+	callgraphCmd.Flags().StringVarP(&callgraphOutputFlag, "output", "o", "", "Write the output to a file instead of stdout")
+	callgraphCmd.Flags().IntVar(&callgraphMaxDepthFlag, "max-depth", callgraph.DefaultMaxDepth, "Report call chains deeper than this")
+	rootCmd.AddCommand(callgraphCmd)
+}
@@ -0,0 +1,174 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/abi"
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/logger"
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/dotandev/hintents/internal/statediff"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+var (
+	changesFormatFlag string
+	changesNoSpecFlag bool
+)
+
+var changesCmd = &cobra.Command{
+	Use:     "changes <transaction-hash>",
+	GroupID: "utility",
+	Short:   "Show the ledger entries a transaction changed, before and after",
+	Long: `List every ledger entry a transaction created, updated, removed or restored,
+as recorded in its TransactionMeta, with the entry's value before and after.
+
+Contract data keys and values are decoded with the contract spec embedded in
+the contract's WASM, so storage shows up as DataKey::Balance(G...) and
+Config { fee: 10 }. Updated structs, maps and classic entries list only the
+fields that changed. TTL entries are shown against the entry they belong to,
+with extensions and archival restores called out.
+
+Examples:
+  erst changes 5c0a...
+  erst changes 5c0a... --network testnet --format json`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if changesFormatFlag != "text" && changesFormatFlag != "json" {
+			return errors.WrapValidationError(fmt.Sprintf("unsupported format: %s (use: text, json)", changesFormatFlag))
+		}
+		return nil
+	},
+	RunE: runChanges,
+}
+
+func runChanges(cmd *cobra.Command, args []string) error {
+	opts := []rpc.ClientOption{rpc.WithNetwork(rpc.Network(networkFlag))}
+	if rpcURLFlag != "" {
+		opts = append(opts, rpc.WithHorizonURL(rpcURLFlag))
+	}
+	client, err := rpc.NewClient(opts...)
+	if err != nil {
+		return errors.WrapValidationError(fmt.Sprintf("failed to create client: %v", err))
+	}
+	registerCacheFlushHook()
+
+	ctx := cmd.Context()
+	resp, err := client.GetTransaction(ctx, args[0])
+	if err != nil {
+		return errors.WrapRPCConnectionFailed(err)
+	}
+	if resp.ResultMetaXdr == "" {
+		return errors.WrapValidationError(fmt.Sprintf("transaction %s has no result metadata", args[0]))
+	}
+
+	changes, err := statediff.FromTransaction(resp.EnvelopeXdr, resp.ResultMetaXdr)
+	if err != nil {
+		return errors.WrapUnmarshalFailed(err, "result meta")
+	}
+
+	specs := statediff.Specs{}
+	if !changesNoSpecFlag {
+		metaEntries, _ := rpc.ExtractLedgerEntriesFromMeta(resp.ResultMetaXdr)
+		for _, id := range statediff.Contracts(changes) {
+			spec, err := changesContractSpec(ctx, client, metaEntries, id)
+			if err != nil {
+				logger.Logger.Warn("Contract spec unavailable; showing raw values", "contract_id", id, "error", err)
+				continue
+			}
+			specs[id] = spec
+		}
+	}
+
+	summaries := make([]statediff.Summary, len(changes))
+	for i, c := range changes {
+		summaries[i] = c.Describe(specs)
+	}
+
+	if changesFormatFlag == "json" {
+		data, err := json.MarshalIndent(summaries, "", "  ")
+		if err != nil {
+			return errors.WrapMarshalFailed(err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	fmt.Print(statediff.FormatText(summaries))
+	return nil
+}
+
+// changesContractSpec loads the spec of the code a contract ran at the time
+// of the transaction: its instance and code are taken from the meta when the
+// transaction touched them, and fetched from the network otherwise.
+func changesContractSpec(ctx context.Context, client *rpc.Client, metaEntries map[string]string, id string) (*abi.ContractSpec, error) {
+	cid, err := rpc.ParseContractID(id)
+	if err != nil {
+		return nil, err
+	}
+	instanceKey, err := rpc.LedgerKeyForContractInstance(cid)
+	if err != nil {
+		return nil, err
+	}
+	instanceKeyB64, err := rpc.EncodeLedgerKey(instanceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, ok := metaEntries[instanceKeyB64]
+	if !ok {
+		fetched, err := rpc.FetchContractBytecode(ctx, client, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range fetched {
+			if code, err := rpc.WasmBytesFromContractCodeEntry(entry); err == nil {
+				return contractSpecFromWasm(code)
+			}
+		}
+		return nil, fmt.Errorf("contract code not found")
+	}
+
+	hash, err := rpc.ContractCodeHashFromInstanceEntry(instance)
+	if err != nil {
+		return nil, err
+	}
+	codeKeyB64, err := rpc.EncodeLedgerKey(xdr.LedgerKey{
+		Type:         xdr.LedgerEntryTypeContractCode,
+		ContractCode: &xdr.LedgerKeyContractCode{Hash: hash},
+	})
+	if err != nil {
+		return nil, err
+	}
+	codeEntry, ok := metaEntries[codeKeyB64]
+	if !ok {
+		fetched, err := client.GetLedgerEntries(ctx, []string{codeKeyB64})
+		if err != nil {
+			return nil, err
+		}
+		if codeEntry, ok = fetched[codeKeyB64]; !ok {
+			return nil, fmt.Errorf("contract code %x not found", hash)
+		}
+	}
+	code, err := rpc.WasmBytesFromContractCodeEntry(codeEntry)
+	if err != nil {
+		return nil, err
+	}
+	return contractSpecFromWasm(code)
+}
+
+func init() {
+	changesCmd.Flags().StringVarP(&networkFlag, "network", "n", string(rpc.Mainnet), "Stellar network to use")
+	changesCmd.Flags().StringVar(&rpcURLFlag, "rpc-url", "", "Custom Horizon RPC URL")
+	changesCmd.Flags().StringVar(&changesFormatFlag, "format", "text", "Output format (text, json)")
+	changesCmd.Flags().BoolVar(&changesNoSpecFlag, "no-spec", false, "Do not fetch contract specs to decode contract data")
+
+	_ = changesCmd.RegisterFlagCompletionFunc("network", completeNetworkFlag)
+
+	rootCmd.AddCommand(changesCmd)
+}
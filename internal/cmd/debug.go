@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
 	"strings"
@@ -19,10 +20,10 @@ import (
 	"github.com/dotandev/hintents/internal/decenstorage"
 	"github.com/dotandev/hintents/internal/decoder"
 	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/footprint"
 	"github.com/dotandev/hintents/internal/logger"
 	"github.com/dotandev/hintents/internal/lto"
 	"github.com/dotandev/hintents/internal/rpc"
-	"github.com/dotandev/hintents/internal/security"
 	"github.com/dotandev/hintents/internal/session"
 	"github.com/dotandev/hintents/internal/simulator"
 	"github.com/dotandev/hintents/internal/snapshot"
@@ -38,58 +39,35 @@ import (
 )
 
 var (
-	networkFlag         string
-	rpcURLFlag          string
-	rpcTokenFlag        string
-	tracingEnabled      bool
-	otlpExporterURL     string
-	generateTrace       bool
-	traceOutputFile     string
-	snapshotFlag        string
-	compareNetworkFlag  string
-	verbose             bool
-	wasmPath            string
-	wasmOptimizeFlag    bool
-	args                []string
-	themeFlag           string
-	noCacheFlag         bool
-	demoMode            bool
-	watchFlag           bool
-	watchTimeoutFlag    int
-	protocolVersionFlag  uint32
+	networkFlag          string
+	rpcURLFlag           string
+	rpcTokenFlag         string
+	tracingEnabled       bool
+	otlpExporterURL      string
+	generateTrace        bool
+	traceOutputFile      string
+	snapshotFlag         string
+	compareNetworkFlag   string
+	verbose              bool
+	wasmPath             string
+	wasmOptimizeFlag     bool
+	args                 []string
 	themeFlag            string
+	noCacheFlag          bool
+	noVerifiedSourceFlag bool
+	demoMode             bool
+	watchFlag            bool
+	watchTimeoutFlag     int
+	protocolVersionFlag  uint32
 	auditKeyFlag         string
 	publishIPFSFlag      bool
 	publishArweaveFlag   bool
 	ipfsNodeFlag         string
 	arweaveGatewayFlag   string
 	arweaveWalletFlag    string
-	protocolVersionFlag uint32
-	themeFlag           string
-	mockTimeFlag        int64
-	networkFlag        string
-	rpcURLFlag         string
-	rpcTokenFlag       string
-	tracingEnabled     bool
-	otlpExporterURL    string
-	generateTrace      bool
-	traceOutputFile    string
-	snapshotFlag       string
-	compareNetworkFlag string
-	verbose            bool
-	wasmPath           string
-	args               []string
-	noCacheFlag        bool
-	demoMode           bool
-	watchFlag          bool
-	watchTimeoutFlag   int
-	mockBaseFeeFlag    uint32
-	mockGasPriceFlag   uint64
-	themeFlag           string
-	mockTimeFlag        int64
-	protocolVersionFlag uint32
-	mockBaseFeeFlag     uint32
-	mockGasPriceFlag    uint64
+	mockTimeFlag         int64
+	mockBaseFeeFlag      uint32
+	mockGasPriceFlag     uint64
 )
 
 // DebugCommand holds dependencies for the debug command
@@ -217,6 +195,9 @@ Local WASM Replay Mode:
   # Compare execution across networks
   erst debug --network testnet --compare-network mainnet <tx-hash>
 
+  # Write security findings as SARIF for code scanning
+  erst debug abc123...def789 --security --security-output erst.sarif
+
   # Local WASM replay (no network required)
   erst debug --wasm ./contract.wasm --args "arg1" --args "arg2"
 
@@ -224,6 +205,10 @@ Local WASM Replay Mode:
   erst debug --demo`,
 	Args: cobra.MaximumNArgs(1),
 	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateSecurityFlags(); err != nil {
+			return err
+		}
+
 		// Demo mode or local WASM replay don't need transaction hash
 		if demoMode || wasmPath != "" {
 			return nil
@@ -435,6 +420,12 @@ Local WASM Replay Mode:
 		registerRunnerCloseHook("debug-simulator-runner", runner)
 		defer func() { _ = runner.Close() }()
 
+		// Check the footprint for expiring and archived entries
+		restoredEntries, err := runArchivalAnalysis(ctx, client, runner, resp.EnvelopeXdr)
+		if err != nil {
+			return err
+		}
+
 		// Determine timestamps to simulate
 		timestamps := []int64{TimestampFlag}
 		if WindowFlag > 0 && TimestampFlag > 0 {
@@ -446,6 +437,9 @@ Local WASM Replay Mode:
 		}
 
 		var lastSimResp *simulator.SimulationResponse
+		// replayEntries are the ledger entries of the last single-network
+		// replay, kept with the session as its state snapshot.
+		var replayEntries map[string]string
 
 		for _, ts := range timestamps {
 			if len(timestamps) > 1 {
@@ -478,6 +472,15 @@ Local WASM Replay Mode:
 					}
 				}
 
+				if len(restoredEntries) > 0 {
+					ledgerEntries = maps.Clone(ledgerEntries)
+					if ledgerEntries == nil {
+						ledgerEntries = map[string]string{}
+					}
+					maps.Copy(ledgerEntries, restoredEntries)
+				}
+				replayEntries = ledgerEntries
+
 				fmt.Printf("Running simulation on %s...\n", networkFlag)
 				simReq := &simulator.SimulationRequest{
 					EnvelopeXdr:     resp.EnvelopeXdr,
@@ -487,6 +490,9 @@ Local WASM Replay Mode:
 					ProtocolVersion: nil,
 					Profile:         ProfileFlag,
 				}
+				if len(restoredEntries) > 0 {
+					simReq.RestorePreamble = map[string]interface{}{"ledger_entries": restoredEntries}
+				}
 
 				// Apply protocol version override if specified
 				if protocolVersionFlag > 0 {
@@ -506,8 +512,21 @@ Local WASM Replay Mode:
 				// Fetch contract bytecode on demand for any contract calls in the trace; cache via RPC client
 				if client != nil && simResp != nil && len(simResp.DiagnosticEvents) > 0 {
 					contractIDs := collectContractIDsFromDiagnosticEvents(simResp.DiagnosticEvents)
-					if len(contractIDs) > 0 {
-						_, _ = rpc.FetchBytecodeForTraceContractCalls(ctx, client, contractIDs, nil)
+					deployed := deployedWasms(ctx, client, contractIDs)
+					// Without local debug info, place the trap in the source verified
+					// on stellar.expert for the deployed code.
+					if simResp.Status == "error" && !noVerifiedSourceFlag {
+						if sources := loadVerifiedSources(ctx, networkFlag, contractIDs, deployed); sources != nil {
+							printVerifiedSource(simResp, sources)
+						}
+					}
+					if isHostIncompatibilityTrap(simResp) {
+						protocol := simulator.GetOrDefault(simReq.ProtocolVersion).Version
+						for _, id := range contractIDs {
+							if code, ok := deployed[id]; ok {
+								printContractMetaWarnings(id, code, protocol)
+							}
+						}
 					}
 				}
 			} else {
@@ -634,50 +653,35 @@ Local WASM Replay Mode:
 		}
 
 		// Analysis: Security
-		fmt.Printf("\n=== Security Analysis ===\n")
-		secDetector := security.NewDetector()
-		findings := secDetector.Analyze(resp.EnvelopeXdr, resp.ResultMetaXdr, lastSimResp.Events, lastSimResp.Logs)
-		if len(findings) == 0 {
-			fmt.Printf("%s No security issues detected\n", visualizer.Success())
-		} else {
-			verifiedCount := 0
-			heuristicCount := 0
-
-			for _, finding := range findings {
-				if finding.Type == security.FindingVerifiedRisk {
-					verifiedCount++
-				} else {
-					heuristicCount++
-				}
-			}
-
-			if verifiedCount > 0 {
-				fmt.Printf("\n[!]  VERIFIED SECURITY RISKS: %d\n", verifiedCount)
-			}
-			if heuristicCount > 0 {
-				fmt.Printf("* HEURISTIC WARNINGS: %d\n", heuristicCount)
-			}
+		if err := runSecurityAnalysis(txHash, resp.EnvelopeXdr, resp.ResultMetaXdr, lastSimResp); err != nil {
+			return err
+		}
 
-			fmt.Printf("\nFindings:\n")
-			for i, finding := range findings {
-				icon := "*"
-				if finding.Type == security.FindingVerifiedRisk {
-					icon = "[!]"
-				}
-				fmt.Printf("%d. %s [%s] %s - %s\n", i+1, icon, finding.Type, finding.Severity, finding.Title)
-				fmt.Printf("   %s\n", finding.Description)
-				if finding.Evidence != "" {
-					fmt.Printf("   Evidence: %s\n", finding.Evidence)
-				}
-			}
+		// Analysis: Resource Tuning
+		if compareNetworkFlag == "" {
+			printResourceTuning(resp.EnvelopeXdr, lastSimResp, replayEntries)
 		}
 
 		// Analysis: Token Flows
 		if report, err := tokenflow.BuildReport(resp.EnvelopeXdr, resp.ResultMetaXdr); err == nil && len(report.Agg) > 0 {
+			// Symbols and decimals come from each token's metadata: the
+			// replayed state first, the network for the rest.
+			if err := report.ResolveTokens(ctx, tokenflow.Entries(replayEntries), client); err != nil {
+				logger.Logger.Debug("Failed to resolve token metadata", "error", err)
+			}
 			fmt.Printf("\nToken Flow Summary:\n")
 			for _, line := range report.SummaryLines() {
 				fmt.Printf("  %s\n", line)
 			}
+			if len(report.Balances) > 0 {
+				fmt.Printf("\nBalances (before -> after):\n")
+				for _, line := range report.BalanceLines() {
+					fmt.Printf("  %s\n", line)
+				}
+			}
+			for _, d := range report.Discrepancies {
+				fmt.Printf("  %s %s\n", visualizer.Warning(), d.Detail())
+			}
 			fmt.Printf("\nToken Flow Chart (Mermaid):\n")
 			fmt.Println(report.MermaidFlowchart())
 		}
@@ -686,6 +690,7 @@ Local WASM Replay Mode:
 		simReq := &simulator.SimulationRequest{
 			EnvelopeXdr:   resp.EnvelopeXdr,
 			ResultMetaXdr: resp.ResultMetaXdr,
+			LedgerEntries: replayEntries,
 		}
 		applySimulationFeeMocks(simReq)
 		simReqJSON, err := json.Marshal(simReq)
@@ -729,6 +734,7 @@ Local WASM Replay Mode:
 				lastSimResp.Events,
 				lastSimResp.Logs,
 				auditKeyFlag,
+				nil,
 			)
 			if auditErr != nil {
 				return fmt.Errorf("failed to generate audit log: %w", auditErr)
@@ -866,6 +872,12 @@ func runLocalWasmReplay(ctx context.Context) error {
 				fmt.Println(fallbackMsg)
 			}
 		}
+
+		if isHostIncompatibilityTrap(resp) {
+			if wasmBytes, err := os.ReadFile(effectiveWasmPath); err == nil {
+				printContractMetaWarnings(wasmPath, wasmBytes, simulator.LatestVersion())
+			}
+		}
 	} else {
 		fmt.Printf("%s Execution completed successfully\n", visualizer.Success())
 	}
@@ -1204,11 +1216,13 @@ func init() {
 	debugCmd.Flags().StringSliceVar(&args, "args", []string{}, "Mock arguments for local replay (JSON array of strings)")
 	debugCmd.Flags().StringVar(&themeFlag, "theme", "", "Output theme (default, deuteranopia, protanopia, tritanopia, high-contrast)")
 	debugCmd.Flags().BoolVar(&noCacheFlag, "no-cache", false, "Disable local ledger state caching")
+	debugCmd.Flags().IntVar(&resourceMarginFlag, "resource-margin", footprint.DefaultMarginPercent, "Percentage added to measured instructions when recommending resources")
+	debugCmd.Flags().BoolVar(&autoRestoreFlag, "auto-restore", false, "Simulate a RestoreFootprint of archived footprint entries before the invocation")
+	debugCmd.Flags().BoolVar(&noVerifiedSourceFlag, "no-verified-source", false, "Do not fetch stellar.expert-verified source to locate traps")
 	debugCmd.Flags().BoolVar(&demoMode, "demo", false, "Print sample output (no network) - for testing color detection")
 	debugCmd.Flags().BoolVar(&watchFlag, "watch", false, "Poll for transaction on-chain before debugging")
 	debugCmd.Flags().IntVar(&watchTimeoutFlag, "watch-timeout", 30, "Timeout in seconds for watch mode")
 	debugCmd.Flags().Uint32Var(&protocolVersionFlag, "protocol-version", 0, "Override protocol version for simulation (20, 21, 22, etc)")
-	debugCmd.Flags().StringVar(&themeFlag, "theme", "", "Color theme (default, deuteranopia, protanopia, tritanopia, high-contrast)")
 	debugCmd.Flags().StringVar(&auditKeyFlag, "audit-key", "", "Ed25519 private key (hex) used to sign the audit trail")
 	debugCmd.Flags().BoolVar(&publishIPFSFlag, "publish-ipfs", false, "Pin signed audit trail to IPFS after simulation (requires --audit-key)")
 	debugCmd.Flags().BoolVar(&publishArweaveFlag, "publish-arweave", false, "Upload signed audit trail to Arweave after simulation (requires --audit-key)")
@@ -1216,9 +1230,6 @@ func init() {
 	debugCmd.Flags().StringVar(&arweaveGatewayFlag, "arweave-gateway", "", "Arweave HTTP gateway URL (default: https://arweave.net or ERST_ARWEAVE_GATEWAY env)")
 	debugCmd.Flags().StringVar(&arweaveWalletFlag, "arweave-wallet", "", "Path to Arweave JWK wallet file for signing data transactions (or ERST_ARWEAVE_WALLET env)")
 	debugCmd.Flags().Int64Var(&mockTimeFlag, "mock-time", 0, "Fix the ledger timestamp for deterministic local simulation (Unix epoch seconds); 0 = disabled")
-	debugCmd.Flags().StringVar(&themeFlag, "theme", "", "Output theme (default, deuteranopia, protanopia, tritanopia, high-contrast)")
-	debugCmd.Flags().Int64Var(&mockTimeFlag, "mock-time", 0, "Override ledger timestamp (Unix epoch) for simulation")
-	debugCmd.Flags().Uint32Var(&protocolVersionFlag, "protocol-version", 0, "Override protocol version for simulation")
 	debugCmd.Flags().Uint32Var(&mockBaseFeeFlag, "mock-base-fee", 0, "Override base fee (stroops) for local fee sufficiency checks")
 	debugCmd.Flags().Uint64Var(&mockGasPriceFlag, "mock-gas-price", 0, "Override gas price multiplier for local fee sufficiency checks")
 
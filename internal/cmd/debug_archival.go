@@ -0,0 +1,82 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/archival"
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/logger"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/dotandev/hintents/internal/visualizer"
+)
+
+var autoRestoreFlag bool
+
+// runArchivalAnalysis reports the remaining lifetime of every footprint entry
+// of the transaction at the latest ledger. With --auto-restore, archived entries are first restored in a
+// simulated RestoreFootprint transaction; the restored entries are returned
+// so the invocation can be replayed on top of them.
+func runArchivalAnalysis(ctx context.Context, src archival.TTLReader, runner simulator.RunnerInterface, envelopeXdr string) (map[string]string, error) {
+	report, err := archival.Analyze(ctx, src, envelopeXdr, archival.Options{})
+	if err != nil {
+		logger.Logger.Warn("State archival analysis failed", "error", err)
+		return nil, nil
+	}
+	if len(report.Entries) == 0 {
+		return nil, nil
+	}
+
+	fmt.Printf("\n=== State Archival (latest ledger %d) ===\n", report.LatestLedger)
+	for _, line := range report.Lines() {
+		fmt.Printf("  %s\n", line)
+	}
+
+	archived := report.Archived()
+	if len(archived) == 0 {
+		return nil, nil
+	}
+	if !autoRestoreFlag {
+		fmt.Printf("%s %d archived entries need RestoreFootprint before the transaction can succeed; rerun with --auto-restore to simulate the restore first\n",
+			visualizer.Warning(), len(archived))
+		return nil, nil
+	}
+
+	restoreXdr, err := report.RestoreEnvelope(envelopeXdr)
+	if err != nil {
+		return nil, errors.WrapValidationError(fmt.Sprintf("failed to build RestoreFootprint transaction: %v", err))
+	}
+	state, err := report.ArchivedState()
+	if err != nil {
+		return nil, errors.WrapValidationError(fmt.Sprintf("failed to build the archived state: %v", err))
+	}
+
+	fmt.Printf("Simulating RestoreFootprint of %d archived entries...\n", len(archived))
+	restoreResp, err := runner.Run(ctx, &simulator.SimulationRequest{
+		EnvelopeXdr:    restoreXdr,
+		LedgerEntries:  state,
+		LedgerSequence: report.LatestLedger,
+	})
+	if err != nil {
+		return nil, errors.WrapSimulationFailed(err, "")
+	}
+	if restoreResp.Status == "error" {
+		return nil, errors.WrapSimulationLogicError("RestoreFootprint simulation failed: " + restoreResp.Error)
+	}
+
+	restored := report.RestoreEntries()
+	count := 0
+	for _, c := range restoreResp.LedgerChanges {
+		if _, ok := restored[c.KeyXdr]; ok && c.Type == simulator.LedgerChangeRestored {
+			count++
+		}
+	}
+	if count < len(restored) {
+		return nil, errors.WrapSimulationLogicError(fmt.Sprintf("RestoreFootprint simulation restored %d of %d archived entries", count, len(restored)))
+	}
+	fmt.Printf("%s Restored %d entries; replaying the invocation with the restored entries\n", visualizer.Success(), count)
+	return restored, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/security"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/dotandev/hintents/internal/visualizer"
+)
+
+var (
+	securityReportFlag   bool
+	securityFormatFlag   string
+	securityOutputFlag   string
+	securityArtifactFlag string
+)
+
+// validateSecurityFlags checks the security report flags before any network
+// work is done.
+func validateSecurityFlags() error {
+	if securityFormatFlag != "sarif" && securityFormatFlag != "json" {
+		return errors.WrapValidationError(fmt.Sprintf("unsupported security format: %s (use: sarif, json)", securityFormatFlag))
+	}
+	return nil
+}
+
+// runSecurityAnalysis runs the security rule engine configured by erst.toml
+// over a replayed transaction, prints the findings and, with --security,
+// writes the report to a file.
+func runSecurityAnalysis(txHash, envelopeXdr, resultMetaXdr string, simResp *simulator.SimulationResponse) error {
+	fmt.Printf("\n=== Security Analysis ===\n")
+
+	engine, err := loadSecurityEngine()
+	if err != nil {
+		return err
+	}
+
+	report := engine.Analyze(&security.Input{
+		EnvelopeXdr:         envelopeXdr,
+		ResultMetaXdr:       resultMetaXdr,
+		Events:              simResp.Events,
+		DiagnosticEventsXdr: simResp.DiagnosticEventsXdr,
+		Logs:                simResp.Logs,
+		CategorizedEvents:   simResp.CategorizedEvents,
+	})
+	printSecurityFindings(report)
+
+	if securityReportFlag {
+		return writeSecurityReport(txHash, report, engine.Rules())
+	}
+	return nil
+}
+
+// loadSecurityEngine builds the rule engine from the security tables of
+// erst.toml. A configuration that cannot be used fails the run only when a
+// report was requested with --security; otherwise it is reported and the
+// built-in rules run with their defaults.
+func loadSecurityEngine() (*security.Engine, error) {
+	cfg, cfgPath, err := security.LoadConfig()
+	var engine *security.Engine
+	if err == nil {
+		engine, err = security.NewEngine(cfg)
+	}
+	if err != nil {
+		if securityReportFlag {
+			return nil, errors.WrapConfigError("invalid security configuration", err)
+		}
+		fmt.Printf("%s Ignoring security configuration, using the defaults: %v\n", visualizer.Warning(), err)
+		return security.NewEngine(nil)
+	}
+	if cfgPath != "" && verbose {
+		fmt.Printf("Using security rules from %s\n", cfgPath)
+	}
+	return engine, nil
+}
+
+func printSecurityFindings(report *security.Report) {
+	if len(report.Findings) == 0 {
+		fmt.Printf("%s No security issues detected\n", visualizer.Success())
+	} else {
+		verifiedCount := 0
+		heuristicCount := 0
+
+		for _, finding := range report.Findings {
+			if finding.Type == security.FindingVerifiedRisk {
+				verifiedCount++
+			} else {
+				heuristicCount++
+			}
+		}
+
+		if verifiedCount > 0 {
+			fmt.Printf("\n[!]  VERIFIED SECURITY RISKS: %d\n", verifiedCount)
+		}
+		if heuristicCount > 0 {
+			fmt.Printf("* HEURISTIC WARNINGS: %d\n", heuristicCount)
+		}
+
+		fmt.Printf("\nFindings:\n")
+		for i, finding := range report.Findings {
+			icon := "*"
+			if finding.Type == security.FindingVerifiedRisk {
+				icon = "[!]"
+			}
+			fmt.Printf("%d. %s %s [%s] %s - %s\n", i+1, icon, finding.RuleID, finding.Type, finding.Severity, finding.Title)
+			fmt.Printf("   %s\n", finding.Description)
+			if finding.Contract != "" {
+				fmt.Printf("   Contract: %s\n", finding.Contract)
+			}
+			if finding.Evidence != "" {
+				fmt.Printf("   Evidence: %s\n", finding.Evidence)
+			}
+		}
+	}
+
+	if len(report.Suppressed) > 0 {
+		fmt.Printf("(%d finding(s) suppressed by erst.toml)\n", len(report.Suppressed))
+	}
+}
+
+func writeSecurityReport(txHash string, report *security.Report, rules []*security.Rule) error {
+	var (
+		data []byte
+		err  error
+	)
+	path := securityOutputFlag
+	switch securityFormatFlag {
+	case "json":
+		if path == "" {
+			path = txHash + ".security.json"
+		}
+		data, err = json.MarshalIndent(report, "", "  ")
+	default:
+		if path == "" {
+			path = txHash + ".sarif"
+		}
+		data, err = security.MarshalSARIF(report, rules, security.SARIFOptions{
+			ToolVersion: Version,
+			TxHash:      txHash,
+			ArtifactURI: securityArtifactFlag,
+		})
+	}
+	if err != nil {
+		return errors.WrapMarshalFailed(err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write security report: %w", err)
+	}
+	fmt.Printf("%s Security report saved: %s\n", visualizer.Success(), path)
+	return nil
+}
+
+func init() {
+	debugCmd.Flags().BoolVar(&securityReportFlag, "security", false, "Write the security findings to a report file (SARIF by default) for CI upload")
+	debugCmd.Flags().StringVar(&securityFormatFlag, "security-format", "sarif", "Security report format (sarif, json)")
+	debugCmd.Flags().StringVar(&securityOutputFlag, "security-output", "", "Security report path (default: <tx-hash>.sarif or <tx-hash>.security.json)")
+	debugCmd.Flags().StringVar(&securityArtifactFlag, "security-artifact", "", "Source file to attach SARIF results to (code scanning requires a file location)")
+}
@@ -0,0 +1,33 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/config"
+)
+
+func TestLoadSecurityEngine_MalformedConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "erst.toml")
+	if err := os.WriteFile(path, []byte("[security.rules.SEC999]\nenabled = false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	savedPaths, savedFlag := config.Paths, securityReportFlag
+	t.Cleanup(func() { config.Paths, securityReportFlag = savedPaths, savedFlag })
+	config.Paths = []string{path}
+
+	securityReportFlag = false
+	engine, err := loadSecurityEngine()
+	if err != nil || engine == nil {
+		t.Fatalf("without --security the defaults should be used, got %v", err)
+	}
+
+	securityReportFlag = true
+	if _, err := loadSecurityEngine(); err == nil {
+		t.Error("with --security a malformed configuration should fail the run")
+	}
+}
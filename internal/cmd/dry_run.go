@@ -4,11 +4,14 @@
 package cmd
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"os"
 
 	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/footprint"
+	"github.com/dotandev/hintents/internal/logger"
 	"github.com/dotandev/hintents/internal/rpc"
 	"github.com/dotandev/hintents/internal/simulator"
 	"github.com/spf13/cobra"
@@ -38,6 +41,8 @@ This command:
   2) Fetches required ledger entries from the configured Soroban RPC
   3) Replays the transaction locally via the Rust simulator
   4) Prints an estimated required fee based on the observed resource usage
+  5) Recommends the SorobanResources to declare, with --resource-margin
+     percent added to the instructions, and the calls that cost the most
 
 Example:
   erst dry-run ./tx.xdr --network testnet`,
@@ -45,6 +50,8 @@ Example:
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 		// Validate network flag
 		switch rpc.Network(dryRunNetworkFlag) {
+		case rpc.Testnet, rpc.Mainnet, rpc.Futurenet:
+			return nil
 		default:
 			return errors.WrapInvalidNetwork(dryRunNetworkFlag)
 		}
@@ -56,6 +63,7 @@ func init() {
 	dryRunCmd.Flags().StringVarP(&dryRunNetworkFlag, "network", "n", string(rpc.Mainnet), "Stellar network to use (testnet, mainnet, futurenet)")
 	dryRunCmd.Flags().StringVar(&dryRunRPCURLFlag, "rpc-url", "", "Custom Horizon RPC URL to use")
 	dryRunCmd.Flags().StringVar(&dryRunRPCTokenFlag, "rpc-token", "", "RPC authentication token (can also use ERST_RPC_TOKEN env var)")
+	dryRunCmd.Flags().IntVar(&resourceMarginFlag, "resource-margin", footprint.DefaultMarginPercent, "Percentage added to measured instructions when recommending resources")
 
 	_ = dryRunCmd.RegisterFlagCompletionFunc("network", completeNetworkFlag)
 
@@ -106,7 +114,9 @@ func runDryRun(cmd *cobra.Command, args []string) error {
 	}
 
 	// Preferred path: Soroban RPC preflight (simulateTransaction)
+	preflighted := false
 	if preflight, err := client.SimulateTransaction(ctx, envXdrB64); err == nil {
+		preflighted = true
 		fee := preflight.Result.MinResourceFee
 		cpu := preflight.Result.Cost.CpuInsns
 		mem := preflight.Result.Cost.MemBytes
@@ -121,15 +131,15 @@ func runDryRun(cmd *cobra.Command, args []string) error {
 		if cpu != 0 || mem != 0 {
 			fmt.Printf("Preflight cost: CPU=%d, MEM=%d\n", cpu, mem)
 		}
-		return nil
 	}
 
-	// Fallback: local simulator heuristic (best-effort)
+	// Local simulation: the fee estimate when preflight is unavailable, and
+	// the resource recommendation in any case.
 	keys, err := extractLedgerKeysFromEnvelope(&envelope)
 	if err != nil {
 		return errors.WrapSimulationLogicError(fmt.Sprintf("failed to extract ledger keys from envelope: %v", err))
 	}
-	ledgerEntries, err := client.GetLedgerEntries(ctx, keys)
+	ledgerEntries, err := fetchExistingLedgerEntries(ctx, client, keys)
 	if err != nil {
 		return errors.WrapRPCConnectionFailed(err)
 	}
@@ -141,28 +151,39 @@ func runDryRun(cmd *cobra.Command, args []string) error {
 
 	runner, err := simulator.NewRunner("", false)
 	if err != nil {
+		if preflighted {
+			logger.Logger.Warn("Local simulator unavailable, skipping resource tuning", "error", err)
+			return nil
+		}
 		return errors.WrapSimulatorNotFound(err.Error())
 	}
 	registerRunnerCloseHook("dry-run-simulator-runner", runner)
 	defer func() { _ = runner.Close() }()
 
-	// The current Rust simulator requires a non-empty result_meta_xdr.
-	// For dry-run we don't have it (tx not on-chain), so we use a placeholder.
+	// The transaction is not on chain, so there is no result meta to send.
 	simReq := &simulator.SimulationRequest{
-		EnvelopeXdr:   envXdrB64,
-		ResultMetaXdr: "AAAAAQ==", // placeholder base64
-		LedgerEntries: ledgerEntries,
+		EnvelopeXdr:       envXdrB64,
+		LedgerEntries:     ledgerEntries,
+		NetworkPassphrase: client.GetNetworkPassphrase(),
 	}
 
-	gas, err := simulator.EstimateGas(runner, simReq)
-	resp, err := runner.Run(ctx, simReq)
+	simResp, err := runner.Run(ctx, simReq)
+	if err == nil && !preflighted {
+		var gas *simulator.GasEstimation
+		if gas, err = simulator.ExtractGasEstimation(simResp); err == nil {
+			fmt.Printf("Estimated required fee (stroops): %d\n", gas.EstimatedFeeLowerBound)
+			fmt.Printf("Budget usage: CPU=%d, MEM=%d\n", gas.CPUCost, gas.MemoryCost)
+		}
+	}
 	if err != nil {
+		if preflighted {
+			logger.Logger.Warn("Local simulation failed, skipping resource tuning", "error", err)
+			return nil
+		}
 		return errors.WrapSimulationFailed(fmt.Errorf("gas estimation: %w", err), "")
 	}
 
-	fmt.Printf("Estimated required fee (stroops): %d\n", gas.EstimatedFeeLowerBound)
-	fmt.Printf("Budget usage: CPU=%d, MEM=%d\n", gas.CPUCost, gas.MemoryCost)
-
+	printResourceTuning(envXdrB64, simResp, ledgerEntries)
 	return nil
 }
 
@@ -179,12 +200,43 @@ func bytesTrimSpace(b []byte) []byte {
 	return b[start:end]
 }
 
+// extractLedgerKeysFromEnvelope returns the keys of the footprint declared in
+// the envelope's SorobanTransactionData, or none for classic transactions.
 func extractLedgerKeysFromEnvelope(env *xdr.TransactionEnvelope) ([]string, error) {
-	// Best-effort extraction: for Soroban invoke operations, footprint lives in SorobanTransactionDataExt
-	// which is not always present / easy to reconstruct without full parsing.
-	// For now we return an empty list, letting simulation rely on internal defaults (may reduce accuracy).
-	//
-	// TODO: Implement full footprint extraction via soroban tx data when available.
-	_ = env
-	return []string{}, nil
+	envXdr, err := xdr.MarshalBase64(env)
+	if err != nil {
+		return nil, err
+	}
+	data, err := simulator.DecodeSorobanData(envXdr)
+	if err != nil {
+		return []string{}, nil
+	}
+	keys := []string{}
+	fp := data.Resources.Footprint
+	for _, k := range append(append([]xdr.LedgerKey{}, fp.ReadOnly...), fp.ReadWrite...) {
+		keyB64, err := xdr.MarshalBase64(k)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, keyB64)
+	}
+	return keys, nil
+}
+
+// fetchExistingLedgerEntries fetches the entries of keys the network has.
+// Unlike GetLedgerEntries it does not fail on keys the transaction is about
+// to create.
+func fetchExistingLedgerEntries(ctx context.Context, client *rpc.Client, keys []string) (map[string]string, error) {
+	entries := map[string]string{}
+	if len(keys) == 0 {
+		return entries, nil
+	}
+	found, _, err := client.GetLedgerEntryTTLs(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	for k, e := range found {
+		entries[k] = e.EntryXdr
+	}
+	return entries, nil
 }
\ No newline at end of file
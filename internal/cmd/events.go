@@ -0,0 +1,262 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/events"
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/spf13/cobra"
+)
+
+var (
+	eventsContractFlags []string
+	eventsTopicFlags    []string
+	eventsStartLedger   uint32
+	eventsMaxPages      int
+	eventsPageSize      int
+	eventsSinceFlag     string
+	eventsUntilFlag     string
+	eventsFromLedger    uint32
+	eventsToLedger      uint32
+	eventsLimitFlag     int
+	eventsFormatFlag    string
+	eventsOutputFlag    string
+)
+
+var eventsCmd = &cobra.Command{
+	Use:     "events",
+	GroupID: "utility",
+	Short:   "Index and query contract events locally",
+	Long: `Pull contract events from Soroban RPC getEvents into a local SQLite index
+(~/.erst/events.db) and query them over time.
+
+Available subcommands:
+  ingest  - Fetch new events for one or more contracts
+  query   - Search indexed events by contract, topic, time or ledger range`,
+	Example: `  # Index transfer events for a token contract
+  erst events ingest --contract CDLZ... --topic transfer,*,* --network testnet
+
+  # Export everything seen in the last day as CSV
+  erst events query --contract CDLZ... --since 24h --format csv -o events.csv`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+var eventsIngestCmd = &cobra.Command{
+	Use:   "ingest",
+	Short: "Fetch contract events into the local index",
+	Long: `Fetch getEvents pages for the given contracts into the local index.
+
+Each run resumes from the cursor saved by the previous run with the same
+network, contracts and topic filters. The first run starts at --start-ledger,
+or about one day back from the latest ledger when omitted.
+
+Topic filters are comma-separated segments matched positionally. A segment is
+either "*" (any value), "**" (any remaining topics), a base64 ScVal XDR string,
+or a plain word, which is encoded as a symbol.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(eventsContractFlags) == 0 {
+			return errors.WrapCliArgumentRequired("contract")
+		}
+
+		opts := []rpc.ClientOption{rpc.WithNetwork(rpc.Network(networkFlag))}
+		if rpcURLFlag != "" {
+			opts = append(opts, rpc.WithSorobanURL(rpcURLFlag))
+		}
+		client, err := rpc.NewClient(opts...)
+		if err != nil {
+			return errors.WrapValidationError(fmt.Sprintf("failed to create client: %v", err))
+		}
+
+		store, err := events.Open()
+		if err != nil {
+			return errors.WrapValidationError(fmt.Sprintf("failed to open event index: %v", err))
+		}
+		defer store.Close()
+
+		topics := make([][]string, 0, len(eventsTopicFlags))
+		for _, t := range eventsTopicFlags {
+			topics = append(topics, splitTopicFlag(t))
+		}
+
+		res, err := events.Ingest(cmd.Context(), client, store, events.IngestOptions{
+			Network:     networkFlag,
+			ContractIDs: eventsContractFlags,
+			Topics:      topics,
+			StartLedger: eventsStartLedger,
+			PageSize:    eventsPageSize,
+			MaxPages:    eventsMaxPages,
+		})
+		if res != nil {
+			if res.Resumed {
+				fmt.Println("Resumed from saved cursor")
+			}
+			fmt.Printf("Pages fetched: %d\n", res.Pages)
+			fmt.Printf("Events fetched: %d (%d new)\n", res.Fetched, res.Inserted)
+			fmt.Printf("Latest ledger: %d\n", res.LatestLedger)
+		}
+		if err != nil {
+			return errors.WrapRPCConnectionFailed(err)
+		}
+		return nil
+	},
+}
+
+var eventsQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Search indexed contract events",
+	Long: `Search the local event index. Results are ordered by ledger.
+
+--topic takes comma-separated decoded values matched positionally against each
+event's topics; use "*" to skip a position and a trailing "**" for any
+remaining topics. Repeat --topic to keep events matching any of the filters.
+--since and --until accept an RFC 3339 timestamp or a duration relative to now
+(e.g. 24h).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now()
+		since, err := parseEventsTime(eventsSinceFlag, now)
+		if err != nil {
+			return errors.WrapValidationError(fmt.Sprintf("invalid --since: %v", err))
+		}
+		until, err := parseEventsTime(eventsUntilFlag, now)
+		if err != nil {
+			return errors.WrapValidationError(fmt.Sprintf("invalid --until: %v", err))
+		}
+
+		q := events.Query{
+			ContractIDs: eventsContractFlags,
+			Since:       since,
+			Until:       until,
+			FromLedger:  eventsFromLedger,
+			ToLedger:    eventsToLedger,
+			Limit:       eventsLimitFlag,
+		}
+		if cmd.Flags().Changed("network") {
+			q.Network = networkFlag
+		}
+		for _, t := range eventsTopicFlags {
+			q.Topics = append(q.Topics, splitTopicFlag(t))
+		}
+
+		store, err := events.Open()
+		if err != nil {
+			return errors.WrapValidationError(fmt.Sprintf("failed to open event index: %v", err))
+		}
+		defer store.Close()
+
+		found, err := store.Find(q)
+		if err != nil {
+			return errors.WrapValidationError(err.Error())
+		}
+
+		var out io.Writer = os.Stdout
+		if eventsOutputFlag != "" {
+			f, err := os.Create(eventsOutputFlag)
+			if err != nil {
+				return errors.WrapValidationError(fmt.Sprintf("failed to create output file: %v", err))
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if strings.EqualFold(eventsFormatFlag, "table") {
+			printEventsTable(out, found)
+		} else if err := events.Write(out, eventsFormatFlag, found); err != nil {
+			return errors.WrapValidationError(err.Error())
+		}
+
+		if eventsOutputFlag != "" {
+			fmt.Printf("Exported %d events to %s\n", len(found), eventsOutputFlag)
+		}
+		return nil
+	},
+}
+
+func splitTopicFlag(s string) []string {
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// parseEventsTime accepts RFC 3339 or a Go duration counted back from now.
+func parseEventsTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither RFC 3339 nor a duration", s)
+	}
+	return now.Add(-d), nil
+}
+
+func printEventsTable(w io.Writer, evts []events.Event) {
+	if len(evts) == 0 {
+		fmt.Fprintln(w, "No matching events in the local index.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LEDGER\tCLOSED AT\tCONTRACT\tTX\tTOPICS\tDATA")
+	for _, e := range evts {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			e.Ledger,
+			e.LedgerClosedAt.Format(time.RFC3339),
+			abbreviate(e.ContractID, 12),
+			abbreviate(e.TxHash, 12),
+			strings.Join(e.Topics, ", "),
+			abbreviate(e.Data, 40),
+		)
+	}
+	_ = tw.Flush()
+	fmt.Fprintf(w, "\n%d event(s)\n", len(evts))
+}
+
+func abbreviate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+func init() {
+	eventsCmd.PersistentFlags().StringSliceVar(&eventsContractFlags, "contract", nil, "Contract ID (repeatable)")
+	eventsCmd.PersistentFlags().StringArrayVar(&eventsTopicFlags, "topic", nil, "Comma-separated topic filter, e.g. transfer,*")
+	eventsCmd.PersistentFlags().StringVarP(&networkFlag, "network", "n", string(rpc.Mainnet), "Stellar network to use")
+
+	eventsIngestCmd.Flags().StringVar(&rpcURLFlag, "rpc-url", "", "Custom Soroban RPC URL")
+	eventsIngestCmd.Flags().Uint32Var(&eventsStartLedger, "start-ledger", 0, "Ledger to start from when no cursor is saved")
+	eventsIngestCmd.Flags().IntVar(&eventsPageSize, "page-size", events.DefaultPageSize, "Events requested per getEvents page")
+	eventsIngestCmd.Flags().IntVar(&eventsMaxPages, "max-pages", 0, "Stop after this many pages (0 = until caught up)")
+
+	eventsQueryCmd.Flags().StringVar(&eventsSinceFlag, "since", "", "Only events closed at or after this time (RFC 3339 or duration)")
+	eventsQueryCmd.Flags().StringVar(&eventsUntilFlag, "until", "", "Only events closed at or before this time (RFC 3339 or duration)")
+	eventsQueryCmd.Flags().Uint32Var(&eventsFromLedger, "from-ledger", 0, "Only events at or after this ledger")
+	eventsQueryCmd.Flags().Uint32Var(&eventsToLedger, "to-ledger", 0, "Only events at or before this ledger")
+	eventsQueryCmd.Flags().IntVar(&eventsLimitFlag, "limit", 0, "Maximum number of events to return (0 = no limit)")
+	eventsQueryCmd.Flags().StringVar(&eventsFormatFlag, "format", "table", "Output format: table, json or csv")
+	eventsQueryCmd.Flags().StringVarP(&eventsOutputFlag, "output", "o", "", "Write results to a file instead of stdout")
+
+	_ = eventsCmd.RegisterFlagCompletionFunc("network", completeNetworkFlag)
+
+	eventsCmd.AddCommand(eventsIngestCmd)
+	eventsCmd.AddCommand(eventsQueryCmd)
+	rootCmd.AddCommand(eventsCmd)
+}
@@ -0,0 +1,41 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseEventsTime(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseEventsTime("", now)
+	if err != nil || !got.IsZero() {
+		t.Errorf("empty input: got %v, %v", got, err)
+	}
+
+	got, err = parseEventsTime("2025-05-01T00:00:00Z", now)
+	if err != nil || !got.Equal(time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("RFC 3339 input: got %v, %v", got, err)
+	}
+
+	got, err = parseEventsTime("24h", now)
+	if err != nil || !got.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("duration input: got %v, %v", got, err)
+	}
+
+	if _, err := parseEventsTime("yesterday", now); err == nil {
+		t.Error("expected error for unparseable input")
+	}
+}
+
+func TestSplitTopicFlag(t *testing.T) {
+	got := splitTopicFlag("transfer, *,GABC")
+	want := []string{"transfer", "*", "GABC"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitTopicFlag = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/decoder"
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/flamegraph"
+	"github.com/dotandev/hintents/internal/session"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/dotandev/hintents/internal/trace"
+	"github.com/dotandev/hintents/internal/visualizer"
+	"github.com/spf13/cobra"
+)
+
+const sessionSourcePrefix = "session:"
+
+var (
+	flameWeightFlag string
+	flameFormatFlag string
+	flameOutputFlag string
+	flameDiffFlag   string
+	flameIcicleFlag bool
+	flameSearchFlag string
+	flameTitleFlag  string
+	flameThemeFlag  string
+)
+
+var flamegraphCmd = &cobra.Command{
+	Use:     "flamegraph <trace-file | session:ID>",
+	GroupID: "utility",
+	Short:   "Render a trace as an interactive flamegraph or icicle chart",
+	Long: `Render an execution trace as an interactive SVG flamegraph or a standalone
+HTML page. Frames are sized by CPU instructions or memory bytes; click a frame
+to zoom into it and use Search to highlight functions by regex.
+
+The source is a trace file (as used by "erst trace" and "erst profile") or a
+saved session written as session:<id>. Sessions carry the call structure but no
+per-call budget, so they are sized by call count.
+
+With --diff, the chart is a differential flamegraph: frames are sized by the
+source and colored by how much each stack grew or shrank relative to the
+baseline.
+
+Example:
+  erst flamegraph trace.json -o trace.svg
+  erst flamegraph trace.json --weight memory --icicle --format html -o trace.html
+  erst flamegraph session:abc123-1700000000 --diff session:abc123-1690000000 -o diff.svg`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		weight, err := flamegraph.ParseWeight(flameWeightFlag)
+		if err != nil {
+			return errors.WrapValidationError(err.Error())
+		}
+		format := strings.ToLower(flameFormatFlag)
+		if format != "svg" && format != "html" {
+			return errors.WrapValidationError(fmt.Sprintf("unsupported format %q (use svg or html)", flameFormatFlag))
+		}
+		theme := visualizer.DetectTheme()
+		if flameThemeFlag != "" {
+			theme = visualizer.Theme(flameThemeFlag)
+		}
+
+		ctx := cmd.Context()
+		root, weight, err := loadFlamegraphSource(ctx, args[0], weight)
+		if err != nil {
+			return err
+		}
+		if flameDiffFlag != "" {
+			base, baseWeight, err := loadFlamegraphSource(ctx, flameDiffFlag, weight)
+			if err != nil {
+				return err
+			}
+			if baseWeight != weight {
+				return errors.WrapValidationError(fmt.Sprintf("cannot compare a %s-weighted source against a %s-weighted baseline", weight, baseWeight))
+			}
+			root = flamegraph.Diff(base, root)
+		}
+		if root.Total == 0 {
+			return errors.WrapValidationError(fmt.Sprintf("trace has no %s data to chart", weight))
+		}
+
+		title := flameTitleFlag
+		if title == "" {
+			title = "Flame Graph: " + args[0]
+			if flameDiffFlag != "" {
+				title = fmt.Sprintf("Differential Flame Graph: %s vs %s", args[0], flameDiffFlag)
+			}
+		}
+		opts := flamegraph.Options{
+			Title:  title,
+			Weight: weight,
+			Icicle: flameIcicleFlag,
+			Theme:  theme,
+			Search: flameSearchFlag,
+		}
+
+		var out string
+		if format == "html" {
+			out = flamegraph.RenderHTML(root, opts)
+		} else {
+			out = flamegraph.RenderSVG(root, opts)
+		}
+
+		outPath := flameOutputFlag
+		if outPath == "" {
+			outPath = "flamegraph." + format
+		}
+		if err := os.WriteFile(outPath, []byte(out), 0644); err != nil {
+			return errors.WrapValidationError(fmt.Sprintf("failed to write %s: %v", outPath, err))
+		}
+		fmt.Printf("%s Flamegraph saved: %s\n", visualizer.Success(), outPath)
+		return nil
+	},
+}
+
+// loadFlamegraphSource builds the frame tree for a trace file or saved
+// session. Sources without the requested budget data fall back to call
+// counts, and the weight actually used is returned.
+func loadFlamegraphSource(ctx context.Context, source string, weight flamegraph.Weight) (*flamegraph.Frame, flamegraph.Weight, error) {
+	if id, ok := strings.CutPrefix(source, sessionSourcePrefix); ok {
+		tree, err := loadSessionCallTree(ctx, id)
+		if err != nil {
+			return nil, "", err
+		}
+		if weight != flamegraph.WeightCalls && !flamegraph.HasCost(tree, weight) {
+			fmt.Fprintf(os.Stderr, "%s has no per-call %s data; sizing frames by call count\n", source, weight)
+			weight = flamegraph.WeightCalls
+		}
+		return flamegraph.FromTraceNode(tree, weight), weight, nil
+	}
+
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, "", errors.WrapValidationError(fmt.Sprintf("failed to read trace file: %v", err))
+	}
+	execTrace, err := trace.FromJSON(data)
+	if err != nil {
+		return nil, "", errors.WrapUnmarshalFailed(err, "trace")
+	}
+	root := flamegraph.FromExecutionTrace(execTrace, weight)
+	if root.Total == 0 && weight != flamegraph.WeightCalls {
+		fmt.Fprintf(os.Stderr, "%s has no %s data; sizing frames by call count\n", source, weight)
+		weight = flamegraph.WeightCalls
+		root = flamegraph.FromExecutionTrace(execTrace, weight)
+	}
+	return root, weight, nil
+}
+
+func loadSessionCallTree(ctx context.Context, id string) (*trace.TraceNode, error) {
+	store, err := session.NewStore()
+	if err != nil {
+		return nil, errors.WrapValidationError(fmt.Sprintf("failed to open session store: %v", err))
+	}
+	defer store.Close()
+
+	data, err := store.Load(ctx, id)
+	if err != nil {
+		return nil, errors.WrapValidationError(fmt.Sprintf("failed to load session %s: %v", id, err))
+	}
+	resp, err := data.ToSimulationResponse()
+	if err != nil {
+		return nil, errors.WrapValidationError(err.Error())
+	}
+	if len(resp.InvocationBudgets) == 0 && len(resp.DiagnosticEventsXdr) == 0 {
+		return nil, errors.WrapValidationError(fmt.Sprintf("session %s has no diagnostic events to build a call tree from", id))
+	}
+	tree, err := callTreeFromResponse(resp)
+	if err != nil {
+		return nil, errors.WrapValidationError(fmt.Sprintf("failed to decode session events: %v", err))
+	}
+	return tree, nil
+}
+
+// callTreeFromResponse returns the call tree of a run, from its budget
+// snapshots when the simulator reported them so every call carries its cost,
+// and from its diagnostic events otherwise.
+func callTreeFromResponse(resp *simulator.SimulationResponse) (*trace.TraceNode, error) {
+	if len(resp.InvocationBudgets) == 0 {
+		return callTreeFromEvents(resp.DiagnosticEventsXdr)
+	}
+	budgets := make([]trace.InvocationBudget, len(resp.InvocationBudgets))
+	for i, b := range resp.InvocationBudgets {
+		budgets[i] = trace.InvocationBudget(b)
+	}
+	return trace.BuildBudgetTree(budgets), nil
+}
+
+// callTreeFromEvents rebuilds the call tree from the session's base64
+// DiagnosticEvents, using the same fn_call/fn_return pairing as the decoder.
+func callTreeFromEvents(eventsXdr []string) (*trace.TraceNode, error) {
+	calls, err := decoder.DecodeEvents(eventsXdr)
+	if err != nil {
+		return nil, err
+	}
+	root := trace.NewTraceNode("root", "transaction")
+	next := 0
+	var add func(parent *trace.TraceNode, call *decoder.CallNode)
+	add = func(parent *trace.TraceNode, call *decoder.CallNode) {
+		node := trace.NewTraceNode(fmt.Sprintf("call-%d", next), "contract_call")
+		next++
+		node.ContractID = call.ContractID
+		node.Function = call.Function
+		parent.AddChild(node)
+		for _, sub := range call.SubCalls {
+			add(node, sub)
+		}
+	}
+	for _, call := range calls.SubCalls {
+		add(root, call)
+	}
+	return root, nil
+}
+
+func init() {
+	flamegraphCmd.Flags().StringVar(&flameWeightFlag, "weight", "cpu", "Size frames by: cpu, memory or calls")
+	flamegraphCmd.Flags().StringVar(&flameFormatFlag, "format", "svg", "Output format: svg or html")
+	flamegraphCmd.Flags().StringVarP(&flameOutputFlag, "output", "o", "", "Output file (default flamegraph.<format>)")
+	flamegraphCmd.Flags().StringVar(&flameDiffFlag, "diff", "", "Baseline trace file or session:ID for a differential flamegraph")
+	flamegraphCmd.Flags().BoolVar(&flameIcicleFlag, "icicle", false, "Draw an icicle chart (root at the top)")
+	flamegraphCmd.Flags().StringVar(&flameSearchFlag, "search", "", "Highlight frames matching this regex")
+	flamegraphCmd.Flags().StringVar(&flameTitleFlag, "title", "", "Chart title")
+	flamegraphCmd.Flags().StringVar(&flameThemeFlag, "theme", "", "Color theme (default, deuteranopia, protanopia, tritanopia, high-contrast)")
+
+	_ = flamegraphCmd.RegisterFlagCompletionFunc("theme", completeThemeFlag)
+
+	rootCmd.AddCommand(flamegraphCmd)
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallTreeFromEvents(t *testing.T) {
+	// Shaped like erst-sim's diagnostic_events_xdr: fn_call carries the
+	// callee as bytes in its topics, fn_return comes from the callee.
+	var ca, cb xdr.ContractId
+	ca[0], cb[0] = 0xca, 0xcb
+	sym := func(s string) xdr.ScVal {
+		v := xdr.ScSymbol(s)
+		return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &v}
+	}
+	id := func(c xdr.ContractId) xdr.ScVal {
+		b := xdr.ScBytes(c[:])
+		return xdr.ScVal{Type: xdr.ScValTypeScvBytes, Bytes: &b}
+	}
+	encode := func(contract *xdr.ContractId, topics ...xdr.ScVal) string {
+		diag := xdr.DiagnosticEvent{Event: xdr.ContractEvent{
+			ContractId: contract,
+			Type:       xdr.ContractEventTypeDiagnostic,
+			Body:       xdr.ContractEventBody{V0: &xdr.ContractEventV0{Topics: topics, Data: xdr.ScVal{Type: xdr.ScValTypeScvVoid}}},
+		}}
+		raw, err := diag.MarshalBinary()
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+
+	root, err := callTreeFromEvents([]string{
+		encode(nil, sym("fn_call"), id(ca), sym("swap")),
+		encode(&ca, sym("fn_call"), id(cb), sym("transfer")),
+		encode(&cb, sym("transfer"), sym("from"), sym("to")),
+		encode(&cb, sym("fn_return"), sym("transfer")),
+		encode(&ca, sym("fn_call"), id(cb), sym("balance")),
+		encode(&cb, sym("fn_return"), sym("balance")),
+		encode(&ca, sym("fn_return"), sym("swap")),
+	})
+	require.NoError(t, err)
+	require.Len(t, root.Children, 1)
+	swap := root.Children[0]
+	assert.Equal(t, hex.EncodeToString(ca[:]), swap.ContractID)
+	assert.Equal(t, "swap", swap.Function)
+	require.Len(t, swap.Children, 2)
+	assert.Equal(t, "transfer", swap.Children[0].Function)
+	assert.Equal(t, hex.EncodeToString(cb[:]), swap.Children[0].ContractID)
+	assert.Equal(t, "balance", swap.Children[1].Function)
+}
+
+func TestCallTreeFromEvents_InvalidXDR(t *testing.T) {
+	_, err := callTreeFromEvents([]string{"not-base64!"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/footprint"
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/dotandev/hintents/internal/visualizer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	footprintFormatFlag string
+	footprintMarginFlag int
+)
+
+var footprintCmd = &cobra.Command{
+	Use:     "footprint <tx.xdr>",
+	GroupID: "testing",
+	Short:   "Infer the footprint a transaction needs and correct its resources",
+	Long: `Run a local transaction envelope in the simulator with a recording footprint
+and compare the ledger keys it touches with the footprint it declares.
+
+Keys the transaction needs but does not declare, keys declared but never used
+and keys declared with the wrong access are reported. Ledger entries are
+fetched from the network as the run reaches them. The corrected
+SorobanTransactionData is printed as base64 XDR, with instructions sized from
+the run's budget plus --margin percent and read and write bytes sized from the
+entries.
+
+Examples:
+  erst footprint ./tx.xdr --network testnet
+  erst footprint ./tx.xdr --format json --margin 20`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if footprintFormatFlag != "text" && footprintFormatFlag != "json" {
+			return errors.WrapValidationError(fmt.Sprintf("unsupported format: %s (use: text, json)", footprintFormatFlag))
+		}
+		if footprintMarginFlag < 0 {
+			return errors.WrapValidationError("--margin must not be negative")
+		}
+		return nil
+	},
+	RunE: runFootprint,
+}
+
+func runFootprint(cmd *cobra.Command, args []string) error {
+	b, err := os.ReadFile(args[0])
+	if err != nil {
+		return errors.WrapValidationError(fmt.Sprintf("failed to read tx file: %v", err))
+	}
+	envXdr := string(bytesTrimSpace(b))
+	if envXdr == "" {
+		return errors.WrapValidationError("tx file is empty")
+	}
+	if _, err := simulator.DecodeSorobanData(envXdr); err != nil {
+		return errors.WrapValidationError(err.Error())
+	}
+
+	opts := []rpc.ClientOption{rpc.WithNetwork(rpc.Network(networkFlag))}
+	if rpcURLFlag != "" {
+		opts = append(opts, rpc.WithHorizonURL(rpcURLFlag))
+	}
+	client, err := rpc.NewClient(opts...)
+	if err != nil {
+		return errors.WrapValidationError(fmt.Sprintf("failed to create client: %v", err))
+	}
+	registerCacheFlushHook()
+
+	runner, err := simulator.NewRunner("", false)
+	if err != nil {
+		return errors.WrapSimulatorNotFound(err.Error())
+	}
+	registerRunnerCloseHook("footprint-simulator-runner", runner)
+	defer func() { _ = runner.Close() }()
+
+	margin := footprintMarginFlag
+	if margin == 0 {
+		margin = -1
+	}
+	report, err := footprint.Infer(cmd.Context(), runner, client, simulator.SimulationRequest{
+		EnvelopeXdr:       envXdr,
+		NetworkPassphrase: client.GetNetworkPassphrase(),
+	}, footprint.Options{MarginPercent: margin})
+	if err != nil {
+		return errors.WrapSimulationFailed(err, "")
+	}
+
+	if footprintFormatFlag == "json" {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return errors.WrapMarshalFailed(err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	printFootprintReport(report)
+	return nil
+}
+
+func printFootprintReport(r *footprint.Report) {
+	if r.SimulationError != "" {
+		fmt.Printf("%s The recording run failed: %s\n", visualizer.Warning(), r.SimulationError)
+		fmt.Println("  The footprint below only covers what ran before the failure.")
+	}
+	if len(r.Issues) == 0 {
+		fmt.Printf("%s The declared footprint matches the %d keys the transaction touches\n",
+			visualizer.Success(), r.Required.ReadEntries)
+	} else {
+		fmt.Printf("Footprint issues (%d):\n", len(r.Issues))
+		for _, issue := range r.Issues {
+			fmt.Printf("  %-16s %s: %s\n", issue.Kind, issue.Key, footprintIssueDetail(issue))
+		}
+	}
+
+	fmt.Printf("\nResources (declared -> required):\n")
+	fmt.Printf("  instructions     %d -> %d\n", r.Declared.Instructions, r.Required.Instructions)
+	fmt.Printf("  disk read bytes  %d -> %d\n", r.Declared.DiskReadBytes, r.Required.DiskReadBytes)
+	fmt.Printf("  write bytes      %d -> %d\n", r.Declared.WriteBytes, r.Required.WriteBytes)
+	fmt.Printf("  read entries     %d -> %d\n", r.Declared.ReadEntries, r.Required.ReadEntries)
+	fmt.Printf("  write entries    %d -> %d\n", r.Declared.WriteEntries, r.Required.WriteEntries)
+	fmt.Printf("  resource fee     %d -> %d stroops\n", r.Declared.ResourceFee, r.Required.ResourceFee)
+
+	fmt.Printf("\nCorrected SorobanTransactionData:\n%s\n", r.SorobanData)
+}
+
+func footprintIssueDetail(issue footprint.Issue) string {
+	switch issue.Kind {
+	case footprint.IssueMissing:
+		if issue.ReadWrite {
+			return "written but not declared"
+		}
+		return "read but not declared"
+	case footprint.IssueNeedsReadWrite:
+		return "declared read-only but written"
+	case footprint.IssueOnlyRead:
+		return "declared read-write but only read"
+	}
+	return "declared but never used"
+}
+
+func init() {
+	footprintCmd.Flags().StringVarP(&networkFlag, "network", "n", string(rpc.Mainnet), "Stellar network to use")
+	footprintCmd.Flags().StringVar(&rpcURLFlag, "rpc-url", "", "Custom Horizon RPC URL")
+	footprintCmd.Flags().StringVar(&footprintFormatFlag, "format", "text", "Output format (text, json)")
+	footprintCmd.Flags().IntVar(&footprintMarginFlag, "margin", footprint.DefaultMarginPercent, "Percentage added to the measured instructions")
+
+	_ = footprintCmd.RegisterFlagCompletionFunc("network", completeNetworkFlag)
+
+	rootCmd.AddCommand(footprintCmd)
+}
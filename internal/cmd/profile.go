@@ -6,7 +6,9 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/dotandev/hintents/internal/dwarf"
 	"github.com/dotandev/hintents/internal/profile"
 	"github.com/dotandev/hintents/internal/trace"
 	"github.com/spf13/cobra"
@@ -15,19 +17,26 @@ import (
 var (
 	profileTraceFile string
 	profileOutput    string
+	profileWasmFlags []string
 )
 
 var profileCmd = &cobra.Command{
 	Use:     "profile [trace-file]",
 	GroupID: "utility",
 	Short:   "Export trace as pprof profile for gas-to-function mapping",
-	Long: `Synthesize trace events into a pprof-compliant profile that maps gas
-consumption to functions. The output can be viewed with go tool pprof.
+	Long: `Synthesize trace events into a pprof-compliant profile. CPU instructions
+and memory bytes are recorded as separate sample types and attributed to the
+call stack at each step, so cross-contract costs show up in pprof's graph and
+peek views. Each contract appears as its own mapping.
+
+Pass --wasm CONTRACT_ID=path to resolve source files and lines from the
+contract's DWARF debug info.
 
 Example:
   erst profile execution.json -o gas.pb.gz
   erst profile --file debug_trace.json -o gas.pb.gz
-  go tool pprof gas.pb.gz`,
+  erst profile trace.json --wasm CDLZ...=target/wasm32-unknown-unknown/release/token.wasm
+  go tool pprof -sample_index=memory gas.pb.gz`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var filename string
@@ -53,6 +62,23 @@ Example:
 			return fmt.Errorf("failed to parse trace file: %w", err)
 		}
 
+		var opts []profile.Option
+		for _, spec := range profileWasmFlags {
+			contractID, path, ok := strings.Cut(spec, "=")
+			if !ok || contractID == "" || path == "" {
+				return fmt.Errorf("invalid --wasm value %q (expected CONTRACT_ID=path)", spec)
+			}
+			wasmBytes, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read WASM file: %w", err)
+			}
+			parser, err := dwarf.NewParser(wasmBytes)
+			if err != nil {
+				return fmt.Errorf("failed to load debug info from %s: %w", path, err)
+			}
+			opts = append(opts, profile.WithDebugInfo(contractID, parser))
+		}
+
 		outPath := profileOutput
 		if outPath == "" {
 			outPath = "profile.pb.gz"
@@ -64,7 +90,7 @@ Example:
 		}
 		defer out.Close()
 
-		if err := profile.WritePprof(execTrace, out); err != nil {
+		if err := profile.WritePprof(execTrace, out, opts...); err != nil {
 			return fmt.Errorf("failed to write pprof profile: %w", err)
 		}
 
@@ -77,5 +103,6 @@ Example:
 func init() {
 	profileCmd.Flags().StringVarP(&profileTraceFile, "file", "f", "", "Trace file to load")
 	profileCmd.Flags().StringVarP(&profileOutput, "output", "o", "profile.pb.gz", "Output pprof file path")
+	profileCmd.Flags().StringArrayVar(&profileWasmFlags, "wasm", nil, "CONTRACT_ID=path of a WASM with DWARF debug info (repeatable)")
 	rootCmd.AddCommand(profileCmd)
 }
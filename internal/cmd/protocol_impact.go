@@ -0,0 +1,222 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/events"
+	"github.com/dotandev/hintents/internal/logger"
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/spf13/cobra"
+)
+
+var (
+	impactFromFlag       uint32
+	impactToFlag         uint32
+	impactContractFlag   string
+	impactSampleFlag     int
+	impactTxFlags        []string
+	impactSorobanURLFlag string
+	impactFormatFlag     string
+	impactOutputFlag     string
+)
+
+var protocolImpactCmd = &cobra.Command{
+	Use:     "protocol-impact",
+	GroupID: "testing",
+	Short:   "Compare a batch of transactions across two protocol versions",
+	Long: `Replay a sample of recent transactions under two protocol configurations
+and report what changes: execution status, CPU and memory usage, and emitted
+events.
+
+Transactions are sampled from the contract's recent events (getEvents), so
+only contracts that emit events can be sampled. Pass --tx to replay specific
+transactions instead.
+
+erst-sim runs each replay with the requested ledger protocol version, so
+behavior the host gates on the protocol version is compared. Everything else
+comes from the host erst-sim was built with and is the same in both runs: the
+cost model, the network's resource limits and the supported host functions
+are not emulated. A version newer than that host, or older than it supports,
+fails the replay and is reported as an error.`,
+	Example: `  erst protocol-impact --from 22 --to 23 --contract CDLZ... --sample 50
+  erst protocol-impact --from 21 --to 22 --tx 5c0a... --tx 9f1b... --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if impactFromFlag == 0 {
+			return errors.WrapCliArgumentRequired("from")
+		}
+		if impactToFlag == 0 {
+			return errors.WrapCliArgumentRequired("to")
+		}
+		for _, v := range []uint32{impactFromFlag, impactToFlag} {
+			if err := simulator.Validate(v); err != nil {
+				return err
+			}
+		}
+		if impactContractFlag == "" && len(impactTxFlags) == 0 {
+			return errors.WrapCliArgumentRequired("contract")
+		}
+		switch strings.ToLower(impactFormatFlag) {
+		case "table", "json":
+		default:
+			return errors.WrapValidationError(fmt.Sprintf("unsupported format %q (use table or json)", impactFormatFlag))
+		}
+
+		opts := []rpc.ClientOption{rpc.WithNetwork(rpc.Network(networkFlag))}
+		if rpcURLFlag != "" {
+			opts = append(opts, rpc.WithHorizonURL(rpcURLFlag))
+		}
+		if impactSorobanURLFlag != "" {
+			opts = append(opts, rpc.WithSorobanURL(impactSorobanURLFlag))
+		}
+		client, err := rpc.NewClient(opts...)
+		if err != nil {
+			return errors.WrapValidationError(fmt.Sprintf("failed to create client: %v", err))
+		}
+		registerCacheFlushHook()
+
+		ctx := cmd.Context()
+		hashes := impactTxFlags
+		if len(hashes) == 0 {
+			fmt.Fprintf(os.Stderr, "Sampling up to %d recent transactions for %s...\n", impactSampleFlag, impactContractFlag)
+			hashes, err = events.SampleTransactions(ctx, client, events.SampleOptions{
+				ContractID: impactContractFlag,
+				Count:      impactSampleFlag,
+			})
+			if err != nil {
+				return errors.WrapRPCConnectionFailed(err)
+			}
+			if len(hashes) == 0 {
+				return errors.WrapValidationError("no recent transactions found for contract; pass --tx to choose transactions explicitly")
+			}
+		}
+
+		cases := make([]simulator.ProtocolImpactCase, 0, len(hashes))
+		for _, hash := range hashes {
+			req, err := buildReplayRequest(ctx, client, hash)
+			if err != nil {
+				logger.Logger.Warn("Skipping transaction", "tx", hash, "error", err)
+				fmt.Fprintf(os.Stderr, "skipping %s: %v\n", hash, err)
+				continue
+			}
+			cases = append(cases, simulator.ProtocolImpactCase{TxHash: hash, Request: req})
+		}
+
+		runner, err := simulator.NewRunner("", false)
+		if err != nil {
+			return errors.WrapSimulatorNotFound(err.Error())
+		}
+		registerRunnerCloseHook("protocol-impact-runner", runner)
+		defer func() { _ = runner.Close() }()
+
+		fmt.Fprintf(os.Stderr, "Replaying %d transaction(s) under protocol %d and %d...\n", len(cases), impactFromFlag, impactToFlag)
+		report, err := simulator.AnalyzeProtocolImpact(ctx, runner, impactFromFlag, impactToFlag, cases)
+		if err != nil {
+			return errors.WrapSimulationLogicError(err.Error())
+		}
+
+		var out io.Writer = os.Stdout
+		if impactOutputFlag != "" {
+			f, err := os.Create(impactOutputFlag)
+			if err != nil {
+				return errors.WrapValidationError(fmt.Sprintf("failed to create output file: %v", err))
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if strings.EqualFold(impactFormatFlag, "json") {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return errors.WrapMarshalFailed(err)
+			}
+		} else {
+			printProtocolImpactTable(out, report)
+		}
+		if impactOutputFlag != "" {
+			fmt.Printf("Wrote protocol impact report to %s\n", impactOutputFlag)
+		}
+		return nil
+	},
+}
+
+// buildReplayRequest fetches a transaction and the ledger state it touched.
+func buildReplayRequest(ctx context.Context, client *rpc.Client, hash string) (*simulator.SimulationRequest, error) {
+	resp, err := client.GetTransaction(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := rpc.ExtractLedgerEntriesFromMeta(resp.ResultMetaXdr)
+	if err != nil {
+		keys, keyErr := extractLedgerKeys(resp.ResultMetaXdr)
+		if keyErr != nil {
+			return nil, keyErr
+		}
+		if entries, err = client.GetLedgerEntries(ctx, keys); err != nil {
+			return nil, err
+		}
+	}
+	return &simulator.SimulationRequest{
+		EnvelopeXdr:   resp.EnvelopeXdr,
+		ResultMetaXdr: resp.ResultMetaXdr,
+		LedgerEntries: entries,
+	}, nil
+}
+
+func printProtocolImpactTable(w io.Writer, report *simulator.ProtocolImpactReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "TX\tSTATUS (%d -> %d)\tCPU DELTA\tMEM DELTA\tEVENTS\n", report.FromVersion, report.ToVersion)
+	for _, r := range report.Results {
+		if r.Error != "" {
+			fmt.Fprintf(tw, "%s\terror: %s\t-\t-\t-\n", abbreviate(r.TxHash, 12), abbreviate(r.Error, 40))
+			continue
+		}
+		status := r.From.Status
+		if r.StatusChanged {
+			status += " -> " + r.To.Status
+		}
+		evts := "same"
+		if r.EventsChanged {
+			evts = fmt.Sprintf("changed (%d -> %d)", len(r.From.Events), len(r.To.Events))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%+d\t%+d\t%s\n", abbreviate(r.TxHash, 12), status, r.CPUDelta, r.MemoryDelta, evts)
+	}
+	_ = tw.Flush()
+
+	s := report.Summary
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Transactions replayed: %d (%d error(s))\n", s.Total, s.Errors)
+	fmt.Fprintf(w, "Status changed:        %d\n", s.StatusChanged)
+	fmt.Fprintf(w, "Events changed:        %d\n", s.EventsChanged)
+	fmt.Fprintf(w, "CPU delta:             %+d (%+.2f%%)\n", s.TotalCPUDelta, s.CPUDeltaPercent)
+	fmt.Fprintf(w, "Memory delta:          %+d (%+.2f%%)\n", s.TotalMemoryDelta, s.MemDeltaPercent)
+}
+
+func init() {
+	protocolImpactCmd.Flags().Uint32Var(&impactFromFlag, "from", 0, "Baseline protocol version")
+	protocolImpactCmd.Flags().Uint32Var(&impactToFlag, "to", 0, "Protocol version to compare against")
+	protocolImpactCmd.Flags().StringVar(&impactContractFlag, "contract", "", "Contract whose recent transactions are sampled")
+	protocolImpactCmd.Flags().IntVar(&impactSampleFlag, "sample", 20, "Number of recent transactions to replay")
+	protocolImpactCmd.Flags().StringArrayVar(&impactTxFlags, "tx", nil, "Replay this transaction instead of sampling (repeatable)")
+	protocolImpactCmd.Flags().StringVarP(&networkFlag, "network", "n", string(rpc.Mainnet), "Stellar network to use")
+	protocolImpactCmd.Flags().StringVar(&rpcURLFlag, "rpc-url", "", "Custom Horizon RPC URL")
+	protocolImpactCmd.Flags().StringVar(&impactSorobanURLFlag, "soroban-url", "", "Custom Soroban RPC URL used for sampling")
+	protocolImpactCmd.Flags().StringVar(&impactFormatFlag, "format", "table", "Output format: table or json")
+	protocolImpactCmd.Flags().StringVarP(&impactOutputFlag, "output", "o", "", "Write the report to a file instead of stdout")
+
+	_ = protocolImpactCmd.RegisterFlagCompletionFunc("network", completeNetworkFlag)
+
+	rootCmd.AddCommand(protocolImpactCmd)
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/simulator"
+)
+
+func TestPrintProtocolImpactTable(t *testing.T) {
+	report := &simulator.ProtocolImpactReport{
+		FromVersion: 22,
+		ToVersion:   23,
+		Results: []simulator.ProtocolImpactResult{
+			{
+				TxHash:        "aaaaaaaaaaaaaaaaaaaa",
+				From:          simulator.ProtocolRun{Status: "error"},
+				To:            simulator.ProtocolRun{Status: "success"},
+				StatusChanged: true,
+				CPUDelta:      -120,
+			},
+			{TxHash: "bbbb", Error: "simulator crashed"},
+		},
+		Summary: simulator.ProtocolImpactSummary{Total: 2, Errors: 1, StatusChanged: 1, TotalCPUDelta: -120},
+	}
+
+	var buf bytes.Buffer
+	printProtocolImpactTable(&buf, report)
+	out := buf.String()
+
+	for _, want := range []string{"STATUS (22 -> 23)", "error -> success", "-120", "error: simulator crashed", "Status changed:        1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -16,6 +16,7 @@ var (
 	regressionProtocolVersion uint32
 	regressionStartSeq        uint32
 	regressionMaxWorkers      int
+	regressionVerifyFlag      bool
 )
 
 var regressionTestCmd = &cobra.Command{
@@ -30,6 +31,8 @@ simulates them in parallel, verifying that the simulator produces the same
 traps and events as the original network execution.
 
 The tests help ensure that protocol changes don't introduce regressions.
+With --verify, each replay is checked against the on-chain return value,
+events, ledger changes and resources (see verify-replay) instead of status only.
 
 Example:
   erst regression-test --count 100
@@ -83,6 +86,9 @@ func runRegressionTest(cmd *cobra.Command, args []string) error {
 	// Create regression harness
 	harness := simulator.NewRegressionHarness(runner, client, regressionMaxWorkers)
 	harness.Verbose = verbose
+	if regressionVerifyFlag {
+		harness.Verifier = verifyReplayAgainstChain
+	}
 
 	// Run the regression tests
 	ctx := cmd.Context()
@@ -141,6 +147,13 @@ func init() {
 		"Number of parallel workers for testing",
 	)
 
+	regressionTestCmd.Flags().BoolVar(
+		&regressionVerifyFlag,
+		"verify",
+		false,
+		"Decide pass/fail by full comparison against the on-chain result",
+	)
+
 	regressionTestCmd.Flags().Uint32Var(
 		&regressionProtocolVersion,
 		"protocol-version",
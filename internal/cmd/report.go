@@ -6,19 +6,24 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"os"
 	"time"
 
 	"github.com/dotandev/hintents/internal/errors"
 	"github.com/dotandev/hintents/internal/report"
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/dotandev/hintents/internal/session"
+	"github.com/dotandev/hintents/internal/tokenflow"
 	"github.com/dotandev/hintents/internal/trace"
 	"github.com/spf13/cobra"
 )
 
 var (
-	reportFormat string
-	reportOutput string
-	reportFile   string
+	reportFormat  string
+	reportOutput  string
+	reportFile    string
+	reportSession string
 )
 
 var reportCmd = &cobra.Command{
@@ -33,10 +38,11 @@ Reports include:
   - Contract interaction analytics
   - Risk assessment with detected issues
   - Timeline and event distribution
+  - Token flows and per-account balances, with a Sankey diagram (--session)
 
 Examples:
   erst report --file trace.json --format html --output reports/
-  erst report --file trace.json --format pdf --output reports/
+  erst report --file trace.json --session <id> --format html  erst report --file trace.json --format pdf --output reports/
   erst report --file trace.json --format html,pdf --output reports/`,
 	RunE: reportExec,
 }
@@ -102,6 +108,12 @@ func reportExec(cmd *cobra.Command, args []string) error {
 	riskLevel := assessRisk(executionTrace.States)
 	builder.SetRiskAssessment(riskLevel, calculateRiskScore(executionTrace.States))
 
+	if reportSession != "" {
+		if err := addSessionTokenFlow(cmd, builder, reportSession); err != nil {
+			return err
+		}
+	}
+
 	// Metadata
 	builder.SetMetadata("execution_trace", "1.0.0", map[string]string{
 		"generated_by": "erst",
@@ -156,6 +168,61 @@ func reportExec(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// addSessionTokenFlow adds the token movements of a saved session's
+// transaction. Token metadata is read from the ledger entries stored with
+// the session and from the transaction meta.
+func addSessionTokenFlow(cmd *cobra.Command, builder *report.Builder, id string) error {
+	store, err := session.NewStore()
+	if err != nil {
+		return fmt.Errorf("failed to open session store: %w", err)
+	}
+	defer store.Close()
+
+	data, err := resolveSessionInput(cmd.Context(), store, id)
+	if err != nil {
+		return err
+	}
+	flows, err := tokenflow.BuildReport(data.EnvelopeXdr, data.ResultMetaXdr)
+	if err != nil {
+		return errors.WrapUnmarshalFailed(err, "transaction")
+	}
+
+	var sources []tokenflow.LedgerReader
+	if simReq, err := data.ToSimulationRequest(); err == nil && len(simReq.LedgerEntries) > 0 {
+		sources = append(sources, tokenflow.Entries(simReq.LedgerEntries))
+	}
+	if entries, err := rpc.ExtractLedgerEntriesFromMeta(data.ResultMetaXdr); err == nil {
+		sources = append(sources, tokenflow.Entries(entries))
+	}
+	_ = flows.ResolveTokens(cmd.Context(), sources...)
+
+	for _, t := range flows.Agg {
+		// Links are only compared within a token, so raw units size them.
+		value, _ := new(big.Float).SetInt(t.Amount).Float64()
+		builder.AddTokenFlow(report.TokenFlowLink{
+			From:   t.From,
+			To:     t.To,
+			Token:  t.Token.Display(),
+			Kind:   string(t.Kind),
+			Amount: t.Token.FormatAmount(t.Amount),
+			Value:  value,
+		})
+	}
+	for _, b := range flows.Balances {
+		builder.AddTokenBalance(report.TokenBalance{
+			Account: b.Account,
+			Token:   b.Token.Display(),
+			Before:  b.Token.FormatOptional(b.Before),
+			After:   b.Token.FormatOptional(b.After),
+			Change:  b.Token.FormatChange(b.Change()),
+		})
+	}
+	for _, d := range flows.Discrepancies {
+		builder.AddIssue(string(d.Kind), "high", d.Detail(), d.Token.ID, d.Account)
+	}
+	return nil
+}
+
 func countErrors(states []trace.ExecutionState) int {
 	count := 0
 	for _, state := range states {
@@ -219,6 +286,7 @@ func init() {
 	reportCmd.Flags().StringVar(&reportFormat, "format", "html", "Output format: html, pdf, json, or html,pdf")
 	reportCmd.Flags().StringVar(&reportOutput, "output", ".", "Output directory for reports")
 	reportCmd.Flags().StringVar(&reportFile, "file", "", "Trace file to analyze")
+	reportCmd.Flags().StringVar(&reportSession, "session", "", "Add token flows and balances from a saved session")
 
 	_ = reportCmd.RegisterFlagCompletionFunc("format", completeReportFormatFlag)
 
@@ -0,0 +1,45 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/logger"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/dotandev/hintents/internal/tuning"
+)
+
+// resourceMarginFlag is the --resource-margin of debug and dry-run.
+var resourceMarginFlag int
+
+// printResourceTuning recommends the SorobanResources a transaction should
+// declare, sized from its simulated run, and the calls that spent the most
+// instructions. Transactions without Soroban data are skipped.
+func printResourceTuning(envelopeXdr string, resp *simulator.SimulationResponse, entries map[string]string) {
+	if _, err := simulator.DecodeSorobanData(envelopeXdr); err != nil {
+		return
+	}
+	margin := resourceMarginFlag
+	if margin == 0 {
+		margin = -1
+	}
+	calls, err := callTreeFromResponse(resp)
+	if err != nil {
+		logger.Logger.Warn("Call tree unavailable for resource tuning", "error", err)
+	}
+	rec, err := tuning.Recommend(envelopeXdr, resp, entries, calls, tuning.Options{MarginPercent: margin})
+	if err != nil {
+		logger.Logger.Warn("Resource tuning unavailable", "error", err)
+		return
+	}
+
+	fmt.Printf("\n=== Resource Tuning ===\n")
+	for _, line := range rec.Lines() {
+		fmt.Printf("  %s\n", line)
+	}
+	if data, err := rec.SorobanData(envelopeXdr); err == nil {
+		fmt.Printf("  Tuned SorobanTransactionData: %s\n", data)
+	}
+}
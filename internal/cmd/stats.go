@@ -10,6 +10,7 @@ import (
 
 	"github.com/dotandev/hintents/internal/session"
 	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/dotandev/hintents/internal/trace"
 	"github.com/spf13/cobra"
 )
 
@@ -40,7 +41,11 @@ var statsCmd = &cobra.Command{
 	Short:   "Summarize budget usage and call depth for the top contract calls",
 	Long: `Returns a non-interactive table of the top 5 most expensive contract calls.
 
-Cost is estimated based on weighted operations:
+When the simulator reported per-invocation budgets, calls are ranked by the
+CPU instructions they consumed, including the calls they made, alongside
+their own instructions and memory.
+
+Otherwise cost is estimated based on weighted operations:
   - Storage writes: weight 3
   - Auth checks:    weight 2
   - Other events:   weight 1
@@ -56,6 +61,15 @@ func runStats(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if len(simResp.InvocationBudgets) > 0 {
+		root, err := callTreeFromResponse(simResp)
+		if err != nil {
+			return err
+		}
+		printCallStatsTable(buildCallStats(root), root)
+		return nil
+	}
+
 	stats := buildContractStats(simResp)
 	if len(stats) == 0 {
 		fmt.Println("No contract call data found in the session.")
@@ -171,6 +185,68 @@ func printStatsTable(stats []contractStat) {
 	}
 }
 
+// callStat is the measured cost of one contract call.
+type callStat struct {
+	contractID string
+	function   string
+	depth      int
+	cpu        uint64 // including sub-calls
+	selfCPU    uint64
+	mem        uint64 // including sub-calls
+}
+
+// buildCallStats ranks the contract calls of a budget tree by the CPU
+// instructions they consumed.
+func buildCallStats(root *trace.TraceNode) []callStat {
+	var result []callStat
+	for _, n := range root.FlattenAll() {
+		if n.Type != "contract_call" || n.CPUInclusive == nil {
+			continue
+		}
+		s := callStat{contractID: n.ContractID, function: n.Function, depth: n.Depth, cpu: *n.CPUInclusive}
+		if n.CPUDelta != nil {
+			s.selfCPU = *n.CPUDelta
+		}
+		if n.MemoryInclusive != nil {
+			s.mem = *n.MemoryInclusive
+		}
+		result = append(result, s)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].cpu > result[j].cpu })
+	if len(result) > statsTopN {
+		result = result[:statsTopN]
+	}
+	return result
+}
+
+func printCallStatsTable(stats []callStat, root *trace.TraceNode) {
+	const colCall = 44
+	var total uint64
+	if root.CPUInclusive != nil {
+		total = *root.CPUInclusive
+	}
+
+	fmt.Printf("Top %d most expensive contract calls (of %d CPU instructions)\n\n", statsTopN, total)
+	fmt.Printf("%-44s | %-12s | %-12s | %-6s | %-10s | %-5s\n", "Call", "CPU", "Self CPU", "Share", "Memory", "Depth")
+	fmt.Println(strings.Repeat("-", colCall+62))
+
+	for i, s := range stats {
+		name := s.function
+		if s.contractID != "" {
+			name = s.contractID + "::" + s.function
+		}
+		if len(name) > colCall-3 {
+			name = name[:colCall-6] + "..."
+		}
+		share := 0.0
+		if total > 0 {
+			share = float64(s.cpu) / float64(total) * 100
+		}
+		fmt.Printf("%d. %-41s | %-12d | %-12d | %5.1f%% | %-10d | %-5d\n", i+1, name, s.cpu, s.selfCPU, share, s.mem, s.depth)
+	}
+}
+
 func init() {
 	statsCmd.Flags().StringVar(&statsSessionFlag, "session", "", "Load a saved session by ID")
 	rootCmd.AddCommand(statsCmd)
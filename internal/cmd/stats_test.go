@@ -79,3 +79,33 @@ func TestEventCost(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildCallStats_RanksByInstructions(t *testing.T) {
+	resp := &simulator.SimulationResponse{
+		InvocationBudgets: []simulator.InvocationBudget{
+			{Kind: simulator.InvocationContractCall, ContractID: "CPOOL", Function: "swap", Depth: 1, CPUEnd: 1_000, MemEnd: 400},
+			{Kind: simulator.InvocationHostFn, Function: "require_auth", Depth: 2, CPUStart: 50, CPUEnd: 150},
+			{Kind: simulator.InvocationContractCall, ContractID: "CTOKEN", Function: "transfer", Depth: 2, CPUStart: 200, CPUEnd: 900},
+			{Kind: simulator.InvocationContractCall, ContractID: "CTOKEN", Function: "balance", Depth: 1, CPUStart: 1_000, CPUEnd: 1_800},
+		},
+	}
+
+	root, err := callTreeFromResponse(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := buildCallStats(root)
+
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 contract calls, got %d", len(stats))
+	}
+	if stats[0].function != "swap" || stats[0].cpu != 1_000 || stats[0].selfCPU != 200 {
+		t.Errorf("first = %+v, want swap with 1000 instructions, 200 of its own", stats[0])
+	}
+	if stats[1].function != "balance" || stats[2].function != "transfer" {
+		t.Errorf("order = %s, %s; want balance, transfer", stats[1].function, stats[2].function)
+	}
+	if stats[2].depth != 2 {
+		t.Errorf("transfer depth = %d, want 2", stats[2].depth)
+	}
+}
@@ -8,14 +8,16 @@ import (
 	"os"
 
 	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/rpc"
 	"github.com/dotandev/hintents/internal/trace"
 	"github.com/dotandev/hintents/internal/visualizer"
 	"github.com/spf13/cobra"
 )
 
 var (
-	traceFile      string
-	traceThemeFlag string
+	traceFile           string
+	traceThemeFlag      string
+	traceVerifiedSource bool
 )
 
 var traceCmd = &cobra.Command{
@@ -30,9 +32,19 @@ The trace viewer allows you to:
 - Reconstruct state at any point
 - View memory and host state changes
 
+Steps that record a source file and line can be stepped through by source
+line: next-line steps over calls, step-in enters calls, and step-out runs
+until the current function returns. Each stop shows the source line.
+
+With --verified-source, steps and traps without source lines are located in
+the contract source verified on stellar.expert, provided it was verified
+against the WASM currently deployed. The split-pane view then shows those
+files, with links to the repository at the verified commit.
+
 Example:
   erst trace execution.json
-  erst trace --file debug_trace.json`,
+  erst trace --file debug_trace.json
+  erst trace execution.json --verified-source --network testnet`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Apply theme if specified, otherwise auto-detect
@@ -69,15 +81,52 @@ Example:
 
 		// Start interactive viewer
 		viewer := trace.NewInteractiveViewer(executionTrace)
+		if traceVerifiedSource {
+			opts := []rpc.ClientOption{rpc.WithNetwork(rpc.Network(networkFlag))}
+			if rpcURLFlag != "" {
+				opts = append(opts, rpc.WithHorizonURL(rpcURLFlag))
+			}
+			client, err := rpc.NewClient(opts...)
+			if err != nil {
+				return errors.WrapValidationError(fmt.Sprintf("failed to create client: %v", err))
+			}
+			registerCacheFlushHook()
+
+			ids := traceContractIDs(executionTrace)
+			deployed := deployedWasms(cmd.Context(), client, ids)
+			if sources := loadVerifiedSources(cmd.Context(), networkFlag, ids, deployed); sources != nil {
+				viewer.SetSourceLocator(sources)
+			} else {
+				fmt.Fprintln(os.Stderr, "No verified source matches the deployed contracts; showing the trace without source")
+			}
+		}
 		return viewer.Start()
 	},
 }
 
+// traceContractIDs returns the contracts called in a trace, in order of first
+// appearance.
+func traceContractIDs(t *trace.ExecutionTrace) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, state := range t.States {
+		if state.ContractID != "" && !seen[state.ContractID] {
+			seen[state.ContractID] = true
+			ids = append(ids, state.ContractID)
+		}
+	}
+	return ids
+}
+
 func init() {
 	traceCmd.Flags().StringVarP(&traceFile, "file", "f", "", "Trace file to load")
 	traceCmd.Flags().StringVar(&traceThemeFlag, "theme", "", "Color theme (default, deuteranopia, protanopia, tritanopia, high-contrast)")
+	traceCmd.Flags().BoolVar(&traceVerifiedSource, "verified-source", false, "Resolve source from stellar.expert-verified repositories")
+	traceCmd.Flags().StringVarP(&networkFlag, "network", "n", string(rpc.Mainnet), "Stellar network of the traced contracts")
+	traceCmd.Flags().StringVar(&rpcURLFlag, "rpc-url", "", "Custom Horizon RPC URL")
 
 	_ = traceCmd.RegisterFlagCompletionFunc("theme", completeThemeFlag)
+	_ = traceCmd.RegisterFlagCompletionFunc("network", completeNetworkFlag)
 
 	rootCmd.AddCommand(traceCmd)
 }
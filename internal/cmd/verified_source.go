@@ -0,0 +1,239 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/logger"
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/dotandev/hintents/internal/sourcemap"
+	"github.com/dotandev/hintents/internal/trace"
+	"github.com/dotandev/hintents/internal/visualizer"
+)
+
+// verifiedSources locates code in the stellar.expert-verified source of each
+// contract in a trace. It implements trace.SourceLocator.
+type verifiedSources struct {
+	order    []string
+	locators map[string]*sourcemap.Locator
+}
+
+// loadVerifiedSources resolves verified source for every contract whose
+// deployed WASM, as returned by deployedWasms, matches the hash the source
+// was verified against. Contracts without matching source are skipped; nil
+// is returned when none resolve or the network has no registry.
+func loadVerifiedSources(ctx context.Context, network string, contractIDs []string, deployed map[string][]byte) *verifiedSources {
+	var registryNetwork sourcemap.NetworkID
+	switch rpc.Network(network) {
+	case rpc.Mainnet:
+		registryNetwork = sourcemap.NetworkPublic
+	case rpc.Testnet:
+		registryNetwork = sourcemap.NetworkTestnet
+	default:
+		return nil
+	}
+
+	cacheDir := getCacheDir()
+	resolver := sourcemap.NewResolver(
+		sourcemap.WithCache(cacheDir),
+		sourcemap.WithRegistryClient(sourcemap.NewRegistryClient(sourcemap.WithNetwork(registryNetwork))),
+	)
+
+	v := &verifiedSources{locators: make(map[string]*sourcemap.Locator)}
+	for _, id := range contractIDs {
+		if _, ok := v.locators[id]; ok {
+			continue
+		}
+		code := deployed[id]
+		if code == nil {
+			continue
+		}
+		sum := sha256.Sum256(code)
+		hash := hex.EncodeToString(sum[:])
+
+		src, err := resolver.ResolveVerified(ctx, id, hash)
+		if err != nil {
+			logger.Logger.Debug("Verified source lookup failed", "contract_id", id, "error", err)
+			continue
+		}
+		if src == nil {
+			continue
+		}
+
+		root := filepath.Join(cacheDir, "sourcemap", "src", hash)
+		if err := src.Materialize(root); err != nil {
+			logger.Logger.Warn("Failed to write verified source to disk", "contract_id", id, "error", err)
+			root = ""
+		}
+		v.order = append(v.order, id)
+		v.locators[id] = sourcemap.NewLocator(src, root, code)
+	}
+	if len(v.order) == 0 {
+		return nil
+	}
+	return v
+}
+
+// deployedWasms fetches the WASM currently deployed for each contract, once
+// per contract, keyed by contract ID. Contracts whose code cannot be fetched
+// are left out.
+func deployedWasms(ctx context.Context, client *rpc.Client, contractIDs []string) map[string][]byte {
+	codes := make(map[string][]byte)
+	for _, id := range contractIDs {
+		if _, ok := codes[id]; ok || id == "" {
+			continue
+		}
+		if code := deployedWasm(ctx, client, id); code != nil {
+			codes[id] = code
+		}
+	}
+	return codes
+}
+
+// deployedWasm returns the WASM currently deployed for a contract, or nil.
+func deployedWasm(ctx context.Context, client *rpc.Client, contractID string) []byte {
+	entries, err := rpc.FetchContractBytecode(ctx, client, contractID)
+	if err != nil {
+		logger.Logger.Debug("Failed to fetch deployed WASM", "contract_id", contractID, "error", err)
+		return nil
+	}
+	for _, entry := range entries {
+		if code, err := rpc.WasmBytesFromContractCodeEntry(entry); err == nil {
+			return code
+		}
+	}
+	return nil
+}
+
+// LocateFunction implements trace.SourceLocator. With an empty contractID
+// every resolved contract is searched.
+func (v *verifiedSources) LocateFunction(contractID, function string) (trace.SourceRef, bool) {
+	loc, ok := v.locate(contractID, func(l *sourcemap.Locator) (sourcemap.Location, bool) {
+		return l.LocateFunction(function)
+	})
+	if !ok {
+		return trace.SourceRef{}, false
+	}
+	file := loc.LocalPath
+	if file == "" {
+		file = loc.Path
+	}
+	return trace.SourceRef{
+		File:     file,
+		Line:     loc.Line,
+		Column:   loc.Column,
+		Function: function,
+		URL:      loc.URL,
+	}, true
+}
+
+func (v *verifiedSources) locate(contractID string, fn func(*sourcemap.Locator) (sourcemap.Location, bool)) (sourcemap.Location, bool) {
+	if l, ok := v.locators[contractID]; ok {
+		return fn(l)
+	}
+	if contractID != "" {
+		return sourcemap.Location{}, false
+	}
+	for _, id := range v.order {
+		if loc, ok := fn(v.locators[id]); ok {
+			return loc, true
+		}
+	}
+	return sourcemap.Location{}, false
+}
+
+// locateFrame resolves a WASM stack frame, by offset when the deployed WASM
+// has line tables and by function name otherwise.
+func (v *verifiedSources) locateFrame(frame simulator.StackFrame) (sourcemap.Location, bool) {
+	if frame.WasmOffset != nil {
+		if loc, ok := v.locate("", func(l *sourcemap.Locator) (sourcemap.Location, bool) {
+			return l.LocateOffset(*frame.WasmOffset)
+		}); ok {
+			return loc, true
+		}
+	}
+	if frame.FuncName != nil {
+		return v.locate("", func(l *sourcemap.Locator) (sourcemap.Location, bool) {
+			return l.LocateFunction(*frame.FuncName)
+		})
+	}
+	return sourcemap.Location{}, false
+}
+
+// printVerifiedSource shows where a failed simulation trapped in the verified
+// source: the reported source location, or else each stack frame, with the
+// code around the innermost resolved line.
+func printVerifiedSource(res *simulator.SimulationResponse, sources *verifiedSources) {
+	var locs []sourcemap.Location
+	if file, line, col, ok := parseSourceLocation(res.SourceLocation); ok {
+		if loc, ok := sources.locate("", func(l *sourcemap.Locator) (sourcemap.Location, bool) {
+			return l.LocateFile(file, line, col)
+		}); ok {
+			locs = append(locs, loc)
+		}
+	}
+	if len(locs) == 0 && res.StackTrace != nil {
+		for _, frame := range res.StackTrace.Frames {
+			if loc, ok := sources.locateFrame(frame); ok {
+				locs = append(locs, loc)
+			}
+		}
+	}
+	if len(locs) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s Verified Source:\n", visualizer.Symbol("file"))
+	for i, loc := range locs {
+		name := loc.Function
+		if name == "" {
+			name = "?"
+		}
+		fmt.Printf("  #%d %s at %s:%d\n", i, name, loc.Path, loc.Line)
+		if loc.URL != "" {
+			fmt.Printf("     %s\n", loc.URL)
+		}
+	}
+	if top := locs[0]; top.LocalPath != "" {
+		displaySourceLocation(&simulator.SourceLocation{
+			File:   top.LocalPath,
+			Line:   uint(top.Line),
+			Column: uint(top.Column),
+		})
+	}
+}
+
+// parseSourceLocation splits a "file:line[:column]" location string.
+func parseSourceLocation(s string) (file string, line, col int, ok bool) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 {
+		return "", 0, 0, false
+	}
+	nums := make([]int, 0, 2)
+	for len(parts) > 1 && len(nums) < 2 {
+		n, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			break
+		}
+		nums = append([]int{n}, nums...)
+		parts = parts[:len(parts)-1]
+	}
+	if len(nums) == 0 {
+		return "", 0, 0, false
+	}
+	file = strings.Join(parts, ":")
+	line = nums[0]
+	if len(nums) == 2 {
+		col = nums[1]
+	}
+	return file, line, col, file != "" && line > 0
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/dotandev/hintents/internal/sourcemap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSourceLocation(t *testing.T) {
+	file, line, col, ok := parseSourceLocation("src/lib.rs:42:7")
+	require.True(t, ok)
+	assert.Equal(t, "src/lib.rs", file)
+	assert.Equal(t, 42, line)
+	assert.Equal(t, 7, col)
+
+	file, line, col, ok = parseSourceLocation(`C:\build\src\lib.rs:10`)
+	require.True(t, ok)
+	assert.Equal(t, `C:\build\src\lib.rs`, file)
+	assert.Equal(t, 10, line)
+	assert.Equal(t, 0, col)
+
+	_, _, _, ok = parseSourceLocation("src/lib.rs")
+	assert.False(t, ok)
+	_, _, _, ok = parseSourceLocation("")
+	assert.False(t, ok)
+}
+
+func TestVerifiedSources_Locate(t *testing.T) {
+	src := &sourcemap.SourceCode{
+		Repository: "https://github.com/o/token",
+		Commit:     "abc",
+		Files:      map[string]string{"src/lib.rs": "impl T {\n    pub fn transfer(e: Env) {}\n}\n"},
+	}
+	v := &verifiedSources{
+		order:    []string{"CTOKEN"},
+		locators: map[string]*sourcemap.Locator{"CTOKEN": sourcemap.NewLocator(src, "", nil)},
+	}
+
+	ref, ok := v.LocateFunction("CTOKEN", "transfer")
+	require.True(t, ok)
+	assert.Equal(t, "src/lib.rs", ref.File)
+	assert.Equal(t, 2, ref.Line)
+	assert.Equal(t, "https://github.com/o/token/blob/abc/src/lib.rs#L2", ref.URL)
+
+	_, ok = v.LocateFunction("COTHER", "transfer")
+	assert.False(t, ok, "contracts without verified source are not searched")
+
+	name := "token::T::transfer"
+	loc, ok := v.locateFrame(simulator.StackFrame{FuncName: &name})
+	require.True(t, ok)
+	assert.Equal(t, "src/lib.rs", loc.Path)
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dotandev/hintents/internal/compare"
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+var verifyReplayJSONFlag bool
+
+var verifyReplayCmd = &cobra.Command{
+	Use:     "verify-replay <transaction-hash>",
+	GroupID: "testing",
+	Short:   "Check that a local replay reproduces the on-chain result",
+	Long: `Simulate a transaction and compare the outcome with what the network
+recorded in its TransactionMeta:
+
+  status          success or failure
+  return_value    the Soroban return value
+  events          contract events, in order
+  ledger_changes  net created/updated/removed entries per ledger key
+  resources       CPU instructions against the declared limit
+
+Dimensions the simulator does not report are shown as skipped. The command
+exits non-zero when any checked dimension diverges.`,
+	Example: `  erst verify-replay 5c0a... --network mainnet
+  erst verify-replay 5c0a... --json > report.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		txHash := args[0]
+
+		opts := []rpc.ClientOption{rpc.WithNetwork(rpc.Network(networkFlag))}
+		if rpcURLFlag != "" {
+			opts = append(opts, rpc.WithHorizonURL(rpcURLFlag))
+		}
+		client, err := rpc.NewClient(opts...)
+		if err != nil {
+			return errors.WrapValidationError(fmt.Sprintf("failed to create client: %v", err))
+		}
+		registerCacheFlushHook()
+
+		resp, err := client.GetTransaction(cmd.Context(), txHash)
+		if err != nil {
+			return errors.WrapRPCConnectionFailed(err)
+		}
+
+		onChain, err := compare.ExtractOnChainResult(resp.EnvelopeXdr, resp.ResultXdr, resp.ResultMetaXdr)
+		if err != nil {
+			return errors.WrapUnmarshalFailed(err, "transaction")
+		}
+
+		entries, err := replayLedgerEntries(cmd.Context(), client, resp.EnvelopeXdr, resp.ResultMetaXdr)
+		if err != nil {
+			return err
+		}
+
+		runner, err := simulator.NewRunner("", false)
+		if err != nil {
+			return errors.WrapSimulatorNotFound(err.Error())
+		}
+		registerRunnerCloseHook("verify-replay-runner", runner)
+		defer func() { _ = runner.Close() }()
+
+		result, err := runner.Run(cmd.Context(), &simulator.SimulationRequest{
+			EnvelopeXdr:   resp.EnvelopeXdr,
+			ResultMetaXdr: resp.ResultMetaXdr,
+			LedgerEntries: entries,
+		})
+		if err != nil {
+			return errors.WrapSimulationFailed(err, "")
+		}
+
+		report := compare.Verify(onChain, result)
+		report.TxHash = txHash
+
+		if verifyReplayJSONFlag {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return errors.WrapMarshalFailed(err)
+			}
+		} else {
+			compare.RenderVerify(report)
+		}
+
+		if !report.Passed() {
+			return errors.WrapSimulationLogicError(report.Summary())
+		}
+		return nil
+	},
+}
+
+// replayLedgerEntries rebuilds the ledger entries the transaction ran
+// against. Entries it changed are taken from their state before the change,
+// as recorded in the meta, since the network has moved on since; entries it
+// created are left out. The other footprint entries were only read and are
+// fetched at their current state.
+func replayLedgerEntries(ctx context.Context, client *rpc.Client, envelopeXdr, resultMetaXdr string) (map[string]string, error) {
+	before, err := compare.StateBefore(resultMetaXdr)
+	if err != nil {
+		return nil, errors.WrapUnmarshalFailed(err, "result meta")
+	}
+
+	var env xdr.TransactionEnvelope
+	if err := xdr.SafeUnmarshalBase64(envelopeXdr, &env); err != nil {
+		return nil, errors.WrapUnmarshalFailed(err, "envelope")
+	}
+	var readOnly []string
+	if data := simulator.SorobanData(env); data != nil {
+		fp := data.Resources.Footprint
+		for _, k := range append(append([]xdr.LedgerKey{}, fp.ReadOnly...), fp.ReadWrite...) {
+			keyB64, err := xdr.MarshalBase64(k)
+			if err != nil {
+				return nil, errors.WrapUnmarshalFailed(err, "envelope")
+			}
+			if _, ok := before[keyB64]; !ok {
+				readOnly = append(readOnly, keyB64)
+			}
+		}
+	}
+	entries, err := client.GetLedgerEntries(ctx, readOnly)
+	if err != nil {
+		return nil, errors.WrapRPCConnectionFailed(err)
+	}
+	for k, e := range before {
+		if e != "" {
+			entries[k] = e
+		}
+	}
+	return entries, nil
+}
+
+// verifyReplayAgainstChain adapts compare.Verify to simulator.ReplayVerifier
+// so regression-test can use the same pass/fail decision.
+func verifyReplayAgainstChain(tx *rpc.TransactionResponse, sim *simulator.SimulationResponse) (*simulator.ReplayVerdict, error) {
+	onChain, err := compare.ExtractOnChainResult(tx.EnvelopeXdr, tx.ResultXdr, tx.ResultMetaXdr)
+	if err != nil {
+		return nil, err
+	}
+	report := compare.Verify(onChain, sim)
+	return &simulator.ReplayVerdict{
+		Passed:         report.Passed(),
+		Reason:         report.Summary(),
+		StatusMatch:    !report.Diverged(compare.DimensionStatus),
+		EventsMatch:    !report.Diverged(compare.DimensionEvents),
+		ExpectedEvents: len(onChain.ContractEvents),
+	}, nil
+}
+
+func init() {
+	verifyReplayCmd.Flags().StringVarP(&networkFlag, "network", "n", string(rpc.Mainnet), "Stellar network to use")
+	verifyReplayCmd.Flags().StringVar(&rpcURLFlag, "rpc-url", "", "Custom Horizon RPC URL")
+	verifyReplayCmd.Flags().BoolVar(&verifyReplayJSONFlag, "json", false, "Print the mismatch report as JSON")
+
+	_ = verifyReplayCmd.RegisterFlagCompletionFunc("network", completeNetworkFlag)
+
+	rootCmd.AddCommand(verifyReplayCmd)
+}
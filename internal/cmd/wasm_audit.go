@@ -0,0 +1,132 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/security"
+	"github.com/dotandev/hintents/internal/visualizer"
+	"github.com/dotandev/hintents/internal/wasmaudit"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditWasmFormat string
+	auditWasmOutput string
+)
+
+var auditWasmCmd = &cobra.Command{
+	Use:     "audit-wasm <wasm-file>",
+	GroupID: "development",
+	Short:   "Statically analyze contract bytecode for security issues",
+	Long: `Analyze a compiled Soroban contract without running it. Starting from each
+exported function, erst follows the call graph to the host functions it can
+reach and reports:
+
+  SEC009  storage writes with no reachable require_auth
+  SEC010  integer overflow checks that panic instead of returning an error
+  SEC011  loops that read or write storage on every iteration
+  SEC012  persistent writes with no reachable extend_contract_data_ttl
+
+Findings are mapped to source lines when the contract is built with debug
+info; otherwise they point at the function and module offset, which can be
+inspected with erst wat --offset. Rules are configured and suppressed in
+erst.toml like the transaction rules used by erst debug.
+
+Examples:
+  erst audit-wasm ./contract.wasm
+  erst audit-wasm ./contract.wasm --format sarif -o audit.sarif`,
+	Args: cobra.ExactArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		switch auditWasmFormat {
+		case "text", "json", "sarif":
+			return nil
+		}
+		return errors.WrapValidationError(fmt.Sprintf("unsupported format: %s (use: text, json, sarif)", auditWasmFormat))
+	},
+	RunE: auditWasmExec,
+}
+
+func auditWasmExec(cmd *cobra.Command, args []string) error {
+	wasmBytes, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("reading WASM file: %w", err)
+	}
+	audit, err := wasmaudit.Analyze(wasmBytes)
+	if err != nil {
+		return err
+	}
+
+	cfg, _, err := security.LoadConfig()
+	if err != nil {
+		return errors.WrapConfigError("failed to load security configuration", err)
+	}
+	engine, err := security.NewEngine(cfg)
+	if err != nil {
+		return errors.WrapConfigError("invalid security configuration", err)
+	}
+	report := engine.Analyze(&security.Input{WasmAudit: audit})
+
+	var out []byte
+	switch auditWasmFormat {
+	case "json":
+		out, err = json.MarshalIndent(report, "", "  ")
+	case "sarif":
+		out, err = security.MarshalSARIF(report, engine.Rules(), security.SARIFOptions{ToolVersion: Version})
+	default:
+		out = []byte(formatWasmAudit(args[0], audit, report))
+	}
+	if err != nil {
+		return errors.WrapMarshalFailed(err)
+	}
+
+	if auditWasmOutput == "" {
+		fmt.Print(string(out))
+		if auditWasmFormat != "text" {
+			fmt.Println()
+		}
+		return nil
+	}
+	if err := os.WriteFile(auditWasmOutput, out, 0644); err != nil {
+		return fmt.Errorf("failed to write audit report: %w", err)
+	}
+	fmt.Printf("%s Audit report saved: %s\n", visualizer.Success(), auditWasmOutput)
+	return nil
+}
+
+func formatWasmAudit(path string, audit *wasmaudit.Report, report *security.Report) string {
+	out := fmt.Sprintf("Audited %s: %d exported function(s)\n", path, audit.Exports)
+	if !audit.DebugInfo {
+		out += "No debug info: findings point at bytecode offsets (build with debug info to map them to source)\n"
+	}
+
+	if len(report.Findings) == 0 {
+		out += fmt.Sprintf("%s No security issues detected\n", visualizer.Success())
+	} else {
+		out += fmt.Sprintf("\nFindings: %d\n", len(report.Findings))
+		for i, f := range report.Findings {
+			out += fmt.Sprintf("%d. %s %s - %s\n", i+1, f.RuleID, f.Severity, f.Title)
+			out += fmt.Sprintf("   %s\n", f.Description)
+			if f.File != "" {
+				out += fmt.Sprintf("   Source: %s:%d\n", f.File, f.Line)
+			}
+			out += fmt.Sprintf("   Location: %s (%s)\n", f.Location, f.Evidence)
+		}
+	}
+
+	if len(report.Suppressed) > 0 {
+		out += fmt.Sprintf("(%d finding(s) suppressed by erst.toml)\n", len(report.Suppressed))
+	}
+	return out
+}
+
+func init() {
+	auditWasmCmd.Flags().StringVar(&auditWasmFormat, "format", "text", "Output format (text, json, sarif)")
+	auditWasmCmd.Flags().StringVarP(&auditWasmOutput, "output", "o", "", "Write the report to a file instead of stdout")
+	rootCmd.AddCommand(auditWasmCmd)
+}
@@ -6,10 +6,84 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/dotandev/hintents/internal/wasmopt"
+	"github.com/spf13/cobra"
 )
 
+var (
+	wasmOptimizeOutput string
+	wasmOptimizePasses []string
+)
+
+var wasmOptimizeCmd = &cobra.Command{
+	Use:     "wasm-optimize <wasm-file>",
+	GroupID: "development",
+	Short:   "Shrink a Soroban contract WASM with size optimization passes",
+	Long: `Run size optimization passes over a compiled contract and report how many
+bytes each pass saved. Useful when a contract is close to the network's
+max_contract_size limit.
+
+Passes (run in this order by default):
+` + wasmOptimizePassList() + `
+After the passes run, the contract spec is decoded again and compared with
+the original, and imports, exports and their signatures are checked, so an
+optimization that would change the contract interface fails instead of
+producing a broken binary.
+
+Without -o, performs a dry run and prints the report only.
+
+Examples:
+  erst wasm-optimize ./contract.wasm -o ./contract.min.wasm
+  erst wasm-optimize ./contract.wasm --passes strip-custom,dce`,
+	Args: cobra.ExactArgs(1),
+	RunE: wasmOptimizeExec,
+}
+
+func wasmOptimizePassList() string {
+	var sb strings.Builder
+	for _, p := range wasmopt.Passes {
+		fmt.Fprintf(&sb, "  %-22s %s\n", p.Name, p.Description)
+	}
+	return sb.String()
+}
+
+func wasmOptimizeExec(cmd *cobra.Command, args []string) error {
+	wasmBytes, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("reading WASM file: %w", err)
+	}
+
+	passes := wasmopt.Passes
+	if len(wasmOptimizePasses) > 0 {
+		if passes, err = wasmopt.PassesByName(wasmOptimizePasses); err != nil {
+			return err
+		}
+	}
+
+	out, report, err := wasmopt.Optimize(wasmBytes, passes)
+	if err != nil {
+		return err
+	}
+	fmt.Print(wasmopt.FormatSizeReport(report))
+
+	if wasmOptimizeOutput == "" {
+		return nil
+	}
+	if err := os.WriteFile(wasmOptimizeOutput, out, 0644); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	fmt.Printf("Written to:     %s\n", wasmOptimizeOutput)
+	return nil
+}
+
+func init() {
+	wasmOptimizeCmd.Flags().StringVarP(&wasmOptimizeOutput, "output", "o", "", "Output file path (omit for dry run)")
+	wasmOptimizeCmd.Flags().StringSliceVar(&wasmOptimizePasses, "passes", nil, "Comma-separated passes to run, in order (default: all)")
+	rootCmd.AddCommand(wasmOptimizeCmd)
+}
+
 func optimizeWasmBytesIfRequested(input []byte, enabled bool) ([]byte, *wasmopt.Report, error) {
 	if !enabled {
 		return input, nil, nil
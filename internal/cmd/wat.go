@@ -0,0 +1,91 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/wat"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watFunc   string
+	watOffset string
+)
+
+var watCmd = &cobra.Command{
+	Use:     "wat <wasm-file>",
+	GroupID: "utility",
+	Short:   "Disassemble a WASM contract to WebAssembly text format",
+	Long: `Print a compiled contract in WebAssembly text format (WAT), with structured
+blocks indented and functions named from the "name" section (demangled) or,
+for stripped builds, from their exports.
+
+Use --func to print a single function, selected by export name, demangled or
+mangled symbol, or index. Use --offset to mark the instruction at a module
+byte offset, such as one reported by a trap; without --func, the function
+containing the offset is printed.
+
+Examples:
+  erst wat ./contract.wasm
+  erst wat ./contract.wasm --func transfer
+  erst wat ./contract.wasm --offset 0x1a2f`,
+	Args: cobra.ExactArgs(1),
+	RunE: watExec,
+}
+
+func watExec(cmd *cobra.Command, args []string) error {
+	wasmBytes, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("reading WASM file: %w", err)
+	}
+	d := wat.NewDisassembler(wasmBytes)
+
+	highlight := ^uint64(0)
+	if watOffset != "" {
+		if highlight, err = strconv.ParseUint(watOffset, 0, 64); err != nil {
+			return errors.WrapValidationError(fmt.Sprintf("invalid offset %q", watOffset))
+		}
+	}
+
+	switch {
+	case watFunc != "":
+		idx, err := d.FindFunction(watFunc)
+		if err != nil {
+			return err
+		}
+		text, err := d.Function(idx, highlight)
+		if err != nil {
+			return err
+		}
+		fmt.Print(text)
+	case watOffset != "":
+		idx, ok := d.FunctionAt(highlight)
+		if !ok {
+			return errors.WrapValidationError(fmt.Sprintf("offset %s is not inside a function body", watOffset))
+		}
+		text, err := d.Function(idx, highlight)
+		if err != nil {
+			return err
+		}
+		fmt.Print(text)
+	default:
+		text, err := d.Module()
+		if err != nil {
+			return err
+		}
+		fmt.Print(text)
+	}
+	return nil
+}
+
+func init() {
+	watCmd.Flags().StringVar(&watFunc, "func", "", "Print only this function (name, symbol or index)")
+	watCmd.Flags().StringVar(&watOffset, "offset", "", "Mark the instruction at this module byte offset (decimal or 0x hex)")
+	rootCmd.AddCommand(watCmd)
+}
@@ -62,6 +62,61 @@ func Render(result *DiffResult) {
 	renderSummary(result)
 }
 
+// RenderVerify prints a VerifyReport to stdout: one line per checked
+// dimension followed by the individual mismatches.
+func RenderVerify(report *VerifyReport) {
+	if report == nil {
+		return
+	}
+
+	fmt.Println()
+	title := "Replay Verification"
+	if report.TxHash != "" {
+		title += " – " + truncate(report.TxHash, 20)
+	}
+	fmt.Println(sectionTitle(title))
+	fmt.Println()
+
+	for _, c := range report.Checks {
+		var tag string
+		switch c.Result {
+		case CheckMatch:
+			tag = visualizer.Colorize("[MATCH]", "green")
+		case CheckMismatch:
+			tag = visualizer.Colorize("[DIFF] ", "red")
+		default:
+			tag = visualizer.Colorize("[SKIP] ", "dim")
+		}
+		line := fmt.Sprintf("  %s  %-16s", tag, c.Dimension)
+		if c.Detail != "" {
+			line += "  " + c.Detail
+		}
+		fmt.Println(line)
+	}
+
+	if len(report.Mismatches) > 0 {
+		fmt.Println()
+		fmt.Println(sectionTitle("Mismatches"))
+		for _, m := range report.Mismatches {
+			fmt.Println()
+			label := m.Dimension
+			if m.Subject != "" {
+				label += " " + m.Subject
+			}
+			fmt.Printf("  %s\n", visualizer.Colorize(label, "bold"))
+			fmt.Printf("       On-Chain  : %s\n", visualizer.Colorize(m.OnChain, "magenta"))
+			fmt.Printf("       Simulated : %s\n", visualizer.Colorize(m.Simulated, "cyan"))
+		}
+	}
+
+	fmt.Println()
+	if report.Passed() {
+		fmt.Printf("  %s  %s\n", visualizer.Success(), report.Summary())
+	} else {
+		fmt.Printf("  %s  %s\n", visualizer.Warning(), report.Summary())
+	}
+}
+
 // ─── internal renderers ───────────────────────────────────────────────────────
 
 func printHeader() {
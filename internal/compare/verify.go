@@ -0,0 +1,576 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package compare
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// Dimensions checked by Verify.
+const (
+	DimensionStatus        = "status"
+	DimensionReturnValue   = "return_value"
+	DimensionEvents        = "events"
+	DimensionLedgerChanges = "ledger_changes"
+	DimensionResources     = "resources"
+)
+
+// Outcomes of a single dimension check.
+const (
+	CheckMatch    = "match"
+	CheckMismatch = "mismatch"
+	CheckSkipped  = "skipped"
+)
+
+// OnChainResult is what actually happened on chain, extracted from the
+// transaction envelope, result and meta XDR.
+type OnChainResult struct {
+	Successful     bool                          `json:"successful"`
+	ReturnValue    string                        `json:"return_value,omitempty"`
+	ContractEvents []string                      `json:"contract_events,omitempty"`
+	LedgerChanges  []simulator.LedgerEntryChange `json:"ledger_changes,omitempty"`
+
+	// DeclaredInstructions is the CPU instruction limit from the envelope's
+	// SorobanTransactionData; 0 for classic transactions.
+	DeclaredInstructions uint64 `json:"declared_instructions,omitempty"`
+}
+
+// Mismatch is one concrete difference between the replay and the chain.
+type Mismatch struct {
+	Dimension string `json:"dimension"`
+	// Subject identifies what differs within the dimension, such as an event
+	// index or a ledger key.
+	Subject   string `json:"subject,omitempty"`
+	OnChain   string `json:"on_chain"`
+	Simulated string `json:"simulated"`
+}
+
+// DimensionCheck records the outcome of comparing one dimension.
+type DimensionCheck struct {
+	Dimension string `json:"dimension"`
+	Result    string `json:"result"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// VerifyReport is the structured result of checking a replay against chain.
+type VerifyReport struct {
+	TxHash     string           `json:"tx_hash,omitempty"`
+	Checks     []DimensionCheck `json:"checks"`
+	Mismatches []Mismatch       `json:"mismatches,omitempty"`
+}
+
+// Passed reports whether no checked dimension diverged. Skipped dimensions
+// do not cause a failure.
+func (r *VerifyReport) Passed() bool {
+	for _, c := range r.Checks {
+		if c.Result == CheckMismatch {
+			return false
+		}
+	}
+	return true
+}
+
+// Diverged reports whether the check of dim found a mismatch. Unchecked and
+// skipped dimensions have not diverged.
+func (r *VerifyReport) Diverged(dim string) bool {
+	for _, c := range r.Checks {
+		if c.Dimension == dim && c.Result == CheckMismatch {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary returns a one-line description of the report.
+func (r *VerifyReport) Summary() string {
+	var matched, skipped int
+	for _, c := range r.Checks {
+		switch c.Result {
+		case CheckMatch:
+			matched++
+		case CheckSkipped:
+			skipped++
+		}
+	}
+	if r.Passed() {
+		return fmt.Sprintf("replay matches chain (%d checked, %d skipped)", matched, skipped)
+	}
+	return fmt.Sprintf("replay diverges from chain (%d mismatch(es) across %d dimension(s))",
+		len(r.Mismatches), len(r.Checks)-matched-skipped)
+}
+
+// ExtractOnChainResult decodes the on-chain outcome of a transaction. metaXdr
+// may be either a TransactionMeta or a TransactionResultMeta; resultXdr may be
+// empty, in which case success is inferred from the presence of a return value.
+func ExtractOnChainResult(envelopeXdr, resultXdr, metaXdr string) (*OnChainResult, error) {
+	out := &OnChainResult{}
+
+	if envelopeXdr != "" {
+		var env xdr.TransactionEnvelope
+		if err := xdr.SafeUnmarshalBase64(envelopeXdr, &env); err != nil {
+			return nil, fmt.Errorf("decode envelope: %w", err)
+		}
+		if data := simulator.SorobanData(env); data != nil {
+			out.DeclaredInstructions = uint64(data.Resources.Instructions)
+		}
+	}
+
+	meta, err := decodeTransactionMeta(metaXdr)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := simulator.SplitMeta(meta)
+	if err != nil {
+		return nil, err
+	}
+	opChanges, returnValue, events := parts.Operations, parts.ReturnValue, parts.Events
+
+	if returnValue != nil {
+		if out.ReturnValue, err = xdr.MarshalBase64(*returnValue); err != nil {
+			return nil, fmt.Errorf("encode return value: %w", err)
+		}
+	}
+	for _, e := range events {
+		b64, err := xdr.MarshalBase64(e)
+		if err != nil {
+			return nil, fmt.Errorf("encode contract event: %w", err)
+		}
+		out.ContractEvents = append(out.ContractEvents, b64)
+	}
+	if out.LedgerChanges, err = NetLedgerChanges(opChanges...); err != nil {
+		return nil, err
+	}
+
+	if resultXdr != "" {
+		var res xdr.TransactionResult
+		if err := xdr.SafeUnmarshalBase64(resultXdr, &res); err != nil {
+			return nil, fmt.Errorf("decode result: %w", err)
+		}
+		out.Successful = res.Successful()
+	} else {
+		out.Successful = returnValue != nil
+	}
+
+	return out, nil
+}
+
+// NetLedgerChanges collapses operation-level change lists into one change per
+// ledger key, in key order. STATE entries only describe the pre-image and are
+// dropped; an entry created and later updated in the same transaction stays
+// "created", and one created then removed disappears.
+func NetLedgerChanges(changeSets ...xdr.LedgerEntryChanges) ([]simulator.LedgerEntryChange, error) {
+	net := make(map[string]*simulator.LedgerEntryChange)
+	for _, changes := range changeSets {
+		for _, c := range changes {
+			var (
+				key   xdr.LedgerKey
+				entry *xdr.LedgerEntry
+				kind  string
+				err   error
+			)
+			switch c.Type {
+			case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+				entry, kind = c.Created, simulator.LedgerChangeCreated
+			case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+				entry, kind = c.Updated, simulator.LedgerChangeUpdated
+			case xdr.LedgerEntryChangeTypeLedgerEntryRestored:
+				entry, kind = c.Restored, simulator.LedgerChangeRestored
+			case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+				key, kind = *c.Removed, simulator.LedgerChangeRemoved
+			default:
+				continue
+			}
+			if entry != nil {
+				if key, err = entry.LedgerKey(); err != nil {
+					return nil, fmt.Errorf("derive ledger key: %w", err)
+				}
+			}
+			keyB64, err := xdr.MarshalBase64(key)
+			if err != nil {
+				return nil, fmt.Errorf("encode ledger key: %w", err)
+			}
+
+			next := simulator.LedgerEntryChange{KeyXdr: keyB64, Type: kind}
+			if entry != nil {
+				if next.EntryXdr, err = xdr.MarshalBase64(*entry); err != nil {
+					return nil, fmt.Errorf("encode ledger entry: %w", err)
+				}
+			}
+
+			prev, seen := net[keyB64]
+			switch {
+			case !seen:
+				net[keyB64] = &next
+			case prev.Type == simulator.LedgerChangeCreated && kind == simulator.LedgerChangeRemoved:
+				delete(net, keyB64)
+			case prev.Type == simulator.LedgerChangeCreated || prev.Type == simulator.LedgerChangeRestored:
+				prev.EntryXdr = next.EntryXdr
+				if kind == simulator.LedgerChangeRemoved {
+					prev.Type = kind
+				}
+			default:
+				*prev = next
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(net))
+	for k := range net {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]simulator.LedgerEntryChange, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, *net[k])
+	}
+	return out, nil
+}
+
+// StateBefore rebuilds the ledger entries the operations of a transaction
+// started from out of its meta, as base64 LedgerEntry by base64 LedgerKey:
+// the state an entry had before the first operation changed it, after any
+// fee and transaction-level changes made ahead of the operations. Entries
+// the operations created map to "". Restored entries are given as they came
+// out of the archive. Entries the transaction only read are not in the meta
+// and so not in the result.
+func StateBefore(metaXdr string) (map[string]string, error) {
+	fees, meta, err := decodeResultMeta(metaXdr)
+	if err != nil {
+		return nil, err
+	}
+	parts, err := simulator.SplitMeta(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string]string{}
+	// Changes ahead of the operations leave entries in their latest state.
+	for _, c := range append(append(xdr.LedgerEntryChanges{}, fees...), parts.Before...) {
+		if c.Type == xdr.LedgerEntryChangeTypeLedgerEntryRemoved {
+			continue
+		}
+		keyB64, entryB64, err := encodeChange(c)
+		if err != nil {
+			return nil, err
+		}
+		out[keyB64] = entryB64
+	}
+	// The operations started from the first state they saw of an entry.
+	seen := map[string]bool{}
+	for _, op := range parts.Operations {
+		for _, c := range op {
+			keyB64, entryB64, err := encodeChange(c)
+			if err != nil {
+				return nil, err
+			}
+			if seen[keyB64] {
+				continue
+			}
+			seen[keyB64] = true
+			if _, ok := out[keyB64]; ok {
+				continue
+			}
+			switch c.Type {
+			case xdr.LedgerEntryChangeTypeLedgerEntryState, xdr.LedgerEntryChangeTypeLedgerEntryRestored:
+				out[keyB64] = entryB64
+			case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+				out[keyB64] = ""
+			}
+		}
+	}
+	return out, nil
+}
+
+// encodeChange returns the base64 key and entry of a change; the entry is
+// empty for removals.
+func encodeChange(c xdr.LedgerEntryChange) (string, string, error) {
+	if c.Type == xdr.LedgerEntryChangeTypeLedgerEntryRemoved {
+		keyB64, err := xdr.MarshalBase64(*c.Removed)
+		if err != nil {
+			return "", "", fmt.Errorf("encode ledger key: %w", err)
+		}
+		return keyB64, "", nil
+	}
+	var entry xdr.LedgerEntry
+	switch c.Type {
+	case xdr.LedgerEntryChangeTypeLedgerEntryState:
+		entry = *c.State
+	case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+		entry = *c.Created
+	case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+		entry = *c.Updated
+	case xdr.LedgerEntryChangeTypeLedgerEntryRestored:
+		entry = *c.Restored
+	default:
+		return "", "", fmt.Errorf("unsupported ledger entry change type: %d", c.Type)
+	}
+	key, err := entry.LedgerKey()
+	if err != nil {
+		return "", "", fmt.Errorf("derive ledger key: %w", err)
+	}
+	keyB64, err := xdr.MarshalBase64(key)
+	if err != nil {
+		return "", "", fmt.Errorf("encode ledger key: %w", err)
+	}
+	entryB64, err := xdr.MarshalBase64(entry)
+	if err != nil {
+		return "", "", fmt.Errorf("encode ledger entry: %w", err)
+	}
+	return keyB64, entryB64, nil
+}
+
+// Verify compares a replay against the on-chain result. Dimensions the
+// simulator did not report are marked skipped rather than failed.
+func Verify(onChain *OnChainResult, sim *simulator.SimulationResponse) *VerifyReport {
+	r := &VerifyReport{}
+	r.verifyStatus(onChain, sim)
+	r.verifyReturnValue(onChain, sim)
+	r.verifyEvents(onChain, sim)
+	r.verifyLedgerChanges(onChain, sim)
+	r.verifyResources(onChain, sim)
+	return r
+}
+
+func (r *VerifyReport) add(dim string, mismatches []Mismatch, detail string) {
+	result := CheckMatch
+	if len(mismatches) > 0 {
+		result = CheckMismatch
+		r.Mismatches = append(r.Mismatches, mismatches...)
+	}
+	r.Checks = append(r.Checks, DimensionCheck{Dimension: dim, Result: result, Detail: detail})
+}
+
+func (r *VerifyReport) skip(dim, reason string) {
+	r.Checks = append(r.Checks, DimensionCheck{Dimension: dim, Result: CheckSkipped, Detail: reason})
+}
+
+func (r *VerifyReport) verifyStatus(onChain *OnChainResult, sim *simulator.SimulationResponse) {
+	chainStatus := "failed"
+	if onChain.Successful {
+		chainStatus = "success"
+	}
+	simOK := sim.Status == "success"
+	if simOK == onChain.Successful {
+		r.add(DimensionStatus, nil, "")
+		return
+	}
+	simStatus := sim.Status
+	if sim.Error != "" {
+		simStatus += ": " + sim.Error
+	}
+	r.add(DimensionStatus, []Mismatch{{Dimension: DimensionStatus, OnChain: chainStatus, Simulated: simStatus}}, "")
+}
+
+func (r *VerifyReport) verifyReturnValue(onChain *OnChainResult, sim *simulator.SimulationResponse) {
+	if sim.ReturnValue == "" {
+		r.skip(DimensionReturnValue, "not reported by simulator")
+		return
+	}
+	if sim.ReturnValue == onChain.ReturnValue {
+		r.add(DimensionReturnValue, nil, "")
+		return
+	}
+	r.add(DimensionReturnValue, []Mismatch{{
+		Dimension: DimensionReturnValue,
+		OnChain:   describeScVal(onChain.ReturnValue),
+		Simulated: describeScVal(sim.ReturnValue),
+	}}, "")
+}
+
+func (r *VerifyReport) verifyEvents(onChain *OnChainResult, sim *simulator.SimulationResponse) {
+	if sim.ContractEvents == nil {
+		// Fall back to a count check against the structured diagnostic stream.
+		if sim.DiagnosticEvents == nil {
+			r.skip(DimensionEvents, "not reported by simulator")
+			return
+		}
+		count := 0
+		for _, e := range sim.DiagnosticEvents {
+			if e.EventType == "contract" {
+				count++
+			}
+		}
+		var ms []Mismatch
+		if count != len(onChain.ContractEvents) {
+			ms = append(ms, Mismatch{
+				Dimension: DimensionEvents,
+				Subject:   "count",
+				OnChain:   fmt.Sprint(len(onChain.ContractEvents)),
+				Simulated: fmt.Sprint(count),
+			})
+		}
+		r.add(DimensionEvents, ms, "count only; simulator did not report event XDR")
+		return
+	}
+
+	var ms []Mismatch
+	n := max(len(onChain.ContractEvents), len(sim.ContractEvents))
+	for i := 0; i < n; i++ {
+		var want, got string
+		if i < len(onChain.ContractEvents) {
+			want = onChain.ContractEvents[i]
+		}
+		if i < len(sim.ContractEvents) {
+			got = sim.ContractEvents[i]
+		}
+		if want != got {
+			ms = append(ms, Mismatch{
+				Dimension: DimensionEvents,
+				Subject:   fmt.Sprintf("event[%d]", i),
+				OnChain:   describeEvent(want),
+				Simulated: describeEvent(got),
+			})
+		}
+	}
+	r.add(DimensionEvents, ms, fmt.Sprintf("%d on-chain, %d simulated", len(onChain.ContractEvents), len(sim.ContractEvents)))
+}
+
+func (r *VerifyReport) verifyLedgerChanges(onChain *OnChainResult, sim *simulator.SimulationResponse) {
+	if sim.LedgerChanges == nil {
+		r.skip(DimensionLedgerChanges, "not reported by simulator")
+		return
+	}
+
+	simByKey := make(map[string]simulator.LedgerEntryChange, len(sim.LedgerChanges))
+	for _, c := range sim.LedgerChanges {
+		simByKey[c.KeyXdr] = c
+	}
+
+	var ms []Mismatch
+	for _, want := range onChain.LedgerChanges {
+		subject := describeLedgerKey(want.KeyXdr)
+		got, ok := simByKey[want.KeyXdr]
+		delete(simByKey, want.KeyXdr)
+		switch {
+		case !ok:
+			ms = append(ms, Mismatch{Dimension: DimensionLedgerChanges, Subject: subject, OnChain: want.Type, Simulated: "unchanged"})
+		case got.Type != want.Type:
+			ms = append(ms, Mismatch{Dimension: DimensionLedgerChanges, Subject: subject, OnChain: want.Type, Simulated: got.Type})
+		case !ledgerEntryDataEqual(want.EntryXdr, got.EntryXdr):
+			ms = append(ms, Mismatch{
+				Dimension: DimensionLedgerChanges,
+				Subject:   subject,
+				OnChain:   want.Type + " " + truncate(want.EntryXdr, 40),
+				Simulated: got.Type + " " + truncate(got.EntryXdr, 40),
+			})
+		}
+	}
+
+	extra := make([]string, 0, len(simByKey))
+	for k := range simByKey {
+		extra = append(extra, k)
+	}
+	sort.Strings(extra)
+	for _, k := range extra {
+		ms = append(ms, Mismatch{Dimension: DimensionLedgerChanges, Subject: describeLedgerKey(k), OnChain: "unchanged", Simulated: simByKey[k].Type})
+	}
+
+	r.add(DimensionLedgerChanges, ms, fmt.Sprintf("%d on-chain, %d simulated", len(onChain.LedgerChanges), len(sim.LedgerChanges)))
+}
+
+// verifyResources checks that the replay stayed within the instruction limit
+// the transaction declared. Actual on-chain consumption is not recorded in
+// meta, so the declared limit is the only reference available.
+func (r *VerifyReport) verifyResources(onChain *OnChainResult, sim *simulator.SimulationResponse) {
+	if sim.BudgetUsage == nil {
+		r.skip(DimensionResources, "not reported by simulator")
+		return
+	}
+	if onChain.DeclaredInstructions == 0 {
+		r.skip(DimensionResources, "transaction declares no Soroban resources")
+		return
+	}
+	detail := fmt.Sprintf("%d of %d declared instructions", sim.BudgetUsage.CPUInstructions, onChain.DeclaredInstructions)
+	if !onChain.Successful || sim.BudgetUsage.CPUInstructions <= onChain.DeclaredInstructions {
+		r.add(DimensionResources, nil, detail)
+		return
+	}
+	r.add(DimensionResources, []Mismatch{{
+		Dimension: DimensionResources,
+		Subject:   "cpu_instructions",
+		OnChain:   fmt.Sprintf("<= %d", onChain.DeclaredInstructions),
+		Simulated: fmt.Sprint(sim.BudgetUsage.CPUInstructions),
+	}}, detail)
+}
+
+// ─── XDR helpers ──────────────────────────────────────────────────────────────
+
+func decodeTransactionMeta(metaXdr string) (xdr.TransactionMeta, error) {
+	_, meta, err := decodeResultMeta(metaXdr)
+	return meta, err
+}
+
+// decodeResultMeta decodes a TransactionMeta or a TransactionResultMeta, and
+// returns the fee changes of the latter.
+func decodeResultMeta(metaXdr string) (xdr.LedgerEntryChanges, xdr.TransactionMeta, error) {
+	var meta xdr.TransactionMeta
+	raw, err := base64.StdEncoding.DecodeString(metaXdr)
+	if err != nil {
+		return nil, meta, fmt.Errorf("decode result meta: %w", err)
+	}
+	if err := xdr.SafeUnmarshal(raw, &meta); err == nil {
+		return nil, meta, nil
+	}
+	var resultMeta xdr.TransactionResultMeta
+	if err := xdr.SafeUnmarshal(raw, &resultMeta); err != nil {
+		return nil, meta, fmt.Errorf("decode result meta: %w", err)
+	}
+	return resultMeta.FeeProcessing, resultMeta.TxApplyProcessing, nil
+}
+
+// ledgerEntryDataEqual compares two entries ignoring lastModifiedLedgerSeq,
+// which a local replay cannot reproduce.
+func ledgerEntryDataEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	var ea, eb xdr.LedgerEntry
+	if xdr.SafeUnmarshalBase64(a, &ea) != nil || xdr.SafeUnmarshalBase64(b, &eb) != nil {
+		return false
+	}
+	da, errA := ea.Data.MarshalBinary()
+	db, errB := eb.Data.MarshalBinary()
+	return errA == nil && errB == nil && bytes.Equal(da, db)
+}
+
+func describeScVal(b64 string) string {
+	if b64 == "" {
+		return "(none)"
+	}
+	var v xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(b64, &v); err != nil {
+		return b64
+	}
+	return v.String()
+}
+
+func describeEvent(b64 string) string {
+	if b64 == "" {
+		return "(absent)"
+	}
+	var e xdr.ContractEvent
+	if err := xdr.SafeUnmarshalBase64(b64, &e); err != nil || e.Body.V0 == nil {
+		return truncate(b64, 40)
+	}
+	topics := make([]string, 0, len(e.Body.V0.Topics))
+	for _, t := range e.Body.V0.Topics {
+		topics = append(topics, t.String())
+	}
+	return fmt.Sprintf("%v -> %s", topics, e.Body.V0.Data.String())
+}
+
+func describeLedgerKey(b64 string) string {
+	var k xdr.LedgerKey
+	if err := xdr.SafeUnmarshalBase64(b64, &k); err != nil {
+		return truncate(b64, 40)
+	}
+	return k.Type.String() + " " + truncate(b64, 24)
+}
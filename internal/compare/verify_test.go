@@ -0,0 +1,258 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package compare
+
+import (
+	"testing"
+
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ─── fixtures ────────────────────────────────────────────────────────────────
+
+func u32Val(n uint32) xdr.ScVal {
+	v := xdr.Uint32(n)
+	return xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &v}
+}
+
+func contractDataEntry(key string, val uint32, seq uint32) xdr.LedgerEntry {
+	id := xdr.ContractId{1}
+	sym := xdr.ScSymbol(key)
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: xdr.Uint32(seq),
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeContractData,
+			ContractData: &xdr.ContractDataEntry{
+				Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &id},
+				Key:        xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym},
+				Durability: xdr.ContractDataDurabilityPersistent,
+				Val:        u32Val(val),
+			},
+		},
+	}
+}
+
+func mustB64(t *testing.T, v interface{}) string {
+	t.Helper()
+	s, err := xdr.MarshalBase64(v)
+	require.NoError(t, err)
+	return s
+}
+
+func testEvent(n uint32) xdr.ContractEvent {
+	sym := xdr.ScSymbol("count")
+	return xdr.ContractEvent{
+		Type: xdr.ContractEventTypeContract,
+		Body: xdr.ContractEventBody{V: 0, V0: &xdr.ContractEventV0{
+			Topics: []xdr.ScVal{{Type: xdr.ScValTypeScvSymbol, Sym: &sym}},
+			Data:   u32Val(n),
+		}},
+	}
+}
+
+// onChainFixture builds a V3 meta where "counter" goes from 1 to 2 and
+// "scratch" is created then removed, plus an envelope declaring 1000
+// instructions.
+func onChainFixture(t *testing.T) (envelope, meta string) {
+	t.Helper()
+	before := contractDataEntry("counter", 1, 10)
+	after := contractDataEntry("counter", 2, 20)
+	scratch := contractDataEntry("scratch", 0, 20)
+	scratchKey, err := scratch.LedgerKey()
+	require.NoError(t, err)
+
+	changes := xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &before},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &after},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &scratch},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &scratchKey},
+	}
+	m := xdr.TransactionMeta{V: 3, V3: &xdr.TransactionMetaV3{
+		Operations: []xdr.OperationMeta{{Changes: changes}},
+		SorobanMeta: &xdr.SorobanTransactionMeta{
+			Events:      []xdr.ContractEvent{testEvent(2)},
+			ReturnValue: u32Val(2),
+		},
+	}}
+
+	env := xdr.TransactionEnvelope{Type: xdr.EnvelopeTypeEnvelopeTypeTx, V1: &xdr.TransactionV1Envelope{
+		Tx: xdr.Transaction{
+			SourceAccount: xdr.MustMuxedAddress("GC7ERFCD7QLDFRSEPLYB3GYSWX6GYMCHLDL45N4S5Q2N5EJDOMOJ63V4"),
+			Ext: xdr.TransactionExt{V: 1, SorobanData: &xdr.SorobanTransactionData{
+				Resources: xdr.SorobanResources{Instructions: 1000},
+			}},
+		},
+	}}
+	return mustB64(t, env), mustB64(t, m)
+}
+
+func matchingSim(t *testing.T) *simulator.SimulationResponse {
+	// The replay cannot reproduce lastModifiedLedgerSeq, so use a different one.
+	after := contractDataEntry("counter", 2, 99)
+	key, err := after.LedgerKey()
+	require.NoError(t, err)
+	return &simulator.SimulationResponse{
+		Status:         "success",
+		ReturnValue:    mustB64(t, u32Val(2)),
+		ContractEvents: []string{mustB64(t, testEvent(2))},
+		LedgerChanges: []simulator.LedgerEntryChange{{
+			KeyXdr: mustB64(t, key), Type: simulator.LedgerChangeUpdated, EntryXdr: mustB64(t, after),
+		}},
+		BudgetUsage: &simulator.BudgetUsage{CPUInstructions: 800},
+	}
+}
+
+// ─── ExtractOnChainResult ────────────────────────────────────────────────────
+
+func TestExtractOnChainResult(t *testing.T) {
+	env, meta := onChainFixture(t)
+	got, err := ExtractOnChainResult(env, "", meta)
+	require.NoError(t, err)
+
+	assert.True(t, got.Successful)
+	assert.Equal(t, uint64(1000), got.DeclaredInstructions)
+	assert.Equal(t, mustB64(t, u32Val(2)), got.ReturnValue)
+	assert.Len(t, got.ContractEvents, 1)
+	require.Len(t, got.LedgerChanges, 1, "created-then-removed entries must cancel out")
+	assert.Equal(t, simulator.LedgerChangeUpdated, got.LedgerChanges[0].Type)
+}
+
+func TestExtractOnChainResult_BadMeta(t *testing.T) {
+	_, err := ExtractOnChainResult("", "", "not-base64!")
+	assert.Error(t, err)
+}
+
+func TestStateBefore(t *testing.T) {
+	feeBefore := contractDataEntry("fee", 1, 5)
+	feeAfter := contractDataEntry("fee", 0, 20)
+	before := contractDataEntry("counter", 1, 10)
+	after := contractDataEntry("counter", 2, 20)
+	scratch := contractDataEntry("scratch", 0, 20)
+	archived := contractDataEntry("archived", 7, 3)
+	state := func(e xdr.LedgerEntry) xdr.LedgerEntryChange {
+		return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &e}
+	}
+	updated := func(e xdr.LedgerEntry) xdr.LedgerEntryChange {
+		return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &e}
+	}
+
+	resultMeta := xdr.TransactionResultMeta{
+		Result: xdr.TransactionResultPair{Result: xdr.TransactionResult{
+			Result: xdr.TransactionResultResult{Code: xdr.TransactionResultCodeTxSuccess, Results: &[]xdr.OperationResult{}},
+		}},
+		FeeProcessing: xdr.LedgerEntryChanges{state(feeBefore), updated(feeAfter)},
+		TxApplyProcessing: xdr.TransactionMeta{V: 3, V3: &xdr.TransactionMetaV3{
+			Operations: []xdr.OperationMeta{
+				{Changes: xdr.LedgerEntryChanges{
+					state(before), updated(after),
+					{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &scratch},
+					{Type: xdr.LedgerEntryChangeTypeLedgerEntryRestored, Restored: &archived},
+					state(feeAfter), updated(feeBefore),
+				}},
+				{Changes: xdr.LedgerEntryChanges{state(after), updated(before)}},
+			},
+		}},
+	}
+	got, err := StateBefore(mustB64(t, resultMeta))
+	require.NoError(t, err)
+
+	keyOf := func(e xdr.LedgerEntry) string {
+		key, err := e.LedgerKey()
+		require.NoError(t, err)
+		return mustB64(t, key)
+	}
+	assert.Equal(t, map[string]string{
+		keyOf(feeAfter): mustB64(t, feeAfter),
+		keyOf(before):   mustB64(t, before),
+		keyOf(scratch):  "",
+		keyOf(archived): mustB64(t, archived),
+	}, got)
+}
+
+// ─── Verify ──────────────────────────────────────────────────────────────────
+
+func TestVerify_AllMatch(t *testing.T) {
+	env, meta := onChainFixture(t)
+	onChain, err := ExtractOnChainResult(env, "", meta)
+	require.NoError(t, err)
+
+	report := Verify(onChain, matchingSim(t))
+	assert.True(t, report.Passed(), report.Mismatches)
+	assert.Empty(t, report.Mismatches)
+	require.Len(t, report.Checks, 5)
+	for _, c := range report.Checks {
+		assert.Equal(t, CheckMatch, c.Result, c.Dimension)
+	}
+}
+
+func TestVerify_ReportsEachDivergence(t *testing.T) {
+	env, meta := onChainFixture(t)
+	onChain, err := ExtractOnChainResult(env, "", meta)
+	require.NoError(t, err)
+
+	sim := matchingSim(t)
+	sim.ReturnValue = mustB64(t, u32Val(3))
+	sim.ContractEvents = append(sim.ContractEvents, mustB64(t, testEvent(3)))
+	sim.LedgerChanges[0].EntryXdr = mustB64(t, contractDataEntry("counter", 3, 20))
+	sim.BudgetUsage.CPUInstructions = 5000
+
+	report := Verify(onChain, sim)
+	assert.False(t, report.Passed())
+
+	dims := map[string]bool{}
+	for _, m := range report.Mismatches {
+		dims[m.Dimension] = true
+	}
+	assert.Equal(t, map[string]bool{
+		DimensionReturnValue:   true,
+		DimensionEvents:        true,
+		DimensionLedgerChanges: true,
+		DimensionResources:     true,
+	}, dims)
+	assert.True(t, report.Diverged(DimensionEvents))
+	assert.False(t, report.Diverged(DimensionStatus))
+}
+
+func TestVerify_StatusMismatch(t *testing.T) {
+	report := Verify(&OnChainResult{Successful: true}, &simulator.SimulationResponse{Status: "error", Error: "trap"})
+	require.Len(t, report.Mismatches, 1)
+	assert.Equal(t, DimensionStatus, report.Mismatches[0].Dimension)
+	assert.Equal(t, "error: trap", report.Mismatches[0].Simulated)
+}
+
+func TestVerify_SkipsUnreportedDimensions(t *testing.T) {
+	report := Verify(&OnChainResult{Successful: true, ReturnValue: "AAAAAQ=="}, &simulator.SimulationResponse{Status: "success"})
+	assert.True(t, report.Passed())
+	skipped := 0
+	for _, c := range report.Checks {
+		if c.Result == CheckSkipped {
+			skipped++
+		}
+	}
+	assert.Equal(t, 4, skipped)
+}
+
+func TestVerify_EventCountFallback(t *testing.T) {
+	onChain := &OnChainResult{Successful: true, ContractEvents: []string{"a", "b"}}
+	sim := &simulator.SimulationResponse{
+		Status: "success",
+		DiagnosticEvents: []simulator.DiagnosticEvent{
+			{EventType: "contract"}, {EventType: "diagnostic"},
+		},
+	}
+	report := Verify(onChain, sim)
+	require.Len(t, report.Mismatches, 1)
+	assert.Equal(t, "count", report.Mismatches[0].Subject)
+	assert.Equal(t, "2", report.Mismatches[0].OnChain)
+	assert.Equal(t, "1", report.Mismatches[0].Simulated)
+}
+
+func TestRenderVerify_NoPanic(t *testing.T) {
+	report := Verify(&OnChainResult{}, &simulator.SimulationResponse{Status: "success"})
+	assert.NotPanics(t, func() { RenderVerify(report) })
+	assert.NotPanics(t, func() { RenderVerify(nil) })
+}
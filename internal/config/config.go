@@ -151,13 +151,7 @@ func Load() (*Config, error) {
 }
 
 func (c *Config) loadFromFile() error {
-	paths := []string{
-		".erst.toml",
-		filepath.Join(os.ExpandEnv("$HOME"), ".erst.toml"),
-		"/etc/erst/config.toml",
-	}
-
-	for _, path := range paths {
+	for _, path := range Paths {
 		if err := c.loadTOML(path); err == nil {
 			return nil
 		}
@@ -179,27 +173,17 @@ func (c *Config) loadTOML(path string) error {
 	return c.parseTOML(string(data))
 }
 
+// parseTOML applies the keys of the root table; other tables, such as the
+// security tables, belong to other packages.
 func (c *Config) parseTOML(content string) error {
-	lines := strings.Split(content, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-
-		key := strings.TrimSpace(parts[0])
-		rawVal := strings.TrimSpace(parts[1])
-
-		if key == "rpc_urls" && strings.HasPrefix(rawVal, "[") && strings.HasSuffix(rawVal, "]") {
+	tables, err := ParseTOML(content)
+	if err != nil {
+		return err
+	}
+	for _, k := range tables[0].Keys {
+		if k.Name == "rpc_urls" && strings.HasPrefix(k.Value, "[") && strings.HasSuffix(k.Value, "]") {
 			// Basic array parsing for TOML-like lists: ["a", "b"]
-			rawVal = strings.Trim(rawVal, "[]")
-			parts := strings.Split(rawVal, ",")
+			parts := strings.Split(strings.Trim(k.Value, "[]"), ",")
 			var urls []string
 			for _, p := range parts {
 				urls = append(urls, strings.Trim(strings.TrimSpace(p), "\"'"))
@@ -208,9 +192,9 @@ func (c *Config) parseTOML(content string) error {
 			continue
 		}
 
-		value := strings.Trim(rawVal, "\"'")
+		value := k.String()
 
-		switch key {
+		switch k.Name {
 		case "rpc_url":
 			c.RpcUrl = value
 		case "rpc_urls":
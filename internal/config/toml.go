@@ -0,0 +1,120 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Paths are the configuration files Load and LoadTOML search, in order; the
+// first that exists is used. erst.toml is the project file written by
+// erst init.
+var Paths = []string{
+	"erst.toml",
+	".erst.toml",
+	filepath.Join(os.ExpandEnv("$HOME"), ".erst.toml"),
+	"/etc/erst/config.toml",
+}
+
+// Table is one table of a TOML file: the root table (Name ""), a [name]
+// table or one element of a [[name]] array of tables.
+type Table struct {
+	Name string
+	// Array is set for elements of an array of tables.
+	Array bool
+	Line  int
+	Keys  []Key
+}
+
+// Key is a key/value pair. Value is kept as written in the file.
+type Key struct {
+	Name  string
+	Value string
+	Line  int
+}
+
+// String unquotes a basic or literal string value; other values are
+// returned as written.
+func (k Key) String() string {
+	if strings.HasPrefix(k.Value, `"`) {
+		if s, err := strconv.Unquote(k.Value); err == nil {
+			return s
+		}
+	}
+	return strings.Trim(k.Value, "'")
+}
+
+// ParseTOML splits TOML content into its tables, root table first. It reads
+// the subset erst's configuration uses: comments, table and array-of-table
+// headers, and single-line key = value pairs.
+func ParseTOML(content string) ([]Table, error) {
+	tables := []Table{{}}
+	for i, line := range strings.Split(content, "\n") {
+		lineNo := i + 1
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[[") && strings.HasSuffix(line, "]]") {
+			tables = append(tables, Table{Name: strings.TrimSpace(line[2 : len(line)-2]), Array: true, Line: lineNo})
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			tables = append(tables, Table{Name: strings.TrimSpace(line[1 : len(line)-1]), Line: lineNo})
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		t := &tables[len(tables)-1]
+		t.Keys = append(t.Keys, Key{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value), Line: lineNo})
+	}
+	return tables, nil
+}
+
+// LoadTOML reads and parses the first file in Paths that exists and returns
+// its tables with the path it was read from. Without any file it returns no
+// tables and an empty path.
+func LoadTOML() ([]Table, string, error) {
+	for _, path := range Paths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, path, err
+		}
+		tables, err := ParseTOML(string(data))
+		if err != nil {
+			return nil, path, fmt.Errorf("%s: %w", path, err)
+		}
+		return tables, path, nil
+	}
+	return nil, "", nil
+}
+
+// stripComment removes a trailing # comment outside of quotes.
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTOMLTables(t *testing.T) {
+	tables, err := ParseTOML(`network = "testnet" # default
+
+[security]
+min_severity = "low"
+
+[[security.suppress]]
+reason = "a # in a string"
+
+[[security.suppress]]
+rule = 'SEC008'
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tables) != 4 {
+		t.Fatalf("expected 4 tables, got %d", len(tables))
+	}
+	if tables[0].Name != "" || len(tables[0].Keys) != 1 || tables[0].Keys[0].String() != "testnet" {
+		t.Errorf("unexpected root table: %+v", tables[0])
+	}
+	if tables[1].Name != "security" || tables[1].Array || tables[1].Line != 3 {
+		t.Errorf("unexpected security table: %+v", tables[1])
+	}
+	if !tables[2].Array || tables[2].Keys[0].String() != "a # in a string" {
+		t.Errorf("unexpected first suppress table: %+v", tables[2])
+	}
+	if k := tables[3].Keys[0]; k.Name != "rule" || k.String() != "SEC008" || k.Line != 10 {
+		t.Errorf("unexpected second suppress key: %+v", k)
+	}
+
+	if _, err := ParseTOML("[security]\nmin_severity"); err == nil {
+		t.Error("expected an error for a line without a value")
+	}
+}
+
+func TestParseTOML_IgnoresOtherTables(t *testing.T) {
+	cfg := &Config{}
+	if err := cfg.parseTOML("network = \"testnet\"\n\n[security.rules.SEC001]\nseverity = \"low\"\nnetwork = \"public\""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Network != NetworkTestnet {
+		t.Errorf("expected network from the root table, got %s", cfg.Network)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	dir := t.TempDir()
+	saved := Paths
+	t.Cleanup(func() { Paths = saved })
+	Paths = []string{filepath.Join(dir, "missing.toml"), filepath.Join(dir, "erst.toml")}
+
+	tables, path, err := LoadTOML()
+	if err != nil || path != "" || tables != nil {
+		t.Fatalf("expected nothing without a file, got %v %q %v", tables, path, err)
+	}
+
+	if err := os.WriteFile(Paths[1], []byte("[security]\nmin_severity = \"low\""), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	tables, path, err = LoadTOML()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != Paths[1] || len(tables) != 2 {
+		t.Errorf("expected both tables from %s, got %d from %q", Paths[1], len(tables), path)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/wasm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -42,12 +43,12 @@ func (b *testModuleBuilder) addFuncType() *testModuleBuilder {
 // addFuncImport adds a function import.
 func (b *testModuleBuilder) addFuncImport(module, name string, typeIdx uint32) *testModuleBuilder {
 	var entry []byte
-	entry = append(entry, encodeULEB128(uint32(len(module)))...)
+	entry = append(entry, wasm.AppendU32(nil, uint32(len(module)))...)
 	entry = append(entry, []byte(module)...)
-	entry = append(entry, encodeULEB128(uint32(len(name)))...)
+	entry = append(entry, wasm.AppendU32(nil, uint32(len(name)))...)
 	entry = append(entry, []byte(name)...)
-	entry = append(entry, importKindFunc)
-	entry = append(entry, encodeULEB128(typeIdx)...)
+	entry = append(entry, byte(wasm.KindFunc))
+	entry = append(entry, wasm.AppendU32(nil, typeIdx)...)
 	b.imports = append(b.imports, entry)
 	return b
 }
@@ -67,10 +68,10 @@ func (b *testModuleBuilder) addFunction(typeIdx uint32, bodyInstructions []byte)
 // addExport adds a function export.
 func (b *testModuleBuilder) addExport(name string, funcIdx uint32) *testModuleBuilder {
 	var entry []byte
-	entry = append(entry, encodeULEB128(uint32(len(name)))...)
+	entry = append(entry, wasm.AppendU32(nil, uint32(len(name)))...)
 	entry = append(entry, []byte(name)...)
-	entry = append(entry, exportKindFunc)
-	entry = append(entry, encodeULEB128(funcIdx)...)
+	entry = append(entry, byte(wasm.KindFunc))
+	entry = append(entry, wasm.AppendU32(nil, funcIdx)...)
 	b.exports = append(b.exports, entry)
 	return b
 }
@@ -85,13 +86,13 @@ func (b *testModuleBuilder) setStart(funcIdx uint32) *testModuleBuilder {
 func (b *testModuleBuilder) addElementSegment(funcIdxs []uint32) *testModuleBuilder {
 	var entry []byte
 	// table index 0
-	entry = append(entry, encodeULEB128(0)...)
+	entry = append(entry, wasm.AppendU32(nil, 0)...)
 	// offset expr: i32.const 0, end
 	entry = append(entry, 0x41, 0x00, 0x0b)
 	// func indices
-	entry = append(entry, encodeULEB128(uint32(len(funcIdxs)))...)
+	entry = append(entry, wasm.AppendU32(nil, uint32(len(funcIdxs)))...)
 	for _, idx := range funcIdxs {
-		entry = append(entry, encodeULEB128(idx)...)
+		entry = append(entry, wasm.AppendU32(nil, idx)...)
 	}
 	b.elements = append(b.elements, entry)
 	return b
@@ -100,7 +101,7 @@ func (b *testModuleBuilder) addElementSegment(funcIdxs []uint32) *testModuleBuil
 // addCustomSection adds a custom section with the given name and payload.
 func (b *testModuleBuilder) addCustomSection(name string, payload []byte) *testModuleBuilder {
 	var sec []byte
-	sec = append(sec, encodeULEB128(uint32(len(name)))...)
+	sec = append(sec, wasm.AppendU32(nil, uint32(len(name)))...)
 	sec = append(sec, []byte(name)...)
 	sec = append(sec, payload...)
 	b.custom = append(b.custom, sec)
@@ -125,94 +126,94 @@ func (b *testModuleBuilder) addMemory() *testModuleBuilder {
 func (b *testModuleBuilder) build() []byte {
 	out := make([]byte, 0, 256)
 	// Header
-	out = append(out, wasmMagic...)
+	out = append(out, wasm.Magic...)
 	out = append(out, 0x01, 0x00, 0x00, 0x00)
 
-	emitSection := func(id byte, payload []byte) {
-		out = append(out, id)
-		out = append(out, encodeULEB128(uint32(len(payload)))...)
+	emitSection := func(id wasm.SectionID, payload []byte) {
+		out = append(out, byte(id))
+		out = append(out, wasm.AppendU32(nil, uint32(len(payload)))...)
 		out = append(out, payload...)
 	}
 
 	// Type section
 	if len(b.types) > 0 {
 		var payload []byte
-		payload = append(payload, encodeULEB128(uint32(len(b.types)))...)
+		payload = append(payload, wasm.AppendU32(nil, uint32(len(b.types)))...)
 		for _, t := range b.types {
 			payload = append(payload, t...)
 		}
-		emitSection(sectionType, payload)
+		emitSection(wasm.SectionType, payload)
 	}
 
 	// Import section
 	if len(b.imports) > 0 {
 		var payload []byte
-		payload = append(payload, encodeULEB128(uint32(len(b.imports)))...)
+		payload = append(payload, wasm.AppendU32(nil, uint32(len(b.imports)))...)
 		for _, imp := range b.imports {
 			payload = append(payload, imp...)
 		}
-		emitSection(sectionImport, payload)
+		emitSection(wasm.SectionImport, payload)
 	}
 
 	// Function section
 	if len(b.funcIdxs) > 0 {
 		var payload []byte
-		payload = append(payload, encodeULEB128(uint32(len(b.funcIdxs)))...)
+		payload = append(payload, wasm.AppendU32(nil, uint32(len(b.funcIdxs)))...)
 		for _, idx := range b.funcIdxs {
-			payload = append(payload, encodeULEB128(idx)...)
+			payload = append(payload, wasm.AppendU32(nil, idx)...)
 		}
-		emitSection(sectionFunction, payload)
+		emitSection(wasm.SectionFunction, payload)
 	}
 
 	// Table section
 	if b.tables != nil {
-		emitSection(sectionTable, b.tables)
+		emitSection(wasm.SectionTable, b.tables)
 	}
 
 	// Memory section
 	if b.memories != nil {
-		emitSection(sectionMemory, b.memories)
+		emitSection(wasm.SectionMemory, b.memories)
 	}
 
 	// Export section
 	if len(b.exports) > 0 {
 		var payload []byte
-		payload = append(payload, encodeULEB128(uint32(len(b.exports)))...)
+		payload = append(payload, wasm.AppendU32(nil, uint32(len(b.exports)))...)
 		for _, exp := range b.exports {
 			payload = append(payload, exp...)
 		}
-		emitSection(sectionExport, payload)
+		emitSection(wasm.SectionExport, payload)
 	}
 
 	// Start section
 	if b.start != nil {
-		emitSection(sectionStart, encodeULEB128(*b.start))
+		emitSection(wasm.SectionStart, wasm.AppendU32(nil, *b.start))
 	}
 
 	// Element section
 	if len(b.elements) > 0 {
 		var payload []byte
-		payload = append(payload, encodeULEB128(uint32(len(b.elements)))...)
+		payload = append(payload, wasm.AppendU32(nil, uint32(len(b.elements)))...)
 		for _, elem := range b.elements {
 			payload = append(payload, elem...)
 		}
-		emitSection(sectionElement, payload)
+		emitSection(wasm.SectionElement, payload)
 	}
 
 	// Code section
 	if len(b.bodies) > 0 {
 		var payload []byte
-		payload = append(payload, encodeULEB128(uint32(len(b.bodies)))...)
+		payload = append(payload, wasm.AppendU32(nil, uint32(len(b.bodies)))...)
 		for _, body := range b.bodies {
-			payload = append(payload, encodeULEB128(uint32(len(body)))...)
+			payload = append(payload, wasm.AppendU32(nil, uint32(len(body)))...)
 			payload = append(payload, body...)
 		}
-		emitSection(sectionCode, payload)
+		emitSection(wasm.SectionCode, payload)
 	}
 
 	// Custom sections
 	for _, sec := range b.custom {
-		emitSection(sectionCustom, sec)
+		emitSection(wasm.SectionCustom, sec)
 	}
 
 	return out
@@ -224,7 +225,7 @@ func (b *testModuleBuilder) build() []byte {
 
 func TestEliminate_NoDeadCode(t *testing.T) {
 	// Two functions, both exported. No dead code.
-	wasm := newTestModule().
+	bin := newTestModule().
 		addFuncType().
 		addFunction(0, []byte{0x01}).       // func 0: nop
 		addFunction(0, []byte{0x10, 0x00}). // func 1: call func 0
@@ -232,24 +233,24 @@ func TestEliminate_NoDeadCode(t *testing.T) {
 		addExport("f1", 1).
 		build()
 
-	out, stats, err := Eliminate(wasm)
+	out, stats, err := Eliminate(bin)
 	require.NoError(t, err)
 	assert.Equal(t, 0, stats.RemovedFunctions)
 	assert.Equal(t, 2, stats.TotalFunctions)
-	assert.Equal(t, wasm, out, "output should be identical when no dead code")
+	assert.Equal(t, bin, out, "output should be identical when no dead code")
 }
 
 func TestEliminate_SingleDeadFunction(t *testing.T) {
 	// Three functions: 0 (exported), 1 (called by 0), 2 (dead).
-	wasm := newTestModule().
+	bin := newTestModule().
 		addFuncType().
 		addFunction(0, []byte{0x10, 0x01}). // func 0: call func 1
-		addFunction(0, []byte{0x01}).        // func 1: nop
-		addFunction(0, []byte{0x01}).        // func 2: nop (dead)
+		addFunction(0, []byte{0x01}).       // func 1: nop
+		addFunction(0, []byte{0x01}).       // func 2: nop (dead)
 		addExport("main", 0).
 		build()
 
-	out, stats, err := Eliminate(wasm)
+	out, stats, err := Eliminate(bin)
 	require.NoError(t, err)
 	assert.Equal(t, 1, stats.RemovedFunctions)
 	assert.Equal(t, 3, stats.TotalFunctions)
@@ -262,16 +263,16 @@ func TestEliminate_SingleDeadFunction(t *testing.T) {
 
 func TestEliminate_ChainedCalls(t *testing.T) {
 	// A -> B -> C chain. Only A is exported. B and C should survive.
-	wasm := newTestModule().
+	bin := newTestModule().
 		addFuncType().
 		addFunction(0, []byte{0x10, 0x01}). // func 0: call func 1
 		addFunction(0, []byte{0x10, 0x02}). // func 1: call func 2
-		addFunction(0, []byte{0x01}).        // func 2: nop
-		addFunction(0, []byte{0x01}).        // func 3: nop (dead)
+		addFunction(0, []byte{0x01}).       // func 2: nop
+		addFunction(0, []byte{0x01}).       // func 3: nop (dead)
 		addExport("main", 0).
 		build()
 
-	out, stats, err := Eliminate(wasm)
+	out, stats, err := Eliminate(bin)
 	require.NoError(t, err)
 	assert.Equal(t, 1, stats.RemovedFunctions)
 	assert.Equal(t, 4, stats.TotalFunctions)
@@ -285,28 +286,28 @@ func TestEliminate_ChainedCalls(t *testing.T) {
 
 func TestEliminate_AllDead(t *testing.T) {
 	// No exports, no start, no elements. All locals should be removed.
-	wasm := newTestModule().
+	bin := newTestModule().
 		addFuncType().
 		addFunction(0, []byte{0x01}). // func 0: nop (dead)
 		addFunction(0, []byte{0x01}). // func 1: nop (dead)
 		build()
 
-	out, stats, err := Eliminate(wasm)
+	out, stats, err := Eliminate(bin)
 	require.NoError(t, err)
 	assert.Equal(t, 2, stats.RemovedFunctions)
 	assert.Equal(t, 2, stats.TotalFunctions)
 	assert.Less(t, stats.OptimizedSize, stats.OriginalSize)
 
 	// Output should still be valid WASM.
-	mod, err := parseModule(out)
+	mod, err := wasm.Parse(out)
 	require.NoError(t, err)
-	assert.Empty(t, mod.Bodies)
-	assert.Empty(t, mod.FuncTypes)
+	assert.Empty(t, mod.Code)
+	assert.Empty(t, mod.Functions)
 }
 
 func TestEliminate_IndirectCallPreservation(t *testing.T) {
 	// Func 1 is referenced in element segment but not directly called or exported.
-	wasm := newTestModule().
+	bin := newTestModule().
 		addFuncType().
 		addTable().
 		addFunction(0, []byte{0x01}). // func 0: nop (dead - no export)
@@ -315,127 +316,121 @@ func TestEliminate_IndirectCallPreservation(t *testing.T) {
 		addElementSegment([]uint32{1}).
 		build()
 
-	out, stats, err := Eliminate(wasm)
+	out, stats, err := Eliminate(bin)
 	require.NoError(t, err)
 	assert.Equal(t, 2, stats.RemovedFunctions, "funcs 0 and 2 should be removed")
 
 	// Verify func 1 survived.
-	mod, err := parseModule(out)
+	mod, err := wasm.Parse(out)
 	require.NoError(t, err)
-	assert.Equal(t, 1, len(mod.Bodies))
+	assert.Equal(t, 1, len(mod.Code))
 }
 
 func TestEliminate_StartFunctionPreservation(t *testing.T) {
 	// Start function should be preserved even without exports.
 	startIdx := uint32(1)
-	wasm := newTestModule().
+	bin := newTestModule().
 		addFuncType().
 		addFunction(0, []byte{0x01}). // func 0: nop (dead)
 		addFunction(0, []byte{0x01}). // func 1: nop (start)
 		setStart(startIdx).
 		build()
 
-	out, stats, err := Eliminate(wasm)
+	out, stats, err := Eliminate(bin)
 	require.NoError(t, err)
 	assert.Equal(t, 1, stats.RemovedFunctions)
 
-	mod, err := parseModule(out)
+	mod, err := wasm.Parse(out)
 	require.NoError(t, err)
-	require.NotNil(t, mod.StartFunc)
-	assert.Equal(t, uint32(0), *mod.StartFunc, "start should be reindexed to 0")
+	require.NotNil(t, mod.Start)
+	assert.Equal(t, uint32(0), *mod.Start, "start should be reindexed to 0")
 }
 
 func TestEliminate_ImportedFunctionCalls(t *testing.T) {
 	// Import func 0, local func 1 calls it, local func 2 is dead.
-	wasm := newTestModule().
+	bin := newTestModule().
 		addFuncType().
 		addFuncImport("env", "log", 0).
 		addFunction(0, []byte{0x10, 0x00}). // func 1 (local 0): call import func 0
-		addFunction(0, []byte{0x01}).        // func 2 (local 1): nop (dead)
+		addFunction(0, []byte{0x01}).       // func 2 (local 1): nop (dead)
 		addExport("main", 1).
 		build()
 
-	out, stats, err := Eliminate(wasm)
+	out, stats, err := Eliminate(bin)
 	require.NoError(t, err)
 	assert.Equal(t, 1, stats.RemovedFunctions)
 	assert.Equal(t, 3, stats.TotalFunctions) // 1 import + 2 local
 
 	// Verify the import is still there.
-	mod, err := parseModule(out)
+	mod, err := wasm.Parse(out)
 	require.NoError(t, err)
 	assert.Equal(t, 1, len(mod.Imports))
-	assert.Equal(t, 1, len(mod.Bodies))
+	assert.Equal(t, 1, len(mod.Code))
 }
 
 func TestEliminate_IndexRewriting(t *testing.T) {
 	// func 0 (exported, calls func 2), func 1 (dead), func 2 (kept).
 	// After removal of func 1, func 2 becomes func 1.
 	// The call in func 0 should be rewritten from 2 to 1.
-	wasm := newTestModule().
+	bin := newTestModule().
 		addFuncType().
 		addFunction(0, []byte{0x10, 0x02}). // func 0: call func 2
-		addFunction(0, []byte{0x01}).        // func 1: nop (dead)
-		addFunction(0, []byte{0x01}).        // func 2: nop (kept)
+		addFunction(0, []byte{0x01}).       // func 1: nop (dead)
+		addFunction(0, []byte{0x01}).       // func 2: nop (kept)
 		addExport("main", 0).
 		build()
 
-	out, stats, err := Eliminate(wasm)
+	out, stats, err := Eliminate(bin)
 	require.NoError(t, err)
 	assert.Equal(t, 1, stats.RemovedFunctions)
 
 	// Parse the output and check that func 0's body calls func 1 (not 2).
-	mod, err := parseModule(out)
+	mod, err := wasm.Parse(out)
 	require.NoError(t, err)
-	require.Equal(t, 2, len(mod.Bodies))
+	require.Equal(t, 2, len(mod.Code))
 
-	targets := scanCalls(mod.Bodies[0].Raw)
-	assert.True(t, targets[1], "func 0 should now call func 1")
-	assert.False(t, targets[2], "func 0 should not reference old index 2")
+	targets, err := wasm.FuncRefs(mod.Code[0].Body)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{1}, targets, "func 0 should now call func 1, not old index 2")
 }
 
 func TestEliminate_CustomSectionsPreserved(t *testing.T) {
 	customPayload := []byte{0xDE, 0xAD, 0xBE, 0xEF}
-	wasm := newTestModule().
+	bin := newTestModule().
 		addFuncType().
 		addFunction(0, []byte{0x01}).
 		addExport("main", 0).
 		addCustomSection("mydata", customPayload).
 		build()
 
-	out, _, err := Eliminate(wasm)
+	out, _, err := Eliminate(bin)
 	require.NoError(t, err)
 
 	// Parse output and check custom section is preserved.
-	mod, err := parseModule(out)
+	mod, err := wasm.Parse(out)
 	require.NoError(t, err)
 
-	found := false
-	for _, s := range mod.PassthroughSections {
-		if s.ID == sectionCustom {
-			found = true
-			// Payload should contain our custom data.
-			assert.Contains(t, string(s.Payload), string(customPayload))
-		}
-	}
+	data, found := mod.Custom("mydata")
 	assert.True(t, found, "custom section should be preserved")
+	assert.Equal(t, customPayload, data)
 }
 
 func TestEliminate_ExportReindexing(t *testing.T) {
 	// func 0 (dead), func 1 (exported as "main"), func 2 (exported as "helper").
-	wasm := newTestModule().
+	bin := newTestModule().
 		addFuncType().
-		addFunction(0, []byte{0x01}).        // func 0: nop (dead)
+		addFunction(0, []byte{0x01}).       // func 0: nop (dead)
 		addFunction(0, []byte{0x10, 0x02}). // func 1: call func 2
-		addFunction(0, []byte{0x01}).        // func 2: nop
+		addFunction(0, []byte{0x01}).       // func 2: nop
 		addExport("main", 1).
 		addExport("helper", 2).
 		build()
 
-	out, stats, err := Eliminate(wasm)
+	out, stats, err := Eliminate(bin)
 	require.NoError(t, err)
 	assert.Equal(t, 1, stats.RemovedFunctions)
 
-	mod, err := parseModule(out)
+	mod, err := wasm.Parse(out)
 	require.NoError(t, err)
 
 	// Exports should be reindexed: main=0, helper=1.
@@ -457,25 +452,25 @@ func TestEliminate_InvalidWasm(t *testing.T) {
 
 func TestEliminate_EmptyModule(t *testing.T) {
 	// Valid WASM with no functions at all.
-	wasm := newTestModule().build()
+	bin := newTestModule().build()
 
-	out, stats, err := Eliminate(wasm)
+	out, stats, err := Eliminate(bin)
 	require.NoError(t, err)
 	assert.Equal(t, 0, stats.TotalFunctions)
 	assert.Equal(t, 0, stats.RemovedFunctions)
-	assert.Equal(t, wasm, out)
+	assert.Equal(t, bin, out)
 }
 
 func TestEliminate_Idempotent(t *testing.T) {
-	wasm := newTestModule().
+	bin := newTestModule().
 		addFuncType().
 		addFunction(0, []byte{0x10, 0x01}). // func 0: call func 1
-		addFunction(0, []byte{0x01}).        // func 1: nop
-		addFunction(0, []byte{0x01}).        // func 2: nop (dead)
+		addFunction(0, []byte{0x01}).       // func 1: nop
+		addFunction(0, []byte{0x01}).       // func 2: nop (dead)
 		addExport("main", 0).
 		build()
 
-	out1, stats1, err := Eliminate(wasm)
+	out1, stats1, err := Eliminate(bin)
 	require.NoError(t, err)
 	assert.Equal(t, 1, stats1.RemovedFunctions)
 
@@ -486,7 +481,7 @@ func TestEliminate_Idempotent(t *testing.T) {
 }
 
 func TestEliminate_Stats(t *testing.T) {
-	wasm := newTestModule().
+	bin := newTestModule().
 		addFuncType().
 		addFuncImport("env", "log", 0).
 		addFunction(0, []byte{0x01}). // func 1 (local 0): nop (kept)
@@ -495,72 +490,74 @@ func TestEliminate_Stats(t *testing.T) {
 		addExport("main", 1).
 		build()
 
-	out, stats, err := Eliminate(wasm)
+	out, stats, err := Eliminate(bin)
 	require.NoError(t, err)
 
 	assert.Equal(t, 4, stats.TotalFunctions)   // 1 import + 3 local
-	assert.Equal(t, 2, stats.RemovedFunctions)  // 2 dead locals
-	assert.Equal(t, len(wasm), stats.OriginalSize)
+	assert.Equal(t, 2, stats.RemovedFunctions) // 2 dead locals
+	assert.Equal(t, len(bin), stats.OriginalSize)
 	assert.Equal(t, len(out), stats.OptimizedSize)
 	assert.Less(t, stats.OptimizedSize, stats.OriginalSize)
 }
 
-// =============================================================================
-// Internal function tests
-// =============================================================================
-
-func TestScanCalls_MultipleCalls(t *testing.T) {
-	// Body with calls to func 3 and func 7.
-	body := []byte{
-		0x00,       // 0 local declarations
-		0x10, 0x03, // call 3
-		0x10, 0x07, // call 7
-		0x0b,       // end
-	}
-	targets := scanCalls(body)
-	assert.True(t, targets[3])
-	assert.True(t, targets[7])
-	assert.Equal(t, 2, len(targets))
-}
-
-func TestScanCalls_NoCalls(t *testing.T) {
-	body := []byte{
-		0x00, // 0 local declarations
-		0x01, // nop
-		0x0b, // end
+func TestEliminate_RefFuncRoots(t *testing.T) {
+	// func 0 is exported and takes a reference to func 2; func 3 is only
+	// referenced by an expression element segment and func 4 by a global.
+	mod := &wasm.Module{
+		Types:     []wasm.FuncType{{}},
+		Functions: []uint32{0, 0, 0, 0, 0},
+		Tables:    []byte{0x01, byte(wasm.FuncRef), 0x00, 0x01},
+		Globals: []wasm.Global{
+			{Type: wasm.FuncRef, Init: []byte{wasm.OpRefFunc, 0x04, wasm.OpEnd}},
+		},
+		Exports: []wasm.Export{{Name: "main", Kind: wasm.KindFunc, Index: 0}},
+		Elements: []wasm.Element{
+			{Flags: 5, Kind: byte(wasm.FuncRef), Exprs: [][]byte{{wasm.OpRefFunc, 0x03, wasm.OpEnd}}},
+		},
+		Code: []wasm.Func{
+			{Body: []byte{wasm.OpRefFunc, 0x02, 0x1a, wasm.OpEnd}},
+			{Body: []byte{wasm.OpEnd}}, // dead
+			{Body: []byte{wasm.OpEnd}},
+			{Body: []byte{wasm.OpEnd}},
+			{Body: []byte{wasm.OpEnd}},
+		},
 	}
-	targets := scanCalls(body)
-	assert.Empty(t, targets)
-}
-
-func TestRoundTrip_ParseAssemble(t *testing.T) {
-	// Build a module, parse it, reassemble, and verify identical output.
-	wasm := newTestModule().
-		addFuncType().
-		addFunction(0, []byte{0x01}).
-		addExport("main", 0).
-		build()
+	bin, err := mod.Encode()
+	require.NoError(t, err)
 
-	mod, err := parseModule(wasm)
+	out, stats, err := Eliminate(bin)
 	require.NoError(t, err)
+	assert.Equal(t, 1, stats.RemovedFunctions)
 
-	out, err := assemble(mod)
+	got, err := wasm.Parse(out)
 	require.NoError(t, err)
-	assert.Equal(t, wasm, out, "round-trip should produce identical binary")
+	require.Len(t, got.Code, 4)
+	assert.Equal(t, []byte{wasm.OpRefFunc, 0x01, 0x1a, wasm.OpEnd}, got.Code[0].Body)
+	assert.Equal(t, []byte{wasm.OpRefFunc, 0x02, wasm.OpEnd}, got.Elements[0].Exprs[0])
+	assert.Equal(t, []byte{wasm.OpRefFunc, 0x03, wasm.OpEnd}, got.Globals[0].Init)
 }
 
-func TestRoundTrip_WithImports(t *testing.T) {
-	wasm := newTestModule().
+func TestReachableFrom_FollowsIndirectCalls(t *testing.T) {
+	// Func 0 calls func 1 directly; func 2 makes an indirect call, which may
+	// reach func 3 through the table. Func 4 is only reachable from itself.
+	bin := newTestModule().
 		addFuncType().
-		addFuncImport("env", "abort", 0).
-		addFunction(0, []byte{0x10, 0x00}). // call imported func
-		addExport("main", 1).
+		addTable().
+		addFunction(0, []byte{0x10, 0x01}).                   // func 0: call 1
+		addFunction(0, []byte{0x01}).                         // func 1: nop
+		addFunction(0, []byte{0x41, 0x00, 0x11, 0x00, 0x00}). // func 2: call_indirect
+		addFunction(0, []byte{0x01}).                         // func 3: nop
+		addFunction(0, []byte{0x01}).                         // func 4: nop
+		addElementSegment([]uint32{3}).
 		build()
+	mod, err := wasm.Parse(bin)
+	require.NoError(t, err)
 
-	mod, err := parseModule(wasm)
+	fromZero, err := ReachableFrom(mod, []uint32{0})
 	require.NoError(t, err)
+	assert.Equal(t, map[uint32]bool{0: true, 1: true}, fromZero)
 
-	out, err := assemble(mod)
+	fromTwo, err := ReachableFrom(mod, []uint32{2})
 	require.NoError(t, err)
-	assert.Equal(t, wasm, out, "round-trip with imports should be identical")
+	assert.Equal(t, map[uint32]bool{2: true, 3: true}, fromTwo)
 }
@@ -27,6 +27,9 @@ type DecodedEvent struct {
 	ContractID string   `json:"contract_id"`
 	Topics     []string `json:"topics"`
 	Data       string   `json:"data"`
+	// Index is the event's position in the decoded event list, which orders
+	// events against the calls around them.
+	Index int `json:"index"`
 }
 
 // DecodeEvents builds a call hierarchy from a list of base64-encoded XDR DiagnosticEvents
@@ -37,7 +40,7 @@ func DecodeEvents(eventsXdr []string) (*CallNode, error) {
 	}
 	current := root
 
-	for _, eventStr := range eventsXdr {
+	for i, eventStr := range eventsXdr {
 		var diag xdr.DiagnosticEvent
 		data, err := base64.StdEncoding.DecodeString(eventStr)
 		if err != nil {
@@ -48,13 +51,14 @@ func DecodeEvents(eventsXdr []string) (*CallNode, error) {
 		}
 
 		decoded := parseEvent(diag)
+		decoded.Index = i
 
 		// Check for call/return markers in topics
 		// Convention: System events with topics ["fn_call", func_name, ...]
 		// Note: This relies on the environment emitting these diagnostic events.
 		if isFunctionCall(decoded) {
 			child := &CallNode{
-				ContractID: decoded.ContractID,
+				ContractID: calledContract(decoded),
 				Function:   extractFunctionName(decoded),
 				parent:     current,
 			}
@@ -115,6 +119,8 @@ func parseEvent(diag xdr.DiagnosticEvent) DecodedEvent {
 		// Attempt to convert to string if symbol, otherwise hex/debug
 		if topic.Type == xdr.ScValTypeScvSymbol {
 			topics = append(topics, string(*topic.Sym))
+		} else if topic.Type == xdr.ScValTypeScvBytes {
+			topics = append(topics, hex.EncodeToString(*topic.Bytes))
 		} else {
 			// Fallback for other types
 			topics = append(topics, fmt.Sprintf("%v", topic.Type))
@@ -139,13 +145,29 @@ func isFunctionReturn(e DecodedEvent) bool {
 	return len(e.Topics) > 0 && e.Topics[0] == "fn_return"
 }
 
+// extractFunctionName reads the function from a call or return event. The
+// host emits fn_call as [fn_call, callee contract ID, function] with no
+// contract ID on the event itself, and fn_return as [fn_return, function]
+// from the callee.
 func extractFunctionName(e DecodedEvent) string {
+	if isFunctionCall(e) && len(e.Topics) > 2 {
+		return e.Topics[2]
+	}
 	if len(e.Topics) > 1 {
 		return e.Topics[1]
 	}
 	return "unknown"
 }
 
+// calledContract returns the callee of a fn_call event, falling back to the
+// event's contract for two-topic [fn_call, function] events.
+func calledContract(e DecodedEvent) string {
+	if len(e.Topics) > 2 {
+		return e.Topics[1]
+	}
+	return e.ContractID
+}
+
 // DecodeEnvelope decodes a base64-encoded XDR transaction envelope
 func DecodeEnvelope(envelopeXdr string) (*xdr.TransactionEnvelope, error) {
 	if envelopeXdr == "" {
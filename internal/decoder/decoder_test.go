@@ -5,6 +5,7 @@ package decoder
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"strings"
 	"testing"
 
@@ -212,3 +213,43 @@ func FuzzDecodeEnvelopeBytes(f *testing.F) {
 		}
 	})
 }
+
+func TestDecodeEvents_HostCallFormat(t *testing.T) {
+	// The host emits fn_call as [fn_call, callee ID bytes, function] without
+	// a contract ID, and fn_return from the callee.
+	var id xdr.ContractId
+	id[0] = 0xab
+	callee := xdr.ScBytes(id[:])
+	callSym, retSym, fnSym := xdr.ScSymbol("fn_call"), xdr.ScSymbol("fn_return"), xdr.ScSymbol("transfer")
+
+	encode := func(contract *xdr.ContractId, topics ...xdr.ScVal) string {
+		diag := xdr.DiagnosticEvent{Event: xdr.ContractEvent{
+			ContractId: contract,
+			Type:       xdr.ContractEventTypeDiagnostic,
+			Body:       xdr.ContractEventBody{V0: &xdr.ContractEventV0{Topics: topics, Data: xdr.ScVal{Type: xdr.ScValTypeScvVoid}}},
+		}}
+		raw, err := diag.MarshalBinary()
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(raw)
+	}
+
+	root, err := DecodeEvents([]string{
+		encode(nil,
+			xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &callSym},
+			xdr.ScVal{Type: xdr.ScValTypeScvBytes, Bytes: &callee},
+			xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &fnSym}),
+		encode(&id,
+			xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &retSym},
+			xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &fnSym}),
+	})
+	require.NoError(t, err)
+	require.Len(t, root.SubCalls, 1)
+
+	node := root.SubCalls[0]
+	assert.Equal(t, hex.EncodeToString(id[:]), node.ContractID)
+	assert.Equal(t, "transfer", node.Function)
+	require.Len(t, node.Events, 2)
+	assert.Equal(t, 0, node.Events[0].Index)
+	assert.Equal(t, 1, node.Events[1].Index)
+	assert.Empty(t, root.Events, "the return should close the call")
+}
@@ -0,0 +1,503 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package dwarf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/wasm"
+)
+
+// Location expression opcodes evaluated by EvalLocation. rustc targeting
+// wasm32 places variables in WASM locals (DW_OP_WASM_location) or in linear
+// memory relative to the frame base (DW_OP_fbreg), split with DW_OP_piece
+// when a value spans several places.
+const (
+	opAddr       = 0x03
+	opDeref      = 0x06
+	opConst1u    = 0x08
+	opConst8s    = 0x0f
+	opConstu     = 0x10
+	opConsts     = 0x11
+	opDup        = 0x12
+	opDrop       = 0x13
+	opMinus      = 0x1c
+	opPlus       = 0x22
+	opPlusUconst = 0x23
+	opLit0       = 0x30
+	opLit31      = 0x4f
+	opFbreg      = 0x91
+	opPiece      = 0x93
+	opStackValue = 0x9f
+	opWasmLoc    = 0xed // DW_OP_WASM_location
+)
+
+// DW_OP_WASM_location kinds.
+const (
+	wasmLocal       = 0
+	wasmGlobal      = 1
+	wasmStack       = 2
+	wasmGlobalFixed = 3 // global with a fixed-size u32 index
+)
+
+// ErrUnsupportedLocation is returned for location expressions using opcodes
+// the evaluator does not implement.
+var ErrUnsupportedLocation = errors.New("unsupported DWARF location expression")
+
+// Memory gives read access to captured WASM linear memory.
+type Memory interface {
+	// Read returns size bytes starting at addr, or false when any of them
+	// were not captured.
+	Read(addr uint64, size int) ([]byte, bool)
+}
+
+// MemoryRegion is a captured span of linear memory.
+type MemoryRegion struct {
+	Addr uint64 `json:"addr"`
+	Data []byte `json:"data"`
+}
+
+// Regions is a Memory made of captured spans.
+type Regions []MemoryRegion
+
+// Read implements Memory.
+func (r Regions) Read(addr uint64, size int) ([]byte, bool) {
+	for _, region := range r {
+		// Compare without adding so crafted addresses and sizes cannot
+		// overflow into a bogus slice.
+		if size < 0 || size > len(region.Data) || addr < region.Addr {
+			continue
+		}
+		if start := addr - region.Addr; start <= uint64(len(region.Data)-size) {
+			return region.Data[start : start+uint64(size)], true
+		}
+	}
+	return nil, false
+}
+
+// MachineState is the WASM machine state at the point a variable is read:
+// the current function's locals, the module globals, the operand stack
+// (bottom first) and linear memory.
+type MachineState struct {
+	Locals  []uint64
+	Globals []uint64
+	Stack   []uint64
+	Memory  Memory
+}
+
+// PieceKind says where a piece of a variable lives.
+type PieceKind int
+
+const (
+	// PieceMemory is a piece in linear memory at Addr.
+	PieceMemory PieceKind = iota
+	// PieceWasmLocal, PieceWasmGlobal and PieceWasmStack are pieces held in
+	// the WASM local, global or operand stack slot at Index.
+	PieceWasmLocal
+	PieceWasmGlobal
+	PieceWasmStack
+	// PieceValue is a piece whose value is Value itself (DW_OP_stack_value).
+	PieceValue
+	// PieceEmpty is an optimized-out piece.
+	PieceEmpty
+)
+
+// Piece is one part of a variable's location.
+type Piece struct {
+	Kind  PieceKind
+	Addr  uint64
+	Index uint64
+	Value uint64
+	// Size is the piece size in bytes; 0 means the whole variable.
+	Size int
+}
+
+// Location is an evaluated location expression.
+type Location struct {
+	Pieces []Piece
+}
+
+// EvalLocation evaluates a location expression against st. frameBase is the
+// enclosing subprogram's DW_AT_frame_base expression, needed for
+// DW_OP_fbreg. addrSize is the target address size in bytes.
+func EvalLocation(expr, frameBase []byte, addrSize int, st *MachineState) (*Location, error) {
+	e := &evaluator{addrSize: addrSize, st: st, frameBase: frameBase}
+	return e.run(expr)
+}
+
+type evaluator struct {
+	addrSize  int
+	st        *MachineState
+	frameBase []byte
+	stack     []uint64
+	// wasm holds the WASM slot the expression named; it becomes the location
+	// unless later operations use its value.
+	wasm *Piece
+}
+
+func (e *evaluator) push(v uint64) {
+	e.stack = append(e.stack, v)
+}
+
+func (e *evaluator) pop() (uint64, error) {
+	if len(e.stack) == 0 {
+		return 0, fmt.Errorf("%w: expression stack underflow", ErrUnsupportedLocation)
+	}
+	v := e.stack[len(e.stack)-1]
+	e.stack = e.stack[:len(e.stack)-1]
+	return v, nil
+}
+
+// flushWasm turns a pending WASM slot into a value on the expression stack,
+// for expressions that compute with it.
+func (e *evaluator) flushWasm() error {
+	if e.wasm == nil {
+		return nil
+	}
+	v, err := e.wasmValue(*e.wasm)
+	if err != nil {
+		return err
+	}
+	e.wasm = nil
+	e.push(v)
+	return nil
+}
+
+func (e *evaluator) wasmValue(p Piece) (uint64, error) {
+	if e.st == nil {
+		return 0, errors.New("no machine state captured")
+	}
+	var slots []uint64
+	var what string
+	switch p.Kind {
+	case PieceWasmLocal:
+		slots, what = e.st.Locals, "local"
+	case PieceWasmGlobal:
+		slots, what = e.st.Globals, "global"
+	case PieceWasmStack:
+		// Stack indices count down from the top.
+		if p.Index < uint64(len(e.st.Stack)) {
+			return e.st.Stack[len(e.st.Stack)-1-int(p.Index)], nil
+		}
+		return 0, fmt.Errorf("operand stack slot %d not captured", p.Index)
+	}
+	if p.Index >= uint64(len(slots)) {
+		return 0, fmt.Errorf("wasm %s %d not captured", what, p.Index)
+	}
+	return slots[p.Index], nil
+}
+
+func (e *evaluator) run(expr []byte) (*Location, error) {
+	loc := &Location{}
+	valueOnStack := false
+	pos := 0
+	for pos < len(expr) {
+		op := expr[pos]
+		pos++
+		if op != opPiece && op != opStackValue && op != opWasmLoc {
+			if err := e.flushWasm(); err != nil {
+				return nil, err
+			}
+		}
+		switch {
+		case op >= opLit0 && op <= opLit31:
+			e.push(uint64(op - opLit0))
+		case op == opAddr:
+			if pos+e.addrSize > len(expr) {
+				return nil, fmt.Errorf("%w: truncated DW_OP_addr", ErrUnsupportedLocation)
+			}
+			e.push(readUint(expr[pos:pos+e.addrSize], e.addrSize))
+			pos += e.addrSize
+		case op >= opConst1u && op <= opConst8s:
+			// const1u, const1s, const2u, ... const8s: odd opcodes are signed.
+			size := 1 << ((op - opConst1u) / 2)
+			if pos+size > len(expr) {
+				return nil, fmt.Errorf("%w: truncated constant", ErrUnsupportedLocation)
+			}
+			v := readUint(expr[pos:pos+size], size)
+			if (op-opConst1u)%2 == 1 && size < 8 && v&(1<<(uint(size)*8-1)) != 0 {
+				v |= ^uint64(0) << (uint(size) * 8)
+			}
+			e.push(v)
+			pos += size
+		case op == opConstu:
+			v, n, err := wasm.ReadU64(expr, pos)
+			if err != nil {
+				return nil, fmt.Errorf("%w: bad DW_OP_constu", ErrUnsupportedLocation)
+			}
+			e.push(v)
+			pos += n
+		case op == opConsts:
+			v, n, err := wasm.ReadS64(expr, pos)
+			if err != nil {
+				return nil, fmt.Errorf("%w: bad DW_OP_consts", ErrUnsupportedLocation)
+			}
+			e.push(uint64(v))
+			pos += n
+		case op == opDup:
+			v, err := e.pop()
+			if err != nil {
+				return nil, err
+			}
+			e.push(v)
+			e.push(v)
+		case op == opDrop:
+			if _, err := e.pop(); err != nil {
+				return nil, err
+			}
+		case op == opPlus || op == opMinus:
+			b, err := e.pop()
+			if err != nil {
+				return nil, err
+			}
+			a, err := e.pop()
+			if err != nil {
+				return nil, err
+			}
+			if op == opPlus {
+				e.push(a + b)
+			} else {
+				e.push(a - b)
+			}
+		case op == opPlusUconst:
+			v, n, err := wasm.ReadU64(expr, pos)
+			if err != nil {
+				return nil, fmt.Errorf("%w: bad DW_OP_plus_uconst", ErrUnsupportedLocation)
+			}
+			a, err := e.pop()
+			if err != nil {
+				return nil, err
+			}
+			e.push(a + v)
+			pos += n
+		case op == opDeref:
+			addr, err := e.pop()
+			if err != nil {
+				return nil, err
+			}
+			data, err := e.readMemory(addr, e.addrSize)
+			if err != nil {
+				return nil, err
+			}
+			e.push(readUint(data, e.addrSize))
+		case op == opFbreg:
+			off, n, err := wasm.ReadS64(expr, pos)
+			if err != nil {
+				return nil, fmt.Errorf("%w: bad DW_OP_fbreg", ErrUnsupportedLocation)
+			}
+			pos += n
+			base, err := e.evalFrameBase()
+			if err != nil {
+				return nil, err
+			}
+			e.push(base + uint64(off))
+		case op == opWasmLoc:
+			p, n, err := readWasmLocation(expr, pos)
+			if err != nil {
+				return nil, err
+			}
+			pos += n
+			e.wasm = &p
+		case op == opStackValue:
+			if err := e.flushWasm(); err != nil {
+				return nil, err
+			}
+			valueOnStack = true
+		case op == opPiece:
+			size, n, err := wasm.ReadU64(expr, pos)
+			if err != nil {
+				return nil, fmt.Errorf("%w: bad DW_OP_piece", ErrUnsupportedLocation)
+			}
+			pos += n
+			p, err := e.takePiece(valueOnStack)
+			if err != nil {
+				return nil, err
+			}
+			p.Size = int(size)
+			loc.Pieces = append(loc.Pieces, p)
+			valueOnStack = false
+		default:
+			return nil, fmt.Errorf("%w: opcode 0x%02x", ErrUnsupportedLocation, op)
+		}
+	}
+	if len(loc.Pieces) == 0 || e.wasm != nil || len(e.stack) > 0 {
+		p, err := e.takePiece(valueOnStack)
+		if err != nil {
+			return nil, err
+		}
+		loc.Pieces = append(loc.Pieces, p)
+	}
+	return loc, nil
+}
+
+// takePiece turns the current evaluation result into a piece and resets the
+// evaluator for the next one.
+func (e *evaluator) takePiece(isValue bool) (Piece, error) {
+	if e.wasm != nil {
+		p := *e.wasm
+		e.wasm = nil
+		return p, nil
+	}
+	if len(e.stack) == 0 {
+		return Piece{Kind: PieceEmpty}, nil
+	}
+	v, _ := e.pop()
+	e.stack = e.stack[:0]
+	if isValue {
+		return Piece{Kind: PieceValue, Value: v}, nil
+	}
+	return Piece{Kind: PieceMemory, Addr: v}, nil
+}
+
+// evalFrameBase evaluates the subprogram's frame base to an address. On
+// wasm32 it names the local or global holding the stack pointer.
+func (e *evaluator) evalFrameBase() (uint64, error) {
+	if len(e.frameBase) == 0 {
+		return 0, fmt.Errorf("%w: DW_OP_fbreg without a frame base", ErrUnsupportedLocation)
+	}
+	sub := &evaluator{addrSize: e.addrSize, st: e.st}
+	loc, err := sub.run(e.frameBase)
+	if err != nil {
+		return 0, fmt.Errorf("frame base: %w", err)
+	}
+	if len(loc.Pieces) != 1 {
+		return 0, fmt.Errorf("%w: composite frame base", ErrUnsupportedLocation)
+	}
+	switch p := loc.Pieces[0]; p.Kind {
+	case PieceWasmLocal, PieceWasmGlobal, PieceWasmStack:
+		return e.wasmValue(p)
+	case PieceValue:
+		return p.Value, nil
+	case PieceMemory:
+		// A register-style frame base leaves its value as the "address".
+		return p.Addr, nil
+	}
+	return 0, fmt.Errorf("%w: empty frame base", ErrUnsupportedLocation)
+}
+
+func (e *evaluator) readMemory(addr uint64, size int) ([]byte, error) {
+	if e.st == nil || e.st.Memory == nil {
+		return nil, errors.New("no memory captured")
+	}
+	data, ok := e.st.Memory.Read(addr, size)
+	if !ok {
+		return nil, fmt.Errorf("memory at 0x%x not captured", addr)
+	}
+	return data, nil
+}
+
+// Read assembles size bytes of the variable from its pieces.
+func (l *Location) Read(st *MachineState, size int) ([]byte, error) {
+	if size < 0 || size > maxValueSize {
+		return nil, fmt.Errorf("value size %d exceeds the %d byte limit", size, maxValueSize)
+	}
+	e := &evaluator{st: st}
+	out := make([]byte, 0, size)
+	for _, p := range l.Pieces {
+		n := p.Size
+		if n <= 0 || n > size-len(out) {
+			n = size - len(out)
+		}
+		switch p.Kind {
+		case PieceMemory:
+			data, err := e.readMemory(p.Addr, n)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, data...)
+		case PieceEmpty:
+			return nil, errors.New("value optimized out")
+		default:
+			v := p.Value
+			if p.Kind != PieceValue {
+				var err error
+				if v, err = e.wasmValue(p); err != nil {
+					return nil, err
+				}
+			}
+			var buf [8]byte
+			binary.LittleEndian.PutUint64(buf[:], v)
+			for i := 0; i < n; i++ {
+				if i < 8 {
+					out = append(out, buf[i])
+				} else {
+					out = append(out, 0)
+				}
+			}
+		}
+	}
+	if len(out) < size {
+		return nil, fmt.Errorf("location covers %d of %d bytes", len(out), size)
+	}
+	return out[:size], nil
+}
+
+// String describes the location, e.g. "wasm-local 3" or "0x1000".
+func (l *Location) String() string {
+	s := ""
+	for i, p := range l.Pieces {
+		if i > 0 {
+			s += ", "
+		}
+		switch p.Kind {
+		case PieceMemory:
+			s += fmt.Sprintf("0x%x", p.Addr)
+		case PieceWasmLocal:
+			s += fmt.Sprintf("wasm-local %d", p.Index)
+		case PieceWasmGlobal:
+			s += fmt.Sprintf("wasm-global %d", p.Index)
+		case PieceWasmStack:
+			s += fmt.Sprintf("wasm-stack %d", p.Index)
+		case PieceValue:
+			s += "immediate"
+		case PieceEmpty:
+			s += "optimized out"
+		}
+		if p.Size > 0 && len(l.Pieces) > 1 {
+			s += fmt.Sprintf(" [%d bytes]", p.Size)
+		}
+	}
+	return s
+}
+
+// readWasmLocation decodes the operands of DW_OP_WASM_location.
+func readWasmLocation(expr []byte, pos int) (Piece, int, error) {
+	if pos >= len(expr) {
+		return Piece{}, 0, fmt.Errorf("%w: truncated DW_OP_WASM_location", ErrUnsupportedLocation)
+	}
+	kind := expr[pos]
+	var index uint64
+	n := 1
+	if kind == wasmGlobalFixed {
+		if pos+5 > len(expr) {
+			return Piece{}, 0, fmt.Errorf("%w: truncated DW_OP_WASM_location", ErrUnsupportedLocation)
+		}
+		index = uint64(binary.LittleEndian.Uint32(expr[pos+1 : pos+5]))
+		n += 4
+	} else {
+		v, m, err := wasm.ReadU32(expr, pos+1)
+		if err != nil {
+			return Piece{}, 0, fmt.Errorf("%w: truncated DW_OP_WASM_location", ErrUnsupportedLocation)
+		}
+		index = uint64(v)
+		n += m
+	}
+	switch kind {
+	case wasmLocal:
+		return Piece{Kind: PieceWasmLocal, Index: index}, n, nil
+	case wasmGlobal, wasmGlobalFixed:
+		return Piece{Kind: PieceWasmGlobal, Index: index}, n, nil
+	case wasmStack:
+		return Piece{Kind: PieceWasmStack, Index: index}, n, nil
+	}
+	return Piece{}, 0, fmt.Errorf("%w: DW_OP_WASM_location kind %d", ErrUnsupportedLocation, kind)
+}
+
+func readUint(b []byte, size int) uint64 {
+	var buf [8]byte
+	copy(buf[:], b[:min(size, 8)])
+	return binary.LittleEndian.Uint64(buf[:])
+}
@@ -0,0 +1,216 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package dwarf
+
+import (
+	"debug/dwarf"
+	"encoding/binary"
+	"testing"
+)
+
+func le32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	return b
+}
+
+func TestEvalLocation_FbregWasmLocalFrameBase(t *testing.T) {
+	frameBase := []byte{opWasmLoc, 0x00, 0x01} // frame base in local 1
+	expr := []byte{opFbreg, 0x08}              // fp+8
+	st := &MachineState{
+		Locals: []uint64{0, 0x1000},
+		Memory: Regions{{Addr: 0x1000, Data: append(make([]byte, 8), le32(42)...)}},
+	}
+
+	loc, err := EvalLocation(expr, frameBase, 4, st)
+	if err != nil {
+		t.Fatalf("EvalLocation: %v", err)
+	}
+	if len(loc.Pieces) != 1 || loc.Pieces[0].Kind != PieceMemory || loc.Pieces[0].Addr != 0x1008 {
+		t.Fatalf("expected memory piece at 0x1008, got %+v", loc.Pieces)
+	}
+	data, err := loc.Read(st, 4)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got := binary.LittleEndian.Uint32(data); got != 42 {
+		t.Errorf("expected 42, got %d", got)
+	}
+}
+
+func TestEvalLocation_WasmLocal(t *testing.T) {
+	st := &MachineState{Locals: []uint64{7, 8, 0xdeadbeef}}
+	loc, err := EvalLocation([]byte{opWasmLoc, 0x00, 0x02}, nil, 4, st)
+	if err != nil {
+		t.Fatalf("EvalLocation: %v", err)
+	}
+	if loc.String() != "wasm-local 2" {
+		t.Errorf("expected wasm-local 2, got %q", loc.String())
+	}
+	data, err := loc.Read(st, 4)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got := binary.LittleEndian.Uint32(data); got != 0xdeadbeef {
+		t.Errorf("expected 0xdeadbeef, got 0x%x", got)
+	}
+}
+
+func TestEvalLocation_Pieces(t *testing.T) {
+	expr := []byte{
+		opWasmLoc, 0x00, 0x00, opPiece, 0x04,
+		opWasmLoc, 0x01, 0x00, opPiece, 0x04,
+	}
+	st := &MachineState{Locals: []uint64{0x11111111}, Globals: []uint64{0x22222222}}
+	loc, err := EvalLocation(expr, nil, 4, st)
+	if err != nil {
+		t.Fatalf("EvalLocation: %v", err)
+	}
+	if len(loc.Pieces) != 2 {
+		t.Fatalf("expected 2 pieces, got %d", len(loc.Pieces))
+	}
+	data, err := loc.Read(st, 8)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got := binary.LittleEndian.Uint64(data); got != 0x2222222211111111 {
+		t.Errorf("expected 0x2222222211111111, got 0x%x", got)
+	}
+}
+
+func TestEvalLocation_StackValue(t *testing.T) {
+	loc, err := EvalLocation([]byte{opLit0 + 5, opStackValue}, nil, 4, &MachineState{})
+	if err != nil {
+		t.Fatalf("EvalLocation: %v", err)
+	}
+	data, err := loc.Read(nil, 4)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got := binary.LittleEndian.Uint32(data); got != 5 {
+		t.Errorf("expected 5, got %d", got)
+	}
+}
+
+func TestLocationRead_MemoryNotCaptured(t *testing.T) {
+	st := &MachineState{Memory: Regions{{Addr: 0x100, Data: []byte{1, 2}}}}
+	loc := &Location{Pieces: []Piece{{Kind: PieceMemory, Addr: 0x100}}}
+	if _, err := loc.Read(st, 4); err == nil {
+		t.Error("expected error reading past captured memory")
+	}
+}
+
+func TestRegionsRead_Overflow(t *testing.T) {
+	r := Regions{{Addr: 0x100, Data: []byte{1, 2, 3, 4}}}
+	if _, ok := r.Read(^uint64(0)-1, 4); ok {
+		t.Error("expected an address near the top of the range to miss")
+	}
+	if _, ok := r.Read(0x102, 1<<62); ok {
+		t.Error("expected an oversized read to miss")
+	}
+	if data, ok := r.Read(0x102, 2); !ok || data[0] != 3 || data[1] != 4 {
+		t.Errorf("expected bytes 3 4, got %v %v", data, ok)
+	}
+}
+
+func TestLocationRead_SizeLimit(t *testing.T) {
+	loc := &Location{Pieces: []Piece{{Kind: PieceValue, Value: 1, Size: 1 << 30}}}
+	if _, err := loc.Read(nil, 1<<40); err == nil {
+		t.Error("expected an error for an oversized value")
+	}
+}
+
+func TestFormatLocation_Wasm(t *testing.T) {
+	tests := []struct {
+		expr []byte
+		want string
+	}{
+		{[]byte{opWasmLoc, 0x00, 0x03}, "wasm-local 3"},
+		{[]byte{opWasmLoc, 0x01, 0x00}, "wasm-global 0"},
+		{[]byte{opFbreg, 0x10}, "fp+16"},
+		{[]byte{opFbreg, 0x70}, "fp-16"},
+	}
+	for _, tt := range tests {
+		if got := formatLocation(tt.expr); got != tt.want {
+			t.Errorf("formatLocation(%x) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeInt(t *testing.T) {
+	if got := decodeInt([]byte{0xff}, true); got != int64(-1) {
+		t.Errorf("expected -1, got %v", got)
+	}
+	if got := decodeInt([]byte{0xff}, false); got != uint64(255) {
+		t.Errorf("expected 255, got %v", got)
+	}
+	wide := make([]byte, 16)
+	for i := range wide {
+		wide[i] = 0xff
+	}
+	if got := decodeInt(wide, true); got.(interface{ String() string }).String() != "-1" {
+		t.Errorf("expected -1 for i128, got %v", got)
+	}
+}
+
+func u32Type() dwarf.Type {
+	return &dwarf.UintType{BasicType: dwarf.BasicType{CommonType: dwarf.CommonType{ByteSize: 4, Name: "u32"}}}
+}
+
+func TestDecode_StructAndSlice(t *testing.T) {
+	u8 := &dwarf.UintType{BasicType: dwarf.BasicType{CommonType: dwarf.CommonType{ByteSize: 1, Name: "u8"}}}
+	str := &dwarf.StructType{
+		CommonType: dwarf.CommonType{ByteSize: 8},
+		StructName: "&str",
+		Kind:       "struct",
+		Field: []*dwarf.StructField{
+			{Name: "data_ptr", Type: &dwarf.PtrType{CommonType: dwarf.CommonType{ByteSize: 4}, Type: u8}, ByteOffset: 0},
+			{Name: "length", Type: u32Type(), ByteOffset: 4},
+		},
+	}
+	slice := &dwarf.StructType{
+		CommonType: dwarf.CommonType{ByteSize: 8},
+		StructName: "&[u32]",
+		Kind:       "struct",
+		Field: []*dwarf.StructField{
+			{Name: "data_ptr", Type: &dwarf.PtrType{CommonType: dwarf.CommonType{ByteSize: 4}, Type: u32Type()}, ByteOffset: 0},
+			{Name: "length", Type: u32Type(), ByteOffset: 4},
+		},
+	}
+	point := &dwarf.StructType{
+		CommonType: dwarf.CommonType{ByteSize: 24},
+		StructName: "Point",
+		Kind:       "struct",
+		Field: []*dwarf.StructField{
+			{Name: "x", Type: u32Type(), ByteOffset: 0},
+			{Name: "name", Type: str, ByteOffset: 8},
+			{Name: "ys", Type: slice, ByteOffset: 16},
+		},
+	}
+
+	mem := Regions{
+		{Addr: 0x200, Data: []byte("alice")},
+		{Addr: 0x300, Data: append(le32(1), le32(2)...)},
+	}
+	data := append(le32(7), le32(0)...)
+	data = append(data, append(le32(0x200), le32(5)...)...)
+	data = append(data, append(le32(0x300), le32(2)...)...)
+
+	d := &decoder{st: &MachineState{Memory: mem}, addrSize: 4}
+	v, err := d.decode(point, data, 0)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := `Point { x: 7, name: "alice", ys: [1, 2] }`
+	if got := v.(StructValue).String(); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestSliceValue_Truncated(t *testing.T) {
+	s := SliceValue{Elems: []interface{}{uint64(1), uint64(2)}, Len: 10}
+	if got := s.String(); got != "[1, 2, ... (10 total)]" {
+		t.Errorf("unexpected rendering %q", got)
+	}
+}
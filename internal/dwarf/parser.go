@@ -15,6 +15,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+
+	"github.com/dotandev/hintents/internal/wasm"
 )
 
 var (
@@ -28,14 +30,16 @@ var (
 
 // LocalVar represents a local variable at a specific program location
 type LocalVar struct {
-	Name         string      // Variable name (may be mangled)
-	DemangledName string    // Demangled name for display
-	Type         string      // Type name
-	Location     string      // DWARF location description
-	Value        interface{} // Computed value (if available)
-	Address      uint64      // Memory address (if applicable)
-	StartLine    int         // Source line where variable is in scope
-	EndLine      int         // Source line where variable goes out of scope
+	Name          string       // Variable name (may be mangled)
+	DemangledName string       // Demangled name for display
+	Type          string       // Type name
+	Location      string       // DWARF location description
+	Value         interface{}  // Computed value (if available)
+	Address       uint64       // Memory address (if applicable)
+	StartLine     int          // Source line where variable is in scope
+	EndLine       int          // Source line where variable goes out of scope
+	LocationExpr  []byte       // Raw DW_AT_location expression, for EvalLocation
+	TypeOffset    dwarf.Offset // Offset of the variable's type entry
 }
 
 // SubprogramInfo represents a function/subprogram's debug information
@@ -47,6 +51,7 @@ type SubprogramInfo struct {
 	Line           int
 	File           string
 	LocalVariables []LocalVar
+	FrameBase      []byte // Raw DW_AT_frame_base expression
 }
 
 // SourceLocation represents a location in the source code
@@ -112,23 +117,9 @@ func parseWASM(data []byte) (*Parser, error) {
 	// For WASM, we need to look for custom sections starting with ".debug_"
 	// WASM doesn't have native DWARF support, but compilers often embed
 	// DWARF info as custom sections
-	
+
 	// Try to find debug sections in WASM
 	sections := parseWASMSections(data)
-	
-	var dwarfData *dwarf.Data
-	var err error
-	
-	// Look for .debug_info section
-	if infoSection, ok := sections[".debug_info"]; ok {
-		abbrevSection := sections[".debug_abbrev"]
-		lineSection := sections[".debug_line"]
-		strSection := sections[".debug_str"]
-		dwarfData, err = dwarf.New(abbrevSection, nil, nil, infoSection, lineSection, nil, nil, strSection)
-		if err != nil {
-			// Fall back without optional sections
-			dwarfData, err = dwarf.New(abbrevSection, nil, nil, infoSection, nil, nil, nil, nil)
-		}
 
 	infoSec, ok := sections[".debug_info"]
 	if !ok || len(infoSec) == 0 {
@@ -151,73 +142,20 @@ func parseWASM(data []byte) (*Parser, error) {
 	}, nil
 }
 
-// parseWASMSections parses the section table of a WASM binary and returns a
-// map of custom-section names to their content bytes.  Only custom sections
-// (section ID 0) are collected; all other sections are skipped.
+// parseWASMSections returns the custom sections of a WASM binary by name.
+// DWARF lives in custom sections named after the ELF sections (".debug_info"
+// and so on). A malformed binary yields the sections read before the error.
 func parseWASMSections(data []byte) map[string][]byte {
 	sections := make(map[string][]byte)
-
-	pos := 8 // skip 4-byte magic + 4-byte version
-	for pos < len(data) {
-		// Read section ID (1 byte).
-		if pos >= len(data) {
-			break
-		}
-		sectionID := data[pos]
-		pos++
-
-		// Read section size as an unsigned LEB128 varint.
-		sectionSize, n := readULEB128(data, pos)
-		if n == 0 {
-			break
+	raw, _ := wasm.ReadSections(data)
+	for _, s := range raw {
+		if s.ID == wasm.SectionCustom {
+			sections[s.Name] = s.Payload
 		}
-		pos += n
-
-		sectionEnd := pos + int(sectionSize)
-		if sectionEnd > len(data) {
-			break
-		}
-
-		if sectionID == 0 { // custom section
-			// The first field inside the custom section is the name, also
-			// length-prefixed with a LEB128 integer.
-			nameLen, m := readULEB128(data, pos)
-			if m == 0 || pos+m+int(nameLen) > sectionEnd {
-				pos = sectionEnd
-				continue
-			}
-			nameStart := pos + m
-			name := string(data[nameStart : nameStart+int(nameLen)])
-			content := data[nameStart+int(nameLen) : sectionEnd]
-			sections[name] = content
-		}
-
-		pos = sectionEnd
 	}
-
 	return sections
 }
 
-// readULEB128 decodes an unsigned little-endian base-128 integer starting at
-// data[pos].  It returns the value and the number of bytes consumed.  If the
-// data is truncated or malformed it returns (0, 0).
-func readULEB128(data []byte, pos int) (uint64, int) {
-	var result uint64
-	var shift uint
-	for i := pos; i < len(data); i++ {
-		b := data[i]
-		result |= uint64(b&0x7f) << shift
-		shift += 7
-		if b&0x80 == 0 {
-			return result, i - pos + 1
-		}
-		if shift >= 64 {
-			return 0, 0 // overflow
-		}
-	}
-	return 0, 0 // truncated
-}
-
 // parseELF parses DWARF info from an ELF binary
 func parseELF(data []byte) (*Parser, error) {
 	// Create a temporary file to use debug/elf package
@@ -356,6 +294,10 @@ func (p *Parser) extractSubprogram(entry *dwarf.Entry) (SubprogramInfo, error) {
 		info.File = file
 	}
 
+	if fb, ok := entry.Val(dwarf.AttrFrameBase).([]byte); ok {
+		info.FrameBase = fb
+	}
+
 	// Get local variables for this subprogram
 	info.LocalVariables = p.getLocalVariables(entry)
 
@@ -410,11 +352,13 @@ func (p *Parser) extractLocalVar(entry *dwarf.Entry) LocalVar {
 	// Get type
 	if typ, ok := entry.Val(dwarf.AttrType).(dwarf.Offset); ok {
 		local.Type = p.getTypeName(typ)
+		local.TypeOffset = typ
 	}
 
 	// Get location
 	if loc, ok := entry.Val(dwarf.AttrLocation).([]byte); ok {
 		local.Location = formatLocation(loc)
+		local.LocationExpr = loc
 	}
 
 	// Get line number
@@ -499,7 +443,6 @@ func (p *Parser) FindLocalVarsAt(addr uint64) ([]LocalVar, error) {
 	// Filter variables that are in scope at this address
 	var inScope []LocalVar
 	for _, v := range subprogram.LocalVariables {
-		if addr >= uint64(v.StartLine) { // Simplified check
 		if addr >= uint64(v.StartLine) {
 			inScope = append(inScope, v)
 		}
@@ -577,34 +520,14 @@ func (p *Parser) findLineForAddr(lr *dwarf.LineReader, addr uint64) *SourceLocat
 	return nil
 }
 
-// formatLocation formats a DWARF location expression byte sequence into a
-// human-readable string.  Only a small subset of opcodes is handled; the rest
-// fall through to a hex dump.
-//
-// DWARF location-expression opcodes used here:
-//
-//	0x03  DW_OP_addr          – followed by a target-address-sized literal
-//	0x9f  DW_OP_stack_value   – the value is the top of the expression stack
-//	0x00  (no-op / terminator in some older encodings)
-// DWARF location expression opcodes (DW_OP_*) used in formatLocation.
-// These are defined in the DWARF spec and are not exported by debug/dwarf.
-const (
-	dwOpAddr       = 0x03 // DW_OP_addr — constant address
-	dwOpStackValue = 0x9f // DW_OP_stack_value — value is on the expression stack
-	dwOpLit0       = 0x30 // DW_OP_lit0 — literal 0 (marks end-of-list in some contexts)
-)
-
-// formatLocation formats a DWARF location description
+// formatLocation describes a DWARF location expression without evaluating
+// it: a constant address, a WASM local or global, a frame-base offset, or the
+// leading opcode for anything else.
 func formatLocation(loc []byte) string {
 	if len(loc) == 0 {
 		return ""
 	}
 
-	const (
-		opAddr       = 0x03 // DW_OP_addr
-		opStackValue = 0x9f // DW_OP_stack_value
-	)
-
 	switch loc[0] {
 	case opStackValue:
 		return "immediate"
@@ -613,6 +536,20 @@ func formatLocation(loc []byte) string {
 			addr := binary.LittleEndian.Uint64(loc[1:])
 			return fmt.Sprintf("0x%x", addr)
 		}
+		if len(loc) >= 5 {
+			return fmt.Sprintf("0x%x", binary.LittleEndian.Uint32(loc[1:]))
+		}
+	case opWasmLoc:
+		if p, _, err := readWasmLocation(loc, 1); err == nil {
+			return (&Location{Pieces: []Piece{p}}).String()
+		}
+	case opFbreg:
+		if off, _, err := wasm.ReadS64(loc, 1); err == nil {
+			if off < 0 {
+				return fmt.Sprintf("fp-%d", -off)
+			}
+			return fmt.Sprintf("fp+%d", off)
+		}
 	}
 
 	return fmt.Sprintf("location[0x%x]", loc[0])
@@ -0,0 +1,312 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package dwarf
+
+import (
+	"debug/dwarf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+const (
+	// maxValueDepth bounds how far nested structs and slices are decoded.
+	maxValueDepth = 4
+	// maxSliceElems is the number of slice or array elements decoded.
+	maxSliceElems = 32
+	// maxValueSize bounds the bytes read for a single variable, so a
+	// crafted type size cannot force a huge allocation.
+	maxValueSize = 1 << 16
+)
+
+// StructValue is a decoded struct with its fields in declaration order.
+type StructValue struct {
+	Type   string
+	Fields []FieldValue
+}
+
+// FieldValue is one decoded struct field.
+type FieldValue struct {
+	Name  string
+	Value interface{}
+}
+
+// String renders the struct Rust-style, e.g. "Point { x: 1, y: 2 }".
+func (s StructValue) String() string {
+	parts := make([]string, 0, len(s.Fields))
+	for _, f := range s.Fields {
+		parts = append(parts, f.Name+": "+formatValue(f.Value))
+	}
+	return s.Type + " { " + strings.Join(parts, ", ") + " }"
+}
+
+// SliceValue is a decoded slice or array. Len is the full length; Elems may
+// hold only the first elements.
+type SliceValue struct {
+	Elems []interface{}
+	Len   uint64
+}
+
+// String renders the elements, noting any that were not decoded.
+func (s SliceValue) String() string {
+	parts := make([]string, 0, len(s.Elems)+1)
+	for _, e := range s.Elems {
+		parts = append(parts, formatValue(e))
+	}
+	if uint64(len(s.Elems)) < s.Len {
+		parts = append(parts, fmt.Sprintf("... (%d total)", s.Len))
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// Pointer is a decoded pointer value.
+type Pointer uint64
+
+func (p Pointer) String() string {
+	return fmt.Sprintf("0x%x", uint64(p))
+}
+
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(v)
+}
+
+// ReadVariable evaluates a local variable's location in sp against st and
+// decodes its value by its DWARF type. Primitives decode to Go integers,
+// floats, bools and runes; 128-bit integers to *big.Int; &str to string;
+// slices and arrays to SliceValue; structs to StructValue.
+func (p *Parser) ReadVariable(v LocalVar, sp *SubprogramInfo, st *MachineState) (interface{}, error) {
+	if p.data == nil {
+		return nil, ErrNoDebugInfo
+	}
+	if len(v.LocationExpr) == 0 {
+		return nil, errors.New("variable has no location")
+	}
+	typ, err := p.data.Type(v.TypeOffset)
+	if err != nil {
+		return nil, fmt.Errorf("reading type of %s: %w", v.Name, err)
+	}
+	var frameBase []byte
+	if sp != nil {
+		frameBase = sp.FrameBase
+	}
+	loc, err := EvalLocation(v.LocationExpr, frameBase, p.addrSize(), st)
+	if err != nil {
+		return nil, err
+	}
+	size := typ.Size()
+	if size <= 0 {
+		return nil, fmt.Errorf("type %s has unknown size", typ)
+	}
+	if size > maxValueSize {
+		return nil, fmt.Errorf("type %s is too large to display (%d bytes)", typ, size)
+	}
+	data, err := loc.Read(st, int(size))
+	if err != nil {
+		return nil, err
+	}
+	d := &decoder{st: st, addrSize: p.addrSize()}
+	return d.decode(typ, data, 0)
+}
+
+func (p *Parser) addrSize() int {
+	if p.binaryType == "wasm" {
+		return 4
+	}
+	return 8
+}
+
+type decoder struct {
+	st       *MachineState
+	addrSize int
+}
+
+func (d *decoder) decode(t dwarf.Type, data []byte, depth int) (interface{}, error) {
+	if depth > maxValueDepth {
+		return "...", nil
+	}
+	switch t := t.(type) {
+	case *dwarf.TypedefType:
+		return d.decode(t.Type, data, depth)
+	case *dwarf.QualType:
+		return d.decode(t.Type, data, depth)
+	case *dwarf.BoolType:
+		return len(data) > 0 && data[0] != 0, nil
+	case *dwarf.CharType, *dwarf.UcharType:
+		if len(data) == 4 {
+			return rune(binary.LittleEndian.Uint32(data)), nil
+		}
+		return rune(data[0]), nil
+	case *dwarf.IntType:
+		return decodeInt(data, true), nil
+	case *dwarf.UintType:
+		return decodeInt(data, false), nil
+	case *dwarf.FloatType:
+		switch len(data) {
+		case 4:
+			return math.Float32frombits(binary.LittleEndian.Uint32(data)), nil
+		case 8:
+			return math.Float64frombits(binary.LittleEndian.Uint64(data)), nil
+		}
+	case *dwarf.PtrType:
+		return Pointer(readUint(data, d.addrSize)), nil
+	case *dwarf.EnumType:
+		v := decodeInt(data, false)
+		n, _ := v.(uint64)
+		for _, e := range t.Val {
+			if uint64(e.Val) == n {
+				return e.Name, nil
+			}
+		}
+		return v, nil
+	case *dwarf.ArrayType:
+		if t.Count < 0 {
+			break
+		}
+		elemSize := t.Type.Size()
+		if elemSize <= 0 {
+			break
+		}
+		return d.decodeElems(t.Type, data, uint64(t.Count), depth)
+	case *dwarf.StructType:
+		return d.decodeStruct(t, data, depth)
+	}
+	return nil, fmt.Errorf("cannot decode values of type %s", t)
+}
+
+func (d *decoder) decodeStruct(t *dwarf.StructType, data []byte, depth int) (interface{}, error) {
+	if t.Kind != "struct" {
+		return nil, fmt.Errorf("cannot decode %s values", t.Kind)
+	}
+	// Rust fat pointers: &[T] and &str carry data_ptr and length.
+	var ptr, length *dwarf.StructField
+	for _, f := range t.Field {
+		switch f.Name {
+		case "data_ptr":
+			ptr = f
+		case "length":
+			length = f
+		}
+	}
+	if ptr != nil && length != nil && len(t.Field) == 2 {
+		return d.decodeSlice(t, ptr, length, data, depth)
+	}
+
+	out := StructValue{Type: t.StructName}
+	for _, f := range t.Field {
+		size := f.Type.Size()
+		if f.ByteOffset < 0 || size < 0 || f.ByteOffset+size > int64(len(data)) {
+			out.Fields = append(out.Fields, FieldValue{Name: f.Name, Value: "?"})
+			continue
+		}
+		v, err := d.decode(f.Type, data[f.ByteOffset:f.ByteOffset+size], depth+1)
+		if err != nil {
+			v = "?"
+		}
+		out.Fields = append(out.Fields, FieldValue{Name: f.Name, Value: v})
+	}
+	return out, nil
+}
+
+func (d *decoder) decodeSlice(t *dwarf.StructType, ptr, length *dwarf.StructField, data []byte, depth int) (interface{}, error) {
+	field := func(f *dwarf.StructField) (uint64, error) {
+		size := f.Type.Size()
+		if f.ByteOffset+size > int64(len(data)) {
+			return 0, fmt.Errorf("%s: field %s out of range", t.StructName, f.Name)
+		}
+		return readUint(data[f.ByteOffset:f.ByteOffset+size], int(size)), nil
+	}
+	addr, err := field(ptr)
+	if err != nil {
+		return nil, err
+	}
+	n, err := field(length)
+	if err != nil {
+		return nil, err
+	}
+	pt, ok := ptr.Type.(*dwarf.PtrType)
+	if !ok {
+		return nil, fmt.Errorf("%s: data_ptr is not a pointer", t.StructName)
+	}
+	elem := pt.Type
+	elemSize := elem.Size()
+	if elemSize <= 0 {
+		return nil, fmt.Errorf("%s: unknown element size", t.StructName)
+	}
+	if elemSize > maxValueSize {
+		return nil, fmt.Errorf("%s: element size %d is too large", t.StructName, elemSize)
+	}
+
+	count := min(n, maxSliceElems)
+	if t.StructName == "&str" {
+		count = min(n, 256)
+	}
+	if d.st == nil || d.st.Memory == nil {
+		return nil, errors.New("no memory captured")
+	}
+	buf, ok := d.st.Memory.Read(addr, int(count)*int(elemSize))
+	if !ok {
+		return nil, fmt.Errorf("memory at 0x%x not captured", addr)
+	}
+	if t.StructName == "&str" {
+		s := string(buf)
+		if count < n {
+			s += "..."
+		}
+		return s, nil
+	}
+	v, err := d.decodeElems(elem, buf, count, depth)
+	if err != nil {
+		return nil, err
+	}
+	sv := v.(SliceValue)
+	sv.Len = n
+	return sv, nil
+}
+
+func (d *decoder) decodeElems(elem dwarf.Type, data []byte, n uint64, depth int) (interface{}, error) {
+	size := uint64(elem.Size())
+	out := SliceValue{Len: n}
+	for i := uint64(0); i < n && i < maxSliceElems; i++ {
+		if (i+1)*size > uint64(len(data)) {
+			break
+		}
+		v, err := d.decode(elem, data[i*size:(i+1)*size], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		out.Elems = append(out.Elems, v)
+	}
+	return out, nil
+}
+
+// decodeInt decodes a little-endian integer of 1, 2, 4, 8 or 16 bytes.
+func decodeInt(data []byte, signed bool) interface{} {
+	if len(data) == 16 {
+		// Little-endian to big-endian magnitude for big.Int.
+		be := make([]byte, 16)
+		for i := range data {
+			be[15-i] = data[i]
+		}
+		v := new(big.Int).SetBytes(be)
+		if signed && data[15]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), 128))
+		}
+		return v
+	}
+	u := readUint(data, len(data))
+	if !signed {
+		return u
+	}
+	bits := uint(len(data)) * 8
+	if bits < 64 && u&(1<<(bits-1)) != 0 {
+		u |= ^uint64(0) << bits
+	}
+	return int64(u)
+}
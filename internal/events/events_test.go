@@ -0,0 +1,264 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+const testContract = "CDLZFC3SYJYDZT7K67VZ75HPJVIEUVNIXF47ZG2FB2RMQQVU2HHGCYSC"
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	// Every pooled connection to :memory: is a separate database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	store, err := NewStore(db)
+	require.NoError(t, err)
+	return store
+}
+
+func symbolXDR(t *testing.T, s string) string {
+	t.Helper()
+	sym := xdr.ScSymbol(s)
+	out, err := xdr.MarshalBase64(xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym})
+	require.NoError(t, err)
+	return out
+}
+
+func u64XDR(t *testing.T, n uint64) string {
+	t.Helper()
+	v := xdr.Uint64(n)
+	out, err := xdr.MarshalBase64(xdr.ScVal{Type: xdr.ScValTypeScvU64, U64: &v})
+	require.NoError(t, err)
+	return out
+}
+
+// fakeSource serves a fixed list of events in pages, keyed by cursor.
+type fakeSource struct {
+	events   []rpc.EventInfo
+	latest   int
+	requests []rpc.GetEventsRequest
+}
+
+func (f *fakeSource) GetLatestLedgerSequence(ctx context.Context) (int, error) {
+	return f.latest, nil
+}
+
+func (f *fakeSource) GetEvents(ctx context.Context, req *rpc.GetEventsRequest) (*rpc.GetEventsResponse, error) {
+	f.requests = append(f.requests, *req)
+
+	start := 0
+	if req.Pagination.Cursor != "" {
+		for i, e := range f.events {
+			if e.ID == req.Pagination.Cursor {
+				start = i + 1
+			}
+		}
+	}
+	end := start + req.Pagination.Limit
+	if end > len(f.events) {
+		end = len(f.events)
+	}
+
+	resp := &rpc.GetEventsResponse{}
+	resp.Result.Events = f.events[start:end]
+	resp.Result.LatestLedger = uint32(f.latest)
+	if end > start {
+		resp.Result.Cursor = f.events[end-1].ID
+	}
+	return resp, nil
+}
+
+func makeEvents(t *testing.T, n int) []rpc.EventInfo {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	out := make([]rpc.EventInfo, 0, n)
+	for i := 0; i < n; i++ {
+		name := "transfer"
+		if i%2 == 1 {
+			name = "mint"
+		}
+		out = append(out, rpc.EventInfo{
+			Type:                     "contract",
+			Ledger:                   uint32(100 + i),
+			LedgerClosedAt:           base.Add(time.Duration(i) * time.Hour).Format(time.RFC3339),
+			ContractID:               testContract,
+			ID:                       fmt.Sprintf("%019d-0000000001", 100+i),
+			Topic:                    []string{symbolXDR(t, name), symbolXDR(t, "alice")},
+			Value:                    u64XDR(t, uint64(i)),
+			InSuccessfulContractCall: true,
+			TxHash:                   fmt.Sprintf("tx%d", i),
+		})
+	}
+	return out
+}
+
+func TestIngest_PagesAndResumes(t *testing.T) {
+	store := newTestStore(t)
+	src := &fakeSource{events: makeEvents(t, 5), latest: 20000}
+	opts := IngestOptions{Network: "testnet", ContractIDs: []string{testContract}, PageSize: 2}
+
+	res, err := Ingest(context.Background(), src, store, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 3, res.Pages)
+	assert.Equal(t, 5, res.Inserted)
+	assert.False(t, res.Resumed)
+	assert.Equal(t, uint32(20000-DefaultLookbackLedgers), src.requests[0].StartLedger)
+	assert.Empty(t, src.requests[1].StartLedger, "later pages must page by cursor")
+
+	// Two more events arrive; the next run resumes from the saved cursor.
+	src.events = append(src.events, makeEvents(t, 7)[5:]...)
+	src.requests = nil
+	res, err = Ingest(context.Background(), src, store, opts)
+	require.NoError(t, err)
+	assert.True(t, res.Resumed)
+	assert.Equal(t, 2, res.Inserted)
+	assert.Equal(t, src.events[4].ID, src.requests[0].Pagination.Cursor)
+
+	all, err := store.Find(Query{Network: "testnet"})
+	require.NoError(t, err)
+	assert.Len(t, all, 7)
+}
+
+func TestIngest_RequiresContract(t *testing.T) {
+	_, err := Ingest(context.Background(), &fakeSource{}, newTestStore(t), IngestOptions{})
+	assert.Error(t, err)
+}
+
+func TestDecodeEvent(t *testing.T) {
+	info := makeEvents(t, 1)[0]
+	e := DecodeEvent("testnet", info)
+
+	assert.Equal(t, []string{"transfer", "alice"}, e.Topics)
+	assert.Equal(t, "0", e.Data)
+	assert.Equal(t, info.Topic, e.TopicsXDR)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), e.LedgerClosedAt)
+
+	info.Value = "not-xdr"
+	assert.Equal(t, "not-xdr", DecodeEvent("testnet", info).Data)
+}
+
+func TestStoreFind_Filters(t *testing.T) {
+	store := newTestStore(t)
+	evts := make([]Event, 0, 6)
+	for _, info := range makeEvents(t, 6) {
+		evts = append(evts, DecodeEvent("testnet", info))
+	}
+	n, err := store.Insert(evts)
+	require.NoError(t, err)
+	assert.Equal(t, 6, n)
+
+	n, err = store.Insert(evts[:2])
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "duplicates must be ignored")
+
+	tests := []struct {
+		name string
+		q    Query
+		want int
+	}{
+		{"all", Query{}, 6},
+		{"topic name", Query{Topics: [][]string{{"mint"}}}, 3},
+		{"wildcard then value", Query{Topics: [][]string{{TopicWildcard, "alice"}}}, 6},
+		{"no match", Query{Topics: [][]string{{"burn"}}}, 0},
+		{"any filter", Query{Topics: [][]string{{"mint"}, {"burn"}, {"transfer"}}}, 6},
+		{"rest", Query{Topics: [][]string{{"mint", TopicRest}}}, 3},
+		{"only rest", Query{Topics: [][]string{{TopicRest}}}, 6},
+		{"ledger range", Query{FromLedger: 101, ToLedger: 103}, 3},
+		{"time range", Query{
+			Since: time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC),
+			Until: time.Date(2025, 1, 1, 4, 0, 0, 0, time.UTC),
+		}, 3},
+		{"other contract", Query{ContractIDs: []string{"CAAA"}}, 0},
+		{"other network", Query{Network: "mainnet"}, 0},
+		{"limit", Query{Limit: 2}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Find(tt.q)
+			require.NoError(t, err)
+			assert.Len(t, got, tt.want)
+		})
+	}
+}
+
+func TestStoreFind_RestNotLast(t *testing.T) {
+	store := newTestStore(t)
+	_, err := store.Find(Query{Topics: [][]string{{TopicRest, "alice"}}})
+	assert.Error(t, err)
+}
+
+func TestEncodeTopicSegment(t *testing.T) {
+	for _, wc := range []string{"*", "**"} {
+		got, err := EncodeTopicSegment(wc)
+		require.NoError(t, err)
+		assert.Equal(t, wc, got)
+	}
+
+	raw := symbolXDR(t, "transfer")
+	got, err := EncodeTopicSegment(raw)
+	require.NoError(t, err)
+	assert.Equal(t, raw, got)
+
+	got, err = EncodeTopicSegment("transfer")
+	require.NoError(t, err)
+	assert.Equal(t, raw, got)
+}
+
+func TestFilterKey_ContractOrderInsensitive(t *testing.T) {
+	a := FilterKey("testnet", []string{"C1", "C2"}, [][]string{{"transfer"}})
+	b := FilterKey("testnet", []string{"C2", "C1"}, [][]string{{"transfer"}})
+	c := FilterKey("testnet", []string{"C1", "C2"}, [][]string{{"mint"}})
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
+
+func TestWrite_Formats(t *testing.T) {
+	evts := []Event{DecodeEvent("testnet", makeEvents(t, 1)[0])}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, "csv", evts))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "id,network,contract_id"))
+	assert.Contains(t, lines[1], "transfer | alice")
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, "json", evts))
+	var decoded []Event
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, evts[0].ID, decoded[0].ID)
+
+	assert.Error(t, Write(&buf, "xml", evts))
+}
+
+func TestSampleTransactions_NewestDistinctFirst(t *testing.T) {
+	evts := makeEvents(t, 6)
+	evts[5].TxHash = evts[4].TxHash
+	evts[3].InSuccessfulContractCall = false
+	src := &fakeSource{events: evts, latest: 20000}
+
+	got, err := SampleTransactions(context.Background(), src, SampleOptions{ContractID: testContract, Count: 3, PageSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx4", "tx2", "tx1"}, got)
+
+	_, err = SampleTransactions(context.Background(), src, SampleOptions{Count: 3})
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvHeader lists the columns written by WriteCSV.
+var csvHeader = []string{
+	"id", "network", "contract_id", "ledger", "ledger_closed_at", "tx_hash",
+	"type", "topics", "data", "successful_call",
+}
+
+// WriteJSON writes events as an indented JSON array.
+func WriteJSON(w io.Writer, evts []Event) error {
+	if evts == nil {
+		evts = []Event{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(evts)
+}
+
+// WriteCSV writes events as CSV with a header row. Decoded topics are joined
+// with " | " so each event stays on one row.
+func WriteCSV(w io.Writer, evts []Event) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range evts {
+		record := []string{
+			e.ID,
+			e.Network,
+			e.ContractID,
+			strconv.FormatUint(uint64(e.Ledger), 10),
+			e.LedgerClosedAt.Format(time.RFC3339),
+			e.TxHash,
+			e.Type,
+			strings.Join(e.Topics, " | "),
+			e.Data,
+			strconv.FormatBool(e.SuccessfulCall),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Write dispatches to WriteJSON or WriteCSV by format name.
+func Write(w io.Writer, format string, evts []Event) error {
+	switch strings.ToLower(format) {
+	case "json":
+		return WriteJSON(w, evts)
+	case "csv":
+		return WriteCSV(w, evts)
+	default:
+		return fmt.Errorf("unsupported export format %q (use json or csv)", format)
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dotandev/hintents/internal/logger"
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+const (
+	// DefaultPageSize is the number of events requested per getEvents page.
+	DefaultPageSize = 1000
+
+	// DefaultLookbackLedgers is how far back a fresh ingest starts when no
+	// start ledger is given (roughly one day of ledgers).
+	DefaultLookbackLedgers = 17280
+)
+
+// Source is the subset of rpc.Client used for ingestion.
+type Source interface {
+	GetEvents(ctx context.Context, req *rpc.GetEventsRequest) (*rpc.GetEventsResponse, error)
+	GetLatestLedgerSequence(ctx context.Context) (int, error)
+}
+
+// IngestOptions selects which events to pull into the index.
+type IngestOptions struct {
+	Network     string
+	ContractIDs []string
+	// Topics holds one filter per entry; each filter lists its segments as
+	// given on the command line (see EncodeTopicSegment).
+	Topics      [][]string
+	StartLedger uint32
+	PageSize    int
+	// MaxPages stops ingestion after this many pages; 0 means until caught up.
+	MaxPages int
+}
+
+// IngestResult summarises one ingestion run.
+type IngestResult struct {
+	Pages        int
+	Fetched      int
+	Inserted     int
+	Cursor       string
+	LatestLedger uint32
+	Resumed      bool
+}
+
+// Ingest pulls getEvents pages into the store, resuming from the cursor saved
+// by a previous run with the same network, contracts and topics.
+func Ingest(ctx context.Context, src Source, store *Store, opts IngestOptions) (*IngestResult, error) {
+	if len(opts.ContractIDs) == 0 {
+		return nil, fmt.Errorf("at least one contract ID is required")
+	}
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > rpc.MaxEventsPageSize {
+		pageSize = rpc.MaxEventsPageSize
+	}
+
+	filter := rpc.EventFilter{Type: "contract", ContractIDs: opts.ContractIDs}
+	for _, segments := range opts.Topics {
+		encoded := make([]string, 0, len(segments))
+		for _, seg := range segments {
+			e, err := EncodeTopicSegment(seg)
+			if err != nil {
+				return nil, err
+			}
+			encoded = append(encoded, e)
+		}
+		filter.Topics = append(filter.Topics, encoded)
+	}
+
+	key := FilterKey(opts.Network, opts.ContractIDs, opts.Topics)
+	cursor, resumed, err := store.Cursor(key)
+	if err != nil {
+		return nil, err
+	}
+
+	startLedger := opts.StartLedger
+	if !resumed && startLedger == 0 {
+		latest, err := src.GetLatestLedgerSequence(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine start ledger: %w", err)
+		}
+		startLedger = 1
+		if latest > DefaultLookbackLedgers {
+			startLedger = uint32(latest - DefaultLookbackLedgers)
+		}
+	}
+
+	result := &IngestResult{Cursor: cursor, Resumed: resumed}
+	for opts.MaxPages == 0 || result.Pages < opts.MaxPages {
+		req := &rpc.GetEventsRequest{
+			Filters:    []rpc.EventFilter{filter},
+			Pagination: &rpc.EventPagination{Limit: pageSize},
+		}
+		if result.Cursor != "" {
+			req.Pagination.Cursor = result.Cursor
+		} else {
+			req.StartLedger = startLedger
+		}
+
+		resp, err := src.GetEvents(ctx, req)
+		if err != nil {
+			return result, err
+		}
+		result.Pages++
+		result.LatestLedger = resp.Result.LatestLedger
+
+		decoded := make([]Event, 0, len(resp.Result.Events))
+		for _, info := range resp.Result.Events {
+			decoded = append(decoded, DecodeEvent(opts.Network, info))
+		}
+		inserted, err := store.Insert(decoded)
+		if err != nil {
+			return result, err
+		}
+		result.Fetched += len(decoded)
+		result.Inserted += inserted
+
+		if next := resp.NextCursor(); next != "" {
+			result.Cursor = next
+			if err := store.SaveCursor(key, next, resp.Result.LatestLedger); err != nil {
+				return result, err
+			}
+		}
+
+		logger.Logger.Debug("Ingested events page", "page", result.Pages, "events", len(decoded), "inserted", inserted)
+
+		if len(resp.Result.Events) < pageSize {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// DecodeEvent converts an RPC event into its indexed form. Topics and data
+// that fail to decode keep their raw XDR so nothing is silently dropped.
+func DecodeEvent(network string, info rpc.EventInfo) Event {
+	e := Event{
+		ID:             info.ID,
+		Network:        network,
+		ContractID:     info.ContractID,
+		Ledger:         info.Ledger,
+		TxHash:         info.TxHash,
+		Type:           info.Type,
+		TopicsXDR:      info.Topic,
+		DataXDR:        info.Value,
+		SuccessfulCall: info.InSuccessfulContractCall,
+		Topics:         make([]string, 0, len(info.Topic)),
+	}
+	if t, err := time.Parse(time.RFC3339, info.LedgerClosedAt); err == nil {
+		e.LedgerClosedAt = t.UTC()
+	}
+	for _, topic := range info.Topic {
+		e.Topics = append(e.Topics, decodeScVal(topic))
+	}
+	e.Data = decodeScVal(info.Value)
+	return e
+}
+
+func decodeScVal(b64 string) string {
+	if b64 == "" {
+		return ""
+	}
+	var v xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(b64, &v); err != nil {
+		return b64
+	}
+	return v.String()
+}
+
+// EncodeTopicSegment turns a user-supplied topic segment into the form
+// getEvents expects. Wildcards and base64 ScVal XDR pass through; anything
+// else is treated as a symbol, which is how most event names are published.
+func EncodeTopicSegment(seg string) (string, error) {
+	if seg == "*" || seg == "**" {
+		return seg, nil
+	}
+	var v xdr.ScVal
+	if err := xdr.SafeUnmarshalBase64(seg, &v); err == nil {
+		return seg, nil
+	}
+	sym := xdr.ScSymbol(seg)
+	encoded, err := xdr.MarshalBase64(xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode topic %q: %w", seg, err)
+	}
+	return encoded, nil
+}
+
+// FilterKey derives the cursor key for a network/contract/topic selection.
+// Contract order does not matter; topic order does.
+func FilterKey(network string, contractIDs []string, topics [][]string) string {
+	ids := append([]string(nil), contractIDs...)
+	sort.Strings(ids)
+
+	var b strings.Builder
+	b.WriteString(network)
+	b.WriteString("|")
+	b.WriteString(strings.Join(ids, ","))
+	for _, t := range topics {
+		b.WriteString("|")
+		b.WriteString(strings.Join(t, ","))
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/rpc"
+)
+
+// SampleOptions selects recent transactions that invoked a contract.
+type SampleOptions struct {
+	ContractID string
+	// Count is the number of distinct transactions to return.
+	Count int
+	// LookbackLedgers bounds the search window; 0 uses DefaultLookbackLedgers.
+	LookbackLedgers int
+	PageSize        int
+	// MaxPages caps the number of getEvents calls; 0 means no cap.
+	MaxPages int
+}
+
+// SampleTransactions returns up to Count hashes of the most recent
+// successful transactions in which the contract emitted an event, newest
+// first. Contracts that emit no events cannot be sampled this way.
+func SampleTransactions(ctx context.Context, src Source, opts SampleOptions) ([]string, error) {
+	if opts.ContractID == "" {
+		return nil, fmt.Errorf("contract ID is required")
+	}
+	if opts.Count <= 0 {
+		return nil, fmt.Errorf("sample count must be greater than 0")
+	}
+	lookback := opts.LookbackLedgers
+	if lookback <= 0 {
+		lookback = DefaultLookbackLedgers
+	}
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > rpc.MaxEventsPageSize {
+		pageSize = rpc.MaxEventsPageSize
+	}
+
+	latest, err := src.GetLatestLedgerSequence(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine start ledger: %w", err)
+	}
+	startLedger := uint32(1)
+	if latest > lookback {
+		startLedger = uint32(latest - lookback)
+	}
+
+	// getEvents only pages forward, so walk the window and keep the newest
+	// Count distinct hashes seen.
+	var hashes []string
+	seen := make(map[string]bool)
+	filter := rpc.EventFilter{Type: "contract", ContractIDs: []string{opts.ContractID}}
+	cursor := ""
+	for pages := 0; opts.MaxPages == 0 || pages < opts.MaxPages; pages++ {
+		req := &rpc.GetEventsRequest{
+			Filters:    []rpc.EventFilter{filter},
+			Pagination: &rpc.EventPagination{Limit: pageSize, Cursor: cursor},
+		}
+		if cursor == "" {
+			req.StartLedger = startLedger
+		}
+		resp, err := src.GetEvents(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range resp.Result.Events {
+			if e.TxHash == "" || !e.InSuccessfulContractCall || seen[e.TxHash] {
+				continue
+			}
+			seen[e.TxHash] = true
+			hashes = append(hashes, e.TxHash)
+			if len(hashes) > opts.Count {
+				delete(seen, hashes[0])
+				hashes = hashes[1:]
+			}
+		}
+
+		cursor = resp.NextCursor()
+		if len(resp.Result.Events) < pageSize || cursor == "" {
+			break
+		}
+	}
+
+	for i, j := 0, len(hashes)-1; i < j; i, j = i+1, j-1 {
+		hashes[i], hashes[j] = hashes[j], hashes[i]
+	}
+	return hashes, nil
+}
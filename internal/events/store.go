@@ -0,0 +1,283 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+// Package events maintains a local SQLite index of Soroban contract events
+// pulled from getEvents, so they can be queried over time without hitting RPC.
+package events
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dotandev/hintents/internal/rpc"
+	_ "modernc.org/sqlite"
+)
+
+// IndexDBName is the file name of the event index inside ~/.erst/.
+const IndexDBName = "events.db"
+
+// TopicWildcard matches any value at a topic position in a Query.
+const TopicWildcard = "*"
+
+// TopicRest matches any remaining topics. It is only valid as the last
+// segment of a topic filter.
+const TopicRest = "**"
+
+const indexSchema = `
+CREATE TABLE IF NOT EXISTS contract_events (
+	id                 TEXT PRIMARY KEY,
+	network            TEXT NOT NULL,
+	contract_id        TEXT NOT NULL,
+	ledger             INTEGER NOT NULL,
+	ledger_closed_at   INTEGER NOT NULL,
+	tx_hash            TEXT NOT NULL,
+	event_type         TEXT NOT NULL,
+	topics             TEXT NOT NULL,
+	topics_xdr         TEXT NOT NULL,
+	data               TEXT NOT NULL,
+	data_xdr           TEXT NOT NULL,
+	successful_call    INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_contract_events_contract ON contract_events(network, contract_id, ledger);
+CREATE INDEX IF NOT EXISTS idx_contract_events_closed_at ON contract_events(ledger_closed_at);
+CREATE TABLE IF NOT EXISTS event_cursors (
+	filter_key    TEXT PRIMARY KEY,
+	cursor        TEXT NOT NULL,
+	latest_ledger INTEGER NOT NULL,
+	updated_at    INTEGER NOT NULL
+);
+`
+
+// Event is a decoded contract event as stored in the index.
+type Event struct {
+	ID             string    `json:"id"`
+	Network        string    `json:"network"`
+	ContractID     string    `json:"contract_id"`
+	Ledger         uint32    `json:"ledger"`
+	LedgerClosedAt time.Time `json:"ledger_closed_at"`
+	TxHash         string    `json:"tx_hash"`
+	Type           string    `json:"type"`
+	Topics         []string  `json:"topics"`
+	TopicsXDR      []string  `json:"topics_xdr"`
+	Data           string    `json:"data"`
+	DataXDR        string    `json:"data_xdr"`
+	SuccessfulCall bool      `json:"successful_call"`
+}
+
+// Query selects events from the index. Zero values mean "no constraint".
+// Each entry of Topics is a filter matched positionally against the decoded
+// topics, and an event is kept when any filter matches it. Use TopicWildcard
+// to skip a position; positions past the end of a filter, or from a trailing
+// TopicRest, are not checked.
+type Query struct {
+	Network     string
+	ContractIDs []string
+	Topics      [][]string
+	Since       time.Time
+	Until       time.Time
+	FromLedger  uint32
+	ToLedger    uint32
+	Limit       int
+}
+
+// Store is the SQLite-backed event index.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the event index at ~/.erst/events.db.
+func Open() (*Store, error) {
+	db, err := rpc.OpenLocalDB(IndexDBName, indexSchema)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// NewStore wraps an already-open *sql.DB (e.g. an in-memory database for
+// testing) and applies the index schema. The caller owns the connection.
+func NewStore(db *sql.DB) (*Store, error) {
+	if _, err := db.Exec(indexSchema); err != nil {
+		return nil, fmt.Errorf("failed to initialize event index schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Insert stores events, ignoring ones already indexed. It returns the number
+// of newly inserted rows.
+func (s *Store) Insert(evts []Event) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin event insert: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO contract_events
+		(id, network, contract_id, ledger, ledger_closed_at, tx_hash, event_type,
+		 topics, topics_xdr, data, data_xdr, successful_call)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare event insert: %w", err)
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	for _, e := range evts {
+		topics, err := json.Marshal(e.Topics)
+		if err != nil {
+			return 0, err
+		}
+		topicsXDR, err := json.Marshal(e.TopicsXDR)
+		if err != nil {
+			return 0, err
+		}
+		res, err := stmt.Exec(e.ID, e.Network, e.ContractID, e.Ledger, e.LedgerClosedAt.Unix(),
+			e.TxHash, e.Type, string(topics), string(topicsXDR), e.Data, e.DataXDR, e.SuccessfulCall)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert event %s: %w", e.ID, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			inserted++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit events: %w", err)
+	}
+	return inserted, nil
+}
+
+// Find returns indexed events matching q, ordered by ledger then id.
+func (s *Store) Find(q Query) ([]Event, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	if q.Network != "" {
+		where = append(where, "network = ?")
+		args = append(args, q.Network)
+	}
+	if len(q.ContractIDs) > 0 {
+		where = append(where, "contract_id IN ("+strings.TrimSuffix(strings.Repeat("?,", len(q.ContractIDs)), ",")+")")
+		for _, id := range q.ContractIDs {
+			args = append(args, id)
+		}
+	}
+	if len(q.Topics) > 0 {
+		var filters []string
+		for _, filter := range q.Topics {
+			var conds []string
+			for i, topic := range filter {
+				if topic == TopicRest {
+					if i != len(filter)-1 {
+						return nil, fmt.Errorf("topic filter %q: %q must be the last segment", strings.Join(filter, ","), TopicRest)
+					}
+					break
+				}
+				if topic == TopicWildcard {
+					continue
+				}
+				conds = append(conds, fmt.Sprintf("json_extract(topics, '$[%d]') = ?", i))
+				args = append(args, topic)
+			}
+			if len(conds) == 0 {
+				conds = []string{"1"}
+			}
+			filters = append(filters, "("+strings.Join(conds, " AND ")+")")
+		}
+		where = append(where, "("+strings.Join(filters, " OR ")+")")
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "ledger_closed_at >= ?")
+		args = append(args, q.Since.Unix())
+	}
+	if !q.Until.IsZero() {
+		where = append(where, "ledger_closed_at <= ?")
+		args = append(args, q.Until.Unix())
+	}
+	if q.FromLedger > 0 {
+		where = append(where, "ledger >= ?")
+		args = append(args, q.FromLedger)
+	}
+	if q.ToLedger > 0 {
+		where = append(where, "ledger <= ?")
+		args = append(args, q.ToLedger)
+	}
+
+	query := `SELECT id, network, contract_id, ledger, ledger_closed_at, tx_hash, event_type,
+		topics, topics_xdr, data, data_xdr, successful_call FROM contract_events`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY ledger, id"
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("event query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Event
+	for rows.Next() {
+		var (
+			e                 Event
+			closedAt          int64
+			topics, topicsXDR string
+		)
+		if err := rows.Scan(&e.ID, &e.Network, &e.ContractID, &e.Ledger, &closedAt, &e.TxHash, &e.Type,
+			&topics, &topicsXDR, &e.Data, &e.DataXDR, &e.SuccessfulCall); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		e.LedgerClosedAt = time.Unix(closedAt, 0).UTC()
+		if err := json.Unmarshal([]byte(topics), &e.Topics); err != nil {
+			return nil, fmt.Errorf("corrupt topics for event %s: %w", e.ID, err)
+		}
+		if err := json.Unmarshal([]byte(topicsXDR), &e.TopicsXDR); err != nil {
+			return nil, fmt.Errorf("corrupt topics for event %s: %w", e.ID, err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// Cursor returns the stored resume cursor for filterKey, if any.
+func (s *Store) Cursor(filterKey string) (string, bool, error) {
+	var cursor string
+	err := s.db.QueryRow("SELECT cursor FROM event_cursors WHERE filter_key = ?", filterKey).Scan(&cursor)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("cursor read failed: %w", err)
+	}
+	return cursor, true, nil
+}
+
+// SaveCursor records the resume cursor for filterKey.
+func (s *Store) SaveCursor(filterKey, cursor string, latestLedger uint32) error {
+	_, err := s.db.Exec(
+		`INSERT INTO event_cursors (filter_key, cursor, latest_ledger, updated_at)
+		 VALUES (?, ?, ?, ?)
+		 ON CONFLICT(filter_key) DO UPDATE SET
+		   cursor = excluded.cursor,
+		   latest_ledger = excluded.latest_ledger,
+		   updated_at = excluded.updated_at`,
+		filterKey, cursor, latestLedger, time.Now().Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("cursor write failed: %w", err)
+	}
+	return nil
+}
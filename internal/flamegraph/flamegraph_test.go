@@ -0,0 +1,146 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package flamegraph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/trace"
+	"github.com/dotandev/hintents/internal/visualizer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleTrace() *trace.ExecutionTrace {
+	t := trace.NewExecutionTrace("tx", 10)
+	t.AddState(trace.ExecutionState{Operation: "contract_call", ContractID: "A", Function: "swap",
+		HostState: map[string]interface{}{"cpu_instructions": 100, "memory_bytes": 10}})
+	t.AddState(trace.ExecutionState{Operation: "contract_call", ContractID: "B", Function: "transfer",
+		HostState: map[string]interface{}{"cpu_instructions": 300, "memory_bytes": 40}})
+	t.AddState(trace.ExecutionState{Operation: "host_function", Function: "require_auth",
+		HostState: map[string]interface{}{"cpu_instructions": 50}})
+	t.AddState(trace.ExecutionState{Operation: "fn_return", ContractID: "B", Function: "transfer",
+		HostState: map[string]interface{}{"cpu_instructions": 20}})
+	return t
+}
+
+func findChild(f *Frame, name string) *Frame {
+	for _, c := range f.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestFromExecutionTrace(t *testing.T) {
+	root := FromExecutionTrace(sampleTrace(), WeightCPU)
+	assert.Equal(t, int64(470), root.Total)
+
+	swap := findChild(root, "A::swap")
+	require.NotNil(t, swap)
+	assert.Equal(t, int64(470), swap.Total)
+	assert.Equal(t, int64(120), swap.Self)
+
+	transfer := findChild(swap, "B::transfer")
+	require.NotNil(t, transfer)
+	assert.Equal(t, int64(350), transfer.Total)
+	assert.Equal(t, int64(50), findChild(transfer, "require_auth").Total)
+
+	mem := FromExecutionTrace(sampleTrace(), WeightMemory)
+	assert.Equal(t, int64(50), mem.Total)
+
+	calls := FromExecutionTrace(sampleTrace(), WeightCalls)
+	assert.Equal(t, int64(2), calls.Total)
+}
+
+func TestFromTraceNode(t *testing.T) {
+	tree := trace.CreateMockTrace()
+	root := FromTraceNode(tree, WeightCPU)
+	assert.Equal(t, int64(650000), root.Total)
+
+	balance := findChild(root, "CDLZFC3SYJYDZT7K67VZ75HPJVIEUVNIXF47ZG2FB2RMQQVU2HHGCYSC::get_balance")
+	require.NotNil(t, balance)
+	assert.Equal(t, int64(80000), balance.Self)
+	assert.Equal(t, int64(200000), balance.Total)
+
+	assert.True(t, HasCost(tree, WeightMemory))
+	assert.False(t, HasCost(trace.NewTraceNode("root", "simulation"), WeightCPU))
+}
+
+func TestDiff(t *testing.T) {
+	base := FromExecutionTrace(sampleTrace(), WeightCPU)
+
+	cur := sampleTrace()
+	cur.AddState(trace.ExecutionState{Operation: "contract_call", ContractID: "C", Function: "mint",
+		HostState: map[string]interface{}{"cpu_instructions": 30}})
+	d := Diff(base, FromExecutionTrace(cur, WeightCPU))
+
+	assert.True(t, d.IsDiff())
+	assert.Equal(t, int64(500), d.Total)
+	assert.Equal(t, int64(470), d.Baseline)
+	mint := findChild(findChild(d, "A::swap"), "C::mint")
+	require.NotNil(t, mint)
+	assert.Equal(t, int64(-1), mint.Baseline)
+	assert.False(t, base.IsDiff())
+}
+
+func TestRenderSVG(t *testing.T) {
+	root := FromExecutionTrace(sampleTrace(), WeightCPU)
+	svg := RenderSVG(root, Options{Title: "tx", Weight: WeightCPU, Search: "swap", Theme: visualizer.ThemeDefault})
+
+	assert.True(t, strings.HasPrefix(svg, "<svg"))
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(svg), "</svg>"))
+	assert.Contains(t, svg, "prefers-color-scheme")
+	assert.Contains(t, svg, `data-n="B::transfer"`)
+	assert.Contains(t, svg, "B::transfer (350 instructions")
+	assert.Contains(t, svg, `fgInitialSearch = "swap"`)
+	assert.Equal(t, 4, strings.Count(svg, `<g class="f"`))
+}
+
+func TestRenderSVG_Icicle(t *testing.T) {
+	root := FromExecutionTrace(sampleTrace(), WeightCPU)
+	rootY := func(svg string) string {
+		i := strings.Index(svg, `data-n="all"`)
+		j := strings.Index(svg[i:], ` y="`)
+		return svg[i+j : i+j+8]
+	}
+	flame := RenderSVG(root, Options{})
+	icicle := RenderSVG(root, Options{Icicle: true})
+	assert.Contains(t, rootY(icicle), `y="40"`)
+	assert.NotEqual(t, rootY(flame), rootY(icicle))
+}
+
+func TestRenderSVG_ThemesAndDiff(t *testing.T) {
+	root := FromExecutionTrace(sampleTrace(), WeightCPU)
+	def := RenderSVG(root, Options{Theme: visualizer.ThemeDefault})
+	deut := RenderSVG(root, Options{Theme: visualizer.ThemeDeuteranopia})
+	assert.NotEqual(t, def, deut)
+
+	diff := RenderSVG(Diff(root, root), Options{Weight: WeightCPU})
+	assert.Contains(t, diff, "+0 instructions")
+	assert.Contains(t, diff, "rgb(245,245,245)")
+}
+
+func TestRenderSVG_SearchEscaping(t *testing.T) {
+	svg := RenderSVG(FromExecutionTrace(sampleTrace(), WeightCPU), Options{Search: "a]]>b"})
+	assert.Equal(t, 1, strings.Count(svg, "]]>"))
+}
+
+func TestRenderHTML(t *testing.T) {
+	out := RenderHTML(FromExecutionTrace(sampleTrace(), WeightCPU), Options{Title: "<tx>"})
+	assert.True(t, strings.HasPrefix(out, "<!DOCTYPE html>"))
+	assert.Contains(t, out, "<title>&lt;tx&gt;</title>")
+	assert.Contains(t, out, `id="fg-query"`)
+	assert.Contains(t, out, "<svg")
+}
+
+func TestParseWeight(t *testing.T) {
+	w, err := ParseWeight("Memory")
+	require.NoError(t, err)
+	assert.Equal(t, WeightMemory, w)
+	_, err = ParseWeight("gas")
+	assert.Error(t, err)
+}
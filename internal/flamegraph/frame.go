@@ -0,0 +1,215 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+// Package flamegraph renders execution traces as flamegraph and icicle charts.
+package flamegraph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/trace"
+)
+
+// Weight selects the cost a flamegraph is sized by.
+type Weight string
+
+const (
+	WeightCPU    Weight = "cpu"
+	WeightMemory Weight = "memory"
+	// WeightCalls counts function invocations, for traces without budget data.
+	WeightCalls Weight = "calls"
+)
+
+// ParseWeight validates a weight name.
+func ParseWeight(s string) (Weight, error) {
+	switch w := Weight(strings.ToLower(s)); w {
+	case WeightCPU, WeightMemory, WeightCalls:
+		return w, nil
+	}
+	return "", fmt.Errorf("unknown weight %q (use cpu, memory or calls)", s)
+}
+
+// Unit returns the unit label for values of this weight.
+func (w Weight) Unit() string {
+	switch w {
+	case WeightCPU:
+		return "instructions"
+	case WeightMemory:
+		return "bytes"
+	default:
+		return "calls"
+	}
+}
+
+// Frame is one box in a flamegraph: a function and the cost spent in it and
+// everything it called, merged across all call sites with the same stack.
+type Frame struct {
+	Name     string
+	Self     int64
+	Total    int64
+	Children []*Frame
+	// Baseline is the Total of the same stack in the baseline profile of a
+	// differential flamegraph; -1 when the stack is new.
+	Baseline int64
+
+	diff bool
+}
+
+func newFrame(name string) *Frame {
+	return &Frame{Name: name}
+}
+
+func (f *Frame) child(name string) *Frame {
+	for _, c := range f.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	c := newFrame(name)
+	f.Children = append(f.Children, c)
+	return c
+}
+
+// add records cost for the stack below f, outermost frame first.
+func (f *Frame) add(stack []string, cost int64) {
+	f.Total += cost
+	node := f
+	for _, name := range stack {
+		node = node.child(name)
+		node.Total += cost
+	}
+	node.Self += cost
+}
+
+// FromExecutionTrace builds a flamegraph from the call stack reconstructed at
+// every step of the trace.
+func FromExecutionTrace(t *trace.ExecutionTrace, w Weight) *Frame {
+	root := newFrame("all")
+	var stack []string
+	for i := range t.States {
+		state := &t.States[i]
+		prev := len(stack)
+		stack = trace.AdvanceCallStack(stack, state)
+
+		var cost int64
+		switch w {
+		case WeightCalls:
+			if len(stack) > prev {
+				cost = 1
+			}
+		default:
+			cpu, mem := state.Cost()
+			cost = cpu
+			if w == WeightMemory {
+				cost = mem
+			}
+		}
+		if cost == 0 {
+			continue
+		}
+
+		frames := stack
+		switch trace.ClassifyEventType(state) {
+		case trace.EventTypeHostFunction, trace.EventTypeAuth:
+			if state.Function != "" {
+				frames = append(frames[:len(frames):len(frames)], state.Function)
+			}
+		}
+		root.add(frames, cost)
+	}
+	return root
+}
+
+// FromTraceNode builds a flamegraph from a trace tree. Node CPUDelta and
+// MemoryDelta values are the node's own cost, excluding its children.
+func FromTraceNode(n *trace.TraceNode, w Weight) *Frame {
+	root := newFrame("all")
+	var walk func(n *trace.TraceNode, stack []string)
+	walk = func(n *trace.TraceNode, stack []string) {
+		if name := nodeName(n); name != "" {
+			stack = append(stack[:len(stack):len(stack)], name)
+			if cost := nodeCost(n, w); cost > 0 {
+				root.add(stack, cost)
+			}
+		}
+		for _, c := range n.Children {
+			walk(c, stack)
+		}
+	}
+	walk(n, nil)
+	return root
+}
+
+// HasCost reports whether any node in the tree carries the given weight.
+func HasCost(n *trace.TraceNode, w Weight) bool {
+	if nodeCost(n, w) > 0 {
+		return true
+	}
+	for _, c := range n.Children {
+		if HasCost(c, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeName(n *trace.TraceNode) string {
+	if n.Function == "" {
+		return ""
+	}
+	if n.ContractID != "" {
+		return n.ContractID + "::" + n.Function
+	}
+	return n.Function
+}
+
+func nodeCost(n *trace.TraceNode, w Weight) int64 {
+	switch w {
+	case WeightCPU:
+		if n.CPUDelta != nil {
+			return int64(*n.CPUDelta)
+		}
+	case WeightMemory:
+		if n.MemoryDelta != nil {
+			return int64(*n.MemoryDelta)
+		}
+	case WeightCalls:
+		if nodeName(n) != "" {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Diff returns cur annotated with the baseline cost of each stack, for a
+// differential flamegraph. Frame sizes follow cur; stacks that only exist in
+// base are not shown.
+func Diff(base, cur *Frame) *Frame {
+	var walk func(b, c *Frame) *Frame
+	walk = func(b, c *Frame) *Frame {
+		out := &Frame{Name: c.Name, Self: c.Self, Total: c.Total, Baseline: -1, diff: true}
+		if b != nil {
+			out.Baseline = b.Total
+		}
+		for _, cc := range c.Children {
+			var bc *Frame
+			if b != nil {
+				for _, x := range b.Children {
+					if x.Name == cc.Name {
+						bc = x
+						break
+					}
+				}
+			}
+			out.Children = append(out.Children, walk(bc, cc))
+		}
+		return out
+	}
+	return walk(base, cur)
+}
+
+// IsDiff reports whether the frame was produced by Diff.
+func (f *Frame) IsDiff() bool {
+	return f.diff
+}
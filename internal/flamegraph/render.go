@@ -0,0 +1,409 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package flamegraph
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"math"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/visualizer"
+)
+
+const (
+	defaultWidth = 1200
+	frameHeight  = 16
+	xPad         = 10
+	topPad       = 40
+	bottomPad    = 30
+	// minFrameWidth hides frames too narrow to see, in pixels.
+	minFrameWidth = 0.1
+	// charWidth approximates the width of one label character at 12px.
+	charWidth = 7.0
+	// searchColor matches the highlight rule in visualizer's dark-mode CSS.
+	searchColor = "rgb(230,0,230)"
+)
+
+// Options controls rendering.
+type Options struct {
+	Title  string
+	Weight Weight
+	// Width of the chart in pixels; 0 uses 1200.
+	Width int
+	// Icicle draws the root at the top with callees hanging below it.
+	Icicle bool
+	// Theme selects the palette; "" uses the active visualizer theme.
+	Theme visualizer.Theme
+	// Search highlights frames matching this regular expression on load.
+	Search string
+}
+
+type box struct {
+	frame *Frame
+	x, w  float64
+	depth int
+}
+
+// RenderSVG renders the frame tree as an interactive SVG. Clicking a frame
+// zooms into it, and the Search control highlights frames by regex.
+func RenderSVG(root *Frame, opts Options) string {
+	width := opts.Width
+	if width <= 0 {
+		width = defaultWidth
+	}
+	theme := opts.Theme
+	if theme == "" {
+		theme = visualizer.GetTheme()
+	}
+
+	chartWidth := float64(width - 2*xPad)
+	var boxes []box
+	maxDepth := 0
+	var layout func(f *Frame, x float64, depth int)
+	layout = func(f *Frame, x float64, depth int) {
+		w := 0.0
+		if root.Total > 0 {
+			w = float64(f.Total) / float64(root.Total) * chartWidth
+		}
+		if w < minFrameWidth {
+			return
+		}
+		boxes = append(boxes, box{frame: f, x: x, w: w, depth: depth})
+		maxDepth = max(maxDepth, depth)
+		for _, c := range f.Children {
+			layout(c, x, depth+1)
+			x += float64(c.Total) / float64(root.Total) * chartWidth
+		}
+	}
+	layout(root, xPad, 0)
+
+	height := topPad + bottomPad + (maxDepth+1)*frameHeight
+	y := func(depth int) int {
+		if opts.Icicle {
+			return topPad + depth*frameHeight
+		}
+		return height - bottomPad - (depth+1)*frameHeight
+	}
+
+	unit := opts.Weight.Unit()
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" xmlns="http://www.w3.org/2000/svg">`+"\n", width, height, width, height)
+	b.WriteString(`<style type="text/css">
+text { font-family: monospace; font-size: 12px; fill: rgb(0,0,0); }
+#title { font-size: 17px; }
+#search, #unzoom { cursor: pointer; }
+.f { cursor: pointer; }
+.f:hover rect { stroke: rgb(0,0,0); stroke-width: 0.5; }
+</style>
+`)
+	fmt.Fprintf(&b, `<rect class="background" x="0" y="0" width="%d" height="%d" fill="rgb(255,255,255)"/>`+"\n", width, height)
+	fmt.Fprintf(&b, `<text id="title" class="title" x="%d" y="24" text-anchor="middle">%s</text>`+"\n", width/2, html.EscapeString(opts.Title))
+	fmt.Fprintf(&b, `<text id="unzoom" x="%d" y="24" style="opacity:0">Reset Zoom</text>`+"\n", xPad)
+	fmt.Fprintf(&b, `<text id="search" x="%d" y="24" text-anchor="end">Search</text>`+"\n", width-xPad)
+	fmt.Fprintf(&b, `<text id="details" x="%d" y="%d"> </text>`+"\n", xPad, height-10)
+	fmt.Fprintf(&b, `<text id="matched" x="%d" y="%d" text-anchor="end"> </text>`+"\n", width-xPad, height-10)
+
+	b.WriteString(`<g id="frames">` + "\n")
+	for _, bx := range boxes {
+		f := bx.frame
+		info := fmt.Sprintf("%s (%s %s, %.2f%%", f.Name, formatCount(f.Total), unit, percent(f.Total, root.Total))
+		if f.IsDiff() {
+			info += ", " + describeDelta(f, unit)
+		}
+		info += ")"
+		fill := frameColor(f, theme)
+		fmt.Fprintf(&b, `<g class="f" data-n="%s" data-x="%.2f" data-w="%.2f" data-d="%d">`,
+			html.EscapeString(f.Name), bx.x, bx.w, bx.depth)
+		fmt.Fprintf(&b, `<title>%s</title>`, html.EscapeString(info))
+		fmt.Fprintf(&b, `<rect x="%.2f" y="%d" width="%.2f" height="%d" fill="%s" data-c="%s" rx="2" ry="2"/>`,
+			bx.x, y(bx.depth), bx.w, frameHeight-1, fill, fill)
+		fmt.Fprintf(&b, `<text x="%.2f" y="%d">%s</text></g>`+"\n",
+			bx.x+3, y(bx.depth)+frameHeight-4, html.EscapeString(fitLabel(displayName(f.Name), bx.w)))
+	}
+	b.WriteString("</g>\n")
+
+	// Keep the search term from closing the CDATA section.
+	query := strings.ReplaceAll(fmt.Sprintf("%q", opts.Search), "]]>", `]]\u003e`)
+	fmt.Fprintf(&b, "<script type=\"text/ecmascript\"><![CDATA[\nvar fgChartWidth = %.2f, fgPad = %d, fgInitialSearch = %s;\n%s]]></script>\n",
+		chartWidth, xPad, query, interactiveJS)
+	b.WriteString("</svg>\n")
+
+	return visualizer.InjectDarkMode(b.String())
+}
+
+// RenderHTML wraps the interactive SVG in a standalone HTML page with a
+// search box.
+func RenderHTML(root *Frame, opts Options) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(opts.Title))
+	b.WriteString(`<style>
+body { margin: 0; padding: 8px; font-family: sans-serif; }
+#controls { margin-bottom: 8px; }
+#controls input { width: 320px; padding: 4px; }
+@media (prefers-color-scheme: dark) { body { background: #1e1e2e; color: #cdd6f4; } }
+</style>
+</head>
+<body>
+<div id="controls">
+<input id="fg-query" type="search" placeholder="Search functions (regex)" oninput="search(this.value)">
+<button onclick="search(''); document.getElementById('fg-query').value = ''">Clear</button>
+<button onclick="unzoom()">Reset zoom</button>
+</div>
+`)
+	b.WriteString(RenderSVG(root, opts))
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// frameColor picks a fill for a frame. Regular graphs hash the function name
+// into the theme's palette so a function keeps its color across charts;
+// differential graphs shade by how much the frame grew or shrank.
+func frameColor(f *Frame, theme visualizer.Theme) string {
+	grow, shrink, neutral := diffPalette(theme)
+	if f.IsDiff() {
+		if f.Baseline < 0 {
+			return rgb(grow)
+		}
+		delta := float64(f.Total - f.Baseline)
+		scale := float64(max(f.Total, f.Baseline))
+		if scale == 0 || delta == 0 {
+			return rgb(neutral)
+		}
+		t := math.Min(math.Abs(delta)/scale, 1)
+		if delta > 0 {
+			return rgb(mix(neutral, grow, t))
+		}
+		return rgb(mix(neutral, shrink, t))
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(f.Name))
+	v := float64(h.Sum32()%1000) / 1000
+	lo, hi := namePalette(theme)
+	return rgb(mix(lo, hi, v))
+}
+
+type color [3]float64
+
+func namePalette(theme visualizer.Theme) (color, color) {
+	switch theme {
+	case visualizer.ThemeDeuteranopia, visualizer.ThemeProtanopia:
+		// Blue to yellow avoids the red-green axis.
+		return color{70, 120, 220}, color{240, 210, 80}
+	case visualizer.ThemeTritanopia:
+		// Red to cyan avoids the blue-yellow axis.
+		return color{220, 70, 70}, color{80, 200, 210}
+	case visualizer.ThemeHighContrast:
+		return color{170, 170, 170}, color{250, 250, 250}
+	default:
+		return color{205, 60, 0}, color{250, 200, 55}
+	}
+}
+
+func diffPalette(theme visualizer.Theme) (grow, shrink, neutral color) {
+	neutral = color{245, 245, 245}
+	switch theme {
+	case visualizer.ThemeDeuteranopia, visualizer.ThemeProtanopia:
+		return color{230, 140, 20}, color{40, 90, 220}, neutral
+	case visualizer.ThemeTritanopia:
+		return color{220, 40, 40}, color{20, 160, 160}, neutral
+	case visualizer.ThemeHighContrast:
+		return color{200, 0, 0}, color{0, 0, 200}, color{255, 255, 255}
+	default:
+		return color{230, 60, 50}, color{60, 110, 230}, neutral
+	}
+}
+
+func mix(a, b color, t float64) color {
+	return color{a[0] + (b[0]-a[0])*t, a[1] + (b[1]-a[1])*t, a[2] + (b[2]-a[2])*t}
+}
+
+func rgb(c color) string {
+	return fmt.Sprintf("rgb(%d,%d,%d)", int(c[0]), int(c[1]), int(c[2]))
+}
+
+func describeDelta(f *Frame, unit string) string {
+	if f.Baseline < 0 {
+		return "new"
+	}
+	delta := f.Total - f.Baseline
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+	}
+	s := fmt.Sprintf("%s%s %s", sign, formatCount(abs(delta)), unit)
+	if f.Baseline > 0 {
+		s += fmt.Sprintf(" / %s%.2f%%", sign, math.Abs(percent(delta, f.Baseline)))
+	}
+	return s + " vs baseline"
+}
+
+// displayName shortens contract IDs in frame labels; the full name stays in
+// the tooltip.
+func displayName(name string) string {
+	contract, fn, ok := strings.Cut(name, "::")
+	if !ok || len(contract) <= 12 {
+		return name
+	}
+	return contract[:4] + "…" + contract[len(contract)-4:] + "::" + fn
+}
+
+func fitLabel(label string, width float64) string {
+	n := int((width - 6) / charWidth)
+	runes := []rune(label)
+	switch {
+	case n < 3:
+		return ""
+	case len(runes) <= n:
+		return label
+	default:
+		return string(runes[:n-2]) + ".."
+	}
+}
+
+func percent(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole) * 100
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func formatCount(n int64) string {
+	s := fmt.Sprint(n)
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	var out []byte
+	for i := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			out = append(out, ',')
+		}
+		out = append(out, s[i])
+	}
+	return string(out)
+}
+
+// interactiveJS implements click-to-zoom and regex search over the frames
+// emitted by RenderSVG. It runs at the end of the document, so it needs no
+// onload hook and works both standalone and inlined in HTML.
+const interactiveJS = `
+(function () {
+  var frames = document.getElementById("frames").getElementsByTagName("g");
+  var details = document.getElementById("details");
+  var matchedText = document.getElementById("matched");
+  var unzoomBtn = document.getElementById("unzoom");
+  var searchBtn = document.getElementById("search");
+
+  function attr(g, name) { return g.getAttribute("data-" + name); }
+  function num(g, name) { return parseFloat(attr(g, name)); }
+
+  function label(g, width) {
+    var name = attr(g, "n");
+    var parts = name.split("::");
+    if (parts.length > 1 && parts[0].length > 12) {
+      name = parts[0].slice(0, 4) + "…" + parts[0].slice(-4) + "::" + parts.slice(1).join("::");
+    }
+    var n = Math.floor((width - 6) / 7);
+    if (n < 3) return "";
+    if (name.length <= n) return name;
+    return name.slice(0, n - 2) + "..";
+  }
+
+  function place(g, x, w, visible, dim) {
+    var rect = g.getElementsByTagName("rect")[0];
+    var text = g.getElementsByTagName("text")[0];
+    g.style.display = visible ? "" : "none";
+    if (!visible) return;
+    rect.setAttribute("x", x.toFixed(2));
+    rect.setAttribute("width", w.toFixed(2));
+    text.setAttribute("x", (x + 3).toFixed(2));
+    text.textContent = label(g, w);
+    g.style.opacity = dim ? "0.5" : "";
+  }
+
+  window.zoom = function (target) {
+    var zx = num(target, "x"), zw = num(target, "w"), zd = num(target, "d");
+    var scale = fgChartWidth / zw;
+    for (var i = 0; i < frames.length; i++) {
+      var g = frames[i];
+      var x = num(g, "x"), w = num(g, "w"), d = num(g, "d");
+      var inside = x >= zx - 0.01 && x + w <= zx + zw + 0.01;
+      if (d < zd) {
+        place(g, fgPad, fgChartWidth, x <= zx + 0.01 && x + w >= zx + zw - 0.01, true);
+      } else {
+        place(g, fgPad + (x - zx) * scale, w * scale, inside && w * scale >= 0.1, false);
+      }
+    }
+    unzoomBtn.style.opacity = zd > 0 ? "1" : "0";
+  };
+
+  window.unzoom = function () {
+    for (var i = 0; i < frames.length; i++) {
+      var g = frames[i];
+      place(g, num(g, "x"), num(g, "w"), true, false);
+    }
+    unzoomBtn.style.opacity = "0";
+  };
+
+  window.search = function (term) {
+    var re = null;
+    if (term) {
+      try { re = new RegExp(term); } catch (e) { return; }
+    }
+    var hits = [];
+    for (var i = 0; i < frames.length; i++) {
+      var g = frames[i];
+      var rect = g.getElementsByTagName("rect")[0];
+      if (re && re.test(attr(g, "n"))) {
+        rect.setAttribute("fill", "` + searchColor + `");
+        hits.push([num(g, "x"), num(g, "x") + num(g, "w")]);
+      } else {
+        rect.setAttribute("fill", rect.getAttribute("data-c"));
+      }
+    }
+    // Nested matches overlap their ancestors; merge intervals before summing.
+    hits.sort(function (a, b) { return a[0] - b[0]; });
+    var covered = 0, end = -1;
+    for (var j = 0; j < hits.length; j++) {
+      var s = Math.max(hits[j][0], end);
+      if (hits[j][1] > s) covered += hits[j][1] - s;
+      end = Math.max(end, hits[j][1]);
+    }
+    matchedText.textContent = re ? "Matched: " + (covered / fgChartWidth * 100).toFixed(1) + "%" : " ";
+    searchBtn.textContent = re ? "Reset Search" : "Search";
+  };
+
+  searchBtn.addEventListener("click", function () {
+    if (searchBtn.textContent === "Search") {
+      var term = prompt("Search for (regex):", "");
+      if (term) window.search(term);
+    } else {
+      window.search("");
+    }
+  });
+  unzoomBtn.addEventListener("click", window.unzoom);
+
+  for (var i = 0; i < frames.length; i++) {
+    (function (g) {
+      g.addEventListener("click", function () { window.zoom(g); });
+      g.addEventListener("mouseover", function () {
+        details.textContent = g.getElementsByTagName("title")[0].textContent;
+      });
+      g.addEventListener("mouseout", function () { details.textContent = " "; });
+    })(frames[i]);
+  }
+
+  if (fgInitialSearch) window.search(fgInitialSearch);
+})();
+`
@@ -0,0 +1,317 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+// Package footprint infers the footprint a Soroban transaction needs by
+// running it in the simulator with a recording footprint, compares it with
+// the footprint the transaction declares, and builds corrected
+// SorobanTransactionData with resources sized from the run.
+package footprint
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// MaxRounds bounds the recording runs of Infer. Every run may touch keys
+// that only become reachable once the entries of the previous run are
+// loaded, e.g. the code of a contract whose instance was just fetched.
+const MaxRounds = 5
+
+// DefaultMarginPercent is the headroom added to the measured instructions.
+const DefaultMarginPercent = 10
+
+// EntryReader fetches the ledger entries that exist for a set of keys.
+// *rpc.Client implements it.
+type EntryReader interface {
+	GetLedgerEntryTTLs(ctx context.Context, keys []string) (map[string]rpc.LedgerEntryTTL, uint32, error)
+}
+
+// IssueKind classifies a difference between the declared and the required
+// footprint.
+type IssueKind string
+
+const (
+	// IssueMissing is a key the transaction touches but does not declare.
+	IssueMissing IssueKind = "missing"
+	// IssueNeedsReadWrite is a key declared read-only that the transaction
+	// writes.
+	IssueNeedsReadWrite IssueKind = "needs-read-write"
+	// IssueOnlyRead is a key declared read-write that the transaction only
+	// reads.
+	IssueOnlyRead IssueKind = "only-read"
+	// IssueSuperfluous is a declared key the transaction never touches.
+	IssueSuperfluous IssueKind = "superfluous"
+)
+
+// Issue is one difference between the declared and the required footprint.
+type Issue struct {
+	Kind   IssueKind `json:"kind"`
+	KeyXdr string    `json:"key_xdr"`
+	Key    string    `json:"key"`
+	// ReadWrite is the access the transaction needs; false for superfluous
+	// keys.
+	ReadWrite bool `json:"read_write"`
+}
+
+// Resources are the sizes of a SorobanResources, with the entry counts of
+// its footprint and the resource fee.
+type Resources struct {
+	Instructions  uint32 `json:"instructions"`
+	DiskReadBytes uint32 `json:"disk_read_bytes"`
+	WriteBytes    uint32 `json:"write_bytes"`
+	ReadEntries   int    `json:"read_entries"`
+	WriteEntries  int    `json:"write_entries"`
+	ResourceFee   int64  `json:"resource_fee"`
+}
+
+// Options tune Infer.
+type Options struct {
+	// MarginPercent is added to the measured instructions; 0 selects
+	// DefaultMarginPercent and a negative value none.
+	MarginPercent int
+}
+
+// Report is the result of Infer.
+type Report struct {
+	Issues   []Issue   `json:"issues"`
+	Declared Resources `json:"declared"`
+	Required Resources `json:"required"`
+	// Rounds is the number of recording runs it took for the footprint to
+	// settle.
+	Rounds int `json:"rounds"`
+	// SimulationError is set when the recording run failed; the footprint
+	// then only covers what ran before the failure.
+	SimulationError string `json:"simulation_error,omitempty"`
+	// SorobanData is the corrected SorobanTransactionData as base64 XDR.
+	SorobanData string `json:"soroban_data"`
+
+	// Recorded is the footprint the transaction needs.
+	Recorded xdr.LedgerFootprint `json:"-"`
+}
+
+// Infer runs the transaction of req with a recording footprint until the set
+// of touched keys settles, fetching the entries of newly touched keys from
+// src between runs, and compares the result with the declared footprint.
+func Infer(ctx context.Context, runner simulator.RunnerInterface, src EntryReader, req simulator.SimulationRequest, opts Options) (*Report, error) {
+	declared, err := simulator.DecodeSorobanData(req.EnvelopeXdr)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := maps.Clone(req.LedgerEntries)
+	if entries == nil {
+		entries = map[string]string{}
+	}
+	fetched := map[string]bool{}
+	var pending []string
+	fp := declared.Resources.Footprint
+	for _, k := range append(append([]xdr.LedgerKey{}, fp.ReadOnly...), fp.ReadWrite...) {
+		keyB64, err := xdr.MarshalBase64(k)
+		if err != nil {
+			return nil, fmt.Errorf("encode ledger key: %w", err)
+		}
+		pending = append(pending, keyB64)
+	}
+
+	req.RecordFootprint = true
+	var resp *simulator.SimulationResponse
+	rounds := 0
+	for rounds < MaxRounds {
+		latest, err := fetchEntries(ctx, src, entries, fetched, pending)
+		if err != nil {
+			return nil, err
+		}
+		// Run in the ledger after the one the entries were read at.
+		if req.LedgerSequence == 0 && latest != 0 {
+			req.LedgerSequence = latest + 1
+		}
+		req.LedgerEntries = entries
+		rounds++
+		resp, err = runner.Run(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Footprint == nil {
+			return nil, fmt.Errorf("the simulator did not report a recorded footprint")
+		}
+		pending = nil
+		for _, k := range append(append([]string{}, resp.Footprint.ReadOnly...), resp.Footprint.ReadWrite...) {
+			if !fetched[k] {
+				pending = append(pending, k)
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+	}
+
+	recorded, err := DecodeRecorded(resp.Footprint)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := Compare(declared.Resources.Footprint, recorded)
+	if err != nil {
+		return nil, err
+	}
+
+	var cpu uint64
+	if resp.BudgetUsage != nil {
+		cpu = resp.BudgetUsage.CPUInstructions
+	}
+	margin := opts.MarginPercent
+	if margin == 0 {
+		margin = DefaultMarginPercent
+	}
+	required, err := SizeResources(recorded, entries, resp.LedgerChanges, cpu, margin)
+	if err != nil {
+		return nil, err
+	}
+	required.ResourceFee = int64(declared.ResourceFee)
+	if gas, err := simulator.ExtractGasEstimation(resp); err == nil && gas.EstimatedFeeUpperBound > required.ResourceFee {
+		required.ResourceFee = gas.EstimatedFeeUpperBound
+	}
+
+	report := &Report{
+		Issues:   issues,
+		Declared: DeclaredResources(*declared),
+		Required: required,
+		Rounds:   rounds,
+		Recorded: recorded,
+	}
+	if resp.Status == "error" {
+		report.SimulationError = resp.Error
+	}
+	report.SorobanData, err = xdr.MarshalBase64(xdr.SorobanTransactionData{
+		ResourceFee: xdr.Int64(required.ResourceFee),
+		Resources: xdr.SorobanResources{
+			Footprint:     recorded,
+			Instructions:  xdr.Uint32(required.Instructions),
+			DiskReadBytes: xdr.Uint32(required.DiskReadBytes),
+			WriteBytes:    xdr.Uint32(required.WriteBytes),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode soroban data: %w", err)
+	}
+	return report, nil
+}
+
+// fetchEntries loads the entries of the keys not fetched before and returns
+// the latest ledger of the read, 0 when nothing was read. Keys the network
+// does not have are remembered too, as the transaction may create them.
+func fetchEntries(ctx context.Context, src EntryReader, entries map[string]string, fetched map[string]bool, keys []string) (uint32, error) {
+	var want []string
+	for _, k := range keys {
+		if fetched[k] {
+			continue
+		}
+		fetched[k] = true
+		if _, ok := entries[k]; !ok {
+			want = append(want, k)
+		}
+	}
+	if len(want) == 0 {
+		return 0, nil
+	}
+	found, latest, err := src.GetLedgerEntryTTLs(ctx, want)
+	if err != nil {
+		return 0, err
+	}
+	for k, e := range found {
+		entries[k] = e.EntryXdr
+	}
+	return latest, nil
+}
+
+// DecodeRecorded decodes a recorded footprint, sorting its keys.
+func DecodeRecorded(fp *simulator.RecordedFootprint) (xdr.LedgerFootprint, error) {
+	var out xdr.LedgerFootprint
+	decode := func(keys []string) ([]xdr.LedgerKey, error) {
+		sorted := append([]string(nil), keys...)
+		sort.Strings(sorted)
+		var res []xdr.LedgerKey
+		for _, k := range sorted {
+			var key xdr.LedgerKey
+			if err := xdr.SafeUnmarshalBase64(k, &key); err != nil {
+				return nil, fmt.Errorf("decode recorded ledger key: %w", err)
+			}
+			res = append(res, key)
+		}
+		return res, nil
+	}
+	var err error
+	if out.ReadOnly, err = decode(fp.ReadOnly); err != nil {
+		return out, err
+	}
+	if out.ReadWrite, err = decode(fp.ReadWrite); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// Compare lists the differences between a declared and a required footprint:
+// keys missing from the declaration, keys declared with the wrong access and
+// keys declared for nothing.
+func Compare(declared, required xdr.LedgerFootprint) ([]Issue, error) {
+	declaredAccess, err := access(declared)
+	if err != nil {
+		return nil, err
+	}
+	requiredAccess, err := access(required)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	add := func(kind IssueKind, k string, rw bool) {
+		issues = append(issues, Issue{Kind: kind, KeyXdr: k, Key: describeKey(k), ReadWrite: rw})
+	}
+	for _, k := range sortedKeys(requiredAccess) {
+		rw := requiredAccess[k]
+		declaredRW, ok := declaredAccess[k]
+		switch {
+		case !ok:
+			add(IssueMissing, k, rw)
+		case rw && !declaredRW:
+			add(IssueNeedsReadWrite, k, rw)
+		case !rw && declaredRW:
+			add(IssueOnlyRead, k, rw)
+		}
+	}
+	for _, k := range sortedKeys(declaredAccess) {
+		if _, ok := requiredAccess[k]; !ok {
+			add(IssueSuperfluous, k, false)
+		}
+	}
+	return issues, nil
+}
+
+// access maps the keys of a footprint to whether they are read-write.
+func access(fp xdr.LedgerFootprint) (map[string]bool, error) {
+	out := map[string]bool{}
+	for i, keys := range [][]xdr.LedgerKey{fp.ReadOnly, fp.ReadWrite} {
+		for _, k := range keys {
+			keyB64, err := xdr.MarshalBase64(k)
+			if err != nil {
+				return nil, fmt.Errorf("encode ledger key: %w", err)
+			}
+			out[keyB64] = out[keyB64] || i == 1
+		}
+	}
+	return out, nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,192 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package footprint
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const source = "GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7"
+
+type fakeReader map[string]string
+
+func (f fakeReader) GetLedgerEntryTTLs(_ context.Context, keys []string) (map[string]rpc.LedgerEntryTTL, uint32, error) {
+	out := map[string]rpc.LedgerEntryTTL{}
+	for _, k := range keys {
+		if e, ok := f[k]; ok {
+			out[k] = rpc.LedgerEntryTTL{EntryXdr: e, LiveUntil: 100}
+		}
+	}
+	return out, 10, nil
+}
+
+func contractKey(sym string) xdr.LedgerKey {
+	var id xdr.ContractId
+	id[0] = 0xab
+	key := xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance}
+	if sym != "" {
+		s := xdr.ScSymbol(sym)
+		key = xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &s}
+	}
+	return xdr.LedgerKey{Type: xdr.LedgerEntryTypeContractData, ContractData: &xdr.LedgerKeyContractData{
+		Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &id},
+		Key:        key,
+		Durability: xdr.ContractDataDurabilityPersistent,
+	}}
+}
+
+func codeKey() xdr.LedgerKey {
+	return xdr.LedgerKey{Type: xdr.LedgerEntryTypeContractCode, ContractCode: &xdr.LedgerKeyContractCode{Hash: xdr.Hash{1}}}
+}
+
+func b64(t *testing.T, k xdr.LedgerKey) string {
+	t.Helper()
+	s, err := xdr.MarshalBase64(k)
+	require.NoError(t, err)
+	return s
+}
+
+func envelope(t *testing.T, readOnly, readWrite []xdr.LedgerKey) string {
+	t.Helper()
+	return envelopeFor(t, xdr.HostFunction{
+		Type: xdr.HostFunctionTypeHostFunctionTypeUploadContractWasm, Wasm: &[]byte{},
+	}, readOnly, readWrite)
+}
+
+func envelopeFor(t *testing.T, fn xdr.HostFunction, readOnly, readWrite []xdr.LedgerKey) string {
+	t.Helper()
+	env := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{Tx: xdr.Transaction{
+			SourceAccount: xdr.MustMuxedAddress(source),
+			Fee:           1000,
+			Operations: []xdr.Operation{{Body: xdr.OperationBody{
+				Type:                 xdr.OperationTypeInvokeHostFunction,
+				InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{HostFunction: fn},
+			}}},
+			Ext: xdr.TransactionExt{V: 1, SorobanData: &xdr.SorobanTransactionData{
+				ResourceFee: 50,
+				Resources: xdr.SorobanResources{
+					Footprint:    xdr.LedgerFootprint{ReadOnly: readOnly, ReadWrite: readWrite},
+					Instructions: 5_000_000,
+				},
+			}},
+		}},
+	}
+	s, err := xdr.MarshalBase64(env)
+	require.NoError(t, err)
+	return s
+}
+
+func TestInfer(t *testing.T) {
+	instance, balance, unused, code := contractKey(""), contractKey("balance"), contractKey("unused"), codeKey()
+	entry := strings.Repeat("A", 400) // 300 bytes once decoded
+	larger := strings.Repeat("A", 800)
+	reader := fakeReader{
+		b64(t, instance): entry,
+		b64(t, balance):  entry,
+		b64(t, code):     entry,
+	}
+
+	var runs int
+	runner := simulator.NewMockRunner(func(_ context.Context, req *simulator.SimulationRequest) (*simulator.SimulationResponse, error) {
+		runs++
+		assert.True(t, req.RecordFootprint)
+		assert.Equal(t, uint32(11), req.LedgerSequence)
+		fp := &simulator.RecordedFootprint{ReadOnly: []string{b64(t, instance)}, ReadWrite: []string{b64(t, balance)}}
+		// The code only becomes reachable once the instance is loaded.
+		if _, ok := req.LedgerEntries[b64(t, instance)]; ok {
+			fp.ReadOnly = append(fp.ReadOnly, b64(t, code))
+		}
+		return &simulator.SimulationResponse{
+			Status:        "success",
+			Footprint:     fp,
+			BudgetUsage:   &simulator.BudgetUsage{CPUInstructions: 1_000_000},
+			LedgerChanges: []simulator.LedgerEntryChange{{KeyXdr: b64(t, balance), Type: simulator.LedgerChangeUpdated, EntryXdr: larger}},
+		}, nil
+	})
+
+	env := envelope(t, []xdr.LedgerKey{balance, unused}, []xdr.LedgerKey{instance})
+	report, err := Infer(context.Background(), runner, reader, simulator.SimulationRequest{EnvelopeXdr: env}, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, runs)
+	assert.Equal(t, 2, report.Rounds)
+
+	kinds := map[string]IssueKind{}
+	for _, issue := range report.Issues {
+		kinds[issue.KeyXdr] = issue.Kind
+	}
+	assert.Equal(t, map[string]IssueKind{
+		b64(t, code):     IssueMissing,
+		b64(t, balance):  IssueNeedsReadWrite,
+		b64(t, instance): IssueOnlyRead,
+		b64(t, unused):   IssueSuperfluous,
+	}, kinds)
+
+	assert.Equal(t, Resources{
+		Instructions:  1_100_000,
+		DiskReadBytes: 900,
+		WriteBytes:    600,
+		ReadEntries:   3,
+		WriteEntries:  1,
+		ResourceFee:   230,
+	}, report.Required)
+	assert.Equal(t, uint32(5_000_000), report.Declared.Instructions)
+
+	var data xdr.SorobanTransactionData
+	require.NoError(t, xdr.SafeUnmarshalBase64(report.SorobanData, &data))
+	assert.Len(t, data.Resources.Footprint.ReadOnly, 2)
+	assert.Equal(t, []xdr.LedgerKey{balance}, data.Resources.Footprint.ReadWrite)
+	assert.Equal(t, xdr.Uint32(1_100_000), data.Resources.Instructions)
+}
+
+func TestInfer_NoRecording(t *testing.T) {
+	runner := simulator.NewMockRunner(func(context.Context, *simulator.SimulationRequest) (*simulator.SimulationResponse, error) {
+		return &simulator.SimulationResponse{Status: "success"}, nil
+	})
+	env := envelope(t, nil, []xdr.LedgerKey{contractKey("")})
+	_, err := Infer(context.Background(), runner, fakeReader{}, simulator.SimulationRequest{EnvelopeXdr: env}, Options{})
+	assert.ErrorContains(t, err, "recorded footprint")
+}
+
+// TestInfer_Simulator runs the erst-sim binary, when one is found, on a call
+// to a contract the ledger does not have: the instance read is recorded
+// before the call fails.
+func TestInfer_Simulator(t *testing.T) {
+	runner, err := simulator.NewRunner("", false)
+	if err != nil {
+		t.Skipf("erst-sim not available: %v", err)
+	}
+	defer func() { _ = runner.Close() }()
+
+	instance := contractKey("")
+	fn := xdr.HostFunction{
+		Type: xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+		InvokeContract: &xdr.InvokeContractArgs{
+			ContractAddress: instance.ContractData.Contract,
+			FunctionName:    "hello",
+		},
+	}
+	env := envelopeFor(t, fn, []xdr.LedgerKey{codeKey()}, nil)
+
+	report, err := Infer(context.Background(), runner, fakeReader{}, simulator.SimulationRequest{EnvelopeXdr: env}, Options{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, report.SimulationError)
+	assert.Contains(t, report.Recorded.ReadOnly, instance)
+
+	kinds := map[string]IssueKind{}
+	for _, issue := range report.Issues {
+		kinds[issue.KeyXdr] = issue.Kind
+	}
+	assert.Equal(t, IssueMissing, kinds[b64(t, instance)])
+	assert.Equal(t, IssueSuperfluous, kinds[b64(t, codeKey())])
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package footprint
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/abi"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// SizeResources sizes the resources of a footprint from a run that used cpu
+// instructions. Read bytes count every existing entry of the footprint, which
+// is an upper bound on the disk reads the network charges. Write bytes count
+// the read-write entries as the run left them, taken from changes and
+// otherwise from entries. marginPercent is added to the instructions; a
+// negative margin adds none.
+func SizeResources(fp xdr.LedgerFootprint, entries map[string]string, changes []simulator.LedgerEntryChange, cpu uint64, marginPercent int) (Resources, error) {
+	if marginPercent > 0 {
+		cpu += cpu * uint64(marginPercent) / 100
+	}
+	res := Resources{
+		Instructions: clampUint32(cpu),
+		ReadEntries:  len(fp.ReadOnly) + len(fp.ReadWrite),
+		WriteEntries: len(fp.ReadWrite),
+	}
+
+	written := map[string]int{}
+	for _, c := range changes {
+		written[c.KeyXdr] = entrySize(c.EntryXdr)
+	}
+
+	var read, write uint64
+	for i, keys := range [][]xdr.LedgerKey{fp.ReadOnly, fp.ReadWrite} {
+		for _, k := range keys {
+			keyB64, err := xdr.MarshalBase64(k)
+			if err != nil {
+				return res, fmt.Errorf("encode ledger key: %w", err)
+			}
+			size := entrySize(entries[keyB64])
+			read += uint64(size)
+			if i == 0 {
+				continue
+			}
+			if after, ok := written[keyB64]; ok {
+				size = after
+			}
+			write += uint64(size)
+		}
+	}
+	res.DiskReadBytes = clampUint32(read)
+	res.WriteBytes = clampUint32(write)
+	return res, nil
+}
+
+// DeclaredResources returns the resources and resource fee a transaction
+// declares.
+func DeclaredResources(data xdr.SorobanTransactionData) Resources {
+	r := data.Resources
+	return Resources{
+		Instructions:  uint32(r.Instructions),
+		DiskReadBytes: uint32(r.DiskReadBytes),
+		WriteBytes:    uint32(r.WriteBytes),
+		ReadEntries:   len(r.Footprint.ReadOnly) + len(r.Footprint.ReadWrite),
+		WriteEntries:  len(r.Footprint.ReadWrite),
+		ResourceFee:   int64(data.ResourceFee),
+	}
+}
+
+// Usage returns the resources in the form the fee model prices them.
+func (r Resources) Usage() simulator.ResourceUsage {
+	return simulator.ResourceUsage{
+		Instructions:  r.Instructions,
+		DiskReadBytes: r.DiskReadBytes,
+		WriteBytes:    r.WriteBytes,
+		ReadEntries:   r.ReadEntries,
+		WriteEntries:  r.WriteEntries,
+	}
+}
+
+func entrySize(entryXdr string) int {
+	raw, err := base64.StdEncoding.DecodeString(entryXdr)
+	if err != nil {
+		return 0
+	}
+	return len(raw)
+}
+
+func clampUint32(v uint64) uint32 {
+	if v > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(v)
+}
+
+// describeKey renders a ledger key for humans.
+func describeKey(keyXdr string) string {
+	var k xdr.LedgerKey
+	if err := xdr.SafeUnmarshalBase64(keyXdr, &k); err != nil {
+		return keyXdr
+	}
+	switch k.Type {
+	case xdr.LedgerEntryTypeAccount:
+		return "account " + k.Account.AccountId.Address()
+	case xdr.LedgerEntryTypeTrustline:
+		return "trustline " + k.TrustLine.AccountId.Address() + " " + k.TrustLine.Asset.ToAsset().StringCanonical()
+	case xdr.LedgerEntryTypeContractCode:
+		return "wasm " + hex.EncodeToString(k.ContractCode.Hash[:])
+	case xdr.LedgerEntryTypeContractData:
+		contract, err := k.ContractData.Contract.String()
+		if err != nil {
+			contract = "?"
+		}
+		if k.ContractData.Key.Type == xdr.ScValTypeScvLedgerKeyContractInstance {
+			return contract + " instance"
+		}
+		durability := abi.StoragePersistent
+		if k.ContractData.Durability == xdr.ContractDataDurabilityTemporary {
+			durability = abi.StorageTemporary
+		}
+		return fmt.Sprintf("%s %s (%s)", contract, abi.FormatValue(nil, k.ContractData.Key), durability)
+	}
+	return strings.ToLower(strings.TrimPrefix(k.Type.String(), "LedgerEntryType"))
+}
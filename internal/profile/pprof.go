@@ -6,139 +6,252 @@ package profile
 import (
 	"fmt"
 	"io"
+	"strings"
 
+	"github.com/dotandev/hintents/internal/dwarf"
 	"github.com/dotandev/hintents/internal/trace"
 	"github.com/google/pprof/profile"
 )
 
 const (
-	// SampleTypeGas is the pprof sample type for gas consumption.
-	SampleTypeGas = "gas"
-	// SampleUnitCount is the unit for gas samples.
-	SampleUnitCount = "count"
+	// SampleTypeCPU is the pprof sample type for CPU instructions.
+	SampleTypeCPU = "cpu"
+	// SampleUnitInstructions is the unit for CPU samples.
+	SampleUnitInstructions = "instructions"
+	// SampleTypeMemory is the pprof sample type for memory allocation.
+	SampleTypeMemory = "memory"
+	// SampleUnitBytes is the unit for memory samples.
+	SampleUnitBytes = "bytes"
+
+	// hostMapping names the mapping used for host functions and for frames
+	// without a contract ID.
+	hostMapping = "host"
+	// mappingSize is the synthetic address range given to each mapping.
+	mappingSize = 1 << 32
 )
 
-// TraceToPprof synthesizes an execution trace into a pprof-compliant profile
-// that maps gas consumption to functions. The result can be viewed with
-// go tool pprof.
-func TraceToPprof(execTrace *trace.ExecutionTrace) (*profile.Profile, error) {
+// Option configures TraceToPprof.
+type Option func(*builder)
+
+// WithDebugInfo resolves source files and lines for functions of the given
+// contract using the DWARF data in its WASM.
+func WithDebugInfo(contractID string, p *dwarf.Parser) Option {
+	return func(b *builder) {
+		b.debug[contractID] = p
+	}
+}
+
+// TraceToPprof synthesizes an execution trace into a pprof-compliant profile.
+// Each step's CPU and memory cost is attributed to the call stack reconstructed
+// at that step, so callers and callees can be explored with go tool pprof.
+// Every contract gets its own mapping.
+func TraceToPprof(execTrace *trace.ExecutionTrace, opts ...Option) (*profile.Profile, error) {
 	if execTrace == nil {
 		return nil, fmt.Errorf("execution trace is nil")
 	}
 
-	p := &profile.Profile{
-		SampleType: []*profile.ValueType{
-			{Type: SampleTypeGas, Unit: SampleUnitCount},
-		},
-		DefaultSampleType: SampleTypeGas,
-		Mapping: []*profile.Mapping{
-			{ID: 1, Start: 0, Limit: 0, File: "soroban", HasFunctions: true},
+	b := &builder{
+		p: &profile.Profile{
+			SampleType: []*profile.ValueType{
+				{Type: SampleTypeCPU, Unit: SampleUnitInstructions},
+				{Type: SampleTypeMemory, Unit: SampleUnitBytes},
+			},
+			DefaultSampleType: SampleTypeCPU,
+			Function:          make([]*profile.Function, 0),
+			Location:          make([]*profile.Location, 0),
+			Sample:            make([]*profile.Sample, 0),
 		},
-		Function: make([]*profile.Function, 0),
-		Location: make([]*profile.Location, 0),
-		Sample:   make([]*profile.Sample, 0),
+		debug:     make(map[string]*dwarf.Parser),
+		mappings:  make(map[string]*profile.Mapping),
+		functions: make(map[string]*profile.Function),
+		locations: make(map[locationKey]*profile.Location),
+		subprogs:  make(map[string]map[string]dwarf.SubprogramInfo),
 	}
-
-	funcByKey := make(map[string]*profile.Function)
-	locByKey := make(map[string]*profile.Location)
-	mapping := p.Mapping[0]
-	var funcID, locID uint64
-
-	nextFuncID := func() uint64 {
-		funcID++
-		return funcID
-	}
-	nextLocID := func() uint64 {
-		locID++
-		return locID
+	for _, opt := range opts {
+		opt(b)
 	}
 
+	var stack []string
 	for i := range execTrace.States {
 		state := &execTrace.States[i]
-		gas := extractGasFromState(state)
-		if gas < 0 {
-			gas = 0
+		stack = trace.AdvanceCallStack(stack, state)
+
+		cpu, mem := state.Cost()
+		if cpu == 0 && mem == 0 {
+			continue
 		}
 
-		name := functionName(state)
-		if name == "" {
-			name = state.Operation
+		frames := stack
+		if leaf := leafFrame(state, stack); leaf != "" {
+			frames = append(frames[:len(frames):len(frames)], leaf)
 		}
-		if name == "" {
-			name = fmt.Sprintf("step_%d", state.Step)
+		if len(frames) == 0 {
+			frames = []string{"::" + fallbackName(state)}
 		}
 
-		key := name
-		loc, ok := locByKey[key]
-		if !ok {
-			fn, ok := funcByKey[key]
-			if !ok {
-				fn = &profile.Function{
-					ID:   nextFuncID(),
-					Name: name,
-				}
-				p.Function = append(p.Function, fn)
-				funcByKey[key] = fn
-			}
-			loc = &profile.Location{
-				ID:      nextLocID(),
-				Mapping: mapping,
-				Address: uint64(state.Step),
-				Line:    []profile.Line{{Function: fn, Line: int64(state.Step)}},
+		// pprof lists locations leaf first; the leaf carries the step's line.
+		locs := make([]*profile.Location, 0, len(frames))
+		for j := len(frames) - 1; j >= 0; j-- {
+			contractID, fn, _ := strings.Cut(frames[j], "::")
+			var file string
+			var line int64
+			if j == len(frames)-1 {
+				file, line = state.SourceFile, int64(state.SourceLine)
 			}
-			p.Location = append(p.Location, loc)
-			locByKey[key] = loc
+			locs = append(locs, b.location(contractID, fn, file, line))
 		}
 
-		if gas > 0 {
-			p.Sample = append(p.Sample, &profile.Sample{
-				Location: []*profile.Location{loc},
-				Value:    []int64{gas},
-			})
-		}
+		b.p.Sample = append(b.p.Sample, &profile.Sample{
+			Location: locs,
+			Value:    []int64{cpu, mem},
+		})
 	}
 
-	if err := p.CheckValid(); err != nil {
+	if err := b.p.CheckValid(); err != nil {
 		return nil, fmt.Errorf("profile validation failed: %w", err)
 	}
-	return p, nil
+	return b.p, nil
 }
 
-func functionName(state *trace.ExecutionState) string {
-	if state.ContractID != "" && state.Function != "" {
-		return state.ContractID + "::" + state.Function
+type locationKey struct {
+	function string
+	file     string
+	line     int64
+}
+
+type builder struct {
+	p         *profile.Profile
+	debug     map[string]*dwarf.Parser
+	mappings  map[string]*profile.Mapping
+	functions map[string]*profile.Function
+	locations map[locationKey]*profile.Location
+	// subprogs caches DWARF subprograms per contract, keyed by name.
+	subprogs map[string]map[string]dwarf.SubprogramInfo
+}
+
+func (b *builder) mapping(contractID string) *profile.Mapping {
+	name := contractID
+	if name == "" {
+		name = hostMapping
 	}
-	if state.Function != "" {
-		return state.Function
+	if m, ok := b.mappings[name]; ok {
+		return m
 	}
-	return ""
+	id := uint64(len(b.p.Mapping) + 1)
+	m := &profile.Mapping{
+		ID:           id,
+		Start:        id * mappingSize,
+		Limit:        (id+1)*mappingSize - 1,
+		File:         name,
+		HasFunctions: true,
+	}
+	b.p.Mapping = append(b.p.Mapping, m)
+	b.mappings[name] = m
+	return m
 }
 
-func extractGasFromState(state *trace.ExecutionState) int64 {
-	if state.HostState == nil {
-		return 0
+func (b *builder) function(contractID, name string) *profile.Function {
+	key := contractID + "::" + name
+	if fn, ok := b.functions[key]; ok {
+		return fn
+	}
+	fn := &profile.Function{
+		ID:         uint64(len(b.p.Function) + 1),
+		Name:       name,
+		SystemName: name,
+	}
+	if contractID != "" {
+		fn.Name = key
+	}
+	if sp, ok := b.subprogram(contractID, name); ok {
+		fn.Filename = sp.File
+		fn.StartLine = int64(sp.Line)
+		if sp.DemangledName != "" {
+			fn.SystemName = sp.Name
+		}
 	}
-	v, ok := state.HostState["gas_used"]
+	b.p.Function = append(b.p.Function, fn)
+	b.functions[key] = fn
+	return fn
+}
+
+// location returns the location for a function at a source line. When the
+// trace does not carry a line, the function's DWARF declaration is used.
+func (b *builder) location(contractID, name, file string, line int64) *profile.Location {
+	fn := b.function(contractID, name)
+	if file == "" && line == 0 {
+		file, line = fn.Filename, fn.StartLine
+	}
+	// pprof takes the file name from the function.
+	if fn.Filename == "" {
+		fn.Filename = file
+	}
+
+	key := locationKey{function: contractID + "::" + name, file: file, line: line}
+	if loc, ok := b.locations[key]; ok {
+		return loc
+	}
+	m := b.mapping(contractID)
+	loc := &profile.Location{
+		ID:      uint64(len(b.p.Location) + 1),
+		Mapping: m,
+		Address: m.Start + uint64(len(b.p.Location)),
+		Line:    []profile.Line{{Function: fn, Line: line}},
+	}
+	b.p.Location = append(b.p.Location, loc)
+	b.locations[key] = loc
+	return loc
+}
+
+func (b *builder) subprogram(contractID, name string) (dwarf.SubprogramInfo, bool) {
+	p := b.debug[contractID]
+	if p == nil {
+		return dwarf.SubprogramInfo{}, false
+	}
+	index, ok := b.subprogs[contractID]
 	if !ok {
-		return 0
-	}
-	switch n := v.(type) {
-	case float64:
-		return int64(n)
-	case int:
-		return int64(n)
-	case int64:
-		return n
-	case uint64:
-		return int64(n)
-	default:
-		return 0
+		index = make(map[string]dwarf.SubprogramInfo)
+		if subs, err := p.GetSubprograms(); err == nil {
+			for _, sp := range subs {
+				for _, n := range []string{sp.Name, sp.DemangledName} {
+					if _, seen := index[n]; n != "" && !seen {
+						index[n] = sp
+					}
+				}
+			}
+		}
+		b.subprogs[contractID] = index
+	}
+	sp, ok := index[name]
+	return sp, ok
+}
+
+// leafFrame returns an extra frame for steps that run below the current call,
+// such as host functions, or "" when the step belongs to the top frame.
+func leafFrame(state *trace.ExecutionState, stack []string) string {
+	switch trace.ClassifyEventType(state) {
+	case trace.EventTypeHostFunction, trace.EventTypeAuth:
+		return "::" + fallbackName(state)
+	}
+	if state.Function == "" && len(stack) == 0 {
+		return "::" + fallbackName(state)
+	}
+	return ""
+}
+
+func fallbackName(state *trace.ExecutionState) string {
+	if state.Function != "" {
+		return state.Function
+	}
+	if state.Operation != "" {
+		return state.Operation
 	}
+	return fmt.Sprintf("step_%d", state.Step)
 }
 
 // WritePprof writes the trace as a pprof profile to w (gzip-compressed protobuf).
-func WritePprof(execTrace *trace.ExecutionTrace, w io.Writer) error {
-	p, err := TraceToPprof(execTrace)
+func WritePprof(execTrace *trace.ExecutionTrace, w io.Writer, opts ...Option) error {
+	p, err := TraceToPprof(execTrace, opts...)
 	if err != nil {
 		return err
 	}
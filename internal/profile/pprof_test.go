@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/dotandev/hintents/internal/trace"
+	"github.com/google/pprof/profile"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,9 +23,11 @@ func TestTraceToPprof_EmptyTrace(t *testing.T) {
 	p, err := TraceToPprof(execTrace)
 	require.NoError(t, err)
 	require.NotNil(t, p)
-	assert.Len(t, p.SampleType, 1)
-	assert.Equal(t, SampleTypeGas, p.SampleType[0].Type)
-	assert.Equal(t, SampleUnitCount, p.SampleType[0].Unit)
+	require.Len(t, p.SampleType, 2)
+	assert.Equal(t, SampleTypeCPU, p.SampleType[0].Type)
+	assert.Equal(t, SampleUnitInstructions, p.SampleType[0].Unit)
+	assert.Equal(t, SampleTypeMemory, p.SampleType[1].Type)
+	assert.Equal(t, SampleUnitBytes, p.SampleType[1].Unit)
 	assert.Empty(t, p.Sample)
 }
 
@@ -48,11 +51,9 @@ func TestTraceToPprof_WithGas(t *testing.T) {
 	p, err := TraceToPprof(execTrace)
 	require.NoError(t, err)
 	require.NotNil(t, p)
-	assert.Len(t, p.SampleType, 1)
-	assert.Equal(t, SampleTypeGas, p.SampleType[0].Type)
 	require.Len(t, p.Sample, 2)
-	assert.Equal(t, []int64{15000}, p.Sample[0].Value)
-	assert.Equal(t, []int64{8000}, p.Sample[1].Value)
+	assert.Equal(t, []int64{15000, 0}, p.Sample[0].Value)
+	assert.Equal(t, []int64{8000, 0}, p.Sample[1].Value)
 	assert.Len(t, p.Function, 2)
 	assert.Len(t, p.Location, 2)
 }
@@ -90,7 +91,56 @@ func TestTraceToPprof_GasTypes(t *testing.T) {
 	p, err := TraceToPprof(execTrace)
 	require.NoError(t, err)
 	require.Len(t, p.Sample, 3)
-	assert.Equal(t, []int64{100}, p.Sample[0].Value)
-	assert.Equal(t, []int64{200}, p.Sample[1].Value)
-	assert.Equal(t, []int64{300}, p.Sample[2].Value)
+	assert.Equal(t, []int64{100, 0}, p.Sample[0].Value)
+	assert.Equal(t, []int64{200, 0}, p.Sample[1].Value)
+	assert.Equal(t, []int64{300, 0}, p.Sample[2].Value)
+}
+
+func TestTraceToPprof_CallStacks(t *testing.T) {
+	execTrace := trace.NewExecutionTrace("tx1", 10)
+	execTrace.AddState(trace.ExecutionState{
+		Operation: "contract_call", ContractID: "CROUTER", Function: "swap",
+		HostState: map[string]interface{}{"cpu_instructions": 100, "memory_bytes": 10},
+	})
+	execTrace.AddState(trace.ExecutionState{
+		Operation: "contract_call", ContractID: "CTOKEN", Function: "transfer",
+		SourceFile: "src/token.rs", SourceLine: 42,
+		HostState: map[string]interface{}{"cpu_instructions": 300, "memory_bytes": 64},
+	})
+	execTrace.AddState(trace.ExecutionState{
+		Operation: "host_function", Function: "require_auth",
+		HostState: map[string]interface{}{"cpu_instructions": 50},
+	})
+	execTrace.AddState(trace.ExecutionState{
+		Operation: "fn_return", ContractID: "CTOKEN", Function: "transfer",
+		HostState: map[string]interface{}{"cpu_instructions": 20, "memory_bytes": 4},
+	})
+
+	p, err := TraceToPprof(execTrace)
+	require.NoError(t, err)
+	require.Len(t, p.Sample, 4)
+
+	stack := func(s *profile.Sample) []string {
+		var names []string
+		for _, loc := range s.Location {
+			names = append(names, loc.Line[0].Function.Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"CROUTER::swap"}, stack(p.Sample[0]))
+	assert.Equal(t, []string{"CTOKEN::transfer", "CROUTER::swap"}, stack(p.Sample[1]))
+	assert.Equal(t, []int64{300, 64}, p.Sample[1].Value)
+	assert.Equal(t, []string{"require_auth", "CTOKEN::transfer", "CROUTER::swap"}, stack(p.Sample[2]))
+	assert.Equal(t, []string{"CROUTER::swap"}, stack(p.Sample[3]))
+
+	leaf := p.Sample[1].Location[0]
+	assert.Equal(t, int64(42), leaf.Line[0].Line)
+	assert.Equal(t, "src/token.rs", leaf.Line[0].Function.Filename)
+
+	var files []string
+	for _, m := range p.Mapping {
+		files = append(files, m.File)
+	}
+	assert.ElementsMatch(t, []string{"CROUTER", "CTOKEN", "host"}, files)
+	assert.Same(t, p.Sample[1].Location[1].Mapping, p.Sample[0].Location[0].Mapping)
 }
@@ -136,6 +136,22 @@ func (b *Builder) SetRiskAssessment(level string, score float64) *Builder {
 	return b
 }
 
+func (b *Builder) AddTokenFlow(link TokenFlowLink) *Builder {
+	if b.report.TokenFlow == nil {
+		b.report.TokenFlow = &TokenFlow{}
+	}
+	b.report.TokenFlow.Flows = append(b.report.TokenFlow.Flows, link)
+	return b
+}
+
+func (b *Builder) AddTokenBalance(balance TokenBalance) *Builder {
+	if b.report.TokenFlow == nil {
+		b.report.TokenFlow = &TokenFlow{}
+	}
+	b.report.TokenFlow.Balances = append(b.report.TokenFlow.Balances, balance)
+	return b
+}
+
 func (b *Builder) SetMetadata(source, version string, tags map[string]string) *Builder {
 	b.report.Metadata.DataSource = source
 	b.report.Metadata.GeneratorVersion = version
@@ -25,6 +25,7 @@ func (r *HTMLRenderer) Render(report *Report) ([]byte, error) {
 		"escapeHTML":  escapeHTML,
 		"statusClass": statusClass,
 		"riskColor":   riskColor,
+		"sankey":      sankeyDiagrams,
 	}).Parse(htmlTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse template: %w", err)
@@ -114,6 +115,9 @@ const htmlTemplate = `<!DOCTYPE html>
 		.metric-box { background: #f9f9f9; padding: 20px; border-radius: 4px; border: 1px solid #e0e0e0; }
 		.metric-box h4 { color: #667eea; margin-bottom: 10px; }
 		.metric-box .metric-value { font-size: 1.5em; font-weight: bold; color: #333; }
+		.sankey { display: block; margin: 10px 0 20px 0; }
+		.sankey text { fill: #333; font-family: monospace; }
+		.amount { font-family: monospace; text-align: right; }
 		footer { background: #f5f5f5; padding: 20px 30px; text-align: center; color: #999; font-size: 0.9em; }
 		@media print { body { background: white; } .container { box-shadow: none; } section { page-break-inside: avoid; } }
 	</style>
@@ -128,6 +132,7 @@ const htmlTemplate = `<!DOCTYPE html>
 			<a href="#summary">Summary</a>
 			<a href="#execution">Execution</a>
 			<a href="#analytics">Analytics</a>
+			{{ if .TokenFlow }}<a href="#token-flow">Token Flow</a>{{ end }}
 			<a href="#risks">Risk Assessment</a>
 			<a href="#metadata">Metadata</a>
 		</div>
@@ -218,6 +223,29 @@ const htmlTemplate = `<!DOCTYPE html>
 			{{ end }}
 			{{ end }}
 		</section>
+		{{ with .TokenFlow }}
+		<section id="token-flow">
+			<h2>Token Flow</h2>
+			{{ sankey . }}
+			{{ if .Balances }}
+			<h3>Balances</h3>
+			<table>
+				<thead><tr><th>Account</th><th>Token</th><th>Before</th><th>After</th><th>Change</th></tr></thead>
+				<tbody>
+					{{ range .Balances }}
+					<tr>
+						<td><code>{{ escapeHTML .Account }}</code></td>
+						<td>{{ escapeHTML .Token }}</td>
+						<td class="amount">{{ .Before }}</td>
+						<td class="amount">{{ .After }}</td>
+						<td class="amount">{{ .Change }}</td>
+					</tr>
+					{{ end }}
+				</tbody>
+			</table>
+			{{ end }}
+		</section>
+		{{ end }}
 		<section id="risks">
 			<h2>Risk Assessment</h2>
 			{{ with .Analytics.RiskAssessment }}
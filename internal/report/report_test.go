@@ -293,6 +293,48 @@ func TestBuilderFromJSON(t *testing.T) {
 	}
 }
 
+func TestHTMLRendering_TokenFlow(t *testing.T) {
+	report := NewBuilder("Token Report").
+		AddTokenFlow(TokenFlowLink{From: "GALICE", To: "GBOB", Token: "USDC", Kind: "transfer", Amount: "15", Value: 15}).
+		AddTokenFlow(TokenFlowLink{From: "GALICE", To: "GCAROL", Token: "USDC", Kind: "transfer", Amount: "5", Value: 5}).
+		AddTokenFlow(TokenFlowLink{From: "GALICE", To: "GDEX", Token: "USDC", Kind: "approve", Amount: "100", Value: 100}).
+		AddTokenBalance(TokenBalance{Account: "GALICE", Token: "USDC", Before: "50", After: "30", Change: "-20"}).
+		Build()
+
+	html, err := NewHTMLRenderer().Render(report)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	out := string(html)
+
+	if !strings.Contains(out, `href="#token-flow"`) || !strings.Contains(out, `id="token-flow"`) {
+		t.Error("expected token flow section and TOC link")
+	}
+	if n := strings.Count(out, `<svg class="sankey"`); n != 1 {
+		t.Errorf("expected one Sankey diagram, got %d", n)
+	}
+	// Two transfer bands; the allowance moves no tokens.
+	if n := strings.Count(out, "<path "); n != 2 {
+		t.Errorf("expected 2 Sankey bands, got %d", n)
+	}
+	if strings.Contains(out, "GDEX") {
+		t.Error("allowance should not be drawn")
+	}
+	if !strings.Contains(out, "<td class=\"amount\">-20</td>") {
+		t.Error("expected balance change in table")
+	}
+}
+
+func TestHTMLRendering_NoTokenFlow(t *testing.T) {
+	html, err := NewHTMLRenderer().Render(NewBuilder("Plain").Build())
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if strings.Contains(string(html), "token-flow") {
+		t.Error("token flow section should be omitted without flows")
+	}
+}
+
 func BenchmarkHTMLRendering(b *testing.B) {
 	report := NewBuilder("Test Report").
 		WithTransactionHash("0xbench").
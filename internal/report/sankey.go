@@ -0,0 +1,154 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package report
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+const (
+	sankeyWidth   = 760
+	sankeyNodeW   = 10
+	sankeyLeftX   = 230
+	sankeyRightX  = sankeyWidth - 230 - sankeyNodeW
+	sankeyPad     = 12
+	sankeyRowH    = 32
+	sankeyMinH    = 80
+	sankeyMargin  = 10
+	sankeyMinBand = 2.0
+)
+
+var sankeyColors = []string{"#667eea", "#26a69a", "#f57c00", "#ab47bc", "#42a5f5", "#ef5350"}
+
+type sankeyNode struct {
+	label string
+	value float64
+	y     float64
+	h     float64
+	used  float64 // height of the bands already attached
+}
+
+// sankeyDiagrams renders one Sankey diagram per token as inline SVG, with
+// senders on the left, receivers on the right and bands as wide as the
+// amount moved. Allowances are left out as they move no tokens.
+func sankeyDiagrams(tf *TokenFlow) string {
+	if tf == nil {
+		return ""
+	}
+	var tokens []string
+	byToken := map[string][]TokenFlowLink{}
+	for _, l := range tf.Flows {
+		if l.Kind == "approve" {
+			continue
+		}
+		if _, ok := byToken[l.Token]; !ok {
+			tokens = append(tokens, l.Token)
+		}
+		byToken[l.Token] = append(byToken[l.Token], l)
+	}
+
+	var b strings.Builder
+	for i, token := range tokens {
+		b.WriteString(fmt.Sprintf("<h3>%s</h3>\n", html.EscapeString(token)))
+		b.WriteString(sankeySVG(byToken[token], sankeyColors[i%len(sankeyColors)]))
+	}
+	return b.String()
+}
+
+func sankeySVG(links []TokenFlowLink, color string) string {
+	values := make([]float64, len(links))
+	total := 0.0
+	for i, l := range links {
+		values[i] = l.Value
+		if values[i] < 0 {
+			values[i] = 0
+		}
+		total += values[i]
+	}
+	if total == 0 {
+		// Amounts too large or unparsable: draw equal bands.
+		for i := range values {
+			values[i] = 1
+		}
+		total = float64(len(values))
+	}
+
+	var sources, targets []*sankeyNode
+	sourceIdx, targetIdx := map[string]int{}, map[string]int{}
+	node := func(nodes *[]*sankeyNode, idx map[string]int, label string) *sankeyNode {
+		if i, ok := idx[label]; ok {
+			return (*nodes)[i]
+		}
+		idx[label] = len(*nodes)
+		n := &sankeyNode{label: label}
+		*nodes = append(*nodes, n)
+		return n
+	}
+	for i, l := range links {
+		node(&sources, sourceIdx, l.From).value += values[i]
+		node(&targets, targetIdx, l.To).value += values[i]
+	}
+
+	rows := len(sources)
+	if len(targets) > rows {
+		rows = len(targets)
+	}
+	height := float64(rows * sankeyRowH)
+	if height < sankeyMinH {
+		height = sankeyMinH
+	}
+	scale := (height - float64(sankeyPad*(rows-1))) / total
+	layout := func(nodes []*sankeyNode) {
+		y := float64(sankeyMargin)
+		for _, n := range nodes {
+			n.y = y
+			n.h = max(n.value*scale, sankeyMinBand)
+			y += n.h + sankeyPad
+		}
+	}
+	layout(sources)
+	layout(targets)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="sankey" viewBox="0 0 %d %.0f" width="100%%" role="img" xmlns="http://www.w3.org/2000/svg">`+"\n",
+		sankeyWidth, height+2*sankeyMargin)
+
+	x0, x1 := float64(sankeyLeftX+sankeyNodeW), float64(sankeyRightX)
+	xm := (x0 + x1) / 2
+	for i, l := range links {
+		src, dst := sources[sourceIdx[l.From]], targets[targetIdx[l.To]]
+		w := max(values[i]*scale, sankeyMinBand)
+		y0, y1 := src.y+src.used, dst.y+dst.used
+		src.used += w
+		dst.used += w
+		fmt.Fprintf(&b, `<path d="M%.1f %.1f C%.1f %.1f %.1f %.1f %.1f %.1f L%.1f %.1f C%.1f %.1f %.1f %.1f %.1f %.1f Z" fill="%s" fill-opacity="0.35">`,
+			x0, y0, xm, y0, xm, y1, x1, y1,
+			x1, y1+w, xm, y1+w, xm, y0+w, x0, y0+w, color)
+		fmt.Fprintf(&b, "<title>%s</title></path>\n", html.EscapeString(fmt.Sprintf("%s → %s: %s %s", l.From, l.To, l.Amount, l.Token)))
+	}
+
+	nodes := func(nodes []*sankeyNode, x int, labelX int, anchor string) {
+		for _, n := range nodes {
+			fmt.Fprintf(&b, `<rect x="%d" y="%.1f" width="%d" height="%.1f" fill="%s"><title>%s</title></rect>`+"\n",
+				x, n.y, sankeyNodeW, n.h, color, html.EscapeString(n.label))
+			fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="%s" dominant-baseline="middle" font-size="12">%s</text>`+"\n",
+				labelX, n.y+n.h/2, anchor, html.EscapeString(shortAccount(n.label)))
+		}
+	}
+	nodes(sources, sankeyLeftX, sankeyLeftX-6, "end")
+	nodes(targets, sankeyRightX, sankeyRightX+sankeyNodeW+6, "start")
+
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// shortAccount keeps the first and last characters of a long address.
+func shortAccount(s string) string {
+	if len(s) > 16 {
+		return s[:6] + "…" + s[len(s)-6:]
+	}
+	return s
+}
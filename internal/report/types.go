@@ -18,6 +18,7 @@ type Report struct {
 	Summary     *Summary      `json:"summary"`
 	Execution   *ExecutionLog `json:"execution"`
 	Analytics   *Analytics    `json:"analytics"`
+	TokenFlow   *TokenFlow    `json:"token_flow,omitempty"`
 	Metadata    *Metadata     `json:"metadata"`
 }
 
@@ -92,6 +93,33 @@ type Issue struct {
 	Location    string `json:"location,omitempty"`
 }
 
+// TokenFlow is the money movement of the transaction: aggregated flows
+// between accounts and each account's balances around it. Amounts are
+// already formatted with the token's decimals.
+type TokenFlow struct {
+	Flows    []TokenFlowLink `json:"flows"`
+	Balances []TokenBalance  `json:"balances"`
+}
+
+type TokenFlowLink struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Token  string `json:"token"`
+	Kind   string `json:"kind"`
+	Amount string `json:"amount"`
+	// Value sizes the link in the Sankey diagram; it is only compared
+	// between links of the same token.
+	Value float64 `json:"value"`
+}
+
+type TokenBalance struct {
+	Account string `json:"account"`
+	Token   string `json:"token"`
+	Before  string `json:"before"`
+	After   string `json:"after"`
+	Change  string `json:"change"`
+}
+
 type Metadata struct {
 	GeneratorVersion string            `json:"generator_version"`
 	DataSource       string            `json:"data_source"`
@@ -71,6 +71,11 @@ func ContractCodeHashFromInstanceEntry(entryXDR string) (xdr.Hash, error) {
 	}
 }
 
+// ParseContractID decodes a contract ID from strkey (C...) or 32-byte hex.
+func ParseContractID(contractIDStr string) (xdr.ContractId, error) {
+	return decodeContractID(contractIDStr)
+}
+
 // decodeContractID decodes a contract ID from strkey (C...) or 32-byte hex.
 func decodeContractID(contractIDStr string) (xdr.ContractId, error) {
 	s := strings.TrimSpace(contractIDStr)
@@ -81,15 +81,6 @@ func GetCachePath() (string, error) {
 	return path, nil
 }
 
-// getCacheDBPath returns the full path to cache.db inside ~/.erst/
-func getCacheDBPath() (string, error) {
-	dir, err := GetCachePath()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(dir, CacheDBName), nil
-}
-
 // ensureDB lazily opens the SQLite database and creates the schema.
 func ensureDB() (*sql.DB, error) {
 	cacheMu.Lock()
@@ -99,14 +90,27 @@ func ensureDB() (*sql.DB, error) {
 		return cacheDB, nil
 	}
 
-	dbPath, err := getCacheDBPath()
+	db, err := OpenLocalDB(CacheDBName, cacheSchema)
 	if err != nil {
 		return nil, err
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	cacheDB = db
+	return cacheDB, nil
+}
+
+// OpenLocalDB opens (or creates) the named SQLite database inside ~/.erst/,
+// enables WAL mode and applies schema. It is shared by the RPC cache and other
+// local indexes so they all live alongside each other with the same settings.
+func OpenLocalDB(name, schema string) (*sql.DB, error) {
+	dir, err := GetCachePath()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(dir, name))
 	if err != nil {
-		return nil, fmt.Errorf("failed to open cache database: %w", err)
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
 	}
 
 	// WAL mode for better concurrent read performance
@@ -115,13 +119,12 @@ func ensureDB() (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to set WAL mode: %w", err)
 	}
 
-	if _, err := db.Exec(cacheSchema); err != nil {
+	if _, err := db.Exec(schema); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to initialize cache schema: %w", err)
+		return nil, fmt.Errorf("failed to initialize %s schema: %w", name, err)
 	}
 
-	cacheDB = db
-	return cacheDB, nil
+	return db, nil
 }
 
 // InitCacheWithDB injects an already-open *sql.DB (e.g. an in-memory database
@@ -743,19 +743,7 @@ func (c *Client) GetLedgerEntries(ctx context.Context, keys []string) (map[strin
 }
 
 func (c *Client) getLedgerEntriesAttempt(ctx context.Context, keysToFetch []string) (entries map[string]string, err error) {
-	// Always use the dedicated Soroban RPC URL for getLedgerEntries; this is a
-	// Soroban JSON-RPC method and is not served by the Horizon REST API.
-	targetURL := c.SorobanURL
-	if targetURL == "" {
-		switch c.Network {
-		case Testnet:
-			targetURL = TestnetSorobanURL
-		case Mainnet:
-			targetURL = MainnetSorobanURL
-		case Futurenet:
-			targetURL = FuturenetSorobanURL
-		}
-	}
+	targetURL := c.ledgerEntriesURL()
 
 	timer := c.startMethodTimer(ctx, "rpc.get_ledger_entries", map[string]string{
 		"network": c.GetNetworkName(),
@@ -767,6 +755,59 @@ func (c *Client) getLedgerEntriesAttempt(ctx context.Context, keysToFetch []stri
 
 	logger.Logger.Debug("Fetching ledger entries", "count", len(keysToFetch), "url", targetURL)
 
+	rpcResp, err := c.requestLedgerEntries(ctx, targetURL, keysToFetch)
+	if err != nil {
+		return nil, err
+	}
+
+	entries = make(map[string]string)
+	fetchedCount := 0
+	for _, entry := range rpcResp.Result.Entries {
+		entries[entry.Key] = entry.Xdr
+		fetchedCount++
+
+		// Cache the new entry
+		if c.CacheEnabled {
+			if err := Set(entry.Key, entry.Xdr); err != nil {
+				logger.Logger.Warn("Failed to cache entry", "key", entry.Key, "error", err)
+			}
+		}
+	}
+
+	// Cryptographically verify all returned ledger entries
+	if err := VerifyLedgerEntries(keysToFetch, entries); err != nil {
+		return nil, fmt.Errorf("ledger entry verification failed: %w", err)
+	}
+
+	logger.Logger.Info("Ledger entries fetched",
+		"total_requested", len(keysToFetch),
+		"from_cache", len(keysToFetch)-fetchedCount,
+		"from_rpc", fetchedCount,
+		"url", targetURL,
+	)
+
+	return entries, nil
+}
+
+// ledgerEntriesURL is the Soroban RPC endpoint serving getLedgerEntries.
+// This is a Soroban JSON-RPC method and is not served by the Horizon REST API.
+func (c *Client) ledgerEntriesURL() string {
+	if c.SorobanURL != "" {
+		return c.SorobanURL
+	}
+	switch c.Network {
+	case Testnet:
+		return TestnetSorobanURL
+	case Mainnet:
+		return MainnetSorobanURL
+	case Futurenet:
+		return FuturenetSorobanURL
+	}
+	return ""
+}
+
+// requestLedgerEntries sends one getLedgerEntries request to targetURL.
+func (c *Client) requestLedgerEntries(ctx context.Context, targetURL string, keys []string) (*GetLedgerEntriesResponse, error) {
 	// Fail fast if circuit breaker is open for this Soroban endpoint.
 	if !c.isHealthy(targetURL) {
 		return nil, errors.WrapRPCConnectionFailed(
@@ -778,7 +819,7 @@ func (c *Client) getLedgerEntriesAttempt(ctx context.Context, keysToFetch []stri
 		Jsonrpc: "2.0",
 		ID:      1,
 		Method:  "getLedgerEntries",
-		Params:  []interface{}{keysToFetch},
+		Params:  []interface{}{keys},
 	}
 
 	bodyBytes, err := json.Marshal(reqBody)
@@ -820,34 +861,46 @@ func (c *Client) getLedgerEntriesAttempt(ctx context.Context, keysToFetch []stri
 	if rpcResp.Error != nil {
 		return nil, errors.WrapRPCError(targetURL, rpcResp.Error.Message, rpcResp.Error.Code)
 	}
+	return &rpcResp, nil
+}
 
-	entries = make(map[string]string)
-	fetchedCount := 0
-	for _, entry := range rpcResp.Result.Entries {
-		entries[entry.Key] = entry.Xdr
-		fetchedCount++
+// LedgerEntryTTL is a ledger entry with the last ledger it is live in.
+type LedgerEntryTTL struct {
+	EntryXdr string
+	// LiveUntil is 0 for entries without a TTL, such as accounts.
+	LiveUntil uint32
+}
 
-		// Cache the new entry
-		if c.CacheEnabled {
-			if err := Set(entry.Key, entry.Xdr); err != nil {
-				logger.Logger.Warn("Failed to cache entry", "key", entry.Key, "error", err)
-			}
-		}
+// GetLedgerEntryTTLs fetches ledger entries together with their live-until
+// ledgers, and returns the latest ledger the node has seen. Keys that are
+// not found are left out. The cache is bypassed: it does not keep TTLs,
+// which change whenever an entry is extended.
+func (c *Client) GetLedgerEntryTTLs(ctx context.Context, keys []string) (map[string]LedgerEntryTTL, uint32, error) {
+	if len(keys) == 0 {
+		return map[string]LedgerEntryTTL{}, 0, nil
 	}
-
-	// Cryptographically verify all returned ledger entries
-	if err := VerifyLedgerEntries(keysToFetch, entries); err != nil {
-		return nil, fmt.Errorf("ledger entry verification failed: %w", err)
+	targetURL := c.ledgerEntriesURL()
+	rpcResp, err := c.requestLedgerEntries(ctx, targetURL, keys)
+	if err != nil {
+		c.markFailure(targetURL)
+		return nil, 0, err
 	}
+	c.markSuccess(targetURL)
 
-	logger.Logger.Info("Ledger entries fetched",
-		"total_requested", len(keysToFetch),
-		"from_cache", len(keysToFetch)-fetchedCount,
-		"from_rpc", fetchedCount,
-		"url", targetURL,
-	)
-
-	return entries, nil
+	out := make(map[string]LedgerEntryTTL, len(rpcResp.Result.Entries))
+	found := make(map[string]string, len(rpcResp.Result.Entries))
+	for _, e := range rpcResp.Result.Entries {
+		out[e.Key] = LedgerEntryTTL{EntryXdr: e.Xdr, LiveUntil: uint32(e.LiveUntilLedger)}
+		found[e.Key] = e.Xdr
+	}
+	returned := make([]string, 0, len(found))
+	for k := range found {
+		returned = append(returned, k)
+	}
+	if err := VerifyLedgerEntries(returned, found); err != nil {
+		return nil, 0, fmt.Errorf("ledger entry verification failed: %w", err)
+	}
+	return out, uint32(rpcResp.Result.LatestLedger), nil
 }
 
 type TransactionSummary struct {
@@ -1191,7 +1244,6 @@ func (c *Client) getHealthAttempt(ctx context.Context) (healthResp *GetHealthRes
 
 	// Prefer SorobanURL but fall back to the currently active HorizonURL so that
 	// rotateURL-triggered failovers are reflected in health checks.
-	targetURL := c.SorobanURL
 	if targetURL == "" {
 		targetURL = c.HorizonURL
 	}
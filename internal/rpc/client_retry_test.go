@@ -72,9 +72,6 @@ func TestSimulateTransactionRetriesOnRateLimit(t *testing.T) {
 
 func TestGetLedgerEntriesRetriesOnRateLimit(t *testing.T) {
 	// Use a valid base64-encoded XDR LedgerKey so VerifyLedgerEntries passes.
-	validKeys := makeKeys(1)
-	testKey := validKeys[0]
-
 	var calls int32
 	validKey := createTestLedgerKey(t, 42)
 
@@ -89,13 +86,6 @@ func TestGetLedgerEntriesRetriesOnRateLimit(t *testing.T) {
 			Jsonrpc: "2.0",
 			ID:      1,
 		}
-		resp.Result.Entries = []struct {
-			Key                string `json:"key"`
-			Xdr                string `json:"xdr"`
-			LastModifiedLedger int    `json:"lastModifiedLedgerSeq"`
-			LiveUntilLedger    int    `json:"liveUntilLedgerSeq"`
-		}{{
-			Key: testKey,
 		resp.Result.Entries = []LedgerEntryResult{{
 			Key: validKey,
 			Xdr: "BBB",
@@ -115,15 +105,11 @@ func TestGetLedgerEntriesRetriesOnRateLimit(t *testing.T) {
 		t.Fatalf("failed to build client: %v", err)
 	}
 
-	entries, err := client.GetLedgerEntries(context.Background(), []string{testKey})
-	entries, err := client.GetLedgerEntries(context.Background(), []string{key})
 	entries, err := client.GetLedgerEntries(context.Background(), []string{validKey})
 	if err != nil {
 		t.Fatalf("expected retry to succeed, got error: %v", err)
 	}
 
-	if entries[testKey] != "BBB" {
-	if entries[key] != "BBB" {
 	if entries[validKey] != "BBB" {
 		t.Fatalf("unexpected ledger entry: %v", entries)
 	}
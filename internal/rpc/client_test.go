@@ -18,6 +18,7 @@ import (
 	effects "github.com/stellar/go-stellar-sdk/protocols/horizon/effects"
 	operations "github.com/stellar/go-stellar-sdk/protocols/horizon/operations"
 	"github.com/stellar/go-stellar-sdk/txnbuild"
+	"github.com/stellar/go-stellar-sdk/xdr"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -427,3 +428,32 @@ func TestWithRequestTimeout_RespectsContextDeadline(t *testing.T) {
 		t.Error("expected timeout error, got nil")
 	}
 }
+
+func TestGetLedgerEntryTTLs(t *testing.T) {
+	var code xdr.Hash
+	code[0] = 1
+	key, err := EncodeLedgerKey(xdr.LedgerKey{Type: xdr.LedgerEntryTypeContractCode, ContractCode: &xdr.LedgerKeyContractCode{Hash: code}})
+	assert.NoError(t, err)
+	missing, err := EncodeLedgerKey(xdr.LedgerKey{Type: xdr.LedgerEntryTypeContractCode, ContractCode: &xdr.LedgerKeyContractCode{}})
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"latestLedger":1000,"entries":[` +
+			`{"key":"` + key + `","xdr":"ENTRY","lastModifiedLedgerSeq":10,"liveUntilLedgerSeq":900}]}}`))
+	}))
+	defer server.Close()
+
+	c := &Client{
+		Horizon:    &mockHorizonClient{},
+		HorizonURL: server.URL,
+		SorobanURL: server.URL,
+		Network:    "custom",
+		AltURLs:    []string{server.URL},
+	}
+
+	entries, latest, err := c.GetLedgerEntryTTLs(context.Background(), []string{key, missing})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1000), latest)
+	assert.Equal(t, map[string]LedgerEntryTTL{key: {EntryXdr: "ENTRY", LiveUntil: 900}}, entries)
+}
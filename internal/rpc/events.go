@@ -0,0 +1,190 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/dotandev/hintents/internal/logger"
+)
+
+// MaxEventsPageSize is the largest page Soroban RPC accepts for getEvents.
+const MaxEventsPageSize = 10000
+
+// EventFilter narrows getEvents results. Topics holds one slice per topic
+// filter; each segment is a base64 ScVal XDR string or the "*" / "**"
+// wildcards understood by Soroban RPC.
+type EventFilter struct {
+	Type        string     `json:"type,omitempty"`
+	ContractIDs []string   `json:"contractIds,omitempty"`
+	Topics      [][]string `json:"topics,omitempty"`
+}
+
+// EventPagination controls getEvents paging. When Cursor is set the request
+// must not carry a StartLedger.
+type EventPagination struct {
+	Cursor string `json:"cursor,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// GetEventsRequest holds the parameters of a getEvents call.
+type GetEventsRequest struct {
+	StartLedger uint32           `json:"startLedger,omitempty"`
+	EndLedger   uint32           `json:"endLedger,omitempty"`
+	Filters     []EventFilter    `json:"filters,omitempty"`
+	Pagination  *EventPagination `json:"pagination,omitempty"`
+}
+
+// EventInfo is a single contract event as returned by getEvents. Topic and
+// Value are base64-encoded ScVal XDR.
+type EventInfo struct {
+	Type                     string   `json:"type"`
+	Ledger                   uint32   `json:"ledger"`
+	LedgerClosedAt           string   `json:"ledgerClosedAt"`
+	ContractID               string   `json:"contractId"`
+	ID                       string   `json:"id"`
+	PagingToken              string   `json:"pagingToken,omitempty"`
+	Topic                    []string `json:"topic"`
+	Value                    string   `json:"value"`
+	InSuccessfulContractCall bool     `json:"inSuccessfulContractCall"`
+	TxHash                   string   `json:"txHash"`
+}
+
+// GetEventsResponse is the JSON-RPC envelope for getEvents.
+type GetEventsResponse struct {
+	Jsonrpc string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Result  struct {
+		Events       []EventInfo `json:"events"`
+		LatestLedger uint32      `json:"latestLedger"`
+		Cursor       string      `json:"cursor,omitempty"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// NextCursor returns the cursor to resume from after this page. Older RPC
+// versions omit the top-level cursor, so fall back to the last event's id.
+func (r *GetEventsResponse) NextCursor() string {
+	if r.Result.Cursor != "" {
+		return r.Result.Cursor
+	}
+	if n := len(r.Result.Events); n > 0 {
+		last := r.Result.Events[n-1]
+		if last.PagingToken != "" {
+			return last.PagingToken
+		}
+		return last.ID
+	}
+	return ""
+}
+
+// GetEvents calls Soroban RPC getEvents, failing over to alternative
+// endpoints the same way SimulateTransaction does.
+func (c *Client) GetEvents(ctx context.Context, req *GetEventsRequest) (*GetEventsResponse, error) {
+	if req == nil {
+		return nil, errors.WrapValidationError("getEvents request is nil")
+	}
+	if req.Pagination != nil && req.Pagination.Cursor != "" && req.StartLedger != 0 {
+		return nil, errors.WrapValidationError("getEvents: startLedger and cursor are mutually exclusive")
+	}
+	if req.Pagination != nil && req.Pagination.Limit > MaxEventsPageSize {
+		return nil, errors.WrapValidationError(fmt.Sprintf("getEvents: limit %d exceeds maximum of %d", req.Pagination.Limit, MaxEventsPageSize))
+	}
+
+	attempts := c.endpointAttempts()
+	var failures []NodeFailure
+	for attempt := 0; attempt < attempts; attempt++ {
+		resp, err := c.getEventsAttempt(ctx, req)
+		if err == nil {
+			c.markSuccess(c.SorobanURL)
+			return resp, nil
+		}
+
+		c.markFailure(c.SorobanURL)
+
+		failures = append(failures, NodeFailure{URL: c.SorobanURL, Reason: err})
+
+		if attempt < attempts-1 && len(c.AltURLs) > 1 {
+			logger.Logger.Warn("Retrying getEvents with fallback RPC...", "error", err)
+			if !c.rotateURL() {
+				break
+			}
+			continue
+		}
+
+		if len(c.AltURLs) <= 1 {
+			return nil, err
+		}
+	}
+	return nil, &AllNodesFailedError{Failures: failures}
+}
+
+func (c *Client) getEventsAttempt(ctx context.Context, params *GetEventsRequest) (eventsResp *GetEventsResponse, err error) {
+	targetURL := c.SorobanURL
+	timer := c.startMethodTimer(ctx, "rpc.get_events", map[string]string{
+		"network": c.GetNetworkName(),
+		"rpc_url": targetURL,
+	})
+	defer func() {
+		timer.Stop(err)
+	}()
+
+	logger.Logger.Debug("Fetching contract events", "url", targetURL, "start_ledger", params.StartLedger)
+
+	if !c.isHealthy(targetURL) {
+		return nil, errors.WrapRPCConnectionFailed(
+			fmt.Errorf("circuit breaker open for %s", targetURL),
+		)
+	}
+
+	reqBody := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "getEvents",
+		"params":  params,
+	}
+
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, errors.WrapMarshalFailed(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", targetURL, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return nil, errors.WrapRPCConnectionFailed(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.getHTTPClient().Do(req)
+	if err != nil {
+		return nil, errors.WrapRPCConnectionFailed(err)
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WrapUnmarshalFailed(err, "body read error")
+	}
+
+	var rpcResp GetEventsResponse
+	if err := json.Unmarshal(respBytes, &rpcResp); err != nil {
+		return nil, errors.WrapUnmarshalFailed(err, string(respBytes))
+	}
+
+	if rpcResp.Error != nil {
+		return nil, errors.WrapRPCError(targetURL, rpcResp.Error.Message, rpcResp.Error.Code)
+	}
+
+	logger.Logger.Debug("Contract events retrieved", "count", len(rpcResp.Result.Events), "latest_ledger", rpcResp.Result.LatestLedger)
+	return &rpcResp, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEvents_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string           `json:"method"`
+			Params GetEventsRequest `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "getEvents", req.Method)
+		assert.Equal(t, uint32(500), req.Params.StartLedger)
+		assert.Equal(t, []string{"CABC"}, req.Params.Filters[0].ContractIDs)
+
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{
+			"latestLedger":900,
+			"cursor":"0000000002147483648-0000000001",
+			"events":[{"type":"contract","ledger":501,"ledgerClosedAt":"2025-01-01T00:00:00Z",
+				"contractId":"CABC","id":"0000000002147483648-0000000001",
+				"topic":["AAAADwAAAAh0cmFuc2Zlcg=="],"value":"AAAAAQ==",
+				"inSuccessfulContractCall":true,"txHash":"abcd"}]}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{SorobanURL: server.URL, AltURLs: []string{server.URL}}
+	resp, err := client.GetEvents(context.Background(), &GetEventsRequest{
+		StartLedger: 500,
+		Filters:     []EventFilter{{Type: "contract", ContractIDs: []string{"CABC"}}},
+		Pagination:  &EventPagination{Limit: 10},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Result.Events, 1)
+	assert.Equal(t, "abcd", resp.Result.Events[0].TxHash)
+	assert.Equal(t, uint32(900), resp.Result.LatestLedger)
+	assert.Equal(t, "0000000002147483648-0000000001", resp.NextCursor())
+}
+
+func TestGetEvents_RPCError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"startLedger must be within the ledger range"}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{SorobanURL: server.URL, AltURLs: []string{server.URL}}
+	_, err := client.GetEvents(context.Background(), &GetEventsRequest{StartLedger: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ledger range")
+}
+
+func TestGetEvents_ValidatesParams(t *testing.T) {
+	client := &Client{}
+
+	_, err := client.GetEvents(context.Background(), &GetEventsRequest{
+		StartLedger: 10,
+		Pagination:  &EventPagination{Cursor: "abc"},
+	})
+	assert.Error(t, err)
+
+	_, err = client.GetEvents(context.Background(), &GetEventsRequest{
+		Pagination: &EventPagination{Limit: MaxEventsPageSize + 1},
+	})
+	assert.Error(t, err)
+}
+
+func TestGetEventsResponse_NextCursorFallback(t *testing.T) {
+	var resp GetEventsResponse
+	assert.Empty(t, resp.NextCursor())
+
+	resp.Result.Events = []EventInfo{{ID: "a"}, {ID: "b", PagingToken: "b-token"}}
+	assert.Equal(t, "b-token", resp.NextCursor())
+}
@@ -10,6 +10,8 @@ type TransactionResponse struct {
 	EnvelopeXdr   string
 	ResultXdr     string
 	ResultMetaXdr string
+	// Ledger is the sequence of the ledger the transaction was applied in.
+	Ledger uint32
 }
 
 // ParseTransactionResponse converts a Horizon transaction into a TransactionResponse
@@ -18,6 +20,7 @@ func ParseTransactionResponse(tx hProtocol.Transaction) *TransactionResponse {
 		EnvelopeXdr:   tx.EnvelopeXdr,
 		ResultXdr:     tx.ResultXdr,
 		ResultMetaXdr: tx.ResultMetaXdr,
+		Ledger:        uint32(tx.Ledger),
 	}
 }
 
@@ -28,10 +28,6 @@ func TestVerifyLedgerEntryHash_ValidKey(t *testing.T) {
 	}
 
 	sym := xdr.ScSymbol("COUNTER")
-	keyVal := xdr.ScVal{
-		Type: xdr.ScValTypeScvSymbol,
-		Sym:  &sym,
-	symbol := xdr.ScSymbol("COUNTER")
 	keyVal := xdr.ScVal{
 		Type: xdr.ScValTypeScvSymbol,
 		Sym:  &sym,
@@ -215,10 +211,6 @@ func createTestLedgerKey(t *testing.T, seed int) string {
 	}
 
 	sym := xdr.ScSymbol("COUNTER")
-	keyVal := xdr.ScVal{
-		Type: xdr.ScValTypeScvSymbol,
-		Sym:  &sym,
-	symbol := xdr.ScSymbol("COUNTER")
 	keyVal := xdr.ScVal{
 		Type: xdr.ScValTypeScvSymbol,
 		Sym:  &sym,
@@ -0,0 +1,193 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package security
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/config"
+)
+
+// Config configures the rule engine. It is read from the security tables of
+// erst.toml:
+//
+//	[security]
+//	min_severity = "low"
+//
+//	[security.rules.SEC001]
+//	severity = "medium"
+//	threshold_xlm = 250000
+//
+//	[security.rules.SEC003]
+//	enabled = false
+//
+//	[[security.suppress]]
+//	rule = "SEC008"
+//	contract = "CABC..."
+//	reason = "writes are gated by the factory contract"
+type Config struct {
+	// MinSeverity drops findings below this severity; empty keeps all.
+	MinSeverity Severity
+	// Rules holds per-rule overrides keyed by rule ID.
+	Rules        map[string]RuleConfig
+	Suppressions []Suppression
+}
+
+// RuleConfig overrides the defaults of one rule.
+type RuleConfig struct {
+	Disabled bool
+	// Severity replaces the rule's default severity when set.
+	Severity Severity
+	// Params replace the rule's default thresholds by name.
+	Params Params
+}
+
+// Suppression hides matching findings from the report. At least one of Rule
+// and Contract is set; an empty field matches anything.
+type Suppression struct {
+	Rule     string
+	Contract string
+	Reason   string
+}
+
+func (s Suppression) matches(f Finding) bool {
+	if s.Rule != "" && s.Rule != f.RuleID {
+		return false
+	}
+	if s.Contract != "" && s.Contract != f.Contract {
+		return false
+	}
+	return true
+}
+
+// LoadConfig reads the security configuration from the erst configuration
+// file (see config.Paths) and returns it with the path it was read from.
+// Without any file it returns an empty configuration and an empty path.
+func LoadConfig() (*Config, string, error) {
+	tables, path, err := config.LoadTOML()
+	if err != nil {
+		return nil, path, err
+	}
+	cfg, err := configFromTables(tables)
+	if err != nil && path != "" {
+		err = fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, path, err
+}
+
+// ParseConfig reads the security tables from the contents of an erst.toml
+// file. Other tables and keys are ignored. Rule IDs, parameter names and
+// severities are validated against the built-in rules.
+func ParseConfig(content string) (*Config, error) {
+	tables, err := config.ParseTOML(content)
+	if err != nil {
+		return nil, err
+	}
+	return configFromTables(tables)
+}
+
+func configFromTables(tables []config.Table) (*Config, error) {
+	cfg := &Config{Rules: make(map[string]RuleConfig)}
+	for _, t := range tables {
+		var set func(k config.Key) error
+		switch id, isRule := strings.CutPrefix(t.Name, "security.rules."); {
+		case t.Name == "security" && !t.Array:
+			set = cfg.setGlobal
+		case t.Name == "security.suppress" && t.Array:
+			cfg.Suppressions = append(cfg.Suppressions, Suppression{})
+			s := &cfg.Suppressions[len(cfg.Suppressions)-1]
+			set = func(k config.Key) error { return setSuppression(s, k) }
+		case isRule && !t.Array:
+			rule := RuleByID(id)
+			if rule == nil {
+				return nil, fmt.Errorf("line %d: unknown rule %q", t.Line, id)
+			}
+			set = func(k config.Key) error {
+				rc := cfg.Rules[id]
+				err := setRuleOption(&rc, rule, k)
+				cfg.Rules[id] = rc
+				return err
+			}
+		default:
+			continue
+		}
+		for _, k := range t.Keys {
+			if err := set(k); err != nil {
+				return nil, fmt.Errorf("line %d: %w", k.Line, err)
+			}
+		}
+	}
+
+	for i, s := range cfg.Suppressions {
+		if s.Rule == "" && s.Contract == "" {
+			return nil, fmt.Errorf("security.suppress entry %d: set rule, contract or both", i+1)
+		}
+	}
+	return cfg, nil
+}
+
+func (c *Config) setGlobal(k config.Key) error {
+	switch k.Name {
+	case "min_severity":
+		sev, err := ParseSeverity(k.String())
+		if err != nil {
+			return err
+		}
+		c.MinSeverity = sev
+		return nil
+	}
+	return fmt.Errorf("unknown security option %q", k.Name)
+}
+
+func setSuppression(s *Suppression, k config.Key) error {
+	switch k.Name {
+	case "rule":
+		s.Rule = k.String()
+		if RuleByID(s.Rule) == nil {
+			return fmt.Errorf("unknown rule %q", s.Rule)
+		}
+	case "contract":
+		s.Contract = k.String()
+	case "reason":
+		s.Reason = k.String()
+	default:
+		return fmt.Errorf("unknown suppression option %q", k.Name)
+	}
+	return nil
+}
+
+func setRuleOption(rc *RuleConfig, rule *Rule, k config.Key) error {
+	key, raw := k.Name, k.Value
+	switch key {
+	case "enabled":
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("enabled: %w", err)
+		}
+		rc.Disabled = !enabled
+		return nil
+	case "severity":
+		sev, err := ParseSeverity(k.String())
+		if err != nil {
+			return err
+		}
+		rc.Severity = sev
+		return nil
+	}
+
+	if _, ok := rule.Params[key]; !ok {
+		return fmt.Errorf("rule %s has no option %q", rule.ID, key)
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(raw, "_", ""), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s: expected an integer, got %s", key, raw)
+	}
+	if rc.Params == nil {
+		rc.Params = make(Params)
+	}
+	rc.Params[key] = n
+	return nil
+}
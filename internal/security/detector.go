@@ -22,6 +22,30 @@ const (
 	SeverityInfo   Severity = "INFO"
 )
 
+// rank orders severities from INFO (0) to HIGH (3); unknown values rank -1.
+func (s Severity) rank() int {
+	switch s {
+	case SeverityInfo:
+		return 0
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	}
+	return -1
+}
+
+// ParseSeverity parses a severity name case-insensitively.
+func ParseSeverity(s string) (Severity, error) {
+	sev := Severity(strings.ToUpper(strings.TrimSpace(s)))
+	if sev.rank() < 0 {
+		return "", fmt.Errorf("unknown severity %q (use: info, low, medium, high)", s)
+	}
+	return sev, nil
+}
+
 // FindingType categorizes the security issue
 type FindingType string
 
@@ -32,41 +56,51 @@ const (
 
 // Finding represents a security vulnerability or warning
 type Finding struct {
+	// RuleID is the ID of the rule that produced the finding, e.g. "SEC004".
+	RuleID      string      `json:"rule_id"`
 	Type        FindingType `json:"type"`
 	Severity    Severity    `json:"severity"`
 	Title       string      `json:"title"`
 	Description string      `json:"description"`
 	Evidence    string      `json:"evidence,omitempty"`
+	// Contract is the contract the finding concerns, when known.
+	Contract string `json:"contract,omitempty"`
+	// Location points into the transaction, e.g. "event_index:3", or into
+	// the bytecode, e.g. "func[12]@0x1a2f".
+	Location string `json:"location,omitempty"`
+	// Function is the contract function a static finding is in.
+	Function string `json:"function,omitempty"`
+	// File and Line locate a static finding in the contract's source when
+	// the bytecode has debug info.
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
 }
 
-// Detector analyzes transactions for security vulnerabilities
+// Detector analyzes transactions for security vulnerabilities using the
+// built-in rules with their default configuration.
 type Detector struct {
+	engine   *Engine
 	findings []Finding
 }
 
 // NewDetector creates a new security detector
 func NewDetector() *Detector {
+	engine, _ := NewEngine(nil)
 	return &Detector{
+		engine:   engine,
 		findings: make([]Finding, 0),
 	}
 }
 
 // Analyze performs security checks on transaction data
 func (d *Detector) Analyze(envelopeXdr, resultMetaXdr string, events []string, logs []string) []Finding {
-	d.findings = make([]Finding, 0)
-
-	// Decode envelope
-	envelope, err := decodeEnvelope(envelopeXdr)
-	if err == nil {
-		d.checkLargeValueTransfers(envelope)
-		d.checkReentrancyPatterns(envelope, events)
-	}
-
-	// Check patterns that don't require envelope
-	d.checkIntegerOverflow(events, logs)
-	d.checkSuspiciousEvents(events)
-	d.checkAuthorizationBypass(events, logs)
-
+	report := d.engine.Analyze(&Input{
+		EnvelopeXdr:   envelopeXdr,
+		ResultMetaXdr: resultMetaXdr,
+		Events:        events,
+		Logs:          logs,
+	})
+	d.findings = append(make([]Finding, 0, len(report.Findings)), report.Findings...)
 	return d.findings
 }
 
@@ -75,194 +109,6 @@ func (d *Detector) GetFindings() []Finding {
 	return d.findings
 }
 
-func (d *Detector) addFinding(finding Finding) {
-	d.findings = append(d.findings, finding)
-}
-
-// checkLargeValueTransfers detects unusually large value transfers
-func (d *Detector) checkLargeValueTransfers(envelope xdr.TransactionEnvelope) {
-	const largeTransferThreshold = 1000000 * 10000000 // 1M XLM in stroops
-
-	ops := extractOperations(envelope)
-	for _, op := range ops {
-		switch op.Body.Type {
-		case xdr.OperationTypePayment:
-			payment := op.Body.PaymentOp
-			if payment.Amount > xdr.Int64(largeTransferThreshold) {
-				d.addFinding(Finding{
-					Type:        FindingHeuristicWarn,
-					Severity:    SeverityHigh,
-					Title:       "Large Value Transfer Detected",
-					Description: fmt.Sprintf("Transfer of %d stroops (%.2f XLM) detected. Verify recipient address.", payment.Amount, float64(payment.Amount)/10000000.0),
-					Evidence:    fmt.Sprintf("Destination: %s", payment.Destination.Address()),
-				})
-			}
-		case xdr.OperationTypeInvokeHostFunction:
-			// Check for large amounts in contract invocations
-			d.checkContractValueTransfer(op)
-		}
-	}
-}
-
-// checkContractValueTransfer analyzes contract invocations for large transfers
-func (d *Detector) checkContractValueTransfer(op xdr.Operation) {
-	hostFn := op.Body.InvokeHostFunctionOp
-	if hostFn == nil {
-		return
-	}
-
-	if hostFn.HostFunction.Type == xdr.HostFunctionTypeHostFunctionTypeInvokeContract {
-		invokeArgs := hostFn.HostFunction.InvokeContract
-		if invokeArgs == nil {
-			return
-		}
-
-		// Look for amount parameters (common in transfer functions)
-		for _, arg := range invokeArgs.Args {
-			if arg.Type == xdr.ScValTypeScvI128 || arg.Type == xdr.ScValTypeScvU128 {
-				amount := extractAmount(arg)
-				if amount != nil && amount.Cmp(big.NewInt(100000000000000)) > 0 { // 10M tokens (assuming 7 decimals)
-					d.addFinding(Finding{
-						Type:        FindingHeuristicWarn,
-						Severity:    SeverityMedium,
-						Title:       "Large Contract Value Transfer",
-						Description: fmt.Sprintf("Contract invocation with large amount: %s", amount.String()),
-						Evidence:    "Review contract address and function parameters",
-					})
-				}
-			}
-		}
-	}
-}
-
-// checkReentrancyPatterns detects potential reentrancy vulnerabilities
-func (d *Detector) checkReentrancyPatterns(envelope xdr.TransactionEnvelope, events []string) {
-	ops := extractOperations(envelope)
-
-	// Count contract invocations
-	invocationCount := 0
-	for _, op := range ops {
-		if op.Body.Type == xdr.OperationTypeInvokeHostFunction {
-			invocationCount++
-		}
-	}
-
-	// Multiple invocations + state changes = potential reentrancy
-	if invocationCount > 1 {
-		hasStateChange := false
-		for _, event := range events {
-			if strings.Contains(event, "contract_data") || strings.Contains(event, "write") {
-				hasStateChange = true
-				break
-			}
-		}
-
-		if hasStateChange {
-			d.addFinding(Finding{
-				Type:        FindingHeuristicWarn,
-				Severity:    SeverityMedium,
-				Title:       "Potential Reentrancy Pattern",
-				Description: fmt.Sprintf("Transaction contains %d contract invocations with state changes. Verify reentrancy guards are in place.", invocationCount),
-				Evidence:    "Multiple contract calls with storage modifications detected",
-			})
-		}
-	}
-}
-
-// checkIntegerOverflow detects potential integer overflow issues
-func (d *Detector) checkIntegerOverflow(events []string, logs []string) {
-	overflowKeywords := []string{"overflow", "underflow"}
-	arithmeticKeywords := []string{"checked_add", "checked_sub", "checked_mul", "checked_div", "arithmetic"}
-
-	for _, log := range logs {
-		logLower := strings.ToLower(log)
-
-		// Check for explicit overflow/underflow mentions
-		for _, keyword := range overflowKeywords {
-			if strings.Contains(logLower, keyword) {
-				d.addFinding(Finding{
-					Type:        FindingVerifiedRisk,
-					Severity:    SeverityHigh,
-					Title:       "Integer Overflow/Underflow Detected",
-					Description: "Arithmetic operation failed, indicating potential overflow or underflow",
-					Evidence:    log,
-				})
-				return
-			}
-		}
-
-		// Check for arithmetic operation failures
-		for _, keyword := range arithmeticKeywords {
-			if strings.Contains(logLower, keyword) && (strings.Contains(logLower, "fail") || strings.Contains(logLower, "error")) {
-				d.addFinding(Finding{
-					Type:        FindingVerifiedRisk,
-					Severity:    SeverityHigh,
-					Title:       "Integer Overflow/Underflow Detected",
-					Description: "Arithmetic operation failed, indicating potential overflow or underflow",
-					Evidence:    log,
-				})
-				return
-			}
-		}
-	}
-}
-
-// checkSuspiciousEvents analyzes diagnostic events for suspicious patterns
-func (d *Detector) checkSuspiciousEvents(events []string) {
-	for _, event := range events {
-		eventLower := strings.ToLower(event)
-
-		// Check for authorization failures
-		if strings.Contains(eventLower, "auth") && (strings.Contains(eventLower, "fail") || strings.Contains(eventLower, "invalid")) {
-			d.addFinding(Finding{
-				Type:        FindingVerifiedRisk,
-				Severity:    SeverityHigh,
-				Title:       "Authorization Failure",
-				Description: "Contract authorization check failed",
-				Evidence:    event,
-			})
-		}
-
-		// Check for panic/trap events
-		if strings.Contains(eventLower, "panic") || strings.Contains(eventLower, "trap") {
-			d.addFinding(Finding{
-				Type:        FindingVerifiedRisk,
-				Severity:    SeverityHigh,
-				Title:       "Contract Panic/Trap",
-				Description: "Contract execution panicked or trapped",
-				Evidence:    event,
-			})
-		}
-	}
-}
-
-// checkAuthorizationBypass detects potential authorization bypass attempts
-func (d *Detector) checkAuthorizationBypass(events []string, logs []string) {
-	hasAuthCheck := false
-	hasPrivilegedOp := false
-
-	for _, log := range logs {
-		logLower := strings.ToLower(log)
-		if strings.Contains(logLower, "require_auth") || strings.Contains(logLower, "check_auth") {
-			hasAuthCheck = true
-		}
-		if strings.Contains(logLower, "admin") || strings.Contains(logLower, "owner") || strings.Contains(logLower, "privileged") {
-			hasPrivilegedOp = true
-		}
-	}
-
-	// Privileged operation without auth check
-	if hasPrivilegedOp && !hasAuthCheck {
-		d.addFinding(Finding{
-			Type:        FindingHeuristicWarn,
-			Severity:    SeverityHigh,
-			Title:       "Potential Authorization Bypass",
-			Description: "Privileged operation detected without corresponding authorization check",
-			Evidence:    "Review contract authorization logic",
-		})
-	}
-}
-
 // Helper functions
 
 func decodeEnvelope(envelopeXdr string) (xdr.TransactionEnvelope, error) {
@@ -0,0 +1,93 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package security
+
+import "fmt"
+
+// Engine runs the enabled rules with their configured severities and
+// thresholds and applies suppressions.
+type Engine struct {
+	rules        []*Rule
+	minSeverity  Severity
+	suppressions []Suppression
+}
+
+// SuppressedFinding is a finding hidden by a suppression.
+type SuppressedFinding struct {
+	Finding
+	Reason string `json:"reason,omitempty"`
+}
+
+// Report is the outcome of analyzing one transaction.
+type Report struct {
+	Findings   []Finding           `json:"findings"`
+	Suppressed []SuppressedFinding `json:"suppressed,omitempty"`
+}
+
+// NewEngine builds an engine from cfg; a nil cfg runs every rule with its
+// defaults.
+func NewEngine(cfg *Config) (*Engine, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	for id := range cfg.Rules {
+		if RuleByID(id) == nil {
+			return nil, fmt.Errorf("unknown rule %q", id)
+		}
+	}
+
+	e := &Engine{minSeverity: cfg.MinSeverity, suppressions: cfg.Suppressions}
+	for _, rule := range Rules() {
+		rc := cfg.Rules[rule.ID]
+		if rc.Disabled {
+			continue
+		}
+		if rc.Severity != "" {
+			rule.Severity = rc.Severity
+		}
+		for name, value := range rc.Params {
+			if _, ok := rule.Params[name]; !ok {
+				return nil, fmt.Errorf("rule %s has no option %q", rule.ID, name)
+			}
+			rule.Params[name] = value
+		}
+		e.rules = append(e.rules, rule)
+	}
+	return e, nil
+}
+
+// Rules returns the enabled rules with their effective severity and
+// parameters.
+func (e *Engine) Rules() []*Rule {
+	return e.rules
+}
+
+// Analyze runs every enabled rule over in.
+func (e *Engine) Analyze(in *Input) *Report {
+	report := &Report{Findings: make([]Finding, 0)}
+	ri := newRuleInput(in)
+
+	for _, rule := range e.rules {
+		for _, f := range rule.run(ri, rule.Params) {
+			if e.minSeverity != "" && f.Severity.rank() < e.minSeverity.rank() {
+				continue
+			}
+			if s, ok := e.suppression(f); ok {
+				report.Suppressed = append(report.Suppressed, SuppressedFinding{Finding: f, Reason: s.Reason})
+				continue
+			}
+			report.Findings = append(report.Findings, f)
+		}
+	}
+	return report
+}
+
+func (e *Engine) suppression(f Finding) (Suppression, bool) {
+	for _, s := range e.suppressions {
+		if s.matches(f) {
+			return s, true
+		}
+	}
+	return Suppression{}, false
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/simulator"
+)
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(`
+rpc_url = "https://soroban-testnet.stellar.org"
+
+[security]
+min_severity = "low" # drop INFO
+
+[security.rules.SEC001]
+severity = "medium"
+threshold_xlm = 250_000
+
+[security.rules.SEC003]
+enabled = false
+
+[[security.suppress]]
+rule = "SEC008"
+contract = "CABC"
+reason = "gated by # factory"
+`)
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	if cfg.MinSeverity != SeverityLow {
+		t.Errorf("MinSeverity = %s, want LOW", cfg.MinSeverity)
+	}
+	if rc := cfg.Rules[RuleLargePayment]; rc.Severity != SeverityMedium || rc.Params["threshold_xlm"] != 250000 {
+		t.Errorf("SEC001 config = %+v", rc)
+	}
+	if !cfg.Rules[RuleReentrancyPattern].Disabled {
+		t.Error("SEC003 should be disabled")
+	}
+	want := Suppression{Rule: RuleUnauthorizedStateWrite, Contract: "CABC", Reason: "gated by # factory"}
+	if len(cfg.Suppressions) != 1 || cfg.Suppressions[0] != want {
+		t.Errorf("Suppressions = %+v, want [%+v]", cfg.Suppressions, want)
+	}
+}
+
+func TestParseConfig_Errors(t *testing.T) {
+	tests := map[string]string{
+		"unknown rule":     "[security.rules.SEC999]\nseverity = \"high\"",
+		"unknown param":    "[security.rules.SEC004]\nthreshold = 3",
+		"bad severity":     "[security]\nmin_severity = \"critical\"",
+		"bad threshold":    "[security.rules.SEC001]\nthreshold_xlm = lots",
+		"empty suppress":   "[[security.suppress]]\nreason = \"everything\"",
+		"unknown suppress": "[[security.suppress]]\nrule = \"SEC123\"",
+		"malformed line":   "[security]\nmin_severity",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseConfig(content); err == nil {
+				t.Errorf("ParseConfig(%q) succeeded, want error", content)
+			}
+		})
+	}
+}
+
+func TestEngine_Configuration(t *testing.T) {
+	logs := []string{"checked_add failed: overflow", "Privileged admin operation"}
+	events := []string{"PANIC: trap"}
+
+	engine, err := NewEngine(&Config{
+		Rules: map[string]RuleConfig{
+			RuleIntegerOverflow: {Severity: SeverityLow},
+			RuleContractTrap:    {Disabled: true},
+		},
+		Suppressions: []Suppression{{Rule: RuleAuthBypass, Reason: "admin is a multisig"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := engine.Analyze(&Input{Events: events, Logs: logs})
+	if len(report.Findings) != 1 {
+		t.Fatalf("Findings = %+v, want only the overflow", report.Findings)
+	}
+	if f := report.Findings[0]; f.RuleID != RuleIntegerOverflow || f.Severity != SeverityLow {
+		t.Errorf("finding = %s/%s, want %s/LOW", f.RuleID, f.Severity, RuleIntegerOverflow)
+	}
+	if len(report.Suppressed) != 1 || report.Suppressed[0].RuleID != RuleAuthBypass || report.Suppressed[0].Reason != "admin is a multisig" {
+		t.Errorf("Suppressed = %+v", report.Suppressed)
+	}
+
+	engine, _ = NewEngine(&Config{
+		MinSeverity: SeverityMedium,
+		Rules:       map[string]RuleConfig{RuleIntegerOverflow: {Severity: SeverityLow}},
+	})
+	for _, f := range engine.Analyze(&Input{Events: events, Logs: logs}).Findings {
+		if f.RuleID == RuleIntegerOverflow {
+			t.Error("LOW finding reported despite min_severity MEDIUM")
+		}
+	}
+}
+
+func TestEngine_ContractSuppression(t *testing.T) {
+	a, b := "CAAA", "CBBB"
+	in := &Input{CategorizedEvents: []simulator.CategorizedEvent{
+		{EventType: "storage_write", ContractID: &a, Topics: []string{"counter"}},
+		{EventType: "storage_write", ContractID: &b, Topics: []string{"counter"}},
+	}}
+
+	engine, err := NewEngine(&Config{Suppressions: []Suppression{{Contract: a}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := engine.Analyze(in)
+	if len(report.Findings) != 1 || report.Findings[0].Contract != b {
+		t.Errorf("Findings = %+v, want one for %s", report.Findings, b)
+	}
+	if len(report.Suppressed) != 1 || report.Suppressed[0].Contract != a {
+		t.Errorf("Suppressed = %+v, want one for %s", report.Suppressed, a)
+	}
+}
+
+func TestEngine_ThresholdOverride(t *testing.T) {
+	envelope := encodeEnvelope(t, createMockEnvelopeWithLargePayment(t))
+
+	engine, _ := NewEngine(nil)
+	if countRule(engine.Analyze(&Input{EnvelopeXdr: envelope}).Findings, RuleLargePayment) != 1 {
+		t.Fatal("expected a large payment finding with the default threshold")
+	}
+
+	engine, err := NewEngine(&Config{Rules: map[string]RuleConfig{
+		RuleLargePayment: {Params: Params{"threshold_xlm": 1_000_000_000}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := countRule(engine.Analyze(&Input{EnvelopeXdr: envelope}).Findings, RuleLargePayment); n != 0 {
+		t.Errorf("got %d large payment findings above a raised threshold", n)
+	}
+
+	if _, err := NewEngine(&Config{Rules: map[string]RuleConfig{RuleLargePayment: {Params: Params{"limit": 1}}}}); err == nil || !strings.Contains(err.Error(), "limit") {
+		t.Errorf("NewEngine() error = %v, want unknown option", err)
+	}
+}
+
+func countRule(findings []Finding, id string) int {
+	n := 0
+	for _, f := range findings {
+		if f.RuleID == id {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package security
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+func diagnosticEvent(t *testing.T, contract *xdr.ContractId, topics ...xdr.ScVal) string {
+	t.Helper()
+	diag := xdr.DiagnosticEvent{Event: xdr.ContractEvent{
+		ContractId: contract,
+		Type:       xdr.ContractEventTypeDiagnostic,
+		Body:       xdr.ContractEventBody{V0: &xdr.ContractEventV0{Topics: topics, Data: xdr.ScVal{Type: xdr.ScValTypeScvVoid}}},
+	}}
+	raw, err := diag.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func TestReentrancyPattern_CallGraph(t *testing.T) {
+	symbol := func(s string) xdr.ScVal {
+		v := xdr.ScSymbol(s)
+		return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &v}
+	}
+	var a, b xdr.ContractId
+	a[0], b[0] = 0xa, 0xb
+	call := func(id xdr.ContractId, fn string) string {
+		bytes := xdr.ScBytes(id[:])
+		return diagnosticEvent(t, nil, symbol("fn_call"), xdr.ScVal{Type: xdr.ScValTypeScvBytes, Bytes: &bytes}, symbol(fn))
+	}
+	ret := func(id xdr.ContractId, fn string) string {
+		return diagnosticEvent(t, &id, symbol("fn_return"), symbol(fn))
+	}
+
+	// A.withdraw calls B.pay, which calls back into A.withdraw.
+	findings := RuleByID(RuleReentrancyPattern).Check(&Input{DiagnosticEventsXdr: []string{
+		call(a, "withdraw"),
+		call(b, "pay"),
+		call(a, "withdraw"),
+		ret(a, "withdraw"),
+		ret(b, "pay"),
+		ret(a, "withdraw"),
+	}})
+
+	if len(findings) != 1 {
+		t.Fatalf("findings = %+v, want one re-entry", findings)
+	}
+	f := findings[0]
+	if f.Title != "Contract Re-entry" || !strings.HasPrefix(f.Contract, "C") || f.Location != "function:withdraw" {
+		t.Errorf("finding = %+v", f)
+	}
+	if strings.Count(f.Evidence, "->") != 2 {
+		t.Errorf("evidence = %q, want the A -> B -> A path", f.Evidence)
+	}
+}
@@ -0,0 +1,555 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package security
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/callgraph"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/dotandev/hintents/internal/tokenflow"
+	"github.com/dotandev/hintents/internal/wasmaudit"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// Built-in rule IDs. IDs are stable: they are what erst.toml and SARIF
+// consumers refer to.
+const (
+	RuleLargePayment           = "SEC001"
+	RuleLargeContractAmount    = "SEC002"
+	RuleReentrancyPattern      = "SEC003"
+	RuleIntegerOverflow        = "SEC004"
+	RuleAuthFailure            = "SEC005"
+	RuleContractTrap           = "SEC006"
+	RuleAuthBypass             = "SEC007"
+	RuleUnauthorizedStateWrite = "SEC008"
+	RuleTokenBalanceMismatch   = "SEC013"
+
+	// Static rules, run over contract bytecode by erst audit-wasm.
+	RuleWasmMissingAuth     = "SEC009"
+	RuleWasmArithmeticPanic = "SEC010"
+	RuleWasmStorageLoop     = "SEC011"
+	RuleWasmMissingTTL      = "SEC012"
+)
+
+// Input is the data of one replayed transaction that rules inspect. Any field
+// may be empty; rules skip the checks they lack data for.
+type Input struct {
+	EnvelopeXdr   string
+	ResultMetaXdr string
+	// Events are the diagnostic events reported by the simulator.
+	Events []string
+	// DiagnosticEventsXdr are the same events as base64 DiagnosticEvent
+	// XDR, from which the call graph is built.
+	DiagnosticEventsXdr []string
+	Logs                []string
+	// CategorizedEvents, when present, are used instead of Events for
+	// storage and auth tracking.
+	CategorizedEvents []simulator.CategorizedEvent
+	// WasmAudit is the static analysis of a contract's bytecode, inspected
+	// by the SEC009-SEC012 rules.
+	WasmAudit *wasmaudit.Report
+}
+
+// Params holds the numeric thresholds of a rule by name.
+type Params map[string]int64
+
+// Rule is a single security check.
+type Rule struct {
+	ID string
+	// Name is a short kebab-case identifier, e.g. "large-payment".
+	Name        string
+	Description string
+	Type        FindingType
+	Severity    Severity
+	// Params are the rule's default thresholds; erst.toml may override them.
+	Params Params
+
+	check func(in *ruleInput, p Params) []Finding
+}
+
+// Check runs the rule with its default parameters. Findings carry the rule's
+// ID, type and default severity.
+func (r *Rule) Check(in *Input) []Finding {
+	return r.run(newRuleInput(in), r.Params)
+}
+
+func (r *Rule) run(in *ruleInput, p Params) []Finding {
+	findings := r.check(in, p)
+	for i := range findings {
+		findings[i].RuleID = r.ID
+		findings[i].Type = r.Type
+		findings[i].Severity = r.Severity
+	}
+	return findings
+}
+
+// ruleInput is Input with the envelope decoded once for all rules.
+type ruleInput struct {
+	*Input
+	ops         []xdr.Operation
+	hasEnvelope bool
+}
+
+func newRuleInput(in *Input) *ruleInput {
+	ri := &ruleInput{Input: in}
+	if envelope, err := decodeEnvelope(in.EnvelopeXdr); err == nil {
+		ri.ops = extractOperations(envelope)
+		ri.hasEnvelope = true
+	}
+	return ri
+}
+
+// Rules returns the built-in rules in ID order.
+func Rules() []*Rule {
+	return []*Rule{
+		{
+			ID:          RuleLargePayment,
+			Name:        "large-payment",
+			Description: "Native payment above threshold_xlm XLM.",
+			Type:        FindingHeuristicWarn,
+			Severity:    SeverityHigh,
+			Params:      Params{"threshold_xlm": 1000000},
+			check:       checkLargePayments,
+		},
+		{
+			ID:          RuleLargeContractAmount,
+			Name:        "large-contract-amount",
+			Description: "Contract invocation with an i128/u128 argument above threshold (in base units).",
+			Type:        FindingHeuristicWarn,
+			Severity:    SeverityMedium,
+			Params:      Params{"threshold": 100000000000000}, // 10M tokens at 7 decimals
+			check:       checkLargeContractAmounts,
+		},
+		{
+			ID:          RuleReentrancyPattern,
+			Name:        "reentrancy-pattern",
+			Description: "Contract re-entry or state write after an external call in the diagnostic call graph; without call events, at least min_invocations invocations together with storage writes.",
+			Type:        FindingHeuristicWarn,
+			Severity:    SeverityMedium,
+			Params:      Params{"min_invocations": 2},
+			check:       checkReentrancyPattern,
+		},
+		{
+			ID:          RuleIntegerOverflow,
+			Name:        "integer-overflow",
+			Description: "Arithmetic overflow or underflow reported in contract logs.",
+			Type:        FindingVerifiedRisk,
+			Severity:    SeverityHigh,
+			check:       checkIntegerOverflow,
+		},
+		{
+			ID:          RuleAuthFailure,
+			Name:        "auth-failure",
+			Description: "Authorization check failed during execution.",
+			Type:        FindingVerifiedRisk,
+			Severity:    SeverityHigh,
+			check:       checkAuthFailures,
+		},
+		{
+			ID:          RuleContractTrap,
+			Name:        "contract-trap",
+			Description: "Contract execution panicked or trapped.",
+			Type:        FindingVerifiedRisk,
+			Severity:    SeverityHigh,
+			check:       checkContractTraps,
+		},
+		{
+			ID:          RuleAuthBypass,
+			Name:        "auth-bypass",
+			Description: "Privileged operation logged without a require_auth or check_auth.",
+			Type:        FindingHeuristicWarn,
+			Severity:    SeverityHigh,
+			check:       checkAuthBypass,
+		},
+		{
+			ID:          RuleUnauthorizedStateWrite,
+			Name:        "unauthorized-state-write",
+			Description: "Contract storage write not preceded by require_auth in the same contract.",
+			Type:        FindingHeuristicWarn,
+			Severity:    SeverityHigh,
+			check:       checkUnauthorizedStateWrites,
+		},
+		{
+			ID:          RuleWasmMissingAuth,
+			Name:        "wasm-missing-auth",
+			Description: "Exported function writes contract storage with no reachable require_auth.",
+			Type:        FindingHeuristicWarn,
+			Severity:    SeverityHigh,
+			check:       wasmIssues(wasmaudit.KindMissingAuth, "Storage write without authorization"),
+		},
+		{
+			ID:          RuleWasmArithmeticPanic,
+			Name:        "wasm-arithmetic-panic",
+			Description: "Integer overflow check aborts through a panic instead of returning a contract error.",
+			Type:        FindingHeuristicWarn,
+			Severity:    SeverityLow,
+			check:       wasmIssues(wasmaudit.KindArithmeticPanic, "Arithmetic overflow panics"),
+		},
+		{
+			ID:          RuleWasmStorageLoop,
+			Name:        "wasm-storage-loop",
+			Description: "Loop accesses contract storage on every iteration.",
+			Type:        FindingHeuristicWarn,
+			Severity:    SeverityMedium,
+			check:       wasmIssues(wasmaudit.KindStorageLoop, "Storage access in loop"),
+		},
+		{
+			ID:          RuleWasmMissingTTL,
+			Name:        "wasm-missing-ttl-extension",
+			Description: "Exported function writes persistent storage with no reachable extend_contract_data_ttl.",
+			Type:        FindingHeuristicWarn,
+			Severity:    SeverityMedium,
+			check:       wasmIssues(wasmaudit.KindMissingTTL, "Persistent write without TTL extension"),
+		},
+		{
+			ID:          RuleTokenBalanceMismatch,
+			Name:        "token-balance-mismatch",
+			Description: "Token balance change in the ledger that the token's transfer/mint/burn events do not account for.",
+			Type:        FindingVerifiedRisk,
+			Severity:    SeverityHigh,
+			check:       checkTokenBalanceMismatch,
+		},
+	}
+}
+
+// RuleByID returns the built-in rule with the given ID, or nil.
+func RuleByID(id string) *Rule {
+	for _, r := range Rules() {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func checkLargePayments(in *ruleInput, p Params) []Finding {
+	threshold := big.NewInt(p["threshold_xlm"])
+	threshold.Mul(threshold, big.NewInt(10000000))
+
+	var findings []Finding
+	for _, op := range in.ops {
+		if op.Body.Type != xdr.OperationTypePayment || op.Body.PaymentOp == nil {
+			continue
+		}
+		payment := op.Body.PaymentOp
+		if big.NewInt(int64(payment.Amount)).Cmp(threshold) <= 0 {
+			continue
+		}
+		findings = append(findings, Finding{
+			Title:       "Large Value Transfer Detected",
+			Description: fmt.Sprintf("Transfer of %d stroops (%.2f XLM) detected. Verify recipient address.", payment.Amount, float64(payment.Amount)/10000000.0),
+			Evidence:    fmt.Sprintf("Destination: %s", payment.Destination.Address()),
+		})
+	}
+	return findings
+}
+
+func checkLargeContractAmounts(in *ruleInput, p Params) []Finding {
+	threshold := big.NewInt(p["threshold"])
+
+	var findings []Finding
+	for _, op := range in.ops {
+		hostFn := op.Body.InvokeHostFunctionOp
+		if hostFn == nil || hostFn.HostFunction.Type != xdr.HostFunctionTypeHostFunctionTypeInvokeContract {
+			continue
+		}
+		invokeArgs := hostFn.HostFunction.InvokeContract
+		if invokeArgs == nil {
+			continue
+		}
+		contract, _ := invokeArgs.ContractAddress.String()
+
+		// Look for amount parameters (common in transfer functions)
+		for _, arg := range invokeArgs.Args {
+			amount := extractAmount(arg)
+			if amount == nil || amount.Cmp(threshold) <= 0 {
+				continue
+			}
+			findings = append(findings, Finding{
+				Title:       "Large Contract Value Transfer",
+				Description: fmt.Sprintf("Contract invocation with large amount: %s", amount.String()),
+				Evidence:    "Review contract address and function parameters",
+				Contract:    contract,
+				Location:    fmt.Sprintf("function:%s", invokeArgs.FunctionName),
+			})
+		}
+	}
+	return findings
+}
+
+// checkReentrancyPattern reports re-entry and writes after external calls
+// from the call graph of the fn_call/fn_return diagnostic events. Without
+// call events it falls back to counting invocations alongside storage
+// writes.
+func checkReentrancyPattern(in *ruleInput, p Params) []Finding {
+	if graph, err := callgraph.FromEvents(in.DiagnosticEventsXdr); err == nil && len(graph.Edges) > 0 {
+		return callGraphFindings(graph)
+	}
+
+	invocationCount := 0
+	for _, op := range in.ops {
+		if op.Body.Type == xdr.OperationTypeInvokeHostFunction {
+			invocationCount++
+		}
+	}
+	if int64(invocationCount) < p["min_invocations"] {
+		return nil
+	}
+
+	for _, event := range in.Events {
+		if strings.Contains(event, "contract_data") || strings.Contains(event, "write") {
+			return []Finding{{
+				Title:       "Potential Reentrancy Pattern",
+				Description: fmt.Sprintf("Transaction contains %d contract invocations with state changes. Verify reentrancy guards are in place.", invocationCount),
+				Evidence:    "Multiple contract calls with storage modifications detected",
+			}}
+		}
+	}
+	return nil
+}
+
+func callGraphFindings(graph *callgraph.Graph) []Finding {
+	var findings []Finding
+	for _, f := range graph.Analyze(callgraph.Options{}) {
+		var title string
+		switch f.Kind {
+		case callgraph.KindReentry:
+			title = "Contract Re-entry"
+		case callgraph.KindWriteAfterCall:
+			title = "State Write After External Call"
+		default:
+			continue
+		}
+		findings = append(findings, Finding{
+			Title:       title,
+			Description: f.Detail,
+			Evidence:    callgraph.FormatPath(f.Path),
+			Contract:    callgraph.FullID(f.Contract),
+			Location:    fmt.Sprintf("function:%s", f.Function),
+		})
+	}
+	return findings
+}
+
+// checkTokenBalanceMismatch reconciles the SEP-41 events of the transaction
+// with the balance entries it changed.
+func checkTokenBalanceMismatch(in *ruleInput, _ Params) []Finding {
+	if in.ResultMetaXdr == "" {
+		return nil
+	}
+	flows, err := tokenflow.BuildReport("", in.ResultMetaXdr)
+	if err != nil {
+		return nil
+	}
+
+	var findings []Finding
+	for _, d := range flows.Discrepancies {
+		var title string
+		switch d.Kind {
+		case tokenflow.DiscrepancyUnreported:
+			title = "Unreported Token Balance Change"
+		case tokenflow.DiscrepancyPhantom:
+			title = "Token Event Without Balance Change"
+		default:
+			title = "Token Event Amount Mismatch"
+		}
+		findings = append(findings, Finding{
+			Title:       title,
+			Description: d.Detail() + ". Compliant tokens emit an event for every balance change.",
+			Evidence:    fmt.Sprintf("ledger change: %s, event net flow: %s", d.Ledger, d.Events),
+			Contract:    d.Token.ID,
+			Location:    fmt.Sprintf("account:%s", d.Account),
+		})
+	}
+	return findings
+}
+
+func checkIntegerOverflow(in *ruleInput, _ Params) []Finding {
+	overflowKeywords := []string{"overflow", "underflow"}
+	arithmeticKeywords := []string{"checked_add", "checked_sub", "checked_mul", "checked_div", "arithmetic"}
+
+	finding := func(log string) []Finding {
+		return []Finding{{
+			Title:       "Integer Overflow/Underflow Detected",
+			Description: "Arithmetic operation failed, indicating potential overflow or underflow",
+			Evidence:    log,
+		}}
+	}
+
+	for _, log := range in.Logs {
+		logLower := strings.ToLower(log)
+		for _, keyword := range overflowKeywords {
+			if strings.Contains(logLower, keyword) {
+				return finding(log)
+			}
+		}
+		for _, keyword := range arithmeticKeywords {
+			if strings.Contains(logLower, keyword) && (strings.Contains(logLower, "fail") || strings.Contains(logLower, "error")) {
+				return finding(log)
+			}
+		}
+	}
+	return nil
+}
+
+func checkAuthFailures(in *ruleInput, _ Params) []Finding {
+	var findings []Finding
+	for _, event := range in.Events {
+		eventLower := strings.ToLower(event)
+		if strings.Contains(eventLower, "auth") && (strings.Contains(eventLower, "fail") || strings.Contains(eventLower, "invalid")) {
+			findings = append(findings, Finding{
+				Title:       "Authorization Failure",
+				Description: "Contract authorization check failed",
+				Evidence:    event,
+			})
+		}
+	}
+	return findings
+}
+
+func checkContractTraps(in *ruleInput, _ Params) []Finding {
+	var findings []Finding
+	for _, event := range in.Events {
+		eventLower := strings.ToLower(event)
+		if strings.Contains(eventLower, "panic") || strings.Contains(eventLower, "trap") {
+			findings = append(findings, Finding{
+				Title:       "Contract Panic/Trap",
+				Description: "Contract execution panicked or trapped",
+				Evidence:    event,
+			})
+		}
+	}
+	return findings
+}
+
+func checkAuthBypass(in *ruleInput, _ Params) []Finding {
+	hasAuthCheck := false
+	hasPrivilegedOp := false
+
+	for _, log := range in.Logs {
+		logLower := strings.ToLower(log)
+		if strings.Contains(logLower, "require_auth") || strings.Contains(logLower, "check_auth") {
+			hasAuthCheck = true
+		}
+		if strings.Contains(logLower, "admin") || strings.Contains(logLower, "owner") || strings.Contains(logLower, "privileged") {
+			hasPrivilegedOp = true
+		}
+	}
+
+	if !hasPrivilegedOp || hasAuthCheck {
+		return nil
+	}
+	return []Finding{{
+		Title:       "Potential Authorization Bypass",
+		Description: "Privileged operation detected without corresponding authorization check",
+		Evidence:    "Review contract authorization logic",
+	}}
+}
+
+// checkUnauthorizedStateWrites tracks require_auth and storage_write events
+// per contract. Categorized events are preferred; otherwise diagnostic events
+// encoded as JSON objects ({"type":"auth"|"storage_write","contract":...})
+// are used.
+func checkUnauthorizedStateWrites(in *ruleInput, _ Params) []Finding {
+	if len(in.CategorizedEvents) > 0 {
+		return checkCategorizedStateWrites(in.CategorizedEvents)
+	}
+	return checkJSONStateWrites(in.Events)
+}
+
+func checkCategorizedStateWrites(events []simulator.CategorizedEvent) []Finding {
+	authed := make(map[string]bool)
+
+	var findings []Finding
+	for i, event := range events {
+		contractID := ""
+		if event.ContractID != nil {
+			contractID = *event.ContractID
+		}
+
+		switch event.EventType {
+		case "require_auth":
+			authed[contractID] = true
+		case "storage_write":
+			if authed[contractID] || isSACStorageWrite(event) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Title:       "Unauthorized State Modification",
+				Description: fmt.Sprintf("State modification without require_auth in contract %s", contractID),
+				Contract:    contractID,
+				Location:    fmt.Sprintf("event_index:%d", i),
+			})
+		}
+	}
+	return findings
+}
+
+// isSACStorageWrite reports whether a write looks like Stellar Asset Contract
+// bookkeeping, which is authorized by the asset's own rules.
+func isSACStorageWrite(event simulator.CategorizedEvent) bool {
+	for _, topic := range event.Topics {
+		topicLower := strings.ToLower(topic)
+		if strings.Contains(topicLower, "balance") ||
+			strings.Contains(topicLower, "allowance") ||
+			strings.Contains(topicLower, "admin") ||
+			strings.Contains(topicLower, "metadata") {
+			return true
+		}
+	}
+
+	dataLower := strings.ToLower(event.Data)
+	return strings.Contains(dataLower, "stellar_asset") || strings.Contains(dataLower, "sac_")
+}
+
+type jsonEvent struct {
+	Type     string `json:"type"`
+	Contract string `json:"contract,omitempty"`
+	Address  string `json:"address,omitempty"`
+}
+
+func checkJSONStateWrites(events []string) []Finding {
+	authed := make(map[string]bool)
+
+	var findings []Finding
+	for i, raw := range events {
+		var event jsonEvent
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			continue
+		}
+		if event.Contract == "" || event.Contract == "unknown" {
+			continue
+		}
+
+		switch event.Type {
+		case "auth":
+			authed[event.Contract] = true
+		case "storage_write":
+			if authed[event.Contract] || isSACContractName(event.Contract) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Title:       "Unauthorized State Modification",
+				Description: "Storage write operation without prior require_auth check",
+				Contract:    event.Contract,
+				Location:    fmt.Sprintf("event_index:%d", i),
+			})
+		}
+	}
+	return findings
+}
+
+func isSACContractName(contract string) bool {
+	contractLower := strings.ToLower(contract)
+	for _, pattern := range []string{"stellar_asset", "sac", "token"} {
+		if strings.Contains(contractLower, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,241 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFOptions describes the run a SARIF log is produced for.
+type SARIFOptions struct {
+	ToolVersion string
+	// TxHash identifies the replayed transaction.
+	TxHash string
+	// ArtifactURI, when set, is reported as the physical location of every
+	// result without a source location of its own (e.g. the contract's
+	// source file). Code scanning dashboards such as GitHub's only display
+	// results that have one.
+	ArtifactURI string
+}
+
+// SARIFLog is a SARIF 2.1.0 log with a single run.
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           map[string]any     `json:"properties,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []sarifLocation    `json:"locations,omitempty"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+	Properties          map[string]any     `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// SARIF converts a report to a SARIF log. rules are listed as the tool's
+// rule metadata and should be the engine's rules, so that configured
+// severities are reflected. Suppressed findings are included with an
+// external suppression so dashboards show them as dismissed.
+func SARIF(report *Report, rules []*Rule, opts SARIFOptions) *SARIFLog {
+	driver := sarifDriver{
+		Name:           "erst",
+		Version:        opts.ToolVersion,
+		InformationURI: "https://github.com/dotandev/hintents",
+		Rules:          make([]sarifRule, 0, len(rules)),
+	}
+	ruleIndex := make(map[string]int, len(rules))
+	for i, r := range rules {
+		ruleIndex[r.ID] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   r.ID,
+			Name:                 r.Name,
+			ShortDescription:     sarifMessage{Text: r.Description},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(r.Severity)},
+			Properties: map[string]any{
+				"tags":              []string{"security", strings.ToLower(string(r.Type))},
+				"security-severity": securitySeverity(r.Severity),
+			},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(report.Findings)+len(report.Suppressed))
+	for _, f := range report.Findings {
+		results = append(results, sarifResultFor(f, ruleIndex, opts))
+	}
+	for _, s := range report.Suppressed {
+		result := sarifResultFor(s.Finding, ruleIndex, opts)
+		result.Suppressions = []sarifSuppression{{Kind: "external", Justification: s.Reason}}
+		results = append(results, result)
+	}
+
+	return &SARIFLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
+
+// MarshalSARIF renders a report as indented SARIF JSON.
+func MarshalSARIF(report *Report, rules []*Rule, opts SARIFOptions) ([]byte, error) {
+	return json.MarshalIndent(SARIF(report, rules, opts), "", "  ")
+}
+
+func sarifResultFor(f Finding, ruleIndex map[string]int, opts SARIFOptions) sarifResult {
+	text := f.Title
+	if f.Description != "" {
+		text += ": " + f.Description
+	}
+	if f.Evidence != "" {
+		text += " (" + f.Evidence + ")"
+	}
+
+	index, ok := ruleIndex[f.RuleID]
+	if !ok {
+		index = -1
+	}
+	result := sarifResult{
+		RuleID:              f.RuleID,
+		RuleIndex:           index,
+		Level:               sarifLevel(f.Severity),
+		Message:             sarifMessage{Text: text},
+		PartialFingerprints: map[string]string{"erstFinding/v1": fingerprint(f)},
+		Properties:          map[string]any{"findingType": string(f.Type)},
+	}
+	if opts.TxHash != "" {
+		result.Properties["transaction"] = opts.TxHash
+	}
+	if f.Location != "" {
+		result.Properties["location"] = f.Location
+	}
+
+	var loc sarifLocation
+	switch {
+	case f.File != "":
+		loc.PhysicalLocation = &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: f.File},
+			Region:           sarifRegion{StartLine: max(f.Line, 1)},
+		}
+	case opts.ArtifactURI != "":
+		loc.PhysicalLocation = &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: opts.ArtifactURI},
+			Region:           sarifRegion{StartLine: 1},
+		}
+	}
+	if f.Contract != "" {
+		loc.LogicalLocations = append(loc.LogicalLocations, sarifLogicalLocation{Name: f.Contract, FullyQualifiedName: f.Contract, Kind: "module"})
+	}
+	if f.Function != "" {
+		loc.LogicalLocations = append(loc.LogicalLocations, sarifLogicalLocation{Name: f.Function, FullyQualifiedName: f.Function, Kind: "function"})
+	}
+	if loc.PhysicalLocation != nil || loc.LogicalLocations != nil {
+		result.Locations = []sarifLocation{loc}
+	}
+	return result
+}
+
+// fingerprint identifies a finding across runs so dashboards can track it;
+// it deliberately leaves out the transaction.
+func fingerprint(f Finding) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{f.RuleID, f.Contract, f.Title, f.Evidence}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func sarifLevel(s Severity) string {
+	switch s {
+	case SeverityHigh:
+		return "error"
+	case SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// securitySeverity maps severities onto the CVSS-like scores code scanning
+// uses to rank security results.
+func securitySeverity(s Severity) string {
+	switch s {
+	case SeverityHigh:
+		return "8.0"
+	case SeverityMedium:
+		return "5.5"
+	case SeverityLow:
+		return "3.0"
+	default:
+		return "0.0"
+	}
+}
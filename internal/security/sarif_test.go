@@ -0,0 +1,76 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package security
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMarshalSARIF(t *testing.T) {
+	engine, err := NewEngine(&Config{Suppressions: []Suppression{{Rule: RuleAuthBypass, Reason: "reviewed"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := engine.Analyze(&Input{
+		Events: []string{"PANIC: trap"},
+		Logs:   []string{"Privileged admin operation"},
+	})
+
+	data, err := MarshalSARIF(report, engine.Rules(), SARIFOptions{ToolVersion: "1.2.3", TxHash: "abc", ArtifactURI: "src/lib.rs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name    string `json:"name"`
+					Version string `json:"version"`
+					Rules   []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID       string `json:"ruleId"`
+				RuleIndex    int    `json:"ruleIndex"`
+				Level        string `json:"level"`
+				Locations    []any  `json:"locations"`
+				Suppressions []struct {
+					Kind          string `json:"kind"`
+					Justification string `json:"justification"`
+				} `json:"suppressions"`
+				PartialFingerprints map[string]string `json:"partialFingerprints"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version = %s, runs = %d", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "erst" || run.Tool.Driver.Version != "1.2.3" || len(run.Tool.Driver.Rules) != len(Rules()) {
+		t.Errorf("driver = %+v", run.Tool.Driver)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("results = %+v, want trap and suppressed bypass", run.Results)
+	}
+
+	trap, bypass := run.Results[0], run.Results[1]
+	if trap.RuleID != RuleContractTrap || trap.Level != "error" || run.Tool.Driver.Rules[trap.RuleIndex].ID != RuleContractTrap {
+		t.Errorf("trap result = %+v", trap)
+	}
+	if len(trap.Locations) != 1 || trap.PartialFingerprints["erstFinding/v1"] == "" || len(trap.Suppressions) != 0 {
+		t.Errorf("trap result = %+v", trap)
+	}
+	if bypass.RuleID != RuleAuthBypass || len(bypass.Suppressions) != 1 || bypass.Suppressions[0].Justification != "reviewed" {
+		t.Errorf("suppressed result = %+v", bypass)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package security
+
+import (
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/wasmaudit"
+)
+
+// wasmIssues returns a check that reports the bytecode issues of one kind.
+func wasmIssues(kind wasmaudit.Kind, title string) func(*ruleInput, Params) []Finding {
+	return func(in *ruleInput, _ Params) []Finding {
+		if in.WasmAudit == nil {
+			return nil
+		}
+		var findings []Finding
+		for _, issue := range in.WasmAudit.Issues {
+			if issue.Kind != kind {
+				continue
+			}
+			f := Finding{
+				Title:       title,
+				Description: issue.Detail,
+				Evidence:    fmt.Sprintf("export %s, function %s", issue.Export, issue.Function),
+				Location:    fmt.Sprintf("func[%d]@0x%x", issue.Func, issue.Offset),
+				Function:    issue.Function,
+			}
+			if issue.Source != nil {
+				f.File = issue.Source.File
+				f.Line = issue.Source.Line
+			}
+			findings = append(findings, f)
+		}
+		return findings
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package security
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/dwarf"
+	"github.com/dotandev/hintents/internal/wasmaudit"
+)
+
+func TestEngine_WasmAudit(t *testing.T) {
+	audit := &wasmaudit.Report{Issues: []wasmaudit.Issue{
+		{
+			Kind: wasmaudit.KindMissingAuth, Export: "set_admin", Func: 7, Function: "contract::set_admin",
+			Offset: 0x1a2f, Detail: "set_admin writes contract storage",
+			Source: &dwarf.SourceLocation{File: "src/lib.rs", Line: 42},
+		},
+		{Kind: wasmaudit.KindStorageLoop, Export: "sum", Func: 9, Function: "func[9]", Offset: 0x2000, Detail: "loop"},
+	}}
+
+	engine, err := NewEngine(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := engine.Analyze(&Input{WasmAudit: audit})
+	if len(report.Findings) != 2 {
+		t.Fatalf("Findings = %+v, want two", report.Findings)
+	}
+	auth, loop := report.Findings[0], report.Findings[1]
+	if auth.RuleID != RuleWasmMissingAuth || auth.Severity != SeverityHigh || auth.File != "src/lib.rs" || auth.Line != 42 {
+		t.Errorf("auth finding = %+v", auth)
+	}
+	if auth.Location != "func[7]@0x1a2f" || auth.Function != "contract::set_admin" {
+		t.Errorf("auth finding location = %q, function = %q", auth.Location, auth.Function)
+	}
+	if loop.RuleID != RuleWasmStorageLoop || loop.File != "" {
+		t.Errorf("loop finding = %+v", loop)
+	}
+
+	data, err := MarshalSARIF(report, engine.Rules(), SARIFOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				Locations []struct {
+					PhysicalLocation *struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+					LogicalLocations []struct {
+						Name string `json:"name"`
+						Kind string `json:"kind"`
+					} `json:"logicalLocations"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	loc := log.Runs[0].Results[0].Locations[0]
+	if loc.PhysicalLocation == nil || loc.PhysicalLocation.ArtifactLocation.URI != "src/lib.rs" || loc.PhysicalLocation.Region.StartLine != 42 {
+		t.Errorf("physical location = %+v, want src/lib.rs:42", loc.PhysicalLocation)
+	}
+	if len(loc.LogicalLocations) != 1 || loc.LogicalLocations[0].Kind != "function" {
+		t.Errorf("logical locations = %+v", loc.LogicalLocations)
+	}
+	if log.Runs[0].Results[1].Locations[0].PhysicalLocation != nil {
+		t.Error("finding without source has a physical location")
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// balanceMeta returns the meta of a host function invocation that moved a
+// token balance from before to after without emitting any event.
+func balanceMeta(t *testing.T, before, after int64) string {
+	t.Helper()
+	var token xdr.ContractId
+	token[0] = 0xee
+	holder := xdr.MustAddress("GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7")
+
+	entry := func(amount int64) *xdr.LedgerEntry {
+		sym := xdr.ScSymbol("Balance")
+		addr := xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &holder}
+		key := xdr.ScVec{{Type: xdr.ScValTypeScvSymbol, Sym: &sym}, {Type: xdr.ScValTypeScvAddress, Address: &addr}}
+		keyPtr := &key
+		val := xdr.Int128Parts{Lo: xdr.Uint64(amount)}
+		return &xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeContractData,
+			ContractData: &xdr.ContractDataEntry{
+				Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &token},
+				Key:        xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: &keyPtr},
+				Durability: xdr.ContractDataDurabilityPersistent,
+				Val:        xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &val},
+			},
+		}}
+	}
+
+	results := []xdr.OperationResult{}
+	meta := xdr.TransactionResultMeta{
+		Result: xdr.TransactionResultPair{Result: xdr.TransactionResult{
+			Result: xdr.TransactionResultResult{Code: xdr.TransactionResultCodeTxSuccess, Results: &results},
+		}},
+		TxApplyProcessing: xdr.TransactionMeta{V: 3, V3: &xdr.TransactionMetaV3{
+			Operations: []xdr.OperationMeta{{Changes: xdr.LedgerEntryChanges{
+				{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: entry(before)},
+				{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: entry(after)},
+			}}},
+			SorobanMeta: &xdr.SorobanTransactionMeta{ReturnValue: xdr.ScVal{Type: xdr.ScValTypeScvVoid}},
+		}},
+	}
+	encoded, err := xdr.MarshalBase64(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+func TestTokenBalanceMismatch(t *testing.T) {
+	rule := RuleByID(RuleTokenBalanceMismatch)
+
+	findings := rule.Check(&Input{ResultMetaXdr: balanceMeta(t, 5, 1005)})
+	if len(findings) != 1 {
+		t.Fatalf("findings = %+v, want one", findings)
+	}
+	f := findings[0]
+	if f.Title != "Unreported Token Balance Change" || f.Type != FindingVerifiedRisk || !strings.HasPrefix(f.Contract, "C") {
+		t.Errorf("finding = %+v", f)
+	}
+	if !strings.Contains(f.Description, "changed by +1000 with no event") {
+		t.Errorf("description = %q", f.Description)
+	}
+
+	if findings := rule.Check(&Input{ResultMetaXdr: balanceMeta(t, 5, 5)}); len(findings) != 0 {
+		t.Errorf("unchanged balance: findings = %+v", findings)
+	}
+	if findings := rule.Check(&Input{}); len(findings) != 0 {
+		t.Errorf("no meta: findings = %+v", findings)
+	}
+}
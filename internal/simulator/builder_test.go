@@ -92,35 +92,6 @@ func TestSimulationRequestBuilder_WithLedgerEntry(t *testing.T) {
 	}
 }
 
-func TestSimulationRequestBuilder_WithRestorePreamble(t *testing.T) {
-	builder := NewSimulationRequestBuilder()
-
-	preamble := map[string]interface{}{
-		"foo":   "bar",
-		"count": 42,
-	}
-
-	req, err := builder.
-		WithEnvelopeXDR("envelope").
-		WithResultMetaXDR("result").
-		WithRestorePreamble(preamble).
-		Build()
-
-	if err != nil {
-		t.Fatalf("expected no error, got: %v", err)
-	}
-
-	if req.RestorePreamble == nil {
-		t.Fatalf("expected RestorePreamble to be set")
-	}
-	if req.RestorePreamble["foo"] != "bar" {
-		t.Errorf("expected RestorePreamble['foo'] to be 'bar', got: %v", req.RestorePreamble["foo"])
-	}
-	if req.RestorePreamble["count"] != 42 {
-		t.Errorf("expected RestorePreamble['count'] to be 42, got: %v", req.RestorePreamble["count"])
-	}
-}
-
 func TestSimulationRequestBuilder_WithLedgerEntries(t *testing.T) {
 	builder := NewSimulationRequestBuilder()
 
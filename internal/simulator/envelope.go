@@ -0,0 +1,36 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// SorobanData returns the SorobanTransactionData of a transaction envelope,
+// looking through fee bumps, or nil when the transaction has none.
+func SorobanData(env xdr.TransactionEnvelope) *xdr.SorobanTransactionData {
+	switch {
+	case env.IsFeeBump():
+		return env.FeeBump.Tx.InnerTx.V1.Tx.Ext.SorobanData
+	case env.V1 != nil:
+		return env.V1.Tx.Ext.SorobanData
+	}
+	return nil
+}
+
+// DecodeSorobanData decodes a base64 transaction envelope and returns its
+// SorobanTransactionData, failing when the transaction has none.
+func DecodeSorobanData(envelopeXdr string) (*xdr.SorobanTransactionData, error) {
+	var env xdr.TransactionEnvelope
+	if err := xdr.SafeUnmarshalBase64(envelopeXdr, &env); err != nil {
+		return nil, fmt.Errorf("decode envelope: %w", err)
+	}
+	data := SorobanData(env)
+	if data == nil {
+		return nil, fmt.Errorf("transaction has no SorobanTransactionData")
+	}
+	return data, nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package simulator
+
+import (
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+func TestSorobanData_FeeBump(t *testing.T) {
+	data := &xdr.SorobanTransactionData{ResourceFee: 42}
+	inner := xdr.TransactionV1Envelope{Tx: xdr.Transaction{
+		SourceAccount: xdr.MustMuxedAddress("GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7"),
+		Ext:           xdr.TransactionExt{V: 1, SorobanData: data},
+	}}
+	env := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTxFeeBump,
+		FeeBump: &xdr.FeeBumpTransactionEnvelope{Tx: xdr.FeeBumpTransaction{
+			FeeSource: xdr.MustMuxedAddress("GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7"),
+			InnerTx:   xdr.FeeBumpTransactionInnerTx{Type: xdr.EnvelopeTypeEnvelopeTypeTx, V1: &inner},
+		}},
+	}
+	if got := SorobanData(env); got != data {
+		t.Fatalf("SorobanData() = %v, want the inner transaction's data", got)
+	}
+
+	b64, err := xdr.MarshalBase64(xdr.TransactionEnvelope{Type: xdr.EnvelopeTypeEnvelopeTypeTx, V1: &xdr.TransactionV1Envelope{
+		Tx: xdr.Transaction{SourceAccount: inner.Tx.SourceAccount},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeSorobanData(b64); err == nil {
+		t.Error("expected an error for a transaction without Soroban data")
+	}
+}
@@ -4,6 +4,7 @@
 package simulator
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -190,7 +191,7 @@ func TestGasEstimation_ThresholdHelpers(t *testing.T) {
 // ─── EstimateGas (with MockRunner) ───────────────────────────────────────────
 
 func TestEstimateGas_WithMockRunner(t *testing.T) {
-	mock := NewMockRunner(func(req *SimulationRequest) (*SimulationResponse, error) {
+	mock := NewMockRunner(func(ctx context.Context, req *SimulationRequest) (*SimulationResponse, error) {
 		return &SimulationResponse{
 			Status: "success",
 			BudgetUsage: &BudgetUsage{
@@ -245,7 +246,7 @@ func TestEstimateGas_NilRequest(t *testing.T) {
 }
 
 func TestEstimateGas_RunnerError(t *testing.T) {
-	mock := NewMockRunner(func(req *SimulationRequest) (*SimulationResponse, error) {
+	mock := NewMockRunner(func(ctx context.Context, req *SimulationRequest) (*SimulationResponse, error) {
 		return nil, fmt.Errorf("binary not found")
 	})
 
@@ -390,3 +391,36 @@ func TestBudgetUsage_ToGasEstimation(t *testing.T) {
 		t.Errorf("fee upper bound should be >= lower bound")
 	}
 }
+
+// ─── EstimateRentFee ─────────────────────────────────────────────────────────
+
+func TestEstimateRentFee(t *testing.T) {
+	// 1 KiB for PersistentRentRateDenominator ledgers costs the write fee.
+	if got := EstimateRentFee(1024, uint32(PersistentRentRateDenominator), true); got != RentFeePer1KBStroops {
+		t.Errorf("persistent rent = %d, want %d", got, RentFeePer1KBStroops)
+	}
+	if got := EstimateRentFee(1024, uint32(PersistentRentRateDenominator), false); got != RentFeePer1KBStroops/2 {
+		t.Errorf("temporary rent = %d, want %d", got, RentFeePer1KBStroops/2)
+	}
+	if got := EstimateRentFee(1, 1, true); got != 1 {
+		t.Errorf("rent is rounded up: got %d", got)
+	}
+	if got := EstimateRentFee(1024, 0, true); got != 0 {
+		t.Errorf("no ledgers: got %d", got)
+	}
+}
+
+func TestEstimateResourceFee(t *testing.T) {
+	if got := EstimateResourceFee(ResourceUsage{}); got != 0 {
+		t.Errorf("empty usage: got %d, want 0", got)
+	}
+	u := ResourceUsage{Instructions: 1_000_000, DiskReadBytes: 1024, WriteBytes: 2048, ReadEntries: 2, WriteEntries: 1}
+	want := int64(2_500 + 2*6_250 + 10_000 + 1_786 + 2*11_800)
+	if got := EstimateResourceFee(u); got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+	u.Instructions++
+	if got := EstimateResourceFee(u); got != want+1 {
+		t.Errorf("partial increment should round up: got %d, want %d", got, want+1)
+	}
+}
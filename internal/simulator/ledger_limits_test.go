@@ -59,10 +59,10 @@ func TestCheckLedgerEntriesSize_OneByteOver(t *testing.T) {
 }
 
 func TestCheckLedgerEntriesSize_MultipleEntriesOverLimit(t *testing.T) {
-	// 10 entries, each key=64KiB + value=128KiB → 10 * 192 KiB = 1920 KiB > 1 MiB
+	// 10 distinct entries, each key≈64KiB + value=128KiB → 10 * 192 KiB = 1920 KiB > 1 MiB
 	entries := make(map[string]string, 10)
 	for i := 0; i < 10; i++ {
-		entries[makeEntry(64*1024)] = makeEntry(128 * 1024)
+		entries[makeEntry(64*1024+i)] = makeEntry(128 * 1024)
 	}
 	warning := CheckLedgerEntriesSize(entries)
 	if warning == nil {
@@ -0,0 +1,84 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package simulator
+
+import (
+	"fmt"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// MetaParts is a TransactionMeta with the differences between its versions
+// flattened out.
+type MetaParts struct {
+	// Before and After are the transaction-level changes applied around the
+	// operations. Version 1 only has Before.
+	Before, After xdr.LedgerEntryChanges
+	// Operations are the changes of each operation.
+	Operations []xdr.LedgerEntryChanges
+	// Soroban is set for the meta of a host function invocation.
+	Soroban bool
+	// Events are the contract events: the Soroban meta's in version 3, those
+	// of every operation in version 4. Earlier versions have none.
+	Events           []xdr.ContractEvent
+	DiagnosticEvents []xdr.DiagnosticEvent
+	// ReturnValue is the host function's return value, if any.
+	ReturnValue *xdr.ScVal
+}
+
+// SplitMeta flattens a TransactionMeta of any version up to 4.
+func SplitMeta(tm xdr.TransactionMeta) (MetaParts, error) {
+	var m MetaParts
+	switch tm.V {
+	case 0:
+		if tm.Operations != nil {
+			for _, op := range *tm.Operations {
+				m.Operations = append(m.Operations, op.Changes)
+			}
+		}
+	case 1:
+		if tm.V1 != nil {
+			m.Before = tm.V1.TxChanges
+			for _, op := range tm.V1.Operations {
+				m.Operations = append(m.Operations, op.Changes)
+			}
+		}
+	case 2:
+		if tm.V2 != nil {
+			m.Before, m.After = tm.V2.TxChangesBefore, tm.V2.TxChangesAfter
+			for _, op := range tm.V2.Operations {
+				m.Operations = append(m.Operations, op.Changes)
+			}
+		}
+	case 3:
+		if tm.V3 != nil {
+			m.Before, m.After = tm.V3.TxChangesBefore, tm.V3.TxChangesAfter
+			for _, op := range tm.V3.Operations {
+				m.Operations = append(m.Operations, op.Changes)
+			}
+			if sm := tm.V3.SorobanMeta; sm != nil {
+				m.Soroban = true
+				m.Events = sm.Events
+				m.DiagnosticEvents = sm.DiagnosticEvents
+				m.ReturnValue = &sm.ReturnValue
+			}
+		}
+	case 4:
+		if tm.V4 != nil {
+			m.Before, m.After = tm.V4.TxChangesBefore, tm.V4.TxChangesAfter
+			for _, op := range tm.V4.Operations {
+				m.Operations = append(m.Operations, op.Changes)
+				m.Events = append(m.Events, op.Events...)
+			}
+			m.DiagnosticEvents = tm.V4.DiagnosticEvents
+			if sm := tm.V4.SorobanMeta; sm != nil {
+				m.Soroban = true
+				m.ReturnValue = sm.ReturnValue
+			}
+		}
+	default:
+		return m, fmt.Errorf("unsupported TransactionMeta version: %d", tm.V)
+	}
+	return m, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package simulator
+
+import (
+	"testing"
+
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+func TestSplitMeta(t *testing.T) {
+	change := func(seq uint32) xdr.LedgerEntryChanges {
+		return xdr.LedgerEntryChanges{{
+			Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+			State: &xdr.LedgerEntry{LastModifiedLedgerSeq: xdr.Uint32(seq)},
+		}}
+	}
+	ret := xdr.ScVal{Type: xdr.ScValTypeScvVoid}
+	event := xdr.ContractEvent{Type: xdr.ContractEventTypeContract}
+
+	v4 := xdr.TransactionMeta{V: 4, V4: &xdr.TransactionMetaV4{
+		TxChangesBefore: change(1),
+		Operations: []xdr.OperationMetaV2{
+			{Changes: change(2), Events: []xdr.ContractEvent{event}},
+			{Changes: change(3), Events: []xdr.ContractEvent{event}},
+		},
+		TxChangesAfter:   change(4),
+		SorobanMeta:      &xdr.SorobanTransactionMetaV2{ReturnValue: &ret},
+		DiagnosticEvents: []xdr.DiagnosticEvent{{Event: event}},
+	}}
+	m, err := SplitMeta(v4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Before) != 1 || len(m.Operations) != 2 || len(m.After) != 1 || m.Operations[1][0].State.LastModifiedLedgerSeq != 3 {
+		t.Errorf("unexpected changes: %+v", m)
+	}
+	if !m.Soroban || m.ReturnValue != &ret || len(m.Events) != 2 || len(m.DiagnosticEvents) != 1 {
+		t.Errorf("unexpected Soroban meta: %+v", m)
+	}
+
+	v1 := xdr.TransactionMeta{V: 1, V1: &xdr.TransactionMetaV1{
+		TxChanges:  change(1),
+		Operations: []xdr.OperationMeta{{Changes: change(2)}},
+	}}
+	m, err = SplitMeta(v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Before) != 1 || len(m.Operations) != 1 || m.After != nil || m.Soroban {
+		t.Errorf("unexpected V1 parts: %+v", m)
+	}
+
+	if _, err := SplitMeta(xdr.TransactionMeta{V: 5}); err == nil {
+		t.Error("expected an error for an unknown meta version")
+	}
+}
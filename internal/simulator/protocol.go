@@ -77,6 +77,23 @@ var protocols = map[uint32]*Protocol{
 			},
 		},
 	},
+	// 23 is available for explicit overrides and upgrade impact analysis;
+	// defaultVersion stays on 22 until it is the network-wide version. Only
+	// the features introduced by the protocol 23 CAPs are listed: automatic
+	// restore of archived entries (CAP-0066) and parallel transaction
+	// execution (CAP-0063). Network limits and cost calibration are set by
+	// validator votes rather than the protocol, so none are assumed here.
+	23: {
+		Version: 23,
+		Name:    "Soroban Protocol 23",
+		Features: map[string]interface{}{
+			"supported_opcodes":  []string{"invoke_contract", "create_contract", "extend_contract", "upgrade_contract"},
+			"enhanced_metering":  true,
+			"optimized_storage":  true,
+			"automatic_restore":  true,
+			"parallel_execution": true,
+		},
+	},
 }
 
 var defaultVersion uint32 = 22
@@ -0,0 +1,183 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ProtocolImpactCase is one transaction to replay under both protocols.
+type ProtocolImpactCase struct {
+	TxHash  string
+	Request *SimulationRequest
+}
+
+// ProtocolRun is the outcome of replaying a transaction under one protocol.
+type ProtocolRun struct {
+	Status          string   `json:"status"`
+	Error           string   `json:"error,omitempty"`
+	CPUInstructions uint64   `json:"cpu_instructions"`
+	MemoryBytes     uint64   `json:"memory_bytes"`
+	Events          []string `json:"events,omitempty"`
+}
+
+// ProtocolImpactResult compares one transaction across the two protocols.
+type ProtocolImpactResult struct {
+	TxHash        string      `json:"tx_hash"`
+	From          ProtocolRun `json:"from"`
+	To            ProtocolRun `json:"to"`
+	StatusChanged bool        `json:"status_changed"`
+	EventsChanged bool        `json:"events_changed"`
+	CPUDelta      int64       `json:"cpu_delta"`
+	MemoryDelta   int64       `json:"memory_delta"`
+	// Error is set when either replay could not run at all.
+	Error string `json:"error,omitempty"`
+}
+
+// ProtocolImpactSummary aggregates a ProtocolImpactReport.
+type ProtocolImpactSummary struct {
+	Total            int     `json:"total"`
+	Errors           int     `json:"errors"`
+	StatusChanged    int     `json:"status_changed"`
+	EventsChanged    int     `json:"events_changed"`
+	TotalCPUDelta    int64   `json:"total_cpu_delta"`
+	TotalMemoryDelta int64   `json:"total_memory_delta"`
+	CPUDeltaPercent  float64 `json:"cpu_delta_percent"`
+	MemDeltaPercent  float64 `json:"memory_delta_percent"`
+}
+
+// ProtocolImpactReport is the result of replaying a batch of transactions
+// under two protocol versions.
+type ProtocolImpactReport struct {
+	FromVersion uint32                 `json:"from_version"`
+	ToVersion   uint32                 `json:"to_version"`
+	Results     []ProtocolImpactResult `json:"results"`
+	Summary     ProtocolImpactSummary  `json:"summary"`
+}
+
+// AnalyzeProtocolImpact replays every case under protocol versions from and
+// to and reports differences in status, budget usage and emitted events.
+// Cases that fail to replay are recorded in the report rather than aborting
+// the batch; only context cancellation stops it early.
+func AnalyzeProtocolImpact(
+	ctx context.Context,
+	runner RunnerInterface,
+	from, to uint32,
+	cases []ProtocolImpactCase,
+) (*ProtocolImpactReport, error) {
+	if err := Validate(from); err != nil {
+		return nil, err
+	}
+	if err := Validate(to); err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, fmt.Errorf("from and to protocol versions must differ")
+	}
+
+	report := &ProtocolImpactReport{
+		FromVersion: from,
+		ToVersion:   to,
+		Results:     make([]ProtocolImpactResult, 0, len(cases)),
+	}
+
+	var fromCPU, fromMem uint64
+	for _, c := range cases {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		result := ProtocolImpactResult{TxHash: c.TxHash}
+		fromResp, err := runWithProtocol(ctx, runner, c.Request, from)
+		if err == nil {
+			var toResp *SimulationResponse
+			toResp, err = runWithProtocol(ctx, runner, c.Request, to)
+			if err == nil {
+				result.From = protocolRunFrom(fromResp)
+				result.To = protocolRunFrom(toResp)
+				result.StatusChanged = result.From.Status != result.To.Status
+				result.EventsChanged = !slices.Equal(result.From.Events, result.To.Events)
+				result.CPUDelta = int64(result.To.CPUInstructions) - int64(result.From.CPUInstructions)
+				result.MemoryDelta = int64(result.To.MemoryBytes) - int64(result.From.MemoryBytes)
+			}
+		}
+
+		s := &report.Summary
+		s.Total++
+		if err != nil {
+			result.Error = err.Error()
+			s.Errors++
+		} else {
+			if result.StatusChanged {
+				s.StatusChanged++
+			}
+			if result.EventsChanged {
+				s.EventsChanged++
+			}
+			s.TotalCPUDelta += result.CPUDelta
+			s.TotalMemoryDelta += result.MemoryDelta
+			fromCPU += result.From.CPUInstructions
+			fromMem += result.From.MemoryBytes
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	if fromCPU > 0 {
+		report.Summary.CPUDeltaPercent = float64(report.Summary.TotalCPUDelta) / float64(fromCPU) * 100
+	}
+	if fromMem > 0 {
+		report.Summary.MemDeltaPercent = float64(report.Summary.TotalMemoryDelta) / float64(fromMem) * 100
+	}
+	return report, nil
+}
+
+// Changed reports whether the transaction behaves differently under the new
+// protocol in a way that would be visible to users.
+func (r ProtocolImpactResult) Changed() bool {
+	return r.StatusChanged || r.EventsChanged
+}
+
+func runWithProtocol(ctx context.Context, runner RunnerInterface, base *SimulationRequest, version uint32) (*SimulationResponse, error) {
+	req := *base
+	req.ProtocolVersion = &version
+	// The runner writes protocol limits into CustomAuthCfg; keep the two
+	// runs from sharing that map.
+	req.CustomAuthCfg = maps.Clone(base.CustomAuthCfg)
+	return runner.Run(ctx, &req)
+}
+
+func protocolRunFrom(resp *SimulationResponse) ProtocolRun {
+	run := ProtocolRun{Status: resp.Status, Error: resp.Error, Events: eventSignatures(resp)}
+	if resp.BudgetUsage != nil {
+		run.CPUInstructions = resp.BudgetUsage.CPUInstructions
+		run.MemoryBytes = resp.BudgetUsage.MemoryBytes
+	}
+	return run
+}
+
+// eventSignatures returns a comparable form of the emitted events, preferring
+// contract event XDR, then raw event strings, then structured diagnostics.
+func eventSignatures(resp *SimulationResponse) []string {
+	if len(resp.ContractEvents) > 0 {
+		return resp.ContractEvents
+	}
+	if len(resp.Events) > 0 {
+		return resp.Events
+	}
+	var out []string
+	for _, e := range resp.DiagnosticEvents {
+		if e.EventType != "contract" {
+			continue
+		}
+		id := ""
+		if e.ContractID != nil {
+			id = *e.ContractID
+		}
+		out = append(out, fmt.Sprintf("%s %v %s", id, e.Topics, e.Data))
+	}
+	return out
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeProtocolImpact(t *testing.T) {
+	runner := NewMockRunner(func(ctx context.Context, req *SimulationRequest) (*SimulationResponse, error) {
+		v := *req.ProtocolVersion
+		switch req.EnvelopeXdr {
+		case "same":
+			return &SimulationResponse{Status: "success", Events: []string{"e1"}, BudgetUsage: &BudgetUsage{CPUInstructions: 100, MemoryBytes: 50}}, nil
+		case "cheaper":
+			cpu := uint64(200)
+			if v == 22 {
+				cpu = 150
+			}
+			return &SimulationResponse{Status: "success", BudgetUsage: &BudgetUsage{CPUInstructions: cpu, MemoryBytes: 50}}, nil
+		case "breaks":
+			if v == 22 {
+				return &SimulationResponse{Status: "error", Error: "trap"}, nil
+			}
+			return &SimulationResponse{Status: "success", Events: []string{"e1"}}, nil
+		default:
+			return nil, fmt.Errorf("simulator crashed")
+		}
+	})
+
+	cases := []ProtocolImpactCase{
+		{TxHash: "a", Request: &SimulationRequest{EnvelopeXdr: "same"}},
+		{TxHash: "b", Request: &SimulationRequest{EnvelopeXdr: "cheaper"}},
+		{TxHash: "c", Request: &SimulationRequest{EnvelopeXdr: "breaks"}},
+		{TxHash: "d", Request: &SimulationRequest{EnvelopeXdr: "crash"}},
+	}
+	report, err := AnalyzeProtocolImpact(context.Background(), runner, 21, 22, cases)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 4)
+
+	assert.False(t, report.Results[0].Changed())
+	assert.Zero(t, report.Results[0].CPUDelta)
+
+	assert.Equal(t, int64(-50), report.Results[1].CPUDelta)
+	assert.False(t, report.Results[1].Changed())
+
+	assert.True(t, report.Results[2].StatusChanged)
+	assert.True(t, report.Results[2].EventsChanged)
+
+	assert.Contains(t, report.Results[3].Error, "crashed")
+
+	s := report.Summary
+	assert.Equal(t, 4, s.Total)
+	assert.Equal(t, 1, s.Errors)
+	assert.Equal(t, 1, s.StatusChanged)
+	assert.Equal(t, int64(-50), s.TotalCPUDelta)
+	assert.InDelta(t, -50.0/300.0*100, s.CPUDeltaPercent, 0.001)
+}
+
+func TestAnalyzeProtocolImpact_DoesNotMutateRequest(t *testing.T) {
+	req := &SimulationRequest{CustomAuthCfg: map[string]interface{}{"k": 1}}
+	runner := NewMockRunner(func(ctx context.Context, r *SimulationRequest) (*SimulationResponse, error) {
+		r.CustomAuthCfg["protocol_limits"] = *r.ProtocolVersion
+		return &SimulationResponse{Status: "success"}, nil
+	})
+	_, err := AnalyzeProtocolImpact(context.Background(), runner, 21, 22, []ProtocolImpactCase{{TxHash: "a", Request: req}})
+	require.NoError(t, err)
+	assert.Nil(t, req.ProtocolVersion)
+	assert.NotContains(t, req.CustomAuthCfg, "protocol_limits")
+}
+
+func TestAnalyzeProtocolImpact_InvalidVersions(t *testing.T) {
+	runner := NewDefaultMockRunner()
+	_, err := AnalyzeProtocolImpact(context.Background(), runner, 22, 99, nil)
+	assert.Error(t, err)
+	_, err = AnalyzeProtocolImpact(context.Background(), runner, 22, 22, nil)
+	assert.Error(t, err)
+}
@@ -34,12 +34,35 @@ type RegressionTestSuite struct {
 	mu          sync.Mutex
 }
 
+// ReplayVerdict is the outcome of a ReplayVerifier.
+type ReplayVerdict struct {
+	Passed bool
+	// Reason is a short description of the divergence when not Passed.
+	Reason string
+	// StatusMatch reports whether the replay succeeded or trapped as the
+	// transaction did on chain.
+	StatusMatch bool
+	// EventsMatch reports whether the replay emitted the on-chain contract
+	// events; it is true when the simulator does not report them.
+	EventsMatch bool
+	// ExpectedEvents is the number of contract events recorded on chain.
+	ExpectedEvents int
+}
+
+// ReplayVerifier decides whether a simulation reproduced the on-chain
+// execution of tx, checking each dimension on its own.
+type ReplayVerifier func(tx *rpc.TransactionResponse, sim *SimulationResponse) (*ReplayVerdict, error)
+
 // RegressionHarness manages protocol regression testing against historic transactions
 type RegressionHarness struct {
 	Runner     RunnerInterface
 	RPCClient  *rpc.Client
 	MaxWorkers int
 	Verbose    bool
+
+	// Verifier, when set, replaces the status-only pass/fail decision with a
+	// full comparison against the on-chain result.
+	Verifier ReplayVerifier
 }
 
 // NewRegressionHarness creates a new regression test harness
@@ -196,6 +219,26 @@ func (h *RegressionHarness) testTransaction(
 	result.ExpectedCount = result.EventCount // For now, assume match if simulation succeeded
 
 	// Verify results
+	if h.Verifier != nil {
+		verdict, err := h.Verifier(resp, simResp)
+		if err != nil {
+			result.ErrorMessage = fmt.Sprintf("verification failed: %v", err)
+			return result
+		}
+		result.TrapsMatch = verdict.StatusMatch
+		result.EventCountMatch = verdict.EventsMatch
+		result.ExpectedCount = verdict.ExpectedEvents
+		if simResp.ContractEvents != nil {
+			result.EventCount = len(simResp.ContractEvents)
+		}
+		result.Status = "pass"
+		if !verdict.Passed {
+			result.Status = "fail"
+			result.ErrorMessage = verdict.Reason
+		}
+		return result
+	}
+
 	if simResp.Status == "success" {
 		result.Status = "pass"
 		result.TrapsMatch = true
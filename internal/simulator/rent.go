@@ -0,0 +1,44 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package simulator
+
+// ─── Rent estimation constants ────────────────────────────────────────────────
+// Like the fee heuristics in gas.go, these approximate the network's state
+// archival settings until they are read from its ConfigSetting entries.
+
+const (
+	// RentFeePer1KBStroops is the write fee for 1 KiB of ledger state, on
+	// which rent is based.
+	RentFeePer1KBStroops int64 = 11_800
+
+	// PersistentRentRateDenominator divides the write fee into the rent of
+	// one ledger of persistent state.
+	PersistentRentRateDenominator int64 = 2_103
+
+	// TemporaryRentRateDenominator divides the write fee into the rent of
+	// one ledger of temporary state.
+	TemporaryRentRateDenominator int64 = 4_206
+
+	// MinPersistentTTLLedgers is the lifetime a restored persistent entry is
+	// given, and paid for, by RestoreFootprint (about 120 days).
+	MinPersistentTTLLedgers uint32 = 2_073_600
+
+	// LedgerCloseSeconds is the target time between ledgers.
+	LedgerCloseSeconds = 5
+)
+
+// EstimateRentFee estimates the rent in stroops for keeping an entry of
+// sizeBytes alive for another ledgers ledgers.
+func EstimateRentFee(sizeBytes int, ledgers uint32, persistent bool) int64 {
+	if sizeBytes <= 0 || ledgers == 0 {
+		return 0
+	}
+	denominator := TemporaryRentRateDenominator
+	if persistent {
+		denominator = PersistentRentRateDenominator
+	}
+	num := int64(sizeBytes) * RentFeePer1KBStroops * int64(ledgers)
+	den := 1024 * denominator
+	return (num + den - 1) / den
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package simulator
+
+// ─── Resource fee constants ───────────────────────────────────────────────────
+// Approximations of the network's Soroban resource fee settings, used to
+// price declared SorobanResources against each other. Like the rent settings
+// in rent.go they are not read from the network's ConfigSetting entries.
+
+const (
+	// InstructionsFeeIncrement is the number of instructions priced at
+	// FeePerInstructionsIncrementStroops.
+	InstructionsFeeIncrement int64 = 10_000
+
+	// FeePerInstructionsIncrementStroops is the fee for InstructionsFeeIncrement
+	// instructions.
+	FeePerInstructionsIncrementStroops int64 = 25
+
+	// FeePerDiskReadEntryStroops is the fee for every entry in the footprint.
+	FeePerDiskReadEntryStroops int64 = 6_250
+
+	// FeePerWriteEntryStroops is the fee for every read-write entry.
+	FeePerWriteEntryStroops int64 = 10_000
+
+	// FeePerDiskRead1KBStroops is the fee for 1 KiB of disk reads.
+	FeePerDiskRead1KBStroops int64 = 1_786
+)
+
+// ResourceUsage are the resources a Soroban transaction declares.
+type ResourceUsage struct {
+	Instructions  uint32
+	DiskReadBytes uint32
+	WriteBytes    uint32
+	ReadEntries   int
+	WriteEntries  int
+}
+
+// EstimateResourceFee estimates the non-refundable resource fee in stroops of
+// declaring u, excluding rent and the transaction size fee.
+func EstimateResourceFee(u ResourceUsage) int64 {
+	return ceilDiv(int64(u.Instructions)*FeePerInstructionsIncrementStroops, InstructionsFeeIncrement) +
+		int64(u.ReadEntries)*FeePerDiskReadEntryStroops +
+		int64(u.WriteEntries)*FeePerWriteEntryStroops +
+		ceilDiv(int64(u.DiskReadBytes)*FeePerDiskRead1KBStroops, 1024) +
+		ceilDiv(int64(u.WriteBytes)*RentFeePer1KBStroops, 1024)
+}
+
+func ceilDiv(num, den int64) int64 {
+	return (num + den - 1) / den
+}
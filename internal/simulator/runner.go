@@ -286,8 +286,10 @@ func (r *Runner) Run(ctx context.Context, req *SimulationRequest) (*SimulationRe
 	}
 
 	// If the simulator returned a logical error inside the response payload,
-	// classify it into a unified ErstError before returning to the caller.
-	if resp.Error != "" {
+	// classify it into a unified ErstError before returning to the caller. A
+	// recording run keeps its response: the footprint up to the failure is
+	// what the caller asked for.
+	if resp.Error != "" && !(req.RecordFootprint && resp.Footprint != nil) {
 		classified := (&ipc.Error{Code: resp.ErrorCode, Message: resp.Error}).ToErstError()
 		logger.Logger.Error("Simulator returned error",
 			"code", classified.Code,
@@ -37,6 +37,15 @@ type SimulationRequest struct {
 	CustomAuthCfg       map[string]interface{} `json:"custom_auth_config,omitempty"`
 	ResourceCalibration *ResourceCalibration   `json:"resource_calibration,omitempty"`
 
+	// RecordFootprint runs the transaction with a recording footprint instead
+	// of enforcing the declared one; the keys it touches are reported in
+	// SimulationResponse.Footprint.
+	RecordFootprint bool `json:"record_footprint,omitempty"`
+
+	// NetworkPassphrase selects the network the transaction is run on; the
+	// simulator defaults to the public network.
+	NetworkPassphrase string `json:"network_passphrase,omitempty"`
+
 	// SandboxNativeTokenCapStroops, when set, enforces a hard cap on the sum of native
 	// (XLM) payment amounts in the envelope. Used in local/sandbox mode to simulate
 	// realistic economic constraints during integration tests. Exceeding the cap
@@ -97,6 +106,64 @@ type SimulationResponse struct {
 	StackTrace        *WasmStackTrace      `json:"stack_trace,omitempty"`      // Enhanced WASM stack trace on traps
 	SourceLocation    string               `json:"source_location,omitempty"`
 	WasmOffset        *uint64              `json:"wasm_offset,omitempty"`
+
+	// Replay outputs in XDR form. Simulators that report these allow the
+	// result to be checked field-by-field against the on-chain TransactionMeta.
+	ReturnValue    string              `json:"return_value,omitempty"`    // base64 ScVal
+	ContractEvents []string            `json:"contract_events,omitempty"` // base64 ContractEvent
+	LedgerChanges  []LedgerEntryChange `json:"ledger_changes,omitempty"`
+	// DiagnosticEventsXdr holds every event, diagnostic ones included, as
+	// base64 DiagnosticEvent; Events only has their debug rendering.
+	DiagnosticEventsXdr []string `json:"diagnostic_events_xdr,omitempty"`
+
+	// Footprint is the footprint recorded when the request set RecordFootprint.
+	Footprint *RecordedFootprint `json:"footprint,omitempty"`
+
+	// InvocationBudgets snapshot the budget around every contract call and
+	// host function, in the order they started.
+	InvocationBudgets []InvocationBudget `json:"invocation_budgets,omitempty"`
+}
+
+// Invocation kinds reported in InvocationBudget.Kind.
+const (
+	InvocationContractCall = "contract_call"
+	InvocationHostFn       = "host_fn"
+)
+
+// InvocationBudget is the budget before and after one invocation. Depth is 1
+// for the calls made by the transaction itself; the CPU and memory consumed
+// by the invocation include everything it invoked.
+type InvocationBudget struct {
+	Kind       string `json:"kind"`
+	ContractID string `json:"contract_id,omitempty"`
+	Function   string `json:"function"`
+	Depth      int    `json:"depth"`
+	CPUStart   uint64 `json:"cpu_start"`
+	CPUEnd     uint64 `json:"cpu_end"`
+	MemStart   uint64 `json:"mem_start"`
+	MemEnd     uint64 `json:"mem_end"`
+}
+
+// RecordedFootprint lists the ledger keys (base64 LedgerKey) a recording run
+// read and wrote. Keys that were written are only listed in ReadWrite.
+type RecordedFootprint struct {
+	ReadOnly  []string `json:"read_only"`
+	ReadWrite []string `json:"read_write"`
+}
+
+// Ledger change types reported in LedgerEntryChange.Type.
+const (
+	LedgerChangeCreated  = "created"
+	LedgerChangeUpdated  = "updated"
+	LedgerChangeRemoved  = "removed"
+	LedgerChangeRestored = "restored"
+)
+
+// LedgerEntryChange is the net effect of a transaction on one ledger entry.
+type LedgerEntryChange struct {
+	KeyXdr   string `json:"key_xdr"`             // base64 LedgerKey
+	Type     string `json:"type"`                // created, updated, removed or restored
+	EntryXdr string `json:"entry_xdr,omitempty"` // base64 LedgerEntry after the change; empty for removals
 }
 
 type CategorizedEvent struct {
@@ -159,10 +159,11 @@ func (v *Validator) validateEnvelopeXdr(xdr string) error {
 	return nil
 }
 
-// validateResultMetaXdr validates the result meta XDR field
+// validateResultMetaXdr validates the result meta XDR field. It is empty for
+// transactions that are not on chain, e.g. dry runs.
 func (v *Validator) validateResultMetaXdr(xdr string) error {
 	if xdr == "" {
-		return &ValidationError{Field: "result_meta_xdr", Message: "cannot be empty", Code: "ERR_EMPTY_FIELD"}
+		return nil
 	}
 
 	if !isValidBase64(xdr) {
@@ -0,0 +1,216 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package sourcemap
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/dwarf"
+)
+
+// Location is a position in a contract's verified source code.
+type Location struct {
+	// Path is the file path relative to the repository root.
+	Path string
+	// LocalPath is the on-disk copy of the file, when the source has been
+	// written out with Materialize; empty otherwise.
+	LocalPath string
+	Line      int
+	Column    int
+	Function  string
+	// URL links to the line in the source repository.
+	URL string
+}
+
+// Link returns a URL for a line of a file in the source repository, pinned to
+// the verified commit when one is known. Returns "" for repositories that are
+// not hosted on GitHub.
+func (s *SourceCode) Link(file string, line int) string {
+	owner, repo, err := parseGitHubURL(s.Repository)
+	if err != nil {
+		return ""
+	}
+	ref := s.Commit
+	if ref == "" {
+		ref = "HEAD"
+	}
+	url := fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s", owner, repo, ref, file)
+	if line > 0 {
+		url += fmt.Sprintf("#L%d", line)
+	}
+	return url
+}
+
+// MatchFile maps a file path reported by DWARF or the simulator, which is
+// usually absolute on the machine that built the WASM, to a file in the
+// source. The file sharing the longest path suffix wins.
+func (s *SourceCode) MatchFile(file string) (string, bool) {
+	want := strings.Split(path.Clean(filepath.ToSlash(file)), "/")
+	best, bestLen := "", 0
+	for _, candidate := range s.sortedPaths() {
+		have := strings.Split(candidate, "/")
+		n := 0
+		for n < len(want) && n < len(have) && want[len(want)-1-n] == have[len(have)-1-n] {
+			n++
+		}
+		if n > bestLen {
+			best, bestLen = candidate, n
+		}
+	}
+	return best, bestLen > 0
+}
+
+// FindFunction finds where a Rust function is defined. Qualified names such
+// as "token::Contract::transfer" are matched on their last segment.
+func (s *SourceCode) FindFunction(name string) (Location, bool) {
+	if i := strings.LastIndex(name, "::"); i >= 0 {
+		name = name[i+2:]
+	}
+	if name == "" {
+		return Location{}, false
+	}
+	def := regexp.MustCompile(`^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+"[^"]*"\s+)?fn\s+` + regexp.QuoteMeta(name) + `\s*[<(]`)
+	for _, p := range s.sortedPaths() {
+		if !strings.HasSuffix(p, ".rs") {
+			continue
+		}
+		for i, line := range strings.Split(s.Files[p], "\n") {
+			if def.MatchString(line) {
+				col := strings.Index(line, "fn ") + 1
+				return Location{Path: p, Line: i + 1, Column: col, Function: name}, true
+			}
+		}
+	}
+	return Location{}, false
+}
+
+// sortedPaths returns the source file paths in a stable order, preferring
+// files under src/ and ranking tests last so lookups are deterministic.
+func (s *SourceCode) sortedPaths() []string {
+	paths := make([]string, 0, len(s.Files))
+	for p := range s.Files {
+		paths = append(paths, p)
+	}
+	rank := func(p string) int {
+		switch {
+		case strings.Contains(p, "/tests/") || strings.HasSuffix(p, "/test.rs") || strings.HasSuffix(p, "_test.rs"):
+			return 2
+		case strings.Contains("/"+p, "/src/"):
+			return 0
+		default:
+			return 1
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		if ri, rj := rank(paths[i]), rank(paths[j]); ri != rj {
+			return ri < rj
+		}
+		return paths[i] < paths[j]
+	})
+	return paths
+}
+
+// Materialize writes the source files under dir so tools that read from disk
+// can display them. Paths escaping dir are skipped.
+func (s *SourceCode) Materialize(dir string) error {
+	for p, content := range s.Files {
+		target := filepath.Join(dir, filepath.FromSlash(p))
+		rel, err := filepath.Rel(dir, target)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// Locator maps code in a deployed contract to its verified source. When the
+// deployed WASM still carries DWARF line tables, offsets are resolved exactly;
+// otherwise functions are located by their definitions in the source.
+type Locator struct {
+	source *SourceCode
+	root   string
+	parser *dwarf.Parser
+}
+
+// NewLocator creates a Locator for source. root is the directory the source
+// was materialized to, or "" if it only lives in memory. wasm is the deployed
+// bytecode, used for its debug info when present.
+func NewLocator(source *SourceCode, root string, wasm []byte) *Locator {
+	l := &Locator{source: source, root: root}
+	if len(wasm) > 0 {
+		if p, err := dwarf.NewParser(wasm); err == nil && p.HasDebugInfo() {
+			l.parser = p
+		}
+	}
+	return l
+}
+
+// Source returns the verified source the locator resolves against.
+func (l *Locator) Source() *SourceCode {
+	return l.source
+}
+
+// LocateFunction returns the definition of a function in the source.
+func (l *Locator) LocateFunction(name string) (Location, bool) {
+	if l.parser != nil {
+		if subs, err := l.parser.GetSubprograms(); err == nil {
+			for _, sp := range subs {
+				if sp.Name != name && sp.DemangledName != name {
+					continue
+				}
+				if loc, ok := l.LocateFile(sp.File, sp.Line, 0); ok {
+					loc.Function = name
+					return loc, true
+				}
+			}
+		}
+	}
+	loc, ok := l.source.FindFunction(name)
+	if !ok {
+		return Location{}, false
+	}
+	return l.finish(loc), true
+}
+
+// LocateOffset resolves a WASM code offset through the deployed WASM's line
+// tables. It fails when the WASM was stripped of debug info.
+func (l *Locator) LocateOffset(offset uint64) (Location, bool) {
+	if l.parser == nil {
+		return Location{}, false
+	}
+	sl, err := l.parser.GetSourceLocation(offset)
+	if err != nil || sl == nil {
+		return Location{}, false
+	}
+	return l.LocateFile(sl.File, sl.Line, sl.Column)
+}
+
+// LocateFile maps a build-machine file path and line to the source.
+func (l *Locator) LocateFile(file string, line, column int) (Location, bool) {
+	p, ok := l.source.MatchFile(file)
+	if !ok {
+		return Location{}, false
+	}
+	return l.finish(Location{Path: p, Line: line, Column: column}), true
+}
+
+func (l *Locator) finish(loc Location) Location {
+	if l.root != "" {
+		loc.LocalPath = filepath.Join(l.root, filepath.FromSlash(loc.Path))
+	}
+	loc.URL = l.source.Link(loc.Path, loc.Line)
+	return loc
+}
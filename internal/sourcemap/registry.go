@@ -46,6 +46,18 @@ type ContractInfo struct {
 	Verified bool `json:"verified"`
 	// Creator is the account that deployed the contract.
 	Creator string `json:"creator"`
+	// Commit is the repository commit the WASM was verified against.
+	Commit string `json:"commit"`
+	// Validation carries the verification details when the registry reports
+	// them as a nested object rather than top-level fields.
+	Validation *ContractValidation `json:"validation,omitempty"`
+}
+
+// ContractValidation is the source verification record for a contract.
+type ContractValidation struct {
+	Status     string `json:"status"`
+	Repository string `json:"repository"`
+	Commit     string `json:"commit"`
 }
 
 // SourceCode represents downloaded source code for a contract.
@@ -56,6 +68,9 @@ type SourceCode struct {
 	WasmHash string
 	// Repository is the source repository URL.
 	Repository string
+	// Commit is the repository commit the source was fetched at. Empty when
+	// the registry did not record one and the default branch was used.
+	Commit string
 	// Files maps relative file paths to their contents.
 	Files map[string]string
 	// FetchedAt is the time this source was retrieved.
@@ -153,12 +168,24 @@ func (rc *RegistryClient) GetContractInfo(ctx context.Context, contractID string
 	}
 
 	info.ContractID = contractID
+	if v := info.Validation; v != nil {
+		if info.Repository == "" {
+			info.Repository = v.Repository
+		}
+		if info.Commit == "" {
+			info.Commit = v.Commit
+		}
+		if v.Status == "verified" {
+			info.Verified = true
+		}
+	}
 
 	logger.Logger.Info("Contract info retrieved",
 		"contract_id", contractID,
 		"wasm_hash", info.WasmHash,
 		"verified", info.Verified,
 		"repository", info.Repository,
+		"commit", info.Commit,
 	)
 
 	return &info, nil
@@ -222,7 +249,7 @@ func (rc *RegistryClient) FetchVerifiedSource(ctx context.Context, contractID st
 		return nil, nil
 	}
 
-	files, err := rc.fetchRepositorySource(ctx, info.Repository)
+	files, err := rc.fetchRepositorySource(ctx, info.Repository, info.Commit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch source from repository: %w", err)
 	}
@@ -231,49 +258,37 @@ func (rc *RegistryClient) FetchVerifiedSource(ctx context.Context, contractID st
 		ContractID: contractID,
 		WasmHash:   info.WasmHash,
 		Repository: info.Repository,
+		Commit:     info.Commit,
 		Files:      files,
 		FetchedAt:  time.Now(),
 	}, nil
 }
 
 // fetchRepositorySource downloads source files from a GitHub repository URL.
-// It parses the repo URL, determines the default branch, and fetches Rust source files.
-func (rc *RegistryClient) fetchRepositorySource(ctx context.Context, repoURL string) (map[string]string, error) {
+// It parses the repo URL and fetches Rust source files at the given commit,
+// or at the default branch when commit is empty.
+func (rc *RegistryClient) fetchRepositorySource(ctx context.Context, repoURL, commit string) (map[string]string, error) {
 	owner, repo, err := parseGitHubURL(repoURL)
 	if err != nil {
 		return nil, fmt.Errorf("unsupported repository URL %q: %w", repoURL, err)
 	}
 
+	ref := commit
+	if ref == "" {
+		if ref, err = rc.defaultBranch(ctx, owner, repo); err != nil {
+			return nil, err
+		}
+	}
+
 	logger.Logger.Debug("Fetching source from GitHub",
 		"owner", owner,
 		"repo", repo,
+		"ref", ref,
 	)
 
-	// Fetch repo metadata to determine default branch
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
-	body, statusCode, err := rc.doGet(ctx, apiURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch repository metadata: %w", err)
-	}
-	if statusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d for %s/%s", statusCode, owner, repo)
-	}
-
-	var repoMeta struct {
-		DefaultBranch string `json:"default_branch"`
-	}
-	if err := json.Unmarshal(body, &repoMeta); err != nil {
-		return nil, fmt.Errorf("failed to parse GitHub repo metadata: %w", err)
-	}
-
-	branch := repoMeta.DefaultBranch
-	if branch == "" {
-		branch = "main"
-	}
-
 	// Fetch the file tree
-	treeURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/trees/%s?recursive=1", owner, repo, branch)
-	body, statusCode, err = rc.doGet(ctx, treeURL)
+	treeURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/trees/%s?recursive=1", owner, repo, ref)
+	body, statusCode, err := rc.doGet(ctx, treeURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch repository tree: %w", err)
 	}
@@ -307,7 +322,7 @@ func (rc *RegistryClient) fetchRepositorySource(ctx context.Context, repoURL str
 			continue
 		}
 
-		rawURL := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", owner, repo, branch, entry.Path)
+		rawURL := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", owner, repo, ref, entry.Path)
 		content, statusCode, err := rc.doGet(ctx, rawURL)
 		if err != nil {
 			logger.Logger.Warn("Failed to fetch file", "path", entry.Path, "error", err)
@@ -329,6 +344,29 @@ func (rc *RegistryClient) fetchRepositorySource(ctx context.Context, repoURL str
 	return files, nil
 }
 
+// defaultBranch looks up the default branch of a GitHub repository.
+func (rc *RegistryClient) defaultBranch(ctx context.Context, owner, repo string) (string, error) {
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	body, statusCode, err := rc.doGet(ctx, apiURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch repository metadata: %w", err)
+	}
+	if statusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub API returned status %d for %s/%s", statusCode, owner, repo)
+	}
+
+	var repoMeta struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := json.Unmarshal(body, &repoMeta); err != nil {
+		return "", fmt.Errorf("failed to parse GitHub repo metadata: %w", err)
+	}
+	if repoMeta.DefaultBranch == "" {
+		return "main", nil
+	}
+	return repoMeta.DefaultBranch, nil
+}
+
 // doGet performs an HTTP GET request and returns the response body and status code.
 func (rc *RegistryClient) doGet(ctx context.Context, url string) ([]byte, int, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
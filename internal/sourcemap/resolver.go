@@ -114,6 +114,46 @@ func (r *Resolver) Resolve(ctx context.Context, contractID string) (*SourceCode,
 	return source, nil
 }
 
+// ResolveVerified returns the verified source for a contract only when it was
+// verified against wasmHash, the hash of the code currently deployed. Unlike
+// Resolve it never prompts; nil is returned when no matching source exists,
+// e.g. after the contract was upgraded to code that has not been verified.
+func (r *Resolver) ResolveVerified(ctx context.Context, contractID, wasmHash string) (*SourceCode, error) {
+	if err := validateContractID(contractID); err != nil {
+		return nil, fmt.Errorf("invalid contract ID: %w", err)
+	}
+
+	if r.cache != nil {
+		if cached := r.cache.Get(contractID); cached != nil && strings.EqualFold(cached.WasmHash, wasmHash) {
+			logger.Logger.Info("Verified source resolved from cache", "contract_id", contractID)
+			return cached, nil
+		}
+	}
+
+	source, err := r.registry.FetchVerifiedSource(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, nil
+	}
+	if !strings.EqualFold(source.WasmHash, wasmHash) {
+		logger.Logger.Info("Verified source does not match deployed WASM",
+			"contract_id", contractID,
+			"verified_hash", source.WasmHash,
+			"deployed_hash", wasmHash,
+		)
+		return nil, nil
+	}
+
+	if r.cache != nil {
+		if err := r.cache.Put(source); err != nil {
+			logger.Logger.Warn("Failed to cache source", "contract_id", contractID, "error", err)
+		}
+	}
+	return source, nil
+}
+
 // PromptForWasmPath pauses execution and asks the user for a manual WASM path.
 // Requirement: If erst encounters an unknown contract, pause and ask the user
 // "Please provide path to contract WASM for better mapping".
@@ -750,6 +750,168 @@ func TestNewResolver_Options(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// Verified Source Tests
+// =============================================================================
+
+func TestGetContractInfo_NestedValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"wasm_hash":"abc123","validation":{"status":"verified","repository":"https://github.com/stellar/soroban-examples","commit":"0f1e2d3c"}}`)
+	}))
+	defer server.Close()
+
+	client := NewRegistryClient(WithBaseURL(server.URL))
+	got, err := client.GetContractInfo(context.Background(), "CAS3J7GYCCX3S7LX63P6R7EAL477J26C356X6E5A4XERAD7UXD6I7Y3N")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.Verified {
+		t.Error("expected Verified to be true")
+	}
+	if got.Repository != "https://github.com/stellar/soroban-examples" {
+		t.Errorf("Repository = %q", got.Repository)
+	}
+	if got.Commit != "0f1e2d3c" {
+		t.Errorf("Commit = %q, want %q", got.Commit, "0f1e2d3c")
+	}
+}
+
+func testVerifiedSource() *SourceCode {
+	return &SourceCode{
+		ContractID: "CAS3J7GYCCX3S7LX63P6R7EAL477J26C356X6E5A4XERAD7UXD6I7Y3N",
+		WasmHash:   "abc123",
+		Repository: "https://github.com/stellar/soroban-examples.git",
+		Commit:     "0f1e2d3c",
+		Files: map[string]string{
+			"token/src/contract.rs": "use soroban_sdk::contractimpl;\n\n#[contractimpl]\nimpl Token {\n    pub fn transfer(env: Env, from: Address) {\n        from.require_auth();\n    }\n}\n",
+			"token/src/test.rs":     "fn transfer() {}\n",
+			"token/Cargo.toml":      "[package]\nname = \"token\"\n",
+		},
+	}
+}
+
+func TestSourceCode_Link(t *testing.T) {
+	src := testVerifiedSource()
+	want := "https://github.com/stellar/soroban-examples/blob/0f1e2d3c/token/src/contract.rs#L5"
+	if got := src.Link("token/src/contract.rs", 5); got != want {
+		t.Errorf("Link = %q, want %q", got, want)
+	}
+
+	src.Repository = "not a url"
+	if got := src.Link("token/src/contract.rs", 5); got != "" {
+		t.Errorf("Link for unsupported repository = %q, want empty", got)
+	}
+}
+
+func TestSourceCode_MatchFile(t *testing.T) {
+	src := testVerifiedSource()
+	tests := []struct {
+		file string
+		want string
+		ok   bool
+	}{
+		{"/home/ci/build/token/src/contract.rs", "token/src/contract.rs", true},
+		{"src/contract.rs", "token/src/contract.rs", true},
+		{"/rustc/abc/library/core/src/panicking.rs", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := src.MatchFile(tt.file)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("MatchFile(%q) = %q, %v; want %q, %v", tt.file, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSourceCode_FindFunction(t *testing.T) {
+	src := testVerifiedSource()
+
+	loc, ok := src.FindFunction("token::Token::transfer")
+	if !ok {
+		t.Fatal("expected transfer to be found")
+	}
+	if loc.Path != "token/src/contract.rs" || loc.Line != 5 {
+		t.Errorf("FindFunction = %s:%d, want token/src/contract.rs:5", loc.Path, loc.Line)
+	}
+
+	if _, ok := src.FindFunction("mint"); ok {
+		t.Error("expected mint not to be found")
+	}
+}
+
+func TestLocator_LocateFunction(t *testing.T) {
+	src := testVerifiedSource()
+	root := t.TempDir()
+	if err := src.Materialize(root); err != nil {
+		t.Fatalf("Materialize: %v", err)
+	}
+
+	loc, ok := NewLocator(src, root, nil).LocateFunction("transfer")
+	if !ok {
+		t.Fatal("expected transfer to be located")
+	}
+	if want := filepath.Join(root, "token", "src", "contract.rs"); loc.LocalPath != want {
+		t.Errorf("LocalPath = %q, want %q", loc.LocalPath, want)
+	}
+	if _, err := os.Stat(loc.LocalPath); err != nil {
+		t.Errorf("materialized file missing: %v", err)
+	}
+	if loc.URL != src.Link("token/src/contract.rs", 5) {
+		t.Errorf("URL = %q", loc.URL)
+	}
+}
+
+func TestSourceCode_MaterializeSkipsEscapingPaths(t *testing.T) {
+	src := &SourceCode{Files: map[string]string{"../escape.rs": "fn x() {}"}}
+	dir := filepath.Join(t.TempDir(), "root")
+	if err := src.Materialize(dir); err != nil {
+		t.Fatalf("Materialize: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.rs")); !os.IsNotExist(err) {
+		t.Error("expected path outside the root to be skipped")
+	}
+}
+
+func TestResolver_ResolveVerified(t *testing.T) {
+	cache, err := NewSourceCache(filepath.Join(t.TempDir(), "sourcemap"))
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	src := testVerifiedSource()
+	src.FetchedAt = time.Now()
+	if err := cache.Put(src); err != nil {
+		t.Fatalf("failed to put cache entry: %v", err)
+	}
+
+	// The registry reports the source as unverified for any newer code.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ContractInfo{WasmHash: "abc123", Verified: false})
+	}))
+	defer server.Close()
+
+	resolver := &Resolver{
+		registry: NewRegistryClient(WithBaseURL(server.URL)),
+		cache:    cache,
+	}
+	ctx := context.Background()
+
+	got, err := resolver.ResolveVerified(ctx, src.ContractID, "ABC123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || got.Commit != "0f1e2d3c" {
+		t.Fatalf("expected cached source for matching hash, got %+v", got)
+	}
+
+	got, err = resolver.ResolveVerified(ctx, src.ContractID, "def456")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != nil {
+		t.Error("expected no source when the deployed hash differs")
+	}
+}
+
 // =============================================================================
 // CheckHashMismatch Tests
 // =============================================================================
@@ -0,0 +1,422 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package statediff
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/abi"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// Specs are the contract specs used to decode contract data, by contract
+// ID (C...). Contracts without a spec are rendered structurally.
+type Specs map[string]*abi.ContractSpec
+
+// Summary is a Change decoded for display.
+type Summary struct {
+	Kind      Kind   `json:"kind"`
+	Stage     Stage  `json:"stage"`
+	Operation int    `json:"operation"`
+	Type      string `json:"type"`
+	// Contract and Durability are set for contract data, and for TTLs
+	// whose target is known.
+	Contract   string `json:"contract,omitempty"`
+	Durability string `json:"durability,omitempty"`
+	Key        string `json:"key"`
+	Before     string `json:"before,omitempty"`
+	After      string `json:"after,omitempty"`
+	// Fields are the fields that differ between Before and After, for
+	// updated structs, maps, vectors and classic entries.
+	Fields []FieldDiff `json:"fields,omitempty"`
+	TTL    *TTLChange  `json:"ttl,omitempty"`
+
+	KeyXdr    string `json:"key_xdr"`
+	BeforeXdr string `json:"before_xdr,omitempty"`
+	AfterXdr  string `json:"after_xdr,omitempty"`
+}
+
+// FieldDiff is one field of an updated entry. Before is empty for added
+// fields and After for removed ones.
+type FieldDiff struct {
+	Path   string `json:"path"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// Contracts returns the contracts whose data or TTLs the changes touch, in
+// order of first appearance.
+func Contracts(changes []Change) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, c := range changes {
+		key := c.Key
+		if c.TTL != nil && c.TTL.Target != nil {
+			key = *c.TTL.Target
+		}
+		if id := keyContract(key); id != "" && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// Describe decodes the change, using the spec of the entry's contract for
+// contract data.
+func (c Change) Describe(specs Specs) Summary {
+	s := Summary{Kind: c.Kind, Stage: c.Stage, Operation: c.Operation, Type: entryTypeName(c.Key.Type), TTL: c.TTL}
+	s.KeyXdr, _ = xdr.MarshalBase64(c.Key)
+	if c.Before != nil {
+		s.BeforeXdr, _ = xdr.MarshalBase64(*c.Before)
+	}
+	if c.After != nil {
+		s.AfterXdr, _ = xdr.MarshalBase64(*c.After)
+	}
+
+	key := c.Key
+	if c.TTL != nil {
+		if c.TTL.Target == nil {
+			s.Key = "hash " + hex.EncodeToString(c.Key.Ttl.KeyHash[:])
+			return s
+		}
+		key = *c.TTL.Target
+	}
+	spec := specs[keyContract(key)]
+	s.Contract = keyContract(key)
+	s.Durability = keyDurability(key)
+	s.Key = formatKey(spec, key)
+	if c.TTL != nil {
+		return s
+	}
+
+	if c.Before != nil {
+		s.Before = formatEntry(spec, *c.Before)
+	}
+	if c.After != nil {
+		s.After = formatEntry(spec, *c.After)
+	}
+	if c.Before != nil && c.After != nil {
+		s.Fields = diffEntries(spec, *c.Before, *c.After)
+	}
+	return s
+}
+
+func entryTypeName(t xdr.LedgerEntryType) string {
+	switch t {
+	case xdr.LedgerEntryTypeAccount:
+		return "account"
+	case xdr.LedgerEntryTypeTrustline:
+		return "trustline"
+	case xdr.LedgerEntryTypeOffer:
+		return "offer"
+	case xdr.LedgerEntryTypeData:
+		return "data"
+	case xdr.LedgerEntryTypeClaimableBalance:
+		return "claimable_balance"
+	case xdr.LedgerEntryTypeLiquidityPool:
+		return "liquidity_pool"
+	case xdr.LedgerEntryTypeContractData:
+		return "contract_data"
+	case xdr.LedgerEntryTypeContractCode:
+		return "contract_code"
+	case xdr.LedgerEntryTypeConfigSetting:
+		return "config_setting"
+	case xdr.LedgerEntryTypeTtl:
+		return "ttl"
+	}
+	return t.String()
+}
+
+func keyContract(key xdr.LedgerKey) string {
+	if key.Type != xdr.LedgerEntryTypeContractData || key.ContractData == nil {
+		return ""
+	}
+	id, err := key.ContractData.Contract.String()
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+func keyDurability(key xdr.LedgerKey) string {
+	if key.Type != xdr.LedgerEntryTypeContractData || key.ContractData == nil {
+		return ""
+	}
+	switch {
+	case key.ContractData.Key.Type == xdr.ScValTypeScvLedgerKeyContractInstance:
+		return abi.StorageInstance
+	case key.ContractData.Durability == xdr.ContractDataDurabilityTemporary:
+		return abi.StorageTemporary
+	}
+	return abi.StoragePersistent
+}
+
+// formatKey renders the identifying part of a ledger key.
+func formatKey(spec *abi.ContractSpec, key xdr.LedgerKey) string {
+	switch key.Type {
+	case xdr.LedgerEntryTypeAccount:
+		return key.Account.AccountId.Address()
+	case xdr.LedgerEntryTypeTrustline:
+		return key.TrustLine.AccountId.Address() + " " + trustLineAssetName(key.TrustLine.Asset)
+	case xdr.LedgerEntryTypeOffer:
+		return fmt.Sprintf("%d by %s", key.Offer.OfferId, key.Offer.SellerId.Address())
+	case xdr.LedgerEntryTypeData:
+		return key.Data.AccountId.Address() + " " + string(key.Data.DataName)
+	case xdr.LedgerEntryTypeClaimableBalance:
+		if id, err := xdr.MarshalHex(key.ClaimableBalance.BalanceId); err == nil {
+			return id
+		}
+	case xdr.LedgerEntryTypeLiquidityPool:
+		return hex.EncodeToString(key.LiquidityPool.LiquidityPoolId[:])
+	case xdr.LedgerEntryTypeContractData:
+		if key.ContractData.Key.Type == xdr.ScValTypeScvLedgerKeyContractInstance {
+			return "instance"
+		}
+		return abi.FormatValue(spec, key.ContractData.Key)
+	case xdr.LedgerEntryTypeContractCode:
+		return hex.EncodeToString(key.ContractCode.Hash[:])
+	case xdr.LedgerEntryTypeConfigSetting:
+		return key.ConfigSetting.ConfigSettingId.String()
+	case xdr.LedgerEntryTypeTtl:
+		return hex.EncodeToString(key.Ttl.KeyHash[:])
+	}
+	return ""
+}
+
+func trustLineAssetName(a xdr.TrustLineAsset) string {
+	if a.Type == xdr.AssetTypeAssetTypePoolShare && a.LiquidityPoolId != nil {
+		return "pool " + hex.EncodeToString(a.LiquidityPoolId[:])
+	}
+	return a.ToAsset().StringCanonical()
+}
+
+// field is a named part of a classic ledger entry.
+type field struct {
+	name  string
+	value string
+}
+
+// entryFields lists the fields of entries that are not contract data.
+func entryFields(e xdr.LedgerEntry) []field {
+	d := e.Data
+	switch d.Type {
+	case xdr.LedgerEntryTypeAccount:
+		a := d.Account
+		fs := []field{
+			{"balance", fmt.Sprint(a.Balance)},
+			{"seq_num", fmt.Sprint(a.SeqNum)},
+			{"num_sub_entries", fmt.Sprint(a.NumSubEntries)},
+			{"flags", fmt.Sprint(a.Flags)},
+			{"signers", fmt.Sprint(len(a.Signers))},
+		}
+		if a.HomeDomain != "" {
+			fs = append(fs, field{"home_domain", string(a.HomeDomain)})
+		}
+		return fs
+	case xdr.LedgerEntryTypeTrustline:
+		t := d.TrustLine
+		return []field{{"balance", fmt.Sprint(t.Balance)}, {"limit", fmt.Sprint(t.Limit)}, {"flags", fmt.Sprint(t.Flags)}}
+	case xdr.LedgerEntryTypeOffer:
+		o := d.Offer
+		return []field{
+			{"selling", o.Selling.StringCanonical()},
+			{"buying", o.Buying.StringCanonical()},
+			{"amount", fmt.Sprint(o.Amount)},
+			{"price", fmt.Sprintf("%d/%d", o.Price.N, o.Price.D)},
+		}
+	case xdr.LedgerEntryTypeData:
+		return []field{{"value", hex.EncodeToString(d.Data.DataValue)}}
+	case xdr.LedgerEntryTypeClaimableBalance:
+		cb := d.ClaimableBalance
+		return []field{{"asset", cb.Asset.StringCanonical()}, {"amount", fmt.Sprint(cb.Amount)}, {"claimants", fmt.Sprint(len(cb.Claimants))}}
+	case xdr.LedgerEntryTypeLiquidityPool:
+		if cp := d.LiquidityPool.Body.ConstantProduct; cp != nil {
+			return []field{
+				{"reserve_a", fmt.Sprint(cp.ReserveA)},
+				{"reserve_b", fmt.Sprint(cp.ReserveB)},
+				{"total_pool_shares", fmt.Sprint(cp.TotalPoolShares)},
+			}
+		}
+	case xdr.LedgerEntryTypeContractCode:
+		return []field{{"size", fmt.Sprintf("%d bytes", len(d.ContractCode.Code))}}
+	case xdr.LedgerEntryTypeTtl:
+		return []field{{"live_until", fmt.Sprint(d.Ttl.LiveUntilLedgerSeq)}}
+	}
+	return nil
+}
+
+// formatEntry renders the value of a ledger entry.
+func formatEntry(spec *abi.ContractSpec, e xdr.LedgerEntry) string {
+	if cd, ok := e.Data.GetContractData(); ok {
+		return formatScVal(spec, cd.Val)
+	}
+	fs := entryFields(e)
+	parts := make([]string, len(fs))
+	for i, f := range fs {
+		parts[i] = f.name + "=" + f.value
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatScVal is abi.FormatValue with contract instances spelled out.
+func formatScVal(spec *abi.ContractSpec, v xdr.ScVal) string {
+	if v.Type != xdr.ScValTypeScvContractInstance || v.Instance == nil {
+		return abi.FormatValue(spec, v)
+	}
+	exec := executableName(v.Instance.Executable)
+	if v.Instance.Storage == nil || len(*v.Instance.Storage) == 0 {
+		return exec
+	}
+	return exec + " " + abi.FormatValue(spec, mapVal(*v.Instance.Storage))
+}
+
+func executableName(e xdr.ContractExecutable) string {
+	if e.Type == xdr.ContractExecutableTypeContractExecutableWasm && e.WasmHash != nil {
+		return "wasm " + hex.EncodeToString(e.WasmHash[:])
+	}
+	return "stellar asset contract"
+}
+
+// diffEntries lists the fields that differ between two versions of an
+// entry.
+func diffEntries(spec *abi.ContractSpec, before, after xdr.LedgerEntry) []FieldDiff {
+	var out []FieldDiff
+	if b, ok := before.Data.GetContractData(); ok {
+		if a, ok := after.Data.GetContractData(); ok && composite(b.Val) && b.Val.Type == a.Val.Type {
+			diffScVal(spec, "", b.Val, a.Val, &out)
+		}
+		return out
+	}
+
+	bf, af := entryFields(before), entryFields(after)
+	index := make(map[string]string, len(bf))
+	for _, f := range bf {
+		index[f.name] = f.value
+	}
+	for _, f := range af {
+		if old, ok := index[f.name]; !ok || old != f.value {
+			out = append(out, FieldDiff{Path: f.name, Before: old, After: f.value})
+		}
+		delete(index, f.name)
+	}
+	for _, f := range bf {
+		if _, ok := index[f.name]; ok {
+			out = append(out, FieldDiff{Path: f.name, Before: f.value})
+		}
+	}
+	return out
+}
+
+func composite(v xdr.ScVal) bool {
+	switch v.Type {
+	case xdr.ScValTypeScvMap, xdr.ScValTypeScvVec, xdr.ScValTypeScvContractInstance:
+		return true
+	}
+	return false
+}
+
+// diffScVal appends the leaves that differ between two values. Maps are
+// compared by key and vectors of equal length by index; anything else that
+// differs is reported as a whole.
+func diffScVal(spec *abi.ContractSpec, path string, before, after xdr.ScVal, out *[]FieldDiff) {
+	if scValEqual(before, after) {
+		return
+	}
+	switch {
+	case before.Type == xdr.ScValTypeScvMap && after.Type == xdr.ScValTypeScvMap:
+		diffMaps(spec, path, mapOf(before), mapOf(after), out)
+		return
+	case before.Type == xdr.ScValTypeScvVec && after.Type == xdr.ScValTypeScvVec:
+		b, a := vecOf(before), vecOf(after)
+		if len(b) == len(a) {
+			for i := range b {
+				diffScVal(spec, fmt.Sprintf("%s[%d]", path, i), b[i], a[i], out)
+			}
+			return
+		}
+	case before.Type == xdr.ScValTypeScvContractInstance && after.Type == xdr.ScValTypeScvContractInstance &&
+		before.Instance != nil && after.Instance != nil:
+		if b, a := executableName(before.Instance.Executable), executableName(after.Instance.Executable); b != a {
+			*out = append(*out, FieldDiff{Path: joinPath(path, "executable"), Before: b, After: a})
+		}
+		var b, a xdr.ScMap
+		if before.Instance.Storage != nil {
+			b = *before.Instance.Storage
+		}
+		if after.Instance.Storage != nil {
+			a = *after.Instance.Storage
+		}
+		diffMaps(spec, joinPath(path, "storage"), b, a, out)
+		return
+	}
+	*out = append(*out, FieldDiff{Path: path, Before: formatScVal(spec, before), After: formatScVal(spec, after)})
+}
+
+func diffMaps(spec *abi.ContractSpec, path string, before, after xdr.ScMap, out *[]FieldDiff) {
+	index := make(map[string]xdr.ScVal, len(before))
+	for _, e := range before {
+		index[abi.FormatValue(spec, e.Key)] = e.Val
+	}
+	for _, e := range after {
+		name := abi.FormatValue(spec, e.Key)
+		sub := mapPath(path, e.Key, name)
+		if old, ok := index[name]; ok {
+			diffScVal(spec, sub, old, e.Val, out)
+			delete(index, name)
+			continue
+		}
+		*out = append(*out, FieldDiff{Path: sub, After: formatScVal(spec, e.Val)})
+	}
+	for _, e := range before {
+		name := abi.FormatValue(spec, e.Key)
+		if old, ok := index[name]; ok {
+			*out = append(*out, FieldDiff{Path: mapPath(path, e.Key, name), Before: formatScVal(spec, old)})
+		}
+	}
+}
+
+// mapPath names a map entry: symbol keys (struct fields) as path.name,
+// other keys as path[key].
+func mapPath(path string, key xdr.ScVal, name string) string {
+	if key.Type == xdr.ScValTypeScvSymbol {
+		return joinPath(path, name)
+	}
+	return path + "[" + name + "]"
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func scValEqual(a, b xdr.ScVal) bool {
+	ra, errA := a.MarshalBinary()
+	rb, errB := b.MarshalBinary()
+	return errA == nil && errB == nil && bytes.Equal(ra, rb)
+}
+
+func vecOf(v xdr.ScVal) []xdr.ScVal {
+	if v.Vec == nil || *v.Vec == nil {
+		return nil
+	}
+	return **v.Vec
+}
+
+func mapOf(v xdr.ScVal) xdr.ScMap {
+	if v.Map == nil || *v.Map == nil {
+		return nil
+	}
+	return **v.Map
+}
+
+func mapVal(m xdr.ScMap) xdr.ScVal {
+	p := &m
+	return xdr.ScVal{Type: xdr.ScValTypeScvMap, Map: &p}
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package statediff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatText renders the changes grouped by the stage and operation that
+// made them.
+func FormatText(summaries []Summary) string {
+	if len(summaries) == 0 {
+		return "No ledger entries changed.\n"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Ledger changes: %d\n", len(summaries))
+	heading := ""
+	for _, s := range summaries {
+		if h := stageHeading(s); h != heading {
+			heading = h
+			fmt.Fprintf(&b, "\n%s\n", h)
+		}
+		fmt.Fprintf(&b, "  %-13s %s\n", label(s), subject(s))
+		for _, line := range details(s) {
+			fmt.Fprintf(&b, "      %s\n", line)
+		}
+	}
+	return b.String()
+}
+
+func stageHeading(s Summary) string {
+	switch s.Stage {
+	case StageFee:
+		return "Fee processing"
+	case StageTxBefore:
+		return "Before operations"
+	case StageTxAfter:
+		return "After operations"
+	}
+	return fmt.Sprintf("Operation %d", s.Operation)
+}
+
+func label(s Summary) string {
+	if s.TTL == nil {
+		return string(s.Kind)
+	}
+	if s.TTL.Extended() && s.Kind == KindUpdated {
+		return "ttl extended"
+	}
+	return "ttl " + string(s.Kind)
+}
+
+func subject(s Summary) string {
+	parts := []string{}
+	if s.TTL == nil || s.Contract == "" {
+		parts = append(parts, s.Type)
+	}
+	for _, p := range []string{s.Contract, s.Durability, s.Key} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func details(s Summary) []string {
+	if t := s.TTL; t != nil {
+		switch {
+		case t.Before == 0:
+			return []string{fmt.Sprintf("live until ledger %d", t.After)}
+		case t.After == 0:
+			return []string{fmt.Sprintf("was live until ledger %d", t.Before)}
+		}
+		return []string{fmt.Sprintf("live until ledger %d -> %d", t.Before, t.After)}
+	}
+
+	if len(s.Fields) > 0 {
+		lines := make([]string, len(s.Fields))
+		for i, f := range s.Fields {
+			switch {
+			case f.Before == "":
+				lines[i] = fmt.Sprintf("+ %s: %s", f.Path, f.After)
+			case f.After == "":
+				lines[i] = fmt.Sprintf("- %s: %s", f.Path, f.Before)
+			default:
+				lines[i] = fmt.Sprintf("%s: %s -> %s", f.Path, f.Before, f.After)
+			}
+		}
+		return lines
+	}
+
+	switch {
+	case s.Before != "" && s.After != "":
+		if s.Before == s.After {
+			return []string{"unchanged: " + s.After}
+		}
+		return []string{s.Before + " -> " + s.After}
+	case s.After != "":
+		return []string{"= " + s.After}
+	case s.Before != "":
+		return []string{"was " + s.Before}
+	}
+	return nil
+}
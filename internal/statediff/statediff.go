@@ -0,0 +1,267 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+// Package statediff lists the ledger entries a transaction changed, as
+// recorded in its TransactionMeta, with each entry's value before and after
+// the change. Unlike rpc.ExtractLedgerEntriesFromMeta, which flattens the
+// meta into a key/value map, every created, updated, removed and restored
+// entry is kept along with the operation that changed it. TTL entries are
+// linked to the entry whose lifetime they extend, and footprint restores are
+// recognized on protocols that record them as TTL updates.
+package statediff
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// Kind is what happened to a ledger entry.
+type Kind string
+
+const (
+	KindCreated  Kind = "created"
+	KindUpdated  Kind = "updated"
+	KindRemoved  Kind = "removed"
+	KindRestored Kind = "restored"
+)
+
+// Stage is the part of transaction processing that made a change.
+type Stage string
+
+const (
+	// StageFee is the fee charged before the transaction is applied.
+	StageFee Stage = "fee"
+	// StageTxBefore holds transaction-level changes made before the
+	// operations, such as the sequence number bump.
+	StageTxBefore Stage = "tx-before"
+	// StageOperation holds the changes of one operation.
+	StageOperation Stage = "operation"
+	// StageTxAfter holds transaction-level changes made after the
+	// operations, such as the Soroban fee refund.
+	StageTxAfter Stage = "tx-after"
+)
+
+// Change is one LedgerEntryChange of the meta.
+type Change struct {
+	Kind  Kind
+	Stage Stage
+	// Operation is the index of the operation that made the change, or -1
+	// outside StageOperation.
+	Operation int
+	Key       xdr.LedgerKey
+	// Before is the entry as it was before the change: the preceding STATE
+	// entry of the meta, or the entry left by an earlier change in the same
+	// transaction. It is nil for created entries and for restored entries,
+	// which come out of the archive.
+	Before *xdr.LedgerEntry
+	// After is nil for removed entries.
+	After *xdr.LedgerEntry
+	// TTL is set for changes to TTL entries.
+	TTL *TTLChange
+}
+
+// TTLChange is the change of an entry's live-until ledger.
+type TTLChange struct {
+	// Target is the key of the contract data or code entry the TTL belongs
+	// to, when the transaction changed it or declared it in its footprint.
+	Target *xdr.LedgerKey `json:"-"`
+	// Before and After are live-until ledger sequences; 0 when the TTL
+	// entry did not exist on that side.
+	Before uint32 `json:"before"`
+	After  uint32 `json:"after"`
+}
+
+// Extended reports whether the entry lives longer after the change.
+func (t TTLChange) Extended() bool {
+	return t.Before != 0 && t.After > t.Before
+}
+
+// FromTransaction extracts the changes of a transaction from its base64
+// result meta, which may be a TransactionResultMeta (including fee
+// processing) or a bare TransactionMeta. The base64 envelope is optional; it
+// provides the footprint used to link TTL entries to their targets and the
+// operation types used to recognize footprint restores.
+func FromTransaction(envelopeXdr, resultMetaXdr string) ([]Change, error) {
+	fee, meta, err := decodeMeta(resultMetaXdr)
+	if err != nil {
+		return nil, err
+	}
+
+	var env *xdr.TransactionEnvelope
+	if envelopeXdr != "" {
+		var e xdr.TransactionEnvelope
+		if err := xdr.SafeUnmarshalBase64(envelopeXdr, &e); err != nil {
+			return nil, fmt.Errorf("decode envelope: %w", err)
+		}
+		env = &e
+	}
+
+	parts, err := simulator.SplitMeta(meta)
+	if err != nil {
+		return nil, err
+	}
+	x := extractor{last: map[string]*xdr.LedgerEntry{}}
+	x.add(fee, StageFee, -1)
+	x.add(parts.Before, StageTxBefore, -1)
+	for i, changes := range parts.Operations {
+		x.add(changes, StageOperation, i)
+	}
+	x.add(parts.After, StageTxAfter, -1)
+
+	if err := x.linkTTLs(env); err != nil {
+		return nil, err
+	}
+	return x.changes, nil
+}
+
+func decodeMeta(resultMetaXdr string) (xdr.LedgerEntryChanges, xdr.TransactionMeta, error) {
+	raw, err := base64.StdEncoding.DecodeString(resultMetaXdr)
+	if err != nil {
+		return nil, xdr.TransactionMeta{}, fmt.Errorf("decode result meta: %w", err)
+	}
+	var resultMeta xdr.TransactionResultMeta
+	if err := xdr.SafeUnmarshal(raw, &resultMeta); err == nil {
+		return resultMeta.FeeProcessing, resultMeta.TxApplyProcessing, nil
+	}
+	var meta xdr.TransactionMeta
+	if err := xdr.SafeUnmarshal(raw, &meta); err != nil {
+		return nil, meta, fmt.Errorf("unmarshal result meta: %w", err)
+	}
+	return nil, meta, nil
+}
+
+type extractor struct {
+	changes []Change
+	// last is the latest known value of each entry, by base64 key.
+	last map[string]*xdr.LedgerEntry
+}
+
+func (x *extractor) add(changes xdr.LedgerEntryChanges, stage Stage, op int) {
+	for _, c := range changes {
+		var (
+			kind  Kind
+			entry *xdr.LedgerEntry
+			key   xdr.LedgerKey
+		)
+		switch c.Type {
+		case xdr.LedgerEntryChangeTypeLedgerEntryState:
+			if k, ok := entryKey(c.State); ok {
+				x.last[k] = c.State
+			}
+			continue
+		case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+			kind, entry = KindCreated, c.Created
+		case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+			kind, entry = KindUpdated, c.Updated
+		case xdr.LedgerEntryChangeTypeLedgerEntryRestored:
+			kind, entry = KindRestored, c.Restored
+		case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+			if c.Removed == nil {
+				continue
+			}
+			kind, key = KindRemoved, *c.Removed
+		default:
+			continue
+		}
+		if entry != nil {
+			k, err := entry.LedgerKey()
+			if err != nil {
+				continue
+			}
+			key = k
+		}
+		keyB64, err := xdr.MarshalBase64(key)
+		if err != nil {
+			continue
+		}
+
+		ch := Change{Kind: kind, Stage: stage, Operation: op, Key: key, After: entry}
+		if kind == KindUpdated || kind == KindRemoved {
+			ch.Before = x.last[keyB64]
+		}
+		if entry != nil {
+			x.last[keyB64] = entry
+		} else {
+			delete(x.last, keyB64)
+		}
+		x.changes = append(x.changes, ch)
+	}
+}
+
+// linkTTLs fills in the TTL of every TTL entry change and finds its target
+// among the keys the transaction changed or declared. Before protocol 23 a
+// footprint restore only shows up as TTL updates of the RestoreFootprint
+// operation; those are reported as restores.
+func (x *extractor) linkTTLs(env *xdr.TransactionEnvelope) error {
+	targets := map[xdr.Hash]xdr.LedgerKey{}
+	addTarget := func(k xdr.LedgerKey) error {
+		if k.Type != xdr.LedgerEntryTypeContractData && k.Type != xdr.LedgerEntryTypeContractCode {
+			return nil
+		}
+		raw, err := k.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("encode ledger key: %w", err)
+		}
+		targets[sha256.Sum256(raw)] = k
+		return nil
+	}
+	for _, c := range x.changes {
+		if err := addTarget(c.Key); err != nil {
+			return err
+		}
+	}
+
+	var ops []xdr.Operation
+	if env != nil {
+		ops = env.Operations()
+		if data := simulator.SorobanData(*env); data != nil {
+			fp := data.Resources.Footprint
+			for _, k := range append(append([]xdr.LedgerKey{}, fp.ReadOnly...), fp.ReadWrite...) {
+				if err := addTarget(k); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for i := range x.changes {
+		c := &x.changes[i]
+		if c.Key.Type != xdr.LedgerEntryTypeTtl || c.Key.Ttl == nil {
+			continue
+		}
+		ttl := &TTLChange{Before: liveUntil(c.Before), After: liveUntil(c.After)}
+		if target, ok := targets[c.Key.Ttl.KeyHash]; ok {
+			ttl.Target = &target
+		}
+		c.TTL = ttl
+
+		if c.Kind == KindUpdated && c.Stage == StageOperation && c.Operation < len(ops) &&
+			ops[c.Operation].Body.Type == xdr.OperationTypeRestoreFootprint {
+			c.Kind = KindRestored
+		}
+	}
+	return nil
+}
+
+func liveUntil(e *xdr.LedgerEntry) uint32 {
+	if e == nil || e.Data.Ttl == nil {
+		return 0
+	}
+	return uint32(e.Data.Ttl.LiveUntilLedgerSeq)
+}
+
+func entryKey(e *xdr.LedgerEntry) (string, bool) {
+	if e == nil {
+		return "", false
+	}
+	key, err := e.LedgerKey()
+	if err != nil {
+		return "", false
+	}
+	keyB64, err := xdr.MarshalBase64(key)
+	return keyB64, err == nil
+}
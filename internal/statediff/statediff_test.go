@@ -0,0 +1,307 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package statediff
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/abi"
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const holder = "GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7"
+
+func scSym(s string) xdr.ScVal {
+	v := xdr.ScSymbol(s)
+	return xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &v}
+}
+
+func scU32(n uint32) xdr.ScVal {
+	v := xdr.Uint32(n)
+	return xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &v}
+}
+
+func scVec(items ...xdr.ScVal) xdr.ScVal {
+	v := xdr.ScVec(items)
+	p := &v
+	return xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: &p}
+}
+
+func scMap(kv ...xdr.ScVal) xdr.ScVal {
+	var m xdr.ScMap
+	for i := 0; i+1 < len(kv); i += 2 {
+		m = append(m, xdr.ScMapEntry{Key: kv[i], Val: kv[i+1]})
+	}
+	return mapVal(m)
+}
+
+func scAccount(address string) xdr.ScVal {
+	id := xdr.MustAddress(address)
+	addr := xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &id}
+	return xdr.ScVal{Type: xdr.ScValTypeScvAddress, Address: &addr}
+}
+
+func contractAddress() xdr.ScAddress {
+	var id xdr.ContractId
+	id[0] = 0xab
+	return xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &id}
+}
+
+func dataEntry(key, val xdr.ScVal, durability xdr.ContractDataDurability) *xdr.LedgerEntry {
+	return &xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeContractData,
+		ContractData: &xdr.ContractDataEntry{
+			Contract:   contractAddress(),
+			Key:        key,
+			Durability: durability,
+			Val:        val,
+		},
+	}}
+}
+
+func ttlEntry(t *testing.T, target *xdr.LedgerEntry, liveUntil uint32) *xdr.LedgerEntry {
+	t.Helper()
+	key, err := target.LedgerKey()
+	require.NoError(t, err)
+	raw, err := key.MarshalBinary()
+	require.NoError(t, err)
+	return &xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type: xdr.LedgerEntryTypeTtl,
+		Ttl:  &xdr.TtlEntry{KeyHash: sha256.Sum256(raw), LiveUntilLedgerSeq: xdr.Uint32(liveUntil)},
+	}}
+}
+
+func accountEntry(balance int64) *xdr.LedgerEntry {
+	return &xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type:    xdr.LedgerEntryTypeAccount,
+		Account: &xdr.AccountEntry{AccountId: xdr.MustAddress(holder), Balance: xdr.Int64(balance), SeqNum: 7},
+	}}
+}
+
+func state(e *xdr.LedgerEntry) xdr.LedgerEntryChange {
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: e}
+}
+
+func updated(e *xdr.LedgerEntry) xdr.LedgerEntryChange {
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: e}
+}
+
+func created(e *xdr.LedgerEntry) xdr.LedgerEntryChange {
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: e}
+}
+
+func restored(e *xdr.LedgerEntry) xdr.LedgerEntryChange {
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryRestored, Restored: e}
+}
+
+func removed(t *testing.T, e *xdr.LedgerEntry) xdr.LedgerEntryChange {
+	t.Helper()
+	key, err := e.LedgerKey()
+	require.NoError(t, err)
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &key}
+}
+
+func encodeMeta(t *testing.T, fee xdr.LedgerEntryChanges, ops ...xdr.LedgerEntryChanges) string {
+	t.Helper()
+	results := []xdr.OperationResult{}
+	v3 := &xdr.TransactionMetaV3{}
+	for _, changes := range ops {
+		v3.Operations = append(v3.Operations, xdr.OperationMeta{Changes: changes})
+	}
+	meta := xdr.TransactionResultMeta{
+		Result: xdr.TransactionResultPair{Result: xdr.TransactionResult{
+			Result: xdr.TransactionResultResult{Code: xdr.TransactionResultCodeTxSuccess, Results: &results},
+		}},
+		FeeProcessing:     fee,
+		TxApplyProcessing: xdr.TransactionMeta{V: 3, V3: v3},
+	}
+	encoded, err := xdr.MarshalBase64(meta)
+	require.NoError(t, err)
+	return encoded
+}
+
+func tokenSpec() *abi.ContractSpec {
+	u32 := xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeU32}
+	return &abi.ContractSpec{
+		Structs: []xdr.ScSpecUdtStructV0{{Name: "Config", Fields: []xdr.ScSpecUdtStructFieldV0{
+			{Name: "fee", Type: u32},
+			{Name: "limit", Type: u32},
+		}}},
+		Unions: []xdr.ScSpecUdtUnionV0{{Name: "DataKey", Cases: []xdr.ScSpecUdtUnionCaseV0{
+			{Kind: xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseVoidV0, VoidCase: &xdr.ScSpecUdtUnionCaseVoidV0{Name: "Config"}},
+			{Kind: xdr.ScSpecUdtUnionCaseV0KindScSpecUdtUnionCaseTupleV0, TupleCase: &xdr.ScSpecUdtUnionCaseTupleV0{
+				Name: "Balance", Type: []xdr.ScSpecTypeDef{{Type: xdr.ScSpecTypeScSpecTypeAddress}},
+			}},
+		}}},
+	}
+}
+
+func TestFromTransaction(t *testing.T) {
+	persistent := xdr.ContractDataDurabilityPersistent
+	cfgKey := scVec(scSym("Config"))
+	cfgBefore := dataEntry(cfgKey, scMap(scSym("fee"), scU32(5), scSym("limit"), scU32(100)), persistent)
+	cfgAfter := dataEntry(cfgKey, scMap(scSym("fee"), scU32(10), scSym("limit"), scU32(100)), persistent)
+	balance := dataEntry(scVec(scSym("Balance"), scAccount(holder)), scU32(3), persistent)
+	nonce := dataEntry(scSym("nonce"), scU32(1), xdr.ContractDataDurabilityTemporary)
+	archived := dataEntry(scSym("archived"), scU32(9), persistent)
+
+	meta := encodeMeta(t,
+		xdr.LedgerEntryChanges{state(accountEntry(1000)), updated(accountEntry(900))},
+		xdr.LedgerEntryChanges{
+			state(cfgBefore), updated(cfgAfter),
+			state(ttlEntry(t, cfgBefore, 100)), updated(ttlEntry(t, cfgBefore, 5000)),
+			created(balance),
+			state(nonce), removed(t, nonce),
+			restored(archived), restored(ttlEntry(t, archived, 300)),
+		},
+	)
+
+	changes, err := FromTransaction("", meta)
+	require.NoError(t, err)
+	require.Len(t, changes, 7)
+
+	fee := changes[0]
+	assert.Equal(t, StageFee, fee.Stage)
+	assert.Equal(t, -1, fee.Operation)
+	assert.Equal(t, xdr.Int64(1000), fee.Before.Data.Account.Balance)
+
+	cfg := changes[1]
+	assert.Equal(t, KindUpdated, cfg.Kind)
+	assert.Equal(t, StageOperation, cfg.Stage)
+	assert.Equal(t, 0, cfg.Operation)
+	assert.Equal(t, cfgBefore, cfg.Before)
+	assert.Equal(t, cfgAfter, cfg.After)
+
+	ttl := changes[2]
+	require.NotNil(t, ttl.TTL)
+	assert.True(t, ttl.TTL.Extended())
+	assert.Equal(t, uint32(100), ttl.TTL.Before)
+	assert.Equal(t, uint32(5000), ttl.TTL.After)
+	require.NotNil(t, ttl.TTL.Target)
+	assert.Equal(t, cfg.Key, *ttl.TTL.Target)
+
+	assert.Equal(t, KindCreated, changes[3].Kind)
+	assert.Nil(t, changes[3].Before)
+	assert.Equal(t, KindRemoved, changes[4].Kind)
+	assert.Equal(t, nonce, changes[4].Before)
+	assert.Nil(t, changes[4].After)
+	assert.Equal(t, KindRestored, changes[5].Kind)
+	assert.Nil(t, changes[5].Before)
+	require.NotNil(t, changes[6].TTL)
+	assert.Equal(t, KindRestored, changes[6].Kind)
+	assert.Equal(t, changes[5].Key, *changes[6].TTL.Target)
+
+	contract, err := contractAddress().String()
+	require.NoError(t, err)
+	assert.Equal(t, []string{contract}, Contracts(changes))
+}
+
+func TestDescribe(t *testing.T) {
+	persistent := xdr.ContractDataDurabilityPersistent
+	cfgKey := scVec(scSym("Config"))
+	cfgBefore := dataEntry(cfgKey, scMap(scSym("fee"), scU32(5), scSym("limit"), scU32(100)), persistent)
+	cfgAfter := dataEntry(cfgKey, scMap(scSym("fee"), scU32(10), scSym("limit"), scU32(100)), persistent)
+	meta := encodeMeta(t,
+		xdr.LedgerEntryChanges{state(accountEntry(1000)), updated(accountEntry(900))},
+		xdr.LedgerEntryChanges{
+			state(cfgBefore), updated(cfgAfter),
+			state(ttlEntry(t, cfgBefore, 100)), updated(ttlEntry(t, cfgBefore, 5000)),
+			created(dataEntry(scVec(scSym("Balance"), scAccount(holder)), scU32(3), persistent)),
+		},
+	)
+	changes, err := FromTransaction("", meta)
+	require.NoError(t, err)
+	contract, err := contractAddress().String()
+	require.NoError(t, err)
+	specs := Specs{contract: tokenSpec()}
+
+	fee := changes[0].Describe(specs)
+	assert.Equal(t, "account", fee.Type)
+	assert.Equal(t, holder, fee.Key)
+	assert.Equal(t, []FieldDiff{{Path: "balance", Before: "1000", After: "900"}}, fee.Fields)
+
+	cfg := changes[1].Describe(specs)
+	assert.Equal(t, "contract_data", cfg.Type)
+	assert.Equal(t, contract, cfg.Contract)
+	assert.Equal(t, abi.StoragePersistent, cfg.Durability)
+	assert.Equal(t, "DataKey::Config", cfg.Key)
+	assert.Equal(t, "Config { fee: 10, limit: 100 }", cfg.After)
+	assert.Equal(t, []FieldDiff{{Path: "fee", Before: "5", After: "10"}}, cfg.Fields)
+	assert.NotEmpty(t, cfg.KeyXdr)
+	assert.NotEmpty(t, cfg.BeforeXdr)
+
+	ttl := changes[2].Describe(specs)
+	assert.Equal(t, "ttl", ttl.Type)
+	assert.Equal(t, "DataKey::Config", ttl.Key)
+
+	bal := changes[3].Describe(specs)
+	assert.Equal(t, "DataKey::Balance("+holder+")", bal.Key)
+	assert.Equal(t, "3", bal.After)
+
+	assert.Equal(t, "[Config]", changes[1].Describe(nil).Key, "no spec")
+
+	text := FormatText([]Summary{fee, cfg, ttl, bal})
+	assert.Contains(t, text, "Fee processing\n  updated       account "+holder+"\n      balance: 1000 -> 900\n")
+	assert.Contains(t, text, "Operation 0\n")
+	assert.Contains(t, text, "      fee: 5 -> 10\n")
+	assert.Contains(t, text, "  ttl extended  "+contract+" persistent DataKey::Config\n      live until ledger 100 -> 5000\n")
+	assert.Contains(t, text, "      = 3\n")
+}
+
+func TestDiffScVal(t *testing.T) {
+	before := scMap(scSym("inner"), scMap(scSym("a"), scU32(1)), scSym("gone"), scU32(2), scSym("list"), scVec(scU32(1), scU32(2)))
+	after := scMap(scSym("inner"), scMap(scSym("a"), scU32(3)), scSym("new"), scU32(4), scSym("list"), scVec(scU32(1), scU32(5)))
+
+	var diffs []FieldDiff
+	diffScVal(nil, "", before, after, &diffs)
+	assert.Equal(t, []FieldDiff{
+		{Path: "inner.a", Before: "1", After: "3"},
+		{Path: "new", After: "4"},
+		{Path: "list[1]", Before: "2", After: "5"},
+		{Path: "gone", Before: "2"},
+	}, diffs)
+}
+
+func TestFromTransaction_FootprintRestore(t *testing.T) {
+	archived := dataEntry(scSym("archived"), scU32(9), xdr.ContractDataDurabilityPersistent)
+	key, err := archived.LedgerKey()
+	require.NoError(t, err)
+
+	env := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{Tx: xdr.Transaction{
+			SourceAccount: xdr.MustMuxedAddress(holder),
+			Operations: []xdr.Operation{{Body: xdr.OperationBody{
+				Type:               xdr.OperationTypeRestoreFootprint,
+				RestoreFootprintOp: &xdr.RestoreFootprintOp{},
+			}}},
+			Ext: xdr.TransactionExt{V: 1, SorobanData: &xdr.SorobanTransactionData{
+				Resources: xdr.SorobanResources{Footprint: xdr.LedgerFootprint{ReadWrite: []xdr.LedgerKey{key}}},
+			}},
+		}},
+	}
+	envXdr, err := xdr.MarshalBase64(env)
+	require.NoError(t, err)
+
+	meta := encodeMeta(t, nil, xdr.LedgerEntryChanges{
+		state(ttlEntry(t, archived, 10)), updated(ttlEntry(t, archived, 2000)),
+	})
+	changes, err := FromTransaction(envXdr, meta)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, KindRestored, changes[0].Kind)
+	require.NotNil(t, changes[0].TTL.Target)
+	assert.Equal(t, key, *changes[0].TTL.Target)
+
+	s := changes[0].Describe(nil)
+	assert.Contains(t, FormatText([]Summary{s}), "ttl restored")
+
+	changes, err = FromTransaction("", meta)
+	require.NoError(t, err)
+	assert.Equal(t, KindUpdated, changes[0].Kind, "without the envelope the restore is not recognized")
+	assert.Contains(t, changes[0].Describe(nil).Key, "hash ")
+}
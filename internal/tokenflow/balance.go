@@ -0,0 +1,278 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package tokenflow
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/stellar/go-stellar-sdk/strkey"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// Balance is an account's holding of one token around the transaction.
+type Balance struct {
+	Account string
+	Token   Token
+	// Before and After are read from the balance ledger entries the
+	// transaction changed. They are nil when the entry is not in the meta,
+	// e.g. for a native payment replayed from the envelope alone.
+	Before *big.Int
+	After  *big.Int
+	// Net is what the account received minus what it sent.
+	Net *big.Int
+}
+
+// Change is After - Before when both are known, and Net otherwise.
+func (b Balance) Change() *big.Int {
+	if b.Before != nil && b.After != nil {
+		return new(big.Int).Sub(b.After, b.Before)
+	}
+	return new(big.Int).Set(b.Net)
+}
+
+// balanceEntry is a balance ledger entry before and after the transaction.
+// Contract balances are identified by tokenID, classic ones (accounts and
+// trustlines) by asset.
+type balanceEntry struct {
+	account string
+	tokenID string
+	asset   string
+	before  *big.Int
+	after   *big.Int
+}
+
+// extractBalanceEntries collects the native balances of accounts, the
+// trustline balances and the SEP-41 "Balance" contract data entries changed
+// by the transaction's operations. Transaction-level changes are skipped so
+// that fees do not show up as XLM movements.
+func extractBalanceEntries(tm xdr.TransactionMeta) []balanceEntry {
+	meta, _ := simulator.SplitMeta(tm)
+	var changes []xdr.LedgerEntryChange
+	for _, op := range meta.Operations {
+		changes = append(changes, op...)
+	}
+
+	index := map[balanceEntry]*balanceEntry{}
+	var out []*balanceEntry
+	get := func(id balanceEntry) *balanceEntry {
+		if e, ok := index[id]; ok {
+			return e
+		}
+		e := &id
+		index[id] = e
+		out = append(out, e)
+		return e
+	}
+
+	for _, c := range changes {
+		switch c.Type {
+		case xdr.LedgerEntryChangeTypeLedgerEntryState:
+			if id, amount, ok := balanceFromEntry(*c.State); ok {
+				if e := get(id); e.before == nil {
+					e.before = amount
+				}
+			}
+		case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+			if id, amount, ok := balanceFromEntry(*c.Created); ok {
+				e := get(id)
+				if e.before == nil {
+					e.before = new(big.Int)
+				}
+				e.after = amount
+			}
+		case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+			if id, amount, ok := balanceFromEntry(*c.Updated); ok {
+				get(id).after = amount
+			}
+		case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+			if id, ok := balanceFromKey(*c.Removed); ok {
+				get(id).after = new(big.Int)
+			}
+		}
+	}
+
+	entries := make([]balanceEntry, len(out))
+	for i, e := range out {
+		entries[i] = *e
+	}
+	return entries
+}
+
+// balanceFromEntry returns the identity (account, tokenID, asset) of a
+// balance entry and its amount.
+func balanceFromEntry(entry xdr.LedgerEntry) (balanceEntry, *big.Int, bool) {
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeAccount:
+		acc := entry.Data.MustAccount()
+		return balanceEntry{account: acc.AccountId.Address(), asset: "native"}, big.NewInt(int64(acc.Balance)), true
+	case xdr.LedgerEntryTypeTrustline:
+		tl := entry.Data.MustTrustLine()
+		asset, ok := trustLineAsset(tl.Asset)
+		if !ok {
+			return balanceEntry{}, nil, false
+		}
+		return balanceEntry{account: tl.AccountId.Address(), asset: asset}, big.NewInt(int64(tl.Balance)), true
+	case xdr.LedgerEntryTypeContractData:
+		cd := entry.Data.MustContractData()
+		id, ok := balanceHolder(cd.Contract, cd.Key)
+		if !ok {
+			return balanceEntry{}, nil, false
+		}
+		amount, ok := scValAmount(cd.Val)
+		return id, amount, ok
+	}
+	return balanceEntry{}, nil, false
+}
+
+func balanceFromKey(key xdr.LedgerKey) (balanceEntry, bool) {
+	switch key.Type {
+	case xdr.LedgerEntryTypeAccount:
+		return balanceEntry{account: key.MustAccount().AccountId.Address(), asset: "native"}, true
+	case xdr.LedgerEntryTypeTrustline:
+		tl := key.MustTrustLine()
+		asset, ok := trustLineAsset(tl.Asset)
+		return balanceEntry{account: tl.AccountId.Address(), asset: asset}, ok
+	case xdr.LedgerEntryTypeContractData:
+		cd := key.MustContractData()
+		return balanceHolder(cd.Contract, cd.Key)
+	}
+	return balanceEntry{}, false
+}
+
+// trustLineAsset names a trustline's asset as the Stellar Asset Contract
+// does ("CODE:ISSUER"). Liquidity pool shares are not tokens and are skipped.
+func trustLineAsset(a xdr.TrustLineAsset) (string, bool) {
+	if a.Type == xdr.AssetTypeAssetTypePoolShare {
+		return "", false
+	}
+	return a.ToAsset().StringCanonical(), true
+}
+
+// balanceHolder recognizes the SEP-41 balance key Vec[Symbol("Balance"), holder].
+func balanceHolder(contract xdr.ScAddress, key xdr.ScVal) (balanceEntry, bool) {
+	if contract.Type != xdr.ScAddressTypeScAddressTypeContract || contract.ContractId == nil {
+		return balanceEntry{}, false
+	}
+	if key.Type != xdr.ScValTypeScvVec || key.Vec == nil || *key.Vec == nil || len(**key.Vec) != 2 {
+		return balanceEntry{}, false
+	}
+	parts := **key.Vec
+	if sym, ok := scValSymbol(parts[0]); !ok || sym != "Balance" {
+		return balanceEntry{}, false
+	}
+	account, ok := scValAddressString(parts[1])
+	if !ok {
+		return balanceEntry{}, false
+	}
+	tokenID, err := strkey.Encode(strkey.VersionByteContract, contract.ContractId[:])
+	if err != nil {
+		return balanceEntry{}, false
+	}
+	return balanceEntry{account: account, tokenID: tokenID}, true
+}
+
+// tokenKeys identifies tokens across events and ledger entries: a Stellar
+// Asset Contract moves the balances of its classic asset, so it is keyed by
+// asset. assetOf maps the contract IDs whose events named an asset.
+type tokenKeys struct {
+	assetOf map[string]string
+}
+
+func newTokenKeys(raw []Transfer) tokenKeys {
+	k := tokenKeys{assetOf: map[string]string{}}
+	for _, t := range raw {
+		if t.Token.ID != "" && t.Token.Asset != "" {
+			k.assetOf[t.Token.ID] = t.Token.Asset
+		}
+	}
+	return k
+}
+
+func (k tokenKeys) of(t Token) string {
+	if t.Asset != "" {
+		return t.Asset
+	}
+	if asset := k.assetOf[t.ID]; asset != "" {
+		return asset
+	}
+	return t.ID
+}
+
+// entryToken is the token of a balance entry that no event mentioned.
+func (k tokenKeys) entryToken(e balanceEntry) Token {
+	switch {
+	case e.asset == "native":
+		return Token{Symbol: "XLM", Asset: "native"}
+	case e.asset != "":
+		code, _, _ := strings.Cut(e.asset, ":")
+		return Token{Symbol: code, Asset: e.asset, Decimals: 7}
+	case k.assetOf[e.tokenID] != "":
+		// A contract holder's balance in a Stellar Asset Contract.
+		return Token{ID: e.tokenID, Asset: k.assetOf[e.tokenID]}
+	}
+	return Token{ID: e.tokenID}
+}
+
+// buildBalances combines the net flows of every account with the balance
+// entries of the meta. Entries whose balance did not change and that have
+// no flows (e.g. a sequence number bump) are left out.
+func buildBalances(raw []Transfer, entries []balanceEntry) []Balance {
+	keys := newTokenKeys(raw)
+	type key struct{ account, token string }
+	index := map[key]*Balance{}
+	get := func(account string, token Token) *Balance {
+		k := key{account, keys.of(token)}
+		if b, ok := index[k]; ok {
+			return b
+		}
+		b := &Balance{Account: account, Token: token, Net: new(big.Int)}
+		index[k] = b
+		return b
+	}
+
+	for _, t := range raw {
+		if t.Kind == KindApprove || t.Amount == nil {
+			continue
+		}
+		if !isPseudoAccount(t.From) {
+			b := get(t.From, t.Token)
+			b.Net.Sub(b.Net, t.Amount)
+		}
+		if !isPseudoAccount(t.To) {
+			b := get(t.To, t.Token)
+			b.Net.Add(b.Net, t.Amount)
+		}
+	}
+
+	for _, e := range entries {
+		token := keys.entryToken(e)
+		b, moved := index[key{e.account, keys.of(token)}]
+		if !moved {
+			if e.before != nil && e.after != nil && e.before.Cmp(e.after) == 0 {
+				continue
+			}
+			b = get(e.account, token)
+		}
+		b.Before, b.After = e.before, e.after
+	}
+
+	out := make([]Balance, 0, len(index))
+	for _, b := range index {
+		out = append(out, *b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if ki, kj := keys.of(out[i].Token), keys.of(out[j].Token); ki != kj {
+			return ki < kj
+		}
+		return out[i].Account < out[j].Account
+	})
+	return out
+}
+
+func isPseudoAccount(s string) bool {
+	return s == "MINT" || s == "BURN" || s == "CLAWBACK"
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package tokenflow
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/rpc"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// LedgerReader fetches ledger entries. Keys and values are base64 XDR
+// LedgerKeys and LedgerEntries; keys that are not found are left out of the
+// result. *rpc.Client implements it.
+type LedgerReader interface {
+	GetLedgerEntries(ctx context.Context, keys []string) (map[string]string, error)
+}
+
+// Entries is a LedgerReader over a fixed set of ledger entries, such as a
+// snapshot file or the entries stored with a session.
+type Entries map[string]string
+
+// GetLedgerEntries returns the entries found for keys.
+func (e Entries) GetLedgerEntries(_ context.Context, keys []string) (map[string]string, error) {
+	out := make(map[string]string, len(keys))
+	for _, k := range keys {
+		if v, ok := e[k]; ok {
+			out[k] = v
+		}
+	}
+	return out, nil
+}
+
+// Metadata is what a SEP-41 token keeps under Symbol("METADATA") in its
+// instance storage.
+type Metadata struct {
+	Name     string
+	Symbol   string
+	Decimals uint32
+}
+
+// MetadataFromInstance reads the token metadata from a base64 contract
+// instance LedgerEntry.
+func MetadataFromInstance(entryXDR string) (Metadata, error) {
+	raw, err := base64.StdEncoding.DecodeString(entryXDR)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("decode instance entry: %w", err)
+	}
+	var entry xdr.LedgerEntry
+	if err := xdr.SafeUnmarshal(raw, &entry); err != nil {
+		return Metadata{}, fmt.Errorf("unmarshal ledger entry: %w", err)
+	}
+	cd, ok := entry.Data.GetContractData()
+	if !ok || cd.Val.Type != xdr.ScValTypeScvContractInstance || cd.Val.Instance == nil {
+		return Metadata{}, fmt.Errorf("ledger entry is not a contract instance")
+	}
+	storage := cd.Val.Instance.Storage
+	if storage == nil {
+		return Metadata{}, fmt.Errorf("contract instance has no storage")
+	}
+
+	for _, e := range *storage {
+		if sym, ok := scValSymbol(e.Key); !ok || sym != "METADATA" {
+			continue
+		}
+		if e.Val.Type != xdr.ScValTypeScvMap || e.Val.Map == nil || *e.Val.Map == nil {
+			return Metadata{}, fmt.Errorf("METADATA is not a map")
+		}
+		var md Metadata
+		for _, field := range **e.Val.Map {
+			name, _ := scValSymbol(field.Key)
+			switch name {
+			case "decimal":
+				if field.Val.Type == xdr.ScValTypeScvU32 && field.Val.U32 != nil {
+					md.Decimals = uint32(*field.Val.U32)
+				}
+			case "name":
+				md.Name = scValText(field.Val)
+			case "symbol":
+				md.Symbol = scValText(field.Val)
+			}
+		}
+		return md, nil
+	}
+	return Metadata{}, fmt.Errorf("contract instance has no METADATA entry")
+}
+
+// ResolveTokens fills in the symbol, name and decimals of every contract
+// token in the report from its metadata. Sources are tried in order, so a
+// local snapshot can be listed before an RPC client. Tokens that cannot be
+// resolved keep their contract ID; the last source error is returned if any
+// remain.
+func (r *Report) ResolveTokens(ctx context.Context, sources ...LedgerReader) error {
+	pending := map[string]string{} // instance ledger key -> contract ID
+	for _, id := range r.tokenIDs() {
+		cid, err := rpc.ParseContractID(id)
+		if err != nil {
+			continue
+		}
+		key, err := rpc.LedgerKeyForContractInstance(cid)
+		if err != nil {
+			continue
+		}
+		keyXDR, err := xdr.MarshalBase64(key)
+		if err != nil {
+			continue
+		}
+		pending[keyXDR] = id
+	}
+
+	resolved := map[string]Metadata{}
+	var lastErr error
+	for _, src := range sources {
+		if len(pending) == 0 {
+			break
+		}
+		keys := make([]string, 0, len(pending))
+		for k := range pending {
+			keys = append(keys, k)
+		}
+		entries, err := src.GetLedgerEntries(ctx, keys)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		for k, entryXDR := range entries {
+			id, ok := pending[k]
+			if !ok {
+				continue
+			}
+			md, err := MetadataFromInstance(entryXDR)
+			if err != nil {
+				lastErr = fmt.Errorf("token %s: %w", id, err)
+				continue
+			}
+			resolved[id] = md
+			delete(pending, k)
+		}
+	}
+
+	apply := func(t *Token) {
+		if md, ok := resolved[t.ID]; ok {
+			t.Symbol, t.Name, t.Decimals = md.Symbol, md.Name, md.Decimals
+		}
+	}
+	for i := range r.Raw {
+		apply(&r.Raw[i].Token)
+	}
+	for i := range r.Balances {
+		apply(&r.Balances[i].Token)
+	}
+	for i := range r.Discrepancies {
+		apply(&r.Discrepancies[i].Token)
+	}
+	r.Agg = aggregate(r.Raw)
+
+	if len(pending) > 0 {
+		return lastErr
+	}
+	return nil
+}
+
+func (r *Report) tokenIDs() []string {
+	seen := map[string]bool{}
+	var ids []string
+	add := func(t Token) {
+		if t.ID != "" && !seen[t.ID] {
+			seen[t.ID] = true
+			ids = append(ids, t.ID)
+		}
+	}
+	for _, t := range r.Raw {
+		add(t.Token)
+	}
+	for _, b := range r.Balances {
+		add(b.Token)
+	}
+	return ids
+}
+
+func scValText(v xdr.ScVal) string {
+	switch v.Type {
+	case xdr.ScValTypeScvString:
+		if v.Str != nil {
+			return string(*v.Str)
+		}
+	case xdr.ScValTypeScvSymbol:
+		if v.Sym != nil {
+			return string(*v.Sym)
+		}
+	}
+	return ""
+}
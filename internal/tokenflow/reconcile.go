@@ -0,0 +1,112 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package tokenflow
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// DiscrepancyKind classifies a mismatch between a token's events and the
+// balances it actually changed.
+type DiscrepancyKind string
+
+const (
+	// DiscrepancyUnreported is a balance change no event accounts for.
+	DiscrepancyUnreported DiscrepancyKind = "unreported-balance-change"
+	// DiscrepancyPhantom is an event-reported movement that left the
+	// balance entry unchanged.
+	DiscrepancyPhantom DiscrepancyKind = "event-without-balance-change"
+	// DiscrepancyMismatch is a balance change of a different amount than
+	// the events report.
+	DiscrepancyMismatch DiscrepancyKind = "amount-mismatch"
+)
+
+// Discrepancy is an account whose balance change disagrees with the events
+// of its token. A compliant token emits an event for every balance change,
+// so this points at a buggy or malicious token.
+type Discrepancy struct {
+	Kind    DiscrepancyKind
+	Account string
+	Token   Token
+	// Ledger is the balance change in the ledger entries.
+	Ledger *big.Int
+	// Events is the net movement the events report.
+	Events *big.Int
+}
+
+// Detail describes the discrepancy in one sentence.
+func (d Discrepancy) Detail() string {
+	tok := d.Token
+	switch d.Kind {
+	case DiscrepancyUnreported:
+		return fmt.Sprintf("%s balance of %s changed by %s with no event reporting it",
+			tok.Display(), d.Account, tok.FormatChange(d.Ledger))
+	case DiscrepancyPhantom:
+		return fmt.Sprintf("events move %s %s for %s but its balance did not change",
+			tok.FormatChange(d.Events), tok.Display(), d.Account)
+	default:
+		return fmt.Sprintf("%s balance of %s changed by %s but its events add up to %s",
+			tok.Display(), d.Account, tok.FormatChange(d.Ledger), tok.FormatChange(d.Events))
+	}
+}
+
+// reconcile compares each balance's ledger change with its event net flow.
+//
+// Only Soroban transactions are reconciled: their single host function
+// operation makes every operation-level balance change the work of
+// contracts, which must report it in events. Movements of accounts without
+// a balance entry in the meta are only flagged for tokens whose balance
+// layout is known, i.e. that changed some balance entry or are a Stellar
+// Asset Contract; the issuer of a classic asset holds no balance.
+func reconcile(raw []Transfer, entries []balanceEntry, balances []Balance) []Discrepancy {
+	keys := newTokenKeys(raw)
+	tracked := map[string]bool{}
+	for _, e := range entries {
+		tracked[keys.of(keys.entryToken(e))] = true
+	}
+
+	var out []Discrepancy
+	for _, b := range balances {
+		key := keys.of(b.Token)
+		if b.Before == nil || b.After == nil {
+			if b.Net.Sign() == 0 || !(tracked[key] || strings.Contains(key, ":")) || isIssuer(b.Account, key) {
+				continue
+			}
+			out = append(out, Discrepancy{Kind: DiscrepancyPhantom, Account: b.Account, Token: b.Token, Ledger: new(big.Int), Events: b.Net})
+			continue
+		}
+
+		change := new(big.Int).Sub(b.After, b.Before)
+		if change.Cmp(b.Net) == 0 {
+			continue
+		}
+		d := Discrepancy{Kind: DiscrepancyMismatch, Account: b.Account, Token: b.Token, Ledger: change, Events: b.Net}
+		switch {
+		case b.Net.Sign() == 0:
+			d.Kind = DiscrepancyUnreported
+		case change.Sign() == 0:
+			d.Kind = DiscrepancyPhantom
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// isIssuer reports whether account issues the classic asset "CODE:ISSUER".
+func isIssuer(account, asset string) bool {
+	_, issuer, ok := strings.Cut(asset, ":")
+	return ok && issuer == account
+}
+
+// isSorobanMeta reports whether the meta is that of a host function
+// invocation.
+func isSorobanMeta(tm xdr.TransactionMeta) bool {
+	meta, _ := simulator.SplitMeta(tm)
+	return meta.Soroban
+}
@@ -16,7 +16,25 @@ import (
 func (r *Report) SummaryLines() []string {
 	var lines []string
 	for _, t := range r.Agg {
-		lines = append(lines, fmt.Sprintf("%s -> %s %s -> %s", t.From, formatAmount(t), t.Token.Display(), t.To))
+		line := fmt.Sprintf("%s -> %s %s -> %s", t.From, formatAmount(t), t.Token.Display(), t.To)
+		if t.Kind == KindApprove {
+			line += " (allowance)"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// BalanceLines produces one line per account and token:
+//
+//	AccountA: 100 USDC -> 50 USDC (-50)
+//
+// Unknown balances are shown as "?" with the net flow as the change.
+func (r *Report) BalanceLines() []string {
+	var lines []string
+	for _, b := range r.Balances {
+		lines = append(lines, fmt.Sprintf("%s: %s -> %s %s (%s)", b.Account,
+			b.Token.FormatOptional(b.Before), b.Token.FormatOptional(b.After), b.Token.Display(), b.Token.FormatChange(b.Change())))
 	}
 	return lines
 }
@@ -43,35 +61,65 @@ func (r *Report) MermaidFlowchart() string {
 		from := getNode(t.From)
 		to := getNode(t.To)
 		label := fmt.Sprintf("%s %s", formatAmount(t), t.Token.Display())
-		b.WriteString(fmt.Sprintf("  %s -->|\"%s\"| %s\n", from, escapeMermaidLabel(label), to))
+		arrow := "-->"
+		if t.Kind == KindApprove {
+			label += " allowance"
+			arrow = "-.->"
+		}
+		b.WriteString(fmt.Sprintf("  %s %s|\"%s\"| %s\n", from, arrow, escapeMermaidLabel(label), to))
 	}
 
 	return b.String()
 }
 
 func formatAmount(t Transfer) string {
-	if t.Amount == nil {
-		return "0"
+	return t.Token.FormatAmount(t.Amount)
+}
+
+// FormatAmount renders an amount in smallest units with the token's
+// decimals. Tokens whose metadata was not resolved show the raw integer.
+func (t Token) FormatAmount(amount *big.Int) string {
+	if t.Symbol == "XLM" && t.ID == "" {
+		return formatStroopsAsXLM(amount)
+	}
+	return formatUnits(amount, t.Decimals)
+}
+
+// FormatOptional is FormatAmount with "?" for an unknown amount.
+func (t Token) FormatOptional(amount *big.Int) string {
+	if amount == nil {
+		return "?"
 	}
-	if t.Token.Symbol == "XLM" && t.Token.ID == "" {
-		return formatStroopsAsXLM(t.Amount)
+	return t.FormatAmount(amount)
+}
+
+// FormatChange is FormatAmount with an explicit sign.
+func (t Token) FormatChange(amount *big.Int) string {
+	if amount != nil && amount.Sign() > 0 {
+		return "+" + t.FormatAmount(amount)
 	}
-	// For SAC tokens we don't know decimals here; show raw integer.
-	return t.Amount.String()
+	return t.FormatAmount(amount)
 }
 
 func formatStroopsAsXLM(stroops *big.Int) string {
-	if stroops == nil {
+	return formatUnits(stroops, 7)
+}
+
+func formatUnits(amount *big.Int, decimals uint32) string {
+	if amount == nil {
 		return "0"
 	}
-	neg := stroops.Sign() < 0
-	n := new(big.Int).Abs(stroops)
+	if decimals == 0 {
+		return amount.String()
+	}
+	neg := amount.Sign() < 0
+	n := new(big.Int).Abs(amount)
 
-	scale := big.NewInt(10_000_000) // 7 decimals
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
 	intPart, frac := new(big.Int), new(big.Int)
 	intPart.DivMod(n, scale, frac)
 
-	fracStr := fmt.Sprintf("%07s", frac.String())
+	fracStr := fmt.Sprintf("%0*s", int(decimals), frac.String())
 	fracStr = strings.TrimRight(fracStr, "0")
 	if fracStr == "" {
 		if neg {
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/dotandev/hintents/internal/simulator"
 	"github.com/stellar/go-stellar-sdk/strkey"
 	"github.com/stellar/go-stellar-sdk/xdr"
 )
@@ -20,14 +21,25 @@ type Kind string
 const (
 	KindTransfer Kind = "transfer"
 	KindMint     Kind = "mint"
+	KindBurn     Kind = "burn"
+	KindClawback Kind = "clawback"
+	// KindApprove is an allowance grant. It moves no tokens and is left out
+	// of balances.
+	KindApprove Kind = "approve"
 )
 
 // Token identifies an asset.
-// - XLM: Symbol="XLM", ID=""
-// - SAC: Symbol="SAC" (best-effort), ID="C...." (contract id)
+//   - XLM: Symbol="XLM", ID="", Asset="native"
+//   - SEP-41 token: ID="C...." (contract id); Symbol, Name and Decimals are
+//     empty until resolved from the contract's metadata (see ResolveTokens)
+//   - Stellar Asset Contract: as SEP-41, with the classic asset its events
+//     name ("native" or "CODE:ISSUER") in Asset
 type Token struct {
-	Symbol string
-	ID     string
+	Symbol   string
+	ID       string
+	Name     string
+	Decimals uint32
+	Asset    string
 }
 
 func (t Token) Display() string {
@@ -45,12 +57,13 @@ func (t Token) Display() string {
 		id = id[:12] + "…"
 	}
 	if t.Symbol == "" {
-		return "SAC(" + id + ")"
+		return "TOKEN(" + id + ")"
 	}
 	return t.Symbol + "(" + id + ")"
 }
 
-// Transfer is a single token movement (or mint).
+// Transfer is a single token movement, mint, burn, clawback or approval.
+// Mints come from "MINT", burns and clawbacks go to "BURN" and "CLAWBACK".
 type Transfer struct {
 	From   string
 	To     string
@@ -63,13 +76,21 @@ type Transfer struct {
 type Report struct {
 	Raw []Transfer
 	Agg []Transfer
+	// Balances are the per-account balances of every token that moved.
+	Balances []Balance
+	// Discrepancies are the balances whose ledger change disagrees with
+	// the token's events. Only Soroban transactions are reconciled.
+	Discrepancies []Discrepancy
 }
 
-// BuildReport extracts transfers/mints from:
-// - native XLM payments in EnvelopeXdr
-// - Soroban SAC transfer/mint events from ResultMetaXdr diagnostic events
+// BuildReport extracts token movements from:
+//   - native XLM payments in EnvelopeXdr
+//   - SEP-41 transfer/mint/burn/clawback/approve events from ResultMetaXdr,
+//     emitted by the Stellar Asset Contract or any other compliant token
 func BuildReport(envelopeXdrB64, resultMetaXdrB64 string) (*Report, error) {
 	var raw []Transfer
+	var entries []balanceEntry
+	soroban := false
 
 	if envelopeXdrB64 != "" {
 		xlm, err := extractNativeXLMPayments(envelopeXdrB64)
@@ -80,17 +101,24 @@ func BuildReport(envelopeXdrB64, resultMetaXdrB64 string) (*Report, error) {
 	}
 
 	if resultMetaXdrB64 != "" {
-		sac, err := extractSACTransfersAndMints(resultMetaXdrB64)
+		rm, err := decodeResultMeta(resultMetaXdrB64)
 		if err != nil {
 			return nil, err
 		}
-		raw = append(raw, sac...)
+		raw = append(raw, extractTokenEvents(rm.TxApplyProcessing)...)
+		entries = extractBalanceEntries(rm.TxApplyProcessing)
+		soroban = isSorobanMeta(rm.TxApplyProcessing)
 	}
 
-	return &Report{
-		Raw: raw,
-		Agg: aggregate(raw),
-	}, nil
+	report := &Report{
+		Raw:      raw,
+		Agg:      aggregate(raw),
+		Balances: buildBalances(raw, entries),
+	}
+	if soroban {
+		report.Discrepancies = reconcile(raw, entries, report.Balances)
+	}
+	return report, nil
 }
 
 func extractNativeXLMPayments(envelopeXdrB64 string) ([]Transfer, error) {
@@ -153,7 +181,7 @@ func extractNativeXLMPayments(envelopeXdrB64 string) ([]Transfer, error) {
 		transfers = append(transfers, Transfer{
 			From:   opSource,
 			To:     to,
-			Token:  Token{Symbol: "XLM"},
+			Token:  Token{Symbol: "XLM", Asset: "native"},
 			Amount: amt,
 			Kind:   KindTransfer,
 		})
@@ -162,7 +190,7 @@ func extractNativeXLMPayments(envelopeXdrB64 string) ([]Transfer, error) {
 	return transfers, nil
 }
 
-func extractSACTransfersAndMints(resultMetaXdrB64 string) ([]Transfer, error) {
+func decodeResultMeta(resultMetaXdrB64 string) (*xdr.TransactionResultMeta, error) {
 	metaBytes, err := base64.StdEncoding.DecodeString(resultMetaXdrB64)
 	if err != nil {
 		return nil, fmt.Errorf("decode result_meta xdr base64: %w", err)
@@ -172,11 +200,17 @@ func extractSACTransfersAndMints(resultMetaXdrB64 string) ([]Transfer, error) {
 	if err := xdr.SafeUnmarshal(metaBytes, &rm); err != nil {
 		return nil, fmt.Errorf("unmarshal TransactionResultMeta: %w", err)
 	}
+	return &rm, nil
+}
 
-	diag := extractDiagnosticEvents(rm.TxApplyProcessing)
+// extractTokenEvents turns the SEP-41 events of every contract into
+// movements. Both the SEP-41 topic layout and the CAP-67 one used by the
+// Stellar Asset Contract (asset string as the last topic, map data) are
+// understood.
+func extractTokenEvents(tm xdr.TransactionMeta) []Transfer {
 	var out []Transfer
 
-	for _, de := range diag {
+	for _, de := range extractEvents(tm) {
 		// Avoid counting reverted calls.
 		if !de.InSuccessfulContractCall {
 			continue
@@ -193,7 +227,7 @@ func extractSACTransfersAndMints(resultMetaXdrB64 string) ([]Transfer, error) {
 		}
 
 		body, ok := ce.Body.GetV0()
-		if !ok || len(body.Topics) == 0 {
+		if !ok || len(body.Topics) < 2 {
 			continue
 		}
 
@@ -202,69 +236,99 @@ func extractSACTransfersAndMints(resultMetaXdrB64 string) ([]Transfer, error) {
 			continue
 		}
 
-		switch op {
-		case "transfer":
-			// Expected topics: ["transfer", from, to], data: amount
+		var from, to string
+		kind := Kind(op)
+		switch kind {
+		case KindTransfer, KindApprove:
+			// Topics: [op, from, to/spender, (asset)]
 			if len(body.Topics) < 3 {
 				continue
 			}
-			from, ok := scValAddressString(body.Topics[1])
-			if !ok {
-				continue
-			}
-			to, ok := scValAddressString(body.Topics[2])
-			if !ok {
+			var okFrom, okTo bool
+			from, okFrom = scValAddressString(body.Topics[1])
+			to, okTo = scValAddressString(body.Topics[2])
+			if !okFrom || !okTo {
 				continue
 			}
-			amt, ok := scValAmount(body.Data)
-			if !ok || amt.Sign() < 0 {
+		case KindMint:
+			// Topics: [mint, admin, to] (SEP-41) or [mint, to, (asset)]
+			from = "MINT"
+			if to, ok = lastAddress(body.Topics[1:]); !ok {
 				continue
 			}
-			out = append(out, Transfer{
-				From:   from,
-				To:     to,
-				Token:  Token{Symbol: "SAC", ID: contractStr},
-				Amount: amt,
-				Kind:   KindTransfer,
-			})
-		case "mint":
-			// Expected topics: ["mint", to], data: amount
-			if len(body.Topics) < 2 {
+		case KindBurn:
+			// Topics: [burn, from, (asset)]
+			to = "BURN"
+			if from, ok = scValAddressString(body.Topics[1]); !ok {
 				continue
 			}
-			to, ok := scValAddressString(body.Topics[1])
-			if !ok {
+		case KindClawback:
+			// Topics: [clawback, admin, from] (SEP-41) or [clawback, from, (asset)]
+			to = "CLAWBACK"
+			if from, ok = lastAddress(body.Topics[1:]); !ok {
 				continue
 			}
-			amt, ok := scValAmount(body.Data)
-			if !ok || amt.Sign() < 0 {
-				continue
-			}
-			out = append(out, Transfer{
-				From:   "MINT",
-				To:     to,
-				Token:  Token{Symbol: "SAC", ID: contractStr},
-				Amount: amt,
-				Kind:   KindMint,
-			})
+		default:
+			continue
 		}
+
+		amt, ok := scValAmount(body.Data)
+		if !ok || amt.Sign() < 0 {
+			continue
+		}
+		out = append(out, Transfer{
+			From:   from,
+			To:     to,
+			Token:  eventToken(contractStr, body.Topics),
+			Amount: amt,
+			Kind:   kind,
+		})
 	}
 
-	return out, nil
+	return out
 }
 
-func extractDiagnosticEvents(tm xdr.TransactionMeta) []xdr.DiagnosticEvent {
-	switch tm.V {
-	case 3:
-		if tm.V3 != nil && tm.V3.SorobanMeta != nil {
-			return tm.V3.SorobanMeta.DiagnosticEvents
-		}
-	case 4:
-		if tm.V4 != nil && len(tm.V4.DiagnosticEvents) > 0 {
-			return tm.V4.DiagnosticEvents
+// eventToken identifies the token of an event. The Stellar Asset Contract
+// names its classic asset in a trailing string topic; such tokens always
+// have 7 decimals.
+func eventToken(contractID string, topics []xdr.ScVal) Token {
+	token := Token{ID: contractID}
+	last := topics[len(topics)-1]
+	if last.Type != xdr.ScValTypeScvString || last.Str == nil {
+		return token
+	}
+	token.Asset = string(*last.Str)
+	token.Decimals = 7
+	token.Symbol, _, _ = strings.Cut(token.Asset, ":")
+	if token.Asset == "native" {
+		token.Symbol = "XLM"
+	}
+	return token
+}
+
+// extractEvents returns the diagnostic events of a transaction, which
+// include its contract events. Without diagnostics the contract events are
+// taken from the Soroban or operation meta instead.
+func extractEvents(tm xdr.TransactionMeta) []xdr.DiagnosticEvent {
+	meta, _ := simulator.SplitMeta(tm)
+	if len(meta.DiagnosticEvents) > 0 {
+		return meta.DiagnosticEvents
+	}
+
+	out := make([]xdr.DiagnosticEvent, len(meta.Events))
+	for i, e := range meta.Events {
+		out[i] = xdr.DiagnosticEvent{InSuccessfulContractCall: true, Event: e}
+	}
+	return out
+}
+
+func lastAddress(topics []xdr.ScVal) (string, bool) {
+	for i := len(topics) - 1; i >= 0; i-- {
+		if s, ok := scValAddressString(topics[i]); ok {
+			return s, true
 		}
 	}
-	return nil
+	return "", false
 }
 
 func scValSymbol(v xdr.ScVal) (string, bool) {
@@ -285,8 +349,26 @@ func scValAddressString(v xdr.ScVal) (string, bool) {
 	return s, true
 }
 
+// scValAmount reads an event amount: a plain integer, a map with an
+// "amount" entry (CAP-67 muxed transfers, SAC balances) or a vector whose
+// first element is the amount (approve's [amount, live_until_ledger]).
 func scValAmount(v xdr.ScVal) (*big.Int, bool) {
 	switch v.Type {
+	case xdr.ScValTypeScvMap:
+		if v.Map == nil || *v.Map == nil {
+			return nil, false
+		}
+		for _, e := range **v.Map {
+			if k, ok := scValSymbol(e.Key); ok && k == "amount" {
+				return scValAmount(e.Val)
+			}
+		}
+		return nil, false
+	case xdr.ScValTypeScvVec:
+		if v.Vec == nil || *v.Vec == nil || len(**v.Vec) == 0 {
+			return nil, false
+		}
+		return scValAmount((**v.Vec)[0])
 	case xdr.ScValTypeScvU64:
 		if v.U64 == nil {
 			return nil, false
@@ -333,16 +415,15 @@ func muxedAccountToAddress(a xdr.MuxedAccount) (string, error) {
 
 func aggregate(in []Transfer) []Transfer {
 	type key struct {
-		from string
-		to   string
-		kind Kind
-		sym  string
-		id   string
+		from  string
+		to    string
+		kind  Kind
+		token Token
 	}
 
 	m := map[key]*big.Int{}
 	for _, t := range in {
-		k := key{from: t.From, to: t.To, kind: t.Kind, sym: t.Token.Symbol, id: t.Token.ID}
+		k := key{from: t.From, to: t.To, kind: t.Kind, token: t.Token}
 		if m[k] == nil {
 			m[k] = new(big.Int)
 		}
@@ -355,7 +436,7 @@ func aggregate(in []Transfer) []Transfer {
 			From:   k.from,
 			To:     k.to,
 			Kind:   k.kind,
-			Token:  Token{Symbol: k.sym, ID: k.id},
+			Token:  k.token,
 			Amount: new(big.Int).Set(v),
 		})
 	}
@@ -4,8 +4,10 @@
 package tokenflow
 
 import (
+	"context"
 	"encoding/base64"
 	"math/big"
+	"sort"
 	"testing"
 
 	"github.com/stellar/go-stellar-sdk/strkey"
@@ -52,7 +54,7 @@ func TestBuildReport_SACTransferAndMint_FromResultMeta(t *testing.T) {
 
 	require.Equal(t, KindTransfer, r.Agg[0].Kind)
 	require.Equal(t, contractStr, r.Agg[0].Token.ID)
-	require.Equal(t, "SAC", r.Agg[0].Token.Symbol)
+	require.Equal(t, "", r.Agg[0].Token.Symbol)
 	require.Equal(t, addrString(fromAddr), r.Agg[0].From)
 	require.Equal(t, addrString(toAddr), r.Agg[0].To)
 	require.Equal(t, big.NewInt(50), r.Agg[0].Amount)
@@ -82,8 +84,227 @@ func TestBuildReport_NativeXLMPayment_FromEnvelope(t *testing.T) {
 	require.Equal(t, big.NewInt(12_345_678), tr.Amount)
 }
 
+func TestBuildReport_SEP41Events(t *testing.T) {
+	cid := xdr.ContractId(bytes32(0xBB))
+	admin := scAddressAccount(bytes32(0x01))
+	alice := scAddressAccount(bytes32(0x02))
+	bob := scAddressAccount(bytes32(0x03))
+	dex := scAddressAccount(bytes32(0x04))
+	asset := scString("USDC:GISSUER")
+
+	events := []xdr.DiagnosticEvent{
+		// SEP-41 mint names the admin first; CAP-67 appends the asset.
+		diagnosticEvent(cid, []xdr.ScVal{scSymbol("mint"), scAddress(admin), scAddress(alice)}, scI128(100), true),
+		diagnosticEvent(cid, []xdr.ScVal{scSymbol("mint"), scAddress(bob), asset}, scI128(1), true),
+		// CAP-67 transfer data may be a map with the amount.
+		diagnosticEvent(cid, []xdr.ScVal{scSymbol("transfer"), scAddress(alice), scAddress(bob), asset},
+			scMap(map[string]xdr.ScVal{"amount": scI128(30), "to_muxed_id": scU64(9)}), true),
+		diagnosticEvent(cid, []xdr.ScVal{scSymbol("burn"), scAddress(bob)}, scI128(5), true),
+		diagnosticEvent(cid, []xdr.ScVal{scSymbol("clawback"), scAddress(admin), scAddress(bob)}, scI128(2), true),
+		diagnosticEvent(cid, []xdr.ScVal{scSymbol("approve"), scAddress(alice), scAddress(dex)},
+			scVec(scI128(50), scU32(1000)), true),
+		// Reverted calls and unrelated events are ignored.
+		diagnosticEvent(cid, []xdr.ScVal{scSymbol("transfer"), scAddress(alice), scAddress(bob)}, scI128(999), false),
+		diagnosticEvent(cid, []xdr.ScVal{scSymbol("swap"), scAddress(alice)}, scI128(1), true),
+	}
+
+	r, err := BuildReport("", encodeResultMetaWithDiagnosticEvents(t, events))
+	require.NoError(t, err)
+	require.Len(t, r.Raw, 6)
+
+	want := []struct {
+		kind     Kind
+		from, to string
+		amount   int64
+	}{
+		{KindMint, "MINT", addrString(alice), 100},
+		{KindMint, "MINT", addrString(bob), 1},
+		{KindTransfer, addrString(alice), addrString(bob), 30},
+		{KindBurn, addrString(bob), "BURN", 5},
+		{KindClawback, addrString(bob), "CLAWBACK", 2},
+		{KindApprove, addrString(alice), addrString(dex), 50},
+	}
+	for i, w := range want {
+		require.Equal(t, w.kind, r.Raw[i].Kind, "movement %d", i)
+		require.Equal(t, w.from, r.Raw[i].From, "movement %d", i)
+		require.Equal(t, w.to, r.Raw[i].To, "movement %d", i)
+		require.Equal(t, big.NewInt(w.amount), r.Raw[i].Amount, "movement %d", i)
+	}
+
+	// Allowances do not change balances; without ledger entries only the
+	// net flow is known.
+	require.Len(t, r.Balances, 2)
+	balances := map[string]Balance{}
+	for _, b := range r.Balances {
+		balances[b.Account] = b
+	}
+	require.Nil(t, balances[addrString(alice)].Before)
+	require.Equal(t, big.NewInt(70), balances[addrString(alice)].Change())
+	require.Equal(t, big.NewInt(24), balances[addrString(bob)].Change())
+}
+
+func TestBuildReport_BalancesFromLedgerChanges(t *testing.T) {
+	cid := xdr.ContractId(bytes32(0xCC))
+	alice := scAddressAccount(bytes32(0x02))
+	bob := scAddressAccount(bytes32(0x03))
+
+	transfer := diagnosticEvent(cid, []xdr.ScVal{scSymbol("transfer"), scAddress(alice), scAddress(bob)}, scI128(40), true)
+	changes := xdr.LedgerEntryChanges{
+		stateChange(balanceLedgerEntry(cid, alice, scI128(100))),
+		updatedChange(balanceLedgerEntry(cid, alice, scI128(60))),
+		// A new holder's balance is created; SAC balances are maps.
+		createdChange(balanceLedgerEntry(cid, bob, scMap(map[string]xdr.ScVal{
+			"amount": scI128(40), "authorized": scBool(true), "clawback": scBool(false),
+		}))),
+	}
+
+	r, err := BuildReport("", encodeResultMeta(t, []xdr.DiagnosticEvent{transfer}, changes))
+	require.NoError(t, err)
+	require.Len(t, r.Balances, 2)
+
+	balances := map[string]Balance{}
+	for _, b := range r.Balances {
+		balances[b.Account] = b
+	}
+	a, b := balances[addrString(alice)], balances[addrString(bob)]
+	require.Equal(t, big.NewInt(100), a.Before)
+	require.Equal(t, big.NewInt(60), a.After)
+	require.Equal(t, big.NewInt(-40), a.Change())
+	require.Equal(t, big.NewInt(0), b.Before)
+	require.Equal(t, big.NewInt(40), b.After)
+	require.Empty(t, r.Discrepancies)
+}
+
+func TestBuildReport_Reconciliation(t *testing.T) {
+	token := xdr.ContractId(bytes32(0xEE))
+	sac := xdr.ContractId(bytes32(0xAC))
+	alice := scAddressAccount(bytes32(0x02))
+	bob := scAddressAccount(bytes32(0x03))
+	carol := scAddressAccount(bytes32(0x05))
+	issuer := bytes32(0x09)
+	usdc := xdr.MustNewCreditAsset("USDC", addrString(scAddressAccount(issuer)))
+	asset := scString(usdc.StringCanonical())
+
+	events := []xdr.DiagnosticEvent{
+		// The token reports 40 but moves 50 out of alice's balance.
+		diagnosticEvent(token, []xdr.ScVal{scSymbol("transfer"), scAddress(alice), scAddress(bob)}, scI128(40), true),
+		// The SAC moves USDC between trustlines as reported...
+		diagnosticEvent(sac, []xdr.ScVal{scSymbol("transfer"), scAddress(alice), scAddress(bob), asset}, scI128(7), true),
+		// ...but this event has no trustline change behind it.
+		diagnosticEvent(sac, []xdr.ScVal{scSymbol("transfer"), scAddress(alice), scAddress(carol), asset}, scI128(3), true),
+		// Mints from the issuer account change no issuer balance.
+		diagnosticEvent(sac, []xdr.ScVal{scSymbol("transfer"), scAddress(scAddressAccount(issuer)), scAddress(alice), asset}, scI128(10), true),
+	}
+	changes := xdr.LedgerEntryChanges{
+		stateChange(balanceLedgerEntry(token, alice, scI128(100))),
+		updatedChange(balanceLedgerEntry(token, alice, scI128(50))),
+		stateChange(balanceLedgerEntry(token, bob, scI128(0))),
+		updatedChange(balanceLedgerEntry(token, bob, scI128(40))),
+		// Carol's balance grows without any event.
+		stateChange(balanceLedgerEntry(token, carol, scI128(1))),
+		updatedChange(balanceLedgerEntry(token, carol, scI128(11))),
+		stateChange(trustLineEntry(alice, usdc, 100)),
+		updatedChange(trustLineEntry(alice, usdc, 100)),
+		stateChange(trustLineEntry(bob, usdc, 0)),
+		updatedChange(trustLineEntry(bob, usdc, 7)),
+	}
+
+	r, err := BuildReport("", encodeResultMeta(t, events, changes))
+	require.NoError(t, err)
+
+	got := map[string]Discrepancy{}
+	for _, d := range r.Discrepancies {
+		got[d.Token.Display()+" "+d.Account] = d
+	}
+	require.Len(t, got, 3, "%v", r.Discrepancies)
+
+	tokenName := Token{ID: mustContract(token)}.Display()
+	mismatch := got[tokenName+" "+addrString(alice)]
+	require.Equal(t, DiscrepancyMismatch, mismatch.Kind)
+	require.Equal(t, big.NewInt(-50), mismatch.Ledger)
+	require.Equal(t, big.NewInt(-40), mismatch.Events)
+	require.Contains(t, mismatch.Detail(), "changed by -50 but its events add up to -40")
+
+	unreported := got[tokenName+" "+addrString(carol)]
+	require.Equal(t, DiscrepancyUnreported, unreported.Kind)
+	require.Contains(t, unreported.Detail(), "changed by +10 with no event reporting it")
+
+	// Alice's trustline is unchanged: +10 from the issuer, -7 and -3 out.
+	phantom := got["USDC("+mustContract(sac)[:12]+"…) "+addrString(carol)]
+	require.Equal(t, DiscrepancyPhantom, phantom.Kind)
+	require.Equal(t, big.NewInt(3), phantom.Events)
+}
+
+func trustLineEntry(holder xdr.ScAddress, asset xdr.Asset, balance int64) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeTrustline,
+			TrustLine: &xdr.TrustLineEntry{
+				AccountId: *holder.AccountId,
+				Asset:     asset.ToTrustLineAsset(),
+				Balance:   xdr.Int64(balance),
+				Limit:     xdr.Int64(1 << 62),
+			},
+		},
+	}
+}
+
+func mustContract(id xdr.ContractId) string {
+	s, err := strkey.Encode(strkey.VersionByteContract, id[:])
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func TestResolveTokens(t *testing.T) {
+	cid := xdr.ContractId(bytes32(0xDD))
+	alice := scAddressAccount(bytes32(0x02))
+	bob := scAddressAccount(bytes32(0x03))
+	transfer := diagnosticEvent(cid, []xdr.ScVal{scSymbol("transfer"), scAddress(alice), scAddress(bob)}, scI128(12_500_000), true)
+
+	r, err := BuildReport("", encodeResultMetaWithDiagnosticEvents(t, []xdr.DiagnosticEvent{transfer}))
+	require.NoError(t, err)
+
+	// Unresolved tokens show the raw amount and the contract ID.
+	require.Contains(t, r.SummaryLines()[0], "12500000 TOKEN(C")
+
+	key, entry := instanceEntry(t, cid, map[string]xdr.ScVal{
+		"decimal": scU32(6),
+		"name":    scString("USD Coin"),
+		"symbol":  scString("USDC"),
+	})
+	require.NoError(t, r.ResolveTokens(context.Background(), Entries{}, Entries{key: entry}))
+
+	tr := r.Agg[0]
+	require.Equal(t, "USDC", tr.Token.Symbol)
+	require.Equal(t, "USD Coin", tr.Token.Name)
+	require.Equal(t, uint32(6), tr.Token.Decimals)
+	require.Contains(t, r.SummaryLines()[0], "-> 12.5 USDC(C")
+	require.Contains(t, r.BalanceLines()[0], "? -> ? USDC(")
+
+	// Missing metadata is reported but leaves the report usable.
+	r2, err := BuildReport("", encodeResultMetaWithDiagnosticEvents(t, []xdr.DiagnosticEvent{transfer}))
+	require.NoError(t, err)
+	require.Error(t, r2.ResolveTokens(context.Background(), Entries{key: "not-xdr"}))
+	require.Equal(t, "", r2.Agg[0].Token.Symbol)
+}
+
+func TestFormatUnits(t *testing.T) {
+	require.Equal(t, "1.5", formatUnits(big.NewInt(15), 1))
+	require.Equal(t, "-0.000001", formatUnits(big.NewInt(-1), 6))
+	require.Equal(t, "42", formatUnits(big.NewInt(42), 0))
+	require.Equal(t, "+1.2345678", Token{Symbol: "XLM"}.FormatChange(big.NewInt(12_345_678)))
+	require.Equal(t, "?", Token{}.FormatOptional(nil))
+}
+
 func encodeResultMetaWithDiagnosticEvents(t *testing.T, events []xdr.DiagnosticEvent) string {
 	t.Helper()
+	return encodeResultMeta(t, events, nil)
+}
+
+func encodeResultMeta(t *testing.T, events []xdr.DiagnosticEvent, changes xdr.LedgerEntryChanges) string {
+	t.Helper()
 
 	stm := xdr.SorobanTransactionMeta{
 		Ext:              xdr.SorobanTransactionMetaExt{V: 0},
@@ -95,7 +316,7 @@ func encodeResultMetaWithDiagnosticEvents(t *testing.T, events []xdr.DiagnosticE
 	tm3 := xdr.TransactionMetaV3{
 		Ext:             xdr.ExtensionPoint{V: 0},
 		TxChangesBefore: xdr.LedgerEntryChanges{},
-		Operations:      nil,
+		Operations:      []xdr.OperationMeta{{Changes: changes}},
 		TxChangesAfter:  xdr.LedgerEntryChanges{},
 		SorobanMeta:     &stm,
 	}
@@ -196,6 +417,112 @@ func scAddress(a xdr.ScAddress) xdr.ScVal {
 	return xdr.ScVal{Type: xdr.ScValTypeScvAddress, Address: &a}
 }
 
+func scU32(v uint32) xdr.ScVal {
+	u := xdr.Uint32(v)
+	return xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &u}
+}
+
+func scI128(v int64) xdr.ScVal {
+	parts := xdr.Int128Parts{Hi: xdr.Int64(0), Lo: xdr.Uint64(v)}
+	if v < 0 {
+		parts.Hi = -1
+	}
+	return xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &parts}
+}
+
+func scString(s string) xdr.ScVal {
+	str := xdr.ScString(s)
+	return xdr.ScVal{Type: xdr.ScValTypeScvString, Str: &str}
+}
+
+func scBool(b bool) xdr.ScVal {
+	return xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &b}
+}
+
+func scVec(vals ...xdr.ScVal) xdr.ScVal {
+	vec := xdr.ScVec(vals)
+	p := &vec
+	return xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: &p}
+}
+
+// scMap builds a map with symbol keys in sorted order, as the host does.
+func scMap(fields map[string]xdr.ScVal) xdr.ScVal {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	m := xdr.ScMap{}
+	for _, name := range names {
+		m = append(m, xdr.ScMapEntry{Key: scSymbol(name), Val: fields[name]})
+	}
+	p := &m
+	return xdr.ScVal{Type: xdr.ScValTypeScvMap, Map: &p}
+}
+
+func balanceLedgerEntry(contract xdr.ContractId, holder xdr.ScAddress, val xdr.ScVal) xdr.LedgerEntry {
+	cid := contract
+	return xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeContractData,
+			ContractData: &xdr.ContractDataEntry{
+				Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &cid},
+				Key:        scVec(scSymbol("Balance"), scAddress(holder)),
+				Durability: xdr.ContractDataDurabilityPersistent,
+				Val:        val,
+			},
+		},
+	}
+}
+
+// instanceEntry returns the base64 instance ledger key and entry of a
+// contract whose instance storage holds METADATA.
+func instanceEntry(t *testing.T, contract xdr.ContractId, metadata map[string]xdr.ScVal) (string, string) {
+	t.Helper()
+	cid := contract
+	addr := xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &cid}
+	storage := xdr.ScMap{{Key: scSymbol("METADATA"), Val: scMap(metadata)}}
+	entry := xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeContractData,
+			ContractData: &xdr.ContractDataEntry{
+				Contract:   addr,
+				Key:        xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance},
+				Durability: xdr.ContractDataDurabilityPersistent,
+				Val: xdr.ScVal{Type: xdr.ScValTypeScvContractInstance, Instance: &xdr.ScContractInstance{
+					Executable: xdr.ContractExecutable{Type: xdr.ContractExecutableTypeContractExecutableStellarAsset},
+					Storage:    &storage,
+				}},
+			},
+		},
+	}
+	key := xdr.LedgerKey{
+		Type: xdr.LedgerEntryTypeContractData,
+		ContractData: &xdr.LedgerKeyContractData{
+			Contract:   addr,
+			Key:        xdr.ScVal{Type: xdr.ScValTypeScvLedgerKeyContractInstance},
+			Durability: xdr.ContractDataDurabilityPersistent,
+		},
+	}
+	keyB64, err := xdr.MarshalBase64(key)
+	require.NoError(t, err)
+	entryB64, err := xdr.MarshalBase64(entry)
+	require.NoError(t, err)
+	return keyB64, entryB64
+}
+
+func stateChange(e xdr.LedgerEntry) xdr.LedgerEntryChange {
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &e}
+}
+
+func updatedChange(e xdr.LedgerEntry) xdr.LedgerEntryChange {
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &e}
+}
+
+func createdChange(e xdr.LedgerEntry) xdr.LedgerEntryChange {
+	return xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &e}
+}
+
 func scU64(v uint64) xdr.ScVal {
 	u := xdr.Uint64(v)
 	return xdr.ScVal{Type: xdr.ScValTypeScvU64, U64: &u}
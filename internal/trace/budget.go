@@ -0,0 +1,81 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import "fmt"
+
+// InvocationBudget is the budget before and after one contract call or host
+// function, mirroring simulator.InvocationBudget (to avoid an import cycle).
+type InvocationBudget struct {
+	Kind       string `json:"kind"`
+	ContractID string `json:"contract_id,omitempty"`
+	Function   string `json:"function"`
+	Depth      int    `json:"depth"`
+	CPUStart   uint64 `json:"cpu_start"`
+	CPUEnd     uint64 `json:"cpu_end"`
+	MemStart   uint64 `json:"mem_start"`
+	MemEnd     uint64 `json:"mem_end"`
+}
+
+// BuildBudgetTree rebuilds the invocation tree from budget snapshots listed
+// in the order the invocations started, with depth 1 for top-level calls, and
+// attributes their cost with AttributeBudget.
+func BuildBudgetTree(budgets []InvocationBudget) *TraceNode {
+	root := NewTraceNode("root", "transaction")
+	stack := []*TraceNode{root}
+	for i, b := range budgets {
+		for len(stack) > 1 && len(stack) > b.Depth {
+			stack = stack[:len(stack)-1]
+		}
+		node := NewTraceNode(fmt.Sprintf("%s-%d", b.Kind, i), b.Kind)
+		node.ContractID = b.ContractID
+		node.Function = b.Function
+		cpu, mem := sub(b.CPUEnd, b.CPUStart), sub(b.MemEnd, b.MemStart)
+		node.CPUInclusive, node.MemoryInclusive = &cpu, &mem
+		stack[len(stack)-1].AddChild(node)
+		stack = append(stack, node)
+	}
+	AttributeBudget(root)
+	return root
+}
+
+// AttributeBudget fills in the exclusive cost (CPUDelta, MemoryDelta) of every
+// node that has an inclusive cost, as its inclusive cost minus that of its
+// children. Nodes without an inclusive cost, like the root, get the sum of
+// their children's.
+func AttributeBudget(n *TraceNode) {
+	var childCPU, childMem uint64
+	measured := false
+	for _, c := range n.Children {
+		AttributeBudget(c)
+		if c.CPUInclusive != nil {
+			childCPU += *c.CPUInclusive
+			measured = true
+		}
+		if c.MemoryInclusive != nil {
+			childMem += *c.MemoryInclusive
+		}
+	}
+	if n.CPUInclusive == nil {
+		if !measured {
+			return
+		}
+		n.CPUInclusive, n.MemoryInclusive = &childCPU, &childMem
+		return
+	}
+	selfCPU := sub(*n.CPUInclusive, childCPU)
+	n.CPUDelta = &selfCPU
+	if n.MemoryInclusive != nil {
+		selfMem := sub(*n.MemoryInclusive, childMem)
+		n.MemoryDelta = &selfMem
+	}
+}
+
+// sub subtracts without wrapping around when snapshots are inconsistent.
+func sub(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTraceNode_BudgetMetrics(t *testing.T) {
@@ -79,3 +80,52 @@ func TestTraceNode_BudgetMetrics_NestedNodes(t *testing.T) {
 	assert.NotNil(t, flattened[2].CPUDelta)
 	assert.Equal(t, uint64(200000), *flattened[2].CPUDelta)
 }
+
+func TestBuildBudgetTree(t *testing.T) {
+	root := BuildBudgetTree([]InvocationBudget{
+		{Kind: "contract_call", ContractID: "CPOOL", Function: "swap", Depth: 1, CPUStart: 100, CPUEnd: 1_100, MemStart: 0, MemEnd: 400},
+		{Kind: "host_fn", Function: "require_auth", Depth: 2, CPUStart: 150, CPUEnd: 250, MemStart: 10, MemEnd: 60},
+		{Kind: "contract_call", ContractID: "CTOKEN", Function: "transfer", Depth: 2, CPUStart: 300, CPUEnd: 900, MemStart: 100, MemEnd: 300},
+		{Kind: "contract_call", ContractID: "CTOKEN", Function: "balance", Depth: 1, CPUStart: 1_200, CPUEnd: 1_500, MemStart: 400, MemEnd: 450},
+	})
+
+	require.Len(t, root.Children, 2)
+	swap := root.Children[0]
+	assert.Equal(t, "swap", swap.Function)
+	require.Len(t, swap.Children, 2)
+	assert.Equal(t, "host_fn", swap.Children[0].Type)
+	assert.Equal(t, 2, swap.Children[1].Depth)
+
+	assert.Equal(t, uint64(1_000), *swap.CPUInclusive)
+	assert.Equal(t, uint64(300), *swap.CPUDelta, "inclusive minus the 100 + 600 of its children")
+	assert.Equal(t, uint64(400), *swap.MemoryInclusive)
+	assert.Equal(t, uint64(150), *swap.MemoryDelta)
+
+	transfer := swap.Children[1]
+	assert.Equal(t, *transfer.CPUInclusive, *transfer.CPUDelta, "leaves cost only themselves")
+
+	assert.Equal(t, uint64(1_300), *root.CPUInclusive)
+	assert.Nil(t, root.CPUDelta)
+}
+
+func TestBuildBudgetTree_InconsistentSnapshots(t *testing.T) {
+	root := BuildBudgetTree([]InvocationBudget{
+		{Kind: "contract_call", Function: "outer", Depth: 1, CPUStart: 0, CPUEnd: 100},
+		{Kind: "contract_call", Function: "inner", Depth: 2, CPUStart: 0, CPUEnd: 500},
+	})
+	assert.Equal(t, uint64(0), *root.Children[0].CPUDelta, "must not wrap around")
+}
+
+func TestTreeRenderer_CostBars(t *testing.T) {
+	root := BuildBudgetTree([]InvocationBudget{
+		{Kind: "contract_call", Function: "swap", Depth: 1, CPUEnd: 1_000},
+		{Kind: "contract_call", Function: "transfer", Depth: 2, CPUEnd: 500},
+	})
+	tr := NewTreeRenderer(120, 20)
+	tr.RenderTree(root)
+
+	nodes := tr.GetAllNodes()
+	require.Len(t, nodes, 3)
+	assert.Contains(t, nodes[0].DisplayText, "██████████ 1000 insns")
+	assert.Contains(t, nodes[2].DisplayText, "█████░░░░░ 500 insns")
+}
@@ -0,0 +1,35 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+// Cost returns the CPU instructions and memory bytes recorded for this step
+// in HostState. Older traces only carry "gas_used", which is reported as CPU.
+func (s *ExecutionState) Cost() (cpu, mem int64) {
+	cpu = hostStateInt(s.HostState, "cpu_instructions", "cpu_insns", "gas_used")
+	mem = hostStateInt(s.HostState, "memory_bytes", "mem_bytes")
+	if cpu < 0 {
+		cpu = 0
+	}
+	if mem < 0 {
+		mem = 0
+	}
+	return cpu, mem
+}
+
+// hostStateInt returns the first of the given keys that holds a number, or 0.
+func hostStateInt(hostState map[string]interface{}, keys ...string) int64 {
+	for _, k := range keys {
+		switch n := hostState[k].(type) {
+		case float64:
+			return int64(n)
+		case int:
+			return int64(n)
+		case int64:
+			return n
+		case uint64:
+			return int64(n)
+		}
+	}
+	return 0
+}
@@ -6,6 +6,8 @@ package trace
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -25,7 +27,10 @@ type ExecutionState struct {
 	HostState       map[string]interface{} `json:"host_state,omitempty"`
 	Memory          map[string]interface{} `json:"memory,omitempty"`
 	WasmInstruction string                 `json:"wasm_instruction,omitempty"`
-
+	WasmOffset      *uint64                `json:"wasm_offset,omitempty"` // Code offset of WasmInstruction, for DWARF lookups
+	SourceFile      string                 `json:"source_file,omitempty"`
+	SourceLine      int                    `json:"source_line,omitempty"`
+	GitHubLink      string                 `json:"github_link,omitempty"`
 }
 
 // DefaultSnapshotInterval is the number of steps between state snapshots.
@@ -301,20 +306,59 @@ func copyMap(original map[string]interface{}) map[string]interface{} {
 }
 
 func (t *ExecutionTrace) getCurrentCallStack() []string {
-	// Extract call stack from current states
+	return t.CallStackAt(t.CurrentStep)
+}
+
+// CallStackAt reconstructs the contract call stack at the given step, outermost
+// frame first. Each frame is formatted as "contractID::function".
+func (t *ExecutionTrace) CallStackAt(step int) []string {
 	var stack []string
-	for i := 0; i <= t.CurrentStep && i < len(t.States); i++ {
-		state := &t.States[i]
-		if state.Function != "" {
-			entry := fmt.Sprintf("%s::%s", state.ContractID, state.Function)
-			if len(stack) == 0 || stack[len(stack)-1] != entry {
-				stack = append(stack, entry)
+	for i := 0; i <= step && i < len(t.States); i++ {
+		stack = AdvanceCallStack(stack, &t.States[i])
+	}
+	return stack
+}
+
+// AdvanceCallStack applies one state to a call stack. A call state pushes a
+// frame, so recursive and re-entrant calls each get their own, and a
+// "fn_return" state pops the frame of the function it returns from along with
+// any frames above it that ended without a return, or the innermost frame
+// when that function is not on the stack. All other states run inside the
+// current frame and leave the stack unchanged.
+func AdvanceCallStack(stack []string, state *ExecutionState) []string {
+	if state.Function == "" {
+		return stack
+	}
+	entry := fmt.Sprintf("%s::%s", state.ContractID, state.Function)
+	if state.Operation == "fn_return" {
+		// Clip so a later push does not overwrite a stack the caller kept.
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i] == entry {
+				return slices.Clip(stack[:i])
 			}
 		}
+		if len(stack) > 0 {
+			return slices.Clip(stack[:len(stack)-1])
+		}
+		return stack
+	}
+	if opensFrame(state) {
+		return append(stack, entry)
 	}
 	return stack
 }
 
+// opensFrame reports whether a state is the start of a contract call: a
+// "fn_call" event, a contract call or init operation, or a state explicitly
+// typed as a contract call.
+func opensFrame(state *ExecutionState) bool {
+	switch strings.ToLower(strings.TrimSpace(state.Operation)) {
+	case "fn_call", "contract_call", "contract_init":
+		return true
+	}
+	return state.EventType != "" && normalizeEventType(state.EventType) == EventTypeContractCall
+}
+
 // FilteredStepForward moves to the next step that matches the given event type filter.
 // If filter is empty, behaves like StepForward. Returns the new state or error.
 func (t *ExecutionTrace) FilteredStepForward(filter string) (*ExecutionState, error) {
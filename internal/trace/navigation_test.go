@@ -4,6 +4,7 @@
 package trace
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -460,3 +461,33 @@ func TestExecutionTrace_LazySnapshotMaterializesOnRead(t *testing.T) {
 		t.Errorf("expected HostState[v]=7, got %v", v)
 	}
 }
+
+func TestCallStackAt(t *testing.T) {
+	tr := NewExecutionTrace("tx", 10)
+	tr.AddState(ExecutionState{Operation: "contract_call", ContractID: "A", Function: "swap"})
+	tr.AddState(ExecutionState{Operation: "contract_call", ContractID: "B", Function: "transfer"})
+	tr.AddState(ExecutionState{Operation: "host_function", Function: "require_auth"})
+	tr.AddState(ExecutionState{Operation: "fn_return", ContractID: "B", Function: "transfer"})
+	// A re-entrant call gets its own frame.
+	tr.AddState(ExecutionState{Operation: "fn_call", ContractID: "A", Function: "swap"})
+	tr.AddState(ExecutionState{Operation: "step", ContractID: "A", Function: "swap"})
+	tr.AddState(ExecutionState{Operation: "contract_call", ContractID: "C", Function: "mint"})
+	// Returning from the inner swap also drops mint, which never returned.
+	tr.AddState(ExecutionState{Operation: "fn_return", ContractID: "A", Function: "swap"})
+
+	want := [][]string{
+		{"A::swap"},
+		{"A::swap", "B::transfer"},
+		{"A::swap", "B::transfer"},
+		{"A::swap"},
+		{"A::swap", "A::swap"},
+		{"A::swap", "A::swap"},
+		{"A::swap", "A::swap", "C::mint"},
+		{"A::swap"},
+	}
+	for step, w := range want {
+		if got := tr.CallStackAt(step); !slices.Equal(got, w) {
+			t.Errorf("CallStackAt(%d) = %v, want %v", step, got, w)
+		}
+	}
+}
@@ -18,8 +18,11 @@ type TraceNode struct {
 	Parent      *TraceNode   // Parent node (nil for root)
 	Expanded    bool         // Whether this node is expanded in the UI
 	SourceRef   *SourceRef   // Optional source mapping from WASM debug info; nil if unknown
-	CPUDelta    *uint64      // CPU instructions consumed by this node (nil if not tracked)
-	MemoryDelta *uint64      // Memory bytes consumed by this node (nil if not tracked)
+	CPUDelta    *uint64      // CPU instructions consumed by this node, excluding children (nil if not tracked)
+	MemoryDelta *uint64      // Memory bytes consumed by this node, excluding children (nil if not tracked)
+
+	CPUInclusive    *uint64 // CPU instructions consumed by this node and its children (nil if not tracked)
+	MemoryInclusive *uint64 // Memory bytes consumed by this node and its children (nil if not tracked)
 }
 
 // NewTraceNode creates a new trace node
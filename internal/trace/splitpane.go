@@ -26,6 +26,13 @@ type SourceRef struct {
 	Line     int // 1-based; 0 = unknown
 	Column   int // 1-based; 0 = unknown
 	Function string
+	URL      string // Link to the line in the source repository; empty if unknown
+}
+
+// SourceLocator resolves a contract function to its source when the trace
+// carries no line information, e.g. from verified registry sources.
+type SourceLocator interface {
+	LocateFunction(contractID, function string) (SourceRef, bool)
 }
 
 // SourceContext holds a windowed slice of source lines for the lower pane.
@@ -221,12 +228,21 @@ func nodeDisplayLines(node *TraceNode) []string {
 	}
 	indent := strings.Repeat("  ", node.Depth)
 	out = append(out, fmt.Sprintf("Depth:    %s%d", indent, node.Depth))
+	if node.CPUInclusive != nil && node.CPUDelta != nil {
+		out = append(out, fmt.Sprintf("CPU:      %d insns (self %d)", *node.CPUInclusive, *node.CPUDelta))
+	}
+	if node.MemoryInclusive != nil && node.MemoryDelta != nil {
+		out = append(out, fmt.Sprintf("Memory:   %d bytes (self %d)", *node.MemoryInclusive, *node.MemoryDelta))
+	}
 	if node.SourceRef != nil {
 		loc := fmt.Sprintf("%s:%d", node.SourceRef.File, node.SourceRef.Line)
 		if node.SourceRef.Column > 0 {
 			loc = fmt.Sprintf("%s:%d:%d", node.SourceRef.File, node.SourceRef.Line, node.SourceRef.Column)
 		}
 		out = append(out, fmt.Sprintf("Source:   %s", visualizer.Colorize(loc, "dim")))
+		if node.SourceRef.URL != "" {
+			out = append(out, fmt.Sprintf("Link:     %s", visualizer.Colorize(node.SourceRef.URL, "dim")))
+		}
 	}
 	return out
 }
@@ -0,0 +1,175 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import (
+	"github.com/dotandev/hintents/internal/dwarf"
+)
+
+// SourcePosition is where a trace step sits in the source code.
+type SourcePosition struct {
+	File     string
+	Line     int
+	Column   int
+	Function string
+	// Depth is the contract call depth plus the number of inlined frames
+	// active at the step; stepping compares depths to detect calls and returns.
+	Depth int
+	// Inlined is the inlined call chain at the step, outermost first.
+	Inlined []InlinedFrame
+}
+
+func (p *SourcePosition) sameLine(o *SourcePosition) bool {
+	return p.File == o.File && p.Line == o.Line
+}
+
+// LineStepper moves through a recorded trace one source line at a time, in
+// the manner of gdb's next, step and finish. Positions come from the DWARF
+// line tables when a step records its WASM offset, and from the step's own
+// SourceFile and SourceLine otherwise.
+type LineStepper struct {
+	trace     *ExecutionTrace
+	parser    *dwarf.Parser
+	positions []*SourcePosition
+}
+
+// NewLineStepper creates a stepper for t. parser may be nil, in which case
+// only source lines recorded in the trace are used.
+func NewLineStepper(t *ExecutionTrace, parser *dwarf.Parser) *LineStepper {
+	return &LineStepper{trace: t, parser: parser}
+}
+
+// PositionAt returns the source position of a step, or nil when the step has
+// no line information.
+func (s *LineStepper) PositionAt(step int) *SourcePosition {
+	if s.positions == nil {
+		s.resolve()
+	}
+	if step < 0 || step >= len(s.positions) {
+		return nil
+	}
+	return s.positions[step]
+}
+
+// resolve computes the position of every step in one pass, tracking the call
+// stack as it goes.
+func (s *LineStepper) resolve() {
+	s.positions = make([]*SourcePosition, len(s.trace.States))
+	var stack []string
+	for i := range s.trace.States {
+		state := &s.trace.States[i]
+		stack = AdvanceCallStack(stack, state)
+		s.positions[i] = s.position(state, len(stack))
+	}
+}
+
+func (s *LineStepper) position(state *ExecutionState, depth int) *SourcePosition {
+	if s.parser != nil && state.WasmOffset != nil {
+		addr := *state.WasmOffset
+		if loc, err := s.parser.GetSourceLocation(addr); err == nil && loc != nil {
+			pos := &SourcePosition{
+				File:     loc.File,
+				Line:     loc.Line,
+				Column:   loc.Column,
+				Function: state.Function,
+				Depth:    depth,
+			}
+			if sp, err := s.parser.FindSubprogramAt(addr); err == nil {
+				pos.Function = displayName(sp.Name, sp.DemangledName)
+			}
+			if frames := inlinedFrames(s.parser, addr); len(frames) > 0 {
+				pos.Inlined = frames
+				pos.Depth += len(frames)
+				pos.Function = frames[len(frames)-1].Function
+			}
+			return pos
+		}
+	}
+	if state.SourceFile != "" && state.SourceLine > 0 {
+		return &SourcePosition{
+			File:     state.SourceFile,
+			Line:     state.SourceLine,
+			Function: state.Function,
+			Depth:    depth,
+		}
+	}
+	return nil
+}
+
+// NextLine returns the next step on a different source line in the current
+// frame or a caller, stepping over calls and inlined bodies.
+func (s *LineStepper) NextLine(from int) (int, bool) {
+	cur := s.PositionAt(from)
+	if cur == nil {
+		return s.StepIn(from)
+	}
+	for i := from + 1; i < len(s.trace.States); i++ {
+		p := s.PositionAt(i)
+		if p == nil || p.Depth > cur.Depth {
+			continue
+		}
+		if p.Depth < cur.Depth || !p.sameLine(cur) {
+			return i, true
+		}
+	}
+	return from, false
+}
+
+// StepIn returns the next step on a different source line, entering calls
+// and inlined bodies.
+func (s *LineStepper) StepIn(from int) (int, bool) {
+	cur := s.PositionAt(from)
+	for i := from + 1; i < len(s.trace.States); i++ {
+		p := s.PositionAt(i)
+		if p == nil {
+			continue
+		}
+		if cur == nil || p.Depth != cur.Depth || !p.sameLine(cur) {
+			return i, true
+		}
+	}
+	return from, false
+}
+
+// StepOut returns the first step after the current frame, or inlined body,
+// has returned to its caller.
+func (s *LineStepper) StepOut(from int) (int, bool) {
+	cur := s.PositionAt(from)
+	if cur == nil {
+		return from, false
+	}
+	for i := from + 1; i < len(s.trace.States); i++ {
+		if p := s.PositionAt(i); p != nil && p.Depth < cur.Depth {
+			return i, true
+		}
+	}
+	return from, false
+}
+
+// LocalsAt returns the local variables in scope at a step, from the DWARF
+// info of the subprogram containing the step's WASM offset.
+func (s *LineStepper) LocalsAt(step int) []LocalVarInfo {
+	if s.parser == nil || step < 0 || step >= len(s.trace.States) {
+		return nil
+	}
+	state := &s.trace.States[step]
+	if state.WasmOffset == nil {
+		return nil
+	}
+	vars, err := s.parser.FindLocalVarsAt(*state.WasmOffset)
+	if err != nil {
+		return nil
+	}
+	out := make([]LocalVarInfo, 0, len(vars))
+	for _, lv := range vars {
+		out = append(out, LocalVarInfo{
+			Name:          lv.Name,
+			DemangledName: lv.DemangledName,
+			Type:          lv.Type,
+			Location:      lv.Location,
+			Value:         lv.Value,
+		})
+	}
+	return out
+}
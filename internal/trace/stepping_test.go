@@ -0,0 +1,90 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package trace
+
+import "testing"
+
+// lineTrace builds a trace where main calls helper: steps 0-1 and 6-7 are in
+// main, 2-4 in helper and step 5 is helper's return.
+func lineTrace() *ExecutionTrace {
+	t := NewExecutionTrace("tx", 10)
+	steps := []ExecutionState{
+		{Operation: "fn_call", Function: "main", SourceFile: "lib.rs", SourceLine: 10},
+		{Function: "main", SourceFile: "lib.rs", SourceLine: 11},
+		{Operation: "fn_call", Function: "helper", SourceFile: "lib.rs", SourceLine: 3},
+		{Function: "helper", SourceFile: "lib.rs", SourceLine: 3},
+		{Function: "helper", SourceFile: "lib.rs", SourceLine: 4},
+		{Operation: "fn_return", Function: "helper"},
+		{Function: "main", SourceFile: "lib.rs", SourceLine: 11},
+		{Function: "main", SourceFile: "lib.rs", SourceLine: 12},
+	}
+	for i, s := range steps {
+		s.Step = i
+		if s.Operation == "" {
+			s.Operation = "step"
+		}
+		t.AddState(s)
+	}
+	return t
+}
+
+func TestLineStepper_NextLineStepsOverCalls(t *testing.T) {
+	s := NewLineStepper(lineTrace(), nil)
+
+	got, ok := s.NextLine(0)
+	if !ok || got != 1 {
+		t.Errorf("NextLine(0) = %d, %v; want 1, true", got, ok)
+	}
+	// Line 11 calls helper; next runs through the call and the rest of
+	// line 11 to stop at line 12.
+	got, ok = s.NextLine(1)
+	if !ok || got != 7 {
+		t.Errorf("NextLine(1) = %d, %v; want 7, true", got, ok)
+	}
+	if _, ok := s.NextLine(7); ok {
+		t.Error("NextLine at the last step should not move")
+	}
+}
+
+func TestLineStepper_StepInEntersCalls(t *testing.T) {
+	s := NewLineStepper(lineTrace(), nil)
+
+	got, ok := s.StepIn(1)
+	if !ok || got != 2 {
+		t.Errorf("StepIn(1) = %d, %v; want 2, true", got, ok)
+	}
+	// Steps 2 and 3 share a line.
+	got, ok = s.StepIn(2)
+	if !ok || got != 4 {
+		t.Errorf("StepIn(2) = %d, %v; want 4, true", got, ok)
+	}
+}
+
+func TestLineStepper_StepOut(t *testing.T) {
+	s := NewLineStepper(lineTrace(), nil)
+
+	got, ok := s.StepOut(3)
+	if !ok || got != 6 {
+		t.Errorf("StepOut(3) = %d, %v; want 6, true", got, ok)
+	}
+	if _, ok := s.StepOut(0); ok {
+		t.Error("StepOut from the outermost frame should not move")
+	}
+}
+
+func TestLineStepper_SkipsStepsWithoutLines(t *testing.T) {
+	tr := NewExecutionTrace("tx", 10)
+	tr.AddState(ExecutionState{Step: 0, Function: "main", SourceFile: "lib.rs", SourceLine: 1})
+	tr.AddState(ExecutionState{Step: 1, Function: "require_auth", EventType: EventTypeHostFunction})
+	tr.AddState(ExecutionState{Step: 2, Function: "main", SourceFile: "lib.rs", SourceLine: 2})
+
+	s := NewLineStepper(tr, nil)
+	if s.PositionAt(1) != nil {
+		t.Error("expected no position for a step without line information")
+	}
+	got, ok := s.NextLine(0)
+	if !ok || got != 2 {
+		t.Errorf("NextLine(0) = %d, %v; want 2, true", got, ok)
+	}
+}
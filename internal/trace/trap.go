@@ -31,12 +31,13 @@ const (
 
 // TrapInfo contains information about a trap that occurred during execution
 type TrapInfo struct {
-	Type           TrapType               // Type of trap
-	Message        string                 // Error message
-	SourceLocation *dwarf.SourceLocation  // Source location if available
-	LocalVars      []LocalVarInfo         // Local variables at trap point
-	Function       string                 // Function where trap occurred
-	CallStack      []string               // Call stack at trap point
+	Type           TrapType              // Type of trap
+	Message        string                // Error message
+	SourceLocation *dwarf.SourceLocation // Source location if available
+	SourceURL      string                // Link to SourceLocation in the source repository, if known
+	LocalVars      []LocalVarInfo        // Local variables at trap point
+	Function       string                // Function where trap occurred
+	CallStack      []string              // Call stack at trap point
 	// InlinedChain holds the resolved inlined-subroutine chain from outermost
 	// to innermost when the fault occurred inside compiler-inlined code.
 	// When non-empty the innermost entry describes the actual fault site and
@@ -56,12 +57,6 @@ type InlinedFrame struct {
 	// InlinedAt is the location inside the inlined function's own source
 	// where execution was when the fault occurred.
 	InlinedAt dwarf.SourceLocation
-	Type           TrapType              // Type of trap
-	Message        string                // Error message
-	SourceLocation *dwarf.SourceLocation // Source location if available
-	LocalVars      []LocalVarInfo        // Local variables at trap point
-	Function       string                // Function where trap occurred
-	CallStack      []string              // Call stack at trap point
 }
 
 // LocalVarInfo represents a local variable with its value at trap time
@@ -78,6 +73,7 @@ type LocalVarInfo struct {
 type TrapDetector struct {
 	dwarfParser *dwarf.Parser
 	wasmData    []byte
+	sources     SourceLocator
 }
 
 // NewTrapDetector creates a new trap detector
@@ -97,6 +93,12 @@ func NewTrapDetector(wasmData []byte) (*TrapDetector, error) {
 	return td, nil
 }
 
+// SetSourceLocator sets a fallback used to place traps in source when the
+// WASM carries no debug info.
+func (td *TrapDetector) SetSourceLocator(l SourceLocator) {
+	td.sources = l
+}
+
 // DetectTrap detects if the given state represents a trap
 func (td *TrapDetector) DetectTrap(state *ExecutionState) *TrapInfo {
 	if state == nil || state.Error == "" {
@@ -137,6 +139,17 @@ func (td *TrapDetector) DetectTrap(state *ExecutionState) *TrapInfo {
 		}
 	}
 
+	if trap.SourceLocation == nil && td.sources != nil && state.Function != "" {
+		if ref, ok := td.sources.LocateFunction(state.ContractID, state.Function); ok {
+			trap.SourceLocation = &dwarf.SourceLocation{
+				File:   ref.File,
+				Line:   ref.Line,
+				Column: ref.Column,
+			}
+			trap.SourceURL = ref.URL
+		}
+	}
+
 	return trap
 }
 
@@ -249,19 +262,10 @@ func (td *TrapDetector) resolveInlinedChain(trap *TrapInfo, addr uint64, sp *dwa
 		return
 	}
 
-	chain, err := td.dwarfParser.ResolveInlinedChain(addr)
-	if err != nil || len(chain) == 0 {
+	frames := inlinedFrames(td.dwarfParser, addr)
+	if len(frames) == 0 {
 		return
 	}
-
-	frames := make([]InlinedFrame, 0, len(chain))
-	for _, il := range chain {
-		frames = append(frames, InlinedFrame{
-			Function:  displayName(il.Name, il.DemangledName),
-			CallSite:  il.CallSite,
-			InlinedAt: il.InlinedLocation,
-		})
-	}
 	trap.InlinedChain = frames
 
 	// Point SourceLocation at the innermost inlined body so the fault site is
@@ -283,6 +287,24 @@ func (td *TrapDetector) resolveInlinedChain(trap *TrapInfo, addr uint64, sp *dwa
 	}
 }
 
+// inlinedFrames returns the inlined call chain active at addr, outermost
+// first, or nil when addr is not inside inlined code.
+func inlinedFrames(p *dwarf.Parser, addr uint64) []InlinedFrame {
+	chain, err := p.ResolveInlinedChain(addr)
+	if err != nil || len(chain) == 0 {
+		return nil
+	}
+	frames := make([]InlinedFrame, 0, len(chain))
+	for _, il := range chain {
+		frames = append(frames, InlinedFrame{
+			Function:  displayName(il.Name, il.DemangledName),
+			CallSite:  il.CallSite,
+			InlinedAt: il.InlinedLocation,
+		})
+	}
+	return frames
+}
+
 // displayName returns DemangledName when non-empty, falling back to Name.
 func displayName(name, demangled string) string {
 	if demangled != "" {
@@ -354,6 +376,11 @@ func FormatTrapInfo(trap *TrapInfo) string {
 		}
 		sb.WriteString(fmt.Sprintf("%d", trap.SourceLocation.Line))
 		sb.WriteString("\n")
+		if trap.SourceURL != "" {
+			sb.WriteString(visualizer.Symbol("link") + " Source: ")
+			sb.WriteString(trap.SourceURL)
+			sb.WriteString("\n")
+		}
 	}
 
 	// Function
@@ -488,6 +515,10 @@ func toString(v interface{}) string {
 			return "true"
 		}
 		return "false"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(val)
+	case fmt.Stringer:
+		return val.String()
 	default:
 		return "<complex>"
 	}
@@ -4,6 +4,7 @@
 package trace
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -569,3 +570,54 @@ func containsAt(s, substr string) bool {
 	}
 	return false
 }
+
+type stubLocator map[string]SourceRef
+
+func (s stubLocator) LocateFunction(contractID, function string) (SourceRef, bool) {
+	ref, ok := s[contractID+"::"+function]
+	return ref, ok
+}
+
+func TestDetectTrap_SourceLocatorFallback(t *testing.T) {
+	td := &TrapDetector{}
+	td.SetSourceLocator(stubLocator{
+		"CABC::transfer": {File: "src/contract.rs", Line: 42, URL: "https://github.com/o/r/blob/abc/src/contract.rs#L42"},
+	})
+
+	trap := td.DetectTrap(&ExecutionState{ContractID: "CABC", Function: "transfer", Error: "panic"})
+	if trap.SourceLocation == nil {
+		t.Fatal("expected source location from locator")
+	}
+	if trap.SourceLocation.File != "src/contract.rs" || trap.SourceLocation.Line != 42 {
+		t.Errorf("SourceLocation = %s:%d, want src/contract.rs:42", trap.SourceLocation.File, trap.SourceLocation.Line)
+	}
+	if trap.SourceURL == "" {
+		t.Error("expected SourceURL to be set")
+	}
+
+	trap = td.DetectTrap(&ExecutionState{ContractID: "CABC", Function: "mint", Error: "panic"})
+	if trap.SourceLocation != nil {
+		t.Error("expected no source location for an unknown function")
+	}
+}
+
+func TestFormatTrapInfo_DecodedValues(t *testing.T) {
+	trap := &TrapInfo{
+		Type:    TrapPanic,
+		Message: "panic",
+		LocalVars: []LocalVarInfo{
+			{DemangledName: "amount", Type: "i128", Value: int64(-5)},
+			{DemangledName: "to", Type: "&str", Value: "GABC"},
+			{DemangledName: "p", Type: "Point", Value: dwarf.StructValue{
+				Type:   "Point",
+				Fields: []dwarf.FieldValue{{Name: "x", Value: uint64(1)}},
+			}},
+		},
+	}
+	out := FormatTrapInfo(trap)
+	for _, want := range []string{"amount: i128 = -5", `to: &str = "GABC"`, "p: Point = Point { x: 1 }"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
@@ -25,8 +25,12 @@ type TreeRenderer struct {
 	screenWidth  int
 	screenHeight int
 	scrollOffset int
+	maxCPU       uint64 // CPU of the most expensive node, the full width of a cost bar
 }
 
+// costBarWidth is the number of cells in a cost bar.
+const costBarWidth = 10
+
 // NewTreeRenderer creates a new tree renderer
 func NewTreeRenderer(screenWidth, screenHeight int) *TreeRenderer {
 	return &TreeRenderer{
@@ -41,6 +45,14 @@ func NewTreeRenderer(screenWidth, screenHeight int) *TreeRenderer {
 // RenderTree builds the tree UI nodes from a trace node
 func (tr *TreeRenderer) RenderTree(root *TraceNode) {
 	tr.nodes = make([]*TreeUINode, 0)
+	tr.maxCPU = 0
+	if root != nil {
+		for _, n := range root.FlattenAll() {
+			if cpu, ok := nodeCPU(n); ok && cpu > tr.maxCPU {
+				tr.maxCPU = cpu
+			}
+		}
+	}
 	tr.renderNode(root, 0)
 }
 
@@ -75,6 +87,9 @@ func (tr *TreeRenderer) renderNode(node *TraceNode, indentLevel int) {
 	if node.Error != "" {
 		nodeDesc = fmt.Sprintf("%s [ERROR: %s]", nodeDesc, node.Error)
 	}
+	if cpu, ok := nodeCPU(node); ok && tr.maxCPU > 0 {
+		nodeDesc = fmt.Sprintf("%s %s", nodeDesc, costBar(cpu, tr.maxCPU))
+	}
 
 	// Build indent
 	indent := strings.Repeat("  ", indentLevel)
@@ -91,6 +106,28 @@ func (tr *TreeRenderer) renderNode(node *TraceNode, indentLevel int) {
 	}
 }
 
+// nodeCPU returns the CPU instructions of a node including its children,
+// falling back to its own when only those are known.
+func nodeCPU(n *TraceNode) (uint64, bool) {
+	switch {
+	case n.CPUInclusive != nil:
+		return *n.CPUInclusive, true
+	case n.CPUDelta != nil:
+		return *n.CPUDelta, true
+	}
+	return 0, false
+}
+
+// costBar renders cpu as a bar relative to scale, followed by the instructions.
+func costBar(cpu, scale uint64) string {
+	filled := int(cpu * costBarWidth / scale)
+	if filled == 0 && cpu > 0 {
+		filled = 1
+	}
+	return fmt.Sprintf("%s%s %d insns",
+		strings.Repeat("█", filled), strings.Repeat("░", costBarWidth-filled), cpu)
+}
+
 // HandleMouseClick processes mouse clicks at the given column and row
 // Returns true if a node was toggled
 func (tr *TreeRenderer) HandleMouseClick(col, row int) bool {
@@ -24,7 +24,10 @@ type InteractiveViewer struct {
 	filterCycle []string // order for cycling: off, trap, contract_call, host_function, auth
 	hideStdLib  bool
 	trap        *TrapInfo
+	detector    *TrapDetector
 	dwarfParser *dwarf.Parser
+	sources     SourceLocator
+	stepper     *LineStepper
 }
 
 // NewInteractiveViewer creates a new interactive trace viewer
@@ -37,8 +40,8 @@ func NewInteractiveViewer(trace *ExecutionTrace) *InteractiveViewer {
 	}
 
 	// Detect any traps in the trace
-	detector := &TrapDetector{}
-	viewer.trap = detector.FindTrapPoint(trace)
+	viewer.detector = &TrapDetector{}
+	viewer.trap = viewer.detector.FindTrapPoint(trace)
 
 	return viewer
 }
@@ -61,12 +64,23 @@ func NewInteractiveViewerWithWASM(trace *ExecutionTrace, wasmData []byte) *Inter
 	}
 
 	// Detect any traps in the trace
-	detector := &TrapDetector{}
-	viewer.trap = detector.FindTrapPoint(trace)
+	viewer.detector = &TrapDetector{dwarfParser: viewer.dwarfParser, wasmData: wasmData}
+	viewer.trap = viewer.detector.FindTrapPoint(trace)
 
 	return viewer
 }
 
+// SetSourceLocator resolves source for steps and traps that carry no line
+// information of their own.
+func (v *InteractiveViewer) SetSourceLocator(l SourceLocator) {
+	v.sources = l
+	if v.detector == nil {
+		v.detector = &TrapDetector{dwarfParser: v.dwarfParser}
+	}
+	v.detector.SetSourceLocator(l)
+	v.trap = v.detector.FindTrapPoint(v.trace)
+}
+
 // Start begins the interactive trace viewing session.
 // It installs a terminal-resize handler so that long contract IDs and XDR
 // strings reflow correctly whenever the window size changes.
@@ -158,6 +172,12 @@ func (v *InteractiveViewer) handleCommand(command string) bool {
 		v.stepForward()
 	case "p", "prev", "back", "backward":
 		v.stepBackward()
+	case "nl", "next-line":
+		v.stepLine(lineStepNext)
+	case "si", "step-in":
+		v.stepLine(lineStepIn)
+	case "so", "step-out", "finish":
+		v.stepLine(lineStepOut)
 	case "f", "filter":
 		v.cycleEventFilter()
 	case "j", "jump":
@@ -350,6 +370,11 @@ func (v *InteractiveViewer) displayCurrentState() {
 		if state.GitHubLink != "" {
 			fmt.Printf("%s GitHub: %s\n", visualizer.Symbol("link"), state.GitHubLink)
 		}
+	} else if ref := v.locateSource(state); ref != nil {
+		fmt.Printf("%s Source: %s:%d\n", visualizer.Symbol("file"), ref.File, ref.Line)
+		if ref.URL != "" {
+			fmt.Printf("%s GitHub: %s\n", visualizer.Symbol("link"), ref.URL)
+		}
 	}
 
 	// Show memory/state summary
@@ -531,6 +556,80 @@ func (v *InteractiveViewer) listSteps(countStr string) {
 	}
 }
 
+// Line stepping modes for stepLine.
+const (
+	lineStepNext = "next-line"
+	lineStepIn   = "step-in"
+	lineStepOut  = "step-out"
+)
+
+// stepLine moves to the next stop of a source-level step and shows the
+// source line and locals there.
+func (v *InteractiveViewer) stepLine(mode string) {
+	if v.stepper == nil {
+		v.stepper = NewLineStepper(v.trace, v.dwarfParser)
+	}
+	from := v.trace.CurrentStep
+	var to int
+	var ok bool
+	switch mode {
+	case lineStepNext:
+		to, ok = v.stepper.NextLine(from)
+	case lineStepIn:
+		to, ok = v.stepper.StepIn(from)
+	case lineStepOut:
+		to, ok = v.stepper.StepOut(from)
+	}
+	if !ok {
+		if v.stepper.PositionAt(from) == nil && mode == lineStepOut {
+			fmt.Printf("%s No source line at step %d; cannot step out\n", visualizer.Warning(), from)
+		} else {
+			fmt.Printf("%s No further source line to stop at\n", visualizer.Warning())
+		}
+		return
+	}
+	if _, err := v.trace.JumpToStep(to); err != nil {
+		fmt.Printf("%s %s\n", visualizer.Error(), err)
+		return
+	}
+	v.showSourceStop(to)
+}
+
+// showSourceStop prints a gdb-style stop: the function and location, any
+// inlined frames, the source line and the locals in scope.
+func (v *InteractiveViewer) showSourceStop(step int) {
+	pos := v.stepper.PositionAt(step)
+	if pos == nil {
+		return
+	}
+	fn := pos.Function
+	if fn == "" {
+		fn = "??"
+	}
+	fmt.Printf("%s step %d  %s () at %s:%d\n", visualizer.Symbol("arrow_r"), step, visualizer.Colorize(fn, "yellow"), pos.File, pos.Line)
+	for i, frame := range pos.Inlined {
+		fmt.Printf("   %s inlined #%d %s at %s:%d\n", visualizer.Colorize("|", "dim"), i, frame.Function, frame.CallSite.File, frame.CallSite.Line)
+	}
+
+	if src, err := LoadSourceContext(SourceRef{File: pos.File, Line: pos.Line}, 1); err == nil && len(src.Lines) > 0 {
+		fmt.Printf("%-6d%s\n", pos.Line, src.Lines[src.FocusIndex])
+	}
+
+	if locals := v.stepper.LocalsAt(step); len(locals) > 0 {
+		fmt.Println("Locals:")
+		for _, lv := range locals {
+			name := displayName(lv.Name, lv.DemangledName)
+			line := fmt.Sprintf("  %s: %s", name, lv.Type)
+			if lv.Value != nil {
+				line += " = " + formatVarValue(lv.Value)
+			} else if lv.Location != "" {
+				line += " @ " + lv.Location
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
 // showSplitPane renders the horizontal split-pane view for the current step.
 func (v *InteractiveViewer) showSplitPane() {
 	state, err := v.trace.GetCurrentState()
@@ -539,6 +638,9 @@ func (v *InteractiveViewer) showSplitPane() {
 		return
 	}
 	node := executionStateToNode(state)
+	if node.SourceRef == nil {
+		node.SourceRef = v.locateSource(state)
+	}
 	var src *SourceContext
 	if node.SourceRef != nil {
 		src, _ = LoadSourceContext(*node.SourceRef, defaultRadius)
@@ -558,9 +660,30 @@ func executionStateToNode(state *ExecutionState) *TraceNode {
 		node.Error = state.Error
 		node.Type = "error"
 	}
+	if state.SourceFile != "" && state.SourceLine > 0 {
+		node.SourceRef = &SourceRef{
+			File:     state.SourceFile,
+			Line:     state.SourceLine,
+			Function: state.Function,
+			URL:      state.GitHubLink,
+		}
+	}
 	return node
 }
 
+// locateSource asks the viewer's SourceLocator for the definition of the
+// step's function. Returns nil when no locator is set or nothing matched.
+func (v *InteractiveViewer) locateSource(state *ExecutionState) *SourceRef {
+	if v.sources == nil || state.Function == "" {
+		return nil
+	}
+	ref, ok := v.sources.LocateFunction(state.ContractID, state.Function)
+	if !ok {
+		return nil
+	}
+	return &ref
+}
+
 // showHelp displays available keyboard shortcuts
 func (v *InteractiveViewer) showHelp() {
 	termW := getTermWidth()
@@ -570,6 +693,9 @@ func (v *InteractiveViewer) showHelp() {
 	fmt.Println("  n, next, forward        - Step forward")
 	fmt.Println("  p, prev, back           - Step backward")
 	fmt.Println("  j, jump <step>          - Jump to specific step")
+	fmt.Println("  nl, next-line           - Step to the next source line, over calls")
+	fmt.Println("  si, step-in             - Step to the next source line, into calls and inlined code")
+	fmt.Println("  so, step-out, finish    - Run until the current function returns")
 	fmt.Println()
 	fmt.Println("Display:")
 	fmt.Println("  s, show, state          - Show current state")
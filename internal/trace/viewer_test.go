@@ -63,3 +63,15 @@ func TestInteractiveViewer_HandleCommand_HelpAlias(t *testing.T) {
 		t.Errorf("help alias '?' did not display help overlay: %s", out)
 	}
 }
+
+func TestInteractiveViewer_SetSourceLocatorKeepsDetector(t *testing.T) {
+	v := NewInteractiveViewer(&ExecutionTrace{})
+	detector := v.detector
+	v.SetSourceLocator(stubLocator{})
+	if v.detector != detector {
+		t.Fatal("expected the existing trap detector to be kept")
+	}
+	if v.detector.sources == nil {
+		t.Error("expected the locator to be set on the detector")
+	}
+}
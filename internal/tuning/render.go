@@ -0,0 +1,40 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package tuning
+
+import "fmt"
+
+// Lines renders the recommendation: declared against tuned resources, the
+// fee difference, the most expensive calls and what the run did not report.
+func (r *Recommendation) Lines() []string {
+	lines := []string{
+		fmt.Sprintf("%-16s %12s %12s", "", "declared", "recommended"),
+		row("instructions", uint64(r.Declared.Instructions), uint64(r.Tuned.Instructions)),
+		row("disk read bytes", uint64(r.Declared.DiskReadBytes), uint64(r.Tuned.DiskReadBytes)),
+		row("write bytes", uint64(r.Declared.WriteBytes), uint64(r.Tuned.WriteBytes)),
+		row("read entries", uint64(r.Declared.ReadEntries), uint64(r.Tuned.ReadEntries)),
+		row("write entries", uint64(r.Declared.WriteEntries), uint64(r.Tuned.WriteEntries)),
+		fmt.Sprintf("Instructions include a %d%% margin. Resource fee ~%d -> ~%d stroops (%+d); declared fee %d -> %d stroops",
+			r.MarginPercent, r.DeclaredFee, r.TunedFee, r.FeeDelta(), r.Declared.ResourceFee, r.Tuned.ResourceFee),
+	}
+
+	if len(r.Hotspots) > 0 {
+		lines = append(lines, "Top calls by CPU:")
+	}
+	for _, h := range r.Hotspots {
+		name := h.Function
+		if h.ContractID != "" {
+			name = h.ContractID + "::" + h.Function
+		}
+		lines = append(lines, fmt.Sprintf("  %5.1f%%  %d insns (self %d)  %s", h.Percent, h.CPU, h.Self, name))
+	}
+	for _, w := range r.Warnings {
+		lines = append(lines, "Warning: "+w)
+	}
+	return lines
+}
+
+func row(name string, declared, tuned uint64) string {
+	return fmt.Sprintf("%-16s %12d %12d", name, declared, tuned)
+}
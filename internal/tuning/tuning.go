@@ -0,0 +1,183 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tuning recommends the SorobanResources a transaction should declare,
+// sized from the budget of a simulated run, prices them against the declared
+// resources and shows which contract calls the instructions went to.
+package tuning
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dotandev/hintents/internal/footprint"
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/dotandev/hintents/internal/trace"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+// DefaultTopCalls is the number of hotspots reported by default.
+const DefaultTopCalls = 5
+
+// Options tune Recommend.
+type Options struct {
+	// MarginPercent is added to the measured instructions; 0 selects
+	// footprint.DefaultMarginPercent and a negative value none.
+	MarginPercent int
+	// TopCalls is the number of hotspots to report; 0 selects
+	// DefaultTopCalls.
+	TopCalls int
+}
+
+// Hotspot is the CPU cost of one contract call.
+type Hotspot struct {
+	ContractID string `json:"contract_id,omitempty"`
+	Function   string `json:"function"`
+	Depth      int    `json:"depth"`
+	// CPU includes the calls it made; Self does not.
+	CPU  uint64 `json:"cpu"`
+	Self uint64 `json:"self"`
+	// Percent is CPU as a share of the instructions of the whole run.
+	Percent float64 `json:"percent"`
+}
+
+// Recommendation compares the declared resources with the ones a run needs.
+type Recommendation struct {
+	MarginPercent int                 `json:"margin_percent"`
+	Declared      footprint.Resources `json:"declared"`
+	Tuned         footprint.Resources `json:"tuned"`
+	// DeclaredFee and TunedFee price both sets of resources with
+	// simulator.EstimateResourceFee.
+	DeclaredFee int64     `json:"declared_fee"`
+	TunedFee    int64     `json:"tuned_fee"`
+	Hotspots    []Hotspot `json:"hotspots,omitempty"`
+	// Warnings name the figures the run did not report, which the tuned
+	// resources could not be measured from.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// FeeDelta is the change in resource fee of declaring the tuned resources;
+// negative when the declaration can shrink.
+func (r *Recommendation) FeeDelta() int64 {
+	return r.TunedFee - r.DeclaredFee
+}
+
+// Recommend sizes the resources of the transaction in envelopeXdr from its
+// simulated run resp. entries are the ledger entries the run was given; the
+// recorded footprint of the run is used when it reported one, the declared
+// footprint otherwise. calls is the call tree of the run, may be nil. Figures
+// the run did not report are named in the Warnings of the recommendation.
+func Recommend(envelopeXdr string, resp *simulator.SimulationResponse, entries map[string]string, calls *trace.TraceNode, opts Options) (*Recommendation, error) {
+	if resp == nil || resp.BudgetUsage == nil {
+		return nil, fmt.Errorf("the simulation did not report budget usage")
+	}
+	declared, err := simulator.DecodeSorobanData(envelopeXdr)
+	if err != nil {
+		return nil, err
+	}
+	margin := opts.MarginPercent
+	if margin == 0 {
+		margin = footprint.DefaultMarginPercent
+	}
+	if margin < 0 {
+		margin = 0
+	}
+	top := opts.TopCalls
+	if top == 0 {
+		top = DefaultTopCalls
+	}
+
+	var warnings []string
+	fp := declared.Resources.Footprint
+	if resp.Footprint != nil {
+		if fp, err = footprint.DecodeRecorded(resp.Footprint); err != nil {
+			return nil, err
+		}
+	}
+	if resp.LedgerChanges == nil && len(fp.ReadWrite) > 0 {
+		warnings = append(warnings, "The simulation did not report ledger changes; write bytes are sized from the entries before the run")
+	}
+	tuned, err := footprint.SizeResources(fp, entries, resp.LedgerChanges, resp.BudgetUsage.CPUInstructions, margin)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &Recommendation{
+		MarginPercent: margin,
+		Declared:      footprint.DeclaredResources(*declared),
+		Tuned:         tuned,
+		Warnings:      warnings,
+	}
+	rec.DeclaredFee = simulator.EstimateResourceFee(rec.Declared.Usage())
+	rec.TunedFee = simulator.EstimateResourceFee(rec.Tuned.Usage())
+	// The model leaves out rent and the size fee, so the declared fee is
+	// adjusted by the difference rather than replaced.
+	rec.Tuned.ResourceFee = max(rec.Declared.ResourceFee+rec.FeeDelta(), 0)
+	if calls != nil {
+		rec.Hotspots = Hotspots(calls, resp.BudgetUsage.CPUInstructions, top)
+	}
+	if len(rec.Hotspots) == 0 {
+		rec.Warnings = append(rec.Warnings, "The simulation did not report per-call budgets; no hotspots are shown")
+	}
+	return rec, nil
+}
+
+// SorobanData returns the declared SorobanTransactionData of envelopeXdr with
+// the tuned resources, keeping the declared footprint.
+func (r *Recommendation) SorobanData(envelopeXdr string) (string, error) {
+	data, err := simulator.DecodeSorobanData(envelopeXdr)
+	if err != nil {
+		return "", err
+	}
+	out := *data
+	out.ResourceFee = xdr.Int64(r.Tuned.ResourceFee)
+	out.Resources.Instructions = xdr.Uint32(r.Tuned.Instructions)
+	out.Resources.DiskReadBytes = xdr.Uint32(r.Tuned.DiskReadBytes)
+	out.Resources.WriteBytes = xdr.Uint32(r.Tuned.WriteBytes)
+	return xdr.MarshalBase64(out)
+}
+
+// Hotspots ranks the contract calls of a call tree by the instructions spent
+// in them, including their sub-calls. Node CPUDelta values are the node's own
+// cost; calls without any cost are left out. total is the instructions of the
+// whole run, used for the percentages.
+func Hotspots(root *trace.TraceNode, total uint64, n int) []Hotspot {
+	var out []Hotspot
+	var walk func(node *trace.TraceNode) uint64
+	walk = func(node *trace.TraceNode) uint64 {
+		// Reserve the slot first so calls keep the order they were made in.
+		idx := -1
+		if node.Type == "contract_call" {
+			idx = len(out)
+			out = append(out, Hotspot{ContractID: node.ContractID, Function: node.Function, Depth: node.Depth})
+		}
+		var self uint64
+		if node.CPUDelta != nil {
+			self = *node.CPUDelta
+		}
+		inclusive := self
+		for _, c := range node.Children {
+			inclusive += walk(c)
+		}
+		if idx >= 0 {
+			out[idx].CPU, out[idx].Self = inclusive, self
+			if total > 0 {
+				out[idx].Percent = float64(inclusive) / float64(total) * 100
+			}
+		}
+		return inclusive
+	}
+	walk(root)
+
+	ranked := out[:0]
+	for _, h := range out {
+		if h.CPU > 0 {
+			ranked = append(ranked, h)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].CPU > ranked[j].CPU })
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package tuning
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/simulator"
+	"github.com/dotandev/hintents/internal/trace"
+	"github.com/stellar/go-stellar-sdk/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const source = "GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7"
+
+func balanceKey() xdr.LedgerKey {
+	var id xdr.ContractId
+	id[0] = 0xab
+	sym := xdr.ScSymbol("balance")
+	return xdr.LedgerKey{Type: xdr.LedgerEntryTypeContractData, ContractData: &xdr.LedgerKeyContractData{
+		Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &id},
+		Key:        xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &sym},
+		Durability: xdr.ContractDataDurabilityPersistent,
+	}}
+}
+
+func envelope(t *testing.T) string {
+	t.Helper()
+	env := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{Tx: xdr.Transaction{
+			SourceAccount: xdr.MustMuxedAddress(source),
+			Fee:           100_000,
+			Operations: []xdr.Operation{{Body: xdr.OperationBody{
+				Type: xdr.OperationTypeInvokeHostFunction,
+				InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{HostFunction: xdr.HostFunction{
+					Type: xdr.HostFunctionTypeHostFunctionTypeUploadContractWasm, Wasm: &[]byte{},
+				}},
+			}}},
+			Ext: xdr.TransactionExt{V: 1, SorobanData: &xdr.SorobanTransactionData{
+				ResourceFee: 200_000,
+				Resources: xdr.SorobanResources{
+					Footprint:     xdr.LedgerFootprint{ReadWrite: []xdr.LedgerKey{balanceKey()}},
+					Instructions:  10_000_000,
+					DiskReadBytes: 10_240,
+					WriteBytes:    10_240,
+				},
+			}},
+		}},
+	}
+	s, err := xdr.MarshalBase64(env)
+	require.NoError(t, err)
+	return s
+}
+
+func callTree() *trace.TraceNode {
+	cost := func(v uint64) *uint64 { return &v }
+	root := trace.NewTraceNode("root", "transaction")
+	swap := trace.NewTraceNode("call-0", "contract_call")
+	swap.ContractID, swap.Function, swap.CPUDelta = "CPOOL", "swap", cost(200_000)
+	transfer := trace.NewTraceNode("call-1", "contract_call")
+	transfer.ContractID, transfer.Function, transfer.CPUDelta = "CTOKEN", "transfer", cost(500_000)
+	idle := trace.NewTraceNode("call-2", "contract_call")
+	idle.Function = "noop"
+	root.AddChild(swap)
+	swap.AddChild(transfer)
+	root.AddChild(idle)
+	return root
+}
+
+func TestRecommend(t *testing.T) {
+	env := envelope(t)
+	keyB64, err := xdr.MarshalBase64(balanceKey())
+	require.NoError(t, err)
+	entries := map[string]string{keyB64: strings.Repeat("A", 1368)} // 1026 bytes
+
+	resp := &simulator.SimulationResponse{
+		Status:      "success",
+		BudgetUsage: &simulator.BudgetUsage{CPUInstructions: 1_000_000},
+	}
+	rec, err := Recommend(env, resp, entries, callTree(), Options{MarginPercent: 20})
+	require.NoError(t, err)
+
+	assert.Equal(t, 20, rec.MarginPercent)
+	assert.Equal(t, uint32(1_200_000), rec.Tuned.Instructions)
+	assert.Equal(t, uint32(1026), rec.Tuned.DiskReadBytes)
+	assert.Equal(t, uint32(1026), rec.Tuned.WriteBytes)
+	assert.Equal(t, 1, rec.Tuned.ReadEntries)
+	assert.Equal(t, 1, rec.Tuned.WriteEntries)
+	assert.Equal(t, simulator.EstimateResourceFee(rec.Declared.Usage()), rec.DeclaredFee)
+	assert.Negative(t, rec.FeeDelta())
+	assert.Equal(t, int64(200_000)+rec.FeeDelta(), rec.Tuned.ResourceFee)
+
+	require.Len(t, rec.Hotspots, 2, "calls without cost are left out")
+	assert.Equal(t, Hotspot{ContractID: "CPOOL", Function: "swap", Depth: 1, CPU: 700_000, Self: 200_000, Percent: 70}, rec.Hotspots[0])
+	assert.Equal(t, "transfer", rec.Hotspots[1].Function)
+
+	data, err := rec.SorobanData(env)
+	require.NoError(t, err)
+	var tuned xdr.SorobanTransactionData
+	require.NoError(t, xdr.SafeUnmarshalBase64(data, &tuned))
+	assert.Equal(t, xdr.Uint32(1_200_000), tuned.Resources.Instructions)
+	assert.Len(t, tuned.Resources.Footprint.ReadWrite, 1)
+
+	lines := strings.Join(rec.Lines(), "\n")
+	assert.Contains(t, lines, "20% margin")
+	assert.Contains(t, lines, "CPOOL::swap")
+	assert.Contains(t, lines, "did not report ledger changes")
+	assert.NotContains(t, lines, "per-call budgets")
+}
+
+func TestRecommend_Warnings(t *testing.T) {
+	env := envelope(t)
+	keyB64, err := xdr.MarshalBase64(balanceKey())
+	require.NoError(t, err)
+
+	resp := &simulator.SimulationResponse{
+		Status:        "success",
+		BudgetUsage:   &simulator.BudgetUsage{CPUInstructions: 1_000_000},
+		LedgerChanges: []simulator.LedgerEntryChange{{KeyXdr: keyB64, Type: "updated", EntryXdr: strings.Repeat("A", 8)}},
+	}
+	rec, err := Recommend(env, resp, nil, nil, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(6), rec.Tuned.WriteBytes)
+	require.Len(t, rec.Warnings, 1)
+	assert.Contains(t, rec.Warnings[0], "per-call budgets")
+}
+
+func TestRecommend_NoBudget(t *testing.T) {
+	_, err := Recommend(envelope(t), &simulator.SimulationResponse{}, nil, nil, Options{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/errors"
+)
+
+// Encode writes the module in binary form. Sections are emitted in the
+// standard order with each custom section after the section it followed when
+// parsed.
+func (m *Module) Encode() ([]byte, error) {
+	out := make([]byte, 0, 1024)
+	out = append(out, Magic...)
+	out = append(out, Version, 0, 0, 0)
+
+	out = m.appendCustoms(out, SectionCustom)
+	for _, id := range sectionOrder {
+		payload, ok, err := m.encodeSection(id)
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %w", id, err)
+		}
+		if ok {
+			out = appendSection(out, id, payload)
+		}
+		out = m.appendCustoms(out, id)
+	}
+	return out, nil
+}
+
+func appendSection(out []byte, id SectionID, payload []byte) []byte {
+	out = append(out, byte(id))
+	out = AppendU32(out, uint32(len(payload)))
+	return append(out, payload...)
+}
+
+func (m *Module) appendCustoms(out []byte, after SectionID) []byte {
+	for _, c := range m.Customs {
+		if c.After != after {
+			continue
+		}
+		payload := appendName(nil, c.Name)
+		payload = append(payload, c.Data...)
+		out = appendSection(out, SectionCustom, payload)
+	}
+	return out
+}
+
+func appendName(b []byte, s string) []byte {
+	b = AppendU32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+func appendValTypes(b []byte, ts []ValType) []byte {
+	b = AppendU32(b, uint32(len(ts)))
+	for _, t := range ts {
+		b = append(b, byte(t))
+	}
+	return b
+}
+
+// encodeSection returns the payload of section id and whether the module has
+// that section.
+func (m *Module) encodeSection(id SectionID) ([]byte, bool, error) {
+	var b []byte
+	switch id {
+	case SectionType:
+		if m.Types == nil {
+			return nil, false, nil
+		}
+		b = AppendU32(b, uint32(len(m.Types)))
+		for _, t := range m.Types {
+			b = append(b, 0x60)
+			b = appendValTypes(b, t.Params)
+			b = appendValTypes(b, t.Results)
+		}
+	case SectionImport:
+		if m.Imports == nil {
+			return nil, false, nil
+		}
+		b = AppendU32(b, uint32(len(m.Imports)))
+		for _, imp := range m.Imports {
+			b = appendName(b, imp.Module)
+			b = appendName(b, imp.Name)
+			b = append(b, byte(imp.Kind))
+			if imp.Kind == KindFunc {
+				b = AppendU32(b, imp.TypeIndex)
+			} else {
+				b = append(b, imp.Desc...)
+			}
+		}
+	case SectionFunction:
+		if m.Functions == nil {
+			return nil, false, nil
+		}
+		b = AppendU32(b, uint32(len(m.Functions)))
+		for _, idx := range m.Functions {
+			b = AppendU32(b, idx)
+		}
+	case SectionTable:
+		return m.Tables, m.Tables != nil, nil
+	case SectionMemory:
+		return m.Memories, m.Memories != nil, nil
+	case SectionTag:
+		return m.Tags, m.Tags != nil, nil
+	case SectionGlobal:
+		if m.Globals == nil {
+			return nil, false, nil
+		}
+		b = AppendU32(b, uint32(len(m.Globals)))
+		for _, g := range m.Globals {
+			b = append(b, byte(g.Type))
+			if g.Mutable {
+				b = append(b, 1)
+			} else {
+				b = append(b, 0)
+			}
+			b = append(b, g.Init...)
+		}
+	case SectionExport:
+		if m.Exports == nil {
+			return nil, false, nil
+		}
+		b = AppendU32(b, uint32(len(m.Exports)))
+		for _, e := range m.Exports {
+			b = appendName(b, e.Name)
+			b = append(b, byte(e.Kind))
+			b = AppendU32(b, e.Index)
+		}
+	case SectionStart:
+		if m.Start == nil {
+			return nil, false, nil
+		}
+		b = AppendU32(b, *m.Start)
+	case SectionElement:
+		if m.Elements == nil {
+			return nil, false, nil
+		}
+		b = AppendU32(b, uint32(len(m.Elements)))
+		for _, e := range m.Elements {
+			var err error
+			if b, err = appendElement(b, e); err != nil {
+				return nil, false, err
+			}
+		}
+	case SectionDataCount:
+		if m.DataCount == nil {
+			return nil, false, nil
+		}
+		b = AppendU32(b, *m.DataCount)
+	case SectionCode:
+		if m.Code == nil {
+			return nil, false, nil
+		}
+		b = AppendU32(b, uint32(len(m.Code)))
+		for _, f := range m.Code {
+			var fb []byte
+			fb = AppendU32(fb, uint32(len(f.Locals)))
+			for _, l := range f.Locals {
+				fb = AppendU32(fb, l.Count)
+				fb = append(fb, byte(l.Type))
+			}
+			fb = append(fb, f.Body...)
+			b = AppendU32(b, uint32(len(fb)))
+			b = append(b, fb...)
+		}
+	case SectionData:
+		if m.Data == nil {
+			return nil, false, nil
+		}
+		b = AppendU32(b, uint32(len(m.Data)))
+		for _, d := range m.Data {
+			b = AppendU32(b, d.Flags)
+			switch d.Flags {
+			case 0:
+				b = append(b, d.Offset...)
+			case 1:
+			case 2:
+				b = AppendU32(b, d.Memory)
+				b = append(b, d.Offset...)
+			default:
+				return nil, false, errors.WrapWasmInvalid(fmt.Sprintf("unsupported data flags %d", d.Flags))
+			}
+			b = AppendU32(b, uint32(len(d.Init)))
+			b = append(b, d.Init...)
+		}
+	}
+	return b, true, nil
+}
+
+func appendElement(b []byte, e Element) ([]byte, error) {
+	if e.Flags > 7 {
+		return nil, errors.WrapWasmInvalid(fmt.Sprintf("unsupported element flags %d", e.Flags))
+	}
+	b = AppendU32(b, e.Flags)
+	active := e.Flags&0x01 == 0
+	if active && e.Flags&0x02 != 0 {
+		b = AppendU32(b, e.Table)
+	}
+	if active {
+		b = append(b, e.Offset...)
+	}
+	if e.Flags&0x03 != 0 {
+		b = append(b, e.Kind)
+	}
+	if e.Flags&0x04 == 0 {
+		b = AppendU32(b, uint32(len(e.Funcs)))
+		for _, idx := range e.Funcs {
+			b = AppendU32(b, idx)
+		}
+		return b, nil
+	}
+	b = AppendU32(b, uint32(len(e.Exprs)))
+	for _, expr := range e.Exprs {
+		b = append(b, expr...)
+	}
+	return b, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// FuzzParseEncode checks that any module Parse accepts re-encodes to a binary
+// that parses to the same module and encodes identically again, and that an
+// input without padded integers re-encodes byte for byte.
+func FuzzParseEncode(f *testing.F) {
+	if bin, err := testModule().Encode(); err == nil {
+		f.Add(bin)
+	}
+	f.Add(append(append([]byte{}, Magic...), 0x01, 0x00, 0x00, 0x00))
+	f.Add(append(append([]byte{}, Magic...), 0x01, 0x00, 0x00, 0x00,
+		byte(SectionType), 0x04, 0x01, 0x60, 0x00, 0x00,
+		byte(SectionFunction), 0x02, 0x01, 0x00,
+		byte(SectionCode), 0x04, 0x01, 0x02, 0x00, OpEnd))
+
+	f.Fuzz(func(t *testing.T, in []byte) {
+		m, err := Parse(in)
+		if err != nil {
+			return
+		}
+		out, err := m.Encode()
+		if err != nil {
+			t.Fatalf("Encode of parsed module failed: %v", err)
+		}
+		if !mayHavePaddedLEB(in) && !bytes.Equal(in, out) {
+			t.Fatalf("canonical input changed across a round trip:\n in  %x\n out %x", in, out)
+		}
+		m2, err := Parse(out)
+		if err != nil {
+			t.Fatalf("Parse of encoded module failed: %v", err)
+		}
+		clearOffsets(m)
+		clearOffsets(m2)
+		if !reflect.DeepEqual(m, m2) {
+			t.Fatalf("module changed across a round trip")
+		}
+		out2, err := m2.Encode()
+		if err != nil {
+			t.Fatalf("second Encode failed: %v", err)
+		}
+		if !bytes.Equal(out, out2) {
+			t.Fatalf("encoding is not stable")
+		}
+	})
+}
+
+// FuzzLEB128 checks that decoded integers re-encode to a minimal form that
+// decodes to the same value.
+func FuzzLEB128(f *testing.F) {
+	f.Add([]byte{0xe5, 0x8e, 0x26})
+	f.Add([]byte{0x80, 0x7f})
+	f.Fuzz(func(t *testing.T, in []byte) {
+		if v, _, err := ReadU32(in, 0); err == nil {
+			if got, _, err := ReadU32(AppendU32(nil, v), 0); err != nil || got != v {
+				t.Fatalf("u32 %d round-tripped to %d (%v)", v, got, err)
+			}
+		}
+		if v, _, err := ReadS64(in, 0); err == nil {
+			if got, _, err := ReadS64(AppendS64(nil, v), 0); err != nil || got != v {
+				t.Fatalf("s64 %d round-tripped to %d (%v)", v, got, err)
+			}
+		}
+	})
+}
+
+// mayHavePaddedLEB reports whether b could hold a non-minimal LEB128 integer,
+// which Encode shortens. Padding always ends in a continuation byte followed
+// by 0x00 or 0x7f, so inputs without that pair are canonical. The check also
+// trips on unrelated bytes, which only skips the identity assertion.
+func mayHavePaddedLEB(b []byte) bool {
+	for i := 1; i < len(b); i++ {
+		if b[i-1]&0x80 != 0 && (b[i] == 0x00 || b[i] == 0x7f) {
+			return true
+		}
+	}
+	return false
+}
+
+// clearOffsets zeroes the positions Parse records, which legitimately move
+// when non-minimal integers are re-encoded.
+func clearOffsets(m *Module) {
+	m.CodeOffset = 0
+	for i := range m.Code {
+		m.Code[i].Offset = 0
+	}
+}
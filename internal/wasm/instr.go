@@ -0,0 +1,354 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/errors"
+)
+
+// Opcodes with immediates this package interprets.
+const (
+	OpBlock              byte = 0x02
+	OpLoop               byte = 0x03
+	OpIf                 byte = 0x04
+	OpElse               byte = 0x05
+	OpEnd                byte = 0x0b
+	OpCall               byte = 0x10
+	OpCallIndirect       byte = 0x11
+	OpReturnCall         byte = 0x12
+	OpReturnCallIndirect byte = 0x13
+	OpGlobalGet          byte = 0x23
+	OpGlobalSet          byte = 0x24
+	OpRefFunc            byte = 0xd2
+	OpPrefixFC           byte = 0xfc
+	OpPrefixSIMD         byte = 0xfd
+)
+
+// Instr is a decoded instruction. Immediates are kept as raw bytes so code
+// can be copied through unchanged.
+type Instr struct {
+	Op byte
+	// Sub is the sub-opcode of 0xfc-prefixed instructions.
+	Sub uint32
+	// Imm is the raw immediate bytes, after the opcode and any sub-opcode.
+	Imm []byte
+	// Offset is the position of the opcode in the decoded bytes.
+	Offset int
+	// Size is the encoded length of the whole instruction.
+	Size int
+}
+
+// FuncIndex returns the function an instruction refers to: the callee of call
+// and return_call, or the operand of ref.func.
+func (in Instr) FuncIndex() (uint32, bool) {
+	switch in.Op {
+	case OpCall, OpReturnCall, OpRefFunc:
+		idx, _, err := ReadU32(in.Imm, 0)
+		return idx, err == nil
+	}
+	return 0, false
+}
+
+// GlobalIndex returns the global read or written by global.get and
+// global.set.
+func (in Instr) GlobalIndex() (uint32, bool) {
+	switch in.Op {
+	case OpGlobalGet, OpGlobalSet:
+		idx, _, err := ReadU32(in.Imm, 0)
+		return idx, err == nil
+	}
+	return 0, false
+}
+
+// TypeIndex returns the function type an instruction refers to: the
+// signature of call_indirect and return_call_indirect, or a block type given
+// as a type index.
+func (in Instr) TypeIndex() (uint32, bool) {
+	switch in.Op {
+	case OpCallIndirect, OpReturnCallIndirect:
+		idx, _, err := ReadU32(in.Imm, 0)
+		return idx, err == nil
+	case OpBlock, OpLoop, OpIf:
+		if len(in.Imm) == 1 && in.Imm[0]&0x40 != 0 {
+			return 0, false // empty or value-type block type
+		}
+		idx, _, err := ReadS33(in.Imm, 0)
+		if err != nil || idx < 0 || idx > int64(^uint32(0)) {
+			return 0, false
+		}
+		return uint32(idx), true
+	}
+	return 0, false
+}
+
+// DecodeInstr decodes the instruction at b[pos].
+func DecodeInstr(b []byte, pos int) (Instr, error) {
+	if pos >= len(b) {
+		return Instr{}, errors.WrapWasmInvalid("instruction truncated")
+	}
+	in := Instr{Op: b[pos], Offset: pos}
+	p := pos + 1
+	if in.Op == OpPrefixFC {
+		sub, n, err := ReadU32(b, p)
+		if err != nil {
+			return Instr{}, err
+		}
+		in.Sub = sub
+		p += n
+	}
+	n, err := immediateLen(in.Op, in.Sub, b, p)
+	if err != nil {
+		return Instr{}, fmt.Errorf("opcode 0x%02x at offset %d: %w", in.Op, pos, err)
+	}
+	in.Imm = b[p : p+n]
+	in.Size = p + n - pos
+	return in, nil
+}
+
+// DecodeExpr decodes every instruction in expr.
+func DecodeExpr(expr []byte) ([]Instr, error) {
+	var out []Instr
+	for pos := 0; pos < len(expr); {
+		in, err := DecodeInstr(expr, pos)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, in)
+		pos += in.Size
+	}
+	return out, nil
+}
+
+// FuncRefs returns the function indices expr calls or takes references to,
+// in order of appearance.
+func FuncRefs(expr []byte) ([]uint32, error) {
+	var refs []uint32
+	for pos := 0; pos < len(expr); {
+		in, err := DecodeInstr(expr, pos)
+		if err != nil {
+			return nil, err
+		}
+		if idx, ok := in.FuncIndex(); ok {
+			refs = append(refs, idx)
+		}
+		pos += in.Size
+	}
+	return refs, nil
+}
+
+// Rewrite returns expr with instructions replaced by edit. edit returns the
+// new encoding of an instruction, or nil to copy it through byte for byte.
+func Rewrite(expr []byte, edit func(Instr) ([]byte, error)) ([]byte, error) {
+	out := make([]byte, 0, len(expr))
+	for pos := 0; pos < len(expr); {
+		in, err := DecodeInstr(expr, pos)
+		if err != nil {
+			return nil, err
+		}
+		pos += in.Size
+		repl, err := edit(in)
+		if err != nil {
+			return nil, err
+		}
+		if repl == nil {
+			repl = expr[in.Offset:pos]
+		}
+		out = append(out, repl...)
+	}
+	return out, nil
+}
+
+// RewriteFuncRefs returns expr with every function reference passed through
+// remap. Instructions that do not change are copied byte for byte. remap
+// reports false for functions that no longer exist, which is an error.
+func RewriteFuncRefs(expr []byte, remap func(uint32) (uint32, bool)) ([]byte, error) {
+	return Rewrite(expr, func(in Instr) ([]byte, error) {
+		idx, ok := in.FuncIndex()
+		if !ok {
+			return nil, nil
+		}
+		newIdx, ok := remap(idx)
+		if !ok {
+			return nil, fmt.Errorf("reference to removed function %d", idx)
+		}
+		if newIdx == idx {
+			return nil, nil
+		}
+		return AppendU32([]byte{in.Op}, newIdx), nil
+	})
+}
+
+// RewriteGlobalRefs is RewriteFuncRefs for global.get and global.set.
+func RewriteGlobalRefs(expr []byte, remap func(uint32) (uint32, bool)) ([]byte, error) {
+	return Rewrite(expr, func(in Instr) ([]byte, error) {
+		idx, ok := in.GlobalIndex()
+		if !ok {
+			return nil, nil
+		}
+		newIdx, ok := remap(idx)
+		if !ok {
+			return nil, fmt.Errorf("reference to removed global %d", idx)
+		}
+		if newIdx == idx {
+			return nil, nil
+		}
+		return AppendU32([]byte{in.Op}, newIdx), nil
+	})
+}
+
+// RewriteTypeRefs is RewriteFuncRefs for the type indices of indirect calls
+// and block types.
+func RewriteTypeRefs(expr []byte, remap func(uint32) (uint32, bool)) ([]byte, error) {
+	return Rewrite(expr, func(in Instr) ([]byte, error) {
+		idx, ok := in.TypeIndex()
+		if !ok {
+			return nil, nil
+		}
+		newIdx, ok := remap(idx)
+		if !ok {
+			return nil, fmt.Errorf("reference to removed type %d", idx)
+		}
+		if newIdx == idx {
+			return nil, nil
+		}
+		out := []byte{in.Op}
+		if in.Op == OpCallIndirect || in.Op == OpReturnCallIndirect {
+			_, n, _ := ReadU32(in.Imm, 0)
+			out = AppendU32(out, newIdx)
+			return append(out, in.Imm[n:]...), nil
+		}
+		return AppendS64(out, int64(newIdx)), nil
+	})
+}
+
+// exprLen returns the length of the expression at the start of b, up to and
+// including the end opcode that closes it.
+func exprLen(b []byte) (int, error) {
+	depth := 0
+	for pos := 0; pos < len(b); {
+		in, err := DecodeInstr(b, pos)
+		if err != nil {
+			return 0, err
+		}
+		pos += in.Size
+		switch in.Op {
+		case OpBlock, OpLoop, OpIf:
+			depth++
+		case OpEnd:
+			if depth == 0 {
+				return pos, nil
+			}
+			depth--
+		}
+	}
+	return 0, errors.WrapWasmInvalid("unterminated expression")
+}
+
+// immediateLen returns the length of the immediates of op starting at b[pos].
+func immediateLen(op byte, sub uint32, b []byte, pos int) (int, error) {
+	u32s := func(count int) (int, error) {
+		p := pos
+		for i := 0; i < count; i++ {
+			_, n, err := ReadU32(b, p)
+			if err != nil {
+				return 0, err
+			}
+			p += n
+		}
+		return p - pos, nil
+	}
+	fixed := func(n int) (int, error) {
+		if pos+n > len(b) {
+			return 0, errors.WrapWasmInvalid("immediate truncated")
+		}
+		return n, nil
+	}
+
+	switch {
+	case op == 0x00 || op == 0x01 || op == OpElse || op == OpEnd || op == 0x0f ||
+		op == 0x1a || op == 0x1b || op == 0xd1 || (op >= 0x45 && op <= 0xc4):
+		return 0, nil
+	case op == OpBlock || op == OpLoop || op == OpIf:
+		if pos >= len(b) {
+			return 0, errors.WrapWasmInvalid("block type truncated")
+		}
+		switch b[pos] {
+		case 0x40, byte(I32), byte(I64), byte(F32), byte(F64), byte(V128), byte(FuncRef), byte(ExternRef):
+			return 1, nil
+		}
+		_, n, err := ReadS33(b, pos)
+		return n, err
+	case op == 0x0c || op == 0x0d || (op >= 0x20 && op <= 0x26) ||
+		op == OpCall || op == OpReturnCall || op == OpRefFunc || op == 0x3f || op == 0x40:
+		return u32s(1)
+	case op == OpCallIndirect || op == OpReturnCallIndirect:
+		return u32s(2)
+	case op == 0x0e: // br_table
+		count, n, err := ReadU32(b, pos)
+		if err != nil {
+			return 0, err
+		}
+		p := pos + n
+		for i := uint64(0); i <= uint64(count); i++ {
+			_, n, err := ReadU32(b, p)
+			if err != nil {
+				return 0, err
+			}
+			p += n
+		}
+		return p - pos, nil
+	case op == 0x1c: // select t*
+		count, n, err := ReadU32(b, pos)
+		if err != nil {
+			return 0, err
+		}
+		if uint64(pos+n)+uint64(count) > uint64(len(b)) {
+			return 0, errors.WrapWasmInvalid("select types truncated")
+		}
+		return n + int(count), nil
+	case op >= 0x28 && op <= 0x3e: // memarg: align, [memory if align bit 6], offset
+		align, n, err := ReadU32(b, pos)
+		if err != nil {
+			return 0, err
+		}
+		p := pos + n
+		if align&0x40 != 0 {
+			if _, n, err = ReadU32(b, p); err != nil {
+				return 0, err
+			}
+			p += n
+		}
+		if _, n, err = ReadU32(b, p); err != nil {
+			return 0, err
+		}
+		return p + n - pos, nil
+	case op == 0x41:
+		_, n, err := ReadS32(b, pos)
+		return n, err
+	case op == 0x42:
+		_, n, err := ReadS64(b, pos)
+		return n, err
+	case op == 0x43:
+		return fixed(4)
+	case op == 0x44:
+		return fixed(8)
+	case op == 0xd0: // ref.null t
+		return fixed(1)
+	case op == OpPrefixFC:
+		switch {
+		case sub <= 7: // trunc_sat
+			return 0, nil
+		case sub == 8 || sub == 10 || sub == 12 || sub == 14:
+			return u32s(2)
+		case sub <= 17:
+			return u32s(1)
+		}
+		return 0, errors.WrapWasmInvalid(fmt.Sprintf("unsupported 0xfc sub-opcode %d", sub))
+	case op == OpPrefixSIMD:
+		return 0, errors.WrapWasmInvalid("SIMD instructions are not supported")
+	}
+	return 0, errors.WrapWasmInvalid(fmt.Sprintf("unsupported opcode 0x%02x", op))
+}
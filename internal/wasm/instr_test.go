@@ -0,0 +1,139 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeExpr(t *testing.T) {
+	expr := []byte{
+		0x20, 0x00, // local.get 0
+		0x28, 0x02, 0x08, // i32.load align=2 offset=8
+		0x41, 0x7f, // i32.const -1
+		0x6a,                         // i32.add
+		0x0e, 0x02, 0x00, 0x01, 0x02, // br_table 0 1 2
+		0xfc, 0x0a, 0x00, 0x00, // memory.copy 0 0
+		0x44, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, // f64.const 1
+		OpEnd,
+	}
+	instrs, err := DecodeExpr(expr)
+	require.NoError(t, err)
+
+	ops := make([]byte, len(instrs))
+	for i, in := range instrs {
+		ops[i] = in.Op
+	}
+	assert.Equal(t, []byte{0x20, 0x28, 0x41, 0x6a, 0x0e, 0xfc, 0x44, OpEnd}, ops)
+	assert.Equal(t, uint32(10), instrs[5].Sub)
+	assert.Equal(t, 3, instrs[1].Size)
+	assert.Equal(t, 9, instrs[6].Size)
+	assert.Equal(t, 5, instrs[2].Offset)
+}
+
+func TestDecodeInstr_Errors(t *testing.T) {
+	for _, expr := range [][]byte{
+		{0x41},             // i32.const without operand
+		{0x43, 0x00, 0x00}, // f32.const truncated
+		{0xfd, 0x00},       // SIMD
+		{0x06},             // unassigned in the supported feature set
+		{0xfc, 0x20},       // unknown 0xfc sub-opcode
+	} {
+		_, err := DecodeExpr(expr)
+		assert.Error(t, err, "%x", expr)
+	}
+}
+
+func TestFuncRefs(t *testing.T) {
+	body := []byte{
+		OpCall, 0x03,
+		OpCallIndirect, 0x00, 0x00,
+		OpRefFunc, 0x07,
+		OpReturnCall, 0x03,
+		OpEnd,
+	}
+	refs, err := FuncRefs(body)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{3, 7, 3}, refs)
+
+	refs, err = FuncRefs([]byte{0x01, OpEnd})
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+}
+
+func TestRewriteFuncRefs(t *testing.T) {
+	body := []byte{
+		OpCall, 0x85, 0x80, 0x80, 0x80, 0x00, // padded call 5
+		OpCall, 0x01,
+		0x41, 0x10, // i32.const 16 (not a function index)
+		OpEnd,
+	}
+	out, err := RewriteFuncRefs(body, func(i uint32) (uint32, bool) {
+		if i == 5 {
+			return 200, true
+		}
+		return i, true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{OpCall, 0xc8, 0x01, OpCall, 0x01, 0x41, 0x10, OpEnd}, out)
+
+	_, err = RewriteFuncRefs(body, func(i uint32) (uint32, bool) { return 0, false })
+	assert.Error(t, err)
+}
+
+func TestExprLen(t *testing.T) {
+	n, err := exprLen([]byte{0x41, 0x0b, OpEnd, 0xff})
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	n, err = exprLen([]byte{OpBlock, 0x40, OpEnd, OpEnd})
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	_, err = exprLen([]byte{0x41, 0x00})
+	assert.Error(t, err)
+}
+
+func TestRewriteGlobalAndTypeRefs(t *testing.T) {
+	body := []byte{
+		OpGlobalGet, 0x02,
+		OpGlobalSet, 0x00,
+		OpBlock, 0x03, // block (type 3)
+		OpBlock, 0x40,
+		OpEnd,
+		OpEnd,
+		0x41, 0x00,
+		OpCallIndirect, 0x03, 0x00,
+		OpEnd,
+	}
+	shift := func(i uint32) (uint32, bool) { return i + 1, true }
+
+	out, err := RewriteGlobalRefs(body, shift)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x03), out[1])
+	assert.Equal(t, byte(0x01), out[3])
+	assert.Equal(t, body[4:], out[4:])
+
+	out, err = RewriteTypeRefs(body, func(i uint32) (uint32, bool) { return i - 3, true })
+	require.NoError(t, err)
+	assert.Equal(t, []byte{
+		OpGlobalGet, 0x02,
+		OpGlobalSet, 0x00,
+		OpBlock, 0x00,
+		OpBlock, 0x40,
+		OpEnd,
+		OpEnd,
+		0x41, 0x00,
+		OpCallIndirect, 0x00, 0x00,
+		OpEnd,
+	}, out)
+
+	instrs, err := DecodeExpr(body)
+	require.NoError(t, err)
+	_, ok := instrs[3].TypeIndex()
+	assert.False(t, ok, "empty block type is not a type index")
+}
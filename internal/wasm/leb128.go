@@ -0,0 +1,116 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"github.com/dotandev/hintents/internal/errors"
+)
+
+// ReadU32 decodes an unsigned LEB128 integer of at most 32 bits at b[pos].
+// It returns the value and the number of bytes consumed.
+func ReadU32(b []byte, pos int) (uint32, int, error) {
+	v, n, err := readUnsigned(b, pos, 32)
+	return uint32(v), n, err
+}
+
+// ReadU64 decodes an unsigned LEB128 integer of at most 64 bits at b[pos].
+func ReadU64(b []byte, pos int) (uint64, int, error) {
+	return readUnsigned(b, pos, 64)
+}
+
+// ReadS32 decodes a signed LEB128 integer of at most 32 bits at b[pos].
+func ReadS32(b []byte, pos int) (int32, int, error) {
+	v, n, err := readSigned(b, pos, 32)
+	return int32(v), n, err
+}
+
+// ReadS33 decodes the signed 33-bit integer used for block type indices.
+func ReadS33(b []byte, pos int) (int64, int, error) {
+	return readSigned(b, pos, 33)
+}
+
+// ReadS64 decodes a signed LEB128 integer of at most 64 bits at b[pos].
+func ReadS64(b []byte, pos int) (int64, int, error) {
+	return readSigned(b, pos, 64)
+}
+
+func readUnsigned(b []byte, pos int, bits uint) (uint64, int, error) {
+	var v uint64
+	var shift uint
+	maxLen := int((bits + 6) / 7)
+	for i := 0; i < maxLen; i++ {
+		if pos+i >= len(b) || pos+i < 0 {
+			return 0, 0, errors.WrapWasmInvalid("LEB128 integer truncated")
+		}
+		c := b[pos+i]
+		if i == maxLen-1 && uint(c&0x7f)>>(bits-shift) != 0 {
+			return 0, 0, errors.WrapWasmInvalid("LEB128 integer too large")
+		}
+		v |= uint64(c&0x7f) << shift
+		if c&0x80 == 0 {
+			return v, i + 1, nil
+		}
+		shift += 7
+	}
+	return 0, 0, errors.WrapWasmInvalid("LEB128 integer too long")
+}
+
+func readSigned(b []byte, pos int, bits uint) (int64, int, error) {
+	var v int64
+	var shift uint
+	maxLen := int((bits + 6) / 7)
+	for i := 0; i < maxLen; i++ {
+		if pos+i >= len(b) || pos+i < 0 {
+			return 0, 0, errors.WrapWasmInvalid("LEB128 integer truncated")
+		}
+		c := b[pos+i]
+		v |= int64(c&0x7f) << shift
+		shift += 7
+		if c&0x80 == 0 {
+			if shift < 64 && c&0x40 != 0 {
+				v |= -1 << shift
+			}
+			if bits < 64 {
+				// Bits past the integer's width must be a sign extension.
+				v = v << (64 - bits) >> (64 - bits)
+			}
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, errors.WrapWasmInvalid("LEB128 integer too long")
+}
+
+// AppendU32 appends the minimal unsigned LEB128 encoding of v.
+func AppendU32(b []byte, v uint32) []byte {
+	return AppendU64(b, uint64(v))
+}
+
+// AppendU64 appends the minimal unsigned LEB128 encoding of v.
+func AppendU64(b []byte, v uint64) []byte {
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
+
+// AppendS32 appends the minimal signed LEB128 encoding of v.
+func AppendS32(b []byte, v int32) []byte {
+	return AppendS64(b, int64(v))
+}
+
+// AppendS64 appends the minimal signed LEB128 encoding of v.
+func AppendS64(b []byte, v int64) []byte {
+	for {
+		c := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && c&0x40 == 0) || (v == -1 && c&0x40 != 0) {
+			return append(b, c)
+		}
+		b = append(b, c|0x80)
+	}
+}
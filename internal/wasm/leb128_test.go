@@ -0,0 +1,77 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadU32(t *testing.T) {
+	tests := []struct {
+		in   []byte
+		want uint32
+		n    int
+	}{
+		{[]byte{0x00}, 0, 1},
+		{[]byte{0x7f}, 127, 1},
+		{[]byte{0x80, 0x01}, 128, 2},
+		{[]byte{0xe5, 0x8e, 0x26}, 624485, 3},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0x0f}, math.MaxUint32, 5},
+		// Padded encodings, as emitted by linkers for relocatable indices.
+		{[]byte{0x83, 0x80, 0x80, 0x80, 0x00}, 3, 5},
+	}
+	for _, tt := range tests {
+		v, n, err := ReadU32(tt.in, 0)
+		require.NoError(t, err, "%x", tt.in)
+		assert.Equal(t, tt.want, v, "%x", tt.in)
+		assert.Equal(t, tt.n, n, "%x", tt.in)
+	}
+}
+
+func TestReadU32_Errors(t *testing.T) {
+	for _, in := range [][]byte{
+		{},
+		{0x80},
+		{0xff, 0xff, 0xff, 0xff, 0x1f},       // more than 32 bits
+		{0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, // more than 5 bytes
+	} {
+		_, _, err := ReadU32(in, 0)
+		require.Error(t, err, "%x", in)
+		assert.True(t, errors.Is(err, errors.ErrWasmInvalid))
+	}
+}
+
+func TestReadSigned(t *testing.T) {
+	v, n, err := ReadS32([]byte{0x7f}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int32(-1), v)
+	assert.Equal(t, 1, n)
+
+	v, _, err = ReadS32([]byte{0x80, 0x7f}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int32(-128), v)
+
+	v64, _, err := ReadS64(AppendS64(nil, math.MinInt64), 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(math.MinInt64), v64)
+}
+
+func TestLEB128_RoundTrip(t *testing.T) {
+	for _, v := range []uint32{0, 1, 63, 64, 127, 128, 16383, 16384, math.MaxUint32} {
+		got, n, err := ReadU32(AppendU32(nil, v), 0)
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+		assert.Equal(t, len(AppendU32(nil, v)), n)
+	}
+	for _, v := range []int64{0, 1, -1, 63, -64, 64, -65, math.MaxInt32, math.MinInt32, math.MaxInt64, math.MinInt64} {
+		got, _, err := ReadS64(AppendS64(nil, v), 0)
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+	}
+}
@@ -0,0 +1,836 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+// Package wasm reads and writes WebAssembly binary modules. Parse decodes a
+// module into typed sections and Encode writes it back; a module whose
+// integers use minimal LEB128 encodings round-trips byte for byte. Function
+// bodies and constant expressions are kept as raw instruction bytes, which
+// DecodeInstr walks, so passes that rewrite code only touch what they change.
+package wasm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/errors"
+)
+
+// Magic is the four-byte preamble of every WASM module.
+var Magic = []byte{0x00, 0x61, 0x73, 0x6d}
+
+// Version is the binary format version this package parses.
+const Version = 1
+
+// SectionID identifies a module section.
+type SectionID byte
+
+// Section IDs.
+const (
+	SectionCustom    SectionID = 0
+	SectionType      SectionID = 1
+	SectionImport    SectionID = 2
+	SectionFunction  SectionID = 3
+	SectionTable     SectionID = 4
+	SectionMemory    SectionID = 5
+	SectionGlobal    SectionID = 6
+	SectionExport    SectionID = 7
+	SectionStart     SectionID = 8
+	SectionElement   SectionID = 9
+	SectionCode      SectionID = 10
+	SectionData      SectionID = 11
+	SectionDataCount SectionID = 12
+	SectionTag       SectionID = 13
+)
+
+// sectionOrder is the order non-custom sections must appear in.
+var sectionOrder = []SectionID{
+	SectionType, SectionImport, SectionFunction, SectionTable, SectionMemory,
+	SectionTag, SectionGlobal, SectionExport, SectionStart, SectionElement,
+	SectionDataCount, SectionCode, SectionData,
+}
+
+func sectionRank(id SectionID) int {
+	for i, s := range sectionOrder {
+		if s == id {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// ValType is a value type.
+type ValType byte
+
+// Value types.
+const (
+	I32       ValType = 0x7f
+	I64       ValType = 0x7e
+	F32       ValType = 0x7d
+	F64       ValType = 0x7c
+	V128      ValType = 0x7b
+	FuncRef   ValType = 0x70
+	ExternRef ValType = 0x6f
+)
+
+func (v ValType) String() string {
+	switch v {
+	case I32:
+		return "i32"
+	case I64:
+		return "i64"
+	case F32:
+		return "f32"
+	case F64:
+		return "f64"
+	case V128:
+		return "v128"
+	case FuncRef:
+		return "funcref"
+	case ExternRef:
+		return "externref"
+	default:
+		return fmt.Sprintf("valtype(0x%02x)", byte(v))
+	}
+}
+
+// ExternalKind is the kind of an import or export.
+type ExternalKind byte
+
+// External kinds.
+const (
+	KindFunc   ExternalKind = 0
+	KindTable  ExternalKind = 1
+	KindMemory ExternalKind = 2
+	KindGlobal ExternalKind = 3
+	KindTag    ExternalKind = 4
+)
+
+// FuncType is a function signature.
+type FuncType struct {
+	Params  []ValType
+	Results []ValType
+}
+
+// Import is an entry of the import section.
+type Import struct {
+	Module string
+	Name   string
+	Kind   ExternalKind
+	// TypeIndex is the signature of a function import.
+	TypeIndex uint32
+	// Desc is the raw descriptor of a table, memory, global or tag import.
+	Desc []byte
+}
+
+// Export is an entry of the export section.
+type Export struct {
+	Name  string
+	Kind  ExternalKind
+	Index uint32
+}
+
+// Global is a module-defined global.
+type Global struct {
+	Type    ValType
+	Mutable bool
+	// Init is the constant initializer expression, including its end opcode.
+	Init []byte
+}
+
+// Element is an element segment. Flags selects the encoding: 0-3 list
+// function indices in Funcs, 4-7 list constant expressions in Exprs. Active
+// segments (flags 0, 2, 4, 6) carry Table and Offset.
+type Element struct {
+	Flags  uint32
+	Table  uint32
+	Offset []byte
+	// Kind is the element kind (flags 1-3) or reference type (flags 5-7).
+	Kind  byte
+	Funcs []uint32
+	Exprs [][]byte
+}
+
+// Local is a run of Count locals of one type in a function body.
+type Local struct {
+	Count uint32
+	Type  ValType
+}
+
+// Func is a function body from the code section.
+type Func struct {
+	Locals []Local
+	// Body is the function's instructions, including the final end opcode.
+	Body []byte
+	// Offset is where Body starts in the parsed binary, or 0 for functions
+	// that were not parsed.
+	Offset int
+}
+
+// Data is a data segment. Active segments (flags 0 and 2) carry Memory and
+// Offset.
+type Data struct {
+	Flags  uint32
+	Memory uint32
+	Offset []byte
+	Init   []byte
+}
+
+// Custom is a custom section. After is the non-custom section it follows,
+// or SectionCustom when it precedes all of them.
+type Custom struct {
+	Name  string
+	Data  []byte
+	After SectionID
+}
+
+// Module is a decoded WASM module. Slice fields are nil when their section
+// is absent; Tables, Memories and Tags hold raw section payloads.
+type Module struct {
+	Types     []FuncType
+	Imports   []Import
+	Functions []uint32
+	Tables    []byte
+	Memories  []byte
+	Tags      []byte
+	Globals   []Global
+	Exports   []Export
+	Start     *uint32
+	Elements  []Element
+	DataCount *uint32
+	Code      []Func
+	Data      []Data
+	Customs   []Custom
+
+	// CodeOffset is where the code section payload starts in the parsed
+	// binary. DWARF code addresses are relative to it.
+	CodeOffset int
+}
+
+// NumImportedFuncs returns the number of imported functions, which occupy the
+// lowest function indices.
+func (m *Module) NumImportedFuncs() uint32 {
+	var n uint32
+	for _, imp := range m.Imports {
+		if imp.Kind == KindFunc {
+			n++
+		}
+	}
+	return n
+}
+
+// NumImportedGlobals returns the number of imported globals, which precede
+// module-defined globals in the global index space.
+func (m *Module) NumImportedGlobals() uint32 {
+	var n uint32
+	for _, imp := range m.Imports {
+		if imp.Kind == KindGlobal {
+			n++
+		}
+	}
+	return n
+}
+
+// FuncTypeIndex returns the type index of function idx, imported or defined.
+func (m *Module) FuncTypeIndex(idx uint32) (uint32, bool) {
+	for _, imp := range m.Imports {
+		if imp.Kind != KindFunc {
+			continue
+		}
+		if idx == 0 {
+			return imp.TypeIndex, true
+		}
+		idx--
+	}
+	if int(idx) < len(m.Functions) {
+		return m.Functions[idx], true
+	}
+	return 0, false
+}
+
+// Custom returns the data of the first custom section with the given name.
+func (m *Module) Custom(name string) ([]byte, bool) {
+	for _, c := range m.Customs {
+		if c.Name == name {
+			return c.Data, true
+		}
+	}
+	return nil, false
+}
+
+// RawSection is a section as it appears in the binary.
+type RawSection struct {
+	ID SectionID
+	// Name is the name of a custom section.
+	Name string
+	// Payload is the section content; for custom sections it excludes the name.
+	Payload []byte
+	// Offset is where Payload starts in the binary.
+	Offset int
+}
+
+// ReadSections splits a binary into its sections without decoding them. It
+// checks the magic number but accepts any version, so it also serves tools
+// that only need custom sections.
+func ReadSections(b []byte) ([]RawSection, error) {
+	if len(b) < 8 {
+		return nil, errors.WrapWasmInvalid("file too short")
+	}
+	if !bytes.Equal(b[:4], Magic) {
+		return nil, errors.WrapWasmInvalid("bad magic bytes")
+	}
+	var sections []RawSection
+	pos := 8
+	for pos < len(b) {
+		id := SectionID(b[pos])
+		pos++
+		size, n, err := ReadU32(b, pos)
+		if err != nil {
+			return nil, fmt.Errorf("section %d size: %w", id, err)
+		}
+		pos += n
+		end := pos + int(size)
+		if end > len(b) || end < pos {
+			return nil, errors.WrapWasmInvalid("section extends past end of file")
+		}
+		s := RawSection{ID: id, Payload: b[pos:end], Offset: pos}
+		if id == SectionCustom {
+			name, n, err := readName(s.Payload, 0)
+			if err != nil {
+				return nil, fmt.Errorf("custom section name: %w", err)
+			}
+			s.Name = name
+			s.Payload = s.Payload[n:]
+			s.Offset += n
+		}
+		sections = append(sections, s)
+		pos = end
+	}
+	return sections, nil
+}
+
+// CustomSection returns the payload of the first custom section named name,
+// or nil if there is none.
+func CustomSection(b []byte, name string) ([]byte, error) {
+	sections, err := ReadSections(b)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sections {
+		if s.ID == SectionCustom && s.Name == name {
+			return s.Payload, nil
+		}
+	}
+	return nil, nil
+}
+
+// ReadCode decodes the function bodies of the code section without parsing
+// the other sections, for tools that must cope with modules Parse rejects.
+// Like ReadSections it accepts any version. It returns nil if the module has
+// no code section.
+func ReadCode(b []byte) ([]Func, error) {
+	sections, err := ReadSections(b)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sections {
+		if s.ID != SectionCode {
+			continue
+		}
+		var code []Func
+		r := &reader{b: s.Payload, base: s.Offset}
+		err := r.vec(func() error {
+			f, err := r.function()
+			code = append(code, f)
+			return err
+		}, func(n uint32) { code = make([]Func, 0, n) })
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %w", s.ID, err)
+		}
+		return code, nil
+	}
+	return nil, nil
+}
+
+// Parse decodes a WASM module.
+func Parse(b []byte) (*Module, error) {
+	sections, err := ReadSections(b)
+	if err != nil {
+		return nil, err
+	}
+	if v := binary.LittleEndian.Uint32(b[4:8]); v != Version {
+		return nil, errors.WrapWasmInvalid(fmt.Sprintf("unsupported version %d", v))
+	}
+
+	m := &Module{}
+	last := SectionCustom
+	for _, s := range sections {
+		if s.ID == SectionCustom {
+			data := make([]byte, len(s.Payload))
+			copy(data, s.Payload)
+			m.Customs = append(m.Customs, Custom{Name: s.Name, Data: data, After: last})
+			continue
+		}
+		rank := sectionRank(s.ID)
+		if rank < 0 {
+			return nil, errors.WrapWasmInvalid(fmt.Sprintf("unknown section id %d", s.ID))
+		}
+		if rank <= sectionRank(last) {
+			return nil, errors.WrapWasmInvalid(fmt.Sprintf("section %d out of order", s.ID))
+		}
+		last = s.ID
+
+		r := &reader{b: s.Payload, base: s.Offset}
+		if err := m.parseSection(s.ID, r); err != nil {
+			return nil, fmt.Errorf("section %d: %w", s.ID, err)
+		}
+		if r.pos != len(r.b) {
+			return nil, errors.WrapWasmInvalid(fmt.Sprintf("section %d has trailing bytes", s.ID))
+		}
+	}
+	if len(m.Functions) != len(m.Code) {
+		return nil, errors.WrapWasmInvalid(fmt.Sprintf("function and code section lengths differ: %d vs %d", len(m.Functions), len(m.Code)))
+	}
+	return m, nil
+}
+
+func (m *Module) parseSection(id SectionID, r *reader) error {
+	switch id {
+	case SectionType:
+		return r.vec(func() error {
+			ft, err := r.funcType()
+			m.Types = append(m.Types, ft)
+			return err
+		}, func(n uint32) { m.Types = make([]FuncType, 0, n) })
+	case SectionImport:
+		return r.vec(func() error {
+			imp, err := r.importEntry()
+			m.Imports = append(m.Imports, imp)
+			return err
+		}, func(n uint32) { m.Imports = make([]Import, 0, n) })
+	case SectionFunction:
+		return r.vec(func() error {
+			idx, err := r.u32()
+			m.Functions = append(m.Functions, idx)
+			return err
+		}, func(n uint32) { m.Functions = make([]uint32, 0, n) })
+	case SectionTable:
+		m.Tables = r.rest()
+	case SectionMemory:
+		m.Memories = r.rest()
+	case SectionTag:
+		m.Tags = r.rest()
+	case SectionGlobal:
+		return r.vec(func() error {
+			g, err := r.global()
+			m.Globals = append(m.Globals, g)
+			return err
+		}, func(n uint32) { m.Globals = make([]Global, 0, n) })
+	case SectionExport:
+		return r.vec(func() error {
+			e, err := r.export()
+			m.Exports = append(m.Exports, e)
+			return err
+		}, func(n uint32) { m.Exports = make([]Export, 0, n) })
+	case SectionStart:
+		idx, err := r.u32()
+		m.Start = &idx
+		return err
+	case SectionElement:
+		return r.vec(func() error {
+			e, err := r.element()
+			m.Elements = append(m.Elements, e)
+			return err
+		}, func(n uint32) { m.Elements = make([]Element, 0, n) })
+	case SectionDataCount:
+		n, err := r.u32()
+		m.DataCount = &n
+		return err
+	case SectionCode:
+		m.CodeOffset = r.base
+		return r.vec(func() error {
+			f, err := r.function()
+			m.Code = append(m.Code, f)
+			return err
+		}, func(n uint32) { m.Code = make([]Func, 0, n) })
+	case SectionData:
+		return r.vec(func() error {
+			d, err := r.data()
+			m.Data = append(m.Data, d)
+			return err
+		}, func(n uint32) { m.Data = make([]Data, 0, n) })
+	}
+	return nil
+}
+
+// reader decodes one section payload. base is the payload's offset in the
+// binary, for recording positions.
+type reader struct {
+	b    []byte
+	pos  int
+	base int
+}
+
+func (r *reader) u32() (uint32, error) {
+	v, n, err := ReadU32(r.b, r.pos)
+	r.pos += n
+	return v, err
+}
+
+func (r *reader) byte() (byte, error) {
+	if r.pos >= len(r.b) {
+		return 0, errors.WrapWasmInvalid("unexpected end of section")
+	}
+	c := r.b[r.pos]
+	r.pos++
+	return c, nil
+}
+
+func (r *reader) bytes(n uint32) ([]byte, error) {
+	if uint64(r.pos)+uint64(n) > uint64(len(r.b)) {
+		return nil, errors.WrapWasmInvalid("unexpected end of section")
+	}
+	out := make([]byte, n)
+	copy(out, r.b[r.pos:])
+	r.pos += int(n)
+	return out, nil
+}
+
+func (r *reader) rest() []byte {
+	out := make([]byte, len(r.b)-r.pos)
+	copy(out, r.b[r.pos:])
+	r.pos = len(r.b)
+	return out
+}
+
+// vec reads a count followed by that many items. alloc is called with the
+// count before the first item so that an empty section decodes to an empty,
+// non-nil slice.
+func (r *reader) vec(item func() error, alloc func(n uint32)) error {
+	n, err := r.u32()
+	if err != nil {
+		return err
+	}
+	// Every item takes at least one byte; reject counts the payload cannot hold.
+	if uint64(n) > uint64(len(r.b)-r.pos) {
+		return errors.WrapWasmInvalid("vector length exceeds section")
+	}
+	if alloc != nil {
+		alloc(n)
+	}
+	for i := uint32(0); i < n; i++ {
+		if err := item(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *reader) name() (string, error) {
+	s, n, err := readName(r.b, r.pos)
+	r.pos += n
+	return s, err
+}
+
+func readName(b []byte, pos int) (string, int, error) {
+	l, n, err := ReadU32(b, pos)
+	if err != nil {
+		return "", 0, err
+	}
+	end := pos + n + int(l)
+	if uint64(pos+n)+uint64(l) > uint64(len(b)) {
+		return "", 0, errors.WrapWasmInvalid("name extends past section")
+	}
+	return string(b[pos+n : end]), n + int(l), nil
+}
+
+func (r *reader) valTypes() ([]ValType, error) {
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := r.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]ValType, n)
+	for i, c := range raw {
+		out[i] = ValType(c)
+	}
+	return out, nil
+}
+
+func (r *reader) funcType() (FuncType, error) {
+	form, err := r.byte()
+	if err != nil {
+		return FuncType{}, err
+	}
+	if form != 0x60 {
+		return FuncType{}, errors.WrapWasmInvalid(fmt.Sprintf("unsupported type form 0x%02x", form))
+	}
+	params, err := r.valTypes()
+	if err != nil {
+		return FuncType{}, err
+	}
+	results, err := r.valTypes()
+	return FuncType{Params: params, Results: results}, err
+}
+
+func (r *reader) limits() error {
+	_, n, err := ReadLimits(r.b, r.pos)
+	r.pos += n
+	return err
+}
+
+// Limits is the size range of a table or memory.
+type Limits struct {
+	Min    uint32
+	Max    uint32
+	HasMax bool
+}
+
+// ReadLimits decodes limits at b[pos], returning them and the bytes consumed.
+func ReadLimits(b []byte, pos int) (Limits, int, error) {
+	var l Limits
+	if pos >= len(b) {
+		return l, 0, errors.WrapWasmInvalid("limits truncated")
+	}
+	p := pos + 1
+	min, n, err := ReadU32(b, p)
+	if err != nil {
+		return l, 0, err
+	}
+	l.Min = min
+	p += n
+	if b[pos]&0x01 != 0 {
+		if l.Max, n, err = ReadU32(b, p); err != nil {
+			return l, 0, err
+		}
+		l.HasMax = true
+		p += n
+	}
+	return l, p - pos, nil
+}
+
+// TableType is the element type and size of a table.
+type TableType struct {
+	Elem   ValType
+	Limits Limits
+}
+
+// TableTypes decodes the module-defined tables.
+func (m *Module) TableTypes() ([]TableType, error) {
+	var out []TableType
+	if m.Tables == nil {
+		return nil, nil
+	}
+	r := &reader{b: m.Tables}
+	err := r.vec(func() error {
+		elem, err := r.byte()
+		if err != nil {
+			return err
+		}
+		l, n, err := ReadLimits(r.b, r.pos)
+		r.pos += n
+		out = append(out, TableType{Elem: ValType(elem), Limits: l})
+		return err
+	}, nil)
+	return out, err
+}
+
+// MemoryTypes decodes the module-defined memories.
+func (m *Module) MemoryTypes() ([]Limits, error) {
+	var out []Limits
+	if m.Memories == nil {
+		return nil, nil
+	}
+	r := &reader{b: m.Memories}
+	err := r.vec(func() error {
+		l, n, err := ReadLimits(r.b, r.pos)
+		r.pos += n
+		out = append(out, l)
+		return err
+	}, nil)
+	return out, err
+}
+
+func (r *reader) importEntry() (Import, error) {
+	var imp Import
+	var err error
+	if imp.Module, err = r.name(); err != nil {
+		return imp, err
+	}
+	if imp.Name, err = r.name(); err != nil {
+		return imp, err
+	}
+	kind, err := r.byte()
+	if err != nil {
+		return imp, err
+	}
+	imp.Kind = ExternalKind(kind)
+	start := r.pos
+	switch imp.Kind {
+	case KindFunc:
+		imp.TypeIndex, err = r.u32()
+		return imp, err
+	case KindTable:
+		if _, err = r.byte(); err == nil {
+			err = r.limits()
+		}
+	case KindMemory:
+		err = r.limits()
+	case KindGlobal:
+		_, err = r.bytes(2)
+	case KindTag:
+		if _, err = r.byte(); err == nil {
+			_, err = r.u32()
+		}
+	default:
+		return imp, errors.WrapWasmInvalid(fmt.Sprintf("unsupported import kind %d", kind))
+	}
+	if err != nil {
+		return imp, err
+	}
+	imp.Desc = append([]byte(nil), r.b[start:r.pos]...)
+	return imp, nil
+}
+
+func (r *reader) global() (Global, error) {
+	var g Global
+	t, err := r.byte()
+	if err != nil {
+		return g, err
+	}
+	mut, err := r.byte()
+	if err != nil {
+		return g, err
+	}
+	if mut > 1 {
+		return g, errors.WrapWasmInvalid("invalid global mutability")
+	}
+	g.Type, g.Mutable = ValType(t), mut == 1
+	g.Init, err = r.constExpr()
+	return g, err
+}
+
+func (r *reader) export() (Export, error) {
+	var e Export
+	var err error
+	if e.Name, err = r.name(); err != nil {
+		return e, err
+	}
+	kind, err := r.byte()
+	if err != nil {
+		return e, err
+	}
+	e.Kind = ExternalKind(kind)
+	e.Index, err = r.u32()
+	return e, err
+}
+
+func (r *reader) constExpr() ([]byte, error) {
+	n, err := exprLen(r.b[r.pos:])
+	if err != nil {
+		return nil, err
+	}
+	return r.bytes(uint32(n))
+}
+
+func (r *reader) element() (Element, error) {
+	var e Element
+	var err error
+	if e.Flags, err = r.u32(); err != nil {
+		return e, err
+	}
+	if e.Flags > 7 {
+		return e, errors.WrapWasmInvalid(fmt.Sprintf("unsupported element flags %d", e.Flags))
+	}
+	active := e.Flags&0x01 == 0
+	if active && e.Flags&0x02 != 0 {
+		if e.Table, err = r.u32(); err != nil {
+			return e, err
+		}
+	}
+	if active {
+		if e.Offset, err = r.constExpr(); err != nil {
+			return e, err
+		}
+	}
+	// Flags 0 and 4 imply funcref and omit the kind byte.
+	if e.Flags&0x03 != 0 {
+		if e.Kind, err = r.byte(); err != nil {
+			return e, err
+		}
+	}
+	if e.Flags&0x04 == 0 {
+		err = r.vec(func() error {
+			idx, err := r.u32()
+			e.Funcs = append(e.Funcs, idx)
+			return err
+		}, func(n uint32) { e.Funcs = make([]uint32, 0, n) })
+		return e, err
+	}
+	err = r.vec(func() error {
+		expr, err := r.constExpr()
+		e.Exprs = append(e.Exprs, expr)
+		return err
+	}, func(n uint32) { e.Exprs = make([][]byte, 0, n) })
+	return e, err
+}
+
+func (r *reader) function() (Func, error) {
+	var f Func
+	size, err := r.u32()
+	if err != nil {
+		return f, err
+	}
+	end := r.pos + int(size)
+	if uint64(r.pos)+uint64(size) > uint64(len(r.b)) {
+		return f, errors.WrapWasmInvalid("function body extends past section")
+	}
+	body := &reader{b: r.b[:end], pos: r.pos}
+	err = body.vec(func() error {
+		count, err := body.u32()
+		if err != nil {
+			return err
+		}
+		t, err := body.byte()
+		f.Locals = append(f.Locals, Local{Count: count, Type: ValType(t)})
+		return err
+	}, func(n uint32) { f.Locals = make([]Local, 0, n) })
+	if err != nil {
+		return f, err
+	}
+	f.Offset = r.base + body.pos
+	f.Body = append([]byte(nil), r.b[body.pos:end]...)
+	r.pos = end
+	return f, nil
+}
+
+func (r *reader) data() (Data, error) {
+	var d Data
+	var err error
+	if d.Flags, err = r.u32(); err != nil {
+		return d, err
+	}
+	switch d.Flags {
+	case 0, 2:
+		if d.Flags == 2 {
+			if d.Memory, err = r.u32(); err != nil {
+				return d, err
+			}
+		}
+		if d.Offset, err = r.constExpr(); err != nil {
+			return d, err
+		}
+	case 1:
+	default:
+		return d, errors.WrapWasmInvalid(fmt.Sprintf("unsupported data flags %d", d.Flags))
+	}
+	n, err := r.u32()
+	if err != nil {
+		return d, err
+	}
+	d.Init, err = r.bytes(n)
+	return d, err
+}
@@ -0,0 +1,204 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"testing"
+
+	"github.com/dotandev/hintents/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testModule returns a module that uses every typed section.
+func testModule() *Module {
+	start := uint32(1)
+	dataCount := uint32(2)
+	return &Module{
+		Types: []FuncType{
+			{},
+			{Params: []ValType{I32, I64}, Results: []ValType{I64}},
+		},
+		Imports: []Import{
+			{Module: "x", Name: "_", Kind: KindFunc, TypeIndex: 1},
+			{Module: "env", Name: "memory", Kind: KindMemory, Desc: []byte{0x00, 0x01}},
+			{Module: "env", Name: "g", Kind: KindGlobal, Desc: []byte{byte(I32), 0x00}},
+		},
+		Functions: []uint32{0, 1, 0},
+		Tables:    []byte{0x01, byte(FuncRef), 0x00, 0x02},
+		Globals: []Global{
+			{Type: I32, Mutable: true, Init: []byte{0x41, 0x80, 0x80, 0x04, OpEnd}},
+			{Type: FuncRef, Init: []byte{OpRefFunc, 0x02, OpEnd}},
+		},
+		Exports: []Export{
+			{Name: "run", Kind: KindFunc, Index: 1},
+			{Name: "memory", Kind: KindMemory, Index: 0},
+		},
+		Start: &start,
+		Elements: []Element{
+			{Flags: 0, Offset: []byte{0x41, 0x00, OpEnd}, Funcs: []uint32{1, 2}},
+			{Flags: 1, Kind: 0x00, Funcs: []uint32{3}},
+			{Flags: 2, Table: 0, Offset: []byte{0x41, 0x01, OpEnd}, Kind: 0x00, Funcs: []uint32{}},
+			{Flags: 5, Kind: byte(FuncRef), Exprs: [][]byte{{OpRefFunc, 0x01, OpEnd}, {0xd0, byte(FuncRef), OpEnd}}},
+		},
+		DataCount: &dataCount,
+		Code: []Func{
+			{Locals: []Local{}, Body: []byte{0x01, OpEnd}},
+			{Locals: []Local{{Count: 2, Type: I32}, {Count: 1, Type: I64}}, Body: []byte{OpCall, 0x00, 0x1a, OpEnd}},
+			{Locals: []Local{}, Body: []byte{OpBlock, 0x40, 0x41, 0x0b, 0x1a, OpEnd, OpEnd}},
+		},
+		Data: []Data{
+			{Flags: 0, Offset: []byte{0x41, 0x10, OpEnd}, Init: []byte("hello")},
+			{Flags: 1, Init: []byte{1, 2, 3}},
+		},
+		Customs: []Custom{
+			{Name: "first", Data: []byte{0xaa}, After: SectionCustom},
+			{Name: "contractspecv0", Data: []byte{1, 2, 3}, After: SectionCode},
+			{Name: "name", Data: []byte{}, After: SectionData},
+		},
+	}
+}
+
+func TestEncodeParse_RoundTrip(t *testing.T) {
+	bin, err := testModule().Encode()
+	require.NoError(t, err)
+
+	m, err := Parse(bin)
+	require.NoError(t, err)
+
+	again, err := m.Encode()
+	require.NoError(t, err)
+	assert.Equal(t, bin, again, "re-encoding a parsed module should be byte-identical")
+
+	assert.Equal(t, uint32(1), m.NumImportedFuncs())
+	assert.Len(t, m.Code, 3)
+	assert.Equal(t, []Local{{Count: 2, Type: I32}, {Count: 1, Type: I64}}, m.Code[1].Locals)
+	// i32.const 11 encodes as 0x41 0x0b: the block's end must not be
+	// mistaken for it.
+	assert.Equal(t, []byte{OpBlock, 0x40, 0x41, 0x0b, 0x1a, OpEnd, OpEnd}, m.Code[2].Body)
+
+	spec, ok := m.Custom("contractspecv0")
+	require.True(t, ok)
+	assert.Equal(t, []byte{1, 2, 3}, spec)
+}
+
+func TestParse_FuncOffsets(t *testing.T) {
+	bin, err := testModule().Encode()
+	require.NoError(t, err)
+	m, err := Parse(bin)
+	require.NoError(t, err)
+
+	for i, f := range m.Code {
+		assert.Equal(t, f.Body, bin[f.Offset:f.Offset+len(f.Body)], "function %d", i)
+	}
+	assert.Greater(t, m.CodeOffset, 8)
+	assert.Less(t, m.CodeOffset, m.Code[0].Offset)
+}
+
+func TestParse_PreservesPaddedCode(t *testing.T) {
+	// call with a five-byte padded index, as wasm-ld emits.
+	m := &Module{
+		Types:     []FuncType{{}},
+		Functions: []uint32{0},
+		Code:      []Func{{Locals: []Local{}, Body: []byte{OpCall, 0x80, 0x80, 0x80, 0x80, 0x00, OpEnd}}},
+	}
+	bin, err := m.Encode()
+	require.NoError(t, err)
+	parsed, err := Parse(bin)
+	require.NoError(t, err)
+	out, err := parsed.Encode()
+	require.NoError(t, err)
+	assert.Equal(t, bin, out)
+}
+
+func TestParse_Errors(t *testing.T) {
+	valid, err := testModule().Encode()
+	require.NoError(t, err)
+
+	tests := map[string][]byte{
+		"too short":   {0x00, 0x61},
+		"bad magic":   {0x01, 0x02, 0x03, 0x04, 0x01, 0x00, 0x00, 0x00},
+		"bad version": {0x00, 0x61, 0x73, 0x6d, 0x02, 0x00, 0x00, 0x00},
+		"truncated":   valid[:len(valid)-3],
+		"out of order": append(append([]byte{}, Magic...), 0x01, 0x00, 0x00, 0x00,
+			byte(SectionFunction), 0x01, 0x00,
+			byte(SectionType), 0x01, 0x00),
+		"function without code": append(append([]byte{}, Magic...), 0x01, 0x00, 0x00, 0x00,
+			byte(SectionType), 0x04, 0x01, 0x60, 0x00, 0x00,
+			byte(SectionFunction), 0x02, 0x01, 0x00),
+		"trailing bytes": append(append([]byte{}, Magic...), 0x01, 0x00, 0x00, 0x00,
+			byte(SectionType), 0x02, 0x00, 0xff),
+	}
+	for name, bin := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(bin)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, errors.ErrWasmInvalid), "got %v", err)
+		})
+	}
+}
+
+func TestCustomSection(t *testing.T) {
+	bin, err := testModule().Encode()
+	require.NoError(t, err)
+
+	data, err := CustomSection(bin, "contractspecv0")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, data)
+
+	data, err = CustomSection(bin, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, data)
+
+	// Section-level reads accept any version.
+	other := append([]byte{}, bin...)
+	other[4] = 2
+	data, err = CustomSection(other, "first")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xaa}, data)
+}
+
+func TestReadSections_Offsets(t *testing.T) {
+	bin, err := testModule().Encode()
+	require.NoError(t, err)
+	sections, err := ReadSections(bin)
+	require.NoError(t, err)
+	for _, s := range sections {
+		assert.Equal(t, s.Payload, bin[s.Offset:s.Offset+len(s.Payload)])
+	}
+}
+
+func TestReadCode(t *testing.T) {
+	bin, err := testModule().Encode()
+	require.NoError(t, err)
+	m, err := Parse(bin)
+	require.NoError(t, err)
+
+	// A section Parse rejects does not stop the code from being read.
+	broken := append(append([]byte{}, bin...), 0x7f, 0x00)
+	_, err = Parse(broken)
+	require.Error(t, err)
+
+	code, err := ReadCode(broken)
+	require.NoError(t, err)
+	assert.Equal(t, m.Code, code)
+
+	code, err = ReadCode(append([]byte{}, bin[:8]...))
+	require.NoError(t, err)
+	assert.Nil(t, code)
+}
+
+func TestModule_IndexSpaces(t *testing.T) {
+	m := testModule()
+	assert.Equal(t, uint32(1), m.NumImportedGlobals())
+
+	typ, ok := m.FuncTypeIndex(0)
+	require.True(t, ok)
+	assert.Equal(t, uint32(1), typ, "imported function")
+	typ, ok = m.FuncTypeIndex(2)
+	require.True(t, ok)
+	assert.Equal(t, uint32(1), typ, "second defined function")
+	_, ok = m.FuncTypeIndex(4)
+	assert.False(t, ok)
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"fmt"
+
+	"github.com/dotandev/hintents/internal/errors"
+)
+
+// Name subsection IDs of the "name" custom section.
+const (
+	nameModule    = 0
+	nameFunctions = 1
+	nameLocals    = 2
+	nameGlobals   = 7
+)
+
+// Names holds the debug names from a module's "name" custom section.
+// Functions and Globals are keyed by index; Locals by function index, then
+// local index.
+type Names struct {
+	Module    string
+	Functions map[uint32]string
+	Locals    map[uint32]map[uint32]string
+	Globals   map[uint32]string
+}
+
+// ParseNames decodes the payload of a "name" custom section. Subsections it
+// does not use are skipped.
+func ParseNames(data []byte) (*Names, error) {
+	names := &Names{
+		Functions: make(map[uint32]string),
+		Locals:    make(map[uint32]map[uint32]string),
+		Globals:   make(map[uint32]string),
+	}
+	for pos := 0; pos < len(data); {
+		id := data[pos]
+		size, n, err := ReadU32(data, pos+1)
+		if err != nil {
+			return nil, err
+		}
+		start := pos + 1 + n
+		end := start + int(size)
+		if end > len(data) {
+			return nil, errors.WrapWasmInvalid(fmt.Sprintf("name subsection %d overruns the section", id))
+		}
+		r := &reader{b: data[:end], pos: start}
+		switch id {
+		case nameModule:
+			names.Module, err = r.name()
+		case nameFunctions:
+			err = r.nameMap(names.Functions)
+		case nameGlobals:
+			err = r.nameMap(names.Globals)
+		case nameLocals:
+			err = r.vec(func() error {
+				fn, err := r.u32()
+				if err != nil {
+					return err
+				}
+				locals := make(map[uint32]string)
+				names.Locals[fn] = locals
+				return r.nameMap(locals)
+			}, nil)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("name subsection %d: %w", id, err)
+		}
+		pos = end
+	}
+	return names, nil
+}
+
+// nameMap reads a vector of index/name pairs into m.
+func (r *reader) nameMap(m map[uint32]string) error {
+	return r.vec(func() error {
+		idx, err := r.u32()
+		if err != nil {
+			return err
+		}
+		name, err := r.name()
+		if err != nil {
+			return err
+		}
+		m[idx] = name
+		return nil
+	}, nil)
+}
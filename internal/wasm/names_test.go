@@ -0,0 +1,57 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func nameEntry(b []byte, idx uint32, name string) []byte {
+	b = AppendU32(b, idx)
+	b = AppendU32(b, uint32(len(name)))
+	return append(b, name...)
+}
+
+func subsection(id byte, payload []byte) []byte {
+	return append(AppendU32([]byte{id}, uint32(len(payload))), payload...)
+}
+
+func TestParseNames(t *testing.T) {
+	funcs := nameEntry([]byte{0x02}, 0, "_ZN4test4main17h0123456789abcdefE")
+	funcs = nameEntry(funcs, 3, "helper")
+	locals := nameEntry([]byte{0x01, 0x03, 0x01}, 0, "amount")
+	globals := nameEntry([]byte{0x01}, 0, "__stack_pointer")
+
+	var data []byte
+	data = append(data, subsection(nameModule, []byte{0x04, 't', 'e', 's', 't'})...)
+	data = append(data, subsection(nameFunctions, funcs)...)
+	data = append(data, subsection(nameLocals, locals)...)
+	data = append(data, subsection(5, []byte{0xff, 0xff})...) // unused kind
+	data = append(data, subsection(nameGlobals, globals)...)
+
+	names, err := ParseNames(data)
+	require.NoError(t, err)
+	assert.Equal(t, "test", names.Module)
+	assert.Equal(t, "helper", names.Functions[3])
+	assert.Equal(t, "amount", names.Locals[3][0])
+	assert.Equal(t, "__stack_pointer", names.Globals[0])
+
+	_, err = ParseNames(subsection(nameFunctions, funcs)[:5])
+	assert.Error(t, err)
+}
+
+func TestTableAndMemoryTypes(t *testing.T) {
+	m := testModule()
+	tables, err := m.TableTypes()
+	require.NoError(t, err)
+	assert.Equal(t, []TableType{{Elem: FuncRef, Limits: Limits{Min: 2}}}, tables)
+
+	m.Memories = []byte{0x01, 0x01, 0x11, 0x20}
+	mems, err := m.MemoryTypes()
+	require.NoError(t, err)
+	assert.Equal(t, []Limits{{Min: 17, Max: 32, HasMax: true}}, mems)
+}
@@ -0,0 +1,457 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+// Package wasmaudit analyzes Soroban contract bytecode for security issues
+// without executing it. It walks the call graph from each exported function,
+// as built by the dce package, looking for storage writes without
+// authorization, arithmetic that aborts through a panic, loops over storage
+// and persistent writes whose TTL is never extended. Issues are mapped back
+// to source lines when the module carries DWARF debug info.
+//
+// The analysis is heuristic: it sees which host functions can be reached,
+// not the conditions under which they are called.
+package wasmaudit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/dce"
+	"github.com/dotandev/hintents/internal/demangle"
+	"github.com/dotandev/hintents/internal/dwarf"
+	"github.com/dotandev/hintents/internal/wasm"
+)
+
+// Kind identifies what an Issue is about.
+type Kind string
+
+const (
+	// KindMissingAuth is an exported function that writes contract storage
+	// with no require_auth reachable from it.
+	KindMissingAuth Kind = "missing-auth"
+	// KindArithmeticPanic is integer arithmetic whose overflow check aborts
+	// the contract through a panic or unreachable instead of returning an
+	// error.
+	KindArithmeticPanic Kind = "arithmetic-panic"
+	// KindStorageLoop is a loop that reads or writes contract storage on
+	// every iteration.
+	KindStorageLoop Kind = "storage-loop"
+	// KindMissingTTL is an exported function that writes persistent storage
+	// with no extend_contract_data_ttl reachable from it.
+	KindMissingTTL Kind = "missing-ttl-extension"
+)
+
+// Issue is a single finding in the bytecode.
+type Issue struct {
+	Kind Kind `json:"kind"`
+	// Export is the exported function the issue was found from.
+	Export string `json:"export"`
+	// Func is the index of the function containing the instruction.
+	Func uint32 `json:"func"`
+	// Function is the function's demangled debug name, or its export name or
+	// func[N] in stripped builds.
+	Function string `json:"function"`
+	// Offset is the instruction's byte offset in the module, as accepted by
+	// erst wat --offset.
+	Offset uint64 `json:"offset"`
+	Detail string `json:"detail"`
+	// Source is the instruction's source location, when the module has
+	// DWARF line info for it.
+	Source *dwarf.SourceLocation `json:"source,omitempty"`
+}
+
+// Report is the outcome of auditing one module.
+type Report struct {
+	// Exports is the number of exported functions analyzed.
+	Exports int `json:"exports"`
+	// DebugInfo reports whether the module has DWARF info to map issues to
+	// source.
+	DebugInfo bool    `json:"debug_info"`
+	Issues    []Issue `json:"issues"`
+}
+
+// Opcodes the audit interprets that the wasm package does not name.
+const (
+	opUnreachable byte = 0x00
+	opBr          byte = 0x0c
+	opBrIf        byte = 0x0d
+	opBrTable     byte = 0x0e
+	opReturn      byte = 0x0f
+	opI64Const    byte = 0x42
+)
+
+// arithOps are the integer add, sub and mul instructions, whose overflow
+// checks rustc emits as a compare and branch to a panic.
+var arithOps = map[byte]bool{
+	0x6a: true, 0x6b: true, 0x6c: true, // i32.add, i32.sub, i32.mul
+	0x7c: true, 0x7d: true, 0x7e: true, // i64.add, i64.sub, i64.mul
+}
+
+// overflowCheckWindow is how many instructions after an arithmetic
+// instruction its overflow check branch may appear.
+const overflowCheckWindow = 6
+
+// Analyze audits a WASM module.
+func Analyze(wasmBytes []byte) (*Report, error) {
+	mod, err := wasm.Parse(wasmBytes)
+	if err != nil {
+		return nil, err
+	}
+	a, err := newAuditor(mod)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Issues: []Issue{}}
+	// The first export a function is reachable from; function-level issues
+	// are reported once, under it.
+	firstExport := make(map[uint32]string)
+	for _, exp := range mod.Exports {
+		if exp.Kind != wasm.KindFunc {
+			continue
+		}
+		report.Exports++
+		reach, err := dce.ReachableFrom(mod, []uint32{exp.Index})
+		if err != nil {
+			return nil, err
+		}
+		for idx := range reach {
+			if _, ok := firstExport[idx]; !ok {
+				firstExport[idx] = exp.Name
+			}
+		}
+		report.Issues = append(report.Issues, a.checkExport(exp, sortedFuncs(reach))...)
+	}
+
+	funcs := make(map[uint32]bool, len(firstExport))
+	for idx := range firstExport {
+		funcs[idx] = true
+	}
+	for _, idx := range sortedFuncs(funcs) {
+		if idx < a.imported {
+			continue
+		}
+		report.Issues = append(report.Issues, a.checkFunction(idx, firstExport[idx])...)
+	}
+
+	if parser, err := dwarf.NewParser(wasmBytes); err == nil && parser.HasDebugInfo() {
+		report.DebugInfo = true
+		for i := range report.Issues {
+			addr := report.Issues[i].Offset - uint64(mod.CodeOffset)
+			if loc, err := parser.GetSourceLocation(addr); err == nil {
+				report.Issues[i].Source = loc
+			}
+		}
+	}
+	return report, nil
+}
+
+type auditor struct {
+	mod      *wasm.Module
+	imported uint32
+	host     map[uint32]hostFunc
+	// rawNames are the symbols from the name section.
+	rawNames map[uint32]string
+	// code holds the decoded body of each defined function, by function
+	// index.
+	code map[uint32][]wasm.Instr
+	// storage is the storage host function each defined function can
+	// reach, if any.
+	storage map[uint32]hostFunc
+}
+
+func newAuditor(mod *wasm.Module) (*auditor, error) {
+	a := &auditor{
+		mod:      mod,
+		imported: mod.NumImportedFuncs(),
+		host:     hostFuncs(mod),
+		rawNames: map[uint32]string{},
+		code:     make(map[uint32][]wasm.Instr, len(mod.Code)),
+	}
+	if data, ok := mod.Custom("name"); ok {
+		if names, err := wasm.ParseNames(data); err == nil {
+			a.rawNames = names.Functions
+		}
+	}
+	for i, f := range mod.Code {
+		idx := a.imported + uint32(i)
+		instrs, err := wasm.DecodeExpr(f.Body)
+		if err != nil {
+			return nil, fmt.Errorf("function %d: %w", idx, err)
+		}
+		a.code[idx] = instrs
+	}
+	a.storage = a.storageReach()
+	return a, nil
+}
+
+// callee returns the function a call instruction calls.
+func callee(in wasm.Instr) (uint32, bool) {
+	if in.Op != wasm.OpCall && in.Op != wasm.OpReturnCall {
+		return 0, false
+	}
+	return in.FuncIndex()
+}
+
+// storageReach finds, for every defined function, a storage host function
+// it calls directly or through other defined functions.
+func (a *auditor) storageReach() map[uint32]hostFunc {
+	reach := make(map[uint32]hostFunc)
+	for changed := true; changed; {
+		changed = false
+		for i := range a.mod.Code {
+			idx := a.imported + uint32(i)
+			if reach[idx] != hostNone {
+				continue
+			}
+			for _, in := range a.code[idx] {
+				target, ok := callee(in)
+				if !ok {
+					continue
+				}
+				h := a.host[target]
+				if !h.accessesStorage() {
+					h = reach[target]
+				}
+				if h != hostNone {
+					reach[idx] = h
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return reach
+}
+
+// site is an instruction in a defined function.
+type site struct {
+	fn uint32
+	in wasm.Instr
+}
+
+func (a *auditor) issue(kind Kind, export string, s site, detail string) Issue {
+	return Issue{
+		Kind:     kind,
+		Export:   export,
+		Func:     s.fn,
+		Function: a.funcName(s.fn),
+		Offset:   uint64(a.mod.Code[s.fn-a.imported].Offset + s.in.Offset),
+		Detail:   detail,
+	}
+}
+
+// checkExport looks for storage writes without authorization and
+// persistent writes without TTL extension among the functions reachable
+// from an export.
+func (a *auditor) checkExport(exp wasm.Export, reach []uint32) []Issue {
+	var writes, persistent []site
+	authorized, extends := false, false
+	for _, idx := range reach {
+		switch h := a.host[idx]; {
+		case h.requiresAuth():
+			authorized = true
+		case h == hostExtendDataTTL:
+			extends = true
+		}
+
+		instrs := a.code[idx]
+		for i, in := range instrs {
+			target, ok := callee(in)
+			if !ok || !a.host[target].writesStorage() {
+				continue
+			}
+			writes = append(writes, site{idx, in})
+			if a.host[target] == hostPutData && i > 0 && constI64(instrs[i-1]) == storagePersistent {
+				persistent = append(persistent, site{idx, in})
+			}
+		}
+	}
+
+	var issues []Issue
+	// Constructors run once, when the deployer creates the contract.
+	if len(writes) > 0 && !authorized && exp.Name != "__constructor" {
+		issues = append(issues, a.issue(KindMissingAuth, exp.Name, writes[0], fmt.Sprintf(
+			"%s writes contract storage (%d call site(s)) but never reaches require_auth or require_auth_for_args",
+			exp.Name, len(writes))))
+	}
+	if len(persistent) > 0 && !extends {
+		issues = append(issues, a.issue(KindMissingTTL, exp.Name, persistent[0], fmt.Sprintf(
+			"%s writes persistent storage (%d call site(s)) but never reaches extend_contract_data_ttl; the entries will be archived once their TTL runs out",
+			exp.Name, len(persistent))))
+	}
+	return issues
+}
+
+// checkFunction looks for storage loops and panicking arithmetic in one
+// defined function.
+func (a *auditor) checkFunction(idx uint32, export string) []Issue {
+	instrs := a.code[idx]
+	ends, targets := structure(instrs)
+	var issues []Issue
+
+	for i, in := range instrs {
+		// A loop left open as the last instruction has no body.
+		if in.Op != wasm.OpLoop || ends[i] <= i {
+			continue
+		}
+		for _, body := range instrs[i+1 : ends[i]] {
+			target, ok := callee(body)
+			if !ok {
+				continue
+			}
+			h := a.host[target]
+			if !h.accessesStorage() {
+				h = a.storage[target]
+			}
+			if h != hostNone {
+				issues = append(issues, a.issue(KindStorageLoop, export, site{idx, in}, fmt.Sprintf(
+					"loop in %s calls %s on every iteration; its cost grows with the data it iterates over",
+					a.funcName(idx), h)))
+				break
+			}
+		}
+	}
+
+	var arith []site
+	for i, in := range instrs {
+		if !arithOps[in.Op] {
+			continue
+		}
+		for j := i + 1; j < len(instrs) && j <= i+overflowCheckWindow; j++ {
+			op := instrs[j].Op
+			if op != wasm.OpIf && op != opBrIf {
+				continue
+			}
+			if a.branchPanics(instrs, ends, targets, j) {
+				arith = append(arith, site{idx, in})
+			}
+			break
+		}
+	}
+	if len(arith) > 0 {
+		issues = append(issues, a.issue(KindArithmeticPanic, export, arith[0], fmt.Sprintf(
+			"%s has %d arithmetic operation(s) whose overflow check panics instead of returning a contract error",
+			a.funcName(idx), len(arith))))
+	}
+	return issues
+}
+
+// branchPanics reports whether either outcome of the if or br_if at
+// instrs[i] leads straight to a panic.
+func (a *auditor) branchPanics(instrs []wasm.Instr, ends, targets map[int]int, i int) bool {
+	if instrs[i].Op == wasm.OpIf {
+		return a.panicsAt(instrs, i+1)
+	}
+	if a.panicsAt(instrs, i+1) {
+		return true
+	}
+	t, ok := targets[i]
+	return ok && t >= 0 && instrs[t].Op != wasm.OpLoop && a.panicsAt(instrs, ends[t]+1)
+}
+
+// panicsAt reports whether execution from instrs[p] reaches unreachable or
+// a call to a panic function, allowing for a few instructions that set up
+// the panic's arguments.
+func (a *auditor) panicsAt(instrs []wasm.Instr, p int) bool {
+	for k := p; k < len(instrs) && k < p+4; k++ {
+		in := instrs[k]
+		switch in.Op {
+		case opUnreachable:
+			return true
+		case wasm.OpCall:
+			target, _ := in.FuncIndex()
+			return a.isPanic(target)
+		case wasm.OpBlock, wasm.OpLoop, wasm.OpIf, wasm.OpElse, wasm.OpEnd,
+			opBr, opBrIf, opBrTable, opReturn, wasm.OpCallIndirect:
+			return false
+		}
+	}
+	return false
+}
+
+// isPanic reports whether a function is a panic: its symbol says so, or its
+// body runs straight into unreachable.
+func (a *auditor) isPanic(idx uint32) bool {
+	if strings.Contains(a.rawNames[idx], "panic") {
+		return true
+	}
+	instrs, ok := a.code[idx]
+	if !ok || len(instrs) < 2 || instrs[len(instrs)-2].Op != opUnreachable {
+		return false
+	}
+	for _, in := range instrs[:len(instrs)-1] {
+		switch in.Op {
+		case wasm.OpBlock, wasm.OpLoop, wasm.OpIf, opBr, opBrIf, opBrTable, opReturn:
+			return false
+		}
+	}
+	return true
+}
+
+// funcName returns a display name for a function.
+func (a *auditor) funcName(idx uint32) string {
+	if name, ok := a.rawNames[idx]; ok {
+		return demangle.DemangleSymbol(name)
+	}
+	for _, exp := range a.mod.Exports {
+		if exp.Kind == wasm.KindFunc && exp.Index == idx {
+			return exp.Name
+		}
+	}
+	return fmt.Sprintf("func[%d]", idx)
+}
+
+// structure pairs each block, loop and if in instrs with the index of its
+// end, and each br and br_if with the index of the construct it targets, or
+// -1 for the function body.
+func structure(instrs []wasm.Instr) (ends, targets map[int]int) {
+	ends = make(map[int]int)
+	targets = make(map[int]int)
+	var open []int
+	for i, in := range instrs {
+		switch in.Op {
+		case wasm.OpBlock, wasm.OpLoop, wasm.OpIf:
+			open = append(open, i)
+		case wasm.OpEnd:
+			if len(open) > 0 {
+				ends[open[len(open)-1]] = i
+				open = open[:len(open)-1]
+			}
+		case opBr, opBrIf:
+			depth, _, err := wasm.ReadU32(in.Imm, 0)
+			if err != nil || int(depth) >= len(open) {
+				targets[i] = -1
+			} else {
+				targets[i] = open[len(open)-1-int(depth)]
+			}
+		}
+	}
+	// Unterminated constructs run to the end of the body.
+	for _, i := range open {
+		ends[i] = len(instrs) - 1
+	}
+	return ends, targets
+}
+
+// constI64 returns the value of an i64.const, or 0 for other instructions.
+func constI64(in wasm.Instr) int64 {
+	if in.Op != opI64Const {
+		return 0
+	}
+	v, _, err := wasm.ReadS64(in.Imm, 0)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func sortedFuncs(set map[uint32]bool) []uint32 {
+	out := make([]uint32, 0, len(set))
+	for idx := range set {
+		out = append(out, idx)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
@@ -0,0 +1,253 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmaudit
+
+import (
+	"testing"
+
+	"github.com/dotandev/hintents/internal/wasm"
+)
+
+// Imported function indices of the test contracts.
+const (
+	fnPut uint32 = iota
+	fnGet
+	fnExtend
+	fnRequireAuth
+	numImports
+)
+
+// contract builds a module importing the storage, TTL and auth host
+// functions under their Soroban short names, followed by the given function
+// bodies (without the final end) and exports. Bodies are not type-checked by
+// the audit, so every function shares one signature.
+type contract struct {
+	bodies  [][]byte
+	exports map[string]uint32
+	names   map[uint32]string
+	// raw keeps the bodies as given, without appending the final end.
+	raw bool
+}
+
+func (c contract) build(t *testing.T) []byte {
+	t.Helper()
+	mod := &wasm.Module{
+		Types: []wasm.FuncType{{Results: []wasm.ValType{wasm.I64}}},
+		Imports: []wasm.Import{
+			{Module: "l", Name: "_", Kind: wasm.KindFunc},
+			{Module: "l", Name: "1", Kind: wasm.KindFunc},
+			{Module: "l", Name: "7", Kind: wasm.KindFunc},
+			{Module: "a", Name: "0", Kind: wasm.KindFunc},
+		},
+	}
+	for _, body := range c.bodies {
+		mod.Functions = append(mod.Functions, 0)
+		if !c.raw {
+			body = append(append([]byte(nil), body...), wasm.OpEnd)
+		}
+		mod.Code = append(mod.Code, wasm.Func{Body: body})
+	}
+	for name, idx := range c.exports {
+		mod.Exports = append(mod.Exports, wasm.Export{Name: name, Kind: wasm.KindFunc, Index: idx})
+	}
+	if len(c.names) > 0 {
+		var entries []byte
+		entries = wasm.AppendU32(entries, uint32(len(c.names)))
+		for idx := uint32(0); idx < numImports+uint32(len(c.bodies)); idx++ {
+			if name, ok := c.names[idx]; ok {
+				entries = wasm.AppendU32(entries, idx)
+				entries = wasm.AppendU32(entries, uint32(len(name)))
+				entries = append(entries, name...)
+			}
+		}
+		sub := append([]byte{1}, wasm.AppendU32(nil, uint32(len(entries)))...)
+		mod.Customs = append(mod.Customs, wasm.Custom{Name: "name", Data: append(sub, entries...), After: wasm.SectionData})
+	}
+	out, err := mod.Encode()
+	if err != nil {
+		t.Fatalf("encoding test module: %v", err)
+	}
+	return out
+}
+
+func call(idx uint32) []byte { return wasm.AppendU32([]byte{wasm.OpCall}, idx) }
+
+func i64(v int64) []byte { return wasm.AppendS64([]byte{opI64Const}, v) }
+
+func code(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// put stores a persistent (or, with instance, instance) entry.
+func put(instance bool) []byte {
+	storageType := storagePersistent
+	if instance {
+		storageType = 2<<32 | 4
+	}
+	return code(i64(1), i64(2), i64(storageType), call(fnPut), []byte{0x1a}) // drop
+}
+
+func analyze(t *testing.T, c contract) *Report {
+	t.Helper()
+	report, err := Analyze(c.build(t))
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	return report
+}
+
+func kinds(issues []Issue) map[Kind][]Issue {
+	out := make(map[Kind][]Issue)
+	for _, issue := range issues {
+		out[issue.Kind] = append(out[issue.Kind], issue)
+	}
+	return out
+}
+
+func TestMissingAuth(t *testing.T) {
+	first := numImports
+	report := analyze(t, contract{
+		bodies: [][]byte{
+			// set_admin writes through a helper without any auth.
+			call(first + 1),
+			put(true),
+			// transfer authorizes first.
+			code(i64(1), call(fnRequireAuth), []byte{0x1a}, put(true)),
+			// __constructor writes without auth by design.
+			put(true),
+		},
+		exports: map[string]uint32{"set_admin": first, "transfer": first + 2, "__constructor": first + 3},
+	})
+
+	if report.Exports != 3 {
+		t.Errorf("Exports = %d, want 3", report.Exports)
+	}
+	missing := kinds(report.Issues)[KindMissingAuth]
+	if len(missing) != 1 {
+		t.Fatalf("got %d missing-auth issues, want 1: %+v", len(missing), report.Issues)
+	}
+	issue := missing[0]
+	if issue.Export != "set_admin" || issue.Func != first+1 || issue.Function != "func[5]" {
+		t.Errorf("issue = %+v, want set_admin's write in func[5]", issue)
+	}
+	if issue.Offset == 0 {
+		t.Error("issue has no offset")
+	}
+}
+
+func TestMissingTTLExtension(t *testing.T) {
+	first := numImports
+	report := analyze(t, contract{
+		bodies: [][]byte{
+			code(i64(1), call(fnRequireAuth), []byte{0x1a}, put(false)),
+			code(i64(1), call(fnRequireAuth), []byte{0x1a}, put(false),
+				i64(1), i64(2), i64(3), i64(4), call(fnExtend), []byte{0x1a}),
+			code(i64(1), call(fnRequireAuth), []byte{0x1a}, put(true)),
+		},
+		exports: map[string]uint32{"save": first, "save_and_extend": first + 1, "save_instance": first + 2},
+	})
+
+	ttl := kinds(report.Issues)[KindMissingTTL]
+	if len(ttl) != 1 || ttl[0].Export != "save" {
+		t.Fatalf("missing-ttl issues = %+v, want one for save", ttl)
+	}
+	if len(kinds(report.Issues)[KindMissingAuth]) != 0 {
+		t.Errorf("unexpected missing-auth issue: %+v", report.Issues)
+	}
+}
+
+func TestStorageLoop(t *testing.T) {
+	first := numImports
+	report := analyze(t, contract{
+		bodies: [][]byte{
+			// sum: loop { call read; br_if 0 }
+			code([]byte{wasm.OpLoop, 0x40}, call(first+1), []byte{0x1a, 0x41, 0x01, opBrIf, 0x00, wasm.OpEnd}),
+			// read: get_contract_data
+			code(i64(1), i64(2), call(fnGet)),
+			// count: a loop without storage access
+			code([]byte{wasm.OpLoop, 0x40, 0x41, 0x01, opBrIf, 0x00, wasm.OpEnd}),
+		},
+		exports: map[string]uint32{"sum": first, "count": first + 2},
+		names:   map[uint32]string{first: "contract::sum", first + 1: "contract::read"},
+	})
+
+	loops := kinds(report.Issues)[KindStorageLoop]
+	if len(loops) != 1 {
+		t.Fatalf("got %d storage-loop issues, want 1: %+v", len(loops), report.Issues)
+	}
+	if loops[0].Function != "contract::sum" || loops[0].Export != "sum" {
+		t.Errorf("issue = %+v, want the loop in contract::sum", loops[0])
+	}
+}
+
+func TestUnterminatedLoop(t *testing.T) {
+	// A body of just "loop" with neither the loop's nor the function's end
+	// must not panic.
+	report := analyze(t, contract{
+		bodies:  [][]byte{{wasm.OpLoop, 0x40}},
+		exports: map[string]uint32{"spin": numImports},
+		raw:     true,
+	})
+	if loops := kinds(report.Issues)[KindStorageLoop]; len(loops) != 0 {
+		t.Errorf("unexpected storage-loop issues: %+v", loops)
+	}
+}
+
+func TestArithmeticPanic(t *testing.T) {
+	first := numImports
+	panicFn := first + 2
+	report := analyze(t, contract{
+		bodies: [][]byte{
+			// add: i64.add; overflow check with if { call panic; unreachable }
+			code(i64(1), i64(2), []byte{0x7c, 0x42, 0x00, 0x54, wasm.OpIf, 0x40}, i64(0), call(panicFn),
+				[]byte{opUnreachable, wasm.OpEnd}),
+			// sub: block { i64.sub; br_if 0 (overflow); return } unreachable
+			code([]byte{wasm.OpBlock, 0x40}, i64(1), i64(2), []byte{0x7d, 0x42, 0x00, 0x53, opBrIf, 0x00, opReturn, wasm.OpEnd, opUnreachable}),
+			// the panic handler
+			[]byte{opUnreachable},
+			// mul: i64.mul with no check
+			code(i64(1), i64(2), []byte{0x7e}),
+		},
+		exports: map[string]uint32{"add": first, "sub": first + 1, "mul": first + 3},
+	})
+
+	arith := kinds(report.Issues)[KindArithmeticPanic]
+	if len(arith) != 2 {
+		t.Fatalf("got %d arithmetic-panic issues, want 2: %+v", len(arith), report.Issues)
+	}
+	if arith[0].Export != "add" || arith[1].Export != "sub" {
+		t.Errorf("issues = %+v, want add and sub", arith)
+	}
+}
+
+func TestAnalyzeInvalidModule(t *testing.T) {
+	if _, err := Analyze([]byte("not wasm")); err == nil {
+		t.Fatal("expected an error for invalid input")
+	}
+}
+
+func TestLongHostNames(t *testing.T) {
+	mod := &wasm.Module{
+		Types:     []wasm.FuncType{{}},
+		Imports:   []wasm.Import{{Module: "env", Name: "put_contract_data", Kind: wasm.KindFunc}},
+		Functions: []uint32{0},
+		Code:      []wasm.Func{{Body: code(call(0), []byte{wasm.OpEnd})}},
+		Exports:   []wasm.Export{{Name: "write", Kind: wasm.KindFunc, Index: 1}},
+	}
+	bin, err := mod.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Analyze(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kinds(report.Issues)[KindMissingAuth]) != 1 {
+		t.Errorf("issues = %+v, want a missing-auth issue", report.Issues)
+	}
+}
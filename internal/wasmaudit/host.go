@@ -0,0 +1,96 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmaudit
+
+import "github.com/dotandev/hintents/internal/wasm"
+
+// hostFunc is a Soroban host function the audit cares about.
+type hostFunc int
+
+const (
+	hostNone hostFunc = iota
+	hostPutData
+	hostDelData
+	hostGetData
+	hostHasData
+	hostExtendDataTTL
+	hostRequireAuth
+	hostRequireAuthForArgs
+)
+
+// hostImports maps the short import names Soroban contracts are linked
+// against (module.function, from soroban-env-common's env.json) to host
+// functions.
+var hostImports = map[string]hostFunc{
+	"l._": hostPutData,
+	"l.0": hostHasData,
+	"l.1": hostGetData,
+	"l.2": hostDelData,
+	"l.7": hostExtendDataTTL,
+	"a._": hostRequireAuthForArgs,
+	"a.0": hostRequireAuth,
+}
+
+// hostNames maps the long host function names, which hand-written and test
+// modules may import under any module name.
+var hostNames = map[string]hostFunc{
+	"put_contract_data":        hostPutData,
+	"has_contract_data":        hostHasData,
+	"get_contract_data":        hostGetData,
+	"del_contract_data":        hostDelData,
+	"extend_contract_data_ttl": hostExtendDataTTL,
+	"require_auth_for_args":    hostRequireAuthForArgs,
+	"require_auth":             hostRequireAuth,
+}
+
+func (h hostFunc) String() string {
+	switch h {
+	case hostPutData:
+		return "put_contract_data"
+	case hostDelData:
+		return "del_contract_data"
+	case hostGetData:
+		return "get_contract_data"
+	case hostHasData:
+		return "has_contract_data"
+	case hostExtendDataTTL:
+		return "extend_contract_data_ttl"
+	case hostRequireAuth:
+		return "require_auth"
+	case hostRequireAuthForArgs:
+		return "require_auth_for_args"
+	}
+	return "unknown"
+}
+
+func (h hostFunc) writesStorage() bool { return h == hostPutData || h == hostDelData }
+
+func (h hostFunc) accessesStorage() bool {
+	return h == hostPutData || h == hostDelData || h == hostGetData || h == hostHasData
+}
+
+func (h hostFunc) requiresAuth() bool { return h == hostRequireAuth || h == hostRequireAuthForArgs }
+
+// hostFuncs resolves the module's function imports to host functions.
+func hostFuncs(mod *wasm.Module) map[uint32]hostFunc {
+	out := make(map[uint32]hostFunc)
+	var idx uint32
+	for _, imp := range mod.Imports {
+		if imp.Kind != wasm.KindFunc {
+			continue
+		}
+		if h, ok := hostImports[imp.Module+"."+imp.Name]; ok {
+			out[idx] = h
+		} else if h, ok := hostNames[imp.Name]; ok {
+			out[idx] = h
+		}
+		idx++
+	}
+	return out
+}
+
+// storagePersistent is StorageType::Persistent as passed to
+// put_contract_data: a U32Val, whose payload sits in the upper 32 bits above
+// the tag (4).
+const storagePersistent int64 = 1<<32 | 4
@@ -6,6 +6,8 @@ package wasmopt
 import (
 	"bytes"
 	"testing"
+
+	"github.com/dotandev/hintents/internal/wasm"
 )
 
 func TestEliminateDeadCode_RemovesUnreachableAndRewritesCalls(t *testing.T) {
@@ -70,15 +72,20 @@ func TestEliminateDeadCode_NoChangesWhenEverythingReachable(t *testing.T) {
 	}
 }
 
+type section struct {
+	id      wasm.SectionID
+	payload []byte
+}
+
 func buildModule(sections ...section) []byte {
-	var out bytes.Buffer
-	out.Write(wasmMagic)
+	out := append([]byte{}, wasm.Magic...)
+	out = append(out, 0x01, 0x00, 0x00, 0x00)
 	for _, s := range sections {
-		out.WriteByte(s.id)
-		out.Write(encodeU32(uint32(len(s.payload))))
-		out.Write(s.payload)
+		out = append(out, byte(s.id))
+		out = wasm.AppendU32(out, uint32(len(s.payload)))
+		out = append(out, s.payload...)
 	}
-	return out.Bytes()
+	return out
 }
 
 func buildTypeSectionSingleEmpty() section {
@@ -88,27 +95,35 @@ func buildTypeSectionSingleEmpty() section {
 		0x60, 0x00, // func type, no params
 		0x00, // no results
 	}
-	return section{id: 1, payload: payload}
+	return section{id: wasm.SectionType, payload: payload}
 }
 
 func buildFunctionSection(typeIndices []uint32) section {
-	return section{id: sectionFunction, payload: encodeFunctionSection(typeIndices)}
+	payload := wasm.AppendU32(nil, uint32(len(typeIndices)))
+	for _, idx := range typeIndices {
+		payload = wasm.AppendU32(payload, idx)
+	}
+	return section{id: wasm.SectionFunction, payload: payload}
 }
 
 func buildExportSection(exports map[string]uint32) section {
-	var payload bytes.Buffer
-	payload.Write(encodeU32(uint32(len(exports))))
+	payload := wasm.AppendU32(nil, uint32(len(exports)))
 	for name, fnIdx := range exports {
-		payload.Write(encodeU32(uint32(len(name))))
-		payload.WriteString(name)
-		payload.WriteByte(0x00) // func export
-		payload.Write(encodeU32(fnIdx))
+		payload = wasm.AppendU32(payload, uint32(len(name)))
+		payload = append(payload, name...)
+		payload = append(payload, 0x00) // func export
+		payload = wasm.AppendU32(payload, fnIdx)
 	}
-	return section{id: sectionExport, payload: payload.Bytes()}
+	return section{id: wasm.SectionExport, payload: payload}
 }
 
 func buildCodeSection(bodies [][]byte) section {
-	return section{id: sectionCode, payload: encodeCodeSection(bodies)}
+	payload := wasm.AppendU32(nil, uint32(len(bodies)))
+	for _, body := range bodies {
+		payload = wasm.AppendU32(payload, uint32(len(body)))
+		payload = append(payload, body...)
+	}
+	return section{id: wasm.SectionCode, payload: payload}
 }
 
 func buildBodyNoop() []byte {
@@ -120,46 +135,30 @@ func buildBodyNoop() []byte {
 }
 
 func buildBodyCall(idx uint32) []byte {
-	var body bytes.Buffer
-	body.WriteByte(0x00) // local decl count
-	body.WriteByte(0x10) // call
-	body.Write(encodeU32(idx))
-	body.WriteByte(0x0b) // end
-	return body.Bytes()
+	body := []byte{
+		0x00, // local decl count
+		0x10, // call
+	}
+	body = wasm.AppendU32(body, idx)
+	return append(body, 0x0b) // end
 }
 
 func parseFunctionSectionPayloadFromModule(module []byte) ([]uint32, error) {
-	sections, err := parseSections(module)
+	mod, err := wasm.Parse(module)
 	if err != nil {
 		return nil, err
 	}
-	payload, ok := findSection(sections, sectionFunction)
-	if !ok {
-		return nil, nil
-	}
-	return parseFunctionSection(payload)
+	return mod.Functions, nil
 }
 
 func firstCallIndexInFunction(module []byte, fn int) (uint32, error) {
-	sections, err := parseSections(module)
+	mod, err := wasm.Parse(module)
 	if err != nil {
 		return 0, err
 	}
-	payload, ok := findSection(sections, sectionCode)
-	if !ok {
-		return 0, nil
-	}
-	bodies, err := parseCodeSection(payload)
-	if err != nil {
+	refs, err := wasm.FuncRefs(mod.Code[fn].Body)
+	if err != nil || len(refs) == 0 {
 		return 0, err
 	}
-	body := bodies[fn]
-	// local decl count must be zero in this fixture
-	pos := 1
-	if body[pos] != 0x10 {
-		return 0, nil
-	}
-	pos++
-	idx, _, err := readU32(body, pos)
-	return idx, err
+	return refs[0], nil
 }
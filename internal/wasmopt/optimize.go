@@ -0,0 +1,176 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmopt
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/abi"
+	"github.com/dotandev/hintents/internal/wasm"
+)
+
+// PassResult is the effect of one pass on module size.
+type PassResult struct {
+	Name       string
+	Removed    int
+	Unit       string
+	SizeBefore int
+	SizeAfter  int
+}
+
+// Saved returns the number of bytes the pass removed.
+func (r PassResult) Saved() int {
+	return r.SizeBefore - r.SizeAfter
+}
+
+// SizeReport summarizes an Optimize run.
+type SizeReport struct {
+	OriginalSize  int
+	OptimizedSize int
+	Passes        []PassResult
+}
+
+// Optimize runs passes over module in order, measuring the encoded size
+// after each one, and then checks the result with Verify.
+func Optimize(module []byte, passes []Pass) ([]byte, *SizeReport, error) {
+	mod, err := wasm.Parse(module)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &SizeReport{OriginalSize: len(module), OptimizedSize: len(module)}
+	out := module
+	for _, p := range passes {
+		removed, err := p.Run(mod)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", p.Name, err)
+		}
+		if removed > 0 {
+			if out, err = mod.Encode(); err != nil {
+				return nil, nil, fmt.Errorf("%s: reassembling module: %w", p.Name, err)
+			}
+		}
+		report.Passes = append(report.Passes, PassResult{
+			Name:       p.Name,
+			Removed:    removed,
+			Unit:       p.Unit,
+			SizeBefore: report.OptimizedSize,
+			SizeAfter:  len(out),
+		})
+		report.OptimizedSize = len(out)
+	}
+
+	if err := Verify(module, out); err != nil {
+		return nil, nil, err
+	}
+	return out, report, nil
+}
+
+// Verify checks that optimized keeps the contract interface of original: the
+// same imports, the same exports with the same signatures, identical
+// contract metadata, and a contract spec that decodes to the same interface
+// with every spec function still exported.
+func Verify(original, optimized []byte) error {
+	before, err := wasm.Parse(original)
+	if err != nil {
+		return err
+	}
+	after, err := wasm.Parse(optimized)
+	if err != nil {
+		return fmt.Errorf("optimized module is invalid: %w", err)
+	}
+
+	if len(before.Imports) != len(after.Imports) {
+		return fmt.Errorf("import count changed from %d to %d", len(before.Imports), len(after.Imports))
+	}
+	for i, imp := range before.Imports {
+		got := after.Imports[i]
+		if imp.Module != got.Module || imp.Name != got.Name || imp.Kind != got.Kind || !bytes.Equal(imp.Desc, got.Desc) {
+			return fmt.Errorf("import %s.%s changed", imp.Module, imp.Name)
+		}
+		if imp.Kind == wasm.KindFunc && !sameType(before, imp.TypeIndex, after, got.TypeIndex) {
+			return fmt.Errorf("import %s.%s changed signature", imp.Module, imp.Name)
+		}
+	}
+
+	if len(before.Exports) != len(after.Exports) {
+		return fmt.Errorf("export count changed from %d to %d", len(before.Exports), len(after.Exports))
+	}
+	exported := make(map[string]bool)
+	for i, exp := range before.Exports {
+		got := after.Exports[i]
+		if exp.Name != got.Name || exp.Kind != got.Kind {
+			return fmt.Errorf("export %q changed", exp.Name)
+		}
+		if exp.Kind != wasm.KindFunc {
+			continue
+		}
+		exported[exp.Name] = true
+		oldType, _ := before.FuncTypeIndex(exp.Index)
+		newType, ok := after.FuncTypeIndex(got.Index)
+		if !ok || !sameType(before, oldType, after, newType) {
+			return fmt.Errorf("export %q changed signature", exp.Name)
+		}
+	}
+
+	for name := range preservedCustomSections {
+		oldData, _ := before.Custom(name)
+		newData, _ := after.Custom(name)
+		if !bytes.Equal(oldData, newData) {
+			return fmt.Errorf("custom section %q changed", name)
+		}
+	}
+
+	specData, ok := before.Custom("contractspecv0")
+	if !ok {
+		return nil
+	}
+	oldSpec, err := abi.DecodeContractSpec(specData)
+	if err != nil {
+		return fmt.Errorf("original contract spec does not decode: %w", err)
+	}
+	newData, _ := after.Custom("contractspecv0")
+	newSpec, err := abi.DecodeContractSpec(newData)
+	if err != nil {
+		return fmt.Errorf("contract spec no longer decodes: %w", err)
+	}
+	if diff := abi.Diff(oldSpec, newSpec); len(diff.Changes) > 0 {
+		return fmt.Errorf("contract spec changed: %s", diff.Changes[0].Detail)
+	}
+	for _, fn := range newSpec.Functions {
+		if name := string(fn.Name); !exported[name] {
+			return fmt.Errorf("spec function %q is no longer exported", name)
+		}
+	}
+	return nil
+}
+
+func sameType(a *wasm.Module, ai uint32, b *wasm.Module, bi uint32) bool {
+	if int(ai) >= len(a.Types) || int(bi) >= len(b.Types) {
+		return false
+	}
+	at, bt := a.Types[ai], b.Types[bi]
+	return slices.Equal(at.Params, bt.Params) && slices.Equal(at.Results, bt.Results)
+}
+
+// FormatSizeReport renders a per-pass size table.
+func FormatSizeReport(r *SizeReport) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-24s %14s %10s %10s\n", "PASS", "REMOVED", "SIZE", "SAVED")
+	for _, p := range r.Passes {
+		fmt.Fprintf(&sb, "%-24s %14s %10d %10d\n", p.Name, fmt.Sprintf("%d %s", p.Removed, p.Unit), p.SizeAfter, p.Saved())
+	}
+	saved := r.OriginalSize - r.OptimizedSize
+	pct := 0.0
+	if r.OriginalSize > 0 {
+		pct = float64(saved) / float64(r.OriginalSize) * 100
+	}
+	fmt.Fprintf(&sb, "\nOriginal size:  %d bytes\n", r.OriginalSize)
+	fmt.Fprintf(&sb, "Optimized size: %d bytes\n", r.OptimizedSize)
+	fmt.Fprintf(&sb, "Saved:          %d bytes (%.1f%%)\n", saved, pct)
+	return sb.String()
+}
@@ -0,0 +1,466 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmopt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/dce"
+	"github.com/dotandev/hintents/internal/wasm"
+)
+
+// Pass is a size optimization over a parsed module. Run reports how many
+// items, counted in Unit, it removed.
+type Pass struct {
+	Name        string
+	Description string
+	Unit        string
+	Run         func(*wasm.Module) (int, error)
+}
+
+// Custom sections the Soroban host and tooling read. Every other custom
+// section (names, DWARF, producers) can be dropped from a deployed contract.
+var preservedCustomSections = map[string]bool{
+	"contractspecv0":    true,
+	"contractenvmetav0": true,
+	"contractmetav0":    true,
+}
+
+// Passes lists every pass in the order Optimize runs them by default.
+// Custom sections are stripped first, then unreachable functions are removed
+// so the later passes see fewer references.
+var Passes = []Pass{
+	{
+		Name:        "strip-custom",
+		Description: "drop custom sections other than the contract spec and metadata",
+		Unit:        "sections",
+		Run:         stripCustomSections,
+	},
+	{
+		Name:        "dce",
+		Description: "remove functions unreachable from exports",
+		Unit:        "functions",
+		Run:         dce.Prune,
+	},
+	{
+		Name:        "dedupe-functions",
+		Description: "merge functions with identical signatures and bodies",
+		Unit:        "functions",
+		Run:         dedupeFunctions,
+	},
+	{
+		Name:        "remove-unused-globals",
+		Description: "remove globals no code, export or initializer uses",
+		Unit:        "globals",
+		Run:         removeUnusedGlobals,
+	},
+	{
+		Name:        "remove-unused-types",
+		Description: "remove function types no function, import or indirect call uses",
+		Unit:        "types",
+		Run:         removeUnusedTypes,
+	},
+	{
+		Name:        "compact-data",
+		Description: "trim zero bytes from data segments and merge nearby segments",
+		Unit:        "bytes",
+		Run:         compactData,
+	},
+}
+
+// PassesByName returns the named passes in the order given.
+func PassesByName(names []string) ([]Pass, error) {
+	var out []Pass
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, p := range Passes {
+			if p.Name == name {
+				out = append(out, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown pass %q", name)
+		}
+	}
+	return out, nil
+}
+
+func stripCustomSections(mod *wasm.Module) (int, error) {
+	var kept []wasm.Custom
+	for _, c := range mod.Customs {
+		if preservedCustomSections[c.Name] {
+			kept = append(kept, c)
+		}
+	}
+	removed := len(mod.Customs) - len(kept)
+	mod.Customs = kept
+	return removed, nil
+}
+
+// dedupeFunctions redirects every reference to a function onto the first
+// defined function with the same type, locals and body, then removes the
+// duplicates. Merging can make callers identical in turn, so it repeats
+// until nothing changes.
+func dedupeFunctions(mod *wasm.Module) (int, error) {
+	removed := 0
+	for {
+		imported := mod.NumImportedFuncs()
+		first := make(map[string]uint32)
+		target := make([]uint32, len(mod.Code))
+		keep := make([]bool, len(mod.Code))
+		dups := 0
+		for i, f := range mod.Code {
+			key := functionKey(mod.Functions[i], f)
+			if idx, ok := first[key]; ok {
+				target[i] = idx
+				dups++
+				continue
+			}
+			idx := imported + uint32(i)
+			first[key] = idx
+			target[i] = idx
+			keep[i] = true
+		}
+		if dups == 0 {
+			return removed, nil
+		}
+
+		redirect := func(old uint32) (uint32, bool) {
+			if old < imported || int(old-imported) >= len(target) {
+				return old, true
+			}
+			return target[old-imported], true
+		}
+		if err := dce.RemapFunctions(mod, redirect); err != nil {
+			return 0, err
+		}
+		if err := dce.RemoveFunctions(mod, keep); err != nil {
+			return 0, err
+		}
+		removed += dups
+	}
+}
+
+func functionKey(typeIndex uint32, f wasm.Func) string {
+	key := wasm.AppendU32(nil, typeIndex)
+	key = wasm.AppendU32(key, uint32(len(f.Locals)))
+	for _, l := range f.Locals {
+		key = wasm.AppendU32(key, l.Count)
+		key = append(key, byte(l.Type))
+	}
+	return string(append(key, f.Body...))
+}
+
+// removeUnusedGlobals removes module-defined globals that are not exported
+// and never read or written, then renumbers the rest.
+func removeUnusedGlobals(mod *wasm.Module) (int, error) {
+	imported := mod.NumImportedGlobals()
+	used := make([]bool, len(mod.Globals))
+	var worklist []uint32
+	mark := func(idx uint32) {
+		if idx < imported || int(idx-imported) >= len(used) || used[idx-imported] {
+			return
+		}
+		used[idx-imported] = true
+		worklist = append(worklist, idx-imported)
+	}
+	markExpr := func(expr []byte) error {
+		instrs, err := wasm.DecodeExpr(expr)
+		if err != nil {
+			return err
+		}
+		for _, in := range instrs {
+			if idx, ok := in.GlobalIndex(); ok {
+				mark(idx)
+			}
+		}
+		return nil
+	}
+
+	for _, exp := range mod.Exports {
+		if exp.Kind == wasm.KindGlobal {
+			mark(exp.Index)
+		}
+	}
+	var exprs [][]byte
+	for _, f := range mod.Code {
+		exprs = append(exprs, f.Body)
+	}
+	for _, elem := range mod.Elements {
+		exprs = append(exprs, elem.Offset)
+		exprs = append(exprs, elem.Exprs...)
+	}
+	for _, d := range mod.Data {
+		exprs = append(exprs, d.Offset)
+	}
+	for _, expr := range exprs {
+		if err := markExpr(expr); err != nil {
+			return 0, err
+		}
+	}
+	// Initializers of live globals may read other globals.
+	for len(worklist) > 0 {
+		g := worklist[len(worklist)-1]
+		worklist = worklist[:len(worklist)-1]
+		if err := markExpr(mod.Globals[g].Init); err != nil {
+			return 0, fmt.Errorf("global %d initializer: %w", imported+g, err)
+		}
+	}
+
+	newIndex := make([]uint32, len(used))
+	var globals []wasm.Global
+	for i, u := range used {
+		if u {
+			newIndex[i] = imported + uint32(len(globals))
+			globals = append(globals, mod.Globals[i])
+		}
+	}
+	removed := len(mod.Globals) - len(globals)
+	if removed == 0 {
+		return 0, nil
+	}
+	remap := func(old uint32) (uint32, bool) {
+		if old < imported {
+			return old, true
+		}
+		if int(old-imported) >= len(used) || !used[old-imported] {
+			return 0, false
+		}
+		return newIndex[old-imported], true
+	}
+
+	rewrite := func(expr *[]byte) error {
+		out, err := wasm.RewriteGlobalRefs(*expr, remap)
+		if err != nil {
+			return err
+		}
+		*expr = out
+		return nil
+	}
+	mod.Globals = globals
+	for i := range mod.Globals {
+		if err := rewrite(&mod.Globals[i].Init); err != nil {
+			return 0, fmt.Errorf("global initializer: %w", err)
+		}
+	}
+	for i := range mod.Code {
+		if err := rewrite(&mod.Code[i].Body); err != nil {
+			return 0, fmt.Errorf("function %d: %w", mod.NumImportedFuncs()+uint32(i), err)
+		}
+	}
+	for i := range mod.Elements {
+		elem := &mod.Elements[i]
+		if err := rewrite(&elem.Offset); err != nil {
+			return 0, fmt.Errorf("element segment %d: %w", i, err)
+		}
+		for j := range elem.Exprs {
+			if err := rewrite(&elem.Exprs[j]); err != nil {
+				return 0, fmt.Errorf("element segment %d: %w", i, err)
+			}
+		}
+	}
+	for i := range mod.Data {
+		if err := rewrite(&mod.Data[i].Offset); err != nil {
+			return 0, fmt.Errorf("data segment %d: %w", i, err)
+		}
+	}
+	for i := range mod.Exports {
+		if mod.Exports[i].Kind == wasm.KindGlobal {
+			mod.Exports[i].Index, _ = remap(mod.Exports[i].Index)
+		}
+	}
+	return removed, nil
+}
+
+// removeUnusedTypes removes function types no function, import, indirect
+// call or block uses, then renumbers the rest. Modules with exception tags
+// are left alone because tag descriptors are kept raw.
+func removeUnusedTypes(mod *wasm.Module) (int, error) {
+	if mod.Tags != nil {
+		return 0, nil
+	}
+	used := make([]bool, len(mod.Types))
+	mark := func(idx uint32) {
+		if int(idx) < len(used) {
+			used[idx] = true
+		}
+	}
+	for _, imp := range mod.Imports {
+		switch imp.Kind {
+		case wasm.KindFunc:
+			mark(imp.TypeIndex)
+		case wasm.KindTag:
+			return 0, nil
+		}
+	}
+	for _, idx := range mod.Functions {
+		mark(idx)
+	}
+	for i, f := range mod.Code {
+		instrs, err := wasm.DecodeExpr(f.Body)
+		if err != nil {
+			return 0, fmt.Errorf("function %d: %w", mod.NumImportedFuncs()+uint32(i), err)
+		}
+		for _, in := range instrs {
+			if idx, ok := in.TypeIndex(); ok {
+				mark(idx)
+			}
+		}
+	}
+
+	newIndex := make([]uint32, len(used))
+	var types []wasm.FuncType
+	for i, u := range used {
+		if u {
+			newIndex[i] = uint32(len(types))
+			types = append(types, mod.Types[i])
+		}
+	}
+	removed := len(mod.Types) - len(types)
+	if removed == 0 {
+		return 0, nil
+	}
+	remap := func(old uint32) (uint32, bool) {
+		if int(old) >= len(used) || !used[old] {
+			return 0, false
+		}
+		return newIndex[old], true
+	}
+
+	mod.Types = types
+	for i := range mod.Imports {
+		if mod.Imports[i].Kind == wasm.KindFunc {
+			mod.Imports[i].TypeIndex, _ = remap(mod.Imports[i].TypeIndex)
+		}
+	}
+	for i := range mod.Functions {
+		mod.Functions[i], _ = remap(mod.Functions[i])
+	}
+	for i := range mod.Code {
+		body, err := wasm.RewriteTypeRefs(mod.Code[i].Body, remap)
+		if err != nil {
+			return 0, fmt.Errorf("function %d: %w", mod.NumImportedFuncs()+uint32(i), err)
+		}
+		mod.Code[i].Body = body
+	}
+	return removed, nil
+}
+
+// zeroRunSplit is the length of a run of zero bytes worth splitting a data
+// segment around: a new segment costs a flags byte, an i32.const offset
+// expression and a length, so shorter runs are cheaper to keep.
+const zeroRunSplit = 8
+
+// dataSpan is a run of initialized memory.
+type dataSpan struct {
+	addr uint32
+	data []byte
+}
+
+// compactData rewrites the active data segments of memory 0 as the minimal
+// set of spans covering their non-zero bytes. Linear memory starts zeroed,
+// so zero bytes need no initializer when the memory is module-defined, the
+// segments do not overlap, and no memory.init or data.drop refers to
+// segments by index. Modules that break any of these are left alone.
+func compactData(mod *wasm.Module) (int, error) {
+	for _, imp := range mod.Imports {
+		if imp.Kind == wasm.KindMemory {
+			return 0, nil
+		}
+	}
+	for _, f := range mod.Code {
+		instrs, err := wasm.DecodeExpr(f.Body)
+		if err != nil {
+			return 0, err
+		}
+		for _, in := range instrs {
+			if in.Op == wasm.OpPrefixFC && (in.Sub == 8 || in.Sub == 9) {
+				return 0, nil
+			}
+		}
+	}
+
+	var active []dataSpan
+	var passive []wasm.Data
+	for _, d := range mod.Data {
+		if d.Flags == 1 {
+			passive = append(passive, d)
+			continue
+		}
+		addr, ok := constOffset(d.Offset)
+		if !ok || d.Memory != 0 {
+			return 0, nil
+		}
+		active = append(active, dataSpan{addr: addr, data: d.Init})
+	}
+	sort.SliceStable(active, func(i, j int) bool { return active[i].addr < active[j].addr })
+	for i := 1; i < len(active); i++ {
+		prev := active[i-1]
+		if uint64(prev.addr)+uint64(len(prev.data)) > uint64(active[i].addr) {
+			return 0, nil
+		}
+	}
+
+	var spans []dataSpan
+	for _, seg := range active {
+		for i, b := range seg.data {
+			if b == 0 {
+				continue
+			}
+			addr := seg.addr + uint32(i)
+			if n := len(spans); n > 0 {
+				last := &spans[n-1]
+				end := last.addr + uint32(len(last.data))
+				if addr-end <= zeroRunSplit {
+					last.data = append(last.data, make([]byte, addr-end)...)
+					last.data = append(last.data, b)
+					continue
+				}
+			}
+			spans = append(spans, dataSpan{addr: addr, data: []byte{b}})
+		}
+	}
+
+	var compacted []wasm.Data
+	for _, s := range spans {
+		offset := wasm.AppendS32([]byte{0x41}, int32(s.addr))
+		compacted = append(compacted, wasm.Data{Offset: append(offset, wasm.OpEnd), Init: s.data})
+	}
+	before := dataSize(mod.Data)
+	after := dataSize(compacted) + dataSize(passive)
+	if after >= before {
+		return 0, nil
+	}
+
+	mod.Data = append(compacted, passive...)
+	if mod.DataCount != nil {
+		n := uint32(len(mod.Data))
+		mod.DataCount = &n
+	}
+	return before - after, nil
+}
+
+// constOffset returns the address of an i32.const offset expression.
+func constOffset(expr []byte) (uint32, bool) {
+	instrs, err := wasm.DecodeExpr(expr)
+	if err != nil || len(instrs) != 2 || instrs[0].Op != 0x41 || instrs[1].Op != wasm.OpEnd {
+		return 0, false
+	}
+	v, _, err := wasm.ReadS32(instrs[0].Imm, 0)
+	return uint32(v), err == nil
+}
+
+// dataSize approximates the encoded size of data segments.
+func dataSize(segs []wasm.Data) int {
+	n := 0
+	for _, d := range segs {
+		n += 1 + len(d.Offset) + len(wasm.AppendU32(nil, uint32(len(d.Init)))) + len(d.Init)
+	}
+	return n
+}
@@ -0,0 +1,294 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmopt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/wasm"
+	"github.com/stellar/go-stellar-sdk/xdr"
+)
+
+func funcs(bodies ...[]byte) []wasm.Func {
+	out := make([]wasm.Func, len(bodies))
+	for i, b := range bodies {
+		out[i] = wasm.Func{Locals: []wasm.Local{}, Body: b}
+	}
+	return out
+}
+
+func TestStripCustomSections(t *testing.T) {
+	mod := &wasm.Module{Customs: []wasm.Custom{
+		{Name: "name"},
+		{Name: "contractspecv0"},
+		{Name: ".debug_info"},
+		{Name: "contractenvmetav0"},
+		{Name: "contractmetav0"},
+	}}
+	removed, err := stripCustomSections(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 || len(mod.Customs) != 3 {
+		t.Fatalf("removed %d, kept %+v", removed, mod.Customs)
+	}
+}
+
+func TestDedupeFunctions(t *testing.T) {
+	mod := &wasm.Module{
+		Types:     []wasm.FuncType{{}},
+		Functions: []uint32{0, 0, 0, 0, 0},
+		Exports: []wasm.Export{
+			{Name: "a", Kind: wasm.KindFunc, Index: 0},
+			{Name: "b", Kind: wasm.KindFunc, Index: 1},
+		},
+		Code: funcs(
+			[]byte{wasm.OpCall, 0x02, wasm.OpEnd}, // a calls 2
+			[]byte{wasm.OpCall, 0x03, wasm.OpEnd}, // b calls 3, a duplicate of 2
+			[]byte{0x01, wasm.OpEnd},
+			[]byte{0x01, wasm.OpEnd},
+			[]byte{0x00, wasm.OpEnd},
+		),
+	}
+	removed, err := dedupeFunctions(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Merging 3 into 2 makes a and b identical, so b goes too.
+	if removed != 2 || len(mod.Code) != 3 {
+		t.Fatalf("removed %d, %d functions left", removed, len(mod.Code))
+	}
+	if mod.Exports[0].Index != 0 || mod.Exports[1].Index != 0 {
+		t.Fatalf("exports not redirected: %+v", mod.Exports)
+	}
+	if !bytes.Equal(mod.Code[0].Body, []byte{wasm.OpCall, 0x01, wasm.OpEnd}) {
+		t.Fatalf("call not rewritten: %x", mod.Code[0].Body)
+	}
+}
+
+func TestRemoveUnusedGlobals(t *testing.T) {
+	mod := &wasm.Module{
+		Imports: []wasm.Import{
+			{Module: "env", Name: "g", Kind: wasm.KindGlobal, Desc: []byte{byte(wasm.I32), 0x00}},
+		},
+		Types:     []wasm.FuncType{{}},
+		Functions: []uint32{0},
+		Globals: []wasm.Global{
+			{Type: wasm.I32, Init: []byte{0x41, 0x01, wasm.OpEnd}},             // 1: unused
+			{Type: wasm.I32, Init: []byte{wasm.OpGlobalGet, 0x03, wasm.OpEnd}}, // 2: used by code
+			{Type: wasm.I32, Init: []byte{0x41, 0x02, wasm.OpEnd}},             // 3: used by 2
+			{Type: wasm.I32, Init: []byte{0x41, 0x03, wasm.OpEnd}},             // 4: exported
+		},
+		Exports: []wasm.Export{{Name: "g4", Kind: wasm.KindGlobal, Index: 4}},
+		Code:    funcs([]byte{wasm.OpGlobalGet, 0x02, wasm.OpGlobalGet, 0x00, 0x1a, 0x1a, wasm.OpEnd}),
+	}
+	removed, err := removeUnusedGlobals(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 || len(mod.Globals) != 3 {
+		t.Fatalf("removed %d, %d globals left", removed, len(mod.Globals))
+	}
+	if !bytes.Equal(mod.Code[0].Body[:4], []byte{wasm.OpGlobalGet, 0x01, wasm.OpGlobalGet, 0x00}) {
+		t.Fatalf("global.get not rewritten: %x", mod.Code[0].Body)
+	}
+	if !bytes.Equal(mod.Globals[0].Init, []byte{wasm.OpGlobalGet, 0x02, wasm.OpEnd}) {
+		t.Fatalf("initializer not rewritten: %x", mod.Globals[0].Init)
+	}
+	if mod.Exports[0].Index != 3 {
+		t.Fatalf("export not rewritten: %d", mod.Exports[0].Index)
+	}
+}
+
+func TestRemoveUnusedTypes(t *testing.T) {
+	mod := &wasm.Module{
+		Types: []wasm.FuncType{
+			{Params: []wasm.ValType{wasm.F64}},
+			{},
+			{Results: []wasm.ValType{wasm.I32}},
+		},
+		Imports:   []wasm.Import{{Module: "x", Name: "f", Kind: wasm.KindFunc, TypeIndex: 2}},
+		Functions: []uint32{1},
+		Code:      funcs([]byte{0x41, 0x00, wasm.OpCallIndirect, 0x02, 0x00, 0x1a, wasm.OpEnd}),
+	}
+	removed, err := removeUnusedTypes(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 || len(mod.Types) != 2 {
+		t.Fatalf("removed %d, %d types left", removed, len(mod.Types))
+	}
+	if mod.Imports[0].TypeIndex != 1 || mod.Functions[0] != 0 {
+		t.Fatalf("type indices not rewritten: import %d, function %d", mod.Imports[0].TypeIndex, mod.Functions[0])
+	}
+	if mod.Code[0].Body[3] != 0x01 {
+		t.Fatalf("call_indirect not rewritten: %x", mod.Code[0].Body)
+	}
+}
+
+func TestCompactData(t *testing.T) {
+	seg := make([]byte, 40)
+	copy(seg[4:], "abc")
+	copy(seg[36:], "xyz")
+	mod := &wasm.Module{
+		Memories: []byte{0x01, 0x00, 0x01},
+		Data: []wasm.Data{
+			{Offset: []byte{0x41, 0x80, 0x08, wasm.OpEnd}, Init: seg},              // 1024
+			{Offset: []byte{0x41, 0xaa, 0x08, wasm.OpEnd}, Init: []byte("de")},     // 1066
+			{Offset: []byte{0x41, 0x80, 0x10, wasm.OpEnd}, Init: make([]byte, 16)}, // all zero
+		},
+	}
+	saved, err := compactData(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved <= 0 {
+		t.Fatalf("expected savings, got %d", saved)
+	}
+	if len(mod.Data) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(mod.Data))
+	}
+	if addr, _ := constOffset(mod.Data[0].Offset); addr != 1028 || string(mod.Data[0].Init) != "abc" {
+		t.Fatalf("segment 0 = %d %q", addr, mod.Data[0].Init)
+	}
+	// "xyz" at 1060 and "de" at 1066 are close enough to share a segment.
+	if addr, _ := constOffset(mod.Data[1].Offset); addr != 1060 || string(mod.Data[1].Init) != "xyz\x00\x00\x00de" {
+		t.Fatalf("segment 1 = %d %q", addr, mod.Data[1].Init)
+	}
+}
+
+func TestCompactData_SkipsImportedMemory(t *testing.T) {
+	mod := &wasm.Module{
+		Imports: []wasm.Import{{Module: "env", Name: "memory", Kind: wasm.KindMemory, Desc: []byte{0x00, 0x01}}},
+		Data:    []wasm.Data{{Offset: []byte{0x41, 0x00, wasm.OpEnd}, Init: make([]byte, 32)}},
+	}
+	saved, err := compactData(mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved != 0 || len(mod.Data[0].Init) != 32 {
+		t.Fatalf("imported memory must not be compacted")
+	}
+}
+
+func specEntry(t *testing.T, name string) []byte {
+	t.Helper()
+	entry := xdr.ScSpecEntry{
+		Kind:       xdr.ScSpecEntryKindScSpecEntryFunctionV0,
+		FunctionV0: &xdr.ScSpecFunctionV0{Name: xdr.ScSymbol(name)},
+	}
+	b, err := entry.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func contractModule(t *testing.T) *wasm.Module {
+	return &wasm.Module{
+		Types:     []wasm.FuncType{{}},
+		Functions: []uint32{0, 0, 0},
+		Exports:   []wasm.Export{{Name: "hello", Kind: wasm.KindFunc, Index: 0}},
+		Code: funcs(
+			[]byte{wasm.OpCall, 0x01, wasm.OpEnd},
+			[]byte{0x01, wasm.OpEnd},
+			[]byte{0x00, wasm.OpEnd},
+		),
+		Customs: []wasm.Custom{
+			{Name: "contractspecv0", Data: specEntry(t, "hello"), After: wasm.SectionCode},
+			{Name: "producers", Data: bytes.Repeat([]byte{0x01}, 20), After: wasm.SectionCode},
+		},
+	}
+}
+
+func TestOptimize(t *testing.T) {
+	module, err := contractModule(t).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, report, err := Optimize(module, Passes)
+	if err != nil {
+		t.Fatalf("Optimize failed: %v", err)
+	}
+	if len(report.Passes) != len(Passes) {
+		t.Fatalf("expected a result per pass, got %d", len(report.Passes))
+	}
+	if report.OptimizedSize != len(out) || report.OptimizedSize >= report.OriginalSize {
+		t.Fatalf("unexpected sizes: %+v (output %d bytes)", report, len(out))
+	}
+	total := 0
+	for _, p := range report.Passes {
+		total += p.Saved()
+	}
+	if total != report.OriginalSize-report.OptimizedSize {
+		t.Fatalf("per-pass savings %d do not add up", total)
+	}
+
+	mod, err := wasm.Parse(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mod.Code) != 2 || len(mod.Customs) != 1 {
+		t.Fatalf("expected 2 functions and 1 custom section, got %d and %d", len(mod.Code), len(mod.Customs))
+	}
+	if s := FormatSizeReport(report); !strings.Contains(s, "strip-custom") || !strings.Contains(s, "Saved:") {
+		t.Fatalf("unexpected report:\n%s", s)
+	}
+}
+
+func TestVerify_DetectsInterfaceChanges(t *testing.T) {
+	original, err := contractModule(t).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renamed := contractModule(t)
+	renamed.Exports[0].Name = "goodbye"
+	bin, _ := renamed.Encode()
+	if err := Verify(original, bin); err == nil {
+		t.Error("expected renamed export to fail verification")
+	}
+
+	respec := contractModule(t)
+	respec.Customs[0].Data = specEntry(t, "goodbye")
+	bin, _ = respec.Encode()
+	if err := Verify(original, bin); err == nil {
+		t.Error("expected changed spec to fail verification")
+	}
+
+	resig := contractModule(t)
+	resig.Types = append(resig.Types, wasm.FuncType{Results: []wasm.ValType{wasm.I64}})
+	resig.Functions[0] = 1
+	bin, _ = resig.Encode()
+	if err := Verify(original, bin); err == nil {
+		t.Error("expected changed signature to fail verification")
+	}
+}
+
+func TestVerify_UndecodableSpec(t *testing.T) {
+	mod := contractModule(t)
+	mod.Customs[0].Data = []byte{0xff, 0xff}
+	bin, err := mod.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(bin, bin); err == nil {
+		t.Error("expected an undecodable original spec to fail verification")
+	}
+}
+
+func TestPassesByName(t *testing.T) {
+	passes, err := PassesByName([]string{"compact-data", " dce"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(passes) != 2 || passes[0].Name != "compact-data" || passes[1].Name != "dce" {
+		t.Fatalf("unexpected passes: %+v", passes)
+	}
+	if _, err := PassesByName([]string{"nope"}); err == nil {
+		t.Fatal("expected unknown pass error")
+	}
+}
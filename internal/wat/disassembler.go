@@ -13,35 +13,32 @@
 package wat
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
-	"math"
 	"strings"
+
+	"github.com/dotandev/hintents/internal/wasm"
 )
 
 // =============================================================================
 // WASM constants
 // =============================================================================
 
-// WASM magic number and version.
-var wasmMagic = []byte{0x00, 0x61, 0x73, 0x6d}
-
-const wasmVersion = 1
-
-// WASM section IDs.
+// WASM section IDs, as bytes for building and matching raw modules.
 const (
-	SectionCustom   byte = 0
-	SectionType     byte = 1
-	SectionImport   byte = 2
-	SectionFunction byte = 3
-	SectionTable    byte = 4
-	SectionMemory   byte = 5
-	SectionGlobal   byte = 6
-	SectionExport   byte = 7
-	SectionStart    byte = 8
-	SectionElement  byte = 9
-	SectionCode     byte = 10
-	SectionData     byte = 11
+	SectionCustom   = byte(wasm.SectionCustom)
+	SectionType     = byte(wasm.SectionType)
+	SectionImport   = byte(wasm.SectionImport)
+	SectionFunction = byte(wasm.SectionFunction)
+	SectionTable    = byte(wasm.SectionTable)
+	SectionMemory   = byte(wasm.SectionMemory)
+	SectionGlobal   = byte(wasm.SectionGlobal)
+	SectionExport   = byte(wasm.SectionExport)
+	SectionStart    = byte(wasm.SectionStart)
+	SectionElement  = byte(wasm.SectionElement)
+	SectionCode     = byte(wasm.SectionCode)
+	SectionData     = byte(wasm.SectionData)
 )
 
 // =============================================================================
@@ -60,6 +57,8 @@ type Instruction struct {
 	Operands string
 	// Size is the number of bytes this instruction occupies.
 	Size int
+	// Depth is the structured-control nesting depth, for indentation.
+	Depth int
 }
 
 // String formats the instruction in WAT style.
@@ -84,22 +83,28 @@ type Snippet struct {
 	TargetIndex int
 	// FuncIndex is the function index this snippet belongs to, if known.
 	FuncIndex int
+	// FuncName is the readable name of that function, if known.
+	FuncName string
 }
 
 // Format renders the snippet as a human-readable WAT text block with an
-// arrow marker on the failing instruction.
+// arrow marker on the failing instruction. Blocks are indented by depth.
 func (s *Snippet) Format() string {
 	if len(s.Instructions) == 0 {
 		return "  <no instructions decoded>"
 	}
 
 	var b strings.Builder
+	if s.FuncName != "" {
+		b.WriteString(fmt.Sprintf("  (func %s\n", s.FuncName))
+	}
 	for i, inst := range s.Instructions {
 		marker := "  "
 		if i == s.TargetIndex {
 			marker = "> "
 		}
-		b.WriteString(fmt.Sprintf("%s0x%04x: %s\n", marker, inst.Offset, inst.String()))
+		indent := strings.Repeat("  ", inst.Depth)
+		b.WriteString(fmt.Sprintf("%s0x%04x: %s%s\n", marker, inst.Offset, indent, inst.String()))
 	}
 	return b.String()
 }
@@ -111,6 +116,10 @@ func (s *Snippet) Format() string {
 // Disassembler decodes WASM bytecode into WAT instructions.
 type Disassembler struct {
 	data []byte
+
+	mod   *wasm.Module
+	names *moduleNames
+	err   error
 }
 
 // NewDisassembler creates a disassembler for the given WASM module bytes.
@@ -123,58 +132,94 @@ func (d *Disassembler) IsValidWasm() bool {
 	if len(d.data) < 8 {
 		return false
 	}
-	for i := 0; i < 4; i++ {
-		if d.data[i] != wasmMagic[i] {
-			return false
-		}
+	if !bytes.Equal(d.data[:4], wasm.Magic) {
+		return false
+	}
+	return binary.LittleEndian.Uint32(d.data[4:8]) == wasm.Version
+}
+
+// load parses the module and its names once.
+func (d *Disassembler) load() error {
+	if d.mod != nil || d.err != nil {
+		return d.err
 	}
-	version := binary.LittleEndian.Uint32(d.data[4:8])
-	return version == wasmVersion
+	if !d.IsValidWasm() {
+		d.err = fmt.Errorf("not a valid WASM module")
+		return d.err
+	}
+	d.mod, d.err = wasm.Parse(d.data)
+	if d.err == nil {
+		d.names = newModuleNames(d.mod)
+	}
+	return d.err
 }
 
 // DisassembleAt decodes instructions around the given byte offset,
 // returning a Snippet with `contextLines` instructions before and after
-// the target instruction.
+// the target instruction. The window stays within the function containing
+// the offset.
 func (d *Disassembler) DisassembleAt(targetOffset uint64, contextLines int) (*Snippet, error) {
-	if !d.IsValidWasm() {
-		return nil, fmt.Errorf("not a valid WASM module")
+	if err := d.load(); err != nil {
+		if !d.IsValidWasm() {
+			return nil, err
+		}
+		// The module does not fully parse, e.g. a section the parser rejects;
+		// the code section alone is enough for a snippet.
+		return d.disassembleRawAt(targetOffset, contextLines)
 	}
 
-	// Find the code section
-	codeStart, codeEnd, err := d.findCodeSection()
+	fn := d.funcAt(targetOffset)
+	if fn < 0 {
+		return &Snippet{TargetOffset: targetOffset, TargetIndex: -1, FuncIndex: -1}, nil
+	}
+	instructions, err := d.decodeFunction(fn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to locate code section: %w", err)
+		return nil, fmt.Errorf("failed to decode instructions: %w", err)
+	}
+
+	funcIndex := d.mod.NumImportedFuncs() + uint32(fn)
+	snippet := &Snippet{
+		TargetOffset: targetOffset,
+		TargetIndex:  -1,
+		FuncIndex:    int(funcIndex),
+		FuncName:     (&printer{names: d.names}).funcRef(funcIndex),
 	}
+	snippet.window(instructions, contextLines)
+	return snippet, nil
+}
 
-	// Decode instructions in the code section
-	instructions, err := d.decodeInstructions(codeStart, codeEnd)
+// disassembleRawAt is DisassembleAt for modules that do not parse: function
+// bodies are located from the code section alone and functions are not
+// named.
+func (d *Disassembler) disassembleRawAt(targetOffset uint64, contextLines int) (*Snippet, error) {
+	bodies, err := wasm.ReadCode(d.data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode instructions: %w", err)
 	}
+	snippet := &Snippet{TargetOffset: targetOffset, TargetIndex: -1, FuncIndex: -1}
+	for _, f := range bodies {
+		if targetOffset >= uint64(f.Offset) && targetOffset < uint64(f.Offset+len(f.Body)) {
+			snippet.window(decodeBody(f, &printer{}), contextLines)
+			break
+		}
+	}
+	return snippet, nil
+}
 
+// window keeps contextLines instructions on either side of the last one
+// starting at or before the target offset.
+func (s *Snippet) window(instructions []Instruction, contextLines int) {
 	if len(instructions) == 0 {
-		return &Snippet{TargetOffset: targetOffset, TargetIndex: -1}, nil
+		return
 	}
-
-	// Find the target instruction
-	targetIdx := -1
+	targetIdx := 0
 	for i, inst := range instructions {
-		if inst.Offset == targetOffset {
-			targetIdx = i
-			break
-		}
-		// If exact match isn't found, find the closest instruction at or before the offset
-		if inst.Offset <= targetOffset && (i+1 >= len(instructions) || instructions[i+1].Offset > targetOffset) {
-			targetIdx = i
+		if inst.Offset > s.TargetOffset {
 			break
 		}
+		targetIdx = i
 	}
 
-	if targetIdx < 0 {
-		targetIdx = 0
-	}
-
-	// Extract context window
 	start := targetIdx - contextLines
 	if start < 0 {
 		start = 0
@@ -184,84 +229,101 @@ func (d *Disassembler) DisassembleAt(targetOffset uint64, contextLines int) (*Sn
 		end = len(instructions)
 	}
 
-	return &Snippet{
-		Instructions: instructions[start:end],
-		TargetOffset: targetOffset,
-		TargetIndex:  targetIdx - start,
-	}, nil
+	s.Instructions = instructions[start:end]
+	s.TargetIndex = targetIdx - start
 }
 
-// DecodeAll decodes all instructions in the code section.
+// DecodeAll decodes the instructions of every function body.
 func (d *Disassembler) DecodeAll() ([]Instruction, error) {
-	if !d.IsValidWasm() {
-		return nil, fmt.Errorf("not a valid WASM module")
+	if err := d.load(); err != nil {
+		return nil, err
 	}
 
-	codeStart, codeEnd, err := d.findCodeSection()
-	if err != nil {
-		return nil, fmt.Errorf("failed to locate code section: %w", err)
+	var instructions []Instruction
+	for i := range d.mod.Code {
+		fn, err := d.decodeFunction(i)
+		if err != nil {
+			return nil, err
+		}
+		instructions = append(instructions, fn...)
 	}
-
-	return d.decodeInstructions(codeStart, codeEnd)
+	return instructions, nil
 }
 
-// findCodeSection locates the code section in the WASM module and returns
-// the start and end byte offsets of the section payload.
-func (d *Disassembler) findCodeSection() (int, int, error) {
-	pos := 8 // Skip magic + version
-
-	for pos < len(d.data) {
-		if pos >= len(d.data) {
-			break
-		}
-
-		sectionID := d.data[pos]
-		pos++
-
-		sectionSize, n := decodeULEB128(d.data[pos:])
-		pos += n
-
-		if sectionID == SectionCode {
-			return pos, pos + int(sectionSize), nil
-		}
-
-		pos += int(sectionSize)
+// FunctionAt returns the index of the function whose body contains offset.
+func (d *Disassembler) FunctionAt(offset uint64) (uint32, bool) {
+	if d.load() != nil {
+		return 0, false
 	}
-
-	return 0, 0, fmt.Errorf("code section not found")
+	fn := d.funcAt(offset)
+	if fn < 0 {
+		return 0, false
+	}
+	return d.mod.NumImportedFuncs() + uint32(fn), true
 }
 
-// decodeInstructions decodes WASM instructions from the given byte range.
-func (d *Disassembler) decodeInstructions(start, end int) ([]Instruction, error) {
-	if start >= len(d.data) || end > len(d.data) || start >= end {
-		return nil, fmt.Errorf("invalid byte range [%d, %d)", start, end)
+// funcAt returns the position in the code section of the function whose
+// body contains offset, or -1.
+func (d *Disassembler) funcAt(offset uint64) int {
+	for i, f := range d.mod.Code {
+		if offset >= uint64(f.Offset) && offset < uint64(f.Offset+len(f.Body)) {
+			return i
+		}
 	}
+	return -1
+}
 
-	// Skip the function count at the beginning of the code section
-	pos := start
-	_, n := decodeULEB128(d.data[pos:])
-	pos += n
+// decodeFunction decodes the body of the i-th defined function. Local
+// declarations are skipped, so instructions start at the first opcode.
+// Bytes that do not decode are shown as unknown opcodes so a snippet can
+// still be produced around them.
+func (d *Disassembler) decodeFunction(i int) ([]Instruction, error) {
+	p := &printer{mod: d.mod, names: d.names, fn: d.mod.NumImportedFuncs() + uint32(i)}
+	return decodeBody(d.mod.Code[i], p), nil
+}
 
+// decodeBody decodes the instructions of a function body, naming indices
+// with p.
+func decodeBody(f wasm.Func, p *printer) []Instruction {
 	var instructions []Instruction
+	depth := 0
+	for pos := 0; pos < len(f.Body); {
+		in, err := wasm.DecodeInstr(f.Body, pos)
+		if err != nil {
+			in = wasm.Instr{Op: f.Body[pos], Offset: pos, Size: 1}
+		}
+		mnemonic, operands := "", ""
+		if err != nil {
+			mnemonic = fmt.Sprintf("unknown_0x%02x", in.Op)
+		} else {
+			mnemonic, operands = p.instr(in)
+		}
 
-	for pos < end {
-		instOffset := uint64(pos)
-		opcode := d.data[pos]
-		pos++
-
-		mnemonic, operands, consumed := decodeOpcode(opcode, d.data[pos:])
-		pos += consumed
+		instDepth := depth
+		switch in.Op {
+		case wasm.OpBlock, wasm.OpLoop, wasm.OpIf:
+			depth++
+		case wasm.OpElse:
+			instDepth--
+		case wasm.OpEnd:
+			depth--
+			instDepth = depth
+		}
+		if instDepth < 0 {
+			instDepth = 0
+		}
 
 		instructions = append(instructions, Instruction{
-			Offset:   instOffset,
-			Opcode:   opcode,
+			Offset:   uint64(f.Offset + pos),
+			Opcode:   in.Op,
 			Mnemonic: mnemonic,
 			Operands: operands,
-			Size:     1 + consumed,
+			Size:     in.Size,
+			Depth:    instDepth,
 		})
+		pos += in.Size
 	}
-
-	return instructions, nil
+	return instructions
 }
 
 // =============================================================================
@@ -269,8 +331,8 @@ func (d *Disassembler) decodeInstructions(start, end int) ([]Instruction, error)
 // =============================================================================
 
 // FormatFallback produces a user-facing fallback message when source mapping
-// is unavailable. It disassembles the WASM around the failing offset and
-// displays the WAT snippet.
+// is unavailable. It disassembles the function around the failing offset and
+// displays the WAT snippet with the failing instruction marked.
 func FormatFallback(wasmBytes []byte, failingOffset uint64, contextLines int) string {
 	if contextLines <= 0 {
 		contextLines = 5
@@ -289,7 +351,11 @@ func FormatFallback(wasmBytes []byte, failingOffset uint64, contextLines int) st
 	var b strings.Builder
 	b.WriteString("Source mapping unavailable. Showing WAT disassembly:\n\n")
 	b.WriteString(snippet.Format())
-	b.WriteString(fmt.Sprintf("\nFailing instruction at offset 0x%x\n", failingOffset))
+	if snippet.FuncName != "" {
+		b.WriteString(fmt.Sprintf("\nFailing instruction at offset 0x%x in %s\n", failingOffset, snippet.FuncName))
+	} else {
+		b.WriteString(fmt.Sprintf("\nFailing instruction at offset 0x%x\n", failingOffset))
+	}
 
 	return b.String()
 }
@@ -299,220 +365,16 @@ func FormatFallback(wasmBytes []byte, failingOffset uint64, contextLines int) st
 // =============================================================================
 
 // decodeOpcode returns the WAT mnemonic, operand string, and number of
-// additional bytes consumed for operands.
+// additional bytes consumed for operands, naming functions by index.
 func decodeOpcode(opcode byte, rest []byte) (string, string, int) {
-	switch opcode {
-	// Control flow
-	case 0x00:
-		return "unreachable", "", 0
-	case 0x01:
-		return "nop", "", 0
-	case 0x02:
-		bt, n := decodeBlockType(rest)
-		return "block", bt, n
-	case 0x03:
-		bt, n := decodeBlockType(rest)
-		return "loop", bt, n
-	case 0x04:
-		bt, n := decodeBlockType(rest)
-		return "if", bt, n
-	case 0x05:
-		return "else", "", 0
-	case 0x0b:
-		return "end", "", 0
-	case 0x0c:
-		idx, n := decodeULEB128(rest)
-		return "br", fmt.Sprintf("%d", idx), n
-	case 0x0d:
-		idx, n := decodeULEB128(rest)
-		return "br_if", fmt.Sprintf("%d", idx), n
-	case 0x0e:
-		// br_table: count + indices + default
-		count, n := decodeULEB128(rest)
-		consumed := n
-		for i := uint64(0); i <= count; i++ {
-			_, m := decodeULEB128(rest[consumed:])
-			consumed += m
-		}
-		return "br_table", fmt.Sprintf("(count=%d)", count), consumed
-	case 0x0f:
-		return "return", "", 0
-	case 0x10:
-		idx, n := decodeULEB128(rest)
-		return "call", fmt.Sprintf("$func%d", idx), n
-	case 0x11:
-		typeIdx, n := decodeULEB128(rest)
-		_, m := decodeULEB128(rest[n:])
-		return "call_indirect", fmt.Sprintf("(type %d)", typeIdx), n + m
-
-	// Variable access
-	case 0x20:
-		idx, n := decodeULEB128(rest)
-		return "local.get", fmt.Sprintf("%d", idx), n
-	case 0x21:
-		idx, n := decodeULEB128(rest)
-		return "local.set", fmt.Sprintf("%d", idx), n
-	case 0x22:
-		idx, n := decodeULEB128(rest)
-		return "local.tee", fmt.Sprintf("%d", idx), n
-	case 0x23:
-		idx, n := decodeULEB128(rest)
-		return "global.get", fmt.Sprintf("%d", idx), n
-	case 0x24:
-		idx, n := decodeULEB128(rest)
-		return "global.set", fmt.Sprintf("%d", idx), n
-
-	// Memory
-	case 0x28:
-		align, n1 := decodeULEB128(rest)
-		offset, n2 := decodeULEB128(rest[n1:])
-		return "i32.load", fmt.Sprintf("offset=%d align=%d", offset, align), n1 + n2
-	case 0x29:
-		align, n1 := decodeULEB128(rest)
-		offset, n2 := decodeULEB128(rest[n1:])
-		return "i64.load", fmt.Sprintf("offset=%d align=%d", offset, align), n1 + n2
-	case 0x2a:
-		align, n1 := decodeULEB128(rest)
-		offset, n2 := decodeULEB128(rest[n1:])
-		return "f32.load", fmt.Sprintf("offset=%d align=%d", offset, align), n1 + n2
-	case 0x2b:
-		align, n1 := decodeULEB128(rest)
-		offset, n2 := decodeULEB128(rest[n1:])
-		return "f64.load", fmt.Sprintf("offset=%d align=%d", offset, align), n1 + n2
-	case 0x36:
-		align, n1 := decodeULEB128(rest)
-		offset, n2 := decodeULEB128(rest[n1:])
-		return "i32.store", fmt.Sprintf("offset=%d align=%d", offset, align), n1 + n2
-	case 0x37:
-		align, n1 := decodeULEB128(rest)
-		offset, n2 := decodeULEB128(rest[n1:])
-		return "i64.store", fmt.Sprintf("offset=%d align=%d", offset, align), n1 + n2
-	case 0x3f:
-		_, n := decodeULEB128(rest)
-		return "memory.size", "", n
-	case 0x40:
-		_, n := decodeULEB128(rest)
-		return "memory.grow", "", n
-
-	// Constants
-	case 0x41:
-		val, n := decodeSLEB128(rest)
-		return "i32.const", fmt.Sprintf("%d", val), n
-	case 0x42:
-		val, n := decodeSLEB128_64(rest)
-		return "i64.const", fmt.Sprintf("%d", val), n
-	case 0x43:
-		if len(rest) < 4 {
-			return "f32.const", "?", 0
-		}
-		bits := binary.LittleEndian.Uint32(rest[:4])
-		return "f32.const", fmt.Sprintf("%g", math.Float32frombits(bits)), 4
-	case 0x44:
-		if len(rest) < 8 {
-			return "f64.const", "?", 0
-		}
-		bits := binary.LittleEndian.Uint64(rest[:8])
-		return "f64.const", fmt.Sprintf("%g", math.Float64frombits(bits)), 8
-
-	// i32 comparison
-	case 0x45:
-		return "i32.eqz", "", 0
-	case 0x46:
-		return "i32.eq", "", 0
-	case 0x47:
-		return "i32.ne", "", 0
-	case 0x48:
-		return "i32.lt_s", "", 0
-	case 0x49:
-		return "i32.lt_u", "", 0
-	case 0x4a:
-		return "i32.gt_s", "", 0
-	case 0x4b:
-		return "i32.gt_u", "", 0
-	case 0x4c:
-		return "i32.le_s", "", 0
-	case 0x4d:
-		return "i32.le_u", "", 0
-	case 0x4e:
-		return "i32.ge_s", "", 0
-	case 0x4f:
-		return "i32.ge_u", "", 0
-
-	// i64 comparison
-	case 0x50:
-		return "i64.eqz", "", 0
-	case 0x51:
-		return "i64.eq", "", 0
-	case 0x52:
-		return "i64.ne", "", 0
-
-	// i32 arithmetic
-	case 0x67:
-		return "i32.clz", "", 0
-	case 0x68:
-		return "i32.ctz", "", 0
-	case 0x69:
-		return "i32.popcnt", "", 0
-	case 0x6a:
-		return "i32.add", "", 0
-	case 0x6b:
-		return "i32.sub", "", 0
-	case 0x6c:
-		return "i32.mul", "", 0
-	case 0x6d:
-		return "i32.div_s", "", 0
-	case 0x6e:
-		return "i32.div_u", "", 0
-	case 0x6f:
-		return "i32.rem_s", "", 0
-	case 0x70:
-		return "i32.rem_u", "", 0
-	case 0x71:
-		return "i32.and", "", 0
-	case 0x72:
-		return "i32.or", "", 0
-	case 0x73:
-		return "i32.xor", "", 0
-	case 0x74:
-		return "i32.shl", "", 0
-	case 0x75:
-		return "i32.shr_s", "", 0
-	case 0x76:
-		return "i32.shr_u", "", 0
-	case 0x77:
-		return "i32.rotl", "", 0
-	case 0x78:
-		return "i32.rotr", "", 0
-
-	// i64 arithmetic
-	case 0x79:
-		return "i64.clz", "", 0
-	case 0x7a:
-		return "i64.ctz", "", 0
-	case 0x7c:
-		return "i64.add", "", 0
-	case 0x7d:
-		return "i64.sub", "", 0
-	case 0x7e:
-		return "i64.mul", "", 0
-
-	// Conversions
-	case 0xa7:
-		return "i32.wrap_i64", "", 0
-	case 0xac:
-		return "i64.extend_i32_s", "", 0
-	case 0xad:
-		return "i64.extend_i32_u", "", 0
-
-	// drop / select
-	case 0x1a:
-		return "drop", "", 0
-	case 0x1b:
-		return "select", "", 0
-
-	default:
+	buf := append([]byte{opcode}, rest...)
+	in, err := wasm.DecodeInstr(buf, 0)
+	if err != nil {
 		return fmt.Sprintf("unknown_0x%02x", opcode), "", 0
 	}
+	p := &printer{}
+	mnemonic, operands := p.instr(in)
+	return mnemonic, operands, in.Size - 1
 }
 
 // decodeBlockType decodes a block type byte and returns the WAT representation.
@@ -520,77 +382,17 @@ func decodeBlockType(data []byte) (string, int) {
 	if len(data) == 0 {
 		return "", 0
 	}
-	switch data[0] {
+	switch t := wasm.ValType(data[0]); t {
 	case 0x40:
 		return "", 1 // void block
-	case 0x7f:
-		return "(result i32)", 1
-	case 0x7e:
-		return "(result i64)", 1
-	case 0x7d:
-		return "(result f32)", 1
-	case 0x7c:
-		return "(result f64)", 1
+	case wasm.I32, wasm.I64, wasm.F32, wasm.F64, wasm.V128, wasm.FuncRef, wasm.ExternRef:
+		return "(result " + t.String() + ")", 1
 	default:
-		// Could be a type index (signed LEB128)
-		_, n := decodeSLEB128(data)
-		return "(type)", n
-	}
-}
-
-// decodeULEB128 decodes an unsigned LEB128 integer from the given bytes.
-// Returns the decoded value and the number of bytes consumed.
-func decodeULEB128(data []byte) (uint64, int) {
-	var result uint64
-	var shift uint
-	for i := 0; i < len(data); i++ {
-		b := data[i]
-		result |= uint64(b&0x7f) << shift
-		shift += 7
-		if b&0x80 == 0 {
-			return result, i + 1
-		}
-	}
-	return result, len(data)
-}
-
-// decodeSLEB128 decodes a signed LEB128 integer (32-bit).
-func decodeSLEB128(data []byte) (int32, int) {
-	var result int64
-	var shift uint
-	var b byte
-	var i int
-	for i = 0; i < len(data); i++ {
-		b = data[i]
-		result |= int64(b&0x7f) << shift
-		shift += 7
-		if b&0x80 == 0 {
-			break
-		}
-	}
-	// Sign extend
-	if shift < 32 && b&0x40 != 0 {
-		result |= -(1 << shift)
-	}
-	return int32(result), i + 1
-}
-
-// decodeSLEB128_64 decodes a signed LEB128 integer (64-bit).
-func decodeSLEB128_64(data []byte) (int64, int) {
-	var result int64
-	var shift uint
-	var b byte
-	var i int
-	for i = 0; i < len(data); i++ {
-		b = data[i]
-		result |= int64(b&0x7f) << shift
-		shift += 7
-		if b&0x80 == 0 {
-			break
+		// A type index (signed LEB128), for multi-value blocks.
+		idx, n, err := wasm.ReadS33(data, 0)
+		if err != nil {
+			return "", 0
 		}
+		return fmt.Sprintf("(type %d)", idx), n
 	}
-	if shift < 64 && b&0x40 != 0 {
-		result |= -(1 << shift)
-	}
-	return result, i + 1
 }
@@ -171,12 +171,12 @@ func TestDecodeOpcode_I32Add(t *testing.T) {
 }
 
 func TestDecodeOpcode_I32Load(t *testing.T) {
-	// i32.load align=2 offset=0
+	// i32.load with alignment exponent 2 (4 bytes) and offset 0
 	m, op, n := decodeOpcode(0x28, []byte{0x02, 0x00})
 	if m != "i32.load" {
 		t.Errorf("expected 'i32.load', got %q", m)
 	}
-	if !strings.Contains(op, "offset=0") || !strings.Contains(op, "align=2") {
+	if !strings.Contains(op, "offset=0") || !strings.Contains(op, "align=4") {
 		t.Errorf("i32.load operands = %q", op)
 	}
 	if n != 2 {
@@ -223,63 +223,6 @@ func TestDecodeOpcode_Unknown(t *testing.T) {
 	}
 }
 
-// =============================================================================
-// LEB128 Tests
-// =============================================================================
-
-func TestDecodeULEB128_Zero(t *testing.T) {
-	val, n := decodeULEB128([]byte{0x00})
-	if val != 0 || n != 1 {
-		t.Errorf("ULEB128(0) = %d, %d bytes", val, n)
-	}
-}
-
-func TestDecodeULEB128_SingleByte(t *testing.T) {
-	val, n := decodeULEB128([]byte{0x7f})
-	if val != 127 || n != 1 {
-		t.Errorf("ULEB128(127) = %d, %d bytes", val, n)
-	}
-}
-
-func TestDecodeULEB128_MultiByte(t *testing.T) {
-	// 128 = 0x80 0x01
-	val, n := decodeULEB128([]byte{0x80, 0x01})
-	if val != 128 || n != 2 {
-		t.Errorf("ULEB128(128) = %d, %d bytes", val, n)
-	}
-}
-
-func TestDecodeULEB128_LargeValue(t *testing.T) {
-	// 624485 = 0xe5 0x8e 0x26
-	val, n := decodeULEB128([]byte{0xe5, 0x8e, 0x26})
-	if val != 624485 || n != 3 {
-		t.Errorf("ULEB128(624485) = %d, %d bytes", val, n)
-	}
-}
-
-func TestDecodeSLEB128_Positive(t *testing.T) {
-	val, n := decodeSLEB128([]byte{0x2a})
-	if val != 42 || n != 1 {
-		t.Errorf("SLEB128(42) = %d, %d bytes", val, n)
-	}
-}
-
-func TestDecodeSLEB128_Negative(t *testing.T) {
-	// -1 in SLEB128 = 0x7f
-	val, n := decodeSLEB128([]byte{0x7f})
-	if val != -1 || n != 1 {
-		t.Errorf("SLEB128(-1) = %d, %d bytes", val, n)
-	}
-}
-
-func TestDecodeSLEB128_NegativeLarge(t *testing.T) {
-	// -128 in SLEB128 = 0x80, 0x7f
-	val, n := decodeSLEB128([]byte{0x80, 0x7f})
-	if val != -128 || n != 2 {
-		t.Errorf("SLEB128(-128) = %d, %d bytes", val, n)
-	}
-}
-
 // =============================================================================
 // DisassembleAt Tests
 // =============================================================================
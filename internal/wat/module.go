@@ -0,0 +1,464 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wat
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/demangle"
+	"github.com/dotandev/hintents/internal/wasm"
+)
+
+// moduleNames holds the text-format identifiers (without the leading $) of
+// a module's functions, locals and globals.
+type moduleNames struct {
+	funcs   map[uint32]string
+	locals  map[uint32]map[uint32]string
+	globals map[uint32]string
+	// symbols is the original, possibly mangled, name of each function.
+	symbols map[uint32]string
+}
+
+// newModuleNames names functions from the "name" section, demangled, falling
+// back to export names so stripped release builds still read well. Names
+// are made unique because the text format requires it.
+func newModuleNames(mod *wasm.Module) *moduleNames {
+	n := &moduleNames{
+		funcs:   make(map[uint32]string),
+		locals:  make(map[uint32]map[uint32]string),
+		globals: make(map[uint32]string),
+		symbols: make(map[uint32]string),
+	}
+	section := &wasm.Names{}
+	if data, ok := mod.Custom("name"); ok {
+		if parsed, err := wasm.ParseNames(data); err == nil {
+			section = parsed
+		}
+	}
+
+	for idx, name := range section.Functions {
+		n.symbols[idx] = name
+	}
+	for _, exp := range mod.Exports {
+		if exp.Kind == wasm.KindFunc {
+			if _, ok := n.symbols[exp.Index]; !ok {
+				n.symbols[exp.Index] = exp.Name
+			}
+		}
+	}
+	assignUnique(n.funcs, n.symbols, func(s string) string { return demangle.DemangleSymbol(s) })
+
+	globals := make(map[uint32]string)
+	for idx, name := range section.Globals {
+		globals[idx] = name
+	}
+	for _, exp := range mod.Exports {
+		if _, ok := globals[exp.Index]; !ok && exp.Kind == wasm.KindGlobal {
+			globals[exp.Index] = exp.Name
+		}
+	}
+	assignUnique(n.globals, globals, nil)
+
+	for fn, locals := range section.Locals {
+		n.locals[fn] = make(map[uint32]string)
+		assignUnique(n.locals[fn], locals, nil)
+	}
+	return n
+}
+
+// assignUnique fills ids with sanitized, unique identifiers for names, in
+// index order so the result is deterministic.
+func assignUnique(ids map[uint32]string, names map[uint32]string, readable func(string) string) {
+	indices := make([]uint32, 0, len(names))
+	for idx := range names {
+		indices = append(indices, idx)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	used := make(map[string]bool)
+	for _, idx := range indices {
+		name := names[idx]
+		if readable != nil {
+			name = readable(name)
+		}
+		id := sanitizeID(name)
+		if id == "" {
+			continue
+		}
+		if used[id] {
+			id = fmt.Sprintf("%s.%d", id, idx)
+		}
+		used[id] = true
+		ids[idx] = id
+	}
+}
+
+// sanitizeID replaces characters the text format does not allow in
+// identifiers.
+func sanitizeID(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r > 0x20 && r < 0x7f && !strings.ContainsRune("\"(),;[]{}", r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// FindFunction resolves a function by index, text-format name (with or
+// without $), demangled or mangled symbol, or export name.
+func (d *Disassembler) FindFunction(name string) (uint32, error) {
+	if err := d.load(); err != nil {
+		return 0, err
+	}
+	total := d.mod.NumImportedFuncs() + uint32(len(d.mod.Code))
+	if idx, err := strconv.ParseUint(name, 10, 32); err == nil {
+		if uint32(idx) >= total {
+			return 0, fmt.Errorf("function index %d out of range (module has %d functions)", idx, total)
+		}
+		return uint32(idx), nil
+	}
+
+	want := strings.TrimPrefix(name, "$")
+	matches := make(map[uint32]bool)
+	for idx, id := range d.names.funcs {
+		if id == want {
+			matches[idx] = true
+		}
+	}
+	for idx, sym := range d.names.symbols {
+		if sym == name || demangle.DemangleSymbol(sym) == name {
+			matches[idx] = true
+		}
+	}
+	for _, exp := range d.mod.Exports {
+		if exp.Kind == wasm.KindFunc && exp.Name == name {
+			matches[exp.Index] = true
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("no function named %q", name)
+	case 1:
+		for idx := range matches {
+			return idx, nil
+		}
+	}
+	var ids []string
+	for idx := range matches {
+		ids = append(ids, (&printer{names: d.names}).funcRef(idx))
+	}
+	sort.Strings(ids)
+	return 0, fmt.Errorf("%q is ambiguous: %s", name, strings.Join(ids, ", "))
+}
+
+// Function renders one function in the text format. When highlight falls
+// inside its body, the instruction there is marked with a comment.
+func (d *Disassembler) Function(idx uint32, highlight uint64) (string, error) {
+	if err := d.load(); err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := d.writeFunction(&b, idx, "", highlight); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Module renders the whole module in the text format.
+func (d *Disassembler) Module() (string, error) {
+	if err := d.load(); err != nil {
+		return "", err
+	}
+	mod := d.mod
+	p := &printer{mod: mod, names: d.names}
+	var b strings.Builder
+	b.WriteString("(module\n")
+
+	for i, ft := range mod.Types {
+		sig := signature(ft, nil)
+		if sig != "" {
+			sig = " " + sig
+		}
+		fmt.Fprintf(&b, "  (type (;%d;) (func%s))\n", i, sig)
+	}
+
+	var funcIdx, globalIdx uint32
+	for _, imp := range mod.Imports {
+		fmt.Fprintf(&b, "  (import %s %s ", quote([]byte(imp.Module)), quote([]byte(imp.Name)))
+		switch imp.Kind {
+		case wasm.KindFunc:
+			b.WriteString("(func " + p.funcRef(funcIdx) + " " + p.typeUse(imp.TypeIndex) + ")")
+			funcIdx++
+		case wasm.KindTable:
+			if len(imp.Desc) > 0 {
+				l, _, _ := wasm.ReadLimits(imp.Desc, 1)
+				fmt.Fprintf(&b, "(table %s %s)", limits(l), wasm.ValType(imp.Desc[0]))
+			}
+		case wasm.KindMemory:
+			l, _, _ := wasm.ReadLimits(imp.Desc, 0)
+			fmt.Fprintf(&b, "(memory %s)", limits(l))
+		case wasm.KindGlobal:
+			if len(imp.Desc) == 2 {
+				fmt.Fprintf(&b, "(global %s%s)", idPrefix(p.globalRef(globalIdx)), globalType(wasm.ValType(imp.Desc[0]), imp.Desc[1] == 1))
+			}
+			globalIdx++
+		case wasm.KindTag:
+			b.WriteString("(tag)")
+		}
+		b.WriteString(")\n")
+	}
+
+	for i := range mod.Code {
+		if err := d.writeFunction(&b, funcIdx+uint32(i), "  ", ^uint64(0)); err != nil {
+			return "", err
+		}
+	}
+
+	tables, _ := mod.TableTypes()
+	for i, t := range tables {
+		fmt.Fprintf(&b, "  (table (;%d;) %s %s)\n", i, limits(t.Limits), t.Elem)
+	}
+	memories, _ := mod.MemoryTypes()
+	for i, l := range memories {
+		fmt.Fprintf(&b, "  (memory (;%d;) %s)\n", i, limits(l))
+	}
+	for i, g := range mod.Globals {
+		idx := globalIdx + uint32(i)
+		fmt.Fprintf(&b, "  (global %s%s %s)\n", idPrefix(p.globalRef(idx)), globalType(g.Type, g.Mutable), constExpr(p, g.Init))
+	}
+	for _, exp := range mod.Exports {
+		var ref string
+		switch exp.Kind {
+		case wasm.KindFunc:
+			ref = "func " + p.funcRef(exp.Index)
+		case wasm.KindTable:
+			ref = fmt.Sprintf("table %d", exp.Index)
+		case wasm.KindMemory:
+			ref = fmt.Sprintf("memory %d", exp.Index)
+		case wasm.KindGlobal:
+			ref = "global " + p.globalRef(exp.Index)
+		case wasm.KindTag:
+			ref = fmt.Sprintf("tag %d", exp.Index)
+		}
+		fmt.Fprintf(&b, "  (export %s (%s))\n", quote([]byte(exp.Name)), ref)
+	}
+	if mod.Start != nil {
+		fmt.Fprintf(&b, "  (start %s)\n", p.funcRef(*mod.Start))
+	}
+	for i, elem := range mod.Elements {
+		writeElement(&b, p, i, elem)
+	}
+	for i, data := range mod.Data {
+		fmt.Fprintf(&b, "  (data (;%d;) ", i)
+		if data.Flags != 1 {
+			if data.Memory != 0 {
+				fmt.Fprintf(&b, "(memory %d) ", data.Memory)
+			}
+			b.WriteString(constExpr(p, data.Offset) + " ")
+		}
+		b.WriteString(quote(data.Init) + ")\n")
+	}
+	for _, c := range mod.Customs {
+		fmt.Fprintf(&b, "  ;; custom section %q, %d bytes\n", c.Name, len(c.Data))
+	}
+	b.WriteString(")\n")
+	return b.String(), nil
+}
+
+// maxLocals is the most locals a function may declare, matching the limit
+// of the Soroban VM; larger declarations are rejected before rendering.
+const maxLocals = 50000
+
+// writeFunction renders function idx with its signature, locals and an
+// indented body.
+func (d *Disassembler) writeFunction(b *strings.Builder, idx uint32, indent string, highlight uint64) error {
+	mod := d.mod
+	p := &printer{mod: mod, names: d.names, fn: idx}
+	imported := mod.NumImportedFuncs()
+	if idx < imported {
+		return fmt.Errorf("function %s is imported and has no body", p.funcRef(idx))
+	}
+	def := int(idx - imported)
+	if def >= len(mod.Code) {
+		return fmt.Errorf("function index %d out of range", idx)
+	}
+
+	typeIdx := mod.Functions[def]
+	header := fmt.Sprintf("%s(func %s (type %d)", indent, p.funcRef(idx), typeIdx)
+	var params int
+	if int(typeIdx) < len(mod.Types) {
+		ft := mod.Types[typeIdx]
+		params = len(ft.Params)
+		if sig := signature(ft, d.names.locals[idx]); sig != "" {
+			header += " " + sig
+		}
+	}
+	if sym, ok := d.names.symbols[idx]; ok && sym != strings.TrimPrefix(p.funcRef(idx), "$") {
+		header += " ;; " + sym
+	}
+	b.WriteString(header + "\n")
+
+	var numLocals uint64
+	for _, l := range mod.Code[def].Locals {
+		numLocals += uint64(l.Count)
+	}
+	if numLocals > maxLocals {
+		return fmt.Errorf("function %s declares %d locals, more than the %d allowed", p.funcRef(idx), numLocals, maxLocals)
+	}
+	if locals := localDecls(mod.Code[def].Locals, params, d.names.locals[idx]); locals != "" {
+		fmt.Fprintf(b, "%s  %s\n", indent, locals)
+	}
+
+	instructions, err := d.decodeFunction(def)
+	if err != nil {
+		return err
+	}
+	// The final end closes the function itself.
+	if n := len(instructions); n > 0 && instructions[n-1].Opcode == wasm.OpEnd && instructions[n-1].Depth == 0 {
+		instructions = instructions[:n-1]
+	}
+	for _, inst := range instructions {
+		line := fmt.Sprintf("%s  %s%s", indent, strings.Repeat("  ", inst.Depth), inst.String())
+		if highlight >= inst.Offset && highlight < inst.Offset+uint64(inst.Size) {
+			line += fmt.Sprintf("  ;; <-- offset 0x%x", highlight)
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(indent + ")\n")
+	return nil
+}
+
+// localDecls renders local declarations, naming them where the name section
+// does and grouping unnamed ones.
+func localDecls(locals []wasm.Local, params int, names map[uint32]string) string {
+	var parts, group []string
+	flush := func() {
+		if len(group) > 0 {
+			parts = append(parts, "(local "+strings.Join(group, " ")+")")
+			group = nil
+		}
+	}
+	idx := uint32(params)
+	for _, l := range locals {
+		for i := uint32(0); i < l.Count; i++ {
+			if name, ok := names[idx]; ok {
+				flush()
+				parts = append(parts, fmt.Sprintf("(local $%s %s)", name, l.Type))
+			} else {
+				group = append(group, l.Type.String())
+			}
+			idx++
+		}
+	}
+	flush()
+	return strings.Join(parts, " ")
+}
+
+func writeElement(b *strings.Builder, p *printer, i int, elem wasm.Element) {
+	fmt.Fprintf(b, "  (elem (;%d;) ", i)
+	switch elem.Flags & 0x03 {
+	case 0x00, 0x02: // active
+		if elem.Table != 0 {
+			fmt.Fprintf(b, "(table %d) ", elem.Table)
+		}
+		b.WriteString(constExpr(p, elem.Offset) + " ")
+	case 0x03:
+		b.WriteString("declare ")
+	}
+	if elem.Flags&0x04 == 0 {
+		b.WriteString("func")
+		for _, f := range elem.Funcs {
+			b.WriteString(" " + p.funcRef(f))
+		}
+	} else {
+		refType := wasm.FuncRef // implied by flags 4
+		if elem.Flags != 4 {
+			refType = wasm.ValType(elem.Kind)
+		}
+		b.WriteString(refType.String())
+		for _, expr := range elem.Exprs {
+			b.WriteString(" (item " + strings.Join(foldedExpr(p, expr), " ") + ")")
+		}
+	}
+	b.WriteString(")\n")
+}
+
+// constExpr renders a constant expression in folded form.
+func constExpr(p *printer, expr []byte) string {
+	instrs := foldedExpr(p, expr)
+	if len(instrs) == 1 {
+		return instrs[0]
+	}
+	return "(offset " + strings.Join(instrs, " ") + ")"
+}
+
+// foldedExpr renders each instruction of expr, except the final end, in
+// parentheses.
+func foldedExpr(p *printer, expr []byte) []string {
+	instrs, err := wasm.DecodeExpr(expr)
+	if err != nil {
+		return []string{"(;invalid expression;)"}
+	}
+	var out []string
+	for _, in := range instrs {
+		if in.Op == wasm.OpEnd {
+			continue
+		}
+		mnemonic, operands := p.instr(in)
+		if operands != "" {
+			mnemonic += " " + operands
+		}
+		out = append(out, "("+mnemonic+")")
+	}
+	return out
+}
+
+func limits(l wasm.Limits) string {
+	if l.HasMax {
+		return fmt.Sprintf("%d %d", l.Min, l.Max)
+	}
+	return strconv.FormatUint(uint64(l.Min), 10)
+}
+
+func globalType(t wasm.ValType, mutable bool) string {
+	if mutable {
+		return fmt.Sprintf("(mut %s)", t)
+	}
+	return t.String()
+}
+
+// idPrefix returns ref followed by a space when it is a $name, for
+// declarations that take an optional identifier.
+func idPrefix(ref string) string {
+	if strings.HasPrefix(ref, "$") {
+		return ref + " "
+	}
+	return ""
+}
+
+// quote renders bytes as a text-format string literal.
+func quote(data []byte) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, c := range data {
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c >= 0x20 && c < 0x7f:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "\\%02x", c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
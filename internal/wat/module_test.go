@@ -0,0 +1,270 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dotandev/hintents/internal/wasm"
+)
+
+// nameSection builds a "name" custom section payload naming functions.
+func nameSection(funcs map[uint32]string, locals map[uint32]map[uint32]string) []byte {
+	appendMap := func(b []byte, m map[uint32]string) []byte {
+		b = wasm.AppendU32(b, uint32(len(m)))
+		for idx := uint32(0); len(m) > 0; idx++ {
+			name, ok := m[idx]
+			if !ok {
+				continue
+			}
+			b = wasm.AppendU32(b, idx)
+			b = wasm.AppendU32(b, uint32(len(name)))
+			b = append(b, name...)
+			delete(m, idx)
+		}
+		return b
+	}
+	sub := func(id byte, payload []byte) []byte {
+		return append(wasm.AppendU32([]byte{id}, uint32(len(payload))), payload...)
+	}
+
+	out := sub(1, appendMap(nil, funcs))
+	var l []byte
+	l = wasm.AppendU32(l, uint32(len(locals)))
+	for fn, m := range locals {
+		l = wasm.AppendU32(l, fn)
+		l = appendMap(l, m)
+	}
+	return append(out, sub(2, l)...)
+}
+
+func testModule(t *testing.T) []byte {
+	t.Helper()
+	mod := &wasm.Module{
+		Types: []wasm.FuncType{
+			{Params: []wasm.ValType{wasm.I64}, Results: []wasm.ValType{wasm.I64}},
+			{Params: []wasm.ValType{wasm.I32}, Results: []wasm.ValType{wasm.I32, wasm.I32}},
+		},
+		Imports: []wasm.Import{
+			{Module: "x", Name: "_", Kind: wasm.KindFunc, TypeIndex: 0},
+		},
+		Functions: []uint32{0, 0},
+		Memories:  []byte{0x01, 0x00, 0x11},
+		Globals: []wasm.Global{
+			{Type: wasm.I32, Mutable: true, Init: []byte{0x41, 0x80, 0x80, 0x04, wasm.OpEnd}},
+		},
+		Exports: []wasm.Export{
+			{Name: "transfer", Kind: wasm.KindFunc, Index: 1},
+			{Name: "memory", Kind: wasm.KindMemory, Index: 0},
+		},
+		Code: []wasm.Func{
+			{Locals: []wasm.Local{{Count: 1, Type: wasm.I32}}, Body: []byte{
+				0x41, 0x07, // i32.const 7
+				wasm.OpBlock, 0x01, // block (type 1): multi-value
+				0x21, 0x01, // local.set $tmp
+				0x1a, // drop
+				wasm.OpEnd,
+				0x20, 0x00, // local.get $amount
+				wasm.OpCall, 0x02, // call helper
+				0x42, 0x01, // i64.const 1
+				0x7c, // i64.add
+				0xc4, // i64.extend32_s
+				wasm.OpEnd,
+			}},
+			{Locals: []wasm.Local{}, Body: []byte{
+				0x41, 0x00, 0x41, 0x10, 0x41, 0x04,
+				0xfc, 0x0a, 0x00, 0x00, // memory.copy
+				0x41, 0x00, 0x41, 0x00, 0x41, 0x04,
+				0xfc, 0x0b, 0x00, // memory.fill
+				0x00, // unreachable
+				wasm.OpEnd,
+			}},
+		},
+		Data: []wasm.Data{{Offset: []byte{0x41, 0x80, 0x08, wasm.OpEnd}, Init: []byte("hi\"\x00")}},
+		Customs: []wasm.Custom{{
+			Name: "name",
+			Data: nameSection(
+				map[uint32]string{2: "_ZN8contract6helper17h0123456789abcdefE"},
+				map[uint32]map[uint32]string{1: {0: "amount", 1: "tmp"}},
+			),
+			After: wasm.SectionData,
+		}},
+	}
+	bin, err := mod.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bin
+}
+
+func TestModule_Text(t *testing.T) {
+	d := NewDisassembler(testModule(t))
+	text, err := d.Module()
+	if err != nil {
+		t.Fatalf("Module: %v", err)
+	}
+
+	for _, want := range []string{
+		"(type (;1;) (func (param i32) (result i32 i32)))",
+		`(import "x" "_" (func $func0 (type 0) (param i64) (result i64)))`,
+		"  (func $transfer (type 0) (param $amount i64) (result i64)\n    (local $tmp i32)\n",
+		"    block (type 1) (param i32) (result i32 i32)\n      local.set $tmp\n      drop\n    end\n",
+		"    call $contract::helper\n",
+		"    i64.extend32_s\n",
+		"  (func $contract::helper (type 0) (param i64) (result i64) ;; _ZN8contract6helper17h0123456789abcdefE\n",
+		"    memory.copy\n    i32.const 0",
+		"    memory.fill\n    unreachable\n  )\n",
+		"(memory (;0;) 17)",
+		"(global (mut i32) (i32.const 65536))",
+		`(export "transfer" (func $transfer))`,
+		`(data (;0;) (i32.const 1024) "hi\"\00")`,
+		`;; custom section "name"`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("module text missing %q\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "unknown_") {
+		t.Errorf("unexpected unknown opcode in\n%s", text)
+	}
+}
+
+func TestFindFunction(t *testing.T) {
+	d := NewDisassembler(testModule(t))
+	for name, want := range map[string]uint32{
+		"transfer":         1,
+		"$transfer":        1,
+		"contract::helper": 2,
+		"_ZN8contract6helper17h0123456789abcdefE": 2,
+		"2": 2,
+	} {
+		got, err := d.FindFunction(name)
+		if err != nil || got != want {
+			t.Errorf("FindFunction(%q) = %d, %v; want %d", name, got, err, want)
+		}
+	}
+	if _, err := d.FindFunction("missing"); err == nil {
+		t.Error("expected error for unknown function")
+	}
+	if _, err := d.FindFunction("9"); err == nil {
+		t.Error("expected error for out of range index")
+	}
+}
+
+func TestFunction_Highlight(t *testing.T) {
+	bin := testModule(t)
+	d := NewDisassembler(bin)
+	mod, err := wasm.Parse(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// unreachable is the second-to-last byte of helper's body.
+	body := mod.Code[1]
+	trap := uint64(body.Offset + len(body.Body) - 2)
+
+	text, err := d.Function(2, trap)
+	if err != nil {
+		t.Fatalf("Function: %v", err)
+	}
+	if !strings.Contains(text, "unreachable  ;; <-- offset") {
+		t.Errorf("trap not highlighted:\n%s", text)
+	}
+	if !strings.HasPrefix(text, "(func $contract::helper") {
+		t.Errorf("unexpected header:\n%s", text)
+	}
+
+	if _, err := d.Function(0, 0); err == nil {
+		t.Error("expected error for imported function")
+	}
+}
+
+func TestDisassembleAt_StaysInFunction(t *testing.T) {
+	bin := testModule(t)
+	mod, err := wasm.Parse(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	helper := mod.Code[1]
+
+	snippet, err := NewDisassembler(bin).DisassembleAt(uint64(helper.Offset), 3)
+	if err != nil {
+		t.Fatalf("DisassembleAt: %v", err)
+	}
+	if snippet.FuncIndex != 2 || snippet.FuncName != "$contract::helper" {
+		t.Errorf("snippet function = %d %q", snippet.FuncIndex, snippet.FuncName)
+	}
+	for _, inst := range snippet.Instructions {
+		if inst.Offset < uint64(helper.Offset) {
+			t.Errorf("instruction at 0x%x is outside the trapping function", inst.Offset)
+		}
+	}
+	if !strings.Contains(snippet.Format(), "(func $contract::helper") {
+		t.Errorf("snippet missing function header:\n%s", snippet.Format())
+	}
+}
+
+func TestDisassembleAt_UnparsableModule(t *testing.T) {
+	bin := testModule(t)
+	mod, err := wasm.Parse(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	helper := mod.Code[1]
+	// An unknown section after the code section fails the full parse.
+	broken := append(append([]byte(nil), bin...), 0x7f, 0x00)
+	if _, err := wasm.Parse(broken); err == nil {
+		t.Fatal("expected the broken module not to parse")
+	}
+
+	snippet, err := NewDisassembler(broken).DisassembleAt(uint64(helper.Offset)+19, 2)
+	if err != nil {
+		t.Fatalf("DisassembleAt: %v", err)
+	}
+	if snippet.TargetIndex < 0 || snippet.Instructions[snippet.TargetIndex].Mnemonic != "unreachable" {
+		t.Errorf("expected the target to be unreachable:\n%s", snippet.Format())
+	}
+}
+
+func TestModule_TooManyLocals(t *testing.T) {
+	mod := &wasm.Module{
+		Types:     []wasm.FuncType{{}},
+		Functions: []uint32{0},
+		Code:      []wasm.Func{{Locals: []wasm.Local{{Count: 0xffffffff, Type: wasm.I32}}, Body: []byte{wasm.OpEnd}}},
+	}
+	bin, err := mod.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewDisassembler(bin).Module(); err == nil || !strings.Contains(err.Error(), "locals") {
+		t.Errorf("expected a locals limit error, got %v", err)
+	}
+}
+
+func TestDecodeOpcode_Extended(t *testing.T) {
+	tests := []struct {
+		op       byte
+		rest     []byte
+		mnemonic string
+		operands string
+	}{
+		{0xfc, []byte{0x0a, 0x00, 0x00}, "memory.copy", ""},
+		{0xfc, []byte{0x08, 0x03, 0x00}, "memory.init", "3"},
+		{0xfc, []byte{0x09, 0x03}, "data.drop", "3"},
+		{0xfc, []byte{0x01}, "i32.trunc_sat_f32_u", ""},
+		{0xc0, nil, "i32.extend8_s", ""},
+		{0x0e, []byte{0x02, 0x00, 0x01, 0x02}, "br_table", "0 1 2"},
+		{0x1c, []byte{0x01, 0x7e}, "select", "(result i64)"},
+		{0xd0, []byte{0x6f}, "ref.null", "extern"},
+		{0x2d, []byte{0x00, 0x08}, "i32.load8_u", "offset=8 align=1"},
+		{0x44, []byte{0, 0, 0, 0, 0, 0, 0xf8, 0x7f}, "f64.const", "nan"},
+		{0x02, []byte{0x05}, "block", "(type 5)"},
+	}
+	for _, tt := range tests {
+		m, op, _ := decodeOpcode(tt.op, tt.rest)
+		if m != tt.mnemonic || op != tt.operands {
+			t.Errorf("decodeOpcode(0x%02x, %x) = %q %q; want %q %q", tt.op, tt.rest, m, op, tt.mnemonic, tt.operands)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+package wat
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dotandev/hintents/internal/wasm"
+)
+
+// mnemonics maps single-byte opcodes to their text-format names. It covers
+// the MVP instruction set plus sign extension, reference types, multi-value
+// select and tail calls.
+var mnemonics = [256]string{
+	0x00: "unreachable", 0x01: "nop", 0x02: "block", 0x03: "loop", 0x04: "if",
+	0x05: "else", 0x0b: "end", 0x0c: "br", 0x0d: "br_if", 0x0e: "br_table",
+	0x0f: "return", 0x10: "call", 0x11: "call_indirect", 0x12: "return_call",
+	0x13: "return_call_indirect",
+
+	0x1a: "drop", 0x1b: "select", 0x1c: "select",
+
+	0x20: "local.get", 0x21: "local.set", 0x22: "local.tee",
+	0x23: "global.get", 0x24: "global.set", 0x25: "table.get", 0x26: "table.set",
+
+	0x28: "i32.load", 0x29: "i64.load", 0x2a: "f32.load", 0x2b: "f64.load",
+	0x2c: "i32.load8_s", 0x2d: "i32.load8_u", 0x2e: "i32.load16_s", 0x2f: "i32.load16_u",
+	0x30: "i64.load8_s", 0x31: "i64.load8_u", 0x32: "i64.load16_s", 0x33: "i64.load16_u",
+	0x34: "i64.load32_s", 0x35: "i64.load32_u",
+	0x36: "i32.store", 0x37: "i64.store", 0x38: "f32.store", 0x39: "f64.store",
+	0x3a: "i32.store8", 0x3b: "i32.store16", 0x3c: "i64.store8", 0x3d: "i64.store16",
+	0x3e: "i64.store32", 0x3f: "memory.size", 0x40: "memory.grow",
+
+	0x41: "i32.const", 0x42: "i64.const", 0x43: "f32.const", 0x44: "f64.const",
+
+	0x45: "i32.eqz", 0x46: "i32.eq", 0x47: "i32.ne", 0x48: "i32.lt_s", 0x49: "i32.lt_u",
+	0x4a: "i32.gt_s", 0x4b: "i32.gt_u", 0x4c: "i32.le_s", 0x4d: "i32.le_u",
+	0x4e: "i32.ge_s", 0x4f: "i32.ge_u",
+	0x50: "i64.eqz", 0x51: "i64.eq", 0x52: "i64.ne", 0x53: "i64.lt_s", 0x54: "i64.lt_u",
+	0x55: "i64.gt_s", 0x56: "i64.gt_u", 0x57: "i64.le_s", 0x58: "i64.le_u",
+	0x59: "i64.ge_s", 0x5a: "i64.ge_u",
+	0x5b: "f32.eq", 0x5c: "f32.ne", 0x5d: "f32.lt", 0x5e: "f32.gt", 0x5f: "f32.le", 0x60: "f32.ge",
+	0x61: "f64.eq", 0x62: "f64.ne", 0x63: "f64.lt", 0x64: "f64.gt", 0x65: "f64.le", 0x66: "f64.ge",
+
+	0x67: "i32.clz", 0x68: "i32.ctz", 0x69: "i32.popcnt", 0x6a: "i32.add", 0x6b: "i32.sub",
+	0x6c: "i32.mul", 0x6d: "i32.div_s", 0x6e: "i32.div_u", 0x6f: "i32.rem_s", 0x70: "i32.rem_u",
+	0x71: "i32.and", 0x72: "i32.or", 0x73: "i32.xor", 0x74: "i32.shl", 0x75: "i32.shr_s",
+	0x76: "i32.shr_u", 0x77: "i32.rotl", 0x78: "i32.rotr",
+	0x79: "i64.clz", 0x7a: "i64.ctz", 0x7b: "i64.popcnt", 0x7c: "i64.add", 0x7d: "i64.sub",
+	0x7e: "i64.mul", 0x7f: "i64.div_s", 0x80: "i64.div_u", 0x81: "i64.rem_s", 0x82: "i64.rem_u",
+	0x83: "i64.and", 0x84: "i64.or", 0x85: "i64.xor", 0x86: "i64.shl", 0x87: "i64.shr_s",
+	0x88: "i64.shr_u", 0x89: "i64.rotl", 0x8a: "i64.rotr",
+	0x8b: "f32.abs", 0x8c: "f32.neg", 0x8d: "f32.ceil", 0x8e: "f32.floor", 0x8f: "f32.trunc",
+	0x90: "f32.nearest", 0x91: "f32.sqrt", 0x92: "f32.add", 0x93: "f32.sub", 0x94: "f32.mul",
+	0x95: "f32.div", 0x96: "f32.min", 0x97: "f32.max", 0x98: "f32.copysign",
+	0x99: "f64.abs", 0x9a: "f64.neg", 0x9b: "f64.ceil", 0x9c: "f64.floor", 0x9d: "f64.trunc",
+	0x9e: "f64.nearest", 0x9f: "f64.sqrt", 0xa0: "f64.add", 0xa1: "f64.sub", 0xa2: "f64.mul",
+	0xa3: "f64.div", 0xa4: "f64.min", 0xa5: "f64.max", 0xa6: "f64.copysign",
+
+	0xa7: "i32.wrap_i64", 0xa8: "i32.trunc_f32_s", 0xa9: "i32.trunc_f32_u",
+	0xaa: "i32.trunc_f64_s", 0xab: "i32.trunc_f64_u", 0xac: "i64.extend_i32_s",
+	0xad: "i64.extend_i32_u", 0xae: "i64.trunc_f32_s", 0xaf: "i64.trunc_f32_u",
+	0xb0: "i64.trunc_f64_s", 0xb1: "i64.trunc_f64_u", 0xb2: "f32.convert_i32_s",
+	0xb3: "f32.convert_i32_u", 0xb4: "f32.convert_i64_s", 0xb5: "f32.convert_i64_u",
+	0xb6: "f32.demote_f64", 0xb7: "f64.convert_i32_s", 0xb8: "f64.convert_i32_u",
+	0xb9: "f64.convert_i64_s", 0xba: "f64.convert_i64_u", 0xbb: "f64.promote_f32",
+	0xbc: "i32.reinterpret_f32", 0xbd: "i64.reinterpret_f64", 0xbe: "f32.reinterpret_i32",
+	0xbf: "f64.reinterpret_i64",
+
+	0xc0: "i32.extend8_s", 0xc1: "i32.extend16_s", 0xc2: "i64.extend8_s",
+	0xc3: "i64.extend16_s", 0xc4: "i64.extend32_s",
+
+	0xd0: "ref.null", 0xd1: "ref.is_null", 0xd2: "ref.func",
+}
+
+// fcMnemonics maps 0xfc sub-opcodes: saturating truncation, bulk memory and
+// table instructions.
+var fcMnemonics = []string{
+	"i32.trunc_sat_f32_s", "i32.trunc_sat_f32_u", "i32.trunc_sat_f64_s", "i32.trunc_sat_f64_u",
+	"i64.trunc_sat_f32_s", "i64.trunc_sat_f32_u", "i64.trunc_sat_f64_s", "i64.trunc_sat_f64_u",
+	"memory.init", "data.drop", "memory.copy", "memory.fill",
+	"table.init", "elem.drop", "table.copy", "table.grow", "table.size", "table.fill",
+}
+
+// printer formats instructions, naming functions, locals and globals from
+// the module when one is available.
+type printer struct {
+	mod   *wasm.Module
+	names *moduleNames
+	// fn is the index of the function being printed, for local names.
+	fn uint32
+}
+
+func (p *printer) funcRef(idx uint32) string {
+	if p.names != nil {
+		if name, ok := p.names.funcs[idx]; ok {
+			return "$" + name
+		}
+	}
+	return fmt.Sprintf("$func%d", idx)
+}
+
+func (p *printer) localRef(idx uint32) string {
+	if p.names != nil {
+		if name, ok := p.names.locals[p.fn][idx]; ok {
+			return "$" + name
+		}
+	}
+	return strconv.FormatUint(uint64(idx), 10)
+}
+
+func (p *printer) globalRef(idx uint32) string {
+	if p.names != nil {
+		if name, ok := p.names.globals[idx]; ok {
+			return "$" + name
+		}
+	}
+	return strconv.FormatUint(uint64(idx), 10)
+}
+
+// typeUse renders a type index, followed by its signature when the module
+// is known, as block types and indirect calls show it.
+func (p *printer) typeUse(idx uint32) string {
+	use := fmt.Sprintf("(type %d)", idx)
+	if p.mod != nil && int(idx) < len(p.mod.Types) {
+		if sig := signature(p.mod.Types[idx], nil); sig != "" {
+			use += " " + sig
+		}
+	}
+	return use
+}
+
+// mnemonic returns the text-format name of in.
+func mnemonic(in wasm.Instr) (string, bool) {
+	if in.Op == wasm.OpPrefixFC {
+		if int(in.Sub) < len(fcMnemonics) {
+			return fcMnemonics[in.Sub], true
+		}
+		return "", false
+	}
+	name := mnemonics[in.Op]
+	return name, name != ""
+}
+
+// instr returns the mnemonic and operands of in.
+func (p *printer) instr(in wasm.Instr) (string, string) {
+	name, ok := mnemonic(in)
+	if !ok {
+		return fmt.Sprintf("unknown_0x%02x", in.Op), ""
+	}
+	return name, p.operands(in)
+}
+
+// u32s reads consecutive unsigned immediates.
+func u32s(imm []byte) []uint32 {
+	var out []uint32
+	for pos := 0; pos < len(imm); {
+		v, n, err := wasm.ReadU32(imm, pos)
+		if err != nil {
+			break
+		}
+		out = append(out, v)
+		pos += n
+	}
+	return out
+}
+
+func (p *printer) operands(in wasm.Instr) string {
+	imm := in.Imm
+	switch in.Op {
+	case wasm.OpBlock, wasm.OpLoop, wasm.OpIf:
+		if idx, ok := in.TypeIndex(); ok {
+			return p.typeUse(idx)
+		}
+		bt, _ := decodeBlockType(imm)
+		return bt
+	case 0x0c, 0x0d, 0x0e: // br, br_if, br_table
+		labels := u32s(imm)
+		if in.Op == 0x0e && len(labels) > 0 {
+			labels = labels[1:] // drop the count
+		}
+		parts := make([]string, len(labels))
+		for i, l := range labels {
+			parts[i] = strconv.FormatUint(uint64(l), 10)
+		}
+		return strings.Join(parts, " ")
+	case wasm.OpCall, wasm.OpReturnCall, wasm.OpRefFunc:
+		idx, _ := in.FuncIndex()
+		return p.funcRef(idx)
+	case wasm.OpCallIndirect, wasm.OpReturnCallIndirect:
+		v := u32s(imm)
+		if len(v) < 2 {
+			return ""
+		}
+		if v[1] != 0 {
+			return fmt.Sprintf("%d %s", v[1], p.typeUse(v[0]))
+		}
+		return p.typeUse(v[0])
+	case 0x1c: // select t*
+		var types []string
+		for _, t := range imm[1:] {
+			types = append(types, wasm.ValType(t).String())
+		}
+		return "(result " + strings.Join(types, " ") + ")"
+	case 0x20, 0x21, 0x22:
+		v := u32s(imm)
+		if len(v) == 0 {
+			return ""
+		}
+		return p.localRef(v[0])
+	case wasm.OpGlobalGet, wasm.OpGlobalSet:
+		idx, _ := in.GlobalIndex()
+		return p.globalRef(idx)
+	case 0x25, 0x26: // table.get, table.set
+		return nonZero(u32s(imm)...)
+	case 0x3f, 0x40: // memory.size, memory.grow
+		return nonZero(u32s(imm)...)
+	case 0x41:
+		v, _, _ := wasm.ReadS32(imm, 0)
+		return strconv.FormatInt(int64(v), 10)
+	case 0x42:
+		v, _, _ := wasm.ReadS64(imm, 0)
+		return strconv.FormatInt(v, 10)
+	case 0x43:
+		if len(imm) < 4 {
+			return "?"
+		}
+		return formatF32(binary.LittleEndian.Uint32(imm))
+	case 0x44:
+		if len(imm) < 8 {
+			return "?"
+		}
+		return formatF64(binary.LittleEndian.Uint64(imm))
+	case 0xd0: // ref.null
+		if len(imm) == 1 && wasm.ValType(imm[0]) == wasm.ExternRef {
+			return "extern"
+		}
+		return "func"
+	case wasm.OpPrefixFC:
+		v := u32s(imm)
+		switch in.Sub {
+		case 8: // memory.init data mem
+			if len(v) == 2 && v[1] != 0 {
+				return fmt.Sprintf("%d %d", v[1], v[0])
+			}
+			return nonZero(v[0])
+		case 9, 13: // data.drop, elem.drop
+			return strconv.FormatUint(uint64(v[0]), 10)
+		case 10, 14: // memory.copy, table.copy dst src
+			if v[0] == 0 && v[1] == 0 {
+				return ""
+			}
+			return fmt.Sprintf("%d %d", v[0], v[1])
+		case 12: // table.init elem table
+			if v[1] != 0 {
+				return fmt.Sprintf("%d %d", v[1], v[0])
+			}
+			return strconv.FormatUint(uint64(v[0]), 10)
+		case 11, 15, 16, 17:
+			return nonZero(v...)
+		}
+		return ""
+	}
+	if in.Op >= 0x28 && in.Op <= 0x3e {
+		return memarg(imm)
+	}
+	return ""
+}
+
+// nonZero renders an index immediate that the text format omits when zero.
+func nonZero(v ...uint32) string {
+	if len(v) == 0 || v[0] == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(v[0]), 10)
+}
+
+// memarg renders a memory access's immediates as offset and alignment in
+// bytes, preceded by the memory index when it is not memory 0.
+func memarg(imm []byte) string {
+	align, n, err := wasm.ReadU32(imm, 0)
+	if err != nil {
+		return ""
+	}
+	var parts []string
+	pos := n
+	if align&0x40 != 0 {
+		mem, n, _ := wasm.ReadU32(imm, pos)
+		pos += n
+		align &^= 0x40
+		parts = append(parts, strconv.FormatUint(uint64(mem), 10))
+	}
+	offset, _, _ := wasm.ReadU32(imm, pos)
+	parts = append(parts, fmt.Sprintf("offset=%d", offset))
+	if align < 32 {
+		parts = append(parts, fmt.Sprintf("align=%d", uint64(1)<<align))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatF32(bits uint32) string {
+	f := math.Float32frombits(bits)
+	switch {
+	case f != f:
+		sign := ""
+		if bits>>31 != 0 {
+			sign = "-"
+		}
+		if payload := bits & 0x7fffff; payload != 0x400000 {
+			return fmt.Sprintf("%snan:0x%x", sign, payload)
+		}
+		return sign + "nan"
+	case math.IsInf(float64(f), 1):
+		return "inf"
+	case math.IsInf(float64(f), -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(float64(f), 'g', -1, 32)
+}
+
+func formatF64(bits uint64) string {
+	f := math.Float64frombits(bits)
+	switch {
+	case f != f:
+		sign := ""
+		if bits>>63 != 0 {
+			sign = "-"
+		}
+		if payload := bits & (1<<52 - 1); payload != 1<<51 {
+			return fmt.Sprintf("%snan:0x%x", sign, payload)
+		}
+		return sign + "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// signature renders a function type as (param ...) (result ...) clauses.
+// paramNames, when given, names individual parameters.
+func signature(ft wasm.FuncType, paramNames map[uint32]string) string {
+	var parts []string
+	if len(ft.Params) > 0 {
+		if paramNames == nil {
+			parts = append(parts, "(param "+valTypes(ft.Params)+")")
+		} else {
+			for i, t := range ft.Params {
+				if name, ok := paramNames[uint32(i)]; ok {
+					parts = append(parts, fmt.Sprintf("(param $%s %s)", name, t))
+				} else {
+					parts = append(parts, fmt.Sprintf("(param %s)", t))
+				}
+			}
+		}
+	}
+	if len(ft.Results) > 0 {
+		parts = append(parts, "(result "+valTypes(ft.Results)+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+func valTypes(ts []wasm.ValType) string {
+	s := make([]string, len(ts))
+	for i, t := range ts {
+		s[i] = t.String()
+	}
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,218 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+//! Budget snapshots around every host function and contract call.
+//!
+//! The host's trace hook reports each frame it pushes and pops; the budget
+//! is read at both points. Frames carry no public identity, so contract calls
+//! are named afterwards from the fn_call diagnostic events, which the host
+//! emits once per call in the same order.
+
+use crate::types::InvocationBudget;
+use soroban_env_host::events::Events;
+use soroban_env_host::xdr::{ContractEventBody, ContractEventType, ScVal};
+use soroban_env_host::{Host, HostError, TraceEvent};
+use std::cell::RefCell;
+use std::rc::Rc;
+
+pub const KIND_HOST_FN: &str = "host_fn";
+pub const KIND_CONTRACT_CALL: &str = "contract_call";
+
+#[derive(Default)]
+struct Recording {
+    budgets: Vec<InvocationBudget>,
+    /// Indices into `budgets` of the frames still on the stack.
+    open: Vec<usize>,
+}
+
+/// Collects invocation budgets from the host's trace hook.
+#[derive(Clone, Default)]
+pub struct InvocationRecorder {
+    recording: Rc<RefCell<Recording>>,
+}
+
+impl InvocationRecorder {
+    /// Installs the recorder as the trace hook of `host`.
+    pub fn install(&self, host: &Host) -> Result<(), HostError> {
+        let recording = Rc::clone(&self.recording);
+        let hook: Rc<dyn for<'a> Fn(&Host, TraceEvent<'a>) -> Result<(), HostError>> =
+            Rc::new(move |host: &Host, event: TraceEvent<'_>| {
+                let budget = host.budget_cloned();
+                let cpu = budget.get_cpu_insns_consumed().unwrap_or(0);
+                let mem = budget.get_mem_bytes_consumed().unwrap_or(0);
+                let mut rec = recording.borrow_mut();
+                match event {
+                    TraceEvent::PushCtx(_) => {
+                        let depth = rec.open.len() + 1;
+                        let kind = if depth == 1 {
+                            KIND_HOST_FN
+                        } else {
+                            KIND_CONTRACT_CALL
+                        };
+                        rec.budgets.push(InvocationBudget {
+                            kind: kind.to_string(),
+                            depth,
+                            cpu_start: cpu,
+                            cpu_end: cpu,
+                            mem_start: mem,
+                            mem_end: mem,
+                            ..InvocationBudget::default()
+                        });
+                        let idx = rec.budgets.len() - 1;
+                        rec.open.push(idx);
+                    }
+                    TraceEvent::PopCtx(..) => {
+                        if let Some(idx) = rec.open.pop() {
+                            rec.budgets[idx].cpu_end = cpu;
+                            rec.budgets[idx].mem_end = mem;
+                        }
+                    }
+                    _ => {}
+                }
+                Ok(())
+            });
+        host.set_trace_hook(Some(hook))
+    }
+
+    /// The recorded budgets in start order. Host functions are named after
+    /// `host_fns`, in order, and contract calls after the fn_call events; a
+    /// list that does not line up with the frames is not used.
+    pub fn finish(&self, host_fns: &[String], events: &Events) -> Vec<InvocationBudget> {
+        let mut budgets = std::mem::take(&mut self.recording.borrow_mut().budgets);
+
+        let host_fn_count = budgets.iter().filter(|b| b.kind == KIND_HOST_FN).count();
+        if host_fn_count == host_fns.len() {
+            let names = host_fns.iter();
+            for (budget, name) in budgets
+                .iter_mut()
+                .filter(|b| b.kind == KIND_HOST_FN)
+                .zip(names)
+            {
+                budget.function = name.clone();
+            }
+        }
+
+        let calls = fn_calls(events);
+        let call_count = budgets
+            .iter()
+            .filter(|b| b.kind == KIND_CONTRACT_CALL)
+            .count();
+        if call_count == calls.len() {
+            for (budget, (contract_id, function)) in budgets
+                .iter_mut()
+                .filter(|b| b.kind == KIND_CONTRACT_CALL)
+                .zip(calls)
+            {
+                budget.contract_id = Some(contract_id);
+                budget.function = function;
+            }
+        }
+        budgets
+    }
+}
+
+/// The callee contract and function of every fn_call diagnostic event. Its
+/// topics are [fn_call, callee contract id bytes, function symbol].
+pub fn fn_calls(events: &Events) -> Vec<(String, String)> {
+    let mut out = Vec::new();
+    for e in &events.0 {
+        if e.event.type_ != ContractEventType::Diagnostic {
+            continue;
+        }
+        let ContractEventBody::V0(body) = &e.event.body;
+        let topics = body.topics.as_slice();
+        match topics {
+            [ScVal::Symbol(marker), ScVal::Bytes(callee), ScVal::Symbol(function), ..]
+                if marker.0.as_slice() == b"fn_call" =>
+            {
+                let contract_id = match <[u8; 32]>::try_from(callee.as_slice()) {
+                    Ok(id) => stellar_strkey::Contract(id).to_string(),
+                    Err(_) => hex::encode(callee.as_slice()),
+                };
+                out.push((
+                    contract_id,
+                    String::from_utf8_lossy(function.0.as_slice()).into_owned(),
+                ));
+            }
+            _ => {}
+        }
+    }
+    out
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use soroban_env_host::events::HostEvent;
+    use soroban_env_host::xdr::{
+        ContractEvent, ContractEventV0, ExtensionPoint, ScBytes, ScSymbol,
+    };
+
+    fn fn_call_event(callee: [u8; 32], function: &str) -> HostEvent {
+        HostEvent {
+            failed_call: false,
+            event: ContractEvent {
+                ext: ExtensionPoint::V0,
+                contract_id: None,
+                type_: ContractEventType::Diagnostic,
+                body: ContractEventBody::V0(ContractEventV0 {
+                    topics: vec![
+                        ScVal::Symbol(ScSymbol("fn_call".try_into().unwrap())),
+                        ScVal::Bytes(ScBytes(callee.to_vec().try_into().unwrap())),
+                        ScVal::Symbol(ScSymbol(function.try_into().unwrap())),
+                    ]
+                    .try_into()
+                    .unwrap(),
+                    data: ScVal::Void,
+                }),
+            },
+        }
+    }
+
+    fn budget(kind: &str, depth: usize) -> InvocationBudget {
+        InvocationBudget {
+            kind: kind.to_string(),
+            depth,
+            ..InvocationBudget::default()
+        }
+    }
+
+    #[test]
+    fn test_fn_calls_decodes_callee_and_function() {
+        let events = Events(vec![fn_call_event([1; 32], "transfer")]);
+        let calls = fn_calls(&events);
+        assert_eq!(calls.len(), 1);
+        assert!(calls[0].0.starts_with('C'), "{}", calls[0].0);
+        assert_eq!(calls[0].1, "transfer");
+    }
+
+    #[test]
+    fn test_finish_names_frames_in_order() {
+        let recorder = InvocationRecorder::default();
+        recorder.recording.borrow_mut().budgets = vec![
+            budget(KIND_HOST_FN, 1),
+            budget(KIND_CONTRACT_CALL, 2),
+            budget(KIND_CONTRACT_CALL, 3),
+        ];
+        let events = Events(vec![
+            fn_call_event([1; 32], "swap"),
+            fn_call_event([2; 32], "transfer"),
+        ]);
+
+        let budgets = recorder.finish(&["InvokeContract".to_string()], &events);
+        assert_eq!(budgets[0].function, "InvokeContract");
+        assert_eq!(budgets[1].function, "swap");
+        assert_eq!(budgets[2].function, "transfer");
+    }
+
+    #[test]
+    fn test_finish_leaves_unmatched_calls_unnamed() {
+        let recorder = InvocationRecorder::default();
+        recorder.recording.borrow_mut().budgets =
+            vec![budget(KIND_HOST_FN, 1), budget(KIND_CONTRACT_CALL, 2)];
+
+        let budgets = recorder.finish(&[], &Events(vec![]));
+        assert_eq!(budgets[1].function, "");
+        assert!(budgets[1].contract_id.is_none());
+    }
+}
@@ -0,0 +1,402 @@
+// Copyright 2025 Erst Users
+// SPDX-License-Identifier: Apache-2.0
+
+//! Ledger state for a simulation run.
+//!
+//! The host reads entries from a [`LedgerState`] built from the request's
+//! ledger entries, through a recording footprint. After the run its storage
+//! is compared with the state to recover the footprint the host recorded and
+//! the entries the transaction changed.
+
+use crate::types::{LedgerChange, RecordedFootprint};
+use base64::Engine as _;
+use sha2::{Digest, Sha256};
+use soroban_env_host::budget::Budget;
+use soroban_env_host::storage::{AccessType, EntryWithLiveUntil, SnapshotSource, Storage};
+use soroban_env_host::xdr::{
+    ContractDataDurability, Hash, LedgerEntry, LedgerEntryData, LedgerEntryExt, LedgerKey,
+    LedgerKeyTtl, Limits, ReadXdr, TtlEntry, WriteXdr,
+};
+use soroban_env_host::HostError;
+use std::collections::{BTreeMap, HashMap};
+use std::rc::Rc;
+
+/// Network settings the host needs that requests do not carry, at their
+/// public network values.
+pub const BASE_RESERVE: u32 = 5_000_000;
+pub const MIN_TEMP_ENTRY_TTL: u32 = 17_280;
+pub const MIN_PERSISTENT_ENTRY_TTL: u32 = 2_073_600;
+pub const MAX_ENTRY_TTL: u32 = 3_110_400;
+
+pub const CHANGE_CREATED: &str = "created";
+pub const CHANGE_UPDATED: &str = "updated";
+pub const CHANGE_REMOVED: &str = "removed";
+pub const CHANGE_RESTORED: &str = "restored";
+
+/// Ledger entries the host reads from, with the live-until ledger of
+/// contract entries.
+#[derive(Debug, Clone, Default)]
+pub struct LedgerState {
+    ledger_seq: u32,
+    entries: BTreeMap<LedgerKey, EntryWithLiveUntil>,
+    /// Keys of archived entries restored before the run.
+    restored: Vec<LedgerKey>,
+}
+
+impl LedgerState {
+    /// Decodes base64 LedgerKey to LedgerEntry pairs as the state at
+    /// `ledger_seq`, or when that is unknown at the latest ledger any entry
+    /// was modified in. TTL entries are not kept themselves but give the
+    /// live-until ledger of the entry they belong to; contract entries
+    /// without one are taken to be live.
+    pub fn from_base64(
+        entries: &HashMap<String, String>,
+        ledger_seq: Option<u32>,
+    ) -> Result<Self, String> {
+        let mut ttls = HashMap::new();
+        let mut decoded = Vec::new();
+        for (key_xdr, entry_xdr) in entries {
+            let key: LedgerKey = decode("LedgerKey", key_xdr)?;
+            let entry: LedgerEntry = decode("LedgerEntry", entry_xdr)?;
+            if let LedgerEntryData::Ttl(ttl) = &entry.data {
+                ttls.insert(ttl.key_hash.clone(), ttl.live_until_ledger_seq);
+                continue;
+            }
+            decoded.push((key, entry));
+        }
+        let ledger_seq = ledger_seq.unwrap_or_else(|| {
+            decoded
+                .iter()
+                .map(|(_, entry)| entry.last_modified_ledger_seq)
+                .max()
+                .unwrap_or(0)
+        });
+
+        let mut state = Self {
+            ledger_seq,
+            ..Self::default()
+        };
+        for (key, entry) in decoded {
+            let live_until = if is_contract_entry(&key) {
+                Some(ttls.get(&key_hash(&key)?).copied().unwrap_or(ledger_seq))
+            } else {
+                None
+            };
+            state.entries.insert(key, (Rc::new(entry), live_until));
+        }
+        Ok(state)
+    }
+
+    /// The ledger the transaction runs in.
+    pub fn ledger_seq(&self) -> u32 {
+        self.ledger_seq
+    }
+
+    /// Number of entries the host can read.
+    pub fn len(&self) -> usize {
+        self.entries.len()
+    }
+
+    /// Adds the entries of a restore preamble, as archived entries restored
+    /// before the run.
+    pub fn restore_entries(&mut self, entries: &HashMap<String, String>) -> Result<(), String> {
+        let live_until = self.restored_live_until();
+        for (key_xdr, entry_xdr) in entries {
+            let key: LedgerKey = decode("LedgerKey", key_xdr)?;
+            let entry: LedgerEntry = decode("LedgerEntry", entry_xdr)?;
+            if let LedgerEntryData::Ttl(_) = entry.data {
+                continue;
+            }
+            self.entries
+                .insert(key.clone(), (Rc::new(entry), Some(live_until)));
+            self.restored.push(key);
+        }
+        Ok(())
+    }
+
+    /// Restores the persistent entries of `keys` that are archived, as a
+    /// RestoreFootprint operation does, and returns how many were restored.
+    pub fn restore_archived(&mut self, keys: &[LedgerKey]) -> usize {
+        let live_until = self.restored_live_until();
+        let mut count = 0;
+        for key in keys {
+            if !is_persistent_entry(key) {
+                continue;
+            }
+            if let Some((entry, Some(old))) = self.entries.get(key) {
+                if *old < self.ledger_seq {
+                    let entry = Rc::clone(entry);
+                    self.entries.insert(key.clone(), (entry, Some(live_until)));
+                    self.restored.push(key.clone());
+                    count += 1;
+                }
+            }
+        }
+        count
+    }
+
+    /// Recording storage over a copy of the state.
+    pub fn recording_storage(&self) -> Storage {
+        Storage::with_recording_footprint(Rc::new(self.clone()))
+    }
+
+    /// The footprint the host recorded in `storage`, as base64 LedgerKeys.
+    pub fn footprint(
+        &self,
+        storage: &Storage,
+        budget: &Budget,
+    ) -> Result<RecordedFootprint, String> {
+        let mut out = RecordedFootprint::default();
+        for (key, access) in storage.footprint.0.iter(budget).map_err(host_err)? {
+            let key_xdr = encode(key.as_ref())?;
+            match access {
+                AccessType::ReadOnly => out.read_only.push(key_xdr),
+                AccessType::ReadWrite => out.read_write.push(key_xdr),
+            }
+        }
+        out.read_only.sort();
+        out.read_write.sort();
+        Ok(out)
+    }
+
+    /// The net change of every entry the run modified, including restores
+    /// and the TTL entries of contract entries, in key order.
+    pub fn changes(&self, storage: &Storage, budget: &Budget) -> Result<Vec<LedgerChange>, String> {
+        let ledger_seq = self.ledger_seq;
+        let mut out = BTreeMap::new();
+        let mut record = |key: &LedgerKey,
+                          change_type: &str,
+                          entry: Option<LedgerEntry>|
+         -> Result<(), String> {
+            let key_xdr = encode(key)?;
+            let entry_xdr = entry.as_ref().map(encode).transpose()?;
+            out.insert(
+                key_xdr.clone(),
+                LedgerChange {
+                    key_xdr,
+                    change_type: change_type.to_string(),
+                    entry_xdr,
+                },
+            );
+            Ok(())
+        };
+
+        for key in &self.restored {
+            if let Some((entry, live_until)) = self.entries.get(key) {
+                record(key, CHANGE_RESTORED, Some(modified(entry, ledger_seq)))?;
+                if let Some(live_until) = live_until {
+                    let (ttl, ttl_state) = ttl_entry(key, *live_until, ledger_seq)?;
+                    record(&ttl, CHANGE_RESTORED, Some(ttl_state))?;
+                }
+            }
+        }
+
+        for (key, after) in storage.map.iter(budget).map_err(host_err)? {
+            let key = key.as_ref();
+            let restored = self.restored.contains(key);
+            match (self.entries.get(key), after) {
+                (None, Some((entry, live_until))) => {
+                    record(key, CHANGE_CREATED, Some(modified(entry, ledger_seq)))?;
+                    if let Some(live_until) = live_until {
+                        let (ttl, ttl_state) = ttl_entry(key, *live_until, ledger_seq)?;
+                        record(&ttl, CHANGE_CREATED, Some(ttl_state))?;
+                    }
+                }
+                (Some((_, old_live_until)), None) => {
+                    record(key, CHANGE_REMOVED, None)?;
+                    if old_live_until.is_some() {
+                        record(&ttl_key(key)?, CHANGE_REMOVED, None)?;
+                    }
+                }
+                (Some((old, old_live_until)), Some((new, new_live_until))) => {
+                    // A restored entry stays restored, with its latest state.
+                    let change_type = if restored {
+                        CHANGE_RESTORED
+                    } else {
+                        CHANGE_UPDATED
+                    };
+                    if old.data != new.data {
+                        record(key, change_type, Some(modified(new, ledger_seq)))?;
+                    }
+                    if let Some(live_until) = new_live_until {
+                        if old_live_until != new_live_until {
+                            let (ttl, ttl_state) = ttl_entry(key, *live_until, ledger_seq)?;
+                            record(&ttl, change_type, Some(ttl_state))?;
+                        }
+                    }
+                }
+                (None, None) => {}
+            }
+        }
+        Ok(out.into_values().collect())
+    }
+
+    /// The live-until ledger of a persistent entry restored in this ledger.
+    fn restored_live_until(&self) -> u32 {
+        self.ledger_seq.saturating_add(MIN_PERSISTENT_ENTRY_TTL - 1)
+    }
+}
+
+impl SnapshotSource for LedgerState {
+    fn get(&self, key: &Rc<LedgerKey>) -> Result<Option<EntryWithLiveUntil>, HostError> {
+        Ok(self.entries.get(key.as_ref()).cloned())
+    }
+}
+
+/// Whether `key` is a contract data or code entry, which carries a TTL.
+pub fn is_contract_entry(key: &LedgerKey) -> bool {
+    matches!(key, LedgerKey::ContractData(_) | LedgerKey::ContractCode(_))
+}
+
+/// Whether `key` is a persistent contract entry, which is archived rather
+/// than deleted when its TTL runs out.
+pub fn is_persistent_entry(key: &LedgerKey) -> bool {
+    match key {
+        LedgerKey::ContractCode(_) => true,
+        LedgerKey::ContractData(data) => data.durability == ContractDataDurability::Persistent,
+        _ => false,
+    }
+}
+
+/// Base64 XDR of `value`.
+pub fn encode<T: WriteXdr>(value: &T) -> Result<String, String> {
+    value
+        .to_xdr(Limits::none())
+        .map(|bytes| base64::engine::general_purpose::STANDARD.encode(bytes))
+        .map_err(|e| format!("Failed to encode XDR: {e}"))
+}
+
+fn decode<T: ReadXdr>(what: &str, b64: &str) -> Result<T, String> {
+    let bytes = base64::engine::general_purpose::STANDARD
+        .decode(b64)
+        .map_err(|e| format!("Failed to decode {what} Base64: {e}"))?;
+    T::from_xdr(bytes, Limits::none()).map_err(|e| format!("Failed to parse {what} XDR: {e}"))
+}
+
+fn host_err(e: HostError) -> String {
+    format!("Failed to read host storage: {e:?}")
+}
+
+/// The entry as the ledger stores it after a change in `ledger_seq`.
+fn modified(entry: &LedgerEntry, ledger_seq: u32) -> LedgerEntry {
+    LedgerEntry {
+        last_modified_ledger_seq: ledger_seq,
+        ..entry.clone()
+    }
+}
+
+fn key_hash(key: &LedgerKey) -> Result<Hash, String> {
+    let bytes = key
+        .to_xdr(Limits::none())
+        .map_err(|e| format!("Failed to encode LedgerKey XDR: {e}"))?;
+    Ok(Hash(Sha256::digest(bytes).into()))
+}
+
+fn ttl_key(key: &LedgerKey) -> Result<LedgerKey, String> {
+    Ok(LedgerKey::Ttl(LedgerKeyTtl {
+        key_hash: key_hash(key)?,
+    }))
+}
+
+fn ttl_entry(
+    key: &LedgerKey,
+    live_until: u32,
+    ledger_seq: u32,
+) -> Result<(LedgerKey, LedgerEntry), String> {
+    let hash = key_hash(key)?;
+    let entry = LedgerEntry {
+        last_modified_ledger_seq: ledger_seq,
+        data: LedgerEntryData::Ttl(TtlEntry {
+            key_hash: hash.clone(),
+            live_until_ledger_seq: live_until,
+        }),
+        ext: LedgerEntryExt::V0,
+    };
+    Ok((LedgerKey::Ttl(LedgerKeyTtl { key_hash: hash }), entry))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use soroban_env_host::xdr::{
+        ContractDataEntry, ContractId, ExtensionPoint, LedgerKeyContractData, ScAddress, ScSymbol,
+        ScVal,
+    };
+
+    fn contract_data(durability: ContractDataDurability) -> (LedgerKey, LedgerEntry) {
+        let contract = ScAddress::Contract(ContractId(Hash([7; 32])));
+        let key = ScVal::Symbol(ScSymbol("counter".try_into().unwrap()));
+        let ledger_key = LedgerKey::ContractData(LedgerKeyContractData {
+            contract: contract.clone(),
+            key: key.clone(),
+            durability,
+        });
+        let entry = LedgerEntry {
+            last_modified_ledger_seq: 10,
+            data: LedgerEntryData::ContractData(ContractDataEntry {
+                ext: ExtensionPoint::V0,
+                contract,
+                key,
+                durability,
+                val: ScVal::U32(1),
+            }),
+            ext: LedgerEntryExt::V0,
+        };
+        (ledger_key, entry)
+    }
+
+    fn base64_map(pairs: &[(LedgerKey, LedgerEntry)]) -> HashMap<String, String> {
+        pairs
+            .iter()
+            .map(|(k, e)| (encode(k).unwrap(), encode(e).unwrap()))
+            .collect()
+    }
+
+    fn live_until(state: &LedgerState, key: LedgerKey) -> Option<u32> {
+        state.get(&Rc::new(key)).unwrap().unwrap().1
+    }
+
+    #[test]
+    fn test_from_base64_takes_live_until_from_ttl_entries() {
+        let (key, entry) = contract_data(ContractDataDurability::Persistent);
+        let ttl = ttl_entry(&key, 500, 10).unwrap();
+        let state =
+            LedgerState::from_base64(&base64_map(&[(key.clone(), entry), ttl]), Some(100)).unwrap();
+
+        assert_eq!(state.len(), 1);
+        assert_eq!(live_until(&state, key), Some(500));
+    }
+
+    #[test]
+    fn test_from_base64_defaults_to_live_at_latest_ledger() {
+        let (key, entry) = contract_data(ContractDataDurability::Temporary);
+        let state = LedgerState::from_base64(&base64_map(&[(key.clone(), entry)]), None).unwrap();
+
+        assert_eq!(state.ledger_seq(), 10);
+        assert_eq!(live_until(&state, key), Some(10));
+    }
+
+    #[test]
+    fn test_restore_archived_only_restores_expired_persistent_entries() {
+        let (persistent, entry) = contract_data(ContractDataDurability::Persistent);
+        let ttl = ttl_entry(&persistent, 50, 10).unwrap();
+        let mut state =
+            LedgerState::from_base64(&base64_map(&[(persistent.clone(), entry), ttl]), Some(100))
+                .unwrap();
+
+        assert_eq!(state.restore_archived(&[persistent.clone()]), 1);
+        assert_eq!(state.restore_archived(&[persistent.clone()]), 0);
+        assert_eq!(
+            live_until(&state, persistent),
+            Some(100 + MIN_PERSISTENT_ENTRY_TTL - 1)
+        );
+    }
+
+    #[test]
+    fn test_invalid_base64_is_reported() {
+        let mut entries = HashMap::new();
+        entries.insert("not-base64!".to_string(), String::new());
+        let err = LedgerState::from_base64(&entries, None).unwrap_err();
+        assert!(err.contains("LedgerKey Base64"), "{err}");
+    }
+}
@@ -6,6 +6,8 @@
 mod config;
 mod gas_optimizer;
 mod git_detector;
+mod invocations;
+mod ledger;
 mod runner;
 mod source_map_cache;
 mod source_mapper;
@@ -15,14 +17,22 @@ mod vm;
 mod wasm;
 
 use crate::gas_optimizer::{BudgetMetrics, GasOptimizationAdvisor, CPU_LIMIT, MEMORY_LIMIT};
+use crate::invocations::InvocationRecorder;
+use crate::ledger::LedgerState;
 use crate::source_mapper::SourceMapper;
 use crate::stack_trace::WasmStackTrace;
 use crate::types::*;
 use base64::Engine as _;
+use sha2::{Digest, Sha256};
+use soroban_env_host::events::Events;
 use soroban_env_host::xdr::ReadXdr;
 use soroban_env_host::{
-    xdr::{Operation, OperationBody},
-    Host, HostError,
+    xdr::{
+        AccountId, ContractEventType, FeeBumpTransactionInnerTx, MuxedAccount, Operation,
+        OperationBody, PublicKey, ScVal, SorobanAuthorizationEntry, SorobanTransactionData,
+        TransactionEnvelope, TransactionExt,
+    },
+    Host, HostError, LedgerInfo,
 };
 use std::collections::HashMap;
 use std::env;
@@ -34,6 +44,8 @@ use tracing_subscriber::{fmt, EnvFilter};
 
 const ERR_MEMORY_LIMIT_EXCEEDED: &str = "ERR_MEMORY_LIMIT_EXCEEDED";
 
+const PUBLIC_NETWORK_PASSPHRASE: &str = "Public Global Stellar Network ; September 2015";
+
 fn init_logger() {
     // Check if the environment variable ERST_LOG_FORMAT is set to "json"
     let use_json = env::var("ERST_LOG_FORMAT")
@@ -74,6 +86,7 @@ fn send_error(msg: String) {
         source_location: None,
         stack_trace: Some(trace),
         wasm_offset: None,
+        ..Default::default()
     };
     if let Ok(json) = serde_json::to_string(&res) {
         println!("{}", json);
@@ -151,14 +164,17 @@ fn check_memory_limit_or_panic(host: &Host, memory_limit: Option<u64>) {
     }
 }
 
+/// Runs the operations on `host` and returns the logs with the value the
+/// last host function returned.
 fn execute_operations(
     host: &Host,
     operations: &[Operation],
     request: &SimulationRequest,
     memory_limit: Option<u64>,
     coverage: &mut CoverageTracker,
-) -> Result<Vec<String>, HostError> {
+) -> Result<(Vec<String>, Option<ScVal>), HostError> {
     let mut logs = Vec::new();
+    let mut return_value = None;
     check_memory_limit_or_panic(host, memory_limit);
     for op in operations {
         coverage.record_operation(op);
@@ -178,8 +194,14 @@ fn execute_operations(
                 
                 let val = host.invoke_function(invoke_op.host_function.clone())?;
                 logs.push(format!("Result: {val:?}"));
+                return_value = Some(val);
                 check_memory_limit_or_panic(host, memory_limit);
             }
+            OperationBody::RestoreFootprint(_) => {
+                // The archived entries were restored into the ledger state
+                // before the host was created.
+                logs.push("Restored the archived entries of the footprint".to_string());
+            }
             _ => {
                 logs.push(format!(
                     "Skipping non-Soroban operation: {:?}",
@@ -189,7 +211,7 @@ fn execute_operations(
             }
         }
     }
-    Ok(logs)
+    Ok((logs, return_value))
 }
 
 fn transaction_fee_stroops(envelope: &soroban_env_host::xdr::TransactionEnvelope) -> u64 {
@@ -200,6 +222,133 @@ fn transaction_fee_stroops(envelope: &soroban_env_host::xdr::TransactionEnvelope
     }
 }
 
+fn account_id(account: &MuxedAccount) -> AccountId {
+    match account {
+        MuxedAccount::Ed25519(key) => AccountId(PublicKey::PublicKeyTypeEd25519(key.clone())),
+        MuxedAccount::MuxedEd25519(muxed) => {
+            AccountId(PublicKey::PublicKeyTypeEd25519(muxed.ed25519.clone()))
+        }
+    }
+}
+
+/// The account the operations run as: the source of the first operation,
+/// or of the transaction when it has none.
+fn source_account(envelope: &TransactionEnvelope, operations: &[Operation]) -> AccountId {
+    if let Some(source) = operations.first().and_then(|op| op.source_account.as_ref()) {
+        return account_id(source);
+    }
+    match envelope {
+        TransactionEnvelope::Tx(tx_v1) => account_id(&tx_v1.tx.source_account),
+        TransactionEnvelope::TxV0(tx_v0) => AccountId(PublicKey::PublicKeyTypeEd25519(
+            tx_v0.tx.source_account_ed25519.clone(),
+        )),
+        TransactionEnvelope::TxFeeBump(bump) => match &bump.tx.inner_tx {
+            FeeBumpTransactionInnerTx::Tx(tx_v1) => account_id(&tx_v1.tx.source_account),
+        },
+    }
+}
+
+fn soroban_data(envelope: &TransactionEnvelope) -> Option<&SorobanTransactionData> {
+    let ext = match envelope {
+        TransactionEnvelope::Tx(tx_v1) => &tx_v1.tx.ext,
+        TransactionEnvelope::TxV0(_) => return None,
+        TransactionEnvelope::TxFeeBump(bump) => match &bump.tx.inner_tx {
+            FeeBumpTransactionInnerTx::Tx(tx_v1) => &tx_v1.tx.ext,
+        },
+    };
+    match ext {
+        TransactionExt::V1(data) => Some(data),
+        TransactionExt::V0 => None,
+    }
+}
+
+/// The authorization entries the transaction was signed with.
+fn authorization_entries(operations: &[Operation]) -> Vec<SorobanAuthorizationEntry> {
+    operations
+        .iter()
+        .filter_map(|op| match &op.body {
+            OperationBody::InvokeHostFunction(invoke_op) => Some(invoke_op.auth.to_vec()),
+            _ => None,
+        })
+        .flatten()
+        .collect()
+}
+
+/// The ledger protocol version the transaction runs under: the requested one,
+/// or the newest the linked host supports. Versions newer than the host's
+/// cannot be emulated; the host itself rejects ones older than it supports.
+fn ledger_protocol_version(request: &SimulationRequest) -> Result<u32, String> {
+    let supported = soroban_env_host::meta::INTERFACE_VERSION.protocol;
+    match request.protocol_version {
+        None => Ok(supported),
+        Some(v) if v <= supported => Ok(v),
+        Some(v) => Err(format!(
+            "protocol {v} is newer than the simulator's host, which supports up to protocol {supported}"
+        )),
+    }
+}
+
+/// Configures `host` to run the transaction: the ledger it runs in, its
+/// source account and authorization. Transactions that carry authorization
+/// entries are checked against them; the others, and footprint recording
+/// runs, record the authorization they need instead.
+fn configure_host(
+    host: &Host,
+    request: &SimulationRequest,
+    protocol_version: u32,
+    ledger_seq: u32,
+    source: AccountId,
+    auth_entries: Vec<SorobanAuthorizationEntry>,
+) -> Result<(), HostError> {
+    let passphrase = request
+        .network_passphrase
+        .as_deref()
+        .unwrap_or(PUBLIC_NETWORK_PASSPHRASE);
+    host.set_ledger_info(LedgerInfo {
+        protocol_version,
+        sequence_number: ledger_seq,
+        timestamp: u64::try_from(request.timestamp).unwrap_or(0),
+        network_id: Sha256::digest(passphrase.as_bytes()).into(),
+        base_reserve: ledger::BASE_RESERVE,
+        min_temp_entry_ttl: ledger::MIN_TEMP_ENTRY_TTL,
+        min_persistent_entry_ttl: ledger::MIN_PERSISTENT_ENTRY_TTL,
+        max_entry_ttl: ledger::MAX_ENTRY_TTL,
+    })?;
+    host.set_source_account(source)?;
+    host.set_base_prng_seed([0; 32])?;
+    if auth_entries.is_empty() || request.record_footprint {
+        host.switch_to_recording_auth(true)
+    } else {
+        host.set_authorization_entries(auth_entries)
+    }
+}
+
+/// Base64 ContractEvent XDR of the events the transaction keeps: those of
+/// calls that did not fail, other than diagnostic events.
+fn contract_events_xdr(events: &Events) -> Vec<String> {
+    events
+        .0
+        .iter()
+        .filter(|e| !e.failed_call && e.event.type_ != ContractEventType::Diagnostic)
+        .filter_map(|e| ledger::encode(&e.event).ok())
+        .collect()
+}
+
+/// Base64 DiagnosticEvent XDR of every event.
+fn diagnostic_events_xdr(events: &Events) -> Vec<String> {
+    events
+        .0
+        .iter()
+        .filter_map(|e| {
+            ledger::encode(&soroban_env_host::xdr::DiagnosticEvent {
+                in_successful_contract_call: !e.failed_call,
+                event: e.event.clone(),
+            })
+            .ok()
+        })
+        .collect()
+}
+
 fn mocked_required_fee_stroops(
     request: &SimulationRequest,
     operations_count: usize,
@@ -341,6 +490,7 @@ fn main() {
             source_location: None,
             stack_trace: None,
             wasm_offset: None,
+            ..Default::default()
         };
         if let Ok(json) = serde_json::to_string(&res) {
             println!("{}", json);
@@ -372,6 +522,7 @@ fn main() {
                 source_location: None,
                 stack_trace: None,
                 wasm_offset: None,
+                ..Default::default()
             };
             println!(
                 "{}",
@@ -459,14 +610,102 @@ fn main() {
         None
     };
 
+    // Extract Operations
+    let operations = match &envelope {
+        soroban_env_host::xdr::TransactionEnvelope::Tx(tx_v1) => &tx_v1.tx.operations,
+        soroban_env_host::xdr::TransactionEnvelope::TxV0(tx_v0) => &tx_v0.tx.operations,
+        soroban_env_host::xdr::TransactionEnvelope::TxFeeBump(bump) => match &bump.tx.inner_tx {
+            soroban_env_host::xdr::FeeBumpTransactionInnerTx::Tx(tx_v1) => &tx_v1.tx.operations,
+        },
+    };
+
+    // Build the ledger state the host reads from
+    let mut ledger_state = match LedgerState::from_base64(
+        &request.ledger_entries.clone().unwrap_or_default(),
+        request.ledger_sequence,
+    ) {
+        Ok(state) => state,
+        Err(e) => {
+            send_error(e);
+            return;
+        }
+    };
+    let loaded_entries_count = ledger_state.len();
+    let ledger_seq = ledger_state.ledger_seq();
+
+    if let Some(preamble) = &request.restore_preamble {
+        let entries = preamble
+            .get("ledger_entries")
+            .map(|v| serde_json::from_value::<HashMap<String, String>>(v.clone()));
+        match entries {
+            Some(Ok(entries)) => {
+                if let Err(e) = ledger_state.restore_entries(&entries) {
+                    send_error(format!("Invalid restore preamble: {e}"));
+                    return;
+                }
+                eprintln!(
+                    "Restored {} archived entries from the preamble",
+                    entries.len()
+                );
+            }
+            Some(Err(e)) => {
+                send_error(format!("Invalid restore preamble: {e}"));
+                return;
+            }
+            None => eprintln!("Warning: restore preamble has no ledger_entries; nothing restored."),
+        }
+    }
+    if operations
+        .iter()
+        .any(|op| matches!(op.body, OperationBody::RestoreFootprint(_)))
+    {
+        let keys = soroban_data(&envelope)
+            .map(|data| data.resources.footprint.read_write.to_vec())
+            .unwrap_or_default();
+        let restored = ledger_state.restore_archived(&keys);
+        eprintln!("RestoreFootprint restored {restored} archived entries");
+    }
+
     // Initialize Host
-    let sim_host = runner::SimHost::new(
+    let sim_host = runner::SimHost::with_storage(
+        ledger_state.recording_storage(),
         None,
         request.resource_calibration.clone(),
         request.memory_limit,
     );
     let host = sim_host.inner;
 
+    let protocol_version = match ledger_protocol_version(&request) {
+        Ok(v) => v,
+        Err(e) => {
+            send_error(e);
+            return;
+        }
+    };
+    let recorder = InvocationRecorder::default();
+    let configured = configure_host(
+        &host,
+        &request,
+        protocol_version,
+        ledger_seq,
+        source_account(&envelope, operations),
+        authorization_entries(operations),
+    )
+    .and_then(|()| recorder.install(&host));
+    if let Err(e) = configured {
+        send_error(format!("Failed to configure host: {e:?}"));
+        return;
+    }
+    let host_fns: Vec<String> = operations
+        .iter()
+        .filter_map(|op| match &op.body {
+            OperationBody::InvokeHostFunction(invoke_op) => {
+                Some(invoke_op.host_function.name().to_string())
+            }
+            _ => None,
+        })
+        .collect();
+
     // --- START: Local WASM Loading Integration (Issue #70) ---
     if let Some(path) = &request.wasm_path {
         match wasm::load_wasm_from_path(path) {
@@ -480,63 +719,6 @@ fn main() {
     }
     // --- END: Local WASM Loading Integration ---
 
-    let mut loaded_entries_count = 0;
-
-    // Populate Host Storage
-    if let Some(entries) = &request.ledger_entries {
-        for (key_xdr, entry_xdr) in entries {
-            // Decode Key
-            let _key = match base64::engine::general_purpose::STANDARD.decode(key_xdr) {
-                Ok(b) => match soroban_env_host::xdr::LedgerKey::from_xdr(
-                    b,
-                    soroban_env_host::xdr::Limits::none(),
-                ) {
-                    Ok(k) => k,
-                    Err(e) => {
-                        send_error(format!("Failed to parse LedgerKey XDR: {}", e));
-                        return;
-                    }
-                },
-                Err(e) => {
-                    send_error(format!("Failed to decode LedgerKey Base64: {}", e));
-                    return;
-                }
-            };
-
-            // Decode Entry
-            let _entry = match base64::engine::general_purpose::STANDARD.decode(entry_xdr) {
-                Ok(b) => match soroban_env_host::xdr::LedgerEntry::from_xdr(
-                    b,
-                    soroban_env_host::xdr::Limits::none(),
-                ) {
-                    Ok(e) => e,
-                    Err(e) => {
-                        send_error(format!("Failed to parse LedgerEntry XDR: {}", e));
-                        return;
-                    }
-                },
-                Err(e) => {
-                    send_error(format!("Failed to decode LedgerEntry Base64: {}", e));
-                    return;
-                }
-            };
-
-            // TODO: Inject into host storage.
-            // For MVP, we verify we can parse them.
-            eprintln!("Parsed Ledger Entry: Key={:?}, Entry={:?}", _key, _entry);
-            loaded_entries_count += 1;
-        }
-    }
-
-    // Extract Operations and Simulate
-    let operations = match &envelope {
-        soroban_env_host::xdr::TransactionEnvelope::Tx(tx_v1) => &tx_v1.tx.operations,
-        soroban_env_host::xdr::TransactionEnvelope::TxV0(tx_v0) => &tx_v0.tx.operations,
-        soroban_env_host::xdr::TransactionEnvelope::TxFeeBump(bump) => match &bump.tx.inner_tx {
-            soroban_env_host::xdr::FeeBumpTransactionInnerTx::Tx(tx_v1) => &tx_v1.tx.operations,
-        },
-    };
-
     // Wrap the operation execution in panic protection
     let mut coverage = CoverageTracker::default();
     let result = std::panic::catch_unwind(std::panic::AssertUnwindSafe(|| {
@@ -611,11 +793,46 @@ fn main() {
         lcov_report = Some(report);
     }
 
+    // Recover what the run did from the host's events and storage
+    let host_events = host.get_events();
+    let no_events = Events(vec![]);
+    let events_ref = host_events.as_ref().unwrap_or(&no_events);
+    let invocation_budgets = recorder.finish(&host_fns, events_ref);
+    let events_xdr = diagnostic_events_xdr(events_ref);
+    let succeeded = matches!(result, Ok(Ok(_)));
+    let contract_events = succeeded.then(|| contract_events_xdr(events_ref));
+    let _ = host.set_trace_hook(None);
+    let (recorded_footprint, ledger_changes) = match host.try_finish() {
+        Ok((storage, _)) => {
+            let footprint = if request.record_footprint {
+                ledger_state
+                    .footprint(&storage, &budget)
+                    .map_err(|e| eprintln!("Warning: {e}"))
+                    .ok()
+            } else {
+                None
+            };
+            let changes = if succeeded {
+                ledger_state
+                    .changes(&storage, &budget)
+                    .map_err(|e| eprintln!("Warning: {e}"))
+                    .ok()
+            } else {
+                None
+            };
+            (footprint, changes)
+        }
+        Err(e) => {
+            eprintln!("Warning: failed to read host storage: {e:?}");
+            (None, None)
+        }
+    };
+
     match result {
-        Ok(Ok(exec_logs)) => {
+        Ok(Ok((exec_logs, return_value))) => {
             // Extract both raw event strings and structured diagnostic events
             let (events, diagnostic_events): (Vec<String>, Vec<DiagnosticEvent>) =
-                match host.get_events() {
+                match &host_events {
                     Ok(evs) => {
                         let raw_events: Vec<String> =
                             (evs.0).iter().map(|e| format!("{:?}", e)).collect();
@@ -669,8 +886,8 @@ fn main() {
                 };
 
             // Capture categorized events for analyzer
-            let categorized_events = match host.get_events() {
-                Ok(evs) => categorize_events(&evs),
+            let categorized_events = match &host_events {
+                Ok(evs) => categorize_events(evs),
                 Err(_) => vec![],
             };
 
@@ -715,6 +932,10 @@ fn main() {
                         source_location: None,
                         stack_trace: None,
                         wasm_offset: None,
+                        footprint: recorded_footprint,
+                        invocation_budgets,
+                        diagnostic_events_xdr: events_xdr,
+                        ..Default::default()
                     };
 
                     if let Ok(json) = serde_json::to_string(&response) {
@@ -741,7 +962,6 @@ fn main() {
                 optimization_report,
                 budget_usage: Some(budget_usage),
                 stack_trace: None,
-                wasm_offset: None,
                 // If a WASM with debug symbols was provided, expose the first
                 // mappable source location so callers can correlate failures.
                 source_location: source_mapper
@@ -749,6 +969,12 @@ fn main() {
                     .and_then(|m| m.map_wasm_offset_to_source(0))
                     .and_then(|loc| serde_json::to_string(&loc).ok()),
                 wasm_offset: None,
+                return_value: return_value.and_then(|val| ledger::encode(&val).ok()),
+                contract_events,
+                ledger_changes,
+                footprint: recorded_footprint,
+                invocation_budgets,
+                diagnostic_events_xdr: events_xdr,
             };
 
             if let Ok(json) = serde_json::to_string(&response) {
@@ -805,6 +1031,10 @@ fn main() {
                 source_location,
                 stack_trace: Some(wasm_trace),
                 wasm_offset,
+                footprint: recorded_footprint,
+                invocation_budgets,
+                diagnostic_events_xdr: events_xdr,
+                ..Default::default()
             };
             if let Ok(json) = serde_json::to_string(&response) {
                 println!("{}", json);
@@ -849,6 +1079,10 @@ fn main() {
                 source_location: None,
                 stack_trace: Some(wasm_trace),
                 wasm_offset: None,
+                footprint: recorded_footprint,
+                invocation_budgets,
+                diagnostic_events_xdr: events_xdr,
+                ..Default::default()
             };
             if let Ok(json) = serde_json::to_string(&response) {
                 println!("{}", json);
@@ -1231,6 +1465,9 @@ mod tests {
     #[test]
     fn test_extract_missing_key_id_none_when_absent() {
         assert_eq!(extract_missing_key_id("generic error with no key"), None);
+    }
+
+    #[test]
     fn test_generate_lcov_report_contains_function_hits() {
         let mut coverage = CoverageTracker::default();
         coverage
@@ -1247,5 +1484,23 @@ mod tests {
         assert!(report.contains("FNF:2"));
         assert!(report.contains("FNH:2"));
     }
+
+    #[test]
+    fn test_ledger_protocol_version() {
+        let supported = soroban_env_host::meta::INTERFACE_VERSION.protocol;
+        let request = |v: Option<u32>| -> SimulationRequest {
+            let mut json = serde_json::json!({"envelope_xdr": "", "result_meta_xdr": ""});
+            if let Some(v) = v {
+                json["protocol_version"] = v.into();
+            }
+            serde_json::from_value(json).unwrap()
+        };
+
+        assert_eq!(ledger_protocol_version(&request(None)), Ok(supported));
+        assert_eq!(
+            ledger_protocol_version(&request(Some(supported - 1))),
+            Ok(supported - 1)
+        );
+        assert!(ledger_protocol_version(&request(Some(supported + 1))).is_err());
+    }
 }
->>>>>>> ac2f0a127a0ae2292443728a70f9e09fa77f8835
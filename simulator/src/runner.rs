@@ -24,6 +24,17 @@ impl SimHost {
         budget_limits: Option<(u64, u64)>,
         calibration: Option<crate::types::ResourceCalibration>,
         memory_limit: Option<u64>,
+    ) -> Self {
+        Self::with_storage(Storage::default(), budget_limits, calibration, memory_limit)
+    }
+
+    /// Initialize a new Host over `storage`, e.g. a recording footprint over
+    /// a ledger snapshot.
+    pub fn with_storage(
+        storage: Storage,
+        budget_limits: Option<(u64, u64)>,
+        calibration: Option<crate::types::ResourceCalibration>,
+        memory_limit: Option<u64>,
     ) -> Self {
         let budget = Budget::default();
 
@@ -39,7 +50,7 @@ impl SimHost {
         }
 
         // Host::with_storage_and_budget is available in recent versions
-        let host = Host::with_storage_and_budget(Storage::default(), budget);
+        let host = Host::with_storage_and_budget(storage, budget);
 
         // Enable debug mode for better diagnostics
         host.set_diagnostic_level(DiagnosticLevel::Debug)
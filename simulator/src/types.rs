@@ -15,12 +15,20 @@ pub struct SimulationRequest {
     pub ledger_entries: Option<HashMap<String, String>>,
     pub contract_wasm: Option<String>,
     pub wasm_path: Option<String>, // Added for local loading
+    #[serde(default)]
     pub enable_optimization_advisor: bool,
     pub profile: Option<bool>,
-    /// RFC 3339 timestamp supplied by the caller.  Preserved for future use
-    /// (e.g. time-locked contract logic); not yet consumed by the simulator.
-    #[allow(dead_code)]
-    pub timestamp: String,
+    /// Unix close time of the ledger the transaction runs in.
+    #[serde(default)]
+    pub timestamp: i64,
+    /// Sequence of the ledger the transaction runs in. When absent the
+    /// latest last-modified ledger of the supplied entries is used.
+    #[serde(default)]
+    pub ledger_sequence: Option<u32>,
+    /// Passphrase of the network the transaction was built for; defaults to
+    /// the public network.
+    #[serde(default)]
+    pub network_passphrase: Option<String>,
     pub mock_base_fee: Option<u32>,
     pub mock_gas_price: Option<u64>,
     pub mock_signature_verification: Option<bool>,
@@ -34,6 +42,16 @@ pub struct SimulationRequest {
     pub memory_limit: Option<u64>,
     #[serde(default)]
     pub restore_preamble: Option<serde_json::Value>,
+    /// Skip the source map cache and always parse debug symbols.
+    #[serde(default)]
+    pub no_cache: Option<bool>,
+    /// Report the footprint the run recorded.
+    #[serde(default)]
+    pub record_footprint: bool,
+    /// Ledger protocol version to run the transaction under; defaults to the
+    /// newest version the linked host supports.
+    #[serde(default)]
+    pub protocol_version: Option<u32>,
 }
 
 #[derive(Debug, Deserialize, Serialize, Clone)]
@@ -45,7 +63,7 @@ pub struct ResourceCalibration {
     pub ed25519_fixed: u64,
 }
 
-#[derive(Debug, Serialize)]
+#[derive(Debug, Serialize, Default)]
 pub struct SimulationResponse {
     pub status: String,
     pub error: Option<String>,
@@ -67,6 +85,57 @@ pub struct SimulationResponse {
     #[serde(skip_serializing_if = "Option::is_none")]
     pub stack_trace: Option<WasmStackTrace>,
     pub wasm_offset: Option<u64>,
+    /// Base64 ScVal returned by the last host function.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub return_value: Option<String>,
+    /// Base64 ContractEvent XDR of the events the transaction emitted.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub contract_events: Option<Vec<String>>,
+    /// Net change of every ledger entry the transaction modified.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub ledger_changes: Option<Vec<LedgerChange>>,
+    /// Footprint recorded when the request set `record_footprint`.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub footprint: Option<RecordedFootprint>,
+    /// Budget before and after every host function and contract call, in
+    /// the order they started.
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub invocation_budgets: Vec<InvocationBudget>,
+    /// Base64 DiagnosticEvent XDR of every event, diagnostic ones included.
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    pub diagnostic_events_xdr: Vec<String>,
+}
+
+#[derive(Debug, Serialize, Default)]
+pub struct RecordedFootprint {
+    pub read_only: Vec<String>,
+    pub read_write: Vec<String>,
+}
+
+#[derive(Debug, Serialize, Clone, PartialEq, Eq)]
+pub struct LedgerChange {
+    pub key_xdr: String,
+    /// created, updated, removed or restored.
+    #[serde(rename = "type")]
+    pub change_type: String,
+    /// Base64 LedgerEntry after the change; absent for removals.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub entry_xdr: Option<String>,
+}
+
+#[derive(Debug, Serialize, Clone, Default)]
+pub struct InvocationBudget {
+    /// host_fn or contract_call.
+    pub kind: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub contract_id: Option<String>,
+    pub function: String,
+    /// 1 for host functions, one more for every nested call.
+    pub depth: usize,
+    pub cpu_start: u64,
+    pub cpu_end: u64,
+    pub mem_start: u64,
+    pub mem_end: u64,
 }
 
 #[derive(Debug, Serialize)]